@@ -0,0 +1,89 @@
+package api
+
+import (
+	"errors"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+
+	"kvcache/rpcerror"
+	"kvcache/service"
+	"kvcache/storage"
+)
+
+// 错误映射：服务层和存储层的错误按storage.Code分类，gRPC状态由rpcerror转换，
+// HTTP返回对应的状态码和{"error", "code"}错误信封
+
+// statusClientClosedRequest 客户端在响应前关闭了请求，沿用nginx的499
+const statusClientClosedRequest = 499
+
+// httpStatuses 错误类别对应的HTTP状态码，未列出的类别返回500
+var httpStatuses = map[storage.Code]int{
+	storage.CodeNotFound:          http.StatusNotFound,
+	storage.CodeEvicted:           http.StatusGone,
+	storage.CodeInvalidArgument:   http.StatusBadRequest,
+	storage.CodeResourceExhausted: http.StatusInsufficientStorage,
+	storage.CodeConflict:          http.StatusConflict,
+	storage.CodeUnavailable:       http.StatusServiceUnavailable,
+	storage.CodeCorrupted:         http.StatusInternalServerError,
+	storage.CodeUnimplemented:     http.StatusNotImplemented,
+	storage.CodeOutOfRange:        http.StatusGone,
+	storage.CodeCanceled:          statusClientClosedRequest,
+	storage.CodeDeadlineExceeded:  http.StatusGatewayTimeout,
+}
+
+// grpcError 将错误转换为gRPC状态，键属于其他节点时返回FailedPrecondition，所属节点通过ErrorInfo的owner给出
+func grpcError(err error) error {
+	var redirect *service.RedirectError
+	if errors.As(err, &redirect) {
+		return rpcerror.WithErrorInfo(status.New(codes.FailedPrecondition, err.Error()), "REDIRECT", map[string]string{"owner": redirect.Addr})
+	}
+	return rpcerror.Status(err)
+}
+
+// invalidArgument 返回请求参数不合法的gRPC状态
+func invalidArgument(msg string) error {
+	return grpcError(storage.NewError(storage.CodeInvalidArgument, msg))
+}
+
+// httpStatus 返回错误对应的HTTP状态码，从节点拒绝写入返回403
+func httpStatus(err error) int {
+	if errors.Is(err, storage.ErrReadOnly) {
+		return http.StatusForbidden
+	}
+	if status, ok := httpStatuses[storage.ErrorCode(err)]; ok {
+		return status
+	}
+	return http.StatusInternalServerError
+}
+
+// errorBody 返回错误信封，prefix为错误信息的前缀，值已被淘汰时带有evicted字段
+func errorBody(prefix string, err error) gin.H {
+	code := storage.ErrorCode(err)
+	body := gin.H{
+		"error": prefix + err.Error(),
+		"code":  code,
+	}
+	if code == storage.CodeEvicted {
+		body["evicted"] = true
+	}
+	return body
+}
+
+// writeError 以错误信封返回失败的请求，状态码由错误类别决定，键属于其他节点时返回421
+func writeError(c *gin.Context, prefix string, err error) {
+	if writeRedirect(c, err) {
+		return
+	}
+	c.JSON(httpStatus(err), errorBody(prefix, err))
+}
+
+// badRequest 以错误信封返回参数不合法的请求
+func badRequest(c *gin.Context, msg string) {
+	c.JSON(http.StatusBadRequest, gin.H{
+		"error": msg,
+		"code":  storage.CodeInvalidArgument,
+	})
+}
@@ -3,9 +3,13 @@ package api
 import (
 	"context"
 	"encoding/json"
+	"time"
 
 	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
 
+	"kvcache/config"
 	"kvcache/proto"
 	"kvcache/service"
 )
@@ -32,13 +36,15 @@ func (s *GRPCServer) Register(srv *grpc.Server) {
 
 // Set 设置键值对
 func (s *GRPCServer) Set(ctx context.Context, req *proto.SetRequest) (*proto.SetResponse, error) {
+	ctx = service.WithNamespace(ctx, req.Namespace)
+
 	if len(req.Key) == 0 {
-		return &proto.SetResponse{Success: false, Error: "empty key"}, nil
+		return nil, invalidArgument("empty key")
 	}
 
-	err := s.service.Set(ctx, string(req.Key), req.Value, 0)
+	err := s.service.Set(ctx, string(req.Key), req.Value, time.Duration(req.Ttl)*time.Second)
 	if err != nil {
-		return &proto.SetResponse{Success: false, Error: err.Error()}, nil
+		return nil, grpcError(err)
 	}
 
 	return &proto.SetResponse{Success: true}, nil
@@ -46,13 +52,15 @@ func (s *GRPCServer) Set(ctx context.Context, req *proto.SetRequest) (*proto.Set
 
 // Get 获取值
 func (s *GRPCServer) Get(ctx context.Context, req *proto.GetRequest) (*proto.GetResponse, error) {
+	ctx = service.WithNamespace(ctx, req.Namespace)
+
 	if len(req.Key) == 0 {
-		return &proto.GetResponse{Found: false, Error: "empty key"}, nil
+		return nil, invalidArgument("empty key")
 	}
 
 	value, err := s.service.Get(ctx, string(req.Key))
 	if err != nil {
-		return &proto.GetResponse{Found: false, Error: err.Error()}, nil
+		return nil, grpcError(err)
 	}
 
 	return &proto.GetResponse{Value: value, Found: true}, nil
@@ -60,13 +68,15 @@ func (s *GRPCServer) Get(ctx context.Context, req *proto.GetRequest) (*proto.Get
 
 // Delete 删除键值对
 func (s *GRPCServer) Delete(ctx context.Context, req *proto.DeleteRequest) (*proto.DeleteResponse, error) {
+	ctx = service.WithNamespace(ctx, req.Namespace)
+
 	if len(req.Key) == 0 {
-		return &proto.DeleteResponse{Success: false, Error: "empty key"}, nil
+		return nil, invalidArgument("empty key")
 	}
 
 	err := s.service.Delete(ctx, string(req.Key))
 	if err != nil {
-		return &proto.DeleteResponse{Success: false, Error: err.Error()}, nil
+		return nil, grpcError(err)
 	}
 
 	return &proto.DeleteResponse{Success: true}, nil
@@ -74,9 +84,11 @@ func (s *GRPCServer) Delete(ctx context.Context, req *proto.DeleteRequest) (*pro
 
 // ScanKeys 扫描键
 func (s *GRPCServer) ScanKeys(ctx context.Context, req *proto.ScanRequest) (*proto.ScanKeysResponse, error) {
-	results, err := s.service.Scan(ctx, string(req.Prefix), 100)
+	ctx = service.WithNamespace(ctx, req.Namespace)
+
+	results, err := s.service.Scan(ctx, string(req.Prefix), scanLimit(req.Limit))
 	if err != nil {
-		return &proto.ScanKeysResponse{Error: err.Error()}, nil
+		return nil, grpcError(err)
 	}
 
 	// 转换为[]byte类型的keys
@@ -90,23 +102,201 @@ func (s *GRPCServer) ScanKeys(ctx context.Context, req *proto.ScanRequest) (*pro
 
 // ScanKeyValues 扫描键值对
 func (s *GRPCServer) ScanKeyValues(ctx context.Context, req *proto.ScanRequest) (*proto.ScanKeyValuesResponse, error) {
-	results, err := s.service.Scan(ctx, string(req.Prefix), 100)
+	ctx = service.WithNamespace(ctx, req.Namespace)
+
+	results, err := s.service.Scan(ctx, string(req.Prefix), scanLimit(req.Limit))
 	if err != nil {
-		return &proto.ScanKeyValuesResponse{Error: err.Error()}, nil
+		return nil, grpcError(err)
 	}
 
 	return &proto.ScanKeyValuesResponse{KeyValues: results}, nil
 }
 
+// scanLimit 返回扫描的最大数量，未指定时为100
+func scanLimit(limit int32) int {
+	if limit <= 0 {
+		return 100
+	}
+	return int(limit)
+}
+
+// Append 向值末尾追加数据
+func (s *GRPCServer) Append(ctx context.Context, req *proto.AppendRequest) (*proto.AppendResponse, error) {
+	ctx = service.WithNamespace(ctx, req.Namespace)
+
+	if len(req.Key) == 0 {
+		return nil, invalidArgument("empty key")
+	}
+
+	err := s.service.Append(ctx, string(req.Key), req.Data)
+	if err != nil {
+		return nil, grpcError(err)
+	}
+
+	return &proto.AppendResponse{Success: true}, nil
+}
+
+// WriteAt 在值的指定偏移量写入数据
+func (s *GRPCServer) WriteAt(ctx context.Context, req *proto.WriteAtRequest) (*proto.WriteAtResponse, error) {
+	ctx = service.WithNamespace(ctx, req.Namespace)
+
+	if len(req.Key) == 0 {
+		return nil, invalidArgument("empty key")
+	}
+
+	err := s.service.WriteAt(ctx, string(req.Key), req.Offset, req.Data)
+	if err != nil {
+		return nil, grpcError(err)
+	}
+
+	return &proto.WriteAtResponse{Success: true}, nil
+}
+
+// Rename 重命名键
+func (s *GRPCServer) Rename(ctx context.Context, req *proto.RenameRequest) (*proto.RenameResponse, error) {
+	ctx = service.WithNamespace(ctx, req.Namespace)
+
+	if len(req.Src) == 0 || len(req.Dst) == 0 {
+		return nil, invalidArgument("empty key")
+	}
+
+	err := s.service.Rename(ctx, string(req.Src), string(req.Dst), req.Overwrite)
+	if err != nil {
+		return nil, grpcError(err)
+	}
+
+	return &proto.RenameResponse{Success: true}, nil
+}
+
+// Copy 复制键
+func (s *GRPCServer) Copy(ctx context.Context, req *proto.CopyRequest) (*proto.CopyResponse, error) {
+	ctx = service.WithNamespace(ctx, req.Namespace)
+
+	if len(req.Src) == 0 || len(req.Dst) == 0 {
+		return nil, invalidArgument("empty key")
+	}
+
+	err := s.service.Copy(ctx, string(req.Src), string(req.Dst))
+	if err != nil {
+		return nil, grpcError(err)
+	}
+
+	return &proto.CopyResponse{Success: true}, nil
+}
+
+// GetMeta 获取键的元数据
+func (s *GRPCServer) GetMeta(ctx context.Context, req *proto.GetMetaRequest) (*proto.GetMetaResponse, error) {
+	ctx = service.WithNamespace(ctx, req.Namespace)
+
+	if len(req.Key) == 0 {
+		return nil, invalidArgument("empty key")
+	}
+
+	info, err := s.service.Stat(ctx, string(req.Key))
+	if err != nil {
+		return nil, grpcError(err)
+	}
+
+	return &proto.GetMetaResponse{
+		Found: true,
+		Meta: &proto.KeyMeta{
+			Size:       info.Size,
+			CreateTime: info.CreatedAt,
+			UpdateTime: info.UpdatedAt,
+			LastAccess: info.LastAccess,
+			Version:    info.Version,
+			Ttl:        ttlSeconds(info.TTL),
+			Location:   info.Location,
+			DiskPath:   info.DiskPath,
+			Codec:      info.Codec,
+			Encryption: info.Encryption,
+			Evicted:    info.Evicted,
+			EvictedAt:  info.EvictedAt,
+		},
+	}, nil
+}
+
+// Exists 判断键是否存在
+func (s *GRPCServer) Exists(ctx context.Context, req *proto.ExistsRequest) (*proto.ExistsResponse, error) {
+	ctx = service.WithNamespace(ctx, req.Namespace)
+
+	if len(req.Key) == 0 {
+		return nil, invalidArgument("empty key")
+	}
+
+	exists, err := s.service.Exists(ctx, string(req.Key))
+	if err != nil {
+		return nil, grpcError(err)
+	}
+
+	return &proto.ExistsResponse{Exists: exists}, nil
+}
+
+// MExists 批量判断键是否存在
+func (s *GRPCServer) MExists(ctx context.Context, req *proto.MExistsRequest) (*proto.MExistsResponse, error) {
+	ctx = service.WithNamespace(ctx, req.Namespace)
+
+	keys := make([]string, len(req.Keys))
+	for i, key := range req.Keys {
+		keys[i] = string(key)
+	}
+
+	results, err := s.service.MExists(ctx, keys)
+	if err != nil {
+		return nil, grpcError(err)
+	}
+
+	return &proto.MExistsResponse{Results: results}, nil
+}
+
+// CountPrefix 统计前缀下的键数量
+func (s *GRPCServer) CountPrefix(ctx context.Context, req *proto.CountPrefixRequest) (*proto.CountPrefixResponse, error) {
+	ctx = service.WithNamespace(ctx, req.Namespace)
+
+	count, err := s.service.CountPrefix(ctx, string(req.Prefix), req.Exact)
+	if err != nil {
+		return nil, grpcError(err)
+	}
+
+	return &proto.CountPrefixResponse{Count: count}, nil
+}
+
+// SizeOf 统计前缀下键的数量和容量
+func (s *GRPCServer) SizeOf(ctx context.Context, req *proto.SizeOfRequest) (*proto.SizeOfResponse, error) {
+	ctx = service.WithNamespace(ctx, req.Namespace)
+
+	size, err := s.service.SizeOf(ctx, string(req.Prefix))
+	if err != nil {
+		return nil, grpcError(err)
+	}
+
+	return &proto.SizeOfResponse{
+		Keys:        size.Keys,
+		InlineBytes: size.InlineBytes,
+		DiskBytes:   size.DiskBytes,
+		TotalBytes:  size.TotalBytes(),
+	}, nil
+}
+
+// ttlSeconds 将剩余存活时间转换为秒数（向上取整），永不过期时返回-1
+func ttlSeconds(ttl time.Duration) int64 {
+	if ttl < 0 {
+		return -1
+	}
+	return int64((ttl + time.Second - 1) / time.Second)
+}
+
 // MSet 批量设置键值对
 func (s *GRPCServer) MSet(ctx context.Context, req *proto.MSetRequest) (*proto.MSetResponse, error) {
+	ctx = service.WithNamespace(ctx, req.Namespace)
+
 	if len(req.KeyValues) == 0 {
-		return &proto.MSetResponse{Success: false, Error: "empty key-value pairs"}, nil
+		return nil, invalidArgument("empty key-value pairs")
 	}
 
-	err := s.service.MSet(ctx, req.KeyValues, 0)
+	err := s.service.MSet(ctx, req.KeyValues, time.Duration(req.Ttl)*time.Second)
 	if err != nil {
-		return &proto.MSetResponse{Success: false, Error: err.Error()}, nil
+		return nil, grpcError(err)
 	}
 
 	return &proto.MSetResponse{Success: true}, nil
@@ -114,8 +304,10 @@ func (s *GRPCServer) MSet(ctx context.Context, req *proto.MSetRequest) (*proto.M
 
 // MGet 批量获取值
 func (s *GRPCServer) MGet(ctx context.Context, req *proto.MGetRequest) (*proto.MGetResponse, error) {
+	ctx = service.WithNamespace(ctx, req.Namespace)
+
 	if len(req.Keys) == 0 {
-		return &proto.MGetResponse{Error: "empty keys"}, nil
+		return nil, invalidArgument("empty keys")
 	}
 
 	// 转换为[]string类型的keys
@@ -126,7 +318,7 @@ func (s *GRPCServer) MGet(ctx context.Context, req *proto.MGetRequest) (*proto.M
 
 	results, err := s.service.MGet(ctx, keys)
 	if err != nil {
-		return &proto.MGetResponse{Error: err.Error()}, nil
+		return nil, grpcError(err)
 	}
 
 	return &proto.MGetResponse{KeyValues: results}, nil
@@ -134,8 +326,10 @@ func (s *GRPCServer) MGet(ctx context.Context, req *proto.MGetRequest) (*proto.M
 
 // MDelete 批量删除键值对
 func (s *GRPCServer) MDelete(ctx context.Context, req *proto.MDeleteRequest) (*proto.MDeleteResponse, error) {
+	ctx = service.WithNamespace(ctx, req.Namespace)
+
 	if len(req.Keys) == 0 {
-		return &proto.MDeleteResponse{Success: false, Error: "empty keys"}, nil
+		return nil, invalidArgument("empty keys")
 	}
 
 	// 转换为[]string类型的keys
@@ -146,22 +340,173 @@ func (s *GRPCServer) MDelete(ctx context.Context, req *proto.MDeleteRequest) (*p
 
 	err := s.service.MDelete(ctx, keys)
 	if err != nil {
-		return &proto.MDeleteResponse{Success: false, Error: err.Error()}, nil
+		return nil, grpcError(err)
 	}
 
 	return &proto.MDeleteResponse{Success: true}, nil
 }
 
+// DeletePrefix 删除前缀下的所有键，需要确认
+func (s *GRPCServer) DeletePrefix(ctx context.Context, req *proto.DeletePrefixRequest) (*proto.DeletePrefixResponse, error) {
+	ctx = service.WithNamespace(ctx, req.Namespace)
+
+	if !req.Confirm {
+		return nil, invalidArgument("confirmation required")
+	}
+
+	jobID, err := s.service.DeletePrefix(ctx, string(req.Prefix))
+	if err != nil {
+		return nil, grpcError(err)
+	}
+
+	return &proto.DeletePrefixResponse{JobId: jobID}, nil
+}
+
+// DeleteRange 删除范围内的所有键，需要确认
+func (s *GRPCServer) DeleteRange(ctx context.Context, req *proto.DeleteRangeRequest) (*proto.DeleteRangeResponse, error) {
+	ctx = service.WithNamespace(ctx, req.Namespace)
+
+	if !req.Confirm {
+		return nil, invalidArgument("confirmation required")
+	}
+
+	jobID, err := s.service.DeleteRange(ctx, string(req.Start), string(req.End))
+	if err != nil {
+		return nil, grpcError(err)
+	}
+
+	return &proto.DeleteRangeResponse{JobId: jobID}, nil
+}
+
+// GetDeleteJob 查询范围删除任务的进度
+func (s *GRPCServer) GetDeleteJob(ctx context.Context, req *proto.GetDeleteJobRequest) (*proto.GetDeleteJobResponse, error) {
+	job, err := s.service.DeleteJob(ctx, req.JobId)
+	if err != nil {
+		return nil, grpcError(err)
+	}
+
+	resp := &proto.GetDeleteJobResponse{
+		Found: true,
+		Job: &proto.DeleteJob{
+			Id:            job.ID,
+			Start:         job.Start,
+			End:           job.End,
+			State:         job.State,
+			KeysDeleted:   job.KeysDeleted,
+			BlobsReleased: job.BlobsReleased,
+			StartedAt:     job.StartedAt.Unix(),
+			Error:         job.Error,
+		},
+	}
+	if !job.FinishedAt.IsZero() {
+		resp.Job.FinishedAt = job.FinishedAt.Unix()
+	}
+
+	return resp, nil
+}
+
+// Watch 按提交顺序推送前缀下的变更，流式接口通过gRPC状态码返回错误
+func (s *GRPCServer) Watch(req *proto.WatchRequest, stream proto.KeyValueService_WatchServer) error {
+	ctx := service.WithNamespace(stream.Context(), req.Namespace)
+	watcher, err := s.service.Watch(ctx, req.Prefix, req.FromRevision)
+	if err != nil {
+		return grpcError(err)
+	}
+
+	// 订阅注册后立即发送响应头，客户端收到响应头后的写入都不会遗漏
+	if err := stream.SendHeader(nil); err != nil {
+		return err
+	}
+
+	for event := range watcher.Events {
+		if err := stream.Send(&proto.WatchEvent{
+			Revision: event.Revision,
+			Type:     event.Type,
+			Key:      event.Key,
+			End:      event.End,
+			Version:  event.Version,
+			Size:     event.Size,
+			Time:     event.Time,
+		}); err != nil {
+			return err
+		}
+	}
+
+	// 事件通道关闭且不是客户端取消时，告知客户端从最后收到的修订号重新订阅
+	if err := watcher.Err(); err != nil {
+		return status.Error(codes.Aborted, err.Error())
+	}
+	return ctx.Err()
+}
+
+// Promote 将从节点提升为主节点
+func (s *GRPCServer) Promote(ctx context.Context, req *proto.PromoteRequest) (*proto.PromoteResponse, error) {
+	if err := s.service.Promote(ctx); err != nil {
+		return nil, grpcError(err)
+	}
+	return &proto.PromoteResponse{Success: true}, nil
+}
+
+// SetVersioned 时间戳较新时设置键值对
+func (s *GRPCServer) SetVersioned(ctx context.Context, req *proto.SetVersionedRequest) (*proto.SetVersionedResponse, error) {
+	ctx = service.WithNamespace(ctx, req.Namespace)
+
+	if len(req.Key) == 0 {
+		return nil, invalidArgument("empty key")
+	}
+
+	applied, err := s.service.SetVersioned(ctx, string(req.Key), req.Value, time.Duration(req.Ttl)*time.Second, req.Stamp)
+	if err != nil {
+		return nil, grpcError(err)
+	}
+	return &proto.SetVersionedResponse{Success: true, Applied: applied}, nil
+}
+
+// DeleteVersioned 时间戳较新时删除键
+func (s *GRPCServer) DeleteVersioned(ctx context.Context, req *proto.DeleteVersionedRequest) (*proto.DeleteVersionedResponse, error) {
+	ctx = service.WithNamespace(ctx, req.Namespace)
+
+	if len(req.Key) == 0 {
+		return nil, invalidArgument("empty key")
+	}
+
+	applied, err := s.service.DeleteVersioned(ctx, string(req.Key), req.Stamp)
+	if err != nil {
+		return nil, grpcError(err)
+	}
+	return &proto.DeleteVersionedResponse{Success: true, Applied: applied}, nil
+}
+
+// GetVersioned 获取值和时间戳
+func (s *GRPCServer) GetVersioned(ctx context.Context, req *proto.GetVersionedRequest) (*proto.GetVersionedResponse, error) {
+	ctx = service.WithNamespace(ctx, req.Namespace)
+
+	if len(req.Key) == 0 {
+		return nil, invalidArgument("empty key")
+	}
+
+	result, err := s.service.GetVersioned(ctx, string(req.Key))
+	if err != nil {
+		return nil, grpcError(err)
+	}
+	resp := &proto.GetVersionedResponse{Value: result.Value, Found: result.Found, Stamp: result.Stamp}
+	if result.TTL > 0 {
+		// 向上取整到秒，不足一秒的剩余时间不会变为永不过期
+		resp.Ttl = int64((result.TTL + time.Second - 1) / time.Second)
+	}
+	return resp, nil
+}
+
 // GetConfig 获取配置
 func (s *GRPCServer) GetConfig(ctx context.Context, req *proto.GetConfigRequest) (*proto.GetConfigResponse, error) {
 	config, err := s.service.GetConfig(ctx)
 	if err != nil {
-		return &proto.GetConfigResponse{Error: err.Error()}, nil
+		return nil, grpcError(err)
 	}
 
 	configJSON, err := json.Marshal(config)
 	if err != nil {
-		return &proto.GetConfigResponse{Error: err.Error()}, nil
+		return nil, grpcError(err)
 	}
 
 	return &proto.GetConfigResponse{Config: string(configJSON)}, nil
@@ -186,16 +531,21 @@ func (s *GRPCServer) UpdateConfig(ctx context.Context, req *proto.UpdateConfigRe
 			CheckInterval      int     `json:"check_interval"`
 			BatchSize          int     `json:"batch_size"`
 		} `json:"eviction"`
+
+		Cache struct {
+			SizeThreshold int   `json:"size_threshold"`
+			MaxBytes      int64 `json:"max_bytes"`
+		} `json:"cache"`
 	}
 
 	if err := json.Unmarshal([]byte(req.Config), &config); err != nil {
-		return &proto.UpdateConfigResponse{Success: false, Error: err.Error()}, nil
+		return nil, invalidArgument("invalid config: " + err.Error())
 	}
 
 	// 获取当前配置
 	currentConfig, err := s.service.GetConfig(ctx)
 	if err != nil {
-		return &proto.UpdateConfigResponse{Success: false, Error: err.Error()}, nil
+		return nil, grpcError(err)
 	}
 
 	// 更新配置
@@ -223,15 +573,140 @@ func (s *GRPCServer) UpdateConfig(ctx context.Context, req *proto.UpdateConfigRe
 	if config.Eviction.BatchSize > 0 {
 		currentConfig.Eviction.BatchSize = config.Eviction.BatchSize
 	}
+	if config.Cache.SizeThreshold > 0 {
+		currentConfig.Cache.SizeThreshold = config.Cache.SizeThreshold
+	}
+	if config.Cache.MaxBytes > 0 {
+		currentConfig.Cache.MaxBytes = config.Cache.MaxBytes
+	}
 
 	err = s.service.UpdateConfig(ctx, currentConfig)
 	if err != nil {
-		return &proto.UpdateConfigResponse{Success: false, Error: err.Error()}, nil
+		return nil, grpcError(err)
 	}
 
 	return &proto.UpdateConfigResponse{Success: true}, nil
 }
 
+// CreateNamespace 创建命名空间
+func (s *GRPCServer) CreateNamespace(ctx context.Context, req *proto.CreateNamespaceRequest) (*proto.CreateNamespaceResponse, error) {
+	if req.Namespace == nil {
+		return nil, invalidArgument("empty namespace")
+	}
+
+	nsCfg := &config.NamespaceConfig{
+		Name:       req.Namespace.Name,
+		DefaultTTL: req.Namespace.DefaultTtl,
+	}
+	if req.Namespace.Cache != "" {
+		nsCfg.Cache = &config.CacheConfig{}
+		if err := json.Unmarshal([]byte(req.Namespace.Cache), nsCfg.Cache); err != nil {
+			return nil, invalidArgument("invalid config: " + err.Error())
+		}
+	}
+	if req.Namespace.Eviction != "" {
+		nsCfg.Eviction = &config.EvictionConfig{}
+		if err := json.Unmarshal([]byte(req.Namespace.Eviction), nsCfg.Eviction); err != nil {
+			return nil, invalidArgument("invalid config: " + err.Error())
+		}
+	}
+
+	if err := s.service.CreateNamespace(ctx, nsCfg); err != nil {
+		return nil, grpcError(err)
+	}
+
+	return &proto.CreateNamespaceResponse{Success: true}, nil
+}
+
+// DropNamespace 删除命名空间及其全部数据
+func (s *GRPCServer) DropNamespace(ctx context.Context, req *proto.DropNamespaceRequest) (*proto.DropNamespaceResponse, error) {
+	if err := s.service.DropNamespace(ctx, req.Name); err != nil {
+		return nil, grpcError(err)
+	}
+
+	return &proto.DropNamespaceResponse{Success: true}, nil
+}
+
+// ListNamespaces 列出所有命名空间
+func (s *GRPCServer) ListNamespaces(ctx context.Context, req *proto.ListNamespacesRequest) (*proto.ListNamespacesResponse, error) {
+	namespaces, err := s.service.ListNamespaces(ctx)
+	if err != nil {
+		return nil, grpcError(err)
+	}
+
+	resp := &proto.ListNamespacesResponse{}
+	for _, nsCfg := range namespaces {
+		ns := &proto.Namespace{Name: nsCfg.Name, DefaultTtl: nsCfg.DefaultTTL}
+		if nsCfg.Cache != nil {
+			data, _ := json.Marshal(nsCfg.Cache)
+			ns.Cache = string(data)
+		}
+		if nsCfg.Eviction != nil {
+			data, _ := json.Marshal(nsCfg.Eviction)
+			ns.Eviction = string(data)
+		}
+		resp.Namespaces = append(resp.Namespaces, ns)
+	}
+
+	return resp, nil
+}
+
+// SetQuota 设置配额
+func (s *GRPCServer) SetQuota(ctx context.Context, req *proto.SetQuotaRequest) (*proto.SetQuotaResponse, error) {
+	ctx = service.WithNamespace(ctx, req.Namespace)
+
+	err := s.service.SetQuota(ctx, &config.QuotaConfig{
+		Prefix:         req.Prefix,
+		MaxKeys:        req.MaxKeys,
+		MaxInlineBytes: req.MaxInlineBytes,
+		MaxDiskBytes:   req.MaxDiskBytes,
+		Action:         req.Action,
+	})
+	if err != nil {
+		return nil, grpcError(err)
+	}
+
+	return &proto.SetQuotaResponse{Success: true}, nil
+}
+
+// DeleteQuota 删除配额
+func (s *GRPCServer) DeleteQuota(ctx context.Context, req *proto.DeleteQuotaRequest) (*proto.DeleteQuotaResponse, error) {
+	ctx = service.WithNamespace(ctx, req.Namespace)
+
+	if err := s.service.DeleteQuota(ctx, req.Prefix); err != nil {
+		return nil, grpcError(err)
+	}
+
+	return &proto.DeleteQuotaResponse{Success: true}, nil
+}
+
+// ListQuotas 列出配额及当前用量
+func (s *GRPCServer) ListQuotas(ctx context.Context, req *proto.ListQuotasRequest) (*proto.ListQuotasResponse, error) {
+	ctx = service.WithNamespace(ctx, req.Namespace)
+
+	quotas, err := s.service.ListQuotas(ctx)
+	if err != nil {
+		return nil, grpcError(err)
+	}
+
+	resp := &proto.ListQuotasResponse{}
+	for _, q := range quotas {
+		resp.Quotas = append(resp.Quotas, &proto.QuotaStatus{
+			Namespace:      q.Namespace,
+			Prefix:         q.Prefix,
+			MaxKeys:        q.MaxKeys,
+			MaxInlineBytes: q.MaxInlineBytes,
+			MaxDiskBytes:   q.MaxDiskBytes,
+			Action:         q.Action,
+			Keys:           q.Usage.Keys,
+			InlineBytes:    q.Usage.InlineBytes,
+			DiskBytes:      q.Usage.DiskBytes,
+		})
+	}
+
+	return resp, nil
+}
+
 // Check 健康检查
 func (s *GRPCServer) Check(ctx context.Context, req *proto.HealthCheckRequest) (*proto.HealthCheckResponse, error) {
 	err := s.service.HealthCheck(ctx)
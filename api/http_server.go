@@ -1,19 +1,28 @@
 package api
 
 import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
 	"net/http"
 	"strconv"
 	"time"
 
 	"github.com/gin-gonic/gin"
 
+	"kvcache/config"
+	"kvcache/proxy"
 	"kvcache/service"
+	"kvcache/storage"
 )
 
 // HTTPServer HTTP服务器
 type HTTPServer struct {
 	service *service.KVService
 	router  *gin.Engine
+	proxy   *proxy.Proxy // 缓存反向代理，未启用时为nil
 }
 
 // NewHTTPServer 创建新的HTTP服务器实例
@@ -43,24 +52,88 @@ func (s *HTTPServer) setupRoutes() {
 	s.router.POST("/api/v1/set", s.Set)
 	s.router.GET("/api/v1/get/:key", s.Get)
 	s.router.DELETE("/api/v1/delete/:key", s.Delete)
+	s.router.HEAD("/api/v1/keys/:key", s.Stat)
+	s.router.GET("/api/v1/exists/:key", s.Exists)
+	s.router.POST("/api/v1/mexists", s.MExists)
+	s.router.GET("/api/v1/count", s.CountPrefix)
+	s.router.GET("/api/v1/size", s.SizeOf)
 	s.router.GET("/api/v1/scan", s.Scan)
+	s.router.POST("/api/v1/append", s.Append)
+	s.router.POST("/api/v1/writeat", s.WriteAt)
+	s.router.POST("/api/v1/rename", s.Rename)
+	s.router.POST("/api/v1/copy", s.Copy)
 	s.router.POST("/api/v1/mset", s.MSet)
 	s.router.POST("/api/v1/mget", s.MGet)
 	s.router.POST("/api/v1/mdelete", s.MDelete)
+	s.router.GET("/api/v1/watch", s.Watch)
+
+	// 管理操作
+	s.router.POST("/api/v1/admin/delete-prefix", s.DeletePrefix)
+	s.router.POST("/api/v1/admin/delete-range", s.DeleteRange)
+	s.router.GET("/api/v1/admin/delete-jobs/:id", s.GetDeleteJob)
+	s.router.GET("/api/v1/admin/quotas", s.ListQuotas)
+	s.router.POST("/api/v1/admin/quotas", s.SetQuota)
+	s.router.DELETE("/api/v1/admin/quotas", s.DeleteQuota)
+	s.router.DELETE("/api/v1/admin/cdc/checkpoints/:name", s.DeleteCheckpoint)
+	s.router.GET("/api/v1/admin/replication", s.ReplicationStatus)
+	s.router.POST("/api/v1/admin/replication/promote", s.Promote)
+	s.router.GET("/api/v1/admin/cluster", s.ClusterStatus)
+	s.router.POST("/api/v1/admin/cluster/members", s.AddMember)
+	s.router.DELETE("/api/v1/admin/cluster/members/:id", s.RemoveMember)
+	s.router.GET("/api/v1/admin/shards", s.ShardingStatus)
+	s.router.GET("/api/v1/admin/shards/migration", s.MigrationStatus)
+	s.router.POST("/api/v1/admin/shards/migration", s.StartMigration)
+	s.router.PUT("/api/v1/admin/shards/migration/rate", s.SetMigrationRate)
+	s.router.DELETE("/api/v1/admin/shards/migration", s.CancelMigration)
+	s.router.POST("/api/v1/admin/proxy/purge", s.PurgeProxy)
 
 	// 配置管理
 	s.router.GET("/api/v1/config", s.GetConfig)
 	s.router.POST("/api/v1/config", s.UpdateConfig)
 
+	// 命名空间管理
+	s.router.GET("/api/v1/namespaces", s.ListNamespaces)
+	s.router.POST("/api/v1/namespaces", s.CreateNamespace)
+	s.router.DELETE("/api/v1/namespaces/:name", s.DropNamespace)
+
 	// 监控指标
 	s.router.GET("/metrics", gin.WrapH(http.DefaultServeMux))
 }
 
+// SetProxy 设置缓存反向代理，用于清除其缓存的响应
+func (s *HTTPServer) SetProxy(p *proxy.Proxy) {
+	s.proxy = p
+}
+
 // Run 启动HTTP服务器
 func (s *HTTPServer) Run(addr string) error {
 	return s.router.Run(addr)
 }
 
+// requestContext 返回携带命名空间的请求上下文，命名空间取自namespace查询参数或X-KV-Namespace请求头
+func requestContext(c *gin.Context) context.Context {
+	name := c.Query("namespace")
+	if name == "" {
+		name = c.GetHeader("X-KV-Namespace")
+	}
+	return service.WithNamespace(c.Request.Context(), name)
+}
+
+// writeRedirect 键属于其他节点时返回421，所属节点通过X-KV-Owner响应头和owner字段给出
+func writeRedirect(c *gin.Context, err error) bool {
+	var redirect *service.RedirectError
+	if !errors.As(err, &redirect) {
+		return false
+	}
+
+	c.Header("X-KV-Owner", redirect.Addr)
+	c.JSON(http.StatusMisdirectedRequest, gin.H{
+		"error": err.Error(),
+		"owner": redirect.Addr,
+	})
+	return true
+}
+
 // HealthCheck 健康检查
 func (s *HTTPServer) HealthCheck(c *gin.Context) {
 	err := s.service.HealthCheck(c.Request.Context())
@@ -87,9 +160,7 @@ func (s *HTTPServer) Set(c *gin.Context) {
 	}
 
 	if err := c.ShouldBindJSON(&req); err != nil {
-		c.JSON(http.StatusBadRequest, gin.H{
-			"error": "invalid request: " + err.Error(),
-		})
+		badRequest(c, "invalid request: "+err.Error())
 		return
 	}
 
@@ -98,11 +169,9 @@ func (s *HTTPServer) Set(c *gin.Context) {
 		ttl = time.Duration(req.TTL) * time.Second
 	}
 
-	err := s.service.Set(c.Request.Context(), req.Key, []byte(req.Value), ttl)
+	err := s.service.Set(requestContext(c), req.Key, []byte(req.Value), ttl)
 	if err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{
-			"error": "failed to set: " + err.Error(),
-		})
+		writeError(c, "failed to set: ", err)
 		return
 	}
 
@@ -116,17 +185,14 @@ func (s *HTTPServer) Set(c *gin.Context) {
 func (s *HTTPServer) Get(c *gin.Context) {
 	key := c.Param("key")
 	if key == "" {
-		c.JSON(http.StatusBadRequest, gin.H{
-			"error": "key is required",
-		})
+		badRequest(c, "key is required")
 		return
 	}
 
-	value, err := s.service.Get(c.Request.Context(), key)
+	value, err := s.service.Get(requestContext(c), key)
 	if err != nil {
-		c.JSON(http.StatusNotFound, gin.H{
-			"error": "key not found: " + err.Error(),
-		})
+		// 值已被淘汰时返回410并带有evicted字段，键和元数据仍然存在
+		writeError(c, "", err)
 		return
 	}
 
@@ -136,21 +202,144 @@ func (s *HTTPServer) Get(c *gin.Context) {
 	})
 }
 
+// Stat 通过响应头返回键的元数据，不传输值本身
+func (s *HTTPServer) Stat(c *gin.Context) {
+	key := c.Param("key")
+	if key == "" {
+		c.Status(http.StatusBadRequest)
+		return
+	}
+
+	info, err := s.service.Stat(requestContext(c), key)
+	if err != nil {
+		if writeRedirect(c, err) {
+			return
+		}
+		c.Header("X-KV-Error", err.Error())
+		c.Header("X-KV-Error-Code", string(storage.ErrorCode(err)))
+		c.Status(httpStatus(err))
+		return
+	}
+
+	c.Header("X-KV-Size", strconv.FormatInt(info.Size, 10))
+	c.Header("X-KV-Create-Time", strconv.FormatInt(info.CreatedAt, 10))
+	c.Header("X-KV-Update-Time", strconv.FormatInt(info.UpdatedAt, 10))
+	c.Header("X-KV-Last-Access", strconv.FormatInt(info.LastAccess, 10))
+	c.Header("X-KV-Version", strconv.FormatUint(info.Version, 10))
+	c.Header("X-KV-TTL", strconv.FormatInt(ttlSeconds(info.TTL), 10))
+	c.Header("X-KV-Location", info.Location)
+	if info.DiskPath != "" {
+		c.Header("X-KV-Disk-Path", info.DiskPath)
+	}
+	c.Header("X-KV-Codec", info.Codec)
+	c.Header("X-KV-Encryption", info.Encryption)
+	c.Header("X-KV-Evicted", strconv.FormatBool(info.Evicted))
+	if info.Evicted {
+		c.Header("X-KV-Evicted-At", strconv.FormatInt(info.EvictedAt, 10))
+	}
+	c.Status(http.StatusOK)
+}
+
+// Exists 判断键是否存在
+func (s *HTTPServer) Exists(c *gin.Context) {
+	key := c.Param("key")
+	if key == "" {
+		badRequest(c, "key cannot be empty")
+		return
+	}
+
+	exists, err := s.service.Exists(requestContext(c), key)
+	if err != nil {
+		writeError(c, "failed to check key: ", err)
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"key":    key,
+		"exists": exists,
+	})
+}
+
+// MExists 批量判断键是否存在
+func (s *HTTPServer) MExists(c *gin.Context) {
+	var req struct {
+		Keys []string `json:"keys" binding:"required"`
+	}
+
+	if err := c.ShouldBindJSON(&req); err != nil {
+		badRequest(c, "invalid request: "+err.Error())
+		return
+	}
+
+	if len(req.Keys) == 0 {
+		badRequest(c, "keys cannot be empty")
+		return
+	}
+
+	results, err := s.service.MExists(requestContext(c), req.Keys)
+	if err != nil {
+		writeError(c, "failed to mexists: ", err)
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"keys":    req.Keys,
+		"results": results,
+	})
+}
+
+// CountPrefix 统计前缀下的键数量，mode=estimated时返回估算值
+func (s *HTTPServer) CountPrefix(c *gin.Context) {
+	prefix := c.Query("prefix")
+	mode := c.DefaultQuery("mode", "exact")
+	if mode != "exact" && mode != "estimated" {
+		badRequest(c, "mode must be exact or estimated")
+		return
+	}
+
+	count, err := s.service.CountPrefix(requestContext(c), prefix, mode == "exact")
+	if err != nil {
+		writeError(c, "failed to count: ", err)
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"prefix": prefix,
+		"mode":   mode,
+		"count":  count,
+	})
+}
+
+// SizeOf 统计前缀下键的数量和容量
+func (s *HTTPServer) SizeOf(c *gin.Context) {
+	prefix := c.Query("prefix")
+
+	size, err := s.service.SizeOf(requestContext(c), prefix)
+	if err != nil {
+		writeError(c, "failed to get size: ", err)
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"prefix":       prefix,
+		"keys":         size.Keys,
+		"inline_bytes": size.InlineBytes,
+		"disk_bytes":   size.DiskBytes,
+		"total_bytes":  size.TotalBytes(),
+	})
+}
+
 // Delete 删除键值对
 func (s *HTTPServer) Delete(c *gin.Context) {
 	key := c.Param("key")
 	if key == "" {
-		c.JSON(http.StatusBadRequest, gin.H{
-			"error": "key is required",
-		})
+		badRequest(c, "key is required")
 		return
 	}
 
-	err := s.service.Delete(c.Request.Context(), key)
+	err := s.service.Delete(requestContext(c), key)
 	if err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{
-			"error": "failed to delete: " + err.Error(),
-		})
+		writeError(c, "failed to delete: ", err)
 		return
 	}
 
@@ -170,11 +359,9 @@ func (s *HTTPServer) Scan(c *gin.Context) {
 		limit = 100
 	}
 
-	results, err := s.service.Scan(c.Request.Context(), prefix, limit)
+	results, err := s.service.Scan(requestContext(c), prefix, limit)
 	if err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{
-			"error": "failed to scan: " + err.Error(),
-		})
+		writeError(c, "failed to scan: ", err)
 		return
 	}
 
@@ -192,6 +379,109 @@ func (s *HTTPServer) Scan(c *gin.Context) {
 	})
 }
 
+// Append 向值末尾追加数据
+func (s *HTTPServer) Append(c *gin.Context) {
+	var req struct {
+		Key   string `json:"key" binding:"required"`
+		Value string `json:"value"`
+	}
+
+	if err := c.ShouldBindJSON(&req); err != nil {
+		badRequest(c, "invalid request: "+err.Error())
+		return
+	}
+
+	err := s.service.Append(requestContext(c), req.Key, []byte(req.Value))
+	if err != nil {
+		writeError(c, "failed to append: ", err)
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"success": true,
+		"message": "value appended successfully",
+	})
+}
+
+// WriteAt 在值的指定偏移量写入数据
+func (s *HTTPServer) WriteAt(c *gin.Context) {
+	var req struct {
+		Key    string `json:"key" binding:"required"`
+		Offset int64  `json:"offset"`
+		Value  string `json:"value"`
+	}
+
+	if err := c.ShouldBindJSON(&req); err != nil {
+		badRequest(c, "invalid request: "+err.Error())
+		return
+	}
+
+	if req.Offset < 0 {
+		badRequest(c, "offset cannot be negative")
+		return
+	}
+
+	err := s.service.WriteAt(requestContext(c), req.Key, req.Offset, []byte(req.Value))
+	if err != nil {
+		writeError(c, "failed to write: ", err)
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"success": true,
+		"message": "value written successfully",
+	})
+}
+
+// Rename 重命名键
+func (s *HTTPServer) Rename(c *gin.Context) {
+	var req struct {
+		Src       string `json:"src" binding:"required"`
+		Dst       string `json:"dst" binding:"required"`
+		Overwrite bool   `json:"overwrite"`
+	}
+
+	if err := c.ShouldBindJSON(&req); err != nil {
+		badRequest(c, "invalid request: "+err.Error())
+		return
+	}
+
+	err := s.service.Rename(requestContext(c), req.Src, req.Dst, req.Overwrite)
+	if err != nil {
+		writeError(c, "failed to rename: ", err)
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"success": true,
+		"message": "key renamed successfully",
+	})
+}
+
+// Copy 复制键
+func (s *HTTPServer) Copy(c *gin.Context) {
+	var req struct {
+		Src string `json:"src" binding:"required"`
+		Dst string `json:"dst" binding:"required"`
+	}
+
+	if err := c.ShouldBindJSON(&req); err != nil {
+		badRequest(c, "invalid request: "+err.Error())
+		return
+	}
+
+	err := s.service.Copy(requestContext(c), req.Src, req.Dst)
+	if err != nil {
+		writeError(c, "failed to copy: ", err)
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"success": true,
+		"message": "key copied successfully",
+	})
+}
+
 // MSet 批量设置键值对
 func (s *HTTPServer) MSet(c *gin.Context) {
 	var req struct {
@@ -200,16 +490,12 @@ func (s *HTTPServer) MSet(c *gin.Context) {
 	}
 
 	if err := c.ShouldBindJSON(&req); err != nil {
-		c.JSON(http.StatusBadRequest, gin.H{
-			"error": "invalid request: " + err.Error(),
-		})
+		badRequest(c, "invalid request: "+err.Error())
 		return
 	}
 
 	if len(req.Kvs) == 0 {
-		c.JSON(http.StatusBadRequest, gin.H{
-			"error": "kvs cannot be empty",
-		})
+		badRequest(c, "kvs cannot be empty")
 		return
 	}
 
@@ -224,11 +510,9 @@ func (s *HTTPServer) MSet(c *gin.Context) {
 		keyValues[k] = []byte(v)
 	}
 
-	err := s.service.MSet(c.Request.Context(), keyValues, ttl)
+	err := s.service.MSet(requestContext(c), keyValues, ttl)
 	if err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{
-			"error": "failed to mset: " + err.Error(),
-		})
+		writeError(c, "failed to mset: ", err)
 		return
 	}
 
@@ -246,24 +530,18 @@ func (s *HTTPServer) MGet(c *gin.Context) {
 	}
 
 	if err := c.ShouldBindJSON(&req); err != nil {
-		c.JSON(http.StatusBadRequest, gin.H{
-			"error": "invalid request: " + err.Error(),
-		})
+		badRequest(c, "invalid request: "+err.Error())
 		return
 	}
 
 	if len(req.Keys) == 0 {
-		c.JSON(http.StatusBadRequest, gin.H{
-			"error": "keys cannot be empty",
-		})
+		badRequest(c, "keys cannot be empty")
 		return
 	}
 
-	results, err := s.service.MGet(c.Request.Context(), req.Keys)
+	results, err := s.service.MGet(requestContext(c), req.Keys)
 	if err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{
-			"error": "failed to mget: " + err.Error(),
-		})
+		writeError(c, "failed to mget: ", err)
 		return
 	}
 
@@ -287,24 +565,18 @@ func (s *HTTPServer) MDelete(c *gin.Context) {
 	}
 
 	if err := c.ShouldBindJSON(&req); err != nil {
-		c.JSON(http.StatusBadRequest, gin.H{
-			"error": "invalid request: " + err.Error(),
-		})
+		badRequest(c, "invalid request: "+err.Error())
 		return
 	}
 
 	if len(req.Keys) == 0 {
-		c.JSON(http.StatusBadRequest, gin.H{
-			"error": "keys cannot be empty",
-		})
+		badRequest(c, "keys cannot be empty")
 		return
 	}
 
-	err := s.service.MDelete(c.Request.Context(), req.Keys)
+	err := s.service.MDelete(requestContext(c), req.Keys)
 	if err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{
-			"error": "failed to mdelete: " + err.Error(),
-		})
+		writeError(c, "failed to mdelete: ", err)
 		return
 	}
 
@@ -315,13 +587,319 @@ func (s *HTTPServer) MDelete(c *gin.Context) {
 	})
 }
 
+// DeletePrefix 删除前缀下的所有键，需要在请求中确认
+func (s *HTTPServer) DeletePrefix(c *gin.Context) {
+	var req struct {
+		Prefix  string `json:"prefix" binding:"required"`
+		Confirm bool   `json:"confirm"`
+	}
+
+	if err := c.ShouldBindJSON(&req); err != nil {
+		badRequest(c, "invalid request: "+err.Error())
+		return
+	}
+
+	if !req.Confirm {
+		badRequest(c, "confirmation required: set confirm to true")
+		return
+	}
+
+	jobID, err := s.service.DeletePrefix(requestContext(c), req.Prefix)
+	if err != nil {
+		writeError(c, "failed to delete prefix: ", err)
+		return
+	}
+
+	c.JSON(http.StatusAccepted, gin.H{
+		"prefix": req.Prefix,
+		"job_id": jobID,
+	})
+}
+
+// DeleteRange 删除[start, end)范围内的所有键，需要在请求中确认
+func (s *HTTPServer) DeleteRange(c *gin.Context) {
+	var req struct {
+		Start   string `json:"start"`
+		End     string `json:"end" binding:"required"`
+		Confirm bool   `json:"confirm"`
+	}
+
+	if err := c.ShouldBindJSON(&req); err != nil {
+		badRequest(c, "invalid request: "+err.Error())
+		return
+	}
+
+	if !req.Confirm {
+		badRequest(c, "confirmation required: set confirm to true")
+		return
+	}
+
+	jobID, err := s.service.DeleteRange(requestContext(c), req.Start, req.End)
+	if err != nil {
+		writeError(c, "failed to delete range: ", err)
+		return
+	}
+
+	c.JSON(http.StatusAccepted, gin.H{
+		"start":  req.Start,
+		"end":    req.End,
+		"job_id": jobID,
+	})
+}
+
+// GetDeleteJob 查询范围删除任务的进度
+func (s *HTTPServer) GetDeleteJob(c *gin.Context) {
+	job, err := s.service.DeleteJob(c.Request.Context(), c.Param("id"))
+	if err != nil {
+		writeError(c, "", err)
+		return
+	}
+
+	resp := gin.H{
+		"id":             job.ID,
+		"start":          string(job.Start),
+		"end":            string(job.End),
+		"state":          job.State,
+		"keys_deleted":   job.KeysDeleted,
+		"blobs_released": job.BlobsReleased,
+		"started_at":     job.StartedAt.Unix(),
+	}
+	if !job.FinishedAt.IsZero() {
+		resp["finished_at"] = job.FinishedAt.Unix()
+	}
+	if job.Error != "" {
+		resp["error"] = job.Error
+	}
+
+	c.JSON(http.StatusOK, resp)
+}
+
+// Watch 以Server-Sent Events推送前缀下的变更，事件ID为修订号
+// 断线重连时浏览器携带的Last-Event-ID优先于from_revision参数
+func (s *HTTPServer) Watch(c *gin.Context) {
+	var fromRevision uint64
+	if lastID := c.GetHeader("Last-Event-ID"); lastID != "" {
+		rev, err := strconv.ParseUint(lastID, 10, 64)
+		if err != nil {
+			badRequest(c, "invalid Last-Event-ID")
+			return
+		}
+		fromRevision = rev + 1
+	} else if from := c.Query("from_revision"); from != "" {
+		rev, err := strconv.ParseUint(from, 10, 64)
+		if err != nil {
+			badRequest(c, "invalid from_revision")
+			return
+		}
+		fromRevision = rev
+	}
+
+	watcher, err := s.service.Watch(requestContext(c), c.Query("prefix"), fromRevision)
+	if err != nil {
+		writeError(c, "failed to watch: ", err)
+		return
+	}
+
+	c.Header("Content-Type", "text/event-stream")
+	c.Header("Cache-Control", "no-cache")
+	c.Header("Connection", "keep-alive")
+	c.Status(http.StatusOK)
+	c.Writer.Flush()
+
+	c.Stream(func(w io.Writer) bool {
+		event, ok := <-watcher.Events
+		if !ok {
+			// 订阅被中断时通知客户端，客户端可从最后收到的修订号重新订阅
+			if err := watcher.Err(); err != nil {
+				fmt.Fprintf(w, "event: error\ndata: %s\n\n", err.Error())
+			}
+			return false
+		}
+
+		data, err := json.Marshal(gin.H{
+			"revision": event.Revision,
+			"type":     event.Type,
+			"key":      string(event.Key),
+			"end":      string(event.End),
+			"version":  event.Version,
+			"size":     event.Size,
+			"time":     event.Time,
+		})
+		if err != nil {
+			return false
+		}
+		fmt.Fprintf(w, "id: %d\nevent: %s\ndata: %s\n\n", event.Revision, event.Type, data)
+		return true
+	})
+}
+
+// DeleteCheckpoint 删除已下线的CDC输出的检查点
+func (s *HTTPServer) DeleteCheckpoint(c *gin.Context) {
+	err := s.service.DeleteCheckpoint(c.Request.Context(), c.Param("name"))
+	if err != nil {
+		writeError(c, "", err)
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"success": true,
+	})
+}
+
+// ReplicationStatus 查询复制状态
+func (s *HTTPServer) ReplicationStatus(c *gin.Context) {
+	c.JSON(http.StatusOK, s.service.ReplicationStatus(c.Request.Context()))
+}
+
+// Promote 将从节点提升为主节点
+func (s *HTTPServer) Promote(c *gin.Context) {
+	if err := s.service.Promote(c.Request.Context()); err != nil {
+		writeError(c, "", err)
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"success": true,
+	})
+}
+
+// ClusterStatus 查询集群状态
+func (s *HTTPServer) ClusterStatus(c *gin.Context) {
+	status, err := s.service.ClusterStatus(c.Request.Context())
+	if err != nil {
+		c.JSON(clusterErrorStatus(err), errorBody("", err))
+		return
+	}
+
+	c.JSON(http.StatusOK, status)
+}
+
+// AddMember 将节点加入集群
+func (s *HTTPServer) AddMember(c *gin.Context) {
+	var req service.ClusterMember
+	if err := c.ShouldBindJSON(&req); err != nil {
+		badRequest(c, "invalid request: "+err.Error())
+		return
+	}
+
+	if err := s.service.AddMember(c.Request.Context(), req); err != nil {
+		c.JSON(clusterErrorStatus(err), errorBody("", err))
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"success": true,
+	})
+}
+
+// RemoveMember 将节点移出集群
+func (s *HTTPServer) RemoveMember(c *gin.Context) {
+	if err := s.service.RemoveMember(c.Request.Context(), c.Param("id")); err != nil {
+		c.JSON(clusterErrorStatus(err), errorBody("", err))
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"success": true,
+	})
+}
+
+// clusterErrorStatus 返回集群管理失败时的状态码，未启用集群模式返回404
+func clusterErrorStatus(err error) int {
+	if errors.Is(err, service.ErrClusterDisabled) {
+		return http.StatusNotFound
+	}
+	return http.StatusConflict
+}
+
+// ShardingStatus 查询分片表，key查询参数非空时返回该键所属的节点
+func (s *HTTPServer) ShardingStatus(c *gin.Context) {
+	status, err := s.service.ShardingStatus(c.Request.Context(), c.Query("key"))
+	if err != nil {
+		c.JSON(http.StatusNotFound, errorBody("", err))
+		return
+	}
+
+	c.JSON(http.StatusOK, status)
+}
+
+// MigrationStatus 查询本节点的迁移进度
+func (s *HTTPServer) MigrationStatus(c *gin.Context) {
+	status, err := s.service.MigrationStatus(c.Request.Context())
+	if err != nil {
+		c.JSON(http.StatusNotFound, errorBody("", err))
+		return
+	}
+
+	c.JSON(http.StatusOK, status)
+}
+
+// StartMigration 开始将本节点的键迁移到新分片表中的所属节点
+func (s *HTTPServer) StartMigration(c *gin.Context) {
+	var req struct {
+		Nodes []string `json:"nodes" binding:"required"`
+		Rate  int64    `json:"rate"` // 每秒传输的字节数上限，0表示不限速
+	}
+	if err := c.ShouldBindJSON(&req); err != nil {
+		badRequest(c, "invalid request: "+err.Error())
+		return
+	}
+
+	if err := s.service.StartMigration(c.Request.Context(), req.Nodes, req.Rate); err != nil {
+		c.JSON(shardingErrorStatus(err), errorBody("", err))
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"success": true,
+	})
+}
+
+// SetMigrationRate 调整迁移的速率上限
+func (s *HTTPServer) SetMigrationRate(c *gin.Context) {
+	var req struct {
+		Rate int64 `json:"rate"`
+	}
+	if err := c.ShouldBindJSON(&req); err != nil {
+		badRequest(c, "invalid request: "+err.Error())
+		return
+	}
+
+	if err := s.service.SetMigrationRate(c.Request.Context(), req.Rate); err != nil {
+		c.JSON(shardingErrorStatus(err), errorBody("", err))
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"success": true,
+	})
+}
+
+// CancelMigration 取消尚未交接所有权的迁移
+func (s *HTTPServer) CancelMigration(c *gin.Context) {
+	if err := s.service.CancelMigration(c.Request.Context()); err != nil {
+		c.JSON(shardingErrorStatus(err), errorBody("", err))
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"success": true,
+	})
+}
+
+// shardingErrorStatus 返回迁移管理失败时的状态码，未启用分片返回404
+func shardingErrorStatus(err error) int {
+	if errors.Is(err, service.ErrShardingDisabled) {
+		return http.StatusNotFound
+	}
+	return http.StatusConflict
+}
+
 // GetConfig 获取配置
 func (s *HTTPServer) GetConfig(c *gin.Context) {
 	config, err := s.service.GetConfig(c.Request.Context())
 	if err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{
-			"error": "failed to get config: " + err.Error(),
-		})
+		writeError(c, "failed to get config: ", err)
 		return
 	}
 
@@ -337,20 +915,18 @@ func (s *HTTPServer) UpdateConfig(c *gin.Context) {
 		MaxDiskUsage          float64 `json:"max_disk_usage"`
 		EvictionCheckInterval int     `json:"eviction_check_interval"`
 		EvictionBatchSize     int     `json:"eviction_batch_size"`
+		CacheSizeThreshold    int     `json:"cache_size_threshold"`
+		CacheMaxBytes         int64   `json:"cache_max_bytes"`
 	}
 
 	if err := c.ShouldBindJSON(&req); err != nil {
-		c.JSON(http.StatusBadRequest, gin.H{
-			"error": "invalid request: " + err.Error(),
-		})
+		badRequest(c, "invalid request: "+err.Error())
 		return
 	}
 
 	config, err := s.service.GetConfig(c.Request.Context())
 	if err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{
-			"error": "failed to get current config: " + err.Error(),
-		})
+		writeError(c, "failed to get current config: ", err)
 		return
 	}
 
@@ -373,12 +949,16 @@ func (s *HTTPServer) UpdateConfig(c *gin.Context) {
 	if req.EvictionBatchSize > 0 {
 		config.Eviction.BatchSize = req.EvictionBatchSize
 	}
+	if req.CacheSizeThreshold > 0 {
+		config.Cache.SizeThreshold = req.CacheSizeThreshold
+	}
+	if req.CacheMaxBytes > 0 {
+		config.Cache.MaxBytes = req.CacheMaxBytes
+	}
 
 	err = s.service.UpdateConfig(c.Request.Context(), config)
 	if err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{
-			"error": "failed to update config: " + err.Error(),
-		})
+		writeError(c, "failed to update config: ", err)
 		return
 	}
 
@@ -388,3 +968,140 @@ func (s *HTTPServer) UpdateConfig(c *gin.Context) {
 		"config":  config,
 	})
 }
+
+// ListNamespaces 列出所有命名空间
+func (s *HTTPServer) ListNamespaces(c *gin.Context) {
+	namespaces, err := s.service.ListNamespaces(c.Request.Context())
+	if err != nil {
+		writeError(c, "", err)
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"namespaces": namespaces,
+	})
+}
+
+// CreateNamespace 创建命名空间
+func (s *HTTPServer) CreateNamespace(c *gin.Context) {
+	var req config.NamespaceConfig
+	if err := c.ShouldBindJSON(&req); err != nil {
+		badRequest(c, "invalid request: "+err.Error())
+		return
+	}
+
+	err := s.service.CreateNamespace(c.Request.Context(), &req)
+	if err != nil {
+		writeError(c, "", err)
+		return
+	}
+
+	c.JSON(http.StatusCreated, gin.H{
+		"success": true,
+	})
+}
+
+// DropNamespace 删除命名空间及其全部数据
+func (s *HTTPServer) DropNamespace(c *gin.Context) {
+	err := s.service.DropNamespace(c.Request.Context(), c.Param("name"))
+	if err != nil {
+		writeError(c, "", err)
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"success": true,
+	})
+}
+
+// ListQuotas 列出配额及当前用量
+func (s *HTTPServer) ListQuotas(c *gin.Context) {
+	quotas, err := s.service.ListQuotas(requestContext(c))
+	if err != nil {
+		writeError(c, "", err)
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"quotas": quotas,
+	})
+}
+
+// SetQuota 设置配额，已存在时替换并重新统计用量
+func (s *HTTPServer) SetQuota(c *gin.Context) {
+	var req config.QuotaConfig
+	if err := c.ShouldBindJSON(&req); err != nil {
+		badRequest(c, "invalid request: "+err.Error())
+		return
+	}
+
+	err := s.service.SetQuota(requestContext(c), &req)
+	if err != nil {
+		writeError(c, "", err)
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"success": true,
+	})
+}
+
+// DeleteQuota 删除配额，前缀通过prefix查询参数指定
+func (s *HTTPServer) DeleteQuota(c *gin.Context) {
+	err := s.service.DeleteQuota(requestContext(c), c.Query("prefix"))
+	if err != nil {
+		writeError(c, "", err)
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"success": true,
+	})
+}
+
+// PurgeProxy 清除缓存反向代理保存的响应，url清除单个请求地址，prefix在后台清除键以其开头的响应
+func (s *HTTPServer) PurgeProxy(c *gin.Context) {
+	if s.proxy == nil {
+		c.JSON(http.StatusNotFound, gin.H{
+			"error": "proxy mode is not enabled",
+			"code":  storage.CodeNotFound,
+		})
+		return
+	}
+
+	var req struct {
+		URL    string `json:"url"`
+		Prefix string `json:"prefix"`
+	}
+	if err := c.ShouldBindJSON(&req); err != nil {
+		badRequest(c, "invalid request: "+err.Error())
+		return
+	}
+	if (req.URL == "") == (req.Prefix == "") {
+		badRequest(c, "exactly one of url and prefix is required")
+		return
+	}
+
+	if req.URL != "" {
+		key, err := s.proxy.Purge(c.Request.Context(), req.URL)
+		if err != nil {
+			writeError(c, "failed to purge: ", err)
+			return
+		}
+		c.JSON(http.StatusOK, gin.H{
+			"key": key,
+		})
+		return
+	}
+
+	jobID, err := s.proxy.PurgePrefix(c.Request.Context(), req.Prefix)
+	if err != nil {
+		writeError(c, "failed to purge prefix: ", err)
+		return
+	}
+	c.JSON(http.StatusAccepted, gin.H{
+		"prefix":    req.Prefix,
+		"namespace": s.proxy.Namespace(),
+		"job_id":    jobID,
+	})
+}
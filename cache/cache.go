@@ -0,0 +1,390 @@
+package cache
+
+import (
+	"container/list"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+)
+
+const (
+	// DefaultMaxBytes 默认的缓存容量
+	DefaultMaxBytes = 64 << 20
+
+	// shardCount 分段数量，每个分段有独立的锁和容量
+	shardCount = 16
+	// entryOverhead 每个条目除键和值以外的估计内存占用
+	entryOverhead = 64
+	// windowPercent 窗口区占总容量的百分比
+	windowPercent = 1
+	// protectedPercent 保护区占主区容量的百分比
+	protectedPercent = 80
+	// sketchBytesPerCounter 每多少字节容量对应一个频率计数
+	sketchBytesPerCounter = 256
+)
+
+// 条目所在的区域
+const (
+	segmentWindow    = iota // 新写入的条目，按LRU淘汰
+	segmentProbation        // 通过准入的条目，再次访问后进入保护区
+	segmentProtected        // 多次访问的条目
+)
+
+// Cache 使用W-TinyLFU策略的分段内存缓存，按字节限制容量
+// 新条目先进入窗口区，离开窗口区时与主区的淘汰候选比较近期访问频率，频率更高者保留
+type Cache struct {
+	shards    [shardCount]*shard
+	maxBytes  atomic.Int64
+	name      string
+	hits      prometheus.Counter
+	misses    prometheus.Counter
+	evictions prometheus.Counter
+	bytes     prometheus.Gauge
+	entries   prometheus.Gauge
+}
+
+// entry 缓存条目
+type entry struct {
+	key       string
+	hash      uint64
+	value     []byte
+	size      int64
+	expiresAt int64 // 过期时间（Unix纳秒），0表示永不过期
+	segment   int
+	elem      *list.Element
+}
+
+// shard 缓存分段
+type shard struct {
+	cache    *Cache
+	mu       sync.Mutex
+	entries  map[string]*entry
+	lists    [3]*list.List
+	bytes    [3]int64
+	capacity int64
+	sketch   *sketch
+}
+
+// New 创建容量为maxBytes的缓存，不大于0时使用默认容量，name用于区分监控指标
+func New(name string, maxBytes int64) *Cache {
+	if maxBytes <= 0 {
+		maxBytes = DefaultMaxBytes
+	}
+
+	c := &Cache{
+		name:      name,
+		hits:      cacheMetrics.hits.WithLabelValues(name),
+		misses:    cacheMetrics.misses.WithLabelValues(name),
+		evictions: cacheMetrics.evictions.WithLabelValues(name),
+		bytes:     cacheMetrics.bytes.WithLabelValues(name),
+		entries:   cacheMetrics.entries.WithLabelValues(name),
+	}
+	c.maxBytes.Store(maxBytes)
+	for i := range c.shards {
+		s := &shard{
+			cache:    c,
+			entries:  make(map[string]*entry),
+			capacity: maxBytes / shardCount,
+			sketch:   newSketch(int(maxBytes / shardCount / sketchBytesPerCounter)),
+		}
+		for j := range s.lists {
+			s.lists[j] = list.New()
+		}
+		c.shards[i] = s
+	}
+	return c
+}
+
+// hashString 计算键的FNV-1a哈希
+func hashString(key string) uint64 {
+	hash := uint64(14695981039346656037)
+	for i := 0; i < len(key); i++ {
+		hash ^= uint64(key[i])
+		hash *= 1099511628211
+	}
+	return hash
+}
+
+// shardFor 返回键所在的分段，使用哈希的高位，低位用于频率草图
+func (c *Cache) shardFor(hash uint64) *shard {
+	return c.shards[hash>>60]
+}
+
+// Get 获取未过期的值
+func (c *Cache) Get(key string) ([]byte, bool) {
+	hash := hashString(key)
+	s := c.shardFor(hash)
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.sketch.increment(hash)
+	e, ok := s.entries[key]
+	if !ok {
+		c.misses.Inc()
+		return nil, false
+	}
+	if e.expired(time.Now()) {
+		s.remove(e)
+		c.misses.Inc()
+		return nil, false
+	}
+
+	s.touch(e)
+	c.hits.Inc()
+	return e.value, true
+}
+
+// Set 写入值，ttl<=0表示永不过期，超过分段容量的值不会被缓存
+func (c *Cache) Set(key string, value []byte, ttl time.Duration) {
+	var expiresAt int64
+	if ttl > 0 {
+		expiresAt = time.Now().Add(ttl).UnixNano()
+	}
+	c.set(key, value, expiresAt)
+}
+
+// set 写入值并指定过期时间
+func (c *Cache) set(key string, value []byte, expiresAt int64) {
+	hash := hashString(key)
+	s := c.shardFor(hash)
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if old, ok := s.entries[key]; ok {
+		s.remove(old)
+	}
+	e := &entry{
+		key:       key,
+		hash:      hash,
+		value:     value,
+		size:      int64(len(key) + len(value) + entryOverhead),
+		expiresAt: expiresAt,
+	}
+	if e.size > s.capacity {
+		return
+	}
+
+	s.sketch.increment(hash)
+	s.entries[key] = e
+	s.cache.entries.Inc()
+	s.link(e, segmentWindow)
+	s.evict()
+}
+
+// Delete 删除值
+func (c *Cache) Delete(key string) {
+	s := c.shardFor(hashString(key))
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if e, ok := s.entries[key]; ok {
+		s.remove(e)
+	}
+}
+
+// DeleteFunc 删除满足条件的键
+func (c *Cache) DeleteFunc(match func(key string) bool) {
+	for _, s := range c.shards {
+		s.mu.Lock()
+		for key, e := range s.entries {
+			if match(key) {
+				s.remove(e)
+			}
+		}
+		s.mu.Unlock()
+	}
+}
+
+// Move 将src的值和过期时间移动到dst，src不在缓存中时删除dst
+func (c *Cache) Move(src, dst string) {
+	s := c.shardFor(hashString(src))
+
+	s.mu.Lock()
+	e, ok := s.entries[src]
+	if ok {
+		s.remove(e)
+	}
+	s.mu.Unlock()
+
+	if !ok || e.expired(time.Now()) {
+		c.Delete(dst)
+		return
+	}
+	c.set(dst, e.value, e.expiresAt)
+}
+
+// Copy 将src的值和过期时间复制到dst，src不在缓存中时删除dst
+func (c *Cache) Copy(src, dst string) {
+	s := c.shardFor(hashString(src))
+
+	s.mu.Lock()
+	e, ok := s.entries[src]
+	var value []byte
+	var expiresAt int64
+	if ok {
+		value, expiresAt = e.value, e.expiresAt
+		ok = !e.expired(time.Now())
+	}
+	s.mu.Unlock()
+
+	if !ok {
+		c.Delete(dst)
+		return
+	}
+	c.set(dst, value, expiresAt)
+}
+
+// Resize 调整缓存容量，不大于0时使用默认容量，超出新容量的条目被淘汰
+func (c *Cache) Resize(maxBytes int64) {
+	if maxBytes <= 0 {
+		maxBytes = DefaultMaxBytes
+	}
+	if c.maxBytes.Swap(maxBytes) == maxBytes {
+		return
+	}
+
+	for _, s := range c.shards {
+		s.mu.Lock()
+		s.capacity = maxBytes / shardCount
+		s.evict()
+		s.mu.Unlock()
+	}
+}
+
+// Clear 删除所有值
+func (c *Cache) Clear() {
+	c.DeleteFunc(func(string) bool { return true })
+}
+
+// Close 删除所有值并移除监控指标
+func (c *Cache) Close() {
+	c.Clear()
+	cacheMetrics.remove(c.name)
+}
+
+// MaxBytes 返回缓存容量
+func (c *Cache) MaxBytes() int64 {
+	return c.maxBytes.Load()
+}
+
+// Len 返回缓存的条目数
+func (c *Cache) Len() int {
+	n := 0
+	for _, s := range c.shards {
+		s.mu.Lock()
+		n += len(s.entries)
+		s.mu.Unlock()
+	}
+	return n
+}
+
+// Bytes 返回缓存条目的估计内存占用
+func (c *Cache) Bytes() int64 {
+	var n int64
+	for _, s := range c.shards {
+		s.mu.Lock()
+		n += s.bytes[segmentWindow] + s.bytes[segmentProbation] + s.bytes[segmentProtected]
+		s.mu.Unlock()
+	}
+	return n
+}
+
+// expired 判断条目是否已过期
+func (e *entry) expired(now time.Time) bool {
+	return e.expiresAt > 0 && now.UnixNano() >= e.expiresAt
+}
+
+// windowCapacity 返回窗口区的容量
+func (s *shard) windowCapacity() int64 {
+	return s.capacity * windowPercent / 100
+}
+
+// mainCapacity 返回主区（试用区和保护区）的容量
+func (s *shard) mainCapacity() int64 {
+	return s.capacity - s.windowCapacity()
+}
+
+// link 将条目加入区域头部，调用方需持有mu
+func (s *shard) link(e *entry, segment int) {
+	e.segment = segment
+	e.elem = s.lists[segment].PushFront(e)
+	s.bytes[segment] += e.size
+	s.cache.bytes.Add(float64(e.size))
+}
+
+// unlink 将条目从所在区域移除，调用方需持有mu
+func (s *shard) unlink(e *entry) {
+	s.lists[e.segment].Remove(e.elem)
+	s.bytes[e.segment] -= e.size
+	s.cache.bytes.Sub(float64(e.size))
+}
+
+// remove 删除条目，调用方需持有mu
+func (s *shard) remove(e *entry) {
+	s.unlink(e)
+	delete(s.entries, e.key)
+	s.cache.entries.Dec()
+}
+
+// touch 记录一次命中，试用区的条目进入保护区，保护区超出容量时最久未访问的条目退回试用区
+func (s *shard) touch(e *entry) {
+	if e.segment != segmentProbation {
+		s.lists[e.segment].MoveToFront(e.elem)
+		return
+	}
+
+	s.unlink(e)
+	s.link(e, segmentProtected)
+	limit := s.mainCapacity() * protectedPercent / 100
+	for s.bytes[segmentProtected] > limit {
+		demoted := s.lists[segmentProtected].Back().Value.(*entry)
+		s.unlink(demoted)
+		s.link(demoted, segmentProbation)
+	}
+}
+
+// victim 返回主区的淘汰候选，优先选择试用区最久未访问的条目
+func (s *shard) victim() *entry {
+	if back := s.lists[segmentProbation].Back(); back != nil {
+		return back.Value.(*entry)
+	}
+	if back := s.lists[segmentProtected].Back(); back != nil {
+		return back.Value.(*entry)
+	}
+	return nil
+}
+
+// evict 窗口区超出容量时将最久未访问的条目交给准入策略，主区超出容量时淘汰候选，调用方需持有mu
+func (s *shard) evict() {
+	for s.bytes[segmentWindow] > s.windowCapacity() {
+		candidate := s.lists[segmentWindow].Back().Value.(*entry)
+		s.unlink(candidate)
+		s.admit(candidate)
+	}
+
+	for s.bytes[segmentProbation]+s.bytes[segmentProtected] > s.mainCapacity() {
+		s.remove(s.victim())
+		s.cache.evictions.Inc()
+	}
+}
+
+// admit 为离开窗口区的条目腾出主区空间，候选的访问频率不高于淘汰候选时丢弃候选
+func (s *shard) admit(candidate *entry) {
+	for s.bytes[segmentProbation]+s.bytes[segmentProtected]+candidate.size > s.mainCapacity() {
+		victim := s.victim()
+		if victim == nil || s.sketch.estimate(candidate.hash) <= s.sketch.estimate(victim.hash) {
+			delete(s.entries, candidate.key)
+			s.cache.entries.Dec()
+			s.cache.evictions.Inc()
+			return
+		}
+		s.remove(victim)
+		s.cache.evictions.Inc()
+	}
+	s.link(candidate, segmentProbation)
+}
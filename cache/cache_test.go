@@ -0,0 +1,122 @@
+package cache
+
+import (
+	"fmt"
+	"testing"
+	"time"
+)
+
+// TestCacheGetSet 测试写入、读取、删除和过期
+func TestCacheGetSet(t *testing.T) {
+	c := New("test-basic", 1<<20)
+	defer c.Close()
+
+	c.Set("a", []byte("1"), 0)
+	if value, ok := c.Get("a"); !ok || string(value) != "1" {
+		t.Fatalf("Expected 1, got %q, %v", value, ok)
+	}
+	c.Set("a", []byte("2"), 0)
+	if value, _ := c.Get("a"); string(value) != "2" {
+		t.Errorf("Expected overwritten value 2, got %q", value)
+	}
+	if c.Len() != 1 {
+		t.Errorf("Expected 1 entry, got %d", c.Len())
+	}
+
+	c.Delete("a")
+	if _, ok := c.Get("a"); ok {
+		t.Errorf("Expected a deleted")
+	}
+
+	// 过期的值视为不存在
+	c.Set("ttl", []byte("v"), 20*time.Millisecond)
+	if _, ok := c.Get("ttl"); !ok {
+		t.Fatalf("Expected ttl before expiry")
+	}
+	time.Sleep(30 * time.Millisecond)
+	if _, ok := c.Get("ttl"); ok {
+		t.Errorf("Expected ttl expired")
+	}
+	if c.Bytes() != 0 {
+		t.Errorf("Expected empty cache, got %d bytes", c.Bytes())
+	}
+}
+
+// TestCacheMoveCopy 测试移动和复制保留过期时间
+func TestCacheMoveCopy(t *testing.T) {
+	c := New("test-move", 1<<20)
+	defer c.Close()
+
+	c.Set("src", []byte("v"), time.Hour)
+	c.Copy("src", "copy")
+	c.Move("src", "dst")
+	if _, ok := c.Get("src"); ok {
+		t.Errorf("Expected src moved")
+	}
+	for _, key := range []string{"copy", "dst"} {
+		if value, ok := c.Get(key); !ok || string(value) != "v" {
+			t.Errorf("Expected v at %s, got %q", key, value)
+		}
+	}
+
+	// 源键不在缓存中时目标键失效
+	c.Copy("missing", "dst")
+	if _, ok := c.Get("dst"); ok {
+		t.Errorf("Expected dst invalidated")
+	}
+
+	c.DeleteFunc(func(key string) bool { return key == "copy" })
+	if c.Len() != 0 {
+		t.Errorf("Expected empty cache, got %d entries", c.Len())
+	}
+}
+
+// TestCacheBudget 测试容量限制，频繁访问的键在大量一次性写入后仍被保留
+func TestCacheBudget(t *testing.T) {
+	const maxBytes = 256 << 10
+	c := New("test-budget", maxBytes)
+	defer c.Close()
+
+	value := make([]byte, 1000)
+	var hot []string
+	for i := 0; i < 32; i++ {
+		key := fmt.Sprintf("hot-%d", i)
+		hot = append(hot, key)
+		c.Set(key, value, 0)
+	}
+	for round := 0; round < 5; round++ {
+		for _, key := range hot {
+			c.Get(key)
+		}
+	}
+
+	// 一次性写入远超容量的键
+	for i := 0; i < 5000; i++ {
+		c.Set(fmt.Sprintf("cold-%d", i), value, 0)
+		if c.Bytes() > maxBytes {
+			t.Fatalf("Cache exceeded budget: %d > %d", c.Bytes(), maxBytes)
+		}
+	}
+
+	kept := 0
+	for _, key := range hot {
+		if _, ok := c.Get(key); ok {
+			kept++
+		}
+	}
+	if kept < len(hot)*3/4 {
+		t.Errorf("Expected most hot keys kept, got %d of %d", kept, len(hot))
+	}
+
+	// 缩小容量后淘汰超出的条目
+	c.Resize(64 << 10)
+	if c.Bytes() > 64<<10 || c.MaxBytes() != 64<<10 {
+		t.Errorf("Expected cache shrunk to 64KB, got %d bytes", c.Bytes())
+	}
+
+	// 超过分段容量的值不缓存
+	c.Set("huge", make([]byte, 64<<10), 0)
+	if _, ok := c.Get("huge"); ok {
+		t.Errorf("Expected value larger than a shard not cached")
+	}
+}
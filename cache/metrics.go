@@ -0,0 +1,61 @@
+package cache
+
+import "github.com/prometheus/client_golang/prometheus"
+
+// metrics 内存缓存监控指标，按命名空间区分
+type metrics struct {
+	hits      *prometheus.CounterVec
+	misses    *prometheus.CounterVec
+	evictions *prometheus.CounterVec
+	bytes     *prometheus.GaugeVec
+	entries   *prometheus.GaugeVec
+}
+
+var cacheMetrics = newMetrics()
+
+// newMetrics 创建并注册缓存监控指标
+func newMetrics() *metrics {
+	m := &metrics{
+		hits: prometheus.NewCounterVec(prometheus.CounterOpts{
+			Namespace: "cachefs",
+			Subsystem: "kv",
+			Name:      "cache_hits_total",
+			Help:      "Total number of memory cache hits",
+		}, []string{"namespace"}),
+		misses: prometheus.NewCounterVec(prometheus.CounterOpts{
+			Namespace: "cachefs",
+			Subsystem: "kv",
+			Name:      "cache_misses_total",
+			Help:      "Total number of memory cache misses",
+		}, []string{"namespace"}),
+		evictions: prometheus.NewCounterVec(prometheus.CounterOpts{
+			Namespace: "cachefs",
+			Subsystem: "kv",
+			Name:      "cache_evictions_total",
+			Help:      "Total number of entries evicted or rejected by the memory cache",
+		}, []string{"namespace"}),
+		bytes: prometheus.NewGaugeVec(prometheus.GaugeOpts{
+			Namespace: "cachefs",
+			Subsystem: "kv",
+			Name:      "cache_bytes",
+			Help:      "Estimated memory used by memory cache entries",
+		}, []string{"namespace"}),
+		entries: prometheus.NewGaugeVec(prometheus.GaugeOpts{
+			Namespace: "cachefs",
+			Subsystem: "kv",
+			Name:      "cache_entries",
+			Help:      "Number of entries in the memory cache",
+		}, []string{"namespace"}),
+	}
+	prometheus.MustRegister(m.hits, m.misses, m.evictions, m.bytes, m.entries)
+	return m
+}
+
+// remove 移除命名空间的监控指标
+func (m *metrics) remove(name string) {
+	m.hits.DeleteLabelValues(name)
+	m.misses.DeleteLabelValues(name)
+	m.evictions.DeleteLabelValues(name)
+	m.bytes.DeleteLabelValues(name)
+	m.entries.DeleteLabelValues(name)
+}
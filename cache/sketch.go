@@ -0,0 +1,67 @@
+package cache
+
+// sketchDepth 计数草图的行数
+const sketchDepth = 4
+
+// sketch 估计键近期访问频率的Count-Min草图，计数上限为15
+// 计数总和达到样本数后所有计数减半，使频率反映近期的访问
+type sketch struct {
+	rows    [sketchDepth][]uint8
+	mask    uint64
+	added   int
+	samples int
+}
+
+// newSketch 创建宽度不小于width的草图，宽度取2的幂
+func newSketch(width int) *sketch {
+	size := 16
+	for size < width {
+		size <<= 1
+	}
+	s := &sketch{mask: uint64(size - 1), samples: size * 10}
+	for i := range s.rows {
+		s.rows[i] = make([]uint8, size)
+	}
+	return s
+}
+
+// index 返回键在第i行的位置
+func (s *sketch) index(hash uint64, i int) uint64 {
+	h := hash + uint64(i)*((hash>>32)|1)
+	return h & s.mask
+}
+
+// increment 记录一次访问
+func (s *sketch) increment(hash uint64) {
+	for i := range s.rows {
+		if j := s.index(hash, i); s.rows[i][j] < 15 {
+			s.rows[i][j]++
+		}
+	}
+
+	s.added++
+	if s.added >= s.samples {
+		s.reset()
+	}
+}
+
+// estimate 返回键的访问频率估计
+func (s *sketch) estimate(hash uint64) uint8 {
+	min := uint8(15)
+	for i := range s.rows {
+		if v := s.rows[i][s.index(hash, i)]; v < min {
+			min = v
+		}
+	}
+	return min
+}
+
+// reset 所有计数减半
+func (s *sketch) reset() {
+	for i := range s.rows {
+		for j := range s.rows[i] {
+			s.rows[i][j] >>= 1
+		}
+	}
+	s.added /= 2
+}
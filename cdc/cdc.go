@@ -0,0 +1,325 @@
+package cdc
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+
+	"kvcache/config"
+	"kvcache/storage"
+)
+
+const (
+	// minRetryDelay 投递失败后的初始重试间隔
+	minRetryDelay = 100 * time.Millisecond
+	// maxRetryDelay 投递失败后的最大重试间隔
+	maxRetryDelay = 30 * time.Second
+)
+
+// Record 投递给外部系统的变更记录，同一修订号可能因重试被投递多次
+type Record struct {
+	Revision  uint64 `json:"revision"`
+	Type      string `json:"type"`
+	Namespace string `json:"namespace"`
+	Key       string `json:"key"`
+	End       string `json:"end,omitempty"`
+	Version   uint64 `json:"version,omitempty"`
+	Size      int64  `json:"size,omitempty"`
+	Time      int64  `json:"time"` // 提交时间（Unix毫秒）
+}
+
+// newRecord 将变更事件转换为投递记录
+func newRecord(event storage.ChangeEvent) Record {
+	return Record{
+		Revision:  event.Revision,
+		Type:      event.Type,
+		Namespace: event.Namespace,
+		Key:       string(event.Key),
+		End:       string(event.End),
+		Version:   event.Version,
+		Size:      event.Size,
+		Time:      event.Time,
+	}
+}
+
+// Sink 变更记录的输出，Write返回nil表示整批记录已持久化或已被对方确认
+type Sink interface {
+	Name() string
+	Write(ctx context.Context, records []Record) error
+	Close() error
+}
+
+// NewSink 根据配置创建输出
+func NewSink(sc *config.CDCSinkConfig) (Sink, error) {
+	if err := sc.Validate(); err != nil {
+		return nil, err
+	}
+
+	switch sc.Type {
+	case config.CDCSinkFile:
+		return NewFileSink(sc.Name, sc.Dir, sc.MaxFileSize, sc.MaxFiles)
+	case config.CDCSinkWebhook:
+		return NewWebhookSink(sc.Name, sc.URL, time.Duration(sc.Timeout)*time.Millisecond, sc.Headers), nil
+	default:
+		return nil, fmt.Errorf("unknown cdc sink type: %s", sc.Type)
+	}
+}
+
+// Status 输出的投递进度
+type Status struct {
+	Sink       string    `json:"sink"`
+	Checkpoint uint64    `json:"checkpoint"` // 已确认投递的最大修订号
+	Delivered  int64     `json:"delivered"`
+	LastError  string    `json:"last_error,omitempty"`
+	LastErrAt  time.Time `json:"last_error_at"`
+}
+
+// Pipeline 从变更日志读取事件投递到一个输出，成功后保存检查点，保证至少投递一次
+type Pipeline struct {
+	store        storage.Storage
+	sink         Sink
+	batchSize    int
+	pollInterval time.Duration
+
+	mu     sync.Mutex
+	status Status
+
+	ctx    context.Context
+	cancel context.CancelFunc
+	done   chan struct{}
+}
+
+// NewPipeline 创建投递流程
+func NewPipeline(store storage.Storage, sink Sink, batchSize int, pollInterval time.Duration) *Pipeline {
+	if batchSize <= 0 {
+		batchSize = 500
+	}
+	if pollInterval <= 0 {
+		pollInterval = time.Second
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	return &Pipeline{
+		store:        store,
+		sink:         sink,
+		batchSize:    batchSize,
+		pollInterval: pollInterval,
+		status:       Status{Sink: sink.Name()},
+		ctx:          ctx,
+		cancel:       cancel,
+		done:         make(chan struct{}),
+	}
+}
+
+// Start 在后台开始投递
+func (p *Pipeline) Start() {
+	go p.run()
+}
+
+// Stop 停止投递并关闭输出，未确认的记录在下次启动时重新投递
+func (p *Pipeline) Stop() error {
+	p.cancel()
+	<-p.done
+	return p.sink.Close()
+}
+
+// Status 返回投递进度
+func (p *Pipeline) Status() Status {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	return p.status
+}
+
+// run 循环读取并投递变更
+func (p *Pipeline) run() {
+	defer close(p.done)
+
+	name := p.sink.Name()
+	checkpoint, found, err := p.store.Checkpoint(name)
+	if err != nil {
+		p.fail(err)
+	}
+	p.mu.Lock()
+	p.status.Checkpoint = checkpoint
+	p.mu.Unlock()
+
+	delay := minRetryDelay
+	for p.ctx.Err() == nil {
+		// 1. 首次运行时从最早保留的变更开始，读到变更后立即保存检查点阻止日志被裁剪
+		from := checkpoint + 1
+		if !found {
+			from = 0
+		}
+		events, err := p.store.ReadChanges(from, p.batchSize)
+		if errors.Is(err, storage.ErrCompacted) {
+			// 检查点之前的变更已被裁剪，只能从最早保留的变更继续
+			p.fail(err)
+			found = false
+			continue
+		}
+		if err == nil && !found && len(events) > 0 {
+			checkpoint = events[0].Revision - 1
+			if err = p.store.SaveCheckpoint(name, checkpoint); err == nil {
+				found = true
+			}
+		}
+		if err != nil {
+			p.fail(err)
+			p.sleep(p.pollInterval)
+			continue
+		}
+
+		if len(events) == 0 {
+			p.sleep(p.pollInterval)
+			continue
+		}
+
+		// 2. 投递，失败时按指数退避重试同一批记录
+		records := make([]Record, len(events))
+		for i, event := range events {
+			records[i] = newRecord(event)
+		}
+		if err := p.sink.Write(p.ctx, records); err != nil {
+			if p.ctx.Err() != nil {
+				return
+			}
+			p.fail(err)
+			cdcMetrics.errors.WithLabelValues(name).Inc()
+			p.sleep(delay)
+			delay = min(delay*2, maxRetryDelay)
+			continue
+		}
+		delay = minRetryDelay
+
+		// 3. 投递成功后保存检查点，保存失败时下一轮会重复投递这批记录
+		last := events[len(events)-1].Revision
+		if err := p.store.SaveCheckpoint(name, last); err != nil {
+			p.fail(err)
+			p.sleep(p.pollInterval)
+			continue
+		}
+		checkpoint = last
+
+		p.mu.Lock()
+		p.status.Checkpoint = checkpoint
+		p.status.Delivered += int64(len(records))
+		p.mu.Unlock()
+		cdcMetrics.delivered.WithLabelValues(name).Add(float64(len(records)))
+		cdcMetrics.checkpoint.WithLabelValues(name).Set(float64(checkpoint))
+	}
+}
+
+// fail 记录最近一次错误
+func (p *Pipeline) fail(err error) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	p.status.LastError = err.Error()
+	p.status.LastErrAt = time.Now()
+}
+
+// sleep 等待一段时间，停止时立即返回
+func (p *Pipeline) sleep(d time.Duration) {
+	timer := time.NewTimer(d)
+	defer timer.Stop()
+	select {
+	case <-timer.C:
+	case <-p.ctx.Done():
+	}
+}
+
+// Manager 管理配置中的所有输出
+type Manager struct {
+	pipelines []*Pipeline
+}
+
+// NewManager 根据配置为每个输出创建投递流程
+func NewManager(store storage.Storage, cfg *config.CDCConfig) (*Manager, error) {
+	m := &Manager{}
+	names := make(map[string]bool)
+	for i := range cfg.Sinks {
+		sc := &cfg.Sinks[i]
+		if names[sc.Name] {
+			m.closeSinks()
+			return nil, fmt.Errorf("duplicate cdc sink name: %s", sc.Name)
+		}
+		names[sc.Name] = true
+
+		sink, err := NewSink(sc)
+		if err != nil {
+			m.closeSinks()
+			return nil, err
+		}
+		pollInterval := time.Duration(cfg.PollInterval) * time.Millisecond
+		m.pipelines = append(m.pipelines, NewPipeline(store, sink, cfg.BatchSize, pollInterval))
+	}
+	return m, nil
+}
+
+// Start 启动所有投递流程
+func (m *Manager) Start() {
+	for _, p := range m.pipelines {
+		p.Start()
+	}
+}
+
+// Stop 停止所有投递流程
+func (m *Manager) Stop() {
+	for _, p := range m.pipelines {
+		p.Stop()
+	}
+}
+
+// Status 返回所有输出的投递进度
+func (m *Manager) Status() []Status {
+	statuses := make([]Status, 0, len(m.pipelines))
+	for _, p := range m.pipelines {
+		statuses = append(statuses, p.Status())
+	}
+	return statuses
+}
+
+// closeSinks 创建失败时关闭已创建的输出
+func (m *Manager) closeSinks() {
+	for _, p := range m.pipelines {
+		p.sink.Close()
+	}
+}
+
+// metrics CDC监控指标
+type metrics struct {
+	delivered  *prometheus.CounterVec
+	errors     *prometheus.CounterVec
+	checkpoint *prometheus.GaugeVec
+}
+
+var cdcMetrics = newMetrics()
+
+// newMetrics 创建并注册CDC监控指标
+func newMetrics() *metrics {
+	m := &metrics{
+		delivered: prometheus.NewCounterVec(prometheus.CounterOpts{
+			Namespace: "cachefs",
+			Subsystem: "kv",
+			Name:      "cdc_delivered_total",
+			Help:      "Total number of change records delivered to a CDC sink",
+		}, []string{"sink"}),
+		errors: prometheus.NewCounterVec(prometheus.CounterOpts{
+			Namespace: "cachefs",
+			Subsystem: "kv",
+			Name:      "cdc_errors_total",
+			Help:      "Total number of failed CDC deliveries",
+		}, []string{"sink"}),
+		checkpoint: prometheus.NewGaugeVec(prometheus.GaugeOpts{
+			Namespace: "cachefs",
+			Subsystem: "kv",
+			Name:      "cdc_checkpoint_revision",
+			Help:      "Last change log revision acknowledged by a CDC sink",
+		}, []string{"sink"}),
+	}
+	prometheus.MustRegister(m.delivered, m.errors, m.checkpoint)
+	return m
+}
@@ -0,0 +1,149 @@
+package cdc
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"kvcache/config"
+	"kvcache/storage"
+)
+
+// TestFileSinkRotate 测试文件输出的写入和轮转
+func TestFileSinkRotate(t *testing.T) {
+	dir := t.TempDir()
+	sink, err := NewFileSink("file", dir, 100, 2)
+	if err != nil {
+		t.Fatalf("Failed to create file sink: %v", err)
+	}
+	defer sink.Close()
+
+	// 每批记录超过100字节，写入后立即轮转
+	for rev := uint64(1); rev <= 4; rev++ {
+		records := []Record{{Revision: rev, Type: "put", Key: "key-with-a-fairly-long-name", Time: 1}}
+		records = append(records, Record{Revision: rev, Type: "put", Key: "another-long-key-name", Time: 1})
+		if err := sink.Write(context.Background(), records); err != nil {
+			t.Fatalf("Failed to write records: %v", err)
+		}
+	}
+
+	files, err := sink.files()
+	if err != nil {
+		t.Fatalf("Failed to list files: %v", err)
+	}
+	if len(files) != 2 {
+		t.Fatalf("Expected 2 files after rotation, got %v", files)
+	}
+
+	// 保留最新的文件，每行是一条记录
+	file, err := os.Open(filepath.Join(dir, files[1]))
+	if err != nil {
+		t.Fatalf("Failed to open file: %v", err)
+	}
+	defer file.Close()
+	scanner := bufio.NewScanner(file)
+	lines := 0
+	for scanner.Scan() {
+		var record Record
+		if err := json.Unmarshal(scanner.Bytes(), &record); err != nil {
+			t.Fatalf("Failed to decode record: %v", err)
+		}
+		if record.Revision != 4 {
+			t.Errorf("Expected revision 4 in newest file, got %d", record.Revision)
+		}
+		lines++
+	}
+	if lines != 2 {
+		t.Errorf("Expected 2 records in newest file, got %d", lines)
+	}
+}
+
+// TestPipelineWebhook 测试通过Webhook投递、失败重试和检查点续传
+func TestPipelineWebhook(t *testing.T) {
+	// 初始化配置
+	cfg := config.DefaultConfig()
+
+	// 删除现有的数据目录，确保测试环境干净
+	os.RemoveAll(cfg.RocksDB.Path)
+	os.RemoveAll(cfg.Value.DiskPath)
+
+	// 创建存储实例
+	store, err := storage.NewStorage(cfg)
+	if err != nil {
+		t.Fatalf("Failed to create storage: %v", err)
+	}
+	defer store.Stop()
+
+	// 第一次请求返回错误，之后记录收到的修订号
+	var mu sync.Mutex
+	var received []Record
+	var requests int32
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if atomic.AddInt32(&requests, 1) == 1 {
+			w.WriteHeader(http.StatusServiceUnavailable)
+			return
+		}
+		var payload webhookPayload
+		if err := json.NewDecoder(r.Body).Decode(&payload); err != nil {
+			w.WriteHeader(http.StatusBadRequest)
+			return
+		}
+		mu.Lock()
+		received = append(received, payload.Records...)
+		mu.Unlock()
+	}))
+	defer server.Close()
+
+	waitFor := func(count int) []Record {
+		deadline := time.Now().Add(5 * time.Second)
+		for time.Now().Before(deadline) {
+			mu.Lock()
+			n := len(received)
+			mu.Unlock()
+			if n >= count {
+				break
+			}
+			time.Sleep(10 * time.Millisecond)
+		}
+		mu.Lock()
+		defer mu.Unlock()
+		return append([]Record(nil), received...)
+	}
+
+	store.Set([]byte("cdc:1"), []byte("a"))
+	store.Delete([]byte("cdc:1"))
+
+	// 首次运行从最早保留的变更开始，失败后重试同一批记录
+	pipeline := NewPipeline(store, NewWebhookSink("webhook", server.URL, time.Second, nil), 100, 10*time.Millisecond)
+	pipeline.Start()
+	records := waitFor(2)
+	if len(records) != 2 || records[0].Type != "put" || records[1].Type != "delete" || records[0].Key != "cdc:1" {
+		t.Fatalf("Unexpected records: %+v", records)
+	}
+	if err := pipeline.Stop(); err != nil {
+		t.Fatalf("Failed to stop pipeline: %v", err)
+	}
+
+	checkpoint, found, err := store.Checkpoint("webhook")
+	if err != nil || !found || checkpoint != records[1].Revision {
+		t.Fatalf("Expected checkpoint %d, got %d (found=%v, err=%v)", records[1].Revision, checkpoint, found, err)
+	}
+
+	// 重启后从检查点之后继续
+	store.Set([]byte("cdc:2"), []byte("b"))
+	pipeline = NewPipeline(store, NewWebhookSink("webhook", server.URL, time.Second, nil), 100, 10*time.Millisecond)
+	pipeline.Start()
+	defer pipeline.Stop()
+	records = waitFor(3)
+	if len(records) != 3 || records[2].Key != "cdc:2" || records[2].Revision <= checkpoint {
+		t.Errorf("Expected only the new change after restart, got %+v", records)
+	}
+}
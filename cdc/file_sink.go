@@ -0,0 +1,156 @@
+package cdc
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"sync"
+)
+
+const (
+	// changeFilePrefix 变更文件名前缀，文件名包含首条记录的修订号，按名称排序即按时间排序
+	changeFilePrefix = "changes-"
+	// changeFileSuffix 变更文件名后缀
+	changeFileSuffix = ".jsonl"
+)
+
+// FileSink 将变更记录以JSON Lines追加到本地文件，超过大小后轮转并删除最早的文件
+type FileSink struct {
+	name     string
+	dir      string
+	maxSize  int64
+	maxFiles int
+
+	mu   sync.Mutex
+	file *os.File
+	size int64
+}
+
+// NewFileSink 创建文件输出
+func NewFileSink(name, dir string, maxSize int64, maxFiles int) (*FileSink, error) {
+	if err := os.MkdirAll(dir, 0755); err != nil {
+		return nil, fmt.Errorf("failed to create cdc directory: %v", err)
+	}
+	return &FileSink{
+		name:     name,
+		dir:      dir,
+		maxSize:  maxSize,
+		maxFiles: maxFiles,
+	}, nil
+}
+
+// Name 返回输出名称
+func (f *FileSink) Name() string {
+	return f.name
+}
+
+// Write 追加一批记录并同步到磁盘
+func (f *FileSink) Write(ctx context.Context, records []Record) error {
+	if len(records) == 0 {
+		return nil
+	}
+
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	// 1. 打开当前文件，启动后或轮转后以首条记录的修订号命名新文件
+	if f.file == nil {
+		path := filepath.Join(f.dir, fmt.Sprintf("%s%020d%s", changeFilePrefix, records[0].Revision, changeFileSuffix))
+		file, err := os.OpenFile(path, os.O_CREATE|os.O_WRONLY|os.O_APPEND, 0644)
+		if err != nil {
+			return err
+		}
+		info, err := file.Stat()
+		if err != nil {
+			file.Close()
+			return err
+		}
+		f.file = file
+		f.size = info.Size()
+	}
+
+	// 2. 写入并同步，确认落盘后才能保存检查点
+	var buf []byte
+	for i := range records {
+		line, err := json.Marshal(&records[i])
+		if err != nil {
+			return err
+		}
+		buf = append(buf, line...)
+		buf = append(buf, '\n')
+	}
+	n, err := f.file.Write(buf)
+	f.size += int64(n)
+	if err != nil {
+		return err
+	}
+	if err := f.file.Sync(); err != nil {
+		return err
+	}
+
+	// 3. 超过大小后轮转
+	if f.size >= f.maxSize {
+		if err := f.rotate(); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// rotate 关闭当前文件并删除超出数量的旧文件，调用方需持有mu
+func (f *FileSink) rotate() error {
+	err := f.file.Close()
+	f.file = nil
+	f.size = 0
+	if err != nil {
+		return err
+	}
+
+	if f.maxFiles <= 0 {
+		return nil
+	}
+	files, err := f.files()
+	if err != nil {
+		return err
+	}
+	for len(files) > f.maxFiles {
+		os.Remove(filepath.Join(f.dir, files[0]))
+		files = files[1:]
+	}
+	return nil
+}
+
+// files 按名称顺序列出变更文件
+func (f *FileSink) files() ([]string, error) {
+	entries, err := os.ReadDir(f.dir)
+	if err != nil {
+		return nil, err
+	}
+
+	var files []string
+	for _, entry := range entries {
+		name := entry.Name()
+		if !entry.IsDir() && strings.HasPrefix(name, changeFilePrefix) && strings.HasSuffix(name, changeFileSuffix) {
+			files = append(files, name)
+		}
+	}
+	sort.Strings(files)
+	return files, nil
+}
+
+// Close 关闭当前文件
+func (f *FileSink) Close() error {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	if f.file == nil {
+		return nil
+	}
+	err := f.file.Close()
+	f.file = nil
+	return err
+}
@@ -0,0 +1,75 @@
+package cdc
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"time"
+)
+
+// WebhookSink 将一批变更记录以JSON POST到外部地址，返回2xx表示对方已确认
+type WebhookSink struct {
+	name    string
+	url     string
+	headers map[string]string
+	client  *http.Client
+}
+
+// webhookPayload 推送的请求体
+type webhookPayload struct {
+	Sink    string   `json:"sink"`
+	Records []Record `json:"records"`
+}
+
+// NewWebhookSink 创建Webhook输出
+func NewWebhookSink(name, url string, timeout time.Duration, headers map[string]string) *WebhookSink {
+	return &WebhookSink{
+		name:    name,
+		url:     url,
+		headers: headers,
+		client:  &http.Client{Timeout: timeout},
+	}
+}
+
+// Name 返回输出名称
+func (w *WebhookSink) Name() string {
+	return w.name
+}
+
+// Write 推送一批记录
+func (w *WebhookSink) Write(ctx context.Context, records []Record) error {
+	body, err := json.Marshal(&webhookPayload{Sink: w.name, Records: records})
+	if err != nil {
+		return err
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, w.url, bytes.NewReader(body))
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", "application/json")
+	for key, value := range w.headers {
+		req.Header.Set(key, value)
+	}
+
+	resp, err := w.client.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	io.Copy(io.Discard, resp.Body)
+
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		return fmt.Errorf("webhook %s returned status %d", w.url, resp.StatusCode)
+	}
+	return nil
+}
+
+// Close 释放空闲连接
+func (w *WebhookSink) Close() error {
+	w.client.CloseIdleConnections()
+	return nil
+}
@@ -63,17 +63,17 @@ func (c *Client) Set(ctx context.Context, key string, value []byte, ttl time.Dur
 	client := c.nextClient()
 
 	req := &proto.SetRequest{
-		Key:   key,
+		Key:   []byte(key),
 		Value: value,
 	}
 
 	_, err := client.Set(ctx, req)
-	if err != nil {
-		// 尝试使用下一个客户端
+	if retryable(err) {
+		// 服务器不可用时尝试使用下一个客户端
 		return c.retrySet(ctx, key, value, ttl)
 	}
 
-	return nil
+	return statusError(err)
 }
 
 // retrySet 重试设置键值对
@@ -82,13 +82,13 @@ func (c *Client) retrySet(ctx context.Context, key string, value []byte, ttl tim
 		client := c.nextClient()
 
 		req := &proto.SetRequest{
-			Key:   key,
+			Key:   []byte(key),
 			Value: value,
 		}
 
 		_, err := client.Set(ctx, req)
-		if err == nil {
-			return nil
+		if !retryable(err) {
+			return statusError(err)
 		}
 	}
 
@@ -100,17 +100,23 @@ func (c *Client) Get(ctx context.Context, key string) ([]byte, error) {
 	client := c.nextClient()
 
 	req := &proto.GetRequest{
-		Key: key,
+		Key: []byte(key),
 	}
 
 	resp, err := client.Get(ctx, req)
-	if err != nil {
-		// 尝试使用下一个客户端
+	if retryable(err) {
+		// 服务器不可用时尝试使用下一个客户端
 		return c.retryGet(ctx, key)
 	}
+	if err != nil {
+		return nil, statusError(err)
+	}
 
+	if resp.Evicted {
+		return nil, ErrEvicted
+	}
 	if !resp.Found {
-		return nil, fmt.Errorf("key not found")
+		return nil, errKeyNotFound
 	}
 
 	return resp.Value, nil
@@ -122,16 +128,23 @@ func (c *Client) retryGet(ctx context.Context, key string) ([]byte, error) {
 		client := c.nextClient()
 
 		req := &proto.GetRequest{
-			Key: key,
+			Key: []byte(key),
 		}
 
 		resp, err := client.Get(ctx, req)
-		if err == nil {
-			if resp.Found {
-				return resp.Value, nil
-			}
-			return nil, fmt.Errorf("key not found")
+		if retryable(err) {
+			continue
+		}
+		if err != nil {
+			return nil, statusError(err)
+		}
+		if resp.Found {
+			return resp.Value, nil
+		}
+		if resp.Evicted {
+			return nil, ErrEvicted
 		}
+		return nil, errKeyNotFound
 	}
 
 	return nil, fmt.Errorf("all servers failed")
@@ -142,16 +155,16 @@ func (c *Client) Delete(ctx context.Context, key string) error {
 	client := c.nextClient()
 
 	req := &proto.DeleteRequest{
-		Key: key,
+		Key: []byte(key),
 	}
 
 	_, err := client.Delete(ctx, req)
-	if err != nil {
-		// 尝试使用下一个客户端
+	if retryable(err) {
+		// 服务器不可用时尝试使用下一个客户端
 		return c.retryDelete(ctx, key)
 	}
 
-	return nil
+	return statusError(err)
 }
 
 // retryDelete 重试删除键值对
@@ -160,14 +173,63 @@ func (c *Client) retryDelete(ctx context.Context, key string) error {
 		client := c.nextClient()
 
 		req := &proto.DeleteRequest{
-			Key: key,
+			Key: []byte(key),
 		}
 
 		_, err := client.Delete(ctx, req)
-		if err == nil {
-			return nil
+		if !retryable(err) {
+			return statusError(err)
 		}
 	}
 
 	return fmt.Errorf("all servers failed")
 }
+
+// Stat 获取键的元数据，不传输值本身
+func (c *Client) Stat(ctx context.Context, key string) (*proto.KeyMeta, error) {
+	client := c.nextClient()
+
+	req := &proto.GetMetaRequest{
+		Key: []byte(key),
+	}
+
+	resp, err := client.GetMeta(ctx, req)
+	if retryable(err) {
+		// 服务器不可用时尝试使用下一个客户端
+		return c.retryStat(ctx, key)
+	}
+	if err != nil {
+		return nil, statusError(err)
+	}
+
+	if !resp.Found {
+		return nil, errKeyNotFound
+	}
+
+	return resp.Meta, nil
+}
+
+// retryStat 重试获取键的元数据
+func (c *Client) retryStat(ctx context.Context, key string) (*proto.KeyMeta, error) {
+	for i := 0; i < len(c.clients); i++ {
+		client := c.nextClient()
+
+		req := &proto.GetMetaRequest{
+			Key: []byte(key),
+		}
+
+		resp, err := client.GetMeta(ctx, req)
+		if retryable(err) {
+			continue
+		}
+		if err != nil {
+			return nil, statusError(err)
+		}
+		if resp.Found {
+			return resp.Meta, nil
+		}
+		return nil, errKeyNotFound
+	}
+
+	return nil, fmt.Errorf("all servers failed")
+}
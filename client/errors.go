@@ -0,0 +1,40 @@
+package client
+
+import (
+	"errors"
+	"fmt"
+
+	"google.golang.org/genproto/googleapis/rpc/errdetails"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+var (
+	// ErrNotFound 键不存在
+	ErrNotFound = errors.New("not found")
+	// ErrEvicted 值已被服务器淘汰，键和元数据仍然存在
+	ErrEvicted = errors.New("value has been evicted")
+)
+
+// errKeyNotFound 旧版本服务器通过found字段返回的键不存在
+var errKeyNotFound = fmt.Errorf("%w: key not found", ErrNotFound)
+
+// retryable 判断请求是否可以发往其他服务器重试，只有服务器不可用时重试
+func retryable(err error) bool {
+	return status.Code(err) == codes.Unavailable
+}
+
+// statusError 将服务器返回的gRPC状态转换为客户端错误，NotFound状态与ErrNotFound或ErrEvicted匹配
+func statusError(err error) error {
+	st, ok := status.FromError(err)
+	if !ok || st.Code() != codes.NotFound {
+		return err
+	}
+
+	for _, detail := range st.Details() {
+		if info, ok := detail.(*errdetails.ErrorInfo); ok && info.Reason == "EVICTED" {
+			return ErrEvicted
+		}
+	}
+	return fmt.Errorf("%w: %s", ErrNotFound, st.Message())
+}
@@ -3,7 +3,6 @@ package client
 import (
 	"context"
 	"log"
-	"time"
 )
 
 // Example 使用示例
@@ -46,6 +45,14 @@ func Example() {
 		log.Printf("Get key %s: %s", key, retrievedValue)
 	}
 
+	// 查看键的元数据
+	meta, err := client.Stat(ctx, key)
+	if err != nil {
+		log.Printf("Failed to stat key: %v", err)
+	} else {
+		log.Printf("Stat key %s: size=%d location=%s version=%d", key, meta.Size, meta.Location, meta.Version)
+	}
+
 	// 删除键值对
 	err = client.Delete(ctx, key)
 	if err != nil {
@@ -60,3 +67,52 @@ func Example() {
 		log.Printf("Expected error when getting deleted key: %v", err)
 	}
 }
+
+// ShardedExample 分片客户端使用示例
+func ShardedExample() {
+	// 每个实例保存不同的键，客户端按一致性哈希选择实例
+	serverAddrs := []string{
+		"localhost:33000",
+		"localhost:33002",
+		"localhost:33004",
+	}
+
+	client, err := NewShardedClient(serverAddrs, DefaultVirtualNodes)
+	if err != nil {
+		log.Fatalf("Failed to create sharded client: %v", err)
+	}
+	defer client.Close()
+
+	ctx := context.Background()
+
+	// 批量写入按实例拆分
+	err = client.MSet(ctx, map[string][]byte{
+		"user:1": []byte("alice"),
+		"user:2": []byte("bob"),
+		"user:3": []byte("carol"),
+	})
+	if err != nil {
+		log.Printf("Failed to mset: %v", err)
+	}
+
+	// 读取总是落在写入的实例上
+	value, err := client.Get(ctx, "user:1")
+	if err != nil {
+		log.Printf("Failed to get key: %v", err)
+	} else {
+		log.Printf("Get user:1 from %s: %s", client.ServerFor("user:1"), value)
+	}
+
+	// 扫描合并所有实例的结果
+	users, err := client.Scan(ctx, "user:")
+	if err != nil {
+		log.Printf("Failed to scan: %v", err)
+	} else {
+		log.Printf("Scanned %d users", len(users))
+	}
+
+	// 运行时添加实例，只有归属新实例的键受影响
+	if err := client.AddServer("localhost:33006"); err != nil {
+		log.Printf("Failed to add server: %v", err)
+	}
+}
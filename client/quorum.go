@@ -0,0 +1,472 @@
+package client
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sync"
+	"time"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/credentials/insecure"
+
+	"kvcache/proto"
+)
+
+const (
+	// DefaultReplicas 每个键的默认副本数
+	DefaultReplicas = 3
+	// defaultHintInterval 重新投递暂存写入的默认间隔
+	defaultHintInterval = time.Second
+	// defaultMaxHints 暂存写入的默认上限
+	defaultMaxHints = 10000
+	// defaultReplicaTimeout 写入法定数量返回后，其余副本请求的超时时间
+	defaultReplicaTimeout = 5 * time.Second
+)
+
+// QuorumOptions 法定数量复制的参数，未设置的字段使用默认值
+type QuorumOptions struct {
+	Replicas     int           // 每个键的副本数N，默认3
+	ReadQuorum   int           // 读取需要响应的副本数R，默认为多数
+	WriteQuorum  int           // 写入需要确认的副本数W，默认为多数
+	VirtualNodes int           // 每个服务器的虚拟节点数，默认160
+	HintInterval time.Duration // 重新投递暂存写入的间隔，默认1秒
+	MaxHints     int           // 暂存写入的上限，超出后丢弃新的写入，默认10000
+}
+
+// QuorumClient 无主的法定数量复制客户端，每个键写入哈希环上连续的N个服务器
+// 写入携带混合逻辑时间戳，W个副本确认后返回；读取等待R个副本响应并返回时间戳最新的值，
+// 之后在后台修复落后的副本。写入失败的副本暂存在客户端，服务器恢复后重新投递
+type QuorumClient struct {
+	opts    QuorumOptions
+	ring    *Ring
+	conns   []*grpc.ClientConn
+	clients map[string]proto.KeyValueServiceClient
+	clock   hybridClock
+
+	mu    sync.Mutex
+	hints map[string]map[string]*hint // 服务器 -> 键 -> 最新的暂存写入
+	count int
+
+	stop chan struct{}
+	wg   sync.WaitGroup
+}
+
+// hint 写入失败的副本暂存的写入
+type hint struct {
+	key       string
+	value     []byte
+	deleted   bool
+	stamp     uint64
+	expiresAt time.Time // 零值表示永不过期
+}
+
+// replicaResult 一个副本的响应
+type replicaResult struct {
+	addr  string
+	key   string
+	value []byte
+	found bool
+	stamp uint64
+	ttl   time.Duration // 剩余存活时间，0表示永不过期
+	err   error
+}
+
+// NewQuorumClient 创建法定数量复制客户端
+func NewQuorumClient(addrs []string, opts QuorumOptions) (*QuorumClient, error) {
+	if opts.Replicas <= 0 {
+		opts.Replicas = DefaultReplicas
+	}
+	if opts.ReadQuorum <= 0 {
+		opts.ReadQuorum = opts.Replicas/2 + 1
+	}
+	if opts.WriteQuorum <= 0 {
+		opts.WriteQuorum = opts.Replicas/2 + 1
+	}
+	if opts.HintInterval <= 0 {
+		opts.HintInterval = defaultHintInterval
+	}
+	if opts.MaxHints <= 0 {
+		opts.MaxHints = defaultMaxHints
+	}
+	if opts.ReadQuorum > opts.Replicas || opts.WriteQuorum > opts.Replicas {
+		return nil, fmt.Errorf("read quorum %d and write quorum %d must not exceed %d replicas", opts.ReadQuorum, opts.WriteQuorum, opts.Replicas)
+	}
+
+	c := &QuorumClient{
+		opts:    opts,
+		ring:    NewRing(opts.VirtualNodes),
+		clients: make(map[string]proto.KeyValueServiceClient),
+		hints:   make(map[string]map[string]*hint),
+		stop:    make(chan struct{}),
+	}
+	for _, addr := range addrs {
+		if _, ok := c.clients[addr]; ok {
+			continue
+		}
+		conn, err := grpc.NewClient(addr, grpc.WithTransportCredentials(insecure.NewCredentials()))
+		if err != nil {
+			c.closeConns()
+			return nil, fmt.Errorf("failed to connect to %s: %v", addr, err)
+		}
+		c.conns = append(c.conns, conn)
+		c.clients[addr] = proto.NewKeyValueServiceClient(conn)
+		c.ring.Add(addr)
+	}
+	if len(c.clients) < opts.Replicas {
+		c.closeConns()
+		return nil, fmt.Errorf("%d replicas require at least %d servers, got %d", opts.Replicas, opts.Replicas, len(c.clients))
+	}
+
+	c.wg.Add(1)
+	go c.deliverHints()
+	return c, nil
+}
+
+// Close 停止投递暂存写入，等待后台修复完成后关闭所有连接，未投递的暂存写入被丢弃
+func (c *QuorumClient) Close() error {
+	close(c.stop)
+	c.wg.Wait()
+	c.closeConns()
+	return nil
+}
+
+// closeConns 关闭所有连接
+func (c *QuorumClient) closeConns() {
+	for _, conn := range c.conns {
+		conn.Close()
+	}
+	c.conns = nil
+}
+
+// Replicas 返回保存键的服务器，第一个为键所属的服务器
+func (c *QuorumClient) Replicas(key string) []string {
+	return c.ring.Preference(key, c.opts.Replicas)
+}
+
+// PendingHints 返回尚未投递的暂存写入数量
+func (c *QuorumClient) PendingHints() int {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.count
+}
+
+// Set 写入键值对，W个副本确认后返回
+func (c *QuorumClient) Set(ctx context.Context, key string, value []byte, ttl time.Duration) error {
+	h := &hint{key: key, value: value, stamp: c.clock.now()}
+	if ttl > 0 {
+		h.expiresAt = time.Now().Add(ttl)
+	}
+	return c.write(ctx, h)
+}
+
+// Delete 删除键，W个副本确认后返回，副本保留墓碑使较旧的写入不会使键复活
+func (c *QuorumClient) Delete(ctx context.Context, key string) error {
+	return c.write(ctx, &hint{key: key, deleted: true, stamp: c.clock.now()})
+}
+
+// write 并发写入所有副本，W个副本确认后返回，失败的副本暂存写入
+func (c *QuorumClient) write(ctx context.Context, h *hint) error {
+	replicas := c.Replicas(h.key)
+	results := make(chan replicaResult, len(replicas))
+
+	// 其余副本在返回后继续写入，不受调用方取消的影响
+	replicaCtx, cancel := context.WithTimeout(context.WithoutCancel(ctx), defaultReplicaTimeout)
+	for _, addr := range replicas {
+		go func(addr string) {
+			results <- replicaResult{addr: addr, err: c.apply(replicaCtx, addr, h)}
+		}(addr)
+	}
+
+	acks := 0
+	var lastErr error
+	for i := 0; i < len(replicas); i++ {
+		var result replicaResult
+		select {
+		case result = <-results:
+		case <-ctx.Done():
+			c.drain(results, len(replicas)-i, h, cancel)
+			return ctx.Err()
+		}
+
+		if result.err != nil {
+			lastErr = result.err
+			c.addHint(result.addr, h)
+		} else {
+			acks++
+		}
+		if acks == c.opts.WriteQuorum {
+			c.drain(results, len(replicas)-i-1, h, cancel)
+			return nil
+		}
+	}
+	cancel()
+	return fmt.Errorf("write quorum not reached: %d of %d replicas acknowledged: %v", acks, c.opts.WriteQuorum, lastErr)
+}
+
+// drain 在后台接收其余副本的写入结果，失败的副本暂存写入
+func (c *QuorumClient) drain(results chan replicaResult, n int, h *hint, cancel context.CancelFunc) {
+	c.wg.Add(1)
+	go func() {
+		defer c.wg.Done()
+		defer cancel()
+		for i := 0; i < n; i++ {
+			if result := <-results; result.err != nil {
+				c.addHint(result.addr, h)
+			}
+		}
+	}()
+}
+
+// apply 将一次写入发送给副本，副本已有相同或更新的时间戳时同样视为成功
+func (c *QuorumClient) apply(ctx context.Context, addr string, h *hint) error {
+	client := c.clients[addr]
+	if h.deleted {
+		resp, err := client.DeleteVersioned(ctx, &proto.DeleteVersionedRequest{Key: []byte(h.key), Stamp: h.stamp})
+		if err != nil {
+			return err
+		}
+		if !resp.Success {
+			return errors.New(resp.Error)
+		}
+		return nil
+	}
+
+	var ttl int64
+	if !h.expiresAt.IsZero() {
+		// 向上取整到秒，保证至少存活到过期时间
+		ttl = int64((time.Until(h.expiresAt) + time.Second - 1) / time.Second)
+		if ttl <= 0 {
+			return nil
+		}
+	}
+	resp, err := client.SetVersioned(ctx, &proto.SetVersionedRequest{Key: []byte(h.key), Value: h.value, Ttl: ttl, Stamp: h.stamp})
+	if err != nil {
+		return err
+	}
+	if !resp.Success {
+		return errors.New(resp.Error)
+	}
+	return nil
+}
+
+// Get 读取键，R个副本响应后返回时间戳最新的值，并在后台修复落后的副本
+func (c *QuorumClient) Get(ctx context.Context, key string) ([]byte, error) {
+	replicas := c.Replicas(key)
+	results := make(chan replicaResult, len(replicas))
+
+	replicaCtx, cancel := context.WithTimeout(context.WithoutCancel(ctx), defaultReplicaTimeout)
+	for _, addr := range replicas {
+		go func(addr string) {
+			results <- c.read(replicaCtx, addr, key)
+		}(addr)
+	}
+
+	// 1. 等待R个副本响应
+	var responses []replicaResult
+	var lastErr error
+	received := 0
+	for received < len(replicas) && len(responses) < c.opts.ReadQuorum {
+		select {
+		case result := <-results:
+			received++
+			if result.err != nil {
+				lastErr = result.err
+				continue
+			}
+			responses = append(responses, result)
+		case <-ctx.Done():
+			c.repair(results, len(replicas)-received, responses, cancel)
+			return nil, ctx.Err()
+		}
+	}
+	if len(responses) < c.opts.ReadQuorum {
+		cancel()
+		return nil, fmt.Errorf("read quorum not reached: %d of %d replicas responded: %v", len(responses), c.opts.ReadQuorum, lastErr)
+	}
+
+	// 2. 选择时间戳最新的结果，之后的写入使用更新的时间戳
+	latest := newest(responses)
+	c.clock.observe(latest.stamp)
+
+	// 3. 在后台接收其余响应并修复落后的副本
+	c.repair(results, len(replicas)-received, responses, cancel)
+
+	if !latest.found {
+		return nil, errKeyNotFound
+	}
+	return latest.value, nil
+}
+
+// repair 在后台接收其余n个副本的响应，将时间戳最新的值写入落后的副本
+func (c *QuorumClient) repair(results chan replicaResult, n int, responses []replicaResult, cancel context.CancelFunc) {
+	c.wg.Add(1)
+	go func() {
+		defer c.wg.Done()
+		defer cancel()
+
+		all := append([]replicaResult(nil), responses...)
+		for i := 0; i < n; i++ {
+			if result := <-results; result.err == nil {
+				all = append(all, result)
+			}
+		}
+
+		// 没有带版本写入的键无法修复
+		if len(all) == 0 {
+			return
+		}
+		latest := newest(all)
+		if latest.stamp == 0 {
+			return
+		}
+		h := &hint{key: latest.key, value: latest.value, deleted: !latest.found, stamp: latest.stamp}
+		if latest.ttl > 0 {
+			h.expiresAt = time.Now().Add(latest.ttl)
+		}
+
+		ctx, cancel := context.WithTimeout(context.Background(), defaultReplicaTimeout)
+		defer cancel()
+		for _, result := range all {
+			if result.stamp >= latest.stamp {
+				continue
+			}
+			if err := c.apply(ctx, result.addr, h); err != nil {
+				c.addHint(result.addr, h)
+			}
+		}
+	}()
+}
+
+// newest 返回时间戳最新的响应，时间戳相同时优先返回存在的值
+func newest(results []replicaResult) replicaResult {
+	latest := results[0]
+	for _, result := range results[1:] {
+		if result.stamp > latest.stamp || (result.stamp == latest.stamp && result.found && !latest.found) {
+			latest = result
+		}
+	}
+	return latest
+}
+
+// read 读取一个副本的值和时间戳
+func (c *QuorumClient) read(ctx context.Context, addr, key string) replicaResult {
+	resp, err := c.clients[addr].GetVersioned(ctx, &proto.GetVersionedRequest{Key: []byte(key)})
+	if err != nil {
+		return replicaResult{addr: addr, err: err}
+	}
+	if resp.Error != "" {
+		return replicaResult{addr: addr, err: errors.New(resp.Error)}
+	}
+	return replicaResult{addr: addr, key: key, value: resp.Value, found: resp.Found, stamp: resp.Stamp, ttl: time.Duration(resp.Ttl) * time.Second}
+}
+
+// addHint 暂存写入失败的副本的写入，同一个键只保留时间戳最新的写入
+func (c *QuorumClient) addHint(addr string, h *hint) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	hints, ok := c.hints[addr]
+	if !ok {
+		hints = make(map[string]*hint)
+		c.hints[addr] = hints
+	}
+	if old, ok := hints[h.key]; ok {
+		if old.stamp < h.stamp {
+			hints[h.key] = h
+		}
+		return
+	}
+	if c.count >= c.opts.MaxHints {
+		return
+	}
+	hints[h.key] = h
+	c.count++
+}
+
+// deliverHints 定期将暂存的写入投递给恢复的服务器
+func (c *QuorumClient) deliverHints() {
+	defer c.wg.Done()
+
+	ticker := time.NewTicker(c.opts.HintInterval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-c.stop:
+			return
+		case <-ticker.C:
+			c.flushHints()
+		}
+	}
+}
+
+// flushHints 投递所有暂存的写入，服务器仍不可用时保留其余的写入
+func (c *QuorumClient) flushHints() {
+	c.mu.Lock()
+	pending := make(map[string][]*hint, len(c.hints))
+	for addr, hints := range c.hints {
+		for _, h := range hints {
+			pending[addr] = append(pending[addr], h)
+		}
+	}
+	c.mu.Unlock()
+
+	for addr, hints := range pending {
+		for _, h := range hints {
+			ctx, cancel := context.WithTimeout(context.Background(), defaultReplicaTimeout)
+			err := c.apply(ctx, addr, h)
+			cancel()
+			if err != nil {
+				break
+			}
+			c.removeHint(addr, h)
+		}
+	}
+}
+
+// removeHint 删除已投递的写入，投递期间有更新的写入时保留
+func (c *QuorumClient) removeHint(addr string, h *hint) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	hints := c.hints[addr]
+	if hints[h.key] != h {
+		return
+	}
+	delete(hints, h.key)
+	c.count--
+	if len(hints) == 0 {
+		delete(c.hints, addr)
+	}
+}
+
+// hybridClock 混合逻辑时钟，高48位为毫秒级的物理时间，低16位为同一毫秒内的逻辑计数
+// 时钟不会回退，并且跟随读到的更新的时间戳，使之后的写入总是比读到的值新
+type hybridClock struct {
+	mu   sync.Mutex
+	last uint64
+}
+
+// now 返回比之前所有时间戳都新的时间戳
+func (c *hybridClock) now() uint64 {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	wall := uint64(time.Now().UnixMilli()) << 16
+	if wall > c.last {
+		c.last = wall
+	} else {
+		c.last++
+	}
+	return c.last
+}
+
+// observe 记录读到的时间戳
+func (c *hybridClock) observe(stamp uint64) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if stamp > c.last {
+		c.last = stamp
+	}
+}
@@ -0,0 +1,228 @@
+package client
+
+import (
+	"context"
+	"net"
+	"sync"
+	"testing"
+	"time"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+
+	"kvcache/proto"
+)
+
+// versionedEntry 带版本的值，deleted表示墓碑
+type versionedEntry struct {
+	value   []byte
+	stamp   uint64
+	deleted bool
+}
+
+// versionedServer 使用内存map的带版本KV服务，down时拒绝所有请求
+type versionedServer struct {
+	proto.UnimplementedKeyValueServiceServer
+	mu   sync.Mutex
+	data map[string]versionedEntry
+	down bool
+}
+
+func (s *versionedServer) SetVersioned(ctx context.Context, req *proto.SetVersionedRequest) (*proto.SetVersionedResponse, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.down {
+		return nil, status.Error(codes.Unavailable, "server is down")
+	}
+	if req.Stamp <= s.data[string(req.Key)].stamp {
+		return &proto.SetVersionedResponse{Success: true}, nil
+	}
+	s.data[string(req.Key)] = versionedEntry{value: req.Value, stamp: req.Stamp}
+	return &proto.SetVersionedResponse{Success: true, Applied: true}, nil
+}
+
+func (s *versionedServer) DeleteVersioned(ctx context.Context, req *proto.DeleteVersionedRequest) (*proto.DeleteVersionedResponse, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.down {
+		return nil, status.Error(codes.Unavailable, "server is down")
+	}
+	if req.Stamp <= s.data[string(req.Key)].stamp {
+		return &proto.DeleteVersionedResponse{Success: true}, nil
+	}
+	s.data[string(req.Key)] = versionedEntry{stamp: req.Stamp, deleted: true}
+	return &proto.DeleteVersionedResponse{Success: true, Applied: true}, nil
+}
+
+func (s *versionedServer) GetVersioned(ctx context.Context, req *proto.GetVersionedRequest) (*proto.GetVersionedResponse, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.down {
+		return nil, status.Error(codes.Unavailable, "server is down")
+	}
+	entry, ok := s.data[string(req.Key)]
+	return &proto.GetVersionedResponse{Value: entry.value, Found: ok && !entry.deleted, Stamp: entry.stamp}, nil
+}
+
+// setDown 设置服务是否不可用
+func (s *versionedServer) setDown(down bool) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.down = down
+}
+
+// entry 返回保存的值
+func (s *versionedServer) entry(key string) versionedEntry {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.data[key]
+}
+
+// put 直接写入值，模拟错过写入的副本
+func (s *versionedServer) put(key string, entry versionedEntry) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.data[key] = entry
+}
+
+// startVersionedServers 启动n个带版本的内存KV服务
+func startVersionedServers(t *testing.T, n int) ([]string, map[string]*versionedServer) {
+	var addrs []string
+	servers := make(map[string]*versionedServer)
+	for i := 0; i < n; i++ {
+		lis, err := net.Listen("tcp", "127.0.0.1:0")
+		if err != nil {
+			t.Fatalf("Failed to listen: %v", err)
+		}
+		server := &versionedServer{data: make(map[string]versionedEntry)}
+		srv := grpc.NewServer()
+		proto.RegisterKeyValueServiceServer(srv, server)
+		go srv.Serve(lis)
+		t.Cleanup(srv.Stop)
+
+		addr := lis.Addr().String()
+		addrs = append(addrs, addr)
+		servers[addr] = server
+	}
+	return addrs, servers
+}
+
+// waitFor 等待条件成立
+func waitFor(t *testing.T, what string, cond func() bool) {
+	deadline := time.Now().Add(5 * time.Second)
+	for !cond() {
+		if time.Now().After(deadline) {
+			t.Fatalf("Timed out waiting for %s", what)
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+}
+
+// TestQuorumClient 测试写入N个副本，读取返回时间戳最新的值并修复落后的副本
+func TestQuorumClient(t *testing.T) {
+	addrs, servers := startVersionedServers(t, 4)
+	c, err := NewQuorumClient(addrs, QuorumOptions{Replicas: 3, HintInterval: 20 * time.Millisecond})
+	if err != nil {
+		t.Fatalf("Failed to create client: %v", err)
+	}
+	defer c.Close()
+	ctx := context.Background()
+
+	// 1. 写入落在偏好列表中的3个服务器
+	if err := c.Set(ctx, "user:1", []byte("alice"), 0); err != nil {
+		t.Fatalf("Failed to set: %v", err)
+	}
+	replicas := c.Replicas("user:1")
+	waitFor(t, "all replicas written", func() bool {
+		for _, addr := range replicas {
+			if string(servers[addr].entry("user:1").value) != "alice" {
+				return false
+			}
+		}
+		return true
+	})
+	for addr, server := range servers {
+		if addr != replicas[0] && addr != replicas[1] && addr != replicas[2] && server.entry("user:1").stamp != 0 {
+			t.Errorf("Unexpected write on %s", addr)
+		}
+	}
+
+	// 2. 读取返回时间戳最新的值，并修复落后的副本
+	stale := servers[replicas[0]]
+	old := stale.entry("user:1")
+	stale.put("user:1", versionedEntry{value: []byte("old"), stamp: old.stamp - 1})
+	if value, err := c.Get(ctx, "user:1"); err != nil || string(value) != "alice" {
+		t.Fatalf("Expected alice, got %q: %v", value, err)
+	}
+	waitFor(t, "read repair", func() bool {
+		return string(stale.entry("user:1").value) == "alice"
+	})
+
+	// 3. 删除写入墓碑，较旧的写入不会使键复活
+	if err := c.Delete(ctx, "user:1"); err != nil {
+		t.Fatalf("Failed to delete: %v", err)
+	}
+	if _, err := c.Get(ctx, "user:1"); err == nil {
+		t.Errorf("Expected key not found after delete")
+	}
+	waitFor(t, "tombstones", func() bool {
+		for _, addr := range replicas {
+			if !servers[addr].entry("user:1").deleted {
+				return false
+			}
+		}
+		return true
+	})
+	tombstone := stale.entry("user:1").stamp
+	resp, _ := stale.SetVersioned(ctx, &proto.SetVersionedRequest{Key: []byte("user:1"), Value: []byte("old"), Stamp: tombstone - 1})
+	if resp.Applied {
+		t.Errorf("Expected older write to be ignored after delete")
+	}
+}
+
+// TestQuorumClientHintedHandoff 测试副本不可用时写入仍满足法定数量，恢复后投递暂存的写入
+func TestQuorumClientHintedHandoff(t *testing.T) {
+	addrs, servers := startVersionedServers(t, 3)
+	c, err := NewQuorumClient(addrs, QuorumOptions{Replicas: 3, HintInterval: 20 * time.Millisecond})
+	if err != nil {
+		t.Fatalf("Failed to create client: %v", err)
+	}
+	defer c.Close()
+	ctx := context.Background()
+
+	// 1. 一个副本不可用时W=2仍然成功，写入暂存在客户端
+	down := servers[addrs[0]]
+	down.setDown(true)
+	if err := c.Set(ctx, "user:1", []byte("alice"), 0); err != nil {
+		t.Fatalf("Failed to set with one replica down: %v", err)
+	}
+	waitFor(t, "hint", func() bool { return c.PendingHints() == 1 })
+	if value, err := c.Get(ctx, "user:1"); err != nil || string(value) != "alice" {
+		t.Errorf("Expected alice with one replica down, got %q: %v", value, err)
+	}
+
+	// 2. 两个副本不可用时无法满足法定数量
+	servers[addrs[1]].setDown(true)
+	if err := c.Set(ctx, "user:2", []byte("bob"), 0); err == nil {
+		t.Errorf("Expected write quorum error")
+	}
+	if _, err := c.Get(ctx, "user:1"); err == nil {
+		t.Errorf("Expected read quorum error")
+	}
+
+	// 3. 服务器恢复后投递暂存的写入
+	servers[addrs[1]].setDown(false)
+	down.setDown(false)
+	waitFor(t, "hinted handoff", func() bool { return c.PendingHints() == 0 })
+	if string(down.entry("user:1").value) != "alice" {
+		t.Errorf("Expected hinted write delivered, got %q", down.entry("user:1").value)
+	}
+
+	if _, err := NewQuorumClient(addrs, QuorumOptions{Replicas: 3, WriteQuorum: 4}); err == nil {
+		t.Errorf("Expected error for write quorum larger than replicas")
+	}
+	if _, err := NewQuorumClient(addrs[:2], QuorumOptions{Replicas: 3}); err == nil {
+		t.Errorf("Expected error for too few servers")
+	}
+}
@@ -0,0 +1,121 @@
+package client
+
+import (
+	"hash/crc32"
+	"sort"
+	"strconv"
+)
+
+// DefaultVirtualNodes 每个服务器在哈希环上的默认虚拟节点数
+const DefaultVirtualNodes = 160
+
+// Ring 一致性哈希环，每个服务器映射为多个虚拟节点，增删服务器时只有相邻区间的键改变归属
+type Ring struct {
+	replicas int
+	hashes   []uint32          // 已排序的虚拟节点哈希
+	owners   map[uint32]string // 虚拟节点哈希 -> 服务器
+	nodes    map[string]struct{}
+}
+
+// NewRing 创建哈希环，replicas为每个服务器的虚拟节点数，不大于0时使用默认值
+func NewRing(replicas int) *Ring {
+	if replicas <= 0 {
+		replicas = DefaultVirtualNodes
+	}
+	return &Ring{
+		replicas: replicas,
+		owners:   make(map[uint32]string),
+		nodes:    make(map[string]struct{}),
+	}
+}
+
+// Add 添加服务器
+func (r *Ring) Add(nodes ...string) {
+	for _, node := range nodes {
+		if _, ok := r.nodes[node]; ok {
+			continue
+		}
+		r.nodes[node] = struct{}{}
+
+		for i := 0; i < r.replicas; i++ {
+			hash := hashKey(node + "#" + strconv.Itoa(i))
+			// 哈希冲突时保留字典序较小的服务器，保证结果与添加顺序无关
+			if owner, ok := r.owners[hash]; ok {
+				if node < owner {
+					r.owners[hash] = node
+				}
+				continue
+			}
+			r.owners[hash] = node
+			r.hashes = append(r.hashes, hash)
+		}
+	}
+	sort.Slice(r.hashes, func(i, j int) bool { return r.hashes[i] < r.hashes[j] })
+}
+
+// Remove 删除服务器
+func (r *Ring) Remove(node string) {
+	if _, ok := r.nodes[node]; !ok {
+		return
+	}
+	delete(r.nodes, node)
+
+	// 冲突的虚拟节点可能需要转交给其他服务器，直接重建
+	nodes := r.Nodes()
+	r.hashes = nil
+	r.owners = make(map[uint32]string)
+	r.nodes = make(map[string]struct{})
+	r.Add(nodes...)
+}
+
+// Get 返回键所属的服务器，环为空时返回空字符串
+func (r *Ring) Get(key string) string {
+	if len(r.hashes) == 0 {
+		return ""
+	}
+
+	hash := hashKey(key)
+	i := sort.Search(len(r.hashes), func(i int) bool { return r.hashes[i] >= hash })
+	if i == len(r.hashes) {
+		i = 0
+	}
+	return r.owners[r.hashes[i]]
+}
+
+// Preference 返回键的前n个不同的服务器，第一个为键所属的服务器，其余为沿环顺时针遇到的服务器
+func (r *Ring) Preference(key string, n int) []string {
+	if n > len(r.nodes) {
+		n = len(r.nodes)
+	}
+	if n <= 0 {
+		return nil
+	}
+
+	hash := hashKey(key)
+	start := sort.Search(len(r.hashes), func(i int) bool { return r.hashes[i] >= hash })
+	nodes := make([]string, 0, n)
+	seen := make(map[string]bool, n)
+	for i := 0; i < len(r.hashes) && len(nodes) < n; i++ {
+		node := r.owners[r.hashes[(start+i)%len(r.hashes)]]
+		if !seen[node] {
+			seen[node] = true
+			nodes = append(nodes, node)
+		}
+	}
+	return nodes
+}
+
+// Nodes 返回排序后的服务器列表
+func (r *Ring) Nodes() []string {
+	nodes := make([]string, 0, len(r.nodes))
+	for node := range r.nodes {
+		nodes = append(nodes, node)
+	}
+	sort.Strings(nodes)
+	return nodes
+}
+
+// hashKey 计算键在环上的位置
+func hashKey(key string) uint32 {
+	return crc32.ChecksumIEEE([]byte(key))
+}
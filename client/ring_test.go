@@ -0,0 +1,101 @@
+package client
+
+import (
+	"fmt"
+	"testing"
+)
+
+// TestRingDistribution 测试键在服务器之间大致均匀分布
+func TestRingDistribution(t *testing.T) {
+	ring := NewRing(0)
+	servers := []string{"localhost:33000", "localhost:33002", "localhost:33004", "localhost:33006"}
+	ring.Add(servers...)
+
+	counts := make(map[string]int)
+	total := 100000
+	for i := 0; i < total; i++ {
+		counts[ring.Get(fmt.Sprintf("key-%d", i))]++
+	}
+
+	expected := total / len(servers)
+	for _, server := range servers {
+		if counts[server] < expected*7/10 || counts[server] > expected*13/10 {
+			t.Errorf("Unbalanced distribution: %v", counts)
+			break
+		}
+	}
+}
+
+// TestRingStability 测试增删服务器只迁移少量键，且结果与添加顺序无关
+func TestRingStability(t *testing.T) {
+	ring := NewRing(0)
+	ring.Add("a:1", "b:1", "c:1")
+
+	other := NewRing(0)
+	other.Add("c:1", "a:1", "b:1")
+
+	before := make(map[string]string)
+	for i := 0; i < 10000; i++ {
+		key := fmt.Sprintf("key-%d", i)
+		before[key] = ring.Get(key)
+		if other.Get(key) != before[key] {
+			t.Fatalf("Owner of %s depends on insertion order", key)
+		}
+	}
+
+	// 新增服务器后只有归属新服务器的键改变
+	ring.Add("d:1")
+	moved := 0
+	for key, owner := range before {
+		current := ring.Get(key)
+		if current == owner {
+			continue
+		}
+		if current != "d:1" {
+			t.Fatalf("Key %s moved from %s to %s", key, owner, current)
+		}
+		moved++
+	}
+	if moved == 0 || moved > len(before)/2 {
+		t.Errorf("Unexpected number of moved keys: %d", moved)
+	}
+
+	// 删除后恢复原来的归属
+	ring.Remove("d:1")
+	for key, owner := range before {
+		if ring.Get(key) != owner {
+			t.Fatalf("Owner of %s changed after removing d:1", key)
+		}
+	}
+	if nodes := ring.Nodes(); len(nodes) != 3 {
+		t.Errorf("Expected 3 nodes, got %v", nodes)
+	}
+
+	if NewRing(0).Get("key") != "" {
+		t.Errorf("Expected empty owner on empty ring")
+	}
+}
+
+// TestRingPreference 测试偏好列表以所属服务器开头且不重复
+func TestRingPreference(t *testing.T) {
+	ring := NewRing(0)
+	ring.Add("a:1", "b:1", "c:1", "d:1")
+
+	for i := 0; i < 100; i++ {
+		key := fmt.Sprintf("key-%d", i)
+		nodes := ring.Preference(key, 3)
+		if len(nodes) != 3 || nodes[0] != ring.Get(key) {
+			t.Fatalf("Unexpected preference list for %s: %v", key, nodes)
+		}
+		if nodes[0] == nodes[1] || nodes[1] == nodes[2] || nodes[0] == nodes[2] {
+			t.Fatalf("Duplicate servers for %s: %v", key, nodes)
+		}
+	}
+
+	if nodes := ring.Preference("key", 10); len(nodes) != 4 {
+		t.Errorf("Expected all 4 servers, got %v", nodes)
+	}
+	if nodes := NewRing(0).Preference("key", 3); len(nodes) != 0 {
+		t.Errorf("Expected no servers on empty ring, got %v", nodes)
+	}
+}
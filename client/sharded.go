@@ -0,0 +1,421 @@
+package client
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sort"
+	"sync"
+	"time"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/credentials/insecure"
+
+	"kvcache/proto"
+)
+
+// ShardedClient 按一致性哈希将键路由到固定服务器的客户端，适用于多个独立实例的部署
+// 每个键只写入所属的服务器，请求失败时不会重试其他服务器
+type ShardedClient struct {
+	mu       sync.RWMutex
+	replicas int
+	ring     *Ring
+	conns    map[string]*grpc.ClientConn
+	clients  map[string]proto.KeyValueServiceClient
+}
+
+// NewShardedClient 创建分片客户端，replicas为每个服务器的虚拟节点数，不大于0时使用默认值
+func NewShardedClient(addrs []string, replicas int) (*ShardedClient, error) {
+	c := &ShardedClient{
+		replicas: replicas,
+		ring:     NewRing(replicas),
+		conns:    make(map[string]*grpc.ClientConn),
+		clients:  make(map[string]proto.KeyValueServiceClient),
+	}
+	if err := c.SetServers(addrs); err != nil {
+		return nil, err
+	}
+	return c, nil
+}
+
+// SetServers 替换服务器列表，新增的服务器建立连接，移除的服务器关闭连接
+// 之后的请求按新的哈希环路由，已有的数据不会自动迁移
+func (c *ShardedClient) SetServers(addrs []string) error {
+	if len(addrs) == 0 {
+		return fmt.Errorf("no available servers")
+	}
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	// 1. 为新增的服务器建立连接
+	conns := make(map[string]*grpc.ClientConn, len(addrs))
+	for _, addr := range addrs {
+		if conn, ok := c.conns[addr]; ok {
+			conns[addr] = conn
+			continue
+		}
+		if _, ok := conns[addr]; ok {
+			continue
+		}
+		conn, err := grpc.NewClient(addr, grpc.WithTransportCredentials(insecure.NewCredentials()))
+		if err != nil {
+			for a, conn := range conns {
+				if _, ok := c.conns[a]; !ok {
+					conn.Close()
+				}
+			}
+			return fmt.Errorf("failed to connect to %s: %v", addr, err)
+		}
+		conns[addr] = conn
+	}
+
+	// 2. 关闭移除的服务器
+	for addr, conn := range c.conns {
+		if _, ok := conns[addr]; !ok {
+			conn.Close()
+		}
+	}
+
+	// 3. 重建哈希环
+	ring := NewRing(c.replicas)
+	clients := make(map[string]proto.KeyValueServiceClient, len(conns))
+	for addr, conn := range conns {
+		ring.Add(addr)
+		clients[addr] = proto.NewKeyValueServiceClient(conn)
+	}
+	c.ring = ring
+	c.conns = conns
+	c.clients = clients
+	return nil
+}
+
+// AddServer 添加服务器
+func (c *ShardedClient) AddServer(addr string) error {
+	return c.SetServers(append(c.Servers(), addr))
+}
+
+// RemoveServer 移除服务器
+func (c *ShardedClient) RemoveServer(addr string) error {
+	var addrs []string
+	for _, server := range c.Servers() {
+		if server != addr {
+			addrs = append(addrs, server)
+		}
+	}
+	return c.SetServers(addrs)
+}
+
+// Servers 返回当前的服务器列表
+func (c *ShardedClient) Servers() []string {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+	return c.ring.Nodes()
+}
+
+// ServerFor 返回键所属的服务器
+func (c *ShardedClient) ServerFor(key string) string {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+	return c.ring.Get(key)
+}
+
+// Close 关闭所有连接
+func (c *ShardedClient) Close() error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	for _, conn := range c.conns {
+		conn.Close()
+	}
+	c.conns = make(map[string]*grpc.ClientConn)
+	c.clients = make(map[string]proto.KeyValueServiceClient)
+	c.ring = NewRing(c.replicas)
+	return nil
+}
+
+// clientFor 返回键所属服务器的客户端
+func (c *ShardedClient) clientFor(key string) (proto.KeyValueServiceClient, error) {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+
+	client, ok := c.clients[c.ring.Get(key)]
+	if !ok {
+		return nil, fmt.Errorf("no available servers")
+	}
+	return client, nil
+}
+
+// groupKeys 按所属服务器对键分组
+func (c *ShardedClient) groupKeys(keys []string) (map[string][]string, map[string]proto.KeyValueServiceClient) {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+
+	groups := make(map[string][]string)
+	for _, key := range keys {
+		addr := c.ring.Get(key)
+		groups[addr] = append(groups[addr], key)
+	}
+	return groups, c.clients
+}
+
+// allClients 返回排序后的服务器列表和所有服务器的客户端
+func (c *ShardedClient) allClients() ([]string, map[string]proto.KeyValueServiceClient) {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+	return c.ring.Nodes(), c.clients
+}
+
+// fanOut 并发地对每个服务器执行操作，返回第一个错误
+func fanOut(addrs []string, fn func(addr string) error) error {
+	var wg sync.WaitGroup
+	errs := make([]error, len(addrs))
+
+	for i, addr := range addrs {
+		wg.Add(1)
+		go func(i int, addr string) {
+			defer wg.Done()
+			if err := fn(addr); err != nil {
+				errs[i] = fmt.Errorf("server %s: %v", addr, err)
+			}
+		}(i, addr)
+	}
+	wg.Wait()
+
+	for _, err := range errs {
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// Set 设置键值对
+func (c *ShardedClient) Set(ctx context.Context, key string, value []byte, ttl time.Duration) error {
+	client, err := c.clientFor(key)
+	if err != nil {
+		return err
+	}
+
+	resp, err := client.Set(ctx, &proto.SetRequest{Key: []byte(key), Value: value, Ttl: int64(ttl / time.Second)})
+	if err != nil {
+		return statusError(err)
+	}
+	if !resp.Success {
+		return errors.New(resp.Error)
+	}
+	return nil
+}
+
+// Get 获取键值对
+func (c *ShardedClient) Get(ctx context.Context, key string) ([]byte, error) {
+	client, err := c.clientFor(key)
+	if err != nil {
+		return nil, err
+	}
+
+	resp, err := client.Get(ctx, &proto.GetRequest{Key: []byte(key)})
+	if err != nil {
+		return nil, statusError(err)
+	}
+	if resp.Evicted {
+		return nil, ErrEvicted
+	}
+	if !resp.Found {
+		return nil, errKeyNotFound
+	}
+	return resp.Value, nil
+}
+
+// Delete 删除键值对
+func (c *ShardedClient) Delete(ctx context.Context, key string) error {
+	client, err := c.clientFor(key)
+	if err != nil {
+		return err
+	}
+
+	resp, err := client.Delete(ctx, &proto.DeleteRequest{Key: []byte(key)})
+	if err != nil {
+		return statusError(err)
+	}
+	if !resp.Success {
+		return errors.New(resp.Error)
+	}
+	return nil
+}
+
+// Stat 获取键的元数据，不传输值本身
+func (c *ShardedClient) Stat(ctx context.Context, key string) (*proto.KeyMeta, error) {
+	client, err := c.clientFor(key)
+	if err != nil {
+		return nil, err
+	}
+
+	resp, err := client.GetMeta(ctx, &proto.GetMetaRequest{Key: []byte(key)})
+	if err != nil {
+		return nil, statusError(err)
+	}
+	if !resp.Found {
+		return nil, errKeyNotFound
+	}
+	return resp.Meta, nil
+}
+
+// MGet 批量获取，按服务器拆分后并发请求，不存在的键不出现在结果中
+func (c *ShardedClient) MGet(ctx context.Context, keys []string) (map[string][]byte, error) {
+	groups, clients := c.groupKeys(keys)
+
+	var mu sync.Mutex
+	result := make(map[string][]byte, len(keys))
+	err := fanOut(sortedAddrs(groups), func(addr string) error {
+		client, ok := clients[addr]
+		if !ok {
+			return fmt.Errorf("no available servers")
+		}
+
+		req := &proto.MGetRequest{}
+		for _, key := range groups[addr] {
+			req.Keys = append(req.Keys, []byte(key))
+		}
+		resp, err := client.MGet(ctx, req)
+		if err != nil {
+			return statusError(err)
+		}
+		if resp.Error != "" {
+			return errors.New(resp.Error)
+		}
+
+		mu.Lock()
+		defer mu.Unlock()
+		for key, value := range resp.KeyValues {
+			result[key] = value
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return result, nil
+}
+
+// MSet 批量设置，按服务器拆分后并发请求，部分服务器失败时其他服务器的写入不会回滚
+func (c *ShardedClient) MSet(ctx context.Context, kvs map[string][]byte) error {
+	keys := make([]string, 0, len(kvs))
+	for key := range kvs {
+		keys = append(keys, key)
+	}
+	groups, clients := c.groupKeys(keys)
+
+	return fanOut(sortedAddrs(groups), func(addr string) error {
+		client, ok := clients[addr]
+		if !ok {
+			return fmt.Errorf("no available servers")
+		}
+
+		req := &proto.MSetRequest{KeyValues: make(map[string][]byte, len(groups[addr]))}
+		for _, key := range groups[addr] {
+			req.KeyValues[key] = kvs[key]
+		}
+		resp, err := client.MSet(ctx, req)
+		if err != nil {
+			return statusError(err)
+		}
+		if !resp.Success {
+			return errors.New(resp.Error)
+		}
+		return nil
+	})
+}
+
+// MDelete 批量删除，按服务器拆分后并发请求
+func (c *ShardedClient) MDelete(ctx context.Context, keys []string) error {
+	groups, clients := c.groupKeys(keys)
+
+	return fanOut(sortedAddrs(groups), func(addr string) error {
+		client, ok := clients[addr]
+		if !ok {
+			return fmt.Errorf("no available servers")
+		}
+
+		req := &proto.MDeleteRequest{}
+		for _, key := range groups[addr] {
+			req.Keys = append(req.Keys, []byte(key))
+		}
+		resp, err := client.MDelete(ctx, req)
+		if err != nil {
+			return statusError(err)
+		}
+		if !resp.Success {
+			return errors.New(resp.Error)
+		}
+		return nil
+	})
+}
+
+// ScanKeys 扫描所有服务器上前缀匹配的键，返回排序后的合并结果
+func (c *ShardedClient) ScanKeys(ctx context.Context, prefix string) ([]string, error) {
+	addrs, clients := c.allClients()
+
+	var mu sync.Mutex
+	var keys []string
+	err := fanOut(addrs, func(addr string) error {
+		resp, err := clients[addr].ScanKeys(ctx, &proto.ScanRequest{Prefix: []byte(prefix)})
+		if err != nil {
+			return statusError(err)
+		}
+		if resp.Error != "" {
+			return errors.New(resp.Error)
+		}
+
+		mu.Lock()
+		defer mu.Unlock()
+		for _, key := range resp.Keys {
+			keys = append(keys, string(key))
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	sort.Strings(keys)
+	return keys, nil
+}
+
+// Scan 扫描所有服务器上前缀匹配的键值对并合并
+func (c *ShardedClient) Scan(ctx context.Context, prefix string) (map[string][]byte, error) {
+	addrs, clients := c.allClients()
+
+	var mu sync.Mutex
+	result := make(map[string][]byte)
+	err := fanOut(addrs, func(addr string) error {
+		resp, err := clients[addr].ScanKeyValues(ctx, &proto.ScanRequest{Prefix: []byte(prefix)})
+		if err != nil {
+			return statusError(err)
+		}
+		if resp.Error != "" {
+			return errors.New(resp.Error)
+		}
+
+		mu.Lock()
+		defer mu.Unlock()
+		for key, value := range resp.KeyValues {
+			result[key] = value
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return result, nil
+}
+
+// sortedAddrs 返回排序后的服务器地址
+func sortedAddrs(groups map[string][]string) []string {
+	addrs := make([]string, 0, len(groups))
+	for addr := range groups {
+		addrs = append(addrs, addr)
+	}
+	sort.Strings(addrs)
+	return addrs
+}
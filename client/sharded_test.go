@@ -0,0 +1,218 @@
+package client
+
+import (
+	"context"
+	"net"
+	"strings"
+	"sync"
+	"testing"
+
+	"google.golang.org/grpc"
+
+	"kvcache/proto"
+)
+
+// memoryServer 使用内存map的KV服务
+type memoryServer struct {
+	proto.UnimplementedKeyValueServiceServer
+	mu   sync.Mutex
+	data map[string][]byte
+}
+
+func (s *memoryServer) Set(ctx context.Context, req *proto.SetRequest) (*proto.SetResponse, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.data[string(req.Key)] = req.Value
+	return &proto.SetResponse{Success: true}, nil
+}
+
+func (s *memoryServer) Get(ctx context.Context, req *proto.GetRequest) (*proto.GetResponse, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	value, ok := s.data[string(req.Key)]
+	return &proto.GetResponse{Value: value, Found: ok}, nil
+}
+
+func (s *memoryServer) MSet(ctx context.Context, req *proto.MSetRequest) (*proto.MSetResponse, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	for key, value := range req.KeyValues {
+		s.data[key] = value
+	}
+	return &proto.MSetResponse{Success: true}, nil
+}
+
+func (s *memoryServer) MGet(ctx context.Context, req *proto.MGetRequest) (*proto.MGetResponse, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	resp := &proto.MGetResponse{KeyValues: make(map[string][]byte)}
+	for _, key := range req.Keys {
+		if value, ok := s.data[string(key)]; ok {
+			resp.KeyValues[string(key)] = value
+		}
+	}
+	return resp, nil
+}
+
+func (s *memoryServer) MDelete(ctx context.Context, req *proto.MDeleteRequest) (*proto.MDeleteResponse, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	for _, key := range req.Keys {
+		delete(s.data, string(key))
+	}
+	return &proto.MDeleteResponse{Success: true}, nil
+}
+
+func (s *memoryServer) ScanKeyValues(ctx context.Context, req *proto.ScanRequest) (*proto.ScanKeyValuesResponse, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	resp := &proto.ScanKeyValuesResponse{KeyValues: make(map[string][]byte)}
+	for key, value := range s.data {
+		if strings.HasPrefix(key, string(req.Prefix)) {
+			resp.KeyValues[key] = value
+		}
+	}
+	return resp, nil
+}
+
+// size 返回保存的键数量
+func (s *memoryServer) size() int {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return len(s.data)
+}
+
+// has 返回键是否存在
+func (s *memoryServer) has(key string) bool {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	_, ok := s.data[key]
+	return ok
+}
+
+// startMemoryServers 启动n个内存KV服务，返回地址和服务实例
+func startMemoryServers(t *testing.T, n int) ([]string, map[string]*memoryServer) {
+	var addrs []string
+	servers := make(map[string]*memoryServer)
+	for i := 0; i < n; i++ {
+		lis, err := net.Listen("tcp", "127.0.0.1:0")
+		if err != nil {
+			t.Fatalf("Failed to listen: %v", err)
+		}
+		server := &memoryServer{data: make(map[string][]byte)}
+		srv := grpc.NewServer()
+		proto.RegisterKeyValueServiceServer(srv, server)
+		go srv.Serve(lis)
+		t.Cleanup(srv.Stop)
+
+		addr := lis.Addr().String()
+		addrs = append(addrs, addr)
+		servers[addr] = server
+	}
+	return addrs, servers
+}
+
+// TestShardedClient 测试键只写入所属服务器，批量操作和扫描合并所有服务器的结果
+func TestShardedClient(t *testing.T) {
+	addrs, servers := startMemoryServers(t, 3)
+	c, err := NewShardedClient(addrs, 0)
+	if err != nil {
+		t.Fatalf("Failed to create client: %v", err)
+	}
+	defer c.Close()
+
+	ctx := context.Background()
+
+	// 1. 单键写入只落在所属服务器
+	if err := c.Set(ctx, "user:1", []byte("alice"), 0); err != nil {
+		t.Fatalf("Failed to set: %v", err)
+	}
+	owner := c.ServerFor("user:1")
+	for addr, server := range servers {
+		if ok := server.has("user:1"); ok != (addr == owner) {
+			t.Errorf("Unexpected placement on %s: %v", addr, ok)
+		}
+	}
+	if value, err := c.Get(ctx, "user:1"); err != nil || string(value) != "alice" {
+		t.Errorf("Expected alice, got %q: %v", value, err)
+	}
+
+	// 2. 批量写入拆分到多个服务器
+	kvs := make(map[string][]byte)
+	keys := []string{}
+	for _, key := range []string{"a", "b", "c", "d", "e", "f", "g", "h"} {
+		kvs["user:"+key] = []byte(key)
+		keys = append(keys, "user:"+key)
+	}
+	if err := c.MSet(ctx, kvs); err != nil {
+		t.Fatalf("Failed to mset: %v", err)
+	}
+	used := 0
+	for _, server := range servers {
+		if server.size() > 0 {
+			used++
+		}
+	}
+	if used < 2 {
+		t.Errorf("Expected keys to be spread over servers, used %d", used)
+	}
+
+	values, err := c.MGet(ctx, append(keys, "user:missing"))
+	if err != nil {
+		t.Fatalf("Failed to mget: %v", err)
+	}
+	if len(values) != len(kvs) {
+		t.Errorf("Expected %d values, got %d", len(kvs), len(values))
+	}
+
+	// 3. 扫描合并所有服务器
+	scanned, err := c.Scan(ctx, "user:")
+	if err != nil {
+		t.Fatalf("Failed to scan: %v", err)
+	}
+	if len(scanned) != len(kvs)+1 {
+		t.Errorf("Expected %d scanned keys, got %d", len(kvs)+1, len(scanned))
+	}
+
+	// 4. 批量删除
+	if err := c.MDelete(ctx, keys); err != nil {
+		t.Fatalf("Failed to mdelete: %v", err)
+	}
+	if values, _ := c.MGet(ctx, keys); len(values) != 0 {
+		t.Errorf("Expected all keys deleted, got %v", values)
+	}
+}
+
+// TestShardedClientTopology 测试运行时更新服务器列表
+func TestShardedClientTopology(t *testing.T) {
+	addrs, _ := startMemoryServers(t, 3)
+	c, err := NewShardedClient(addrs[:2], 0)
+	if err != nil {
+		t.Fatalf("Failed to create client: %v", err)
+	}
+	defer c.Close()
+
+	if err := c.AddServer(addrs[2]); err != nil {
+		t.Fatalf("Failed to add server: %v", err)
+	}
+	if servers := c.Servers(); len(servers) != 3 {
+		t.Fatalf("Expected 3 servers, got %v", servers)
+	}
+
+	// 移除服务器后它不再拥有任何键
+	if err := c.RemoveServer(addrs[0]); err != nil {
+		t.Fatalf("Failed to remove server: %v", err)
+	}
+	for _, key := range []string{"a", "b", "c", "d", "e", "f"} {
+		if c.ServerFor(key) == addrs[0] {
+			t.Errorf("Key %s still routed to removed server", key)
+		}
+	}
+	if err := c.Set(context.Background(), "a", []byte("1"), 0); err != nil {
+		t.Errorf("Failed to set after topology change: %v", err)
+	}
+
+	if err := c.SetServers(nil); err == nil {
+		t.Errorf("Expected error for empty server list")
+	}
+}
@@ -0,0 +1,303 @@
+package cluster
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"net"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/hashicorp/raft"
+	"google.golang.org/grpc"
+
+	"kvcache/config"
+	"kvcache/service"
+	"kvcache/storage"
+)
+
+// testNode 进程内的集群节点
+type testNode struct {
+	node      *Node
+	service   *service.KVService
+	store     storage.Storage
+	transport *raft.InmemTransport
+	server    *grpc.Server
+}
+
+// stop 依次停止gRPC服务、Raft节点和存储
+func (n *testNode) stop() {
+	n.server.Stop()
+	n.node.Shutdown()
+	n.store.Stop()
+}
+
+// newTestNode 创建使用内存传输和内存日志的节点
+func newTestNode(t *testing.T, id string, bootstrap bool) *testNode {
+	dir := t.TempDir()
+	cfg := config.DefaultConfig()
+	cfg.RocksDB.Path = filepath.Join(dir, "rocksdb")
+	cfg.Value.DiskPath = filepath.Join(dir, "values")
+	cfg.Cluster.Enabled = true
+	cfg.Cluster.NodeID = id
+	cfg.Cluster.DataDir = filepath.Join(dir, "raft")
+	cfg.Cluster.Bootstrap = bootstrap
+
+	store, err := storage.NewStorage(cfg)
+	if err != nil {
+		t.Fatalf("Failed to create storage: %v", err)
+	}
+	kvService := service.NewKVService(store, cfg)
+
+	lis, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatalf("Failed to listen: %v", err)
+	}
+
+	_, transport := raft.NewInmemTransport(raft.ServerAddress(id))
+	logs := raft.NewInmemStore()
+	node, err := newNode(&cfg.Cluster, store, kvService, lis.Addr().String(),
+		logs, logs, raft.NewInmemSnapshotStore(), transport)
+	if err != nil {
+		t.Fatalf("Failed to create node %s: %v", id, err)
+	}
+	kvService.SetCluster(node)
+
+	server := grpc.NewServer()
+	node.Register(server)
+	go server.Serve(lis)
+
+	return &testNode{node: node, service: kvService, store: store, transport: transport, server: server}
+}
+
+// connect 连接所有节点的内存传输
+func connect(nodes ...*testNode) {
+	for _, a := range nodes {
+		for _, b := range nodes {
+			if a != b {
+				a.transport.Connect(b.transport.LocalAddr(), b.transport)
+			}
+		}
+	}
+}
+
+// waitFor 等待条件成立
+func waitFor(t *testing.T, what string, cond func() bool) {
+	deadline := time.Now().Add(10 * time.Second)
+	for !cond() {
+		if time.Now().After(deadline) {
+			t.Fatalf("Timed out waiting for %s", what)
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+}
+
+// TestClusterReplication 测试三个进程内节点的写入复制、跟随者转发和成员变更
+func TestClusterReplication(t *testing.T) {
+	n1 := newTestNode(t, "node1", true)
+	defer n1.stop()
+	n2 := newTestNode(t, "node2", false)
+	defer n2.stop()
+	n3 := newTestNode(t, "node3", false)
+	defer n3.stop()
+	connect(n1, n2, n3)
+
+	ctx := context.Background()
+
+	// 1. 等待引导节点成为领导者并公布gRPC地址
+	waitFor(t, "leader", func() bool {
+		return n1.node.raft.State() == raft.Leader && n1.node.fsm.memberAddr("node1") != ""
+	})
+
+	// 2. 加入其余节点，node3通过跟随者node2转发
+	for _, n := range []*testNode{n2, n3} {
+		member := service.ClusterMember{ID: n.node.id, RaftAddr: n.node.id, GRPCAddr: n.node.grpcAddr}
+		via := n1
+		if n == n3 {
+			via = n2
+		}
+		if err := via.service.AddMember(ctx, member); err != nil {
+			t.Fatalf("Failed to add %s: %v", member.ID, err)
+		}
+	}
+
+	status, err := n3.service.ClusterStatus(ctx)
+	if err != nil {
+		t.Fatalf("Failed to get cluster status: %v", err)
+	}
+	if len(status.Members) != 3 || status.Leader != "node1" {
+		t.Fatalf("Unexpected cluster status: %+v", status)
+	}
+
+	// 3. 领导者写入后跟随者的线性一致读能读到
+	if err := n1.service.Set(ctx, "k1", []byte("v1"), 0); err != nil {
+		t.Fatalf("Failed to set on leader: %v", err)
+	}
+	value, err := n2.service.Get(ctx, "k1")
+	if err != nil || string(value) != "v1" {
+		t.Fatalf("Expected v1 on follower, got %q: %v", value, err)
+	}
+
+	// 4. 跟随者的写入转发给领导者
+	if err := n3.service.MSet(ctx, map[string][]byte{"k2": []byte("v2"), "k3": []byte("v3")}, 0); err != nil {
+		t.Fatalf("Failed to mset on follower: %v", err)
+	}
+	if err := n2.service.Delete(ctx, "k1"); err != nil {
+		t.Fatalf("Failed to delete on follower: %v", err)
+	}
+	for _, n := range []*testNode{n1, n2, n3} {
+		if value, err := n.service.Get(ctx, "k2"); err != nil || string(value) != "v2" {
+			t.Errorf("Expected v2 on %s, got %q: %v", n.node.id, value, err)
+		}
+		if _, err := n.service.Get(ctx, "k1"); err == nil {
+			t.Errorf("Expected k1 to be deleted on %s", n.node.id)
+		}
+	}
+
+	// 5. 未经复制日志的写操作被拒绝
+	if err := n2.service.Append(ctx, "k2", []byte("x")); err != service.ErrNotReplicated {
+		t.Errorf("Expected ErrNotReplicated, got %v", err)
+	}
+
+	// 6. 移除节点后成员列表更新
+	if err := n2.service.RemoveMember(ctx, "node3"); err != nil {
+		t.Fatalf("Failed to remove node3: %v", err)
+	}
+	status, err = n1.service.ClusterStatus(ctx)
+	if err != nil {
+		t.Fatalf("Failed to get cluster status: %v", err)
+	}
+	if len(status.Members) != 2 {
+		t.Errorf("Expected 2 members, got %+v", status.Members)
+	}
+}
+
+// bufferSink 写入内存的快照输出
+type bufferSink struct {
+	bytes.Buffer
+}
+
+func (s *bufferSink) ID() string    { return "test" }
+func (s *bufferSink) Cancel() error { return nil }
+func (s *bufferSink) Close() error  { return nil }
+
+// TestSnapshotRestore 测试快照恢复会覆盖本节点的数据和成员地址
+func TestSnapshotRestore(t *testing.T) {
+	src := newTestNode(t, "src", true)
+	defer src.stop()
+	dst := newTestNode(t, "dst", true)
+	defer dst.stop()
+
+	ctx := context.Background()
+	waitFor(t, "leaders", func() bool {
+		return src.node.raft.State() == raft.Leader && dst.node.raft.State() == raft.Leader
+	})
+
+	// 1. 源节点写入小值和大值，目标节点写入多余和不同的键
+	large := bytes.Repeat([]byte("x"), 2*1024*1024)
+	for key, value := range map[string][]byte{"a": []byte("1"), "b": large} {
+		if err := src.service.Set(ctx, key, value, 0); err != nil {
+			t.Fatalf("Failed to set %s: %v", key, err)
+		}
+	}
+	for key, value := range map[string][]byte{"a": []byte("old"), "extra": []byte("2")} {
+		if err := dst.service.Set(ctx, key, value, 0); err != nil {
+			t.Fatalf("Failed to set %s: %v", key, err)
+		}
+	}
+
+	// 2. 创建快照并恢复到目标节点
+	snapshot, err := src.node.fsm.Snapshot()
+	if err != nil {
+		t.Fatalf("Failed to create snapshot: %v", err)
+	}
+	sink := &bufferSink{}
+	if err := snapshot.Persist(sink); err != nil {
+		t.Fatalf("Failed to persist snapshot: %v", err)
+	}
+	snapshot.Release()
+
+	if err := dst.node.fsm.Restore(nopCloser{&sink.Buffer}); err != nil {
+		t.Fatalf("Failed to restore snapshot: %v", err)
+	}
+
+	// 3. 数据和成员地址与源节点一致
+	if value, err := dst.service.Get(ctx, "a"); err != nil || string(value) != "1" {
+		t.Errorf("Expected a=1, got %q: %v", value, err)
+	}
+	if value, err := dst.service.Get(ctx, "b"); err != nil || !bytes.Equal(value, large) {
+		t.Errorf("Large value mismatch: %v", err)
+	}
+	if _, err := dst.service.Get(ctx, "extra"); err == nil {
+		t.Errorf("Expected extra to be removed")
+	}
+	if addr := dst.node.fsm.memberAddr("src"); addr != src.node.grpcAddr {
+		t.Errorf("Expected member address %s, got %s", src.node.grpcAddr, addr)
+	}
+}
+
+// nopCloser 为Reader添加Close
+type nopCloser struct {
+	*bytes.Buffer
+}
+
+func (nopCloser) Close() error { return nil }
+
+// TestLogStore 测试RocksDB日志存储
+func TestLogStore(t *testing.T) {
+	s, err := newLogStore(filepath.Join(t.TempDir(), "log"))
+	if err != nil {
+		t.Fatalf("Failed to open log store: %v", err)
+	}
+	defer s.Close()
+
+	// 1. 空存储
+	if index, err := s.LastIndex(); err != nil || index != 0 {
+		t.Fatalf("Expected last index 0, got %d: %v", index, err)
+	}
+	if v, err := s.GetUint64([]byte("term")); err != nil || v != 0 {
+		t.Fatalf("Expected missing term to be 0, got %d: %v", v, err)
+	}
+	if _, err := s.Get([]byte("vote")); err != errKeyNotFound {
+		t.Fatalf("Expected errKeyNotFound, got %v", err)
+	}
+
+	// 2. 写入日志，索引跨越单字节边界以检查排序
+	var logs []*raft.Log
+	for i := uint64(1); i <= 300; i++ {
+		logs = append(logs, &raft.Log{Index: i, Term: 1, Data: []byte(fmt.Sprintf("entry-%d", i))})
+	}
+	if err := s.StoreLogs(logs); err != nil {
+		t.Fatalf("Failed to store logs: %v", err)
+	}
+	first, _ := s.FirstIndex()
+	last, _ := s.LastIndex()
+	if first != 1 || last != 300 {
+		t.Errorf("Expected range [1, 300], got [%d, %d]", first, last)
+	}
+
+	var log raft.Log
+	if err := s.GetLog(256, &log); err != nil || string(log.Data) != "entry-256" {
+		t.Errorf("Unexpected log 256: %q, %v", log.Data, err)
+	}
+
+	// 3. 删除前缀日志
+	if err := s.DeleteRange(1, 100); err != nil {
+		t.Fatalf("Failed to delete range: %v", err)
+	}
+	if first, _ := s.FirstIndex(); first != 101 {
+		t.Errorf("Expected first index 101, got %d", first)
+	}
+	if err := s.GetLog(50, &log); err != raft.ErrLogNotFound {
+		t.Errorf("Expected ErrLogNotFound, got %v", err)
+	}
+
+	// 4. 元数据
+	if err := s.SetUint64([]byte("term"), 7); err != nil {
+		t.Fatalf("Failed to set term: %v", err)
+	}
+	if v, err := s.GetUint64([]byte("term")); err != nil || v != 7 {
+		t.Errorf("Expected term 7, got %d: %v", v, err)
+	}
+}
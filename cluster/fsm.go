@@ -0,0 +1,122 @@
+package cluster
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"sync"
+
+	"github.com/hashicorp/raft"
+
+	"kvcache/service"
+	"kvcache/storage"
+)
+
+// Applier 执行已提交的命令，由KVService实现，同时负责维护内存缓存
+type Applier interface {
+	PrepareCommand(cmd *service.Command) error
+	ApplyCommand(cmd *service.Command) error
+	InvalidateChange(event storage.ChangeEvent)
+}
+
+// logEntry 复制日志中的一条记录，写命令或成员地址变更
+type logEntry struct {
+	Command *service.Command `json:"command,omitempty"`
+	Member  *memberEntry     `json:"member,omitempty"`
+}
+
+// memberEntry 成员的gRPC地址，跟随者通过它找到领导者转发请求
+type memberEntry struct {
+	ID       string `json:"id"`
+	GRPCAddr string `json:"grpc_addr,omitempty"`
+	Remove   bool   `json:"remove,omitempty"`
+}
+
+// FSM 将复制日志应用到本节点的存储
+type FSM struct {
+	store   storage.Storage
+	applier Applier
+	tmpDir  string // 创建和恢复快照使用的临时目录
+
+	mu      sync.RWMutex
+	members map[string]string // 成员ID -> gRPC地址
+}
+
+// newFSM 创建状态机
+func newFSM(store storage.Storage, applier Applier, tmpDir string) *FSM {
+	return &FSM{
+		store:   store,
+		applier: applier,
+		tmpDir:  tmpDir,
+		members: make(map[string]string),
+	}
+}
+
+// Apply 执行一条已提交的日志，返回命令的执行错误
+func (f *FSM) Apply(log *raft.Log) interface{} {
+	var entry logEntry
+	if err := json.Unmarshal(log.Data, &entry); err != nil {
+		return fmt.Errorf("invalid log entry at index %d: %v", log.Index, err)
+	}
+
+	switch {
+	case entry.Member != nil:
+		f.setMember(entry.Member)
+		return nil
+	case entry.Command != nil:
+		return f.applier.ApplyCommand(entry.Command)
+	default:
+		return fmt.Errorf("empty log entry at index %d", log.Index)
+	}
+}
+
+// Snapshot 创建RocksDB检查点并记录被引用的磁盘文件，文件内容在Persist时写出
+func (f *FSM) Snapshot() (raft.FSMSnapshot, error) {
+	return newSnapshot(f.store, f.tmpDir, f.memberAddrs())
+}
+
+// Restore 用快照替换本节点的全部数据
+func (f *FSM) Restore(rc io.ReadCloser) error {
+	defer rc.Close()
+
+	members, err := restoreSnapshot(rc, f.store, f.applier, f.tmpDir)
+	if err != nil {
+		return err
+	}
+
+	f.mu.Lock()
+	f.members = members
+	f.mu.Unlock()
+	return nil
+}
+
+// setMember 更新成员的gRPC地址
+func (f *FSM) setMember(m *memberEntry) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	if m.Remove {
+		delete(f.members, m.ID)
+		return
+	}
+	f.members[m.ID] = m.GRPCAddr
+}
+
+// memberAddr 返回成员的gRPC地址
+func (f *FSM) memberAddr(id string) string {
+	f.mu.RLock()
+	defer f.mu.RUnlock()
+	return f.members[id]
+}
+
+// memberAddrs 返回所有成员地址的副本
+func (f *FSM) memberAddrs() map[string]string {
+	f.mu.RLock()
+	defer f.mu.RUnlock()
+
+	members := make(map[string]string, len(f.members))
+	for id, addr := range f.members {
+		members[id] = addr
+	}
+	return members
+}
@@ -0,0 +1,174 @@
+package cluster
+
+import (
+	"encoding/binary"
+	"encoding/json"
+	"errors"
+
+	"github.com/hashicorp/raft"
+	gorocksdb "github.com/linxGnu/grocksdb"
+)
+
+// stableCF Raft任期和投票等元数据的列族
+const stableCF = "stable"
+
+// errKeyNotFound 元数据不存在，raft按该错误信息判断首次启动
+var errKeyNotFound = errors.New("not found")
+
+// logStore 基于RocksDB的Raft日志和元数据存储，与数据存储使用独立的数据库
+type logStore struct {
+	db        *gorocksdb.DB
+	opts      *gorocksdb.Options
+	readOpts  *gorocksdb.ReadOptions
+	writeOpts *gorocksdb.WriteOptions
+	logCF     *gorocksdb.ColumnFamilyHandle
+	stableCF  *gorocksdb.ColumnFamilyHandle
+}
+
+// newLogStore 打开或创建日志存储
+func newLogStore(path string) (*logStore, error) {
+	opts := gorocksdb.NewDefaultOptions()
+	opts.SetCreateIfMissing(true)
+	opts.SetCreateIfMissingColumnFamilies(true)
+
+	db, handles, err := gorocksdb.OpenDbColumnFamilies(opts, path,
+		[]string{"default", stableCF}, []*gorocksdb.Options{opts, opts})
+	if err != nil {
+		opts.Destroy()
+		return nil, err
+	}
+
+	// 日志写入前必须落盘，否则宕机后可能违背已确认的投票和提交
+	writeOpts := gorocksdb.NewDefaultWriteOptions()
+	writeOpts.SetSync(true)
+
+	return &logStore{
+		db:        db,
+		opts:      opts,
+		readOpts:  gorocksdb.NewDefaultReadOptions(),
+		writeOpts: writeOpts,
+		logCF:     handles[0],
+		stableCF:  handles[1],
+	}, nil
+}
+
+// Close 关闭日志存储
+func (s *logStore) Close() error {
+	s.logCF.Destroy()
+	s.stableCF.Destroy()
+	s.db.Close()
+	s.readOpts.Destroy()
+	s.writeOpts.Destroy()
+	s.opts.Destroy()
+	return nil
+}
+
+// FirstIndex 返回第一条日志的索引，没有日志时返回0
+func (s *logStore) FirstIndex() (uint64, error) {
+	iter := s.db.NewIteratorCF(s.readOpts, s.logCF)
+	defer iter.Close()
+
+	iter.SeekToFirst()
+	if !iter.Valid() {
+		return 0, iter.Err()
+	}
+	return binary.BigEndian.Uint64(iter.Key().Data()), nil
+}
+
+// LastIndex 返回最后一条日志的索引，没有日志时返回0
+func (s *logStore) LastIndex() (uint64, error) {
+	iter := s.db.NewIteratorCF(s.readOpts, s.logCF)
+	defer iter.Close()
+
+	iter.SeekToLast()
+	if !iter.Valid() {
+		return 0, iter.Err()
+	}
+	return binary.BigEndian.Uint64(iter.Key().Data()), nil
+}
+
+// GetLog 读取指定索引的日志
+func (s *logStore) GetLog(index uint64, log *raft.Log) error {
+	value, err := s.db.GetCF(s.readOpts, s.logCF, encodeIndex(index))
+	if err != nil {
+		return err
+	}
+	defer value.Free()
+
+	if value.Size() == 0 {
+		return raft.ErrLogNotFound
+	}
+	return json.Unmarshal(value.Data(), log)
+}
+
+// StoreLog 写入一条日志
+func (s *logStore) StoreLog(log *raft.Log) error {
+	return s.StoreLogs([]*raft.Log{log})
+}
+
+// StoreLogs 原子地写入多条日志
+func (s *logStore) StoreLogs(logs []*raft.Log) error {
+	wb := gorocksdb.NewWriteBatch()
+	defer wb.Destroy()
+
+	for _, log := range logs {
+		data, err := json.Marshal(log)
+		if err != nil {
+			return err
+		}
+		wb.PutCF(s.logCF, encodeIndex(log.Index), data)
+	}
+	return s.db.Write(s.writeOpts, wb)
+}
+
+// DeleteRange 删除[min, max]范围内的日志
+func (s *logStore) DeleteRange(min, max uint64) error {
+	wb := gorocksdb.NewWriteBatch()
+	defer wb.Destroy()
+
+	wb.DeleteRangeCF(s.logCF, encodeIndex(min), encodeIndex(max+1))
+	return s.db.Write(s.writeOpts, wb)
+}
+
+// Set 写入元数据
+func (s *logStore) Set(key []byte, val []byte) error {
+	return s.db.PutCF(s.writeOpts, s.stableCF, key, val)
+}
+
+// Get 读取元数据，不存在时返回errKeyNotFound
+func (s *logStore) Get(key []byte) ([]byte, error) {
+	value, err := s.db.GetCF(s.readOpts, s.stableCF, key)
+	if err != nil {
+		return nil, err
+	}
+	defer value.Free()
+
+	if !value.Exists() {
+		return nil, errKeyNotFound
+	}
+	return append([]byte(nil), value.Data()...), nil
+}
+
+// SetUint64 写入整数元数据
+func (s *logStore) SetUint64(key []byte, val uint64) error {
+	return s.Set(key, encodeIndex(val))
+}
+
+// GetUint64 读取整数元数据，不存在时返回0
+func (s *logStore) GetUint64(key []byte) (uint64, error) {
+	value, err := s.Get(key)
+	if errors.Is(err, errKeyNotFound) {
+		return 0, nil
+	}
+	if err != nil {
+		return 0, err
+	}
+	return binary.BigEndian.Uint64(value), nil
+}
+
+// encodeIndex 将日志索引编码为8字节大端序，保证按索引顺序迭代
+func encodeIndex(index uint64) []byte {
+	key := make([]byte, 8)
+	binary.BigEndian.PutUint64(key, index)
+	return key
+}
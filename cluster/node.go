@@ -0,0 +1,445 @@
+package cluster
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net"
+	"os"
+	"path/filepath"
+	"sync"
+	"time"
+
+	"github.com/hashicorp/raft"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/credentials/insecure"
+
+	"kvcache/config"
+	"kvcache/proto"
+	"kvcache/rpcerror"
+	"kvcache/service"
+	"kvcache/storage"
+)
+
+var (
+	// ErrNoLeader 集群当前没有领导者
+	ErrNoLeader = storage.NewError(storage.CodeUnavailable, "cluster has no leader")
+	// ErrNotLeader 请求只能由领导者处理
+	ErrNotLeader = storage.NewError(storage.CodeUnavailable, "node is not the leader")
+	// ErrLeaderNotReady 领导者尚未应用之前任期提交的日志
+	ErrLeaderNotReady = storage.NewError(storage.CodeUnavailable, "leader has not caught up with committed log")
+)
+
+// barrierInterval 等待本节点应用日志时的检查间隔
+const barrierInterval = time.Millisecond
+
+// Node Raft集群节点，写命令经复制日志提交后由状态机在每个节点执行
+type Node struct {
+	id       string
+	grpcAddr string
+	config   *config.ClusterConfig
+	timeout  time.Duration
+
+	raft      *raft.Raft
+	fsm       *FSM
+	transport raft.Transport
+	logs      *logStore // 使用内存存储时为nil
+
+	mu    sync.Mutex
+	conns map[string]*grpc.ClientConn // gRPC地址 -> 连接
+	ready bool                        // 成为领导者后已应用之前的全部日志
+
+	stop chan struct{}
+	wg   sync.WaitGroup
+}
+
+// NewNode 使用TCP传输和RocksDB日志存储创建集群节点，grpcAddr为本节点对其他节点公布的gRPC地址
+func NewNode(cfg *config.Config, store storage.Storage, applier Applier, grpcAddr string) (*Node, error) {
+	cc := &cfg.Cluster
+	if err := cc.Validate(); err != nil {
+		return nil, err
+	}
+	if err := os.MkdirAll(cc.DataDir, 0755); err != nil {
+		return nil, err
+	}
+
+	// 1. 日志存储和快照存储
+	logs, err := newLogStore(filepath.Join(cc.DataDir, "log"))
+	if err != nil {
+		return nil, fmt.Errorf("failed to open raft log: %v", err)
+	}
+	snaps, err := raft.NewFileSnapshotStore(cc.DataDir, 2, os.Stderr)
+	if err != nil {
+		logs.Close()
+		return nil, err
+	}
+
+	// 2. 节点之间的TCP传输
+	addr, err := net.ResolveTCPAddr("tcp", cc.RaftAddr)
+	if err != nil {
+		logs.Close()
+		return nil, err
+	}
+	transport, err := raft.NewTCPTransport(cc.RaftAddr, addr, 3, 10*time.Second, os.Stderr)
+	if err != nil {
+		logs.Close()
+		return nil, err
+	}
+
+	// 3. 启动Raft
+	n, err := newNode(cc, store, applier, grpcAddr, logs, logs, snaps, transport)
+	if err != nil {
+		transport.Close()
+		logs.Close()
+		return nil, err
+	}
+	n.logs = logs
+	return n, nil
+}
+
+// newNode 使用给定的存储和传输创建集群节点，测试时可使用内存实现
+func newNode(cc *config.ClusterConfig, store storage.Storage, applier Applier, grpcAddr string,
+	logs raft.LogStore, stable raft.StableStore, snaps raft.SnapshotStore, transport raft.Transport) (*Node, error) {
+	raftCfg := raft.DefaultConfig()
+	raftCfg.LocalID = raft.ServerID(cc.NodeID)
+	raftCfg.LogLevel = "WARN"
+	if cc.SnapshotThreshold > 0 {
+		raftCfg.SnapshotThreshold = cc.SnapshotThreshold
+	}
+	if cc.SnapshotInterval > 0 {
+		raftCfg.SnapshotInterval = time.Duration(cc.SnapshotInterval) * time.Second
+	}
+
+	fsm := newFSM(store, applier, filepath.Join(cc.DataDir, "tmp"))
+	r, err := raft.NewRaft(raftCfg, fsm, logs, stable, snaps, transport)
+	if err != nil {
+		return nil, err
+	}
+
+	// 首次启动且要求初始化时，以本节点为唯一成员创建集群
+	if cc.Bootstrap {
+		hasState, err := raft.HasExistingState(logs, stable, snaps)
+		if err != nil {
+			r.Shutdown()
+			return nil, err
+		}
+		if !hasState {
+			configuration := raft.Configuration{Servers: []raft.Server{{
+				Suffrage: raft.Voter,
+				ID:       raftCfg.LocalID,
+				Address:  transport.LocalAddr(),
+			}}}
+			if err := r.BootstrapCluster(configuration).Error(); err != nil {
+				r.Shutdown()
+				return nil, err
+			}
+		}
+	}
+
+	n := &Node{
+		id:        cc.NodeID,
+		grpcAddr:  grpcAddr,
+		config:    cc,
+		timeout:   time.Duration(cc.ApplyTimeout) * time.Millisecond,
+		raft:      r,
+		fsm:       fsm,
+		transport: transport,
+		conns:     make(map[string]*grpc.ClientConn),
+		stop:      make(chan struct{}),
+	}
+	n.wg.Add(1)
+	go n.watchLeadership()
+	return n, nil
+}
+
+// Shutdown 停止Raft并关闭日志存储
+func (n *Node) Shutdown() error {
+	close(n.stop)
+	n.wg.Wait()
+
+	err := n.raft.Shutdown().Error()
+
+	n.mu.Lock()
+	for _, conn := range n.conns {
+		conn.Close()
+	}
+	n.conns = make(map[string]*grpc.ClientConn)
+	n.mu.Unlock()
+
+	if closer, ok := n.transport.(raft.WithClose); ok {
+		closer.Close()
+	}
+	if n.logs != nil {
+		n.logs.Close()
+	}
+	return err
+}
+
+// Register 注册节点之间的gRPC服务
+func (n *Node) Register(srv *grpc.Server) {
+	proto.RegisterClusterServer(srv, &server{node: n})
+}
+
+// watchLeadership 成为领导者后等待之前的日志全部应用，并公布本节点的gRPC地址
+func (n *Node) watchLeadership() {
+	defer n.wg.Done()
+
+	for {
+		select {
+		case <-n.stop:
+			return
+		case isLeader := <-n.raft.LeaderCh():
+			n.setReady(false)
+			if !isLeader {
+				continue
+			}
+
+			// 屏障返回时之前任期提交的日志都已应用，之后的读取可以使用提交索引
+			if err := n.raft.Barrier(n.timeout).Error(); err != nil {
+				continue
+			}
+			n.setReady(true)
+
+			if n.fsm.memberAddr(n.id) != n.grpcAddr {
+				n.applyEntry(&logEntry{Member: &memberEntry{ID: n.id, GRPCAddr: n.grpcAddr}})
+			}
+		}
+	}
+}
+
+// setReady 更新领导者是否可以处理读屏障
+func (n *Node) setReady(ready bool) {
+	n.mu.Lock()
+	defer n.mu.Unlock()
+	n.ready = ready
+}
+
+// Apply 提交写命令并返回执行结果，跟随者将命令转发给领导者
+func (n *Node) Apply(ctx context.Context, cmd *service.Command) error {
+	if n.raft.State() == raft.Leader {
+		return n.applyCommand(cmd)
+	}
+
+	data, err := json.Marshal(cmd)
+	if err != nil {
+		return err
+	}
+	client, err := n.leaderClient()
+	if err != nil {
+		return err
+	}
+	ctx, cancel := context.WithTimeout(ctx, n.timeout)
+	defer cancel()
+
+	resp, err := client.Forward(ctx, &proto.ForwardRequest{Command: data})
+	if err != nil {
+		return leaderError("forward to leader", err)
+	}
+	if resp.Error != "" {
+		return errors.New(resp.Error)
+	}
+	return nil
+}
+
+// applyCommand 在领导者上确定命令的绝对过期时间后提交
+func (n *Node) applyCommand(cmd *service.Command) error {
+	if err := n.fsm.applier.PrepareCommand(cmd); err != nil {
+		return err
+	}
+	return n.applyEntry(&logEntry{Command: cmd})
+}
+
+// applyEntry 在领导者上提交日志并等待本节点执行完成
+func (n *Node) applyEntry(entry *logEntry) error {
+	data, err := json.Marshal(entry)
+	if err != nil {
+		return err
+	}
+
+	future := n.raft.Apply(data, n.timeout)
+	if err := future.Error(); err != nil {
+		if errors.Is(err, raft.ErrNotLeader) || errors.Is(err, raft.ErrLeadershipLost) {
+			return ErrNotLeader
+		}
+		return err
+	}
+	if err, ok := future.Response().(error); ok {
+		return err
+	}
+	return nil
+}
+
+// ReadBarrier 等待本节点应用到领导者确认身份时已提交的日志，之后的读取是线性一致的
+func (n *Node) ReadBarrier(ctx context.Context) error {
+	ctx, cancel := context.WithTimeout(ctx, n.timeout)
+	defer cancel()
+
+	// 1. 获取读索引，跟随者向领导者请求
+	var index uint64
+	if n.raft.State() == raft.Leader {
+		var err error
+		if index, err = n.readIndex(); err != nil {
+			return err
+		}
+	} else {
+		client, err := n.leaderClient()
+		if err != nil {
+			return err
+		}
+		resp, err := client.ReadIndex(ctx, &proto.ReadIndexRequest{})
+		if err != nil {
+			return leaderError("get read index from leader", err)
+		}
+		if resp.Error != "" {
+			return errors.New(resp.Error)
+		}
+		index = resp.Index
+	}
+
+	// 2. 等待本节点应用到读索引
+	ticker := time.NewTicker(barrierInterval)
+	defer ticker.Stop()
+	for n.raft.AppliedIndex() < index {
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case <-ticker.C:
+		}
+	}
+	return nil
+}
+
+// readIndex 确认本节点仍是领导者后返回提交索引
+func (n *Node) readIndex() (uint64, error) {
+	n.mu.Lock()
+	ready := n.ready
+	n.mu.Unlock()
+	if !ready {
+		return 0, ErrLeaderNotReady
+	}
+
+	index := n.raft.CommitIndex()
+	if err := n.raft.VerifyLeader().Error(); err != nil {
+		return 0, ErrNotLeader
+	}
+	return index, nil
+}
+
+// Status 返回集群状态
+func (n *Node) Status() (*service.ClusterStatus, error) {
+	future := n.raft.GetConfiguration()
+	if err := future.Error(); err != nil {
+		return nil, err
+	}
+	_, leaderID := n.raft.LeaderWithID()
+
+	status := &service.ClusterStatus{
+		NodeID:       n.id,
+		State:        n.raft.State().String(),
+		Leader:       string(leaderID),
+		Term:         n.raft.CurrentTerm(),
+		CommitIndex:  n.raft.CommitIndex(),
+		AppliedIndex: n.raft.AppliedIndex(),
+	}
+	for _, srv := range future.Configuration().Servers {
+		status.Members = append(status.Members, service.ClusterMember{
+			ID:       string(srv.ID),
+			RaftAddr: string(srv.Address),
+			GRPCAddr: n.fsm.memberAddr(string(srv.ID)),
+			Voter:    srv.Suffrage == raft.Voter,
+			Leader:   srv.ID == leaderID,
+		})
+	}
+	return status, nil
+}
+
+// AddMember 将节点加入集群作为投票成员，跟随者转发给领导者
+func (n *Node) AddMember(ctx context.Context, member service.ClusterMember) error {
+	if n.raft.State() != raft.Leader {
+		client, err := n.leaderClient()
+		if err != nil {
+			return err
+		}
+		resp, err := client.AddMember(ctx, &proto.AddMemberRequest{
+			Id:       member.ID,
+			RaftAddr: member.RaftAddr,
+			GrpcAddr: member.GRPCAddr,
+		})
+		if err != nil {
+			return leaderError("forward to leader", err)
+		}
+		if resp.Error != "" {
+			return errors.New(resp.Error)
+		}
+		return nil
+	}
+
+	future := n.raft.AddVoter(raft.ServerID(member.ID), raft.ServerAddress(member.RaftAddr), 0, n.timeout)
+	if err := future.Error(); err != nil {
+		return err
+	}
+	if member.GRPCAddr == "" {
+		return nil
+	}
+	return n.applyEntry(&logEntry{Member: &memberEntry{ID: member.ID, GRPCAddr: member.GRPCAddr}})
+}
+
+// RemoveMember 将节点移出集群，跟随者转发给领导者
+func (n *Node) RemoveMember(ctx context.Context, id string) error {
+	if n.raft.State() != raft.Leader {
+		client, err := n.leaderClient()
+		if err != nil {
+			return err
+		}
+		resp, err := client.RemoveMember(ctx, &proto.RemoveMemberRequest{Id: id})
+		if err != nil {
+			return leaderError("forward to leader", err)
+		}
+		if resp.Error != "" {
+			return errors.New(resp.Error)
+		}
+		return nil
+	}
+
+	// 先删除地址再移除成员，移除领导者自身后无法再提交日志
+	if err := n.applyEntry(&logEntry{Member: &memberEntry{ID: id, Remove: true}}); err != nil {
+		return err
+	}
+	return n.raft.RemoveServer(raft.ServerID(id), 0, n.timeout).Error()
+}
+
+// leaderError 将领导者返回的gRPC状态转换为同一类别的错误，无法分类的错误注明失败的操作
+// 旧版本的领导者在响应的Error字段中返回错误，调用方仍需检查该字段
+func leaderError(action string, err error) error {
+	if converted := rpcerror.FromStatus(err); converted != err {
+		return converted
+	}
+	return fmt.Errorf("failed to %s: %v", action, err)
+}
+
+// leaderClient 返回到领导者gRPC服务的客户端
+func (n *Node) leaderClient() (proto.ClusterClient, error) {
+	_, leaderID := n.raft.LeaderWithID()
+	if leaderID == "" {
+		return nil, ErrNoLeader
+	}
+	addr := n.fsm.memberAddr(string(leaderID))
+	if addr == "" {
+		return nil, fmt.Errorf("grpc address of leader %s is unknown", leaderID)
+	}
+
+	n.mu.Lock()
+	defer n.mu.Unlock()
+
+	conn, ok := n.conns[addr]
+	if !ok {
+		var err error
+		conn, err = grpc.NewClient(addr, grpc.WithTransportCredentials(insecure.NewCredentials()))
+		if err != nil {
+			return nil, err
+		}
+		n.conns[addr] = conn
+	}
+	return proto.NewClusterClient(conn), nil
+}
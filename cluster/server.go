@@ -0,0 +1,74 @@
+package cluster
+
+import (
+	"context"
+	"encoding/json"
+
+	"github.com/hashicorp/raft"
+
+	"kvcache/proto"
+	"kvcache/rpcerror"
+	"kvcache/service"
+	"kvcache/storage"
+)
+
+// server 领导者为跟随者提供的gRPC服务，收到请求的节点不是领导者时返回错误，不再继续转发
+// 失败的请求返回按错误类别转换的gRPC状态，跟随者转换回同一类别的错误
+type server struct {
+	proto.UnimplementedClusterServer
+	node *Node
+}
+
+// Forward 提交跟随者转发的写命令
+func (s *server) Forward(ctx context.Context, req *proto.ForwardRequest) (*proto.ForwardResponse, error) {
+	if s.node.raft.State() != raft.Leader {
+		return nil, rpcerror.Status(ErrNotLeader)
+	}
+
+	cmd := &service.Command{}
+	if err := json.Unmarshal(req.Command, cmd); err != nil {
+		return nil, rpcerror.Status(storage.Errorf(storage.CodeInvalidArgument, "invalid command: %v", err))
+	}
+	if err := s.node.applyCommand(cmd); err != nil {
+		return nil, rpcerror.Status(err)
+	}
+	return &proto.ForwardResponse{Success: true}, nil
+}
+
+// ReadIndex 确认领导者身份后返回读索引
+func (s *server) ReadIndex(ctx context.Context, req *proto.ReadIndexRequest) (*proto.ReadIndexResponse, error) {
+	if s.node.raft.State() != raft.Leader {
+		return nil, rpcerror.Status(ErrNotLeader)
+	}
+
+	index, err := s.node.readIndex()
+	if err != nil {
+		return nil, rpcerror.Status(err)
+	}
+	return &proto.ReadIndexResponse{Index: index}, nil
+}
+
+// AddMember 添加跟随者转发的成员
+func (s *server) AddMember(ctx context.Context, req *proto.AddMemberRequest) (*proto.AddMemberResponse, error) {
+	if s.node.raft.State() != raft.Leader {
+		return nil, rpcerror.Status(ErrNotLeader)
+	}
+
+	member := service.ClusterMember{ID: req.Id, RaftAddr: req.RaftAddr, GRPCAddr: req.GrpcAddr}
+	if err := s.node.AddMember(ctx, member); err != nil {
+		return nil, rpcerror.Status(err)
+	}
+	return &proto.AddMemberResponse{Success: true}, nil
+}
+
+// RemoveMember 移除跟随者转发的成员
+func (s *server) RemoveMember(ctx context.Context, req *proto.RemoveMemberRequest) (*proto.RemoveMemberResponse, error) {
+	if s.node.raft.State() != raft.Leader {
+		return nil, rpcerror.Status(ErrNotLeader)
+	}
+
+	if err := s.node.RemoveMember(ctx, req.Id); err != nil {
+		return nil, rpcerror.Status(err)
+	}
+	return &proto.RemoveMemberResponse{Success: true}, nil
+}
@@ -0,0 +1,452 @@
+package cluster
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"io/fs"
+	"os"
+	"path"
+	"path/filepath"
+
+	"github.com/hashicorp/raft"
+	"google.golang.org/protobuf/encoding/protodelim"
+
+	"kvcache/config"
+	"kvcache/proto"
+	"kvcache/service"
+	"kvcache/storage"
+)
+
+const (
+	// chunkSize 快照文件分块写出的大小
+	chunkSize = 1 << 20
+	// manifestPath 快照清单在快照流中的路径
+	manifestPath = "manifest.json"
+)
+
+// snapshotManifest 快照清单，记录成员地址和检查点引用的磁盘文件
+type snapshotManifest struct {
+	Members map[string]string   `json:"members"`
+	Blobs   map[string][]string `json:"blobs"` // 命名空间 -> 磁盘文件名
+}
+
+// fsmSnapshot 状态机快照，由RocksDB检查点和磁盘文件组成
+// 快照流是一系列带长度前缀的SnapshotChunk：先是清单，然后是检查点文件（db/），最后是磁盘文件（blobs/<命名空间>/）
+type fsmSnapshot struct {
+	store    storage.Storage
+	dir      string
+	manifest snapshotManifest
+}
+
+// newSnapshot 在tmpDir中创建检查点并记录磁盘文件清单
+func newSnapshot(store storage.Storage, tmpDir string, members map[string]string) (*fsmSnapshot, error) {
+	if err := os.MkdirAll(tmpDir, 0755); err != nil {
+		return nil, err
+	}
+	dir, err := os.MkdirTemp(tmpDir, "snapshot-")
+	if err != nil {
+		return nil, err
+	}
+
+	snapshot := &fsmSnapshot{
+		store:    store,
+		dir:      dir,
+		manifest: snapshotManifest{Members: members, Blobs: make(map[string][]string)},
+	}
+	if err := snapshot.prepare(); err != nil {
+		snapshot.Release()
+		return nil, err
+	}
+	return snapshot, nil
+}
+
+// prepare 创建检查点并列出各命名空间引用的磁盘文件
+func (s *fsmSnapshot) prepare() error {
+	if _, err := s.store.CreateCheckpoint(filepath.Join(s.dir, "db")); err != nil {
+		return err
+	}
+
+	namespaces, err := s.store.ListNamespaces()
+	if err != nil {
+		return err
+	}
+	for _, nsCfg := range namespaces {
+		view, err := s.store.Namespace(nsCfg.Name)
+		if err != nil {
+			return err
+		}
+		names, err := view.ListBlobs()
+		if err != nil {
+			return err
+		}
+		s.manifest.Blobs[nsCfg.Name] = names
+	}
+	return nil
+}
+
+// Persist 将快照写入sink
+func (s *fsmSnapshot) Persist(sink raft.SnapshotSink) error {
+	if err := s.write(sink); err != nil {
+		sink.Cancel()
+		return err
+	}
+	return sink.Close()
+}
+
+// Release 删除检查点
+func (s *fsmSnapshot) Release() {
+	os.RemoveAll(s.dir)
+}
+
+// write 按顺序写出清单、检查点文件和磁盘文件
+func (s *fsmSnapshot) write(w io.Writer) error {
+	bw := bufio.NewWriter(w)
+
+	// 1. 清单
+	data, err := json.Marshal(&s.manifest)
+	if err != nil {
+		return err
+	}
+	if err := writeChunk(bw, manifestPath, data); err != nil {
+		return err
+	}
+
+	// 2. 检查点文件
+	db := filepath.Join(s.dir, "db")
+	err = filepath.WalkDir(db, func(name string, d fs.DirEntry, err error) error {
+		if err != nil || d.IsDir() {
+			return err
+		}
+		rel, err := filepath.Rel(db, name)
+		if err != nil {
+			return err
+		}
+		f, err := os.Open(name)
+		if err != nil {
+			return err
+		}
+		defer f.Close()
+		return copyChunks(bw, "db/"+filepath.ToSlash(rel), f)
+	})
+	if err != nil {
+		return err
+	}
+
+	// 3. 磁盘文件，检查点之后被释放的文件对应的键会被后续日志覆盖或删除，跳过即可
+	for ns, names := range s.manifest.Blobs {
+		view, err := s.store.Namespace(ns)
+		if err != nil {
+			continue
+		}
+		for _, fileName := range names {
+			r, err := view.OpenBlob(fileName)
+			if os.IsNotExist(err) {
+				continue
+			}
+			if err != nil {
+				return err
+			}
+			err = copyChunks(bw, "blobs/"+ns+"/"+fileName, r)
+			r.Close()
+			if err != nil {
+				return err
+			}
+		}
+	}
+
+	return bw.Flush()
+}
+
+// writeChunk 写出一个带长度前缀的分块
+func writeChunk(w io.Writer, name string, data []byte) error {
+	_, err := protodelim.MarshalTo(w, &proto.SnapshotChunk{Path: name, Data: data})
+	return err
+}
+
+// copyChunks 分块写出文件内容，空文件也写出一个分块
+func copyChunks(w io.Writer, name string, r io.Reader) error {
+	buf := make([]byte, chunkSize)
+	written := false
+	for {
+		n, err := io.ReadFull(r, buf)
+		if n > 0 || !written {
+			if err := writeChunk(w, name, buf[:n]); err != nil {
+				return err
+			}
+			written = true
+		}
+		if err == io.EOF || err == io.ErrUnexpectedEOF {
+			return nil
+		}
+		if err != nil {
+			return err
+		}
+	}
+}
+
+// restoreSnapshot 用快照替换存储中的全部数据，返回快照中的成员地址
+// 快照检查点以只读从节点方式打开，与本地数据逐键比较后写入差异，内存缓存同步失效
+func restoreSnapshot(r io.Reader, store storage.Storage, applier Applier, tmpDir string) (map[string]string, error) {
+	if err := os.MkdirAll(tmpDir, 0755); err != nil {
+		return nil, err
+	}
+	dir, err := os.MkdirTemp(tmpDir, "restore-")
+	if err != nil {
+		return nil, err
+	}
+	defer os.RemoveAll(dir)
+
+	// 1. 解出清单、检查点和磁盘文件
+	manifest, err := unpackSnapshot(r, dir)
+	if err != nil {
+		return nil, err
+	}
+
+	// 2. 打开检查点，从节点不启动淘汰也不删除过期键
+	cfg := config.DefaultConfig()
+	cfg.RocksDB.Path = filepath.Join(dir, "db")
+	cfg.Value.DiskPath = filepath.Join(dir, "values")
+	cfg.Replication.LeaderAddr = "snapshot"
+	snap, err := storage.NewStorage(cfg)
+	if err != nil {
+		return nil, fmt.Errorf("failed to open snapshot: %v", err)
+	}
+	defer snap.Stop()
+
+	// 3. 同步命名空间
+	snapNamespaces, err := snap.ListNamespaces()
+	if err != nil {
+		return nil, err
+	}
+	if err := restoreNamespaces(snapNamespaces, store, applier); err != nil {
+		return nil, err
+	}
+
+	// 4. 逐个命名空间同步磁盘文件和键
+	for _, nsCfg := range snapNamespaces {
+		snapView, err := snap.Namespace(nsCfg.Name)
+		if err != nil {
+			return nil, err
+		}
+		view, err := store.Namespace(nsCfg.Name)
+		if err != nil {
+			return nil, err
+		}
+		if err := restoreBlobs(filepath.Join(dir, "blobs", nsCfg.Name), view); err != nil {
+			return nil, err
+		}
+		if err := restoreKeys(nsCfg.Name, snapView, view, applier); err != nil {
+			return nil, err
+		}
+	}
+
+	// 5. 同步全局配置
+	snapCfg, err := snap.GetConfig()
+	if err != nil {
+		return nil, err
+	}
+	if err := applier.ApplyCommand(&service.Command{Op: service.CommandUpdateConfig, Config: snapCfg}); err != nil {
+		return nil, err
+	}
+
+	return manifest.Members, nil
+}
+
+// unpackSnapshot 将快照流解出到dir，返回清单
+func unpackSnapshot(r io.Reader, dir string) (*snapshotManifest, error) {
+	br := bufio.NewReader(r)
+
+	var (
+		manifest []byte
+		current  string
+		f        *os.File
+	)
+	closeFile := func() error {
+		if f == nil {
+			return nil
+		}
+		err := f.Close()
+		f = nil
+		return err
+	}
+	defer closeFile()
+
+	for {
+		chunk := &proto.SnapshotChunk{}
+		err := protodelim.UnmarshalFrom(br, chunk)
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return nil, fmt.Errorf("failed to read snapshot: %v", err)
+		}
+
+		if chunk.Path == manifestPath {
+			manifest = append(manifest, chunk.Data...)
+			continue
+		}
+		if chunk.Path != current {
+			if err := closeFile(); err != nil {
+				return nil, err
+			}
+			// 限制文件在临时目录内
+			name := filepath.Join(dir, filepath.FromSlash(path.Clean("/"+chunk.Path)))
+			if err := os.MkdirAll(filepath.Dir(name), 0755); err != nil {
+				return nil, err
+			}
+			if f, err = os.OpenFile(name, os.O_CREATE|os.O_EXCL|os.O_WRONLY, 0644); err != nil {
+				return nil, err
+			}
+			current = chunk.Path
+		}
+		if _, err := f.Write(chunk.Data); err != nil {
+			return nil, err
+		}
+	}
+	if err := closeFile(); err != nil {
+		return nil, err
+	}
+
+	if manifest == nil {
+		return nil, errors.New("snapshot has no manifest")
+	}
+	m := &snapshotManifest{}
+	if err := json.Unmarshal(manifest, m); err != nil {
+		return nil, fmt.Errorf("invalid snapshot manifest: %v", err)
+	}
+	return m, nil
+}
+
+// restoreNamespaces 删除快照中不存在的命名空间，创建快照中新增的命名空间
+func restoreNamespaces(snapNamespaces []*config.NamespaceConfig, store storage.Storage, applier Applier) error {
+	want := make(map[string]bool, len(snapNamespaces))
+	for _, nsCfg := range snapNamespaces {
+		want[nsCfg.Name] = true
+	}
+
+	namespaces, err := store.ListNamespaces()
+	if err != nil {
+		return err
+	}
+	have := make(map[string]bool, len(namespaces))
+	for _, nsCfg := range namespaces {
+		have[nsCfg.Name] = true
+		if nsCfg.Name == config.DefaultNamespace || want[nsCfg.Name] {
+			continue
+		}
+		if err := applier.ApplyCommand(&service.Command{Op: service.CommandDropNamespace, Namespace: nsCfg.Name}); err != nil {
+			return err
+		}
+	}
+
+	for _, nsCfg := range snapNamespaces {
+		if have[nsCfg.Name] {
+			continue
+		}
+		cmd := &service.Command{Op: service.CommandCreateNamespace, Namespace: nsCfg.Name, NsConfig: nsCfg}
+		if err := applier.ApplyCommand(cmd); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// restoreBlobs 导入本地不存在的磁盘文件
+func restoreBlobs(dir string, view storage.Storage) error {
+	entries, err := os.ReadDir(dir)
+	if os.IsNotExist(err) {
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+
+	for _, entry := range entries {
+		fileName := entry.Name()
+		if view.HasBlob(fileName) {
+			continue
+		}
+		f, err := os.Open(filepath.Join(dir, fileName))
+		if err != nil {
+			return err
+		}
+		err = view.ImportBlob(fileName, f)
+		f.Close()
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// restoreKeys 删除快照中不存在的键，写入与快照不同的键
+func restoreKeys(namespace string, snap, view storage.Storage, applier Applier) error {
+	snapKeys, err := snap.Scan(nil)
+	if err != nil {
+		return err
+	}
+	keys, err := view.Scan(nil)
+	if err != nil {
+		return err
+	}
+
+	// 1. 删除多余的键
+	want := make(map[string]bool, len(snapKeys))
+	for _, key := range snapKeys {
+		want[string(key)] = true
+	}
+	for _, key := range keys {
+		if want[string(key)] {
+			continue
+		}
+		if err := view.Delete(key); err != nil {
+			return err
+		}
+		applier.InvalidateChange(storage.ChangeEvent{Type: storage.EventDelete, Namespace: namespace, Key: key})
+	}
+
+	// 2. 写入不同的键，修订号由本地分配
+	for _, key := range snapKeys {
+		entry, err := snap.ExportEntry(key)
+		if err != nil {
+			return err
+		}
+		if entry == nil {
+			continue
+		}
+		current, err := view.ExportEntry(key)
+		if err != nil {
+			return err
+		}
+		if sameEntry(current, entry) {
+			continue
+		}
+
+		event := &storage.ChangeEvent{Type: storage.EventPut, Namespace: namespace, Key: key}
+		if err := view.ApplyChange(event, entry); err != nil {
+			return err
+		}
+		applier.InvalidateChange(*event)
+	}
+	return nil
+}
+
+// sameEntry 判断本地的键是否与快照一致，忽略访问时间
+func sameEntry(current, entry *storage.ReplicaEntry) bool {
+	if current == nil {
+		return false
+	}
+	a, b := current.Meta, entry.Meta
+	return a.Version == b.Version &&
+		a.UpdatedAt == b.UpdatedAt &&
+		a.CreatedAt == b.CreatedAt &&
+		a.Size == b.Size &&
+		a.ExpiresAt == b.ExpiresAt &&
+		a.DiskFile == b.DiskFile &&
+		a.Evicted == b.Evicted &&
+		bytes.Equal(current.Value, entry.Value)
+}
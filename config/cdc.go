@@ -0,0 +1,63 @@
+package config
+
+import (
+	"errors"
+	"fmt"
+)
+
+const (
+	// CDCSinkFile 写入本地按大小轮转的JSON Lines文件
+	CDCSinkFile = "file"
+	// CDCSinkWebhook 以HTTP POST推送到外部地址
+	CDCSinkWebhook = "webhook"
+)
+
+// CDCConfig 变更数据捕获配置，每个输出独立消费变更日志并保存检查点
+type CDCConfig struct {
+	BatchSize    int             `json:"batch_size"`    // 每次投递的最大事件数
+	PollInterval int             `json:"poll_interval"` // 没有新变更时的轮询间隔（毫秒）
+	Sinks        []CDCSinkConfig `json:"sinks"`
+}
+
+// CDCSinkConfig 变更数据输出配置
+type CDCSinkConfig struct {
+	Name string `json:"name"` // 检查点名称，修改后会从最早保留的变更重新投递
+	Type string `json:"type"` // file 或 webhook
+
+	// file
+	Dir         string `json:"dir"`
+	MaxFileSize int64  `json:"max_file_size"` // 单个文件的最大字节数，超出后轮转
+	MaxFiles    int    `json:"max_files"`     // 保留的文件数量，0表示不限制
+
+	// webhook
+	URL     string            `json:"url"`
+	Timeout int               `json:"timeout"` // 请求超时（毫秒）
+	Headers map[string]string `json:"headers,omitempty"`
+}
+
+// Validate 检查输出配置并填充默认值
+func (c *CDCSinkConfig) Validate() error {
+	if c.Name == "" {
+		return errors.New("cdc sink name cannot be empty")
+	}
+
+	switch c.Type {
+	case CDCSinkFile:
+		if c.Dir == "" {
+			return fmt.Errorf("cdc sink %s: dir cannot be empty", c.Name)
+		}
+		if c.MaxFileSize <= 0 {
+			c.MaxFileSize = 64 << 20 // 64MB
+		}
+	case CDCSinkWebhook:
+		if c.URL == "" {
+			return fmt.Errorf("cdc sink %s: url cannot be empty", c.Name)
+		}
+		if c.Timeout <= 0 {
+			c.Timeout = 5000
+		}
+	default:
+		return fmt.Errorf("cdc sink %s: invalid type %q", c.Name, c.Type)
+	}
+	return nil
+}
@@ -0,0 +1,36 @@
+package config
+
+import "errors"
+
+// ClusterConfig Raft集群配置，启用后写操作通过复制日志在所有节点上执行
+type ClusterConfig struct {
+	Enabled           bool   `json:"enabled"`
+	NodeID            string `json:"node_id"`            // 节点在集群中的唯一标识
+	RaftAddr          string `json:"raft_addr"`          // Raft传输的监听地址，其他节点通过该地址连接
+	DataDir           string `json:"data_dir"`           // Raft日志和快照的存储目录
+	Bootstrap         bool   `json:"bootstrap"`          // 以本节点为唯一成员初始化新集群，已初始化时忽略
+	ApplyTimeout      int    `json:"apply_timeout"`      // 提交命令和线性一致读的超时（毫秒）
+	SnapshotThreshold uint64 `json:"snapshot_threshold"` // 距上次快照的日志条数达到该值时创建快照
+	SnapshotInterval  int    `json:"snapshot_interval"`  // 检查是否需要快照的间隔（秒）
+	LinearizableReads bool   `json:"linearizable_reads"` // 读取前确认本节点已应用读取时刻已提交的日志
+}
+
+// Validate 检查集群配置
+func (c *ClusterConfig) Validate() error {
+	if !c.Enabled {
+		return nil
+	}
+	if c.NodeID == "" {
+		return errors.New("cluster node_id cannot be empty")
+	}
+	if c.RaftAddr == "" {
+		return errors.New("cluster raft_addr cannot be empty")
+	}
+	if c.DataDir == "" {
+		return errors.New("cluster data_dir cannot be empty")
+	}
+	if c.ApplyTimeout <= 0 {
+		return errors.New("cluster apply_timeout must be positive")
+	}
+	return nil
+}
@@ -29,12 +29,7 @@ type Config struct {
 		DiskPath      string `json:"disk_path"`
 	} `json:"value"`
 
-	Eviction struct {
-		Enabled            bool    `json:"enabled"`
-		DiskUsageThreshold float64 `json:"disk_usage_threshold"`
-		CheckInterval      int     `json:"check_interval"`
-		BatchSize          int     `json:"batch_size"`
-	} `json:"eviction"`
+	Eviction EvictionConfig `json:"eviction"`
 
 	Monitoring struct {
 		Enabled     bool   `json:"enabled"`
@@ -42,10 +37,45 @@ type Config struct {
 		HealthPath  string `json:"health_path"`
 	} `json:"monitoring"`
 
-	Cache struct {
-		Enabled       bool `json:"enabled"`
-		SizeThreshold int  `json:"size_threshold"` // 缓存阈值，单位字节
-	} `json:"cache"`
+	Cache CacheConfig `json:"cache"`
+
+	ChangeLog struct {
+		Retention int64 `json:"retention"` // 变更日志保留的事件数量
+	} `json:"changelog"`
+
+	Versioned struct {
+		TombstoneRetention int64 `json:"tombstone_retention"` // 带版本删除的墓碑保留时间（秒），应长于暂存写入和读修复的时间，0表示永久保留
+	} `json:"versioned"`
+
+	CDC CDCConfig `json:"cdc"`
+
+	Replication struct {
+		LeaderAddr string `json:"leader_addr"` // 主节点的gRPC地址，非空时作为只读从节点启动
+	} `json:"replication"`
+
+	Cluster ClusterConfig `json:"cluster"`
+
+	Sharding ShardingConfig `json:"sharding"`
+
+	Origin OriginConfig `json:"origin"`
+
+	Proxy ProxyConfig `json:"proxy"`
+}
+
+// EvictionConfig 淘汰配置
+type EvictionConfig struct {
+	Enabled            bool    `json:"enabled"`
+	DiskUsageThreshold float64 `json:"disk_usage_threshold"`
+	CheckInterval      int     `json:"check_interval"`
+	BatchSize          int     `json:"batch_size"`
+	MarkerRetention    int64   `json:"marker_retention"` // 已淘汰键的淘汰标记保留时间（秒），超过后删除键，0表示永久保留
+}
+
+// CacheConfig 缓存配置
+type CacheConfig struct {
+	Enabled       bool  `json:"enabled"`
+	SizeThreshold int   `json:"size_threshold"` // 缓存阈值，单位字节
+	MaxBytes      int64 `json:"max_bytes"`      // 缓存容量，单位字节
 }
 
 // DefaultConfig 返回默认配置
@@ -74,9 +104,36 @@ func DefaultConfig() *Config {
 
 	config.Cache.Enabled = true
 	config.Cache.SizeThreshold = 10240 // 默认10KB
+	config.Cache.MaxBytes = 64 << 20   // 默认64MB
 
 	config.RocksDB.BlockCacheSize = 64 // 默认64MB
 
+	config.ChangeLog.Retention = 100000
+
+	config.Versioned.TombstoneRetention = 7 * 24 * 3600 // 7天
+
+	config.CDC.BatchSize = 500
+	config.CDC.PollInterval = 1000 // 1秒
+
+	config.Cluster.DataDir = "./raft"
+	config.Cluster.ApplyTimeout = 5000 // 5秒
+	config.Cluster.SnapshotThreshold = 8192
+	config.Cluster.SnapshotInterval = 120
+	config.Cluster.LinearizableReads = true
+
+	config.Sharding.Mode = ShardingModeForward
+	config.Sharding.ReloadInterval = 5
+	config.Sharding.VirtualNodes = 160
+
+	config.Origin.Timeout = 5000 // 5秒
+	config.Origin.BatchSize = 100
+	config.Origin.PollInterval = 1000 // 1秒
+
+	config.Proxy.Addr = ":33200"
+	config.Proxy.Namespace = "proxy"
+	config.Proxy.Timeout = 30000        // 30秒
+	config.Proxy.MaxBodySize = 64 << 20 // 64MB
+
 	return config
 }
 
@@ -92,5 +149,5 @@ func FromJSON(data []byte) (*Config, error) {
 	return config, err
 }
 
-// ConfigKey 配置存储在RocksDB中的键
+// ConfigKey 配置在RocksDB元数据列族中的键，不属于用户键空间
 const ConfigKey = "global.config"
@@ -36,6 +36,14 @@ func TestDefaultConfig(t *testing.T) {
 	if cfg.Eviction.BatchSize != 100 {
 		t.Errorf("Expected Eviction.BatchSize to be 100, got %d", cfg.Eviction.BatchSize)
 	}
+
+	if cfg.Cache.MaxBytes != 64<<20 {
+		t.Errorf("Expected Cache.MaxBytes to be 67108864, got %d", cfg.Cache.MaxBytes)
+	}
+
+	if cfg.Versioned.TombstoneRetention != 7*24*3600 {
+		t.Errorf("Expected Versioned.TombstoneRetention to be 604800, got %d", cfg.Versioned.TombstoneRetention)
+	}
 }
 
 // TestFromJSON 测试从JSON字符串解析配置
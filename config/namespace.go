@@ -0,0 +1,49 @@
+package config
+
+import (
+	"path/filepath"
+	"regexp"
+)
+
+// DefaultNamespace 默认命名空间，兼容未指定命名空间的请求
+const DefaultNamespace = "default"
+
+// namespaceNamePattern 命名空间名称格式
+var namespaceNamePattern = regexp.MustCompile(`^[a-zA-Z0-9_-]{1,64}$`)
+
+// NamespaceConfig 命名空间配置，未设置的项沿用全局配置
+type NamespaceConfig struct {
+	Name       string          `json:"name"`
+	DefaultTTL int64           `json:"default_ttl"` // 默认存活时间（秒），0表示永不过期
+	Cache      *CacheConfig    `json:"cache,omitempty"`
+	Eviction   *EvictionConfig `json:"eviction,omitempty"`
+}
+
+// ValidNamespaceName 判断命名空间名称是否合法
+func ValidNamespaceName(name string) bool {
+	return namespaceNamePattern.MatchString(name)
+}
+
+// NamespaceDiskPath 返回命名空间的磁盘存储目录
+func NamespaceDiskPath(base *Config, name string) string {
+	if name == DefaultNamespace {
+		return base.Value.DiskPath
+	}
+	return filepath.Join(base.Value.DiskPath, "namespaces", name)
+}
+
+// Apply 基于全局配置生成命名空间生效的配置
+func (n *NamespaceConfig) Apply(base *Config) *Config {
+	cfg := *base
+	if n.Cache != nil {
+		cfg.Cache = *n.Cache
+		if cfg.Cache.MaxBytes <= 0 {
+			cfg.Cache.MaxBytes = base.Cache.MaxBytes
+		}
+	}
+	if n.Eviction != nil {
+		cfg.Eviction = *n.Eviction
+	}
+	cfg.Value.DiskPath = NamespaceDiskPath(base, n.Name)
+	return &cfg
+}
@@ -0,0 +1,12 @@
+package config
+
+// OriginConfig 源站配置，缓存未命中时从源站加载，启用写回时将写入异步同步到源站
+type OriginConfig struct {
+	URL          string            `json:"url"`     // 源站地址，键作为路径的最后一段，非空时启用
+	Timeout      int               `json:"timeout"` // 请求超时（毫秒）
+	TTL          int64             `json:"ttl"`     // 加载的值的存活时间（秒），源站未通过Cache-Control指定时使用，0表示使用命名空间的默认存活时间
+	Headers      map[string]string `json:"headers,omitempty"`
+	WriteBehind  bool              `json:"write_behind"`  // 将Set和MSet的写入异步PUT到源站
+	BatchSize    int               `json:"batch_size"`    // 每轮写回的最大键数
+	PollInterval int               `json:"poll_interval"` // 没有待写回的键时的轮询间隔（毫秒）
+}
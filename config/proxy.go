@@ -0,0 +1,12 @@
+package config
+
+// ProxyConfig 缓存反向代理配置，上游的GET响应按请求保存在命名空间中
+type ProxyConfig struct {
+	Upstream             string `json:"upstream"`               // 上游地址，非空时启用反向代理
+	Addr                 string `json:"addr"`                   // 反向代理的监听地址
+	Namespace            string `json:"namespace"`              // 保存响应的命名空间，不存在时创建
+	Timeout              int    `json:"timeout"`                // 向上游获取可缓存响应的超时（毫秒）
+	DefaultTTL           int64  `json:"default_ttl"`            // 响应未指定新鲜期时的新鲜期（秒），0表示不缓存
+	StaleWhileRevalidate int64  `json:"stale_while_revalidate"` // 响应未指定stale-while-revalidate时过期后仍可返回的时间（秒）
+	MaxBodySize          int64  `json:"max_body_size"`          // 可缓存的最大响应正文（字节），更大的响应直接转发
+}
@@ -0,0 +1,36 @@
+package config
+
+import (
+	"errors"
+)
+
+const (
+	// QuotaActionReject 超出配额时拒绝写入
+	QuotaActionReject = "reject"
+	// QuotaActionEvict 超出配额时接受写入，并在后台淘汰该范围内最早创建的键
+	QuotaActionEvict = "evict"
+)
+
+// QuotaConfig 命名空间或键前缀的配额，限制为0表示不限制
+type QuotaConfig struct {
+	Prefix         string `json:"prefix"` // 为空表示整个命名空间
+	MaxKeys        int64  `json:"max_keys"`
+	MaxInlineBytes int64  `json:"max_inline_bytes"` // 内联存储在RocksDB中的值的总字节数
+	MaxDiskBytes   int64  `json:"max_disk_bytes"`   // 磁盘存储的值的总字节数
+	Action         string `json:"action"`           // reject 或 evict，默认 reject
+}
+
+// Validate 检查配额配置，未设置的动作默认为拒绝写入
+func (q *QuotaConfig) Validate() error {
+	if q.MaxKeys < 0 || q.MaxInlineBytes < 0 || q.MaxDiskBytes < 0 {
+		return errors.New("quota limits cannot be negative")
+	}
+	switch q.Action {
+	case "":
+		q.Action = QuotaActionReject
+	case QuotaActionReject, QuotaActionEvict:
+	default:
+		return errors.New("invalid quota action: " + q.Action)
+	}
+	return nil
+}
@@ -0,0 +1,38 @@
+package config
+
+import "errors"
+
+const (
+	// ShardingModeForward 将不属于本节点的键转发给所属节点
+	ShardingModeForward = "forward"
+	// ShardingModeRedirect 返回重定向错误，由客户端重新发往所属节点
+	ShardingModeRedirect = "redirect"
+)
+
+// ShardingConfig 服务端分片配置，启用后每个节点按一致性哈希只保存属于自己的键
+type ShardingConfig struct {
+	Enabled        bool     `json:"enabled"`
+	Self           string   `json:"self"`            // 本节点在分片表中的gRPC地址，不在分片表中时只做代理
+	Nodes          []string `json:"nodes"`           // 静态分片表，所有节点的gRPC地址
+	MapFile        string   `json:"map_file"`        // 分片表文件，JSON格式{"nodes": [...]}，非空时覆盖nodes
+	ReloadInterval int      `json:"reload_interval"` // 检查分片表文件是否变化的间隔（秒）
+	Mode           string   `json:"mode"`            // forward或redirect
+	VirtualNodes   int      `json:"virtual_nodes"`   // 每个节点在哈希环上的虚拟节点数
+}
+
+// Validate 检查分片配置
+func (c *ShardingConfig) Validate() error {
+	if !c.Enabled {
+		return nil
+	}
+	if c.Self == "" {
+		return errors.New("sharding self cannot be empty")
+	}
+	if c.MapFile == "" && len(c.Nodes) == 0 {
+		return errors.New("sharding requires nodes or map_file")
+	}
+	if c.Mode != ShardingModeForward && c.Mode != ShardingModeRedirect {
+		return errors.New("sharding mode must be forward or redirect")
+	}
+	return nil
+}
@@ -2,6 +2,7 @@ package main
 
 import (
 	"context"
+	"flag"
 	"fmt"
 	"log"
 	"net"
@@ -15,8 +16,14 @@ import (
 	"google.golang.org/grpc"
 
 	"kvcache/api"
+	"kvcache/cdc"
+	"kvcache/cluster"
 	"kvcache/config"
+	"kvcache/origin"
+	"kvcache/proxy"
+	"kvcache/replication"
 	"kvcache/service"
+	"kvcache/sharding"
 	"kvcache/storage"
 )
 
@@ -27,8 +34,65 @@ const (
 )
 
 func main() {
+	// 子命令
+	if len(os.Args) > 1 && os.Args[1] == "migrate" {
+		runMigrate(os.Args[2:])
+		return
+	}
+
+	replicateFrom := flag.String("replicate-from", "", "leader gRPC address, start as a read-only follower")
+	nodeID := flag.String("node-id", "", "raft node id, enables cluster mode")
+	raftAddr := flag.String("raft-addr", "127.0.0.1:7000", "raft address advertised to other nodes")
+	bootstrap := flag.Bool("bootstrap", false, "bootstrap a new raft cluster with this node as the only member")
+	shardMap := flag.String("shard-map", "", "shard map file listing the gRPC addresses of all nodes, enables server-side sharding")
+	shardSelf := flag.String("shard-self", "", "gRPC address of this node in the shard map, defaults to localhost:<grpc port>")
+	originURL := flag.String("origin", "", "origin base URL, keys missing from storage are loaded from <origin>/<key>")
+	writeBehind := flag.Bool("write-behind", false, "asynchronously PUT Set and MSet writes to the origin")
+	proxyUpstream := flag.String("proxy", "", "upstream base URL, enables the caching reverse proxy")
+	proxyAddr := flag.String("proxy-addr", "", "listen address of the caching reverse proxy, defaults to proxy.addr")
+	flag.Parse()
+
 	// 初始化配置
 	cfg := config.DefaultConfig()
+	if *replicateFrom != "" {
+		cfg.Replication.LeaderAddr = *replicateFrom
+	}
+	if *nodeID != "" {
+		cfg.Cluster.Enabled = true
+		cfg.Cluster.NodeID = *nodeID
+		cfg.Cluster.RaftAddr = *raftAddr
+		cfg.Cluster.Bootstrap = *bootstrap
+	}
+	if *shardMap != "" {
+		cfg.Sharding.Enabled = true
+		cfg.Sharding.MapFile = *shardMap
+		cfg.Sharding.Self = *shardSelf
+	}
+	if *originURL != "" {
+		cfg.Origin.URL = *originURL
+		cfg.Origin.WriteBehind = *writeBehind
+	}
+	if *proxyUpstream != "" {
+		cfg.Proxy.Upstream = *proxyUpstream
+	}
+	if *proxyAddr != "" {
+		cfg.Proxy.Addr = *proxyAddr
+	}
+	if cfg.Cluster.Enabled && cfg.Replication.LeaderAddr != "" {
+		log.Fatalf("Cluster mode cannot be combined with -replicate-from")
+	}
+
+	// 从节点首次启动时从主节点的检查点引导数据目录
+	leaderAddr := cfg.Replication.LeaderAddr
+	if leaderAddr != "" {
+		if _, err := os.Stat(cfg.RocksDB.Path); os.IsNotExist(err) {
+			revision, err := replication.Bootstrap(context.Background(), leaderAddr, cfg)
+			if err != nil {
+				log.Fatalf("Failed to bootstrap from leader: %v", err)
+			}
+			log.Printf("Bootstrapped from %s at revision %d", leaderAddr, revision)
+		}
+	}
 
 	// 创建存储实例
 	store, err := storage.NewStorage(cfg)
@@ -37,9 +101,41 @@ func main() {
 	}
 	defer store.Stop()
 
+	// 启动变更数据捕获，先于存储停止
+	cdcManager, err := cdc.NewManager(store, &cfg.CDC)
+	if err != nil {
+		log.Fatalf("Failed to create cdc sinks: %v", err)
+	}
+	cdcManager.Start()
+	defer cdcManager.Stop()
+
 	// 创建业务逻辑服务
 	kvService := service.NewKVService(store, cfg)
 
+	// 从节点持续复制主节点的变更，先于存储停止
+	if leaderAddr != "" {
+		follower, err := replication.NewFollower(store, leaderAddr, kvService.InvalidateChange)
+		if err != nil {
+			log.Fatalf("Failed to create follower: %v", err)
+		}
+		follower.Start()
+		defer follower.Stop()
+		kvService.SetReplicator(follower)
+	}
+
+	// 缓存未命中时从源站加载，启用写回时后台将写入同步到源站，先于存储停止
+	if cfg.Origin.URL != "" {
+		httpOrigin := origin.NewHTTPOrigin(&cfg.Origin)
+		defer httpOrigin.Close()
+		kvService.SetLoader(httpOrigin, cfg.Origin.WriteBehind)
+		if cfg.Origin.WriteBehind {
+			writer := origin.NewWriteBehind(store, httpOrigin, &cfg.Origin)
+			writer.Start()
+			defer writer.Stop()
+		}
+		log.Printf("Origin loader enabled for %s", cfg.Origin.URL)
+	}
+
 	// 设置监控指标处理
 	http.Handle("/metrics", promhttp.Handler())
 
@@ -47,16 +143,95 @@ func main() {
 	grpcPort, httpPort := findAvailablePorts()
 	log.Printf("Selected ports: GRPC=%d, HTTP=%d", grpcPort, httpPort)
 
+	registrars := []registrar{replication.NewLeader(store, cfg)}
+
+	// 集群模式下写操作经Raft日志复制，先于存储停止
+	if cfg.Cluster.Enabled {
+		host, _, err := net.SplitHostPort(cfg.Cluster.RaftAddr)
+		if err != nil {
+			log.Fatalf("Invalid raft address: %v", err)
+		}
+		node, err := cluster.NewNode(cfg, store, kvService, net.JoinHostPort(host, fmt.Sprint(grpcPort)))
+		if err != nil {
+			log.Fatalf("Failed to start raft node: %v", err)
+		}
+		defer node.Shutdown()
+		kvService.SetCluster(node)
+		registrars = append(registrars, node)
+		log.Printf("Raft node %s started on %s", cfg.Cluster.NodeID, cfg.Cluster.RaftAddr)
+	}
+
+	// 服务端分片，不属于本节点的键转发给所属节点
+	var router *sharding.Router
+	if cfg.Sharding.Enabled {
+		if cfg.Sharding.Self == "" {
+			cfg.Sharding.Self = fmt.Sprintf("localhost:%d", grpcPort)
+		}
+		router, err = sharding.NewRouter(&cfg.Sharding)
+		if err != nil {
+			log.Fatalf("Failed to load shard map: %v", err)
+		}
+		router.Start()
+		defer router.Stop()
+		kvService.SetRouter(router)
+
+		// 迁移先于分片路由停止
+		migrator := sharding.NewMigrator(store, router, kvService.InvalidateChange)
+		defer migrator.Stop()
+		kvService.SetMigrator(migrator)
+		registrars = append(registrars, migrator)
+		log.Printf("Sharding enabled as %s", cfg.Sharding.Self)
+	}
+
 	// 启动gRPC服务器
 	grpcAddr := fmt.Sprintf(":%d", grpcPort)
-	grpcServer := startGRPCServer(grpcAddr, kvService)
+	grpcServer := startGRPCServer(grpcAddr, kvService, router, registrars...)
+
+	// 缓存反向代理，上游的响应保存在存储中
+	var cacheProxy *proxy.Proxy
+	var proxyServer *http.Server
+	if cfg.Proxy.Upstream != "" {
+		cacheProxy, err = proxy.NewProxy(kvService, &cfg.Proxy)
+		if err != nil {
+			log.Fatalf("Failed to create proxy: %v", err)
+		}
+		proxyServer = startProxyServer(cfg.Proxy.Addr, cacheProxy)
+	}
 
 	// 启动HTTP服务器
 	httpAddr := fmt.Sprintf(":%d", httpPort)
-	httpServer := startHTTPServer(httpAddr, kvService)
+	httpServer := startHTTPServer(httpAddr, kvService, cacheProxy)
 
 	// 等待中断信号
-	waitForShutdown(grpcServer, httpServer)
+	waitForShutdown(grpcServer, httpServer, proxyServer)
+}
+
+// runMigrate 将数据目录升级到当前格式，-dry-run时只报告需要转换的条目数
+func runMigrate(args []string) {
+	cfg := config.DefaultConfig()
+	fs := flag.NewFlagSet("migrate", flag.ExitOnError)
+	dryRun := fs.Bool("dry-run", false, "report the migrations that would run without writing any data")
+	fs.StringVar(&cfg.RocksDB.Path, "rocksdb-path", cfg.RocksDB.Path, "rocksdb data directory")
+	fs.StringVar(&cfg.Value.DiskPath, "disk-path", cfg.Value.DiskPath, "directory of values stored on disk")
+	fs.Parse(args)
+
+	report, err := storage.Migrate(cfg, *dryRun)
+	if err != nil {
+		log.Fatalf("Failed to migrate: %v", err)
+	}
+
+	if len(report.Steps) == 0 {
+		fmt.Printf("Data format is up to date at version %d\n", report.From)
+		return
+	}
+	if report.DryRun {
+		fmt.Printf("Dry run: would migrate data format from version %d to %d\n", report.From, report.To)
+	} else {
+		fmt.Printf("Migrated data format from version %d to %d\n", report.From, report.To)
+	}
+	for _, step := range report.Steps {
+		fmt.Printf("  %d: %s (%d entries)\n", step.Version, step.Description, step.Entries)
+	}
 }
 
 // findAvailablePorts 查找可用的端口对
@@ -93,16 +268,24 @@ func findAvailablePorts() (int, int) {
 	return 0, 0
 }
 
-// startGRPCServer 启动gRPC服务器
-func startGRPCServer(addr string, service *service.KVService) *grpc.Server {
-	// 创建gRPC服务器
-	server := grpc.NewServer()
+// registrar 需要注册到gRPC服务器的节点间服务
+type registrar interface {
+	Register(srv *grpc.Server)
+}
+
+// startGRPCServer 启动gRPC服务器，router为分片路由，未启用分片时为nil
+func startGRPCServer(addr string, service *service.KVService, router *sharding.Router, registrars ...registrar) *grpc.Server {
+	// 创建gRPC服务器，只信任分片表中的节点转发的请求
+	server := grpc.NewServer(grpc.UnaryInterceptor(sharding.UnaryServerInterceptor(router)))
 
 	// 创建gRPC服务实例
 	grpcService := api.NewGRPCServer(service)
 
 	// 注册服务
 	grpcService.Register(server)
+	for _, r := range registrars {
+		r.Register(server)
+	}
 
 	// 启动服务器
 	lis, err := net.Listen("tcp", addr)
@@ -121,9 +304,12 @@ func startGRPCServer(addr string, service *service.KVService) *grpc.Server {
 }
 
 // startHTTPServer 启动HTTP服务器
-func startHTTPServer(addr string, service *service.KVService) *http.Server {
+func startHTTPServer(addr string, service *service.KVService, cacheProxy *proxy.Proxy) *http.Server {
 	// 创建HTTP服务实例
 	httpService := api.NewHTTPServer(service)
+	if cacheProxy != nil {
+		httpService.SetProxy(cacheProxy)
+	}
 
 	// 创建HTTP服务器
 	server := &http.Server{
@@ -142,8 +328,25 @@ func startHTTPServer(addr string, service *service.KVService) *http.Server {
 	return server
 }
 
-// waitForShutdown 等待中断信号并优雅关闭服务器
-func waitForShutdown(grpcServer *grpc.Server, httpServer *http.Server) {
+// startProxyServer 启动缓存反向代理服务器
+func startProxyServer(addr string, cacheProxy *proxy.Proxy) *http.Server {
+	server := &http.Server{
+		Addr:    addr,
+		Handler: cacheProxy,
+	}
+
+	go func() {
+		log.Printf("Caching proxy started on %s", addr)
+		if err := server.ListenAndServe(); err != nil && err != http.ErrServerClosed {
+			log.Fatalf("Failed to serve proxy: %v", err)
+		}
+	}()
+
+	return server
+}
+
+// waitForShutdown 等待中断信号并优雅关闭服务器，proxyServer为nil表示未启用反向代理
+func waitForShutdown(grpcServer *grpc.Server, httpServer *http.Server, proxyServer *http.Server) {
 	// 创建通道接收中断信号
 	quit := make(chan os.Signal, 1)
 	signal.Notify(quit, syscall.SIGINT, syscall.SIGTERM)
@@ -167,5 +370,14 @@ func waitForShutdown(grpcServer *grpc.Server, httpServer *http.Server) {
 		log.Println("HTTP server stopped")
 	}
 
+	// 关闭缓存反向代理
+	if proxyServer != nil {
+		if err := proxyServer.Shutdown(ctx); err != nil {
+			log.Printf("Proxy server shutdown error: %v", err)
+		} else {
+			log.Println("Proxy server stopped")
+		}
+	}
+
 	log.Println("All servers stopped gracefully")
 }
@@ -0,0 +1,131 @@
+package origin
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
+
+	"kvcache/config"
+)
+
+// HTTPOrigin 通过HTTP访问的源站，GET {url}/{key}读取，PUT {url}/{key}写入
+// 非默认命名空间的键通过namespace查询参数区分
+type HTTPOrigin struct {
+	url     string
+	ttl     time.Duration
+	headers map[string]string
+	client  *http.Client
+}
+
+// NewHTTPOrigin 创建HTTP源站
+func NewHTTPOrigin(cfg *config.OriginConfig) *HTTPOrigin {
+	return &HTTPOrigin{
+		url:     strings.TrimRight(cfg.URL, "/"),
+		ttl:     time.Duration(cfg.TTL) * time.Second,
+		headers: cfg.Headers,
+		client:  &http.Client{Timeout: time.Duration(cfg.Timeout) * time.Millisecond},
+	}
+}
+
+// keyURL 返回键在源站的地址
+func (o *HTTPOrigin) keyURL(namespace, key string) string {
+	u := o.url + "/" + url.PathEscape(key)
+	if namespace != "" && namespace != config.DefaultNamespace {
+		u += "?namespace=" + url.QueryEscape(namespace)
+	}
+	return u
+}
+
+// do 发送请求并读取响应体
+func (o *HTTPOrigin) do(ctx context.Context, method, u string, body []byte) (*http.Response, []byte, error) {
+	var reader io.Reader
+	if body != nil {
+		reader = bytes.NewReader(body)
+	}
+	req, err := http.NewRequestWithContext(ctx, method, u, reader)
+	if err != nil {
+		return nil, nil, err
+	}
+	if body != nil {
+		req.Header.Set("Content-Type", "application/octet-stream")
+	}
+	for key, value := range o.headers {
+		req.Header.Set(key, value)
+	}
+
+	resp, err := o.client.Do(req)
+	if err != nil {
+		return nil, nil, err
+	}
+	defer resp.Body.Close()
+
+	data, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return nil, nil, err
+	}
+	return resp, data, nil
+}
+
+// Load 从源站读取键，404表示源站没有该键
+// 响应的Cache-Control max-age指定存活时间，no-store或max-age=0时只返回不保存
+func (o *HTTPOrigin) Load(ctx context.Context, namespace, key string) ([]byte, time.Duration, bool, error) {
+	u := o.keyURL(namespace, key)
+	resp, data, err := o.do(ctx, http.MethodGet, u, nil)
+	if err != nil {
+		return nil, 0, false, err
+	}
+
+	switch {
+	case resp.StatusCode == http.StatusNotFound:
+		return nil, 0, false, nil
+	case resp.StatusCode < 200 || resp.StatusCode >= 300:
+		return nil, 0, false, fmt.Errorf("origin %s returned status %d", u, resp.StatusCode)
+	}
+	return data, o.cacheTTL(resp.Header.Get("Cache-Control")), true, nil
+}
+
+// cacheTTL 根据Cache-Control计算存活时间，未指定时使用配置的存活时间
+func (o *HTTPOrigin) cacheTTL(cacheControl string) time.Duration {
+	for _, directive := range strings.Split(cacheControl, ",") {
+		directive = strings.TrimSpace(strings.ToLower(directive))
+		if directive == "no-store" {
+			return -1
+		}
+		if value, ok := strings.CutPrefix(directive, "max-age="); ok {
+			seconds, err := strconv.ParseInt(value, 10, 64)
+			if err != nil {
+				continue
+			}
+			if seconds <= 0 {
+				return -1
+			}
+			return time.Duration(seconds) * time.Second
+		}
+	}
+	return o.ttl
+}
+
+// Store 将值写入源站，返回2xx表示写入成功
+func (o *HTTPOrigin) Store(ctx context.Context, namespace, key string, value []byte) error {
+	u := o.keyURL(namespace, key)
+	resp, _, err := o.do(ctx, http.MethodPut, u, value)
+	if err != nil {
+		return err
+	}
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		return fmt.Errorf("origin %s returned status %d", u, resp.StatusCode)
+	}
+	return nil
+}
+
+// Close 释放空闲连接
+func (o *HTTPOrigin) Close() error {
+	o.client.CloseIdleConnections()
+	return nil
+}
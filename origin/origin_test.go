@@ -0,0 +1,191 @@
+package origin
+
+import (
+	"context"
+	"errors"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"kvcache/config"
+	"kvcache/storage"
+)
+
+// originServer 使用内存map的HTTP源站
+type originServer struct {
+	mu     sync.Mutex
+	values map[string]string
+}
+
+func (o *originServer) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	key := strings.TrimPrefix(r.URL.Path, "/")
+	if ns := r.URL.Query().Get("namespace"); ns != "" {
+		key = ns + "/" + key
+	}
+
+	o.mu.Lock()
+	defer o.mu.Unlock()
+	switch r.Method {
+	case http.MethodGet:
+		value, ok := o.values[key]
+		if !ok {
+			http.NotFound(w, r)
+			return
+		}
+		if strings.HasPrefix(key, "volatile") {
+			w.Header().Set("Cache-Control", "no-store")
+		} else if strings.HasPrefix(key, "short") {
+			w.Header().Set("Cache-Control", "public, max-age=30")
+		}
+		io.WriteString(w, value)
+	case http.MethodPut:
+		data, _ := io.ReadAll(r.Body)
+		o.values[key] = string(data)
+	}
+}
+
+// value 返回源站保存的值
+func (o *originServer) value(key string) string {
+	o.mu.Lock()
+	defer o.mu.Unlock()
+	return o.values[key]
+}
+
+// TestHTTPOrigin 测试从HTTP源站加载和写入，以及按Cache-Control计算存活时间
+func TestHTTPOrigin(t *testing.T) {
+	server := &originServer{values: map[string]string{
+		"user:1":     "alice",
+		"short":      "30s",
+		"volatile":   "now",
+		"tenant/key": "namespaced",
+	}}
+	ts := httptest.NewServer(server)
+	defer ts.Close()
+
+	o := NewHTTPOrigin(&config.OriginConfig{URL: ts.URL + "/", Timeout: 1000, TTL: 60})
+	defer o.Close()
+	ctx := context.Background()
+
+	tests := []struct {
+		namespace string
+		key       string
+		value     string
+		ttl       time.Duration
+		found     bool
+	}{
+		{config.DefaultNamespace, "user:1", "alice", time.Minute, true},
+		{config.DefaultNamespace, "short", "30s", 30 * time.Second, true},
+		{config.DefaultNamespace, "volatile", "now", -1, true},
+		{"tenant", "key", "namespaced", time.Minute, true},
+		{config.DefaultNamespace, "missing", "", 0, false},
+	}
+	for _, tt := range tests {
+		value, ttl, found, err := o.Load(ctx, tt.namespace, tt.key)
+		if err != nil {
+			t.Fatalf("Failed to load %s: %v", tt.key, err)
+		}
+		if found != tt.found || string(value) != tt.value || ttl != tt.ttl {
+			t.Errorf("Load %s/%s: expected %q ttl=%v found=%v, got %q ttl=%v found=%v",
+				tt.namespace, tt.key, tt.value, tt.ttl, tt.found, value, ttl, found)
+		}
+	}
+
+	if err := o.Store(ctx, config.DefaultNamespace, "user:2", []byte("bob")); err != nil {
+		t.Fatalf("Failed to store: %v", err)
+	}
+	if server.value("user:2") != "bob" {
+		t.Errorf("Expected bob stored in origin, got %q", server.value("user:2"))
+	}
+
+	// 源站不可用时报告错误
+	ts.Close()
+	if _, _, _, err := o.Load(ctx, config.DefaultNamespace, "user:1"); err == nil {
+		t.Errorf("Expected error when origin is unavailable")
+	}
+}
+
+// failingWriter 前几次写入失败的源站
+type failingWriter struct {
+	mu       sync.Mutex
+	failures int
+	values   map[string]string
+}
+
+func (w *failingWriter) Store(ctx context.Context, namespace, key string, value []byte) error {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	if w.failures > 0 {
+		w.failures--
+		return errors.New("origin unavailable")
+	}
+	w.values[key] = string(value)
+	return nil
+}
+
+func (w *failingWriter) value(key string) string {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	return w.values[key]
+}
+
+// TestWriteBehind 测试写回失败后重试，只写回键的最新值，成功后移除标记
+func TestWriteBehind(t *testing.T) {
+	// 初始化配置
+	cfg := config.DefaultConfig()
+
+	// 删除现有的数据目录，确保测试环境干净
+	os.RemoveAll(cfg.RocksDB.Path)
+	os.RemoveAll(cfg.Value.DiskPath)
+
+	// 创建存储实例
+	store, err := storage.NewStorage(cfg)
+	if err != nil {
+		t.Fatalf("Failed to create storage: %v", err)
+	}
+	defer store.Stop()
+
+	// 1. 标记后写入，同一个键的多次写入只保留最新的值
+	for _, value := range []string{"v1", "v2"} {
+		if err := store.MarkWriteBehind([]byte("user:1")); err != nil {
+			t.Fatalf("Failed to mark key: %v", err)
+		}
+		if err := store.Set([]byte("user:1"), []byte(value)); err != nil {
+			t.Fatalf("Failed to set value: %v", err)
+		}
+	}
+	// 写回前被删除的键只移除标记
+	store.MarkWriteBehind([]byte("deleted"))
+	pending, err := store.PendingWriteBehind(10)
+	if err != nil || len(pending) != 2 {
+		t.Fatalf("Expected 2 pending keys, got %d: %v", len(pending), err)
+	}
+
+	// 2. 前两次写入失败，重试后写回最新的值
+	writer := &failingWriter{failures: 2, values: make(map[string]string)}
+	w := NewWriteBehind(store, writer, &config.OriginConfig{BatchSize: 10, PollInterval: 10})
+	w.Start()
+	defer w.Stop()
+
+	deadline := time.Now().Add(5 * time.Second)
+	for {
+		pending, err := store.PendingWriteBehind(10)
+		if err == nil && len(pending) == 0 {
+			break
+		}
+		if time.Now().After(deadline) {
+			t.Fatalf("Timed out waiting for write-behind, %d keys pending", len(pending))
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+	if writer.value("user:1") != "v2" {
+		t.Errorf("Expected latest value v2 written back, got %q", writer.value("user:1"))
+	}
+	if status := w.Status(); status.Written != 1 || status.LastError == "" {
+		t.Errorf("Expected 1 write after a failure, got %+v", status)
+	}
+}
@@ -0,0 +1,208 @@
+package origin
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+
+	"kvcache/config"
+	"kvcache/storage"
+)
+
+const (
+	// minRetryDelay 写回失败后的初始重试间隔
+	minRetryDelay = 100 * time.Millisecond
+	// maxRetryDelay 写回失败后的最大重试间隔
+	maxRetryDelay = 30 * time.Second
+)
+
+// Writer 接收写回的源站，重试时同一个值可能被写入多次
+type Writer interface {
+	Store(ctx context.Context, namespace, key string, value []byte) error
+}
+
+// Status 写回进度
+type Status struct {
+	Written   int64     `json:"written"`
+	LastError string    `json:"last_error,omitempty"`
+	LastErrAt time.Time `json:"last_error_at"`
+}
+
+// WriteBehind 从存储中的持久化队列读取待写回的键，将键的最新值写入源站，保证至少写回一次
+// 同一个键的多次写入只写回最新的值，写回前键已被删除或过期时跳过
+type WriteBehind struct {
+	store        storage.Storage
+	writer       Writer
+	batchSize    int
+	pollInterval time.Duration
+
+	mu     sync.Mutex
+	status Status
+
+	ctx    context.Context
+	cancel context.CancelFunc
+	done   chan struct{}
+}
+
+// NewWriteBehind 创建写回流程
+func NewWriteBehind(store storage.Storage, writer Writer, cfg *config.OriginConfig) *WriteBehind {
+	batchSize := cfg.BatchSize
+	if batchSize <= 0 {
+		batchSize = 100
+	}
+	pollInterval := time.Duration(cfg.PollInterval) * time.Millisecond
+	if pollInterval <= 0 {
+		pollInterval = time.Second
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	return &WriteBehind{
+		store:        store,
+		writer:       writer,
+		batchSize:    batchSize,
+		pollInterval: pollInterval,
+		ctx:          ctx,
+		cancel:       cancel,
+		done:         make(chan struct{}),
+	}
+}
+
+// Start 在后台开始写回
+func (w *WriteBehind) Start() {
+	go w.run()
+}
+
+// Stop 停止写回，未确认的键在下次启动时重新写回
+func (w *WriteBehind) Stop() {
+	w.cancel()
+	<-w.done
+}
+
+// Status 返回写回进度
+func (w *WriteBehind) Status() Status {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	return w.status
+}
+
+// run 循环读取并写回待写回的键
+func (w *WriteBehind) run() {
+	defer close(w.done)
+
+	delay := minRetryDelay
+	for w.ctx.Err() == nil {
+		entries, err := w.store.PendingWriteBehind(w.batchSize)
+		if err != nil {
+			w.fail(err)
+			w.sleep(w.pollInterval)
+			continue
+		}
+		writeBehindMetrics.pending.Set(float64(len(entries)))
+		if len(entries) == 0 {
+			w.sleep(w.pollInterval)
+			continue
+		}
+
+		// 写回失败时按指数退避重试剩余的键
+		if err := w.flush(entries); err != nil {
+			if w.ctx.Err() != nil {
+				return
+			}
+			w.fail(err)
+			writeBehindMetrics.errors.Inc()
+			w.sleep(delay)
+			delay = min(delay*2, maxRetryDelay)
+			continue
+		}
+		delay = minRetryDelay
+	}
+}
+
+// flush 写回一批键，每个键写回成功后移除其标记
+func (w *WriteBehind) flush(entries []storage.WriteBehindEntry) error {
+	for _, entry := range entries {
+		// 1. 读取键的最新值，命名空间已删除、键已不存在或值已被淘汰时只移除标记
+		view, err := w.store.Namespace(entry.Namespace)
+		if err == nil {
+			value, found, err := view.Get(entry.Key)
+			if errors.Is(err, storage.ErrEvicted) {
+				found, err = false, nil
+			}
+			if err != nil {
+				return err
+			}
+			if found {
+				// 2. 写入源站
+				if err := w.writer.Store(w.ctx, entry.Namespace, string(entry.Key), value); err != nil {
+					return err
+				}
+				w.mu.Lock()
+				w.status.Written++
+				w.mu.Unlock()
+				writeBehindMetrics.written.Inc()
+			}
+		}
+
+		// 3. 写回期间键被再次写入时保留标记，下一轮写回新值
+		if err := w.store.AckWriteBehind(entry); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// fail 记录最近一次错误
+func (w *WriteBehind) fail(err error) {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	w.status.LastError = err.Error()
+	w.status.LastErrAt = time.Now()
+}
+
+// sleep 等待一段时间，停止时立即返回
+func (w *WriteBehind) sleep(d time.Duration) {
+	timer := time.NewTimer(d)
+	defer timer.Stop()
+	select {
+	case <-timer.C:
+	case <-w.ctx.Done():
+	}
+}
+
+// metrics 写回监控指标
+type metrics struct {
+	written prometheus.Counter
+	errors  prometheus.Counter
+	pending prometheus.Gauge
+}
+
+var writeBehindMetrics = newMetrics()
+
+// newMetrics 创建并注册写回监控指标
+func newMetrics() *metrics {
+	m := &metrics{
+		written: prometheus.NewCounter(prometheus.CounterOpts{
+			Namespace: "cachefs",
+			Subsystem: "kv",
+			Name:      "origin_written_total",
+			Help:      "Total number of values written back to the origin",
+		}),
+		errors: prometheus.NewCounter(prometheus.CounterOpts{
+			Namespace: "cachefs",
+			Subsystem: "kv",
+			Name:      "origin_write_errors_total",
+			Help:      "Total number of failed write-behind attempts",
+		}),
+		pending: prometheus.NewGauge(prometheus.GaugeOpts{
+			Namespace: "cachefs",
+			Subsystem: "kv",
+			Name:      "origin_write_pending",
+			Help:      "Keys waiting to be written back to the origin, capped at the batch size",
+		}),
+	}
+	prometheus.MustRegister(m.written, m.errors, m.pending)
+	return m
+}
@@ -20,16 +20,39 @@ import (
 const _ = grpc.SupportPackageIsVersion9
 
 const (
-	KeyValueService_Set_FullMethodName           = "/kv.KeyValueService/Set"
-	KeyValueService_Get_FullMethodName           = "/kv.KeyValueService/Get"
-	KeyValueService_Delete_FullMethodName        = "/kv.KeyValueService/Delete"
-	KeyValueService_ScanKeys_FullMethodName      = "/kv.KeyValueService/ScanKeys"
-	KeyValueService_ScanKeyValues_FullMethodName = "/kv.KeyValueService/ScanKeyValues"
-	KeyValueService_MSet_FullMethodName          = "/kv.KeyValueService/MSet"
-	KeyValueService_MGet_FullMethodName          = "/kv.KeyValueService/MGet"
-	KeyValueService_MDelete_FullMethodName       = "/kv.KeyValueService/MDelete"
-	KeyValueService_GetConfig_FullMethodName     = "/kv.KeyValueService/GetConfig"
-	KeyValueService_UpdateConfig_FullMethodName  = "/kv.KeyValueService/UpdateConfig"
+	KeyValueService_Set_FullMethodName             = "/kv.KeyValueService/Set"
+	KeyValueService_Get_FullMethodName             = "/kv.KeyValueService/Get"
+	KeyValueService_Delete_FullMethodName          = "/kv.KeyValueService/Delete"
+	KeyValueService_ScanKeys_FullMethodName        = "/kv.KeyValueService/ScanKeys"
+	KeyValueService_ScanKeyValues_FullMethodName   = "/kv.KeyValueService/ScanKeyValues"
+	KeyValueService_Append_FullMethodName          = "/kv.KeyValueService/Append"
+	KeyValueService_WriteAt_FullMethodName         = "/kv.KeyValueService/WriteAt"
+	KeyValueService_Rename_FullMethodName          = "/kv.KeyValueService/Rename"
+	KeyValueService_Copy_FullMethodName            = "/kv.KeyValueService/Copy"
+	KeyValueService_GetMeta_FullMethodName         = "/kv.KeyValueService/GetMeta"
+	KeyValueService_Exists_FullMethodName          = "/kv.KeyValueService/Exists"
+	KeyValueService_MExists_FullMethodName         = "/kv.KeyValueService/MExists"
+	KeyValueService_CountPrefix_FullMethodName     = "/kv.KeyValueService/CountPrefix"
+	KeyValueService_SizeOf_FullMethodName          = "/kv.KeyValueService/SizeOf"
+	KeyValueService_MSet_FullMethodName            = "/kv.KeyValueService/MSet"
+	KeyValueService_MGet_FullMethodName            = "/kv.KeyValueService/MGet"
+	KeyValueService_MDelete_FullMethodName         = "/kv.KeyValueService/MDelete"
+	KeyValueService_DeletePrefix_FullMethodName    = "/kv.KeyValueService/DeletePrefix"
+	KeyValueService_DeleteRange_FullMethodName     = "/kv.KeyValueService/DeleteRange"
+	KeyValueService_GetDeleteJob_FullMethodName    = "/kv.KeyValueService/GetDeleteJob"
+	KeyValueService_Watch_FullMethodName           = "/kv.KeyValueService/Watch"
+	KeyValueService_GetConfig_FullMethodName       = "/kv.KeyValueService/GetConfig"
+	KeyValueService_UpdateConfig_FullMethodName    = "/kv.KeyValueService/UpdateConfig"
+	KeyValueService_CreateNamespace_FullMethodName = "/kv.KeyValueService/CreateNamespace"
+	KeyValueService_DropNamespace_FullMethodName   = "/kv.KeyValueService/DropNamespace"
+	KeyValueService_ListNamespaces_FullMethodName  = "/kv.KeyValueService/ListNamespaces"
+	KeyValueService_SetQuota_FullMethodName        = "/kv.KeyValueService/SetQuota"
+	KeyValueService_DeleteQuota_FullMethodName     = "/kv.KeyValueService/DeleteQuota"
+	KeyValueService_ListQuotas_FullMethodName      = "/kv.KeyValueService/ListQuotas"
+	KeyValueService_Promote_FullMethodName         = "/kv.KeyValueService/Promote"
+	KeyValueService_SetVersioned_FullMethodName    = "/kv.KeyValueService/SetVersioned"
+	KeyValueService_DeleteVersioned_FullMethodName = "/kv.KeyValueService/DeleteVersioned"
+	KeyValueService_GetVersioned_FullMethodName    = "/kv.KeyValueService/GetVersioned"
 )
 
 // KeyValueServiceClient is the client API for KeyValueService service.
@@ -44,13 +67,45 @@ type KeyValueServiceClient interface {
 	Delete(ctx context.Context, in *DeleteRequest, opts ...grpc.CallOption) (*DeleteResponse, error)
 	ScanKeys(ctx context.Context, in *ScanRequest, opts ...grpc.CallOption) (*ScanKeysResponse, error)
 	ScanKeyValues(ctx context.Context, in *ScanRequest, opts ...grpc.CallOption) (*ScanKeyValuesResponse, error)
+	// 增量写入
+	Append(ctx context.Context, in *AppendRequest, opts ...grpc.CallOption) (*AppendResponse, error)
+	WriteAt(ctx context.Context, in *WriteAtRequest, opts ...grpc.CallOption) (*WriteAtResponse, error)
+	// 重命名与复制
+	Rename(ctx context.Context, in *RenameRequest, opts ...grpc.CallOption) (*RenameResponse, error)
+	Copy(ctx context.Context, in *CopyRequest, opts ...grpc.CallOption) (*CopyResponse, error)
+	// 元数据查询
+	GetMeta(ctx context.Context, in *GetMetaRequest, opts ...grpc.CallOption) (*GetMetaResponse, error)
+	Exists(ctx context.Context, in *ExistsRequest, opts ...grpc.CallOption) (*ExistsResponse, error)
+	MExists(ctx context.Context, in *MExistsRequest, opts ...grpc.CallOption) (*MExistsResponse, error)
+	CountPrefix(ctx context.Context, in *CountPrefixRequest, opts ...grpc.CallOption) (*CountPrefixResponse, error)
+	SizeOf(ctx context.Context, in *SizeOfRequest, opts ...grpc.CallOption) (*SizeOfResponse, error)
 	// 批量操作
 	MSet(ctx context.Context, in *MSetRequest, opts ...grpc.CallOption) (*MSetResponse, error)
 	MGet(ctx context.Context, in *MGetRequest, opts ...grpc.CallOption) (*MGetResponse, error)
 	MDelete(ctx context.Context, in *MDeleteRequest, opts ...grpc.CallOption) (*MDeleteResponse, error)
+	// 范围删除（管理操作，需要确认）
+	DeletePrefix(ctx context.Context, in *DeletePrefixRequest, opts ...grpc.CallOption) (*DeletePrefixResponse, error)
+	DeleteRange(ctx context.Context, in *DeleteRangeRequest, opts ...grpc.CallOption) (*DeleteRangeResponse, error)
+	GetDeleteJob(ctx context.Context, in *GetDeleteJobRequest, opts ...grpc.CallOption) (*GetDeleteJobResponse, error)
+	// 变更订阅
+	Watch(ctx context.Context, in *WatchRequest, opts ...grpc.CallOption) (grpc.ServerStreamingClient[WatchEvent], error)
 	// 配置操作
 	GetConfig(ctx context.Context, in *GetConfigRequest, opts ...grpc.CallOption) (*GetConfigResponse, error)
 	UpdateConfig(ctx context.Context, in *UpdateConfigRequest, opts ...grpc.CallOption) (*UpdateConfigResponse, error)
+	// 命名空间管理
+	CreateNamespace(ctx context.Context, in *CreateNamespaceRequest, opts ...grpc.CallOption) (*CreateNamespaceResponse, error)
+	DropNamespace(ctx context.Context, in *DropNamespaceRequest, opts ...grpc.CallOption) (*DropNamespaceResponse, error)
+	ListNamespaces(ctx context.Context, in *ListNamespacesRequest, opts ...grpc.CallOption) (*ListNamespacesResponse, error)
+	// 配额管理
+	SetQuota(ctx context.Context, in *SetQuotaRequest, opts ...grpc.CallOption) (*SetQuotaResponse, error)
+	DeleteQuota(ctx context.Context, in *DeleteQuotaRequest, opts ...grpc.CallOption) (*DeleteQuotaResponse, error)
+	ListQuotas(ctx context.Context, in *ListQuotasRequest, opts ...grpc.CallOption) (*ListQuotasResponse, error)
+	// 复制管理
+	Promote(ctx context.Context, in *PromoteRequest, opts ...grpc.CallOption) (*PromoteResponse, error)
+	// 带版本的读写，用于无主的法定数量复制
+	SetVersioned(ctx context.Context, in *SetVersionedRequest, opts ...grpc.CallOption) (*SetVersionedResponse, error)
+	DeleteVersioned(ctx context.Context, in *DeleteVersionedRequest, opts ...grpc.CallOption) (*DeleteVersionedResponse, error)
+	GetVersioned(ctx context.Context, in *GetVersionedRequest, opts ...grpc.CallOption) (*GetVersionedResponse, error)
 }
 
 type keyValueServiceClient struct {
@@ -111,6 +166,96 @@ func (c *keyValueServiceClient) ScanKeyValues(ctx context.Context, in *ScanReque
 	return out, nil
 }
 
+func (c *keyValueServiceClient) Append(ctx context.Context, in *AppendRequest, opts ...grpc.CallOption) (*AppendResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(AppendResponse)
+	err := c.cc.Invoke(ctx, KeyValueService_Append_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *keyValueServiceClient) WriteAt(ctx context.Context, in *WriteAtRequest, opts ...grpc.CallOption) (*WriteAtResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(WriteAtResponse)
+	err := c.cc.Invoke(ctx, KeyValueService_WriteAt_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *keyValueServiceClient) Rename(ctx context.Context, in *RenameRequest, opts ...grpc.CallOption) (*RenameResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(RenameResponse)
+	err := c.cc.Invoke(ctx, KeyValueService_Rename_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *keyValueServiceClient) Copy(ctx context.Context, in *CopyRequest, opts ...grpc.CallOption) (*CopyResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(CopyResponse)
+	err := c.cc.Invoke(ctx, KeyValueService_Copy_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *keyValueServiceClient) GetMeta(ctx context.Context, in *GetMetaRequest, opts ...grpc.CallOption) (*GetMetaResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(GetMetaResponse)
+	err := c.cc.Invoke(ctx, KeyValueService_GetMeta_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *keyValueServiceClient) Exists(ctx context.Context, in *ExistsRequest, opts ...grpc.CallOption) (*ExistsResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(ExistsResponse)
+	err := c.cc.Invoke(ctx, KeyValueService_Exists_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *keyValueServiceClient) MExists(ctx context.Context, in *MExistsRequest, opts ...grpc.CallOption) (*MExistsResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(MExistsResponse)
+	err := c.cc.Invoke(ctx, KeyValueService_MExists_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *keyValueServiceClient) CountPrefix(ctx context.Context, in *CountPrefixRequest, opts ...grpc.CallOption) (*CountPrefixResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(CountPrefixResponse)
+	err := c.cc.Invoke(ctx, KeyValueService_CountPrefix_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *keyValueServiceClient) SizeOf(ctx context.Context, in *SizeOfRequest, opts ...grpc.CallOption) (*SizeOfResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(SizeOfResponse)
+	err := c.cc.Invoke(ctx, KeyValueService_SizeOf_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
 func (c *keyValueServiceClient) MSet(ctx context.Context, in *MSetRequest, opts ...grpc.CallOption) (*MSetResponse, error) {
 	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
 	out := new(MSetResponse)
@@ -141,6 +286,55 @@ func (c *keyValueServiceClient) MDelete(ctx context.Context, in *MDeleteRequest,
 	return out, nil
 }
 
+func (c *keyValueServiceClient) DeletePrefix(ctx context.Context, in *DeletePrefixRequest, opts ...grpc.CallOption) (*DeletePrefixResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(DeletePrefixResponse)
+	err := c.cc.Invoke(ctx, KeyValueService_DeletePrefix_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *keyValueServiceClient) DeleteRange(ctx context.Context, in *DeleteRangeRequest, opts ...grpc.CallOption) (*DeleteRangeResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(DeleteRangeResponse)
+	err := c.cc.Invoke(ctx, KeyValueService_DeleteRange_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *keyValueServiceClient) GetDeleteJob(ctx context.Context, in *GetDeleteJobRequest, opts ...grpc.CallOption) (*GetDeleteJobResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(GetDeleteJobResponse)
+	err := c.cc.Invoke(ctx, KeyValueService_GetDeleteJob_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *keyValueServiceClient) Watch(ctx context.Context, in *WatchRequest, opts ...grpc.CallOption) (grpc.ServerStreamingClient[WatchEvent], error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	stream, err := c.cc.NewStream(ctx, &KeyValueService_ServiceDesc.Streams[0], KeyValueService_Watch_FullMethodName, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	x := &grpc.GenericClientStream[WatchRequest, WatchEvent]{ClientStream: stream}
+	if err := x.ClientStream.SendMsg(in); err != nil {
+		return nil, err
+	}
+	if err := x.ClientStream.CloseSend(); err != nil {
+		return nil, err
+	}
+	return x, nil
+}
+
+// This type alias is provided for backwards compatibility with existing code that references the prior non-generic stream type by name.
+type KeyValueService_WatchClient = grpc.ServerStreamingClient[WatchEvent]
+
 func (c *keyValueServiceClient) GetConfig(ctx context.Context, in *GetConfigRequest, opts ...grpc.CallOption) (*GetConfigResponse, error) {
 	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
 	out := new(GetConfigResponse)
@@ -161,6 +355,106 @@ func (c *keyValueServiceClient) UpdateConfig(ctx context.Context, in *UpdateConf
 	return out, nil
 }
 
+func (c *keyValueServiceClient) CreateNamespace(ctx context.Context, in *CreateNamespaceRequest, opts ...grpc.CallOption) (*CreateNamespaceResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(CreateNamespaceResponse)
+	err := c.cc.Invoke(ctx, KeyValueService_CreateNamespace_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *keyValueServiceClient) DropNamespace(ctx context.Context, in *DropNamespaceRequest, opts ...grpc.CallOption) (*DropNamespaceResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(DropNamespaceResponse)
+	err := c.cc.Invoke(ctx, KeyValueService_DropNamespace_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *keyValueServiceClient) ListNamespaces(ctx context.Context, in *ListNamespacesRequest, opts ...grpc.CallOption) (*ListNamespacesResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(ListNamespacesResponse)
+	err := c.cc.Invoke(ctx, KeyValueService_ListNamespaces_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *keyValueServiceClient) SetQuota(ctx context.Context, in *SetQuotaRequest, opts ...grpc.CallOption) (*SetQuotaResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(SetQuotaResponse)
+	err := c.cc.Invoke(ctx, KeyValueService_SetQuota_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *keyValueServiceClient) DeleteQuota(ctx context.Context, in *DeleteQuotaRequest, opts ...grpc.CallOption) (*DeleteQuotaResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(DeleteQuotaResponse)
+	err := c.cc.Invoke(ctx, KeyValueService_DeleteQuota_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *keyValueServiceClient) ListQuotas(ctx context.Context, in *ListQuotasRequest, opts ...grpc.CallOption) (*ListQuotasResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(ListQuotasResponse)
+	err := c.cc.Invoke(ctx, KeyValueService_ListQuotas_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *keyValueServiceClient) Promote(ctx context.Context, in *PromoteRequest, opts ...grpc.CallOption) (*PromoteResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(PromoteResponse)
+	err := c.cc.Invoke(ctx, KeyValueService_Promote_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *keyValueServiceClient) SetVersioned(ctx context.Context, in *SetVersionedRequest, opts ...grpc.CallOption) (*SetVersionedResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(SetVersionedResponse)
+	err := c.cc.Invoke(ctx, KeyValueService_SetVersioned_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *keyValueServiceClient) DeleteVersioned(ctx context.Context, in *DeleteVersionedRequest, opts ...grpc.CallOption) (*DeleteVersionedResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(DeleteVersionedResponse)
+	err := c.cc.Invoke(ctx, KeyValueService_DeleteVersioned_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *keyValueServiceClient) GetVersioned(ctx context.Context, in *GetVersionedRequest, opts ...grpc.CallOption) (*GetVersionedResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(GetVersionedResponse)
+	err := c.cc.Invoke(ctx, KeyValueService_GetVersioned_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
 // KeyValueServiceServer is the server API for KeyValueService service.
 // All implementations must embed UnimplementedKeyValueServiceServer
 // for forward compatibility.
@@ -173,13 +467,45 @@ type KeyValueServiceServer interface {
 	Delete(context.Context, *DeleteRequest) (*DeleteResponse, error)
 	ScanKeys(context.Context, *ScanRequest) (*ScanKeysResponse, error)
 	ScanKeyValues(context.Context, *ScanRequest) (*ScanKeyValuesResponse, error)
+	// 增量写入
+	Append(context.Context, *AppendRequest) (*AppendResponse, error)
+	WriteAt(context.Context, *WriteAtRequest) (*WriteAtResponse, error)
+	// 重命名与复制
+	Rename(context.Context, *RenameRequest) (*RenameResponse, error)
+	Copy(context.Context, *CopyRequest) (*CopyResponse, error)
+	// 元数据查询
+	GetMeta(context.Context, *GetMetaRequest) (*GetMetaResponse, error)
+	Exists(context.Context, *ExistsRequest) (*ExistsResponse, error)
+	MExists(context.Context, *MExistsRequest) (*MExistsResponse, error)
+	CountPrefix(context.Context, *CountPrefixRequest) (*CountPrefixResponse, error)
+	SizeOf(context.Context, *SizeOfRequest) (*SizeOfResponse, error)
 	// 批量操作
 	MSet(context.Context, *MSetRequest) (*MSetResponse, error)
 	MGet(context.Context, *MGetRequest) (*MGetResponse, error)
 	MDelete(context.Context, *MDeleteRequest) (*MDeleteResponse, error)
+	// 范围删除（管理操作，需要确认）
+	DeletePrefix(context.Context, *DeletePrefixRequest) (*DeletePrefixResponse, error)
+	DeleteRange(context.Context, *DeleteRangeRequest) (*DeleteRangeResponse, error)
+	GetDeleteJob(context.Context, *GetDeleteJobRequest) (*GetDeleteJobResponse, error)
+	// 变更订阅
+	Watch(*WatchRequest, grpc.ServerStreamingServer[WatchEvent]) error
 	// 配置操作
 	GetConfig(context.Context, *GetConfigRequest) (*GetConfigResponse, error)
 	UpdateConfig(context.Context, *UpdateConfigRequest) (*UpdateConfigResponse, error)
+	// 命名空间管理
+	CreateNamespace(context.Context, *CreateNamespaceRequest) (*CreateNamespaceResponse, error)
+	DropNamespace(context.Context, *DropNamespaceRequest) (*DropNamespaceResponse, error)
+	ListNamespaces(context.Context, *ListNamespacesRequest) (*ListNamespacesResponse, error)
+	// 配额管理
+	SetQuota(context.Context, *SetQuotaRequest) (*SetQuotaResponse, error)
+	DeleteQuota(context.Context, *DeleteQuotaRequest) (*DeleteQuotaResponse, error)
+	ListQuotas(context.Context, *ListQuotasRequest) (*ListQuotasResponse, error)
+	// 复制管理
+	Promote(context.Context, *PromoteRequest) (*PromoteResponse, error)
+	// 带版本的读写，用于无主的法定数量复制
+	SetVersioned(context.Context, *SetVersionedRequest) (*SetVersionedResponse, error)
+	DeleteVersioned(context.Context, *DeleteVersionedRequest) (*DeleteVersionedResponse, error)
+	GetVersioned(context.Context, *GetVersionedRequest) (*GetVersionedResponse, error)
 	mustEmbedUnimplementedKeyValueServiceServer()
 }
 
@@ -205,6 +531,33 @@ func (UnimplementedKeyValueServiceServer) ScanKeys(context.Context, *ScanRequest
 func (UnimplementedKeyValueServiceServer) ScanKeyValues(context.Context, *ScanRequest) (*ScanKeyValuesResponse, error) {
 	return nil, status.Error(codes.Unimplemented, "method ScanKeyValues not implemented")
 }
+func (UnimplementedKeyValueServiceServer) Append(context.Context, *AppendRequest) (*AppendResponse, error) {
+	return nil, status.Error(codes.Unimplemented, "method Append not implemented")
+}
+func (UnimplementedKeyValueServiceServer) WriteAt(context.Context, *WriteAtRequest) (*WriteAtResponse, error) {
+	return nil, status.Error(codes.Unimplemented, "method WriteAt not implemented")
+}
+func (UnimplementedKeyValueServiceServer) Rename(context.Context, *RenameRequest) (*RenameResponse, error) {
+	return nil, status.Error(codes.Unimplemented, "method Rename not implemented")
+}
+func (UnimplementedKeyValueServiceServer) Copy(context.Context, *CopyRequest) (*CopyResponse, error) {
+	return nil, status.Error(codes.Unimplemented, "method Copy not implemented")
+}
+func (UnimplementedKeyValueServiceServer) GetMeta(context.Context, *GetMetaRequest) (*GetMetaResponse, error) {
+	return nil, status.Error(codes.Unimplemented, "method GetMeta not implemented")
+}
+func (UnimplementedKeyValueServiceServer) Exists(context.Context, *ExistsRequest) (*ExistsResponse, error) {
+	return nil, status.Error(codes.Unimplemented, "method Exists not implemented")
+}
+func (UnimplementedKeyValueServiceServer) MExists(context.Context, *MExistsRequest) (*MExistsResponse, error) {
+	return nil, status.Error(codes.Unimplemented, "method MExists not implemented")
+}
+func (UnimplementedKeyValueServiceServer) CountPrefix(context.Context, *CountPrefixRequest) (*CountPrefixResponse, error) {
+	return nil, status.Error(codes.Unimplemented, "method CountPrefix not implemented")
+}
+func (UnimplementedKeyValueServiceServer) SizeOf(context.Context, *SizeOfRequest) (*SizeOfResponse, error) {
+	return nil, status.Error(codes.Unimplemented, "method SizeOf not implemented")
+}
 func (UnimplementedKeyValueServiceServer) MSet(context.Context, *MSetRequest) (*MSetResponse, error) {
 	return nil, status.Error(codes.Unimplemented, "method MSet not implemented")
 }
@@ -214,12 +567,54 @@ func (UnimplementedKeyValueServiceServer) MGet(context.Context, *MGetRequest) (*
 func (UnimplementedKeyValueServiceServer) MDelete(context.Context, *MDeleteRequest) (*MDeleteResponse, error) {
 	return nil, status.Error(codes.Unimplemented, "method MDelete not implemented")
 }
+func (UnimplementedKeyValueServiceServer) DeletePrefix(context.Context, *DeletePrefixRequest) (*DeletePrefixResponse, error) {
+	return nil, status.Error(codes.Unimplemented, "method DeletePrefix not implemented")
+}
+func (UnimplementedKeyValueServiceServer) DeleteRange(context.Context, *DeleteRangeRequest) (*DeleteRangeResponse, error) {
+	return nil, status.Error(codes.Unimplemented, "method DeleteRange not implemented")
+}
+func (UnimplementedKeyValueServiceServer) GetDeleteJob(context.Context, *GetDeleteJobRequest) (*GetDeleteJobResponse, error) {
+	return nil, status.Error(codes.Unimplemented, "method GetDeleteJob not implemented")
+}
+func (UnimplementedKeyValueServiceServer) Watch(*WatchRequest, grpc.ServerStreamingServer[WatchEvent]) error {
+	return status.Error(codes.Unimplemented, "method Watch not implemented")
+}
 func (UnimplementedKeyValueServiceServer) GetConfig(context.Context, *GetConfigRequest) (*GetConfigResponse, error) {
 	return nil, status.Error(codes.Unimplemented, "method GetConfig not implemented")
 }
 func (UnimplementedKeyValueServiceServer) UpdateConfig(context.Context, *UpdateConfigRequest) (*UpdateConfigResponse, error) {
 	return nil, status.Error(codes.Unimplemented, "method UpdateConfig not implemented")
 }
+func (UnimplementedKeyValueServiceServer) CreateNamespace(context.Context, *CreateNamespaceRequest) (*CreateNamespaceResponse, error) {
+	return nil, status.Error(codes.Unimplemented, "method CreateNamespace not implemented")
+}
+func (UnimplementedKeyValueServiceServer) DropNamespace(context.Context, *DropNamespaceRequest) (*DropNamespaceResponse, error) {
+	return nil, status.Error(codes.Unimplemented, "method DropNamespace not implemented")
+}
+func (UnimplementedKeyValueServiceServer) ListNamespaces(context.Context, *ListNamespacesRequest) (*ListNamespacesResponse, error) {
+	return nil, status.Error(codes.Unimplemented, "method ListNamespaces not implemented")
+}
+func (UnimplementedKeyValueServiceServer) SetQuota(context.Context, *SetQuotaRequest) (*SetQuotaResponse, error) {
+	return nil, status.Error(codes.Unimplemented, "method SetQuota not implemented")
+}
+func (UnimplementedKeyValueServiceServer) DeleteQuota(context.Context, *DeleteQuotaRequest) (*DeleteQuotaResponse, error) {
+	return nil, status.Error(codes.Unimplemented, "method DeleteQuota not implemented")
+}
+func (UnimplementedKeyValueServiceServer) ListQuotas(context.Context, *ListQuotasRequest) (*ListQuotasResponse, error) {
+	return nil, status.Error(codes.Unimplemented, "method ListQuotas not implemented")
+}
+func (UnimplementedKeyValueServiceServer) Promote(context.Context, *PromoteRequest) (*PromoteResponse, error) {
+	return nil, status.Error(codes.Unimplemented, "method Promote not implemented")
+}
+func (UnimplementedKeyValueServiceServer) SetVersioned(context.Context, *SetVersionedRequest) (*SetVersionedResponse, error) {
+	return nil, status.Error(codes.Unimplemented, "method SetVersioned not implemented")
+}
+func (UnimplementedKeyValueServiceServer) DeleteVersioned(context.Context, *DeleteVersionedRequest) (*DeleteVersionedResponse, error) {
+	return nil, status.Error(codes.Unimplemented, "method DeleteVersioned not implemented")
+}
+func (UnimplementedKeyValueServiceServer) GetVersioned(context.Context, *GetVersionedRequest) (*GetVersionedResponse, error) {
+	return nil, status.Error(codes.Unimplemented, "method GetVersioned not implemented")
+}
 func (UnimplementedKeyValueServiceServer) mustEmbedUnimplementedKeyValueServiceServer() {}
 func (UnimplementedKeyValueServiceServer) testEmbeddedByValue()                         {}
 
@@ -331,148 +726,1236 @@ func _KeyValueService_ScanKeyValues_Handler(srv interface{}, ctx context.Context
 	return interceptor(ctx, in, info, handler)
 }
 
-func _KeyValueService_MSet_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
-	in := new(MSetRequest)
+func _KeyValueService_Append_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(AppendRequest)
 	if err := dec(in); err != nil {
 		return nil, err
 	}
 	if interceptor == nil {
-		return srv.(KeyValueServiceServer).MSet(ctx, in)
+		return srv.(KeyValueServiceServer).Append(ctx, in)
 	}
 	info := &grpc.UnaryServerInfo{
 		Server:     srv,
-		FullMethod: KeyValueService_MSet_FullMethodName,
+		FullMethod: KeyValueService_Append_FullMethodName,
 	}
 	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
-		return srv.(KeyValueServiceServer).MSet(ctx, req.(*MSetRequest))
+		return srv.(KeyValueServiceServer).Append(ctx, req.(*AppendRequest))
 	}
 	return interceptor(ctx, in, info, handler)
 }
 
-func _KeyValueService_MGet_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
-	in := new(MGetRequest)
+func _KeyValueService_WriteAt_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(WriteAtRequest)
 	if err := dec(in); err != nil {
 		return nil, err
 	}
 	if interceptor == nil {
-		return srv.(KeyValueServiceServer).MGet(ctx, in)
+		return srv.(KeyValueServiceServer).WriteAt(ctx, in)
 	}
 	info := &grpc.UnaryServerInfo{
 		Server:     srv,
-		FullMethod: KeyValueService_MGet_FullMethodName,
+		FullMethod: KeyValueService_WriteAt_FullMethodName,
 	}
 	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
-		return srv.(KeyValueServiceServer).MGet(ctx, req.(*MGetRequest))
+		return srv.(KeyValueServiceServer).WriteAt(ctx, req.(*WriteAtRequest))
 	}
 	return interceptor(ctx, in, info, handler)
 }
 
-func _KeyValueService_MDelete_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
-	in := new(MDeleteRequest)
+func _KeyValueService_Rename_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(RenameRequest)
 	if err := dec(in); err != nil {
 		return nil, err
 	}
 	if interceptor == nil {
-		return srv.(KeyValueServiceServer).MDelete(ctx, in)
+		return srv.(KeyValueServiceServer).Rename(ctx, in)
 	}
 	info := &grpc.UnaryServerInfo{
 		Server:     srv,
-		FullMethod: KeyValueService_MDelete_FullMethodName,
+		FullMethod: KeyValueService_Rename_FullMethodName,
 	}
 	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
-		return srv.(KeyValueServiceServer).MDelete(ctx, req.(*MDeleteRequest))
+		return srv.(KeyValueServiceServer).Rename(ctx, req.(*RenameRequest))
 	}
 	return interceptor(ctx, in, info, handler)
 }
 
-func _KeyValueService_GetConfig_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
-	in := new(GetConfigRequest)
+func _KeyValueService_Copy_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(CopyRequest)
 	if err := dec(in); err != nil {
 		return nil, err
 	}
 	if interceptor == nil {
-		return srv.(KeyValueServiceServer).GetConfig(ctx, in)
+		return srv.(KeyValueServiceServer).Copy(ctx, in)
 	}
 	info := &grpc.UnaryServerInfo{
 		Server:     srv,
-		FullMethod: KeyValueService_GetConfig_FullMethodName,
+		FullMethod: KeyValueService_Copy_FullMethodName,
 	}
 	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
-		return srv.(KeyValueServiceServer).GetConfig(ctx, req.(*GetConfigRequest))
+		return srv.(KeyValueServiceServer).Copy(ctx, req.(*CopyRequest))
 	}
 	return interceptor(ctx, in, info, handler)
 }
 
-func _KeyValueService_UpdateConfig_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
-	in := new(UpdateConfigRequest)
+func _KeyValueService_GetMeta_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(GetMetaRequest)
 	if err := dec(in); err != nil {
 		return nil, err
 	}
 	if interceptor == nil {
-		return srv.(KeyValueServiceServer).UpdateConfig(ctx, in)
+		return srv.(KeyValueServiceServer).GetMeta(ctx, in)
 	}
 	info := &grpc.UnaryServerInfo{
 		Server:     srv,
-		FullMethod: KeyValueService_UpdateConfig_FullMethodName,
+		FullMethod: KeyValueService_GetMeta_FullMethodName,
 	}
 	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
-		return srv.(KeyValueServiceServer).UpdateConfig(ctx, req.(*UpdateConfigRequest))
+		return srv.(KeyValueServiceServer).GetMeta(ctx, req.(*GetMetaRequest))
 	}
 	return interceptor(ctx, in, info, handler)
 }
 
-// KeyValueService_ServiceDesc is the grpc.ServiceDesc for KeyValueService service.
-// It's only intended for direct use with grpc.RegisterService,
-// and not to be introspected or modified (even as a copy)
-var KeyValueService_ServiceDesc = grpc.ServiceDesc{
-	ServiceName: "kv.KeyValueService",
-	HandlerType: (*KeyValueServiceServer)(nil),
-	Methods: []grpc.MethodDesc{
-		{
-			MethodName: "Set",
-			Handler:    _KeyValueService_Set_Handler,
-		},
-		{
-			MethodName: "Get",
-			Handler:    _KeyValueService_Get_Handler,
-		},
-		{
-			MethodName: "Delete",
-			Handler:    _KeyValueService_Delete_Handler,
-		},
-		{
-			MethodName: "ScanKeys",
-			Handler:    _KeyValueService_ScanKeys_Handler,
-		},
-		{
-			MethodName: "ScanKeyValues",
-			Handler:    _KeyValueService_ScanKeyValues_Handler,
-		},
-		{
-			MethodName: "MSet",
-			Handler:    _KeyValueService_MSet_Handler,
-		},
-		{
-			MethodName: "MGet",
-			Handler:    _KeyValueService_MGet_Handler,
-		},
-		{
-			MethodName: "MDelete",
-			Handler:    _KeyValueService_MDelete_Handler,
-		},
-		{
-			MethodName: "GetConfig",
-			Handler:    _KeyValueService_GetConfig_Handler,
-		},
+func _KeyValueService_Exists_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(ExistsRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(KeyValueServiceServer).Exists(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: KeyValueService_Exists_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(KeyValueServiceServer).Exists(ctx, req.(*ExistsRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _KeyValueService_MExists_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(MExistsRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(KeyValueServiceServer).MExists(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: KeyValueService_MExists_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(KeyValueServiceServer).MExists(ctx, req.(*MExistsRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _KeyValueService_CountPrefix_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(CountPrefixRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(KeyValueServiceServer).CountPrefix(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: KeyValueService_CountPrefix_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(KeyValueServiceServer).CountPrefix(ctx, req.(*CountPrefixRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _KeyValueService_SizeOf_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(SizeOfRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(KeyValueServiceServer).SizeOf(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: KeyValueService_SizeOf_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(KeyValueServiceServer).SizeOf(ctx, req.(*SizeOfRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _KeyValueService_MSet_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(MSetRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(KeyValueServiceServer).MSet(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: KeyValueService_MSet_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(KeyValueServiceServer).MSet(ctx, req.(*MSetRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _KeyValueService_MGet_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(MGetRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(KeyValueServiceServer).MGet(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: KeyValueService_MGet_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(KeyValueServiceServer).MGet(ctx, req.(*MGetRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _KeyValueService_MDelete_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(MDeleteRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(KeyValueServiceServer).MDelete(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: KeyValueService_MDelete_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(KeyValueServiceServer).MDelete(ctx, req.(*MDeleteRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _KeyValueService_DeletePrefix_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(DeletePrefixRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(KeyValueServiceServer).DeletePrefix(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: KeyValueService_DeletePrefix_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(KeyValueServiceServer).DeletePrefix(ctx, req.(*DeletePrefixRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _KeyValueService_DeleteRange_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(DeleteRangeRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(KeyValueServiceServer).DeleteRange(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: KeyValueService_DeleteRange_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(KeyValueServiceServer).DeleteRange(ctx, req.(*DeleteRangeRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _KeyValueService_GetDeleteJob_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(GetDeleteJobRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(KeyValueServiceServer).GetDeleteJob(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: KeyValueService_GetDeleteJob_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(KeyValueServiceServer).GetDeleteJob(ctx, req.(*GetDeleteJobRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _KeyValueService_Watch_Handler(srv interface{}, stream grpc.ServerStream) error {
+	m := new(WatchRequest)
+	if err := stream.RecvMsg(m); err != nil {
+		return err
+	}
+	return srv.(KeyValueServiceServer).Watch(m, &grpc.GenericServerStream[WatchRequest, WatchEvent]{ServerStream: stream})
+}
+
+// This type alias is provided for backwards compatibility with existing code that references the prior non-generic stream type by name.
+type KeyValueService_WatchServer = grpc.ServerStreamingServer[WatchEvent]
+
+func _KeyValueService_GetConfig_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(GetConfigRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(KeyValueServiceServer).GetConfig(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: KeyValueService_GetConfig_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(KeyValueServiceServer).GetConfig(ctx, req.(*GetConfigRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _KeyValueService_UpdateConfig_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(UpdateConfigRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(KeyValueServiceServer).UpdateConfig(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: KeyValueService_UpdateConfig_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(KeyValueServiceServer).UpdateConfig(ctx, req.(*UpdateConfigRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _KeyValueService_CreateNamespace_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(CreateNamespaceRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(KeyValueServiceServer).CreateNamespace(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: KeyValueService_CreateNamespace_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(KeyValueServiceServer).CreateNamespace(ctx, req.(*CreateNamespaceRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _KeyValueService_DropNamespace_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(DropNamespaceRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(KeyValueServiceServer).DropNamespace(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: KeyValueService_DropNamespace_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(KeyValueServiceServer).DropNamespace(ctx, req.(*DropNamespaceRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _KeyValueService_ListNamespaces_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(ListNamespacesRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(KeyValueServiceServer).ListNamespaces(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: KeyValueService_ListNamespaces_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(KeyValueServiceServer).ListNamespaces(ctx, req.(*ListNamespacesRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _KeyValueService_SetQuota_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(SetQuotaRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(KeyValueServiceServer).SetQuota(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: KeyValueService_SetQuota_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(KeyValueServiceServer).SetQuota(ctx, req.(*SetQuotaRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _KeyValueService_DeleteQuota_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(DeleteQuotaRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(KeyValueServiceServer).DeleteQuota(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: KeyValueService_DeleteQuota_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(KeyValueServiceServer).DeleteQuota(ctx, req.(*DeleteQuotaRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _KeyValueService_ListQuotas_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(ListQuotasRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(KeyValueServiceServer).ListQuotas(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: KeyValueService_ListQuotas_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(KeyValueServiceServer).ListQuotas(ctx, req.(*ListQuotasRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _KeyValueService_Promote_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(PromoteRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(KeyValueServiceServer).Promote(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: KeyValueService_Promote_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(KeyValueServiceServer).Promote(ctx, req.(*PromoteRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _KeyValueService_SetVersioned_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(SetVersionedRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(KeyValueServiceServer).SetVersioned(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: KeyValueService_SetVersioned_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(KeyValueServiceServer).SetVersioned(ctx, req.(*SetVersionedRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _KeyValueService_DeleteVersioned_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(DeleteVersionedRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(KeyValueServiceServer).DeleteVersioned(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: KeyValueService_DeleteVersioned_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(KeyValueServiceServer).DeleteVersioned(ctx, req.(*DeleteVersionedRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _KeyValueService_GetVersioned_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(GetVersionedRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(KeyValueServiceServer).GetVersioned(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: KeyValueService_GetVersioned_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(KeyValueServiceServer).GetVersioned(ctx, req.(*GetVersionedRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+// KeyValueService_ServiceDesc is the grpc.ServiceDesc for KeyValueService service.
+// It's only intended for direct use with grpc.RegisterService,
+// and not to be introspected or modified (even as a copy)
+var KeyValueService_ServiceDesc = grpc.ServiceDesc{
+	ServiceName: "kv.KeyValueService",
+	HandlerType: (*KeyValueServiceServer)(nil),
+	Methods: []grpc.MethodDesc{
+		{
+			MethodName: "Set",
+			Handler:    _KeyValueService_Set_Handler,
+		},
+		{
+			MethodName: "Get",
+			Handler:    _KeyValueService_Get_Handler,
+		},
+		{
+			MethodName: "Delete",
+			Handler:    _KeyValueService_Delete_Handler,
+		},
+		{
+			MethodName: "ScanKeys",
+			Handler:    _KeyValueService_ScanKeys_Handler,
+		},
+		{
+			MethodName: "ScanKeyValues",
+			Handler:    _KeyValueService_ScanKeyValues_Handler,
+		},
+		{
+			MethodName: "Append",
+			Handler:    _KeyValueService_Append_Handler,
+		},
+		{
+			MethodName: "WriteAt",
+			Handler:    _KeyValueService_WriteAt_Handler,
+		},
+		{
+			MethodName: "Rename",
+			Handler:    _KeyValueService_Rename_Handler,
+		},
+		{
+			MethodName: "Copy",
+			Handler:    _KeyValueService_Copy_Handler,
+		},
+		{
+			MethodName: "GetMeta",
+			Handler:    _KeyValueService_GetMeta_Handler,
+		},
+		{
+			MethodName: "Exists",
+			Handler:    _KeyValueService_Exists_Handler,
+		},
+		{
+			MethodName: "MExists",
+			Handler:    _KeyValueService_MExists_Handler,
+		},
+		{
+			MethodName: "CountPrefix",
+			Handler:    _KeyValueService_CountPrefix_Handler,
+		},
+		{
+			MethodName: "SizeOf",
+			Handler:    _KeyValueService_SizeOf_Handler,
+		},
+		{
+			MethodName: "MSet",
+			Handler:    _KeyValueService_MSet_Handler,
+		},
+		{
+			MethodName: "MGet",
+			Handler:    _KeyValueService_MGet_Handler,
+		},
+		{
+			MethodName: "MDelete",
+			Handler:    _KeyValueService_MDelete_Handler,
+		},
+		{
+			MethodName: "DeletePrefix",
+			Handler:    _KeyValueService_DeletePrefix_Handler,
+		},
+		{
+			MethodName: "DeleteRange",
+			Handler:    _KeyValueService_DeleteRange_Handler,
+		},
+		{
+			MethodName: "GetDeleteJob",
+			Handler:    _KeyValueService_GetDeleteJob_Handler,
+		},
+		{
+			MethodName: "GetConfig",
+			Handler:    _KeyValueService_GetConfig_Handler,
+		},
+		{
+			MethodName: "UpdateConfig",
+			Handler:    _KeyValueService_UpdateConfig_Handler,
+		},
+		{
+			MethodName: "CreateNamespace",
+			Handler:    _KeyValueService_CreateNamespace_Handler,
+		},
+		{
+			MethodName: "DropNamespace",
+			Handler:    _KeyValueService_DropNamespace_Handler,
+		},
+		{
+			MethodName: "ListNamespaces",
+			Handler:    _KeyValueService_ListNamespaces_Handler,
+		},
+		{
+			MethodName: "SetQuota",
+			Handler:    _KeyValueService_SetQuota_Handler,
+		},
+		{
+			MethodName: "DeleteQuota",
+			Handler:    _KeyValueService_DeleteQuota_Handler,
+		},
+		{
+			MethodName: "ListQuotas",
+			Handler:    _KeyValueService_ListQuotas_Handler,
+		},
+		{
+			MethodName: "Promote",
+			Handler:    _KeyValueService_Promote_Handler,
+		},
+		{
+			MethodName: "SetVersioned",
+			Handler:    _KeyValueService_SetVersioned_Handler,
+		},
+		{
+			MethodName: "DeleteVersioned",
+			Handler:    _KeyValueService_DeleteVersioned_Handler,
+		},
+		{
+			MethodName: "GetVersioned",
+			Handler:    _KeyValueService_GetVersioned_Handler,
+		},
+	},
+	Streams: []grpc.StreamDesc{
+		{
+			StreamName:    "Watch",
+			Handler:       _KeyValueService_Watch_Handler,
+			ServerStreams: true,
+		},
+	},
+	Metadata: "proto/kv.proto",
+}
+
+const (
+	Replication_Snapshot_FullMethodName  = "/kv.Replication/Snapshot"
+	Replication_Stream_FullMethodName    = "/kv.Replication/Stream"
+	Replication_FetchBlob_FullMethodName = "/kv.Replication/FetchBlob"
+)
+
+// ReplicationClient is the client API for Replication service.
+//
+// For semantics around ctx use and closing/ending streaming RPCs, please refer to https://pkg.go.dev/google.golang.org/grpc/?tab=doc#ClientConn.NewStream.
+//
+// 主从复制服务，由主节点提供给从节点
+type ReplicationClient interface {
+	Snapshot(ctx context.Context, in *SnapshotRequest, opts ...grpc.CallOption) (grpc.ServerStreamingClient[SnapshotChunk], error)
+	Stream(ctx context.Context, in *StreamRequest, opts ...grpc.CallOption) (grpc.ServerStreamingClient[ReplicationEvent], error)
+	FetchBlob(ctx context.Context, in *FetchBlobRequest, opts ...grpc.CallOption) (grpc.ServerStreamingClient[BlobChunk], error)
+}
+
+type replicationClient struct {
+	cc grpc.ClientConnInterface
+}
+
+func NewReplicationClient(cc grpc.ClientConnInterface) ReplicationClient {
+	return &replicationClient{cc}
+}
+
+func (c *replicationClient) Snapshot(ctx context.Context, in *SnapshotRequest, opts ...grpc.CallOption) (grpc.ServerStreamingClient[SnapshotChunk], error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	stream, err := c.cc.NewStream(ctx, &Replication_ServiceDesc.Streams[0], Replication_Snapshot_FullMethodName, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	x := &grpc.GenericClientStream[SnapshotRequest, SnapshotChunk]{ClientStream: stream}
+	if err := x.ClientStream.SendMsg(in); err != nil {
+		return nil, err
+	}
+	if err := x.ClientStream.CloseSend(); err != nil {
+		return nil, err
+	}
+	return x, nil
+}
+
+// This type alias is provided for backwards compatibility with existing code that references the prior non-generic stream type by name.
+type Replication_SnapshotClient = grpc.ServerStreamingClient[SnapshotChunk]
+
+func (c *replicationClient) Stream(ctx context.Context, in *StreamRequest, opts ...grpc.CallOption) (grpc.ServerStreamingClient[ReplicationEvent], error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	stream, err := c.cc.NewStream(ctx, &Replication_ServiceDesc.Streams[1], Replication_Stream_FullMethodName, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	x := &grpc.GenericClientStream[StreamRequest, ReplicationEvent]{ClientStream: stream}
+	if err := x.ClientStream.SendMsg(in); err != nil {
+		return nil, err
+	}
+	if err := x.ClientStream.CloseSend(); err != nil {
+		return nil, err
+	}
+	return x, nil
+}
+
+// This type alias is provided for backwards compatibility with existing code that references the prior non-generic stream type by name.
+type Replication_StreamClient = grpc.ServerStreamingClient[ReplicationEvent]
+
+func (c *replicationClient) FetchBlob(ctx context.Context, in *FetchBlobRequest, opts ...grpc.CallOption) (grpc.ServerStreamingClient[BlobChunk], error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	stream, err := c.cc.NewStream(ctx, &Replication_ServiceDesc.Streams[2], Replication_FetchBlob_FullMethodName, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	x := &grpc.GenericClientStream[FetchBlobRequest, BlobChunk]{ClientStream: stream}
+	if err := x.ClientStream.SendMsg(in); err != nil {
+		return nil, err
+	}
+	if err := x.ClientStream.CloseSend(); err != nil {
+		return nil, err
+	}
+	return x, nil
+}
+
+// This type alias is provided for backwards compatibility with existing code that references the prior non-generic stream type by name.
+type Replication_FetchBlobClient = grpc.ServerStreamingClient[BlobChunk]
+
+// ReplicationServer is the server API for Replication service.
+// All implementations must embed UnimplementedReplicationServer
+// for forward compatibility.
+//
+// 主从复制服务，由主节点提供给从节点
+type ReplicationServer interface {
+	Snapshot(*SnapshotRequest, grpc.ServerStreamingServer[SnapshotChunk]) error
+	Stream(*StreamRequest, grpc.ServerStreamingServer[ReplicationEvent]) error
+	FetchBlob(*FetchBlobRequest, grpc.ServerStreamingServer[BlobChunk]) error
+	mustEmbedUnimplementedReplicationServer()
+}
+
+// UnimplementedReplicationServer must be embedded to have
+// forward compatible implementations.
+//
+// NOTE: this should be embedded by value instead of pointer to avoid a nil
+// pointer dereference when methods are called.
+type UnimplementedReplicationServer struct{}
+
+func (UnimplementedReplicationServer) Snapshot(*SnapshotRequest, grpc.ServerStreamingServer[SnapshotChunk]) error {
+	return status.Error(codes.Unimplemented, "method Snapshot not implemented")
+}
+func (UnimplementedReplicationServer) Stream(*StreamRequest, grpc.ServerStreamingServer[ReplicationEvent]) error {
+	return status.Error(codes.Unimplemented, "method Stream not implemented")
+}
+func (UnimplementedReplicationServer) FetchBlob(*FetchBlobRequest, grpc.ServerStreamingServer[BlobChunk]) error {
+	return status.Error(codes.Unimplemented, "method FetchBlob not implemented")
+}
+func (UnimplementedReplicationServer) mustEmbedUnimplementedReplicationServer() {}
+func (UnimplementedReplicationServer) testEmbeddedByValue()                     {}
+
+// UnsafeReplicationServer may be embedded to opt out of forward compatibility for this service.
+// Use of this interface is not recommended, as added methods to ReplicationServer will
+// result in compilation errors.
+type UnsafeReplicationServer interface {
+	mustEmbedUnimplementedReplicationServer()
+}
+
+func RegisterReplicationServer(s grpc.ServiceRegistrar, srv ReplicationServer) {
+	// If the following call panics, it indicates UnimplementedReplicationServer was
+	// embedded by pointer and is nil.  This will cause panics if an
+	// unimplemented method is ever invoked, so we test this at initialization
+	// time to prevent it from happening at runtime later due to I/O.
+	if t, ok := srv.(interface{ testEmbeddedByValue() }); ok {
+		t.testEmbeddedByValue()
+	}
+	s.RegisterService(&Replication_ServiceDesc, srv)
+}
+
+func _Replication_Snapshot_Handler(srv interface{}, stream grpc.ServerStream) error {
+	m := new(SnapshotRequest)
+	if err := stream.RecvMsg(m); err != nil {
+		return err
+	}
+	return srv.(ReplicationServer).Snapshot(m, &grpc.GenericServerStream[SnapshotRequest, SnapshotChunk]{ServerStream: stream})
+}
+
+// This type alias is provided for backwards compatibility with existing code that references the prior non-generic stream type by name.
+type Replication_SnapshotServer = grpc.ServerStreamingServer[SnapshotChunk]
+
+func _Replication_Stream_Handler(srv interface{}, stream grpc.ServerStream) error {
+	m := new(StreamRequest)
+	if err := stream.RecvMsg(m); err != nil {
+		return err
+	}
+	return srv.(ReplicationServer).Stream(m, &grpc.GenericServerStream[StreamRequest, ReplicationEvent]{ServerStream: stream})
+}
+
+// This type alias is provided for backwards compatibility with existing code that references the prior non-generic stream type by name.
+type Replication_StreamServer = grpc.ServerStreamingServer[ReplicationEvent]
+
+func _Replication_FetchBlob_Handler(srv interface{}, stream grpc.ServerStream) error {
+	m := new(FetchBlobRequest)
+	if err := stream.RecvMsg(m); err != nil {
+		return err
+	}
+	return srv.(ReplicationServer).FetchBlob(m, &grpc.GenericServerStream[FetchBlobRequest, BlobChunk]{ServerStream: stream})
+}
+
+// This type alias is provided for backwards compatibility with existing code that references the prior non-generic stream type by name.
+type Replication_FetchBlobServer = grpc.ServerStreamingServer[BlobChunk]
+
+// Replication_ServiceDesc is the grpc.ServiceDesc for Replication service.
+// It's only intended for direct use with grpc.RegisterService,
+// and not to be introspected or modified (even as a copy)
+var Replication_ServiceDesc = grpc.ServiceDesc{
+	ServiceName: "kv.Replication",
+	HandlerType: (*ReplicationServer)(nil),
+	Methods:     []grpc.MethodDesc{},
+	Streams: []grpc.StreamDesc{
 		{
-			MethodName: "UpdateConfig",
-			Handler:    _KeyValueService_UpdateConfig_Handler,
+			StreamName:    "Snapshot",
+			Handler:       _Replication_Snapshot_Handler,
+			ServerStreams: true,
+		},
+		{
+			StreamName:    "Stream",
+			Handler:       _Replication_Stream_Handler,
+			ServerStreams: true,
+		},
+		{
+			StreamName:    "FetchBlob",
+			Handler:       _Replication_FetchBlob_Handler,
+			ServerStreams: true,
+		},
+	},
+	Metadata: "proto/kv.proto",
+}
+
+const (
+	Cluster_Forward_FullMethodName      = "/kv.Cluster/Forward"
+	Cluster_ReadIndex_FullMethodName    = "/kv.Cluster/ReadIndex"
+	Cluster_AddMember_FullMethodName    = "/kv.Cluster/AddMember"
+	Cluster_RemoveMember_FullMethodName = "/kv.Cluster/RemoveMember"
+)
+
+// ClusterClient is the client API for Cluster service.
+//
+// For semantics around ctx use and closing/ending streaming RPCs, please refer to https://pkg.go.dev/google.golang.org/grpc/?tab=doc#ClientConn.NewStream.
+//
+// Raft集群服务，跟随者通过它向领导者转发写命令、读屏障和成员变更
+type ClusterClient interface {
+	Forward(ctx context.Context, in *ForwardRequest, opts ...grpc.CallOption) (*ForwardResponse, error)
+	ReadIndex(ctx context.Context, in *ReadIndexRequest, opts ...grpc.CallOption) (*ReadIndexResponse, error)
+	AddMember(ctx context.Context, in *AddMemberRequest, opts ...grpc.CallOption) (*AddMemberResponse, error)
+	RemoveMember(ctx context.Context, in *RemoveMemberRequest, opts ...grpc.CallOption) (*RemoveMemberResponse, error)
+}
+
+type clusterClient struct {
+	cc grpc.ClientConnInterface
+}
+
+func NewClusterClient(cc grpc.ClientConnInterface) ClusterClient {
+	return &clusterClient{cc}
+}
+
+func (c *clusterClient) Forward(ctx context.Context, in *ForwardRequest, opts ...grpc.CallOption) (*ForwardResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(ForwardResponse)
+	err := c.cc.Invoke(ctx, Cluster_Forward_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *clusterClient) ReadIndex(ctx context.Context, in *ReadIndexRequest, opts ...grpc.CallOption) (*ReadIndexResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(ReadIndexResponse)
+	err := c.cc.Invoke(ctx, Cluster_ReadIndex_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *clusterClient) AddMember(ctx context.Context, in *AddMemberRequest, opts ...grpc.CallOption) (*AddMemberResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(AddMemberResponse)
+	err := c.cc.Invoke(ctx, Cluster_AddMember_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *clusterClient) RemoveMember(ctx context.Context, in *RemoveMemberRequest, opts ...grpc.CallOption) (*RemoveMemberResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(RemoveMemberResponse)
+	err := c.cc.Invoke(ctx, Cluster_RemoveMember_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+// ClusterServer is the server API for Cluster service.
+// All implementations must embed UnimplementedClusterServer
+// for forward compatibility.
+//
+// Raft集群服务，跟随者通过它向领导者转发写命令、读屏障和成员变更
+type ClusterServer interface {
+	Forward(context.Context, *ForwardRequest) (*ForwardResponse, error)
+	ReadIndex(context.Context, *ReadIndexRequest) (*ReadIndexResponse, error)
+	AddMember(context.Context, *AddMemberRequest) (*AddMemberResponse, error)
+	RemoveMember(context.Context, *RemoveMemberRequest) (*RemoveMemberResponse, error)
+	mustEmbedUnimplementedClusterServer()
+}
+
+// UnimplementedClusterServer must be embedded to have
+// forward compatible implementations.
+//
+// NOTE: this should be embedded by value instead of pointer to avoid a nil
+// pointer dereference when methods are called.
+type UnimplementedClusterServer struct{}
+
+func (UnimplementedClusterServer) Forward(context.Context, *ForwardRequest) (*ForwardResponse, error) {
+	return nil, status.Error(codes.Unimplemented, "method Forward not implemented")
+}
+func (UnimplementedClusterServer) ReadIndex(context.Context, *ReadIndexRequest) (*ReadIndexResponse, error) {
+	return nil, status.Error(codes.Unimplemented, "method ReadIndex not implemented")
+}
+func (UnimplementedClusterServer) AddMember(context.Context, *AddMemberRequest) (*AddMemberResponse, error) {
+	return nil, status.Error(codes.Unimplemented, "method AddMember not implemented")
+}
+func (UnimplementedClusterServer) RemoveMember(context.Context, *RemoveMemberRequest) (*RemoveMemberResponse, error) {
+	return nil, status.Error(codes.Unimplemented, "method RemoveMember not implemented")
+}
+func (UnimplementedClusterServer) mustEmbedUnimplementedClusterServer() {}
+func (UnimplementedClusterServer) testEmbeddedByValue()                 {}
+
+// UnsafeClusterServer may be embedded to opt out of forward compatibility for this service.
+// Use of this interface is not recommended, as added methods to ClusterServer will
+// result in compilation errors.
+type UnsafeClusterServer interface {
+	mustEmbedUnimplementedClusterServer()
+}
+
+func RegisterClusterServer(s grpc.ServiceRegistrar, srv ClusterServer) {
+	// If the following call panics, it indicates UnimplementedClusterServer was
+	// embedded by pointer and is nil.  This will cause panics if an
+	// unimplemented method is ever invoked, so we test this at initialization
+	// time to prevent it from happening at runtime later due to I/O.
+	if t, ok := srv.(interface{ testEmbeddedByValue() }); ok {
+		t.testEmbeddedByValue()
+	}
+	s.RegisterService(&Cluster_ServiceDesc, srv)
+}
+
+func _Cluster_Forward_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(ForwardRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(ClusterServer).Forward(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: Cluster_Forward_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(ClusterServer).Forward(ctx, req.(*ForwardRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _Cluster_ReadIndex_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(ReadIndexRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(ClusterServer).ReadIndex(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: Cluster_ReadIndex_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(ClusterServer).ReadIndex(ctx, req.(*ReadIndexRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _Cluster_AddMember_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(AddMemberRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(ClusterServer).AddMember(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: Cluster_AddMember_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(ClusterServer).AddMember(ctx, req.(*AddMemberRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _Cluster_RemoveMember_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(RemoveMemberRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(ClusterServer).RemoveMember(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: Cluster_RemoveMember_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(ClusterServer).RemoveMember(ctx, req.(*RemoveMemberRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+// Cluster_ServiceDesc is the grpc.ServiceDesc for Cluster service.
+// It's only intended for direct use with grpc.RegisterService,
+// and not to be introspected or modified (even as a copy)
+var Cluster_ServiceDesc = grpc.ServiceDesc{
+	ServiceName: "kv.Cluster",
+	HandlerType: (*ClusterServer)(nil),
+	Methods: []grpc.MethodDesc{
+		{
+			MethodName: "Forward",
+			Handler:    _Cluster_Forward_Handler,
+		},
+		{
+			MethodName: "ReadIndex",
+			Handler:    _Cluster_ReadIndex_Handler,
+		},
+		{
+			MethodName: "AddMember",
+			Handler:    _Cluster_AddMember_Handler,
+		},
+		{
+			MethodName: "RemoveMember",
+			Handler:    _Cluster_RemoveMember_Handler,
 		},
 	},
 	Streams:  []grpc.StreamDesc{},
 	Metadata: "proto/kv.proto",
 }
 
+const (
+	Migration_Import_FullMethodName   = "/kv.Migration/Import"
+	Migration_PushBlob_FullMethodName = "/kv.Migration/PushBlob"
+	Migration_Handoff_FullMethodName  = "/kv.Migration/Handoff"
+)
+
+// MigrationClient is the client API for Migration service.
+//
+// For semantics around ctx use and closing/ending streaming RPCs, please refer to https://pkg.go.dev/google.golang.org/grpc/?tab=doc#ClientConn.NewStream.
+//
+// 分片迁移服务，源节点通过它将键和磁盘文件写入新的所属节点
+type MigrationClient interface {
+	Import(ctx context.Context, in *ImportRequest, opts ...grpc.CallOption) (*ImportResponse, error)
+	PushBlob(ctx context.Context, opts ...grpc.CallOption) (grpc.ClientStreamingClient[BlobUpload, PushBlobResponse], error)
+	Handoff(ctx context.Context, in *HandoffRequest, opts ...grpc.CallOption) (*HandoffResponse, error)
+}
+
+type migrationClient struct {
+	cc grpc.ClientConnInterface
+}
+
+func NewMigrationClient(cc grpc.ClientConnInterface) MigrationClient {
+	return &migrationClient{cc}
+}
+
+func (c *migrationClient) Import(ctx context.Context, in *ImportRequest, opts ...grpc.CallOption) (*ImportResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(ImportResponse)
+	err := c.cc.Invoke(ctx, Migration_Import_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *migrationClient) PushBlob(ctx context.Context, opts ...grpc.CallOption) (grpc.ClientStreamingClient[BlobUpload, PushBlobResponse], error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	stream, err := c.cc.NewStream(ctx, &Migration_ServiceDesc.Streams[0], Migration_PushBlob_FullMethodName, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	x := &grpc.GenericClientStream[BlobUpload, PushBlobResponse]{ClientStream: stream}
+	return x, nil
+}
+
+// This type alias is provided for backwards compatibility with existing code that references the prior non-generic stream type by name.
+type Migration_PushBlobClient = grpc.ClientStreamingClient[BlobUpload, PushBlobResponse]
+
+func (c *migrationClient) Handoff(ctx context.Context, in *HandoffRequest, opts ...grpc.CallOption) (*HandoffResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(HandoffResponse)
+	err := c.cc.Invoke(ctx, Migration_Handoff_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+// MigrationServer is the server API for Migration service.
+// All implementations must embed UnimplementedMigrationServer
+// for forward compatibility.
+//
+// 分片迁移服务，源节点通过它将键和磁盘文件写入新的所属节点
+type MigrationServer interface {
+	Import(context.Context, *ImportRequest) (*ImportResponse, error)
+	PushBlob(grpc.ClientStreamingServer[BlobUpload, PushBlobResponse]) error
+	Handoff(context.Context, *HandoffRequest) (*HandoffResponse, error)
+	mustEmbedUnimplementedMigrationServer()
+}
+
+// UnimplementedMigrationServer must be embedded to have
+// forward compatible implementations.
+//
+// NOTE: this should be embedded by value instead of pointer to avoid a nil
+// pointer dereference when methods are called.
+type UnimplementedMigrationServer struct{}
+
+func (UnimplementedMigrationServer) Import(context.Context, *ImportRequest) (*ImportResponse, error) {
+	return nil, status.Error(codes.Unimplemented, "method Import not implemented")
+}
+func (UnimplementedMigrationServer) PushBlob(grpc.ClientStreamingServer[BlobUpload, PushBlobResponse]) error {
+	return status.Error(codes.Unimplemented, "method PushBlob not implemented")
+}
+func (UnimplementedMigrationServer) Handoff(context.Context, *HandoffRequest) (*HandoffResponse, error) {
+	return nil, status.Error(codes.Unimplemented, "method Handoff not implemented")
+}
+func (UnimplementedMigrationServer) mustEmbedUnimplementedMigrationServer() {}
+func (UnimplementedMigrationServer) testEmbeddedByValue()                   {}
+
+// UnsafeMigrationServer may be embedded to opt out of forward compatibility for this service.
+// Use of this interface is not recommended, as added methods to MigrationServer will
+// result in compilation errors.
+type UnsafeMigrationServer interface {
+	mustEmbedUnimplementedMigrationServer()
+}
+
+func RegisterMigrationServer(s grpc.ServiceRegistrar, srv MigrationServer) {
+	// If the following call panics, it indicates UnimplementedMigrationServer was
+	// embedded by pointer and is nil.  This will cause panics if an
+	// unimplemented method is ever invoked, so we test this at initialization
+	// time to prevent it from happening at runtime later due to I/O.
+	if t, ok := srv.(interface{ testEmbeddedByValue() }); ok {
+		t.testEmbeddedByValue()
+	}
+	s.RegisterService(&Migration_ServiceDesc, srv)
+}
+
+func _Migration_Import_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(ImportRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(MigrationServer).Import(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: Migration_Import_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(MigrationServer).Import(ctx, req.(*ImportRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _Migration_PushBlob_Handler(srv interface{}, stream grpc.ServerStream) error {
+	return srv.(MigrationServer).PushBlob(&grpc.GenericServerStream[BlobUpload, PushBlobResponse]{ServerStream: stream})
+}
+
+// This type alias is provided for backwards compatibility with existing code that references the prior non-generic stream type by name.
+type Migration_PushBlobServer = grpc.ClientStreamingServer[BlobUpload, PushBlobResponse]
+
+func _Migration_Handoff_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(HandoffRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(MigrationServer).Handoff(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: Migration_Handoff_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(MigrationServer).Handoff(ctx, req.(*HandoffRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+// Migration_ServiceDesc is the grpc.ServiceDesc for Migration service.
+// It's only intended for direct use with grpc.RegisterService,
+// and not to be introspected or modified (even as a copy)
+var Migration_ServiceDesc = grpc.ServiceDesc{
+	ServiceName: "kv.Migration",
+	HandlerType: (*MigrationServer)(nil),
+	Methods: []grpc.MethodDesc{
+		{
+			MethodName: "Import",
+			Handler:    _Migration_Import_Handler,
+		},
+		{
+			MethodName: "Handoff",
+			Handler:    _Migration_Handoff_Handler,
+		},
+	},
+	Streams: []grpc.StreamDesc{
+		{
+			StreamName:    "PushBlob",
+			Handler:       _Migration_PushBlob_Handler,
+			ClientStreams: true,
+		},
+	},
+	Metadata: "proto/kv.proto",
+}
+
 const (
 	Health_Check_FullMethodName = "/kv.Health/Check"
 )
@@ -0,0 +1,261 @@
+package proxy
+
+import (
+	"crypto/sha256"
+	"encoding/binary"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/url"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// hopHeaders 逐跳请求头和响应头，不转发也不保存
+var hopHeaders = []string{
+	"Connection",
+	"Keep-Alive",
+	"Proxy-Authenticate",
+	"Proxy-Authorization",
+	"Proxy-Connection",
+	"Te",
+	"Trailer",
+	"Transfer-Encoding",
+	"Upgrade",
+}
+
+// cacheableStatus 默认可缓存的状态码
+var cacheableStatus = map[int]bool{
+	http.StatusOK:                   true,
+	http.StatusNonAuthoritativeInfo: true,
+	http.StatusNoContent:            true,
+	http.StatusMultipleChoices:      true,
+	http.StatusMovedPermanently:     true,
+	http.StatusPermanentRedirect:    true,
+	http.StatusNotFound:             true,
+	http.StatusMethodNotAllowed:     true,
+	http.StatusGone:                 true,
+	http.StatusRequestURITooLong:    true,
+	http.StatusNotImplemented:       true,
+}
+
+// entry 保存的响应，有Variants的记录是索引，只保存Vary请求头名称，各变体保存在variantKey下
+type entry struct {
+	Status     int         `json:"status,omitempty"`
+	Header     http.Header `json:"header,omitempty"`
+	Vary       []string    `json:"vary,omitempty"`     // 规范化的Vary请求头名称
+	Variants   string      `json:"variants,omitempty"` // 索引的生成号，作为变体键的一部分，重新创建索引后旧的变体不再可见
+	Date       time.Time   `json:"date"`               // 响应在上游生成的时间，用于计算Age
+	FreshUntil time.Time   `json:"fresh_until"`
+	StaleUntil time.Time   `json:"stale_until"` // 过期后在此之前返回旧响应并在后台重新验证
+
+	body []byte
+}
+
+// cacheKey 规范化请求地址作为缓存键，查询参数按名称排序
+func cacheKey(u *url.URL) string {
+	key := u.EscapedPath()
+	if key == "" {
+		key = "/"
+	}
+	if query := u.Query().Encode(); query != "" {
+		key += "?" + query
+	}
+	return key
+}
+
+// variantKey 返回请求对应的变体的键，由索引的生成号和Vary请求头的值决定
+func variantKey(key string, index *entry, header http.Header) string {
+	h := sha256.New()
+	for _, name := range index.Vary {
+		h.Write([]byte(name + ":" + strings.Join(header.Values(name), ",") + "\n"))
+	}
+	return key + "#" + index.Variants + "." + hex.EncodeToString(h.Sum(nil)[:16])
+}
+
+// varyNames 解析Vary响应头，返回规范化并排序的请求头名称，Vary为*时返回false
+func varyNames(header http.Header) ([]string, bool) {
+	var names []string
+	for _, value := range header.Values("Vary") {
+		for _, name := range strings.Split(value, ",") {
+			name = strings.TrimSpace(name)
+			if name == "*" {
+				return nil, false
+			}
+			if name != "" {
+				names = append(names, http.CanonicalHeaderKey(name))
+			}
+		}
+	}
+	slices.Sort(names)
+	return slices.Compact(names), true
+}
+
+// cacheControl 解析Cache-Control，指令名称转为小写，没有参数的指令值为空
+func cacheControl(header http.Header) map[string]string {
+	directives := make(map[string]string)
+	for _, value := range header.Values("Cache-Control") {
+		for _, directive := range strings.Split(value, ",") {
+			name, arg, _ := strings.Cut(strings.TrimSpace(directive), "=")
+			if name == "" {
+				continue
+			}
+			directives[strings.ToLower(name)] = strings.Trim(arg, `"`)
+		}
+	}
+	return directives
+}
+
+// seconds 解析以秒为单位的指令参数
+func seconds(directives map[string]string, name string) (time.Duration, bool) {
+	value, ok := directives[name]
+	if !ok {
+		return 0, false
+	}
+	n, err := strconv.ParseInt(value, 10, 64)
+	if err != nil || n < 0 {
+		return 0, false
+	}
+	return time.Duration(n) * time.Second, true
+}
+
+// removeHopHeaders 删除逐跳头，包括Connection中列出的头
+func removeHopHeaders(header http.Header) {
+	for _, value := range header.Values("Connection") {
+		for _, name := range strings.Split(value, ",") {
+			if name = strings.TrimSpace(name); name != "" {
+				header.Del(name)
+			}
+		}
+	}
+	for _, name := range hopHeaders {
+		header.Del(name)
+	}
+}
+
+// newEntry 根据上游响应创建条目，响应不可缓存时返回nil
+// 新鲜期依次取s-maxage、max-age、Expires减Date，都没有时使用defaultTTL，并扣除响应已有的Age
+func newEntry(req *http.Request, resp *http.Response, body []byte, now time.Time, defaultTTL, staleWhileRevalidate time.Duration) *entry {
+	// 1. 检查响应是否允许共享缓存保存
+	if !cacheableStatus[resp.StatusCode] || resp.Header.Get("Set-Cookie") != "" {
+		return nil
+	}
+	cc := cacheControl(resp.Header)
+	for _, directive := range []string{"no-store", "no-cache", "private"} {
+		if _, ok := cc[directive]; ok {
+			return nil
+		}
+	}
+	_, public := cc["public"]
+	sMaxAge, hasSMaxAge := seconds(cc, "s-maxage")
+	if req.Header.Get("Authorization") != "" && !public && !hasSMaxAge {
+		return nil
+	}
+	vary, ok := varyNames(resp.Header)
+	if !ok {
+		return nil
+	}
+
+	// 2. 计算新鲜期
+	date, err := http.ParseTime(resp.Header.Get("Date"))
+	if err != nil || date.After(now) {
+		date = now
+	}
+	var age time.Duration
+	if n, err := strconv.ParseInt(resp.Header.Get("Age"), 10, 64); err == nil && n > 0 {
+		age = time.Duration(n) * time.Second
+	}
+	maxAge, hasMaxAge := seconds(cc, "max-age")
+	expires := resp.Header.Get("Expires")
+	var fresh time.Duration
+	switch {
+	case hasSMaxAge:
+		fresh = sMaxAge
+	case hasMaxAge:
+		fresh = maxAge
+	case expires != "":
+		// 无法解析的Expires表示已过期
+		if t, err := http.ParseTime(expires); err == nil {
+			fresh = t.Sub(date)
+		}
+	case defaultTTL > 0:
+		fresh = defaultTTL
+	default:
+		return nil
+	}
+	fresh -= age + now.Sub(date)
+
+	// 3. must-revalidate和proxy-revalidate不允许返回过期的响应
+	stale := staleWhileRevalidate
+	if swr, ok := seconds(cc, "stale-while-revalidate"); ok {
+		stale = swr
+	}
+	_, mustRevalidate := cc["must-revalidate"]
+	_, proxyRevalidate := cc["proxy-revalidate"]
+	if mustRevalidate || proxyRevalidate {
+		stale = 0
+	}
+	if fresh < 0 {
+		fresh = 0
+	}
+	if fresh+stale <= 0 {
+		return nil
+	}
+
+	header := resp.Header.Clone()
+	removeHopHeaders(header)
+	header.Del("Age")
+	return &entry{
+		Status:     resp.StatusCode,
+		Header:     header,
+		Vary:       vary,
+		Date:       now.Add(-age - now.Sub(date)),
+		FreshUntil: now.Add(fresh),
+		StaleUntil: now.Add(fresh + stale),
+		body:       body,
+	}
+}
+
+// encode 编码为4字节的头部长度、JSON头部和响应正文
+func (e *entry) encode() ([]byte, error) {
+	head, err := json.Marshal(e)
+	if err != nil {
+		return nil, err
+	}
+	data := make([]byte, 4, 4+len(head)+len(e.body))
+	binary.BigEndian.PutUint32(data, uint32(len(head)))
+	data = append(data, head...)
+	return append(data, e.body...), nil
+}
+
+// decodeEntry 解码保存的响应
+func decodeEntry(data []byte) (*entry, error) {
+	if len(data) < 4 {
+		return nil, errors.New("invalid cached response")
+	}
+	n := binary.BigEndian.Uint32(data)
+	if uint64(len(data)-4) < uint64(n) {
+		return nil, errors.New("invalid cached response")
+	}
+	e := &entry{}
+	if err := json.Unmarshal(data[4:4+n], e); err != nil {
+		return nil, err
+	}
+	e.body = data[4+n:]
+	return e, nil
+}
+
+// validators 返回重新验证使用的条件请求头
+func (e *entry) validators() http.Header {
+	header := make(http.Header)
+	if etag := e.Header.Get("ETag"); etag != "" {
+		header.Set("If-None-Match", etag)
+	}
+	if lastModified := e.Header.Get("Last-Modified"); lastModified != "" {
+		header.Set("If-Modified-Since", lastModified)
+	}
+	return header
+}
@@ -0,0 +1,52 @@
+package proxy
+
+import "github.com/prometheus/client_golang/prometheus"
+
+// metrics 缓存反向代理监控指标
+type metrics struct {
+	requests       *prometheus.CounterVec
+	coalesced      prometheus.Counter
+	upstream       prometheus.Counter
+	upstreamErrors prometheus.Counter
+	storeErrors    prometheus.Counter
+}
+
+var proxyMetrics = newMetrics()
+
+// newMetrics 创建并注册缓存反向代理监控指标
+func newMetrics() *metrics {
+	m := &metrics{
+		requests: prometheus.NewCounterVec(prometheus.CounterOpts{
+			Namespace: "cachefs",
+			Subsystem: "kv",
+			Name:      "proxy_requests_total",
+			Help:      "Total number of proxied requests by cache result",
+		}, []string{"result"}),
+		coalesced: prometheus.NewCounter(prometheus.CounterOpts{
+			Namespace: "cachefs",
+			Subsystem: "kv",
+			Name:      "proxy_coalesced_total",
+			Help:      "Total number of cache misses served by an in-flight upstream request for the same key",
+		}),
+		upstream: prometheus.NewCounter(prometheus.CounterOpts{
+			Namespace: "cachefs",
+			Subsystem: "kv",
+			Name:      "proxy_upstream_requests_total",
+			Help:      "Total number of cacheable requests sent to the upstream, including revalidations",
+		}),
+		upstreamErrors: prometheus.NewCounter(prometheus.CounterOpts{
+			Namespace: "cachefs",
+			Subsystem: "kv",
+			Name:      "proxy_upstream_errors_total",
+			Help:      "Total number of failed requests to the upstream",
+		}),
+		storeErrors: prometheus.NewCounter(prometheus.CounterOpts{
+			Namespace: "cachefs",
+			Subsystem: "kv",
+			Name:      "proxy_store_errors_total",
+			Help:      "Total number of responses that could not be stored",
+		}),
+	}
+	prometheus.MustRegister(m.requests, m.coalesced, m.upstream, m.upstreamErrors, m.storeErrors)
+	return m
+}
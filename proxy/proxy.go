@@ -0,0 +1,483 @@
+package proxy
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"net/http"
+	"net/http/httputil"
+	"net/url"
+	"slices"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"kvcache/config"
+	"kvcache/service"
+)
+
+// X-Cache响应头的取值，同时作为请求指标的标签
+const (
+	resultHit         = "HIT"
+	resultStale       = "STALE"
+	resultMiss        = "MISS"
+	resultRevalidated = "REVALIDATED"
+	resultBypass      = "BYPASS"
+	resultError       = "ERROR"
+)
+
+// conditionalHeaders 客户端的条件请求头，获取可缓存响应时不转发
+var conditionalHeaders = []string{
+	"If-Match",
+	"If-None-Match",
+	"If-Modified-Since",
+	"If-Unmodified-Since",
+	"If-Range",
+}
+
+// Proxy 缓存HTTP反向代理，GET请求的响应按规范化的请求地址保存在命名空间中
+// 遵循Cache-Control、Expires和Vary，过期后在stale-while-revalidate期间返回旧响应并在后台重新验证
+// 其他方法的请求直接转发，成功后删除该地址的缓存
+type Proxy struct {
+	service              *service.KVService
+	upstream             *url.URL
+	namespace            string
+	timeout              time.Duration
+	defaultTTL           time.Duration
+	staleWhileRevalidate time.Duration
+	maxBodySize          int64
+	client               *http.Client
+	passthrough          *httputil.ReverseProxy
+
+	mu      sync.Mutex
+	fetches map[string]*fetch // 保存响应的键 -> 进行中的上游请求
+}
+
+// fetch 一次进行中的上游请求，done关闭后结果可用
+type fetch struct {
+	done   chan struct{}
+	header http.Header // 发起请求的请求头，Vary请求头的值不同的请求不共享响应
+	entry  *entry      // 可缓存的响应，获取失败或响应不可缓存时为nil
+}
+
+// response 上游响应，cacheable为false时不保存也不与其他请求共享
+// rest非nil时正文超过大小上限，entry.body只是正文的开头，剩余部分需要继续转发
+type response struct {
+	*entry
+	cacheable   bool
+	revalidated bool
+	rest        io.ReadCloser
+}
+
+// NewProxy 创建缓存反向代理，保存响应的命名空间不存在时创建
+func NewProxy(kv *service.KVService, cfg *config.ProxyConfig) (*Proxy, error) {
+	upstream, err := url.Parse(cfg.Upstream)
+	if err != nil {
+		return nil, err
+	}
+	if upstream.Scheme == "" || upstream.Host == "" {
+		return nil, fmt.Errorf("invalid upstream: %s", cfg.Upstream)
+	}
+
+	// 只限制等待响应头的时间，超过大小上限的正文需要持续转发
+	timeout := time.Duration(cfg.Timeout) * time.Millisecond
+	transport := http.DefaultTransport.(*http.Transport).Clone()
+	transport.ResponseHeaderTimeout = timeout
+	transport.DisableCompression = true
+
+	p := &Proxy{
+		service:              kv,
+		upstream:             upstream,
+		namespace:            cfg.Namespace,
+		timeout:              timeout,
+		defaultTTL:           time.Duration(cfg.DefaultTTL) * time.Second,
+		staleWhileRevalidate: time.Duration(cfg.StaleWhileRevalidate) * time.Second,
+		maxBodySize:          cfg.MaxBodySize,
+		client: &http.Client{
+			Transport: transport,
+			CheckRedirect: func(*http.Request, []*http.Request) error {
+				return http.ErrUseLastResponse
+			},
+		},
+		fetches: make(map[string]*fetch),
+	}
+	p.passthrough = &httputil.ReverseProxy{Rewrite: p.rewrite}
+
+	if err := p.ensureNamespace(); err != nil {
+		return nil, err
+	}
+	return p, nil
+}
+
+// ensureNamespace 创建保存响应的命名空间
+func (p *Proxy) ensureNamespace() error {
+	if p.namespace == "" || p.namespace == config.DefaultNamespace {
+		return nil
+	}
+
+	ctx := context.Background()
+	namespaces, err := p.service.ListNamespaces(ctx)
+	if err != nil {
+		return err
+	}
+	for _, ns := range namespaces {
+		if ns.Name == p.namespace {
+			return nil
+		}
+	}
+	return p.service.CreateNamespace(ctx, &config.NamespaceConfig{Name: p.namespace})
+}
+
+// rewrite 将请求发往上游
+func (p *Proxy) rewrite(pr *httputil.ProxyRequest) {
+	pr.SetURL(p.upstream)
+	pr.SetXForwarded()
+}
+
+// context 返回在保存响应的命名空间中执行的上下文
+func (p *Proxy) context(ctx context.Context) context.Context {
+	return service.WithNamespace(ctx, p.namespace)
+}
+
+// ServeHTTP 处理代理请求
+func (p *Proxy) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	switch r.Method {
+	case http.MethodGet, http.MethodHead:
+		// 范围请求和请求no-store时不使用缓存
+		if _, noStore := cacheControl(r.Header)["no-store"]; !noStore && r.Header.Get("Range") == "" {
+			p.serveCached(w, r)
+			return
+		}
+		proxyMetrics.requests.WithLabelValues(strings.ToLower(resultBypass)).Inc()
+		p.passthrough.ServeHTTP(w, r)
+	case http.MethodOptions, http.MethodTrace:
+		proxyMetrics.requests.WithLabelValues(strings.ToLower(resultBypass)).Inc()
+		p.passthrough.ServeHTTP(w, r)
+	default:
+		// 不安全的方法执行成功后删除该地址的缓存
+		proxyMetrics.requests.WithLabelValues(strings.ToLower(resultBypass)).Inc()
+		rec := &statusRecorder{ResponseWriter: w, status: http.StatusOK}
+		p.passthrough.ServeHTTP(rec, r)
+		if rec.status < http.StatusBadRequest {
+			p.service.Delete(p.context(context.WithoutCancel(r.Context())), cacheKey(r.URL))
+		}
+	}
+}
+
+// serveCached 返回缓存的响应，未命中或需要重新验证时从上游获取并保存
+func (p *Proxy) serveCached(w http.ResponseWriter, r *http.Request) {
+	ctx := p.context(r.Context())
+	key := cacheKey(r.URL)
+	index, cached, storeKey := p.lookup(ctx, key, r.Header)
+	cc := cacheControl(r.Header)
+	_, noCache := cc["no-cache"]
+	noCache = noCache || r.Header.Get("Pragma") == "no-cache"
+
+	// 1. 新鲜的响应直接返回，stale-while-revalidate期间返回旧响应并在后台重新验证
+	now := time.Now()
+	if cached != nil && !noCache {
+		if now.Before(cached.FreshUntil) {
+			p.write(w, r, cached, resultHit, now)
+			return
+		}
+		if now.Before(cached.StaleUntil) {
+			p.write(w, r, cached, resultStale, now)
+			go p.refresh(r.Clone(context.Background()), key, storeKey, index, cached)
+			return
+		}
+	}
+
+	// 2. HEAD请求未命中时直接转发
+	if r.Method == http.MethodHead {
+		proxyMetrics.requests.WithLabelValues(strings.ToLower(resultBypass)).Inc()
+		p.passthrough.ServeHTTP(w, r)
+		return
+	}
+
+	// 3. 从上游获取，已有的响应过期时发送条件请求，同一个键的并发未命中共享一次请求
+	resp, err := p.load(ctx, r, key, storeKey, index, cached)
+	if err != nil {
+		proxyMetrics.requests.WithLabelValues(strings.ToLower(resultError)).Inc()
+		http.Error(w, "upstream error: "+err.Error(), http.StatusBadGateway)
+		return
+	}
+
+	result := resultMiss
+	if resp.revalidated {
+		result = resultRevalidated
+	}
+	p.write(w, r, resp.entry, result, time.Now())
+	if resp.rest != nil {
+		io.Copy(w, resp.rest)
+		resp.rest.Close()
+	}
+}
+
+// lookup 读取请求对应的已保存响应，返回索引（响应没有Vary时为nil）、响应和保存响应的键
+func (p *Proxy) lookup(ctx context.Context, key string, header http.Header) (*entry, *entry, string) {
+	cached := p.get(ctx, key)
+	if cached == nil || cached.Variants == "" {
+		return nil, cached, key
+	}
+
+	storeKey := variantKey(key, cached, header)
+	return cached, p.get(ctx, storeKey), storeKey
+}
+
+// get 读取保存的响应，不存在或读取失败时返回nil
+func (p *Proxy) get(ctx context.Context, key string) *entry {
+	data, err := p.service.Get(ctx, key)
+	if err != nil {
+		return nil
+	}
+	e, err := decodeEntry(data)
+	if err != nil {
+		return nil
+	}
+	return e
+}
+
+// load 从上游获取响应并保存，其他请求正在获取同一个键且Vary请求头的值相同时等待其结果
+func (p *Proxy) load(ctx context.Context, r *http.Request, key, storeKey string, index, stale *entry) (*response, error) {
+	f, leader := p.join(storeKey, r.Header)
+	if !leader {
+		select {
+		case <-f.done:
+		case <-ctx.Done():
+			return nil, ctx.Err()
+		}
+		if f.entry != nil && sameVariant(f.entry.Vary, f.header, r.Header) {
+			proxyMetrics.coalesced.Inc()
+			return &response{entry: f.entry, cacheable: true}, nil
+		}
+		return p.fetch(ctx, r, stale)
+	}
+	defer p.finish(storeKey, f)
+
+	resp, err := p.fetch(ctx, r, stale)
+	if err != nil {
+		return nil, err
+	}
+	if resp.cacheable {
+		f.entry = resp.entry
+		p.store(ctx, key, index, resp.entry, r.Header)
+	}
+	return resp, nil
+}
+
+// refresh 在后台重新验证过期的响应，同一个键同时只有一个请求，响应变为不可缓存时删除旧响应
+func (p *Proxy) refresh(r *http.Request, key, storeKey string, index, stale *entry) {
+	f, leader := p.join(storeKey, r.Header)
+	if !leader {
+		return
+	}
+	defer p.finish(storeKey, f)
+
+	ctx, cancel := context.WithTimeout(p.context(context.Background()), p.timeout)
+	defer cancel()
+
+	resp, err := p.fetch(ctx, r, stale)
+	if err != nil {
+		return
+	}
+	if resp.rest != nil {
+		resp.rest.Close()
+	}
+	if !resp.cacheable {
+		p.service.Delete(ctx, storeKey)
+		return
+	}
+	f.entry = resp.entry
+	p.store(ctx, key, index, resp.entry, r.Header)
+}
+
+// join 注册对键的上游请求，已有进行中的请求时返回该请求和false
+func (p *Proxy) join(storeKey string, header http.Header) (*fetch, bool) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	if f, ok := p.fetches[storeKey]; ok {
+		return f, false
+	}
+	f := &fetch{done: make(chan struct{}), header: header}
+	p.fetches[storeKey] = f
+	return f, true
+}
+
+// finish 发布上游请求的结果
+func (p *Proxy) finish(storeKey string, f *fetch) {
+	p.mu.Lock()
+	delete(p.fetches, storeKey)
+	p.mu.Unlock()
+	close(f.done)
+}
+
+// fetch 向上游发送GET请求，stale非空时带上其验证器，304时沿用旧响应的正文并更新响应头
+func (p *Proxy) fetch(ctx context.Context, r *http.Request, stale *entry) (*response, error) {
+	// 1. 构造上游请求，去掉客户端的条件请求头，避免得到无法保存的304
+	out := r.Clone(ctx)
+	out.Method = http.MethodGet
+	out.Body = nil
+	out.ContentLength = 0
+	out.RequestURI = ""
+	out.Close = false
+	removeHopHeaders(out.Header)
+	for _, name := range conditionalHeaders {
+		out.Header.Del(name)
+	}
+	if stale != nil {
+		for name, values := range stale.validators() {
+			out.Header[name] = values
+		}
+	}
+	pr := &httputil.ProxyRequest{In: r, Out: out}
+	p.rewrite(pr)
+
+	proxyMetrics.upstream.Inc()
+	resp, err := p.client.Do(pr.Out)
+	if err != nil {
+		proxyMetrics.upstreamErrors.Inc()
+		return nil, err
+	}
+	now := time.Now()
+
+	// 2. 未修改时合并响应头，重新计算新鲜期
+	if resp.StatusCode == http.StatusNotModified && stale != nil {
+		resp.Body.Close()
+		header := stale.Header.Clone()
+		resp.Header.Del("Content-Length")
+		removeHopHeaders(resp.Header)
+		for name, values := range resp.Header {
+			header[name] = values
+		}
+		merged := &http.Response{StatusCode: stale.Status, Header: header}
+		if e := newEntry(r, merged, stale.body, now, p.defaultTTL, p.staleWhileRevalidate); e != nil {
+			return &response{entry: e, cacheable: true, revalidated: true}, nil
+		}
+		return &response{entry: &entry{Status: stale.Status, Header: header, body: stale.body}, revalidated: true}, nil
+	}
+
+	// 3. 读取正文，超过大小上限时不保存，剩余部分由调用方转发
+	body, err := io.ReadAll(io.LimitReader(resp.Body, p.maxBodySize+1))
+	if err != nil {
+		resp.Body.Close()
+		proxyMetrics.upstreamErrors.Inc()
+		return nil, err
+	}
+	header := resp.Header.Clone()
+	removeHopHeaders(header)
+	if int64(len(body)) > p.maxBodySize {
+		return &response{entry: &entry{Status: resp.StatusCode, Header: header, body: body}, rest: resp.Body}, nil
+	}
+	resp.Body.Close()
+
+	if e := newEntry(r, resp, body, now, p.defaultTTL, p.staleWhileRevalidate); e != nil {
+		return &response{entry: e, cacheable: true}, nil
+	}
+	return &response{entry: &entry{Status: resp.StatusCode, Header: header, body: body}}, nil
+}
+
+// store 保存响应，有Vary的响应保存在索引的变体键下，Vary请求头名称改变时创建新的索引
+// 本节点不可写时不保存，例如从节点
+func (p *Proxy) store(ctx context.Context, key string, index, e *entry, header http.Header) {
+	ctx = context.WithoutCancel(ctx)
+	storeKey := key
+	if len(e.Vary) > 0 {
+		if index == nil || !slices.Equal(index.Vary, e.Vary) {
+			index = &entry{Vary: e.Vary, Variants: strconv.FormatInt(time.Now().UnixNano(), 36)}
+		}
+		// 索引的存活时间不短于任何变体
+		if index.StaleUntil.Before(e.StaleUntil) {
+			index.StaleUntil = e.StaleUntil
+			if err := p.put(ctx, key, index); err != nil {
+				return
+			}
+		}
+		storeKey = variantKey(key, index, header)
+	}
+	p.put(ctx, storeKey, e)
+}
+
+// put 按可以返回的时间设置存活时间写入响应
+func (p *Proxy) put(ctx context.Context, key string, e *entry) error {
+	ttl := time.Until(e.StaleUntil)
+	if ttl <= 0 {
+		return nil
+	}
+	data, err := e.encode()
+	if err != nil {
+		return err
+	}
+	if err := p.service.Set(ctx, key, data, ttl); err != nil {
+		proxyMetrics.storeErrors.Inc()
+		return err
+	}
+	return nil
+}
+
+// write 返回响应，保存的响应附加Age，X-Cache给出缓存结果
+func (p *Proxy) write(w http.ResponseWriter, r *http.Request, e *entry, result string, now time.Time) {
+	proxyMetrics.requests.WithLabelValues(strings.ToLower(result)).Inc()
+
+	header := w.Header()
+	for name, values := range e.Header {
+		header[name] = values
+	}
+	if !e.Date.IsZero() {
+		header.Set("Age", strconv.FormatInt(int64(max(now.Sub(e.Date), 0)/time.Second), 10))
+	}
+	header.Set("X-Cache", result)
+	w.WriteHeader(e.Status)
+	if r.Method != http.MethodHead {
+		w.Write(e.body)
+	}
+}
+
+// Purge 删除请求地址对应的缓存响应，返回规范化的键
+// 有Vary的响应删除索引后其变体不再可见，存活时间到期后删除
+func (p *Proxy) Purge(ctx context.Context, rawURL string) (string, error) {
+	u, err := url.Parse(rawURL)
+	if err != nil {
+		return "", fmt.Errorf("invalid url: %w", err)
+	}
+	key := cacheKey(u)
+	return key, p.service.Delete(p.context(ctx), key)
+}
+
+// PurgePrefix 在后台删除键以prefix开头的缓存响应，prefix为转义后的路径，返回清理任务ID
+func (p *Proxy) PurgePrefix(ctx context.Context, prefix string) (string, error) {
+	return p.service.DeletePrefix(p.context(ctx), prefix)
+}
+
+// Namespace 返回保存响应的命名空间
+func (p *Proxy) Namespace() string {
+	return p.namespace
+}
+
+// sameVariant 两个请求的Vary请求头的值相同时返回true
+func sameVariant(vary []string, a, b http.Header) bool {
+	for _, name := range vary {
+		if !slices.Equal(a.Values(name), b.Values(name)) {
+			return false
+		}
+	}
+	return true
+}
+
+// statusRecorder 记录转发响应的状态码
+type statusRecorder struct {
+	http.ResponseWriter
+	status int
+}
+
+// WriteHeader 记录状态码
+func (r *statusRecorder) WriteHeader(status int) {
+	r.status = status
+	r.ResponseWriter.WriteHeader(status)
+}
+
+// Unwrap 返回原始的ResponseWriter，用于Flush
+func (r *statusRecorder) Unwrap() http.ResponseWriter {
+	return r.ResponseWriter
+}
@@ -0,0 +1,239 @@
+package proxy
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"os"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"kvcache/config"
+	"kvcache/service"
+	"kvcache/storage"
+)
+
+// TestCacheKey 测试请求地址的规范化
+func TestCacheKey(t *testing.T) {
+	tests := []struct {
+		url string
+		key string
+	}{
+		{"http://example.com", "/"},
+		{"/users/1", "/users/1"},
+		{"/users?b=2&a=1", "/users?a=1&b=2"},
+		{"/a%2Fb?q=x+y", "/a%2Fb?q=x+y"},
+	}
+	for _, tt := range tests {
+		u, err := url.Parse(tt.url)
+		if err != nil {
+			t.Fatalf("Failed to parse %s: %v", tt.url, err)
+		}
+		if key := cacheKey(u); key != tt.key {
+			t.Errorf("cacheKey(%s): expected %s, got %s", tt.url, tt.key, key)
+		}
+	}
+}
+
+// TestNewEntry 测试按Cache-Control、Expires和Age计算新鲜期，以及不可缓存的响应
+func TestNewEntry(t *testing.T) {
+	now := time.Now().Truncate(time.Second)
+	date := now.UTC().Format(http.TimeFormat)
+	tests := []struct {
+		name   string
+		header http.Header
+		status int
+		auth   bool
+		fresh  time.Duration // 小于0表示不可缓存
+		stale  time.Duration
+	}{
+		{"max-age", http.Header{"Cache-Control": {"max-age=60"}}, 200, false, time.Minute, 0},
+		{"s-maxage", http.Header{"Cache-Control": {"max-age=60, s-maxage=120"}}, 200, false, 2 * time.Minute, 0},
+		{"age", http.Header{"Cache-Control": {"max-age=60"}, "Age": {"20"}}, 200, false, 40 * time.Second, 0},
+		{"expires", http.Header{"Date": {date}, "Expires": {now.Add(30 * time.Second).UTC().Format(http.TimeFormat)}}, 200, false, 30 * time.Second, 0},
+		{"default ttl", http.Header{}, 200, false, 10 * time.Second, 0},
+		{"stale-while-revalidate", http.Header{"Cache-Control": {"max-age=10, stale-while-revalidate=50"}}, 200, false, 10 * time.Second, 50 * time.Second},
+		{"must-revalidate", http.Header{"Cache-Control": {"max-age=10, stale-while-revalidate=50, must-revalidate"}}, 200, false, 10 * time.Second, 0},
+		{"not found", http.Header{"Cache-Control": {"max-age=60"}}, 404, false, time.Minute, 0},
+		{"no-store", http.Header{"Cache-Control": {"no-store"}}, 200, false, -1, 0},
+		{"private", http.Header{"Cache-Control": {"private, max-age=60"}}, 200, false, -1, 0},
+		{"set-cookie", http.Header{"Cache-Control": {"max-age=60"}, "Set-Cookie": {"a=b"}}, 200, false, -1, 0},
+		{"vary star", http.Header{"Cache-Control": {"max-age=60"}, "Vary": {"*"}}, 200, false, -1, 0},
+		{"server error", http.Header{"Cache-Control": {"max-age=60"}}, 500, false, -1, 0},
+		{"authorization", http.Header{"Cache-Control": {"max-age=60"}}, 200, true, -1, 0},
+		{"authorization public", http.Header{"Cache-Control": {"public, max-age=60"}}, 200, true, time.Minute, 0},
+	}
+	for _, tt := range tests {
+		req := httptest.NewRequest(http.MethodGet, "/", nil)
+		if tt.auth {
+			req.Header.Set("Authorization", "Bearer token")
+		}
+		resp := &http.Response{StatusCode: tt.status, Header: tt.header}
+		e := newEntry(req, resp, nil, now, 10*time.Second, 0)
+		if tt.fresh < 0 {
+			if e != nil {
+				t.Errorf("%s: expected response not to be cached", tt.name)
+			}
+			continue
+		}
+		if e == nil {
+			t.Errorf("%s: expected response to be cached", tt.name)
+			continue
+		}
+		if fresh := e.FreshUntil.Sub(now); fresh != tt.fresh {
+			t.Errorf("%s: expected fresh for %v, got %v", tt.name, tt.fresh, fresh)
+		}
+		if stale := e.StaleUntil.Sub(e.FreshUntil); stale != tt.stale {
+			t.Errorf("%s: expected stale for %v, got %v", tt.name, tt.stale, stale)
+		}
+	}
+
+	// 保存后解码得到相同的响应
+	e := newEntry(httptest.NewRequest(http.MethodGet, "/", nil),
+		&http.Response{StatusCode: 200, Header: http.Header{"Cache-Control": {"max-age=60"}, "Vary": {"accept-encoding, Accept"}}},
+		[]byte("body"), now, 0, 0)
+	data, err := e.encode()
+	if err != nil {
+		t.Fatalf("Failed to encode entry: %v", err)
+	}
+	decoded, err := decodeEntry(data)
+	if err != nil {
+		t.Fatalf("Failed to decode entry: %v", err)
+	}
+	if string(decoded.body) != "body" || len(decoded.Vary) != 2 || decoded.Vary[0] != "Accept" || !decoded.FreshUntil.Equal(e.FreshUntil) {
+		t.Errorf("Expected decoded entry to match, got %+v", decoded)
+	}
+}
+
+// TestProxy 测试缓存命中、Vary变体、stale-while-revalidate、不安全方法使缓存失效和清除
+func TestProxy(t *testing.T) {
+	// 初始化配置
+	cfg := config.DefaultConfig()
+
+	// 删除现有的数据目录，确保测试环境干净
+	os.RemoveAll(cfg.RocksDB.Path)
+	os.RemoveAll(cfg.Value.DiskPath)
+
+	// 创建存储实例
+	store, err := storage.NewStorage(cfg)
+	if err != nil {
+		t.Fatalf("Failed to create storage: %v", err)
+	}
+	defer store.Stop()
+
+	// 上游响应带有请求次数，/stale在1秒后过期，/lang按Accept-Language返回不同的变体
+	var requests atomic.Int32
+	upstream := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		n := requests.Add(1)
+		switch r.URL.Path {
+		case "/stale":
+			w.Header().Set("Cache-Control", "max-age=1, stale-while-revalidate=60")
+			w.Header().Set("ETag", `"v1"`)
+			if r.Header.Get("If-None-Match") == `"v1"` {
+				w.WriteHeader(http.StatusNotModified)
+				return
+			}
+		case "/lang":
+			w.Header().Set("Cache-Control", "max-age=60")
+			w.Header().Set("Vary", "Accept-Language")
+			fmt.Fprint(w, r.Header.Get("Accept-Language")+":")
+		case "/private":
+			w.Header().Set("Cache-Control", "private")
+		default:
+			w.Header().Set("Cache-Control", "max-age=60")
+		}
+		fmt.Fprintf(w, "%s %d", r.URL.Path, n)
+	}))
+	defer upstream.Close()
+
+	kv := service.NewKVService(store, cfg)
+	p, err := NewProxy(kv, &config.ProxyConfig{Upstream: upstream.URL, Namespace: "proxy", Timeout: 1000, MaxBodySize: 1 << 20})
+	if err != nil {
+		t.Fatalf("Failed to create proxy: %v", err)
+	}
+	server := httptest.NewServer(p)
+	defer server.Close()
+
+	get := func(path string, header http.Header) (string, string) {
+		req, _ := http.NewRequest(http.MethodGet, server.URL+path, nil)
+		for name, values := range header {
+			req.Header[name] = values
+		}
+		resp, err := http.DefaultClient.Do(req)
+		if err != nil {
+			t.Fatalf("Failed to get %s: %v", path, err)
+		}
+		defer resp.Body.Close()
+		body, _ := io.ReadAll(resp.Body)
+		return string(body), resp.Header.Get("X-Cache")
+	}
+
+	// 1. 第二次请求命中缓存，查询参数的顺序不影响缓存键
+	if body, result := get("/users?a=1&b=2", nil); body != "/users 1" || result != resultMiss {
+		t.Errorf("Expected miss with first response, got %q %s", body, result)
+	}
+	if body, result := get("/users?b=2&a=1", nil); body != "/users 1" || result != resultHit {
+		t.Errorf("Expected hit with cached response, got %q %s", body, result)
+	}
+
+	// 2. 不可缓存的响应每次都请求上游
+	get("/private", nil)
+	if body, result := get("/private", nil); body != "/private 3" || result != resultMiss {
+		t.Errorf("Expected private response not cached, got %q %s", body, result)
+	}
+
+	// 3. Vary请求头的值不同的请求使用不同的变体
+	en := http.Header{"Accept-Language": {"en"}}
+	zh := http.Header{"Accept-Language": {"zh"}}
+	get("/lang", en)
+	get("/lang", zh)
+	if body, result := get("/lang", en); body != "en:/lang 4" || result != resultHit {
+		t.Errorf("Expected en variant, got %q %s", body, result)
+	}
+	if body, result := get("/lang", zh); body != "zh:/lang 5" || result != resultHit {
+		t.Errorf("Expected zh variant, got %q %s", body, result)
+	}
+
+	// 4. 过期后返回旧响应并在后台重新验证，304后响应重新变为新鲜
+	get("/stale", nil)
+	time.Sleep(1100 * time.Millisecond)
+	if body, result := get("/stale", nil); body != "/stale 6" || result != resultStale {
+		t.Errorf("Expected stale response, got %q %s", body, result)
+	}
+	deadline := time.Now().Add(5 * time.Second)
+	for {
+		if body, result := get("/stale", nil); result == resultHit {
+			if body != "/stale 6" {
+				t.Errorf("Expected body kept after 304, got %q", body)
+			}
+			break
+		}
+		if time.Now().After(deadline) {
+			t.Fatalf("Timed out waiting for background revalidation")
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+
+	// 5. 成功的POST使该地址的缓存失效
+	resp, err := http.Post(server.URL+"/users?b=2&a=1", "text/plain", nil)
+	if err != nil {
+		t.Fatalf("Failed to post: %v", err)
+	}
+	resp.Body.Close()
+	if _, result := get("/users?a=1&b=2", nil); result != resultMiss {
+		t.Errorf("Expected miss after POST, got %s", result)
+	}
+
+	// 6. 按地址清除缓存
+	key, err := p.Purge(context.Background(), "/users?b=2&a=1")
+	if err != nil || key != "/users?a=1&b=2" {
+		t.Fatalf("Failed to purge: %s %v", key, err)
+	}
+	if _, result := get("/users?a=1&b=2", nil); result != resultMiss {
+		t.Errorf("Expected miss after purge, got %s", result)
+	}
+}
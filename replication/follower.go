@@ -0,0 +1,476 @@
+package replication
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path"
+	"path/filepath"
+	"sync"
+	"time"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/credentials/insecure"
+	"google.golang.org/grpc/status"
+
+	"kvcache/config"
+	"kvcache/proto"
+	"kvcache/service"
+	"kvcache/storage"
+)
+
+const (
+	// minRetryDelay 连接断开后的初始重连间隔
+	minRetryDelay = 100 * time.Millisecond
+	// maxRetryDelay 连接断开后的最大重连间隔
+	maxRetryDelay = 10 * time.Second
+)
+
+// ErrResyncRequired 主节点已裁剪从节点需要的变更，需清空数据目录后重新引导
+var ErrResyncRequired = errors.New("leader has compacted required changes, resync from a new snapshot")
+
+// Bootstrap 从主节点下载检查点作为本地RocksDB数据目录，返回检查点包含的最新修订号
+// 数据目录必须不存在，下载完成前写入临时目录，失败时不会留下不完整的数据目录
+func Bootstrap(ctx context.Context, leaderAddr string, cfg *config.Config) (uint64, error) {
+	conn, err := grpc.NewClient(leaderAddr, grpc.WithTransportCredentials(insecure.NewCredentials()))
+	if err != nil {
+		return 0, fmt.Errorf("failed to connect to leader: %v", err)
+	}
+	defer conn.Close()
+
+	// 1. 准备临时目录
+	tmp := filepath.Clean(cfg.RocksDB.Path) + ".bootstrap"
+	if err := os.RemoveAll(tmp); err != nil {
+		return 0, err
+	}
+	defer os.RemoveAll(tmp)
+
+	// 2. 按顺序接收检查点文件
+	stream, err := proto.NewReplicationClient(conn).Snapshot(ctx, &proto.SnapshotRequest{})
+	if err != nil {
+		return 0, fmt.Errorf("failed to request snapshot: %v", err)
+	}
+
+	var (
+		revision uint64
+		current  string
+		f        *os.File
+	)
+	closeFile := func() error {
+		if f == nil {
+			return nil
+		}
+		err := f.Close()
+		f = nil
+		return err
+	}
+	defer closeFile()
+
+	for {
+		chunk, err := stream.Recv()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return 0, fmt.Errorf("failed to receive snapshot: %v", err)
+		}
+		revision = chunk.Revision
+
+		if chunk.Path != current {
+			if err := closeFile(); err != nil {
+				return 0, err
+			}
+			// 限制文件在临时目录内
+			name := filepath.Join(tmp, filepath.FromSlash(path.Clean("/"+chunk.Path)))
+			if err := os.MkdirAll(filepath.Dir(name), 0755); err != nil {
+				return 0, err
+			}
+			if f, err = os.OpenFile(name, os.O_CREATE|os.O_EXCL|os.O_WRONLY, 0644); err != nil {
+				return 0, err
+			}
+			current = chunk.Path
+		}
+		if _, err := f.Write(chunk.Data); err != nil {
+			return 0, err
+		}
+	}
+	if err := closeFile(); err != nil {
+		return 0, err
+	}
+
+	// 3. 接收完成后替换为数据目录
+	if err := os.MkdirAll(filepath.Dir(filepath.Clean(cfg.RocksDB.Path)), 0755); err != nil {
+		return 0, err
+	}
+	if err := os.Rename(tmp, cfg.RocksDB.Path); err != nil {
+		return 0, fmt.Errorf("failed to install snapshot: %v", err)
+	}
+	return revision, nil
+}
+
+// Follower 从节点的复制进程，持续应用主节点的变更直到被提升为主节点
+type Follower struct {
+	store   storage.Storage
+	leader  string
+	conn    *grpc.ClientConn
+	repl    proto.ReplicationClient
+	kv      proto.KeyValueServiceClient
+	onApply func(storage.ChangeEvent)
+
+	mu       sync.Mutex
+	status   service.ReplicationStatus
+	promoted bool
+
+	ctx    context.Context
+	cancel context.CancelFunc
+	done   chan struct{}
+}
+
+// NewFollower 创建从节点复制进程，onApply在每个变更应用后调用，用于使缓存失效
+func NewFollower(store storage.Storage, leaderAddr string, onApply func(storage.ChangeEvent)) (*Follower, error) {
+	if !store.IsFollower() {
+		return nil, errors.New("storage is not opened as a follower")
+	}
+
+	conn, err := grpc.NewClient(leaderAddr, grpc.WithTransportCredentials(insecure.NewCredentials()))
+	if err != nil {
+		return nil, fmt.Errorf("failed to connect to leader: %v", err)
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	return &Follower{
+		store:   store,
+		leader:  leaderAddr,
+		conn:    conn,
+		repl:    proto.NewReplicationClient(conn),
+		kv:      proto.NewKeyValueServiceClient(conn),
+		onApply: onApply,
+		status:  service.ReplicationStatus{Role: service.RoleFollower, Leader: leaderAddr},
+		ctx:     ctx,
+		cancel:  cancel,
+		done:    make(chan struct{}),
+	}, nil
+}
+
+// Start 在后台开始复制
+func (f *Follower) Start() {
+	go f.run()
+}
+
+// Stop 停止复制并断开与主节点的连接
+func (f *Follower) Stop() error {
+	f.cancel()
+	<-f.done
+	return f.conn.Close()
+}
+
+// Status 返回复制状态
+func (f *Follower) Status() service.ReplicationStatus {
+	f.mu.Lock()
+	st := f.status
+	f.mu.Unlock()
+
+	st.AppliedRevision = f.store.LatestRevision()
+	if st.Role == service.RoleFollower && st.AppliedRevision >= st.LeaderRevision {
+		st.LagSeconds = 0
+	}
+	return st
+}
+
+// Promote 停止复制并将本节点提升为主节点，已收到但未应用的变更会丢失
+func (f *Follower) Promote() error {
+	f.mu.Lock()
+	if f.promoted {
+		f.mu.Unlock()
+		return errors.New("node is already promoted")
+	}
+	f.promoted = true
+	f.mu.Unlock()
+
+	if err := f.Stop(); err != nil {
+		return err
+	}
+	if err := f.store.Promote(); err != nil {
+		return err
+	}
+
+	f.mu.Lock()
+	f.status = service.ReplicationStatus{Role: service.RoleLeader, Connected: true}
+	f.mu.Unlock()
+	replicationMetrics.connected.Set(0)
+	replicationMetrics.lagRevisions.Set(0)
+	replicationMetrics.lagSeconds.Set(0)
+	return nil
+}
+
+// run 补齐磁盘文件后循环接收并应用变更，连接断开时按指数退避重连
+func (f *Follower) run() {
+	defer close(f.done)
+
+	delay := minRetryDelay
+	for f.ctx.Err() == nil {
+		err := f.syncBlobs()
+		if err == nil {
+			break
+		}
+		f.fail(err)
+		f.sleep(delay)
+		delay = min(delay*2, maxRetryDelay)
+	}
+
+	delay = minRetryDelay
+	for f.ctx.Err() == nil {
+		applied, err := f.stream()
+		if f.ctx.Err() != nil {
+			return
+		}
+		if status.Code(err) == codes.OutOfRange {
+			// 主节点已裁剪需要的变更，重连无法恢复
+			f.fail(ErrResyncRequired)
+			return
+		}
+		f.fail(err)
+
+		if applied {
+			delay = minRetryDelay
+		}
+		f.sleep(delay)
+		delay = min(delay*2, maxRetryDelay)
+	}
+}
+
+// syncBlobs 下载检查点中被引用但本地不存在的磁盘文件
+func (f *Follower) syncBlobs() error {
+	names, err := f.store.ListNamespaces()
+	if err != nil {
+		return err
+	}
+	for _, nsCfg := range names {
+		view, err := f.store.Namespace(nsCfg.Name)
+		if err != nil {
+			return err
+		}
+		missing, err := view.MissingBlobs()
+		if err != nil {
+			return err
+		}
+		for _, fileName := range missing {
+			if err := f.fetchBlob(view, nsCfg.Name, fileName); err != nil && status.Code(err) != codes.NotFound {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+// stream 从本地最新修订号之后接收并应用变更，返回是否应用过变更
+func (f *Follower) stream() (bool, error) {
+	ctx, cancel := context.WithCancel(f.ctx)
+	defer cancel()
+
+	from := f.store.LatestRevision() + 1
+	stream, err := f.repl.Stream(ctx, &proto.StreamRequest{FromRevision: from})
+	if err != nil {
+		return false, err
+	}
+
+	applied := false
+	for {
+		msg, err := stream.Recv()
+		if err != nil {
+			f.setConnected(false)
+			return applied, err
+		}
+		f.setConnected(true)
+
+		if msg.Revision != 0 {
+			if err := f.apply(msg); err != nil {
+				f.setConnected(false)
+				return applied, err
+			}
+			applied = true
+			replicationMetrics.applied.Inc()
+		}
+		f.observe(msg)
+	}
+}
+
+// apply 应用一个变更，必要时先创建命名空间和下载磁盘文件
+func (f *Follower) apply(msg *proto.ReplicationEvent) error {
+	event := storage.ChangeEvent{
+		Revision:  msg.Revision,
+		Type:      msg.Type,
+		Namespace: msg.Namespace,
+		Key:       msg.Key,
+		End:       msg.End,
+		Version:   msg.Version,
+		Size:      msg.Size,
+		Time:      msg.Time,
+	}
+
+	view, err := f.namespace(msg.Namespace)
+	if err != nil {
+		return err
+	}
+	if view == nil {
+		// 命名空间已在主节点删除，跳过其中的变更，但仍记录修订号
+		if err := f.store.SkipChange(&event); err != nil {
+			return fmt.Errorf("failed to skip revision %d: %v", msg.Revision, err)
+		}
+		return nil
+	}
+
+	var entry *storage.ReplicaEntry
+	if msg.HasEntry {
+		meta := &storage.KeyMeta{}
+		if err := json.Unmarshal(msg.Meta, meta); err != nil {
+			return fmt.Errorf("invalid key meta at revision %d: %v", msg.Revision, err)
+		}
+		entry = &storage.ReplicaEntry{Value: msg.Value, Meta: meta}
+
+		if meta.DiskFile != "" && !view.HasBlob(meta.DiskFile) {
+			err := f.fetchBlob(view, msg.Namespace, meta.DiskFile)
+			if status.Code(err) == codes.NotFound {
+				// 文件已被主节点回收，键已被覆盖或删除，由后续变更补齐
+				entry = nil
+			} else if err != nil {
+				return err
+			}
+		}
+	}
+
+	if err := view.ApplyChange(&event, entry); err != nil {
+		return fmt.Errorf("failed to apply revision %d: %v", msg.Revision, err)
+	}
+	if f.onApply != nil {
+		f.onApply(event)
+	}
+	return nil
+}
+
+// namespace 获取变更所属的命名空间，本地不存在时按主节点的配置创建，主节点也不存在时返回nil
+func (f *Follower) namespace(name string) (storage.Storage, error) {
+	if view, err := f.store.Namespace(name); err == nil {
+		return view, nil
+	}
+
+	resp, err := f.kv.ListNamespaces(f.ctx, &proto.ListNamespacesRequest{})
+	if err != nil {
+		return nil, err
+	}
+	if resp.Error != "" {
+		return nil, errors.New(resp.Error)
+	}
+
+	for _, ns := range resp.Namespaces {
+		if ns.Name != name {
+			continue
+		}
+		nsCfg := &config.NamespaceConfig{Name: ns.Name, DefaultTTL: ns.DefaultTtl}
+		if ns.Cache != "" {
+			nsCfg.Cache = &config.CacheConfig{}
+			if err := json.Unmarshal([]byte(ns.Cache), nsCfg.Cache); err != nil {
+				return nil, err
+			}
+		}
+		if ns.Eviction != "" {
+			nsCfg.Eviction = &config.EvictionConfig{}
+			if err := json.Unmarshal([]byte(ns.Eviction), nsCfg.Eviction); err != nil {
+				return nil, err
+			}
+		}
+		if err := f.store.CreateNamespace(nsCfg); err != nil {
+			return nil, err
+		}
+		return f.store.Namespace(name)
+	}
+	return nil, nil
+}
+
+// fetchBlob 从主节点下载磁盘文件，导入时校验内容哈希
+func (f *Follower) fetchBlob(view storage.Storage, namespace, fileName string) error {
+	ctx, cancel := context.WithCancel(f.ctx)
+	defer cancel()
+
+	stream, err := f.repl.FetchBlob(ctx, &proto.FetchBlobRequest{Namespace: namespace, FileName: fileName})
+	if err != nil {
+		return err
+	}
+
+	pr, pw := io.Pipe()
+	go func() {
+		for {
+			chunk, err := stream.Recv()
+			if err == io.EOF {
+				pw.Close()
+				return
+			}
+			if err != nil {
+				pw.CloseWithError(err)
+				return
+			}
+			if _, err := pw.Write(chunk.Data); err != nil {
+				return
+			}
+		}
+	}()
+
+	err = view.ImportBlob(fileName, pr)
+	pr.CloseWithError(err)
+	return err
+}
+
+// observe 根据收到的消息更新复制延迟
+func (f *Follower) observe(msg *proto.ReplicationEvent) {
+	applied := f.store.LatestRevision()
+
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.status.LeaderRevision = max(f.status.LeaderRevision, msg.LeaderRevision)
+	if msg.Revision != 0 {
+		f.status.LagSeconds = max(time.Since(time.UnixMilli(msg.Time)).Seconds(), 0)
+	}
+	if applied >= f.status.LeaderRevision {
+		f.status.LagSeconds = 0
+	}
+
+	replicationMetrics.lagRevisions.Set(float64(f.status.LeaderRevision - min(applied, f.status.LeaderRevision)))
+	replicationMetrics.lagSeconds.Set(f.status.LagSeconds)
+}
+
+// setConnected 更新连接状态
+func (f *Follower) setConnected(connected bool) {
+	f.mu.Lock()
+	f.status.Connected = connected
+	if connected {
+		f.status.LastError = ""
+	}
+	f.mu.Unlock()
+
+	if connected {
+		replicationMetrics.connected.Set(1)
+	} else {
+		replicationMetrics.connected.Set(0)
+	}
+}
+
+// fail 记录最近一次错误
+func (f *Follower) fail(err error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.status.LastError = err.Error()
+}
+
+// sleep 等待一段时间，停止时立即返回
+func (f *Follower) sleep(d time.Duration) {
+	timer := time.NewTimer(d)
+	defer timer.Stop()
+	select {
+	case <-timer.C:
+	case <-f.ctx.Done():
+	}
+}
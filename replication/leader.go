@@ -0,0 +1,217 @@
+package replication
+
+import (
+	"encoding/json"
+	"io"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"time"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+
+	"kvcache/config"
+	"kvcache/proto"
+	"kvcache/rpcerror"
+	"kvcache/storage"
+)
+
+const (
+	// chunkSize 快照和磁盘文件分块传输的大小
+	chunkSize = 1 << 20
+	// streamBatchSize 每次从变更日志读取的事件数
+	streamBatchSize = 500
+	// pollInterval 没有新变更时轮询变更日志的间隔
+	pollInterval = 100 * time.Millisecond
+	// heartbeatInterval 没有新变更时发送心跳的间隔，从节点据此更新主节点修订号
+	heartbeatInterval = time.Second
+)
+
+// Leader 主节点的复制服务，为从节点提供快照、变更流和磁盘文件
+type Leader struct {
+	proto.UnimplementedReplicationServer
+	store  storage.Storage
+	config *config.Config
+}
+
+// NewLeader 创建主节点复制服务
+func NewLeader(store storage.Storage, cfg *config.Config) *Leader {
+	return &Leader{store: store, config: cfg}
+}
+
+// Register 注册gRPC服务
+func (l *Leader) Register(srv *grpc.Server) {
+	proto.RegisterReplicationServer(srv, l)
+}
+
+// Snapshot 创建RocksDB检查点并逐个文件发送
+func (l *Leader) Snapshot(req *proto.SnapshotRequest, stream proto.Replication_SnapshotServer) error {
+	// 1. 检查点创建在数据目录旁边，与数据目录位于同一文件系统时使用硬链接
+	parent := filepath.Dir(filepath.Clean(l.config.RocksDB.Path))
+	tmp, err := os.MkdirTemp(parent, ".checkpoint-")
+	if err != nil {
+		return status.Error(codes.Internal, err.Error())
+	}
+	defer os.RemoveAll(tmp)
+
+	dir := filepath.Join(tmp, "db")
+	revision, err := l.store.CreateCheckpoint(dir)
+	if err != nil {
+		return status.Error(codes.Internal, err.Error())
+	}
+
+	// 2. 按相对路径发送检查点中的文件
+	return filepath.WalkDir(dir, func(path string, d fs.DirEntry, err error) error {
+		if err != nil || d.IsDir() {
+			return err
+		}
+		rel, err := filepath.Rel(dir, path)
+		if err != nil {
+			return err
+		}
+		return sendFile(path, func(data []byte) error {
+			return stream.Send(&proto.SnapshotChunk{Revision: revision, Path: filepath.ToSlash(rel), Data: data})
+		})
+	})
+}
+
+// Stream 从指定修订号开始发送变更，写入类事件附带键的当前状态
+func (l *Leader) Stream(req *proto.StreamRequest, stream proto.Replication_StreamServer) error {
+	ctx := stream.Context()
+	from := req.FromRevision
+	if from > l.store.LatestRevision()+1 {
+		return status.Errorf(codes.FailedPrecondition, "revision %d is ahead of leader", from)
+	}
+	lastSend := time.Now()
+
+	for {
+		events, err := l.store.ReadChanges(from, streamBatchSize)
+		if err != nil {
+			// 已裁剪的修订号返回OutOfRange，从节点据此重新同步快照
+			return rpcerror.Status(err)
+		}
+		leaderRevision := l.store.LatestRevision()
+
+		for _, event := range events {
+			msg, err := l.eventMessage(event)
+			if err != nil {
+				return status.Error(codes.Internal, err.Error())
+			}
+			msg.LeaderRevision = leaderRevision
+			if err := stream.Send(msg); err != nil {
+				return err
+			}
+			from = event.Revision + 1
+			lastSend = time.Now()
+		}
+		if len(events) > 0 {
+			continue
+		}
+
+		// 没有新变更时定期发送心跳
+		if time.Since(lastSend) >= heartbeatInterval {
+			if err := stream.Send(&proto.ReplicationEvent{LeaderRevision: leaderRevision}); err != nil {
+				return err
+			}
+			lastSend = time.Now()
+		}
+
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case <-time.After(pollInterval):
+		}
+	}
+}
+
+// eventMessage 将变更事件转换为复制消息
+func (l *Leader) eventMessage(event storage.ChangeEvent) (*proto.ReplicationEvent, error) {
+	msg := &proto.ReplicationEvent{
+		Revision:  event.Revision,
+		Type:      event.Type,
+		Namespace: event.Namespace,
+		Key:       event.Key,
+		End:       event.End,
+		Version:   event.Version,
+		Size:      event.Size,
+		Time:      event.Time,
+	}
+
+	switch event.Type {
+	case storage.EventDelete, storage.EventExpire, storage.EventDeleteRange:
+		return msg, nil
+	}
+
+	// 命名空间已被删除时只发送事件
+	view, err := l.store.Namespace(event.Namespace)
+	if err != nil {
+		return msg, nil
+	}
+	entry, err := view.ExportEntry(event.Key)
+	if err != nil || entry == nil {
+		return msg, err
+	}
+
+	meta, err := json.Marshal(entry.Meta)
+	if err != nil {
+		return nil, err
+	}
+	msg.HasEntry = true
+	msg.Meta = meta
+	msg.Value = entry.Value
+	return msg, nil
+}
+
+// FetchBlob 发送命名空间中的磁盘文件
+func (l *Leader) FetchBlob(req *proto.FetchBlobRequest, stream proto.Replication_FetchBlobServer) error {
+	view, err := l.store.Namespace(req.Namespace)
+	if err != nil {
+		return status.Error(codes.NotFound, err.Error())
+	}
+
+	r, err := view.OpenBlob(req.FileName)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return status.Error(codes.NotFound, err.Error())
+		}
+		return status.Error(codes.InvalidArgument, err.Error())
+	}
+	defer r.Close()
+
+	return sendChunks(r, func(data []byte) error {
+		return stream.Send(&proto.BlobChunk{Data: data})
+	})
+}
+
+// sendFile 分块发送文件
+func sendFile(path string, send func([]byte) error) error {
+	f, err := os.Open(path)
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+	return sendChunks(f, send)
+}
+
+// sendChunks 分块发送数据，空文件也发送一个分块
+func sendChunks(r io.Reader, send func([]byte) error) error {
+	buf := make([]byte, chunkSize)
+	sent := false
+	for {
+		n, err := io.ReadFull(r, buf)
+		if n > 0 || !sent {
+			if err := send(buf[:n]); err != nil {
+				return err
+			}
+			sent = true
+		}
+		if err == io.EOF || err == io.ErrUnexpectedEOF {
+			return nil
+		}
+		if err != nil {
+			return err
+		}
+	}
+}
@@ -0,0 +1,45 @@
+package replication
+
+import "github.com/prometheus/client_golang/prometheus"
+
+// metrics 从节点复制监控指标
+type metrics struct {
+	lagRevisions prometheus.Gauge
+	lagSeconds   prometheus.Gauge
+	applied      prometheus.Counter
+	connected    prometheus.Gauge
+}
+
+var replicationMetrics = newMetrics()
+
+// newMetrics 创建并注册复制监控指标
+func newMetrics() *metrics {
+	m := &metrics{
+		lagRevisions: prometheus.NewGauge(prometheus.GaugeOpts{
+			Namespace: "cachefs",
+			Subsystem: "kv",
+			Name:      "replication_lag_revisions",
+			Help:      "Number of leader revisions not yet applied by this follower",
+		}),
+		lagSeconds: prometheus.NewGauge(prometheus.GaugeOpts{
+			Namespace: "cachefs",
+			Subsystem: "kv",
+			Name:      "replication_lag_seconds",
+			Help:      "Age of the last change applied by this follower while it is behind the leader",
+		}),
+		applied: prometheus.NewCounter(prometheus.CounterOpts{
+			Namespace: "cachefs",
+			Subsystem: "kv",
+			Name:      "replication_applied_total",
+			Help:      "Total number of leader changes applied by this follower",
+		}),
+		connected: prometheus.NewGauge(prometheus.GaugeOpts{
+			Namespace: "cachefs",
+			Subsystem: "kv",
+			Name:      "replication_connected",
+			Help:      "Whether this follower is connected to the leader change stream",
+		}),
+	}
+	prometheus.MustRegister(m.lagRevisions, m.lagSeconds, m.applied, m.connected)
+	return m
+}
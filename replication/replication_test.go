@@ -0,0 +1,41 @@
+package replication
+
+import (
+	"bytes"
+	"testing"
+)
+
+func TestSendChunks(t *testing.T) {
+	tests := []struct {
+		name   string
+		size   int
+		chunks int
+	}{
+		{"empty", 0, 1},
+		{"single", 10, 1},
+		{"exact", chunkSize, 1},
+		{"multiple", chunkSize*2 + 1, 3},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			data := bytes.Repeat([]byte("x"), tt.size)
+
+			var received [][]byte
+			err := sendChunks(bytes.NewReader(data), func(chunk []byte) error {
+				received = append(received, append([]byte(nil), chunk...))
+				return nil
+			})
+			if err != nil {
+				t.Fatalf("Failed to send chunks: %v", err)
+			}
+
+			if len(received) != tt.chunks {
+				t.Errorf("Expected %d chunks, got %d", tt.chunks, len(received))
+			}
+			if got := bytes.Join(received, nil); !bytes.Equal(got, data) {
+				t.Errorf("Expected %d bytes, got %d", len(data), len(got))
+			}
+		})
+	}
+}
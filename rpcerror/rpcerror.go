@@ -0,0 +1,97 @@
+package rpcerror
+
+import (
+	"strings"
+
+	"google.golang.org/genproto/googleapis/rpc/errdetails"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+
+	"kvcache/storage"
+)
+
+// 节点之间的错误传递：错误按storage.Code转换为gRPC状态，详情中的ErrorInfo以大写的类别作为Reason，
+// 收到状态的节点再转换回同一类别的错误，转发的请求与本地处理的请求返回相同的错误
+
+// grpcCodes 错误类别对应的gRPC状态码，未列出的类别返回codes.Internal
+var grpcCodes = map[storage.Code]codes.Code{
+	storage.CodeNotFound:          codes.NotFound,
+	storage.CodeEvicted:           codes.NotFound,
+	storage.CodeInvalidArgument:   codes.InvalidArgument,
+	storage.CodeResourceExhausted: codes.ResourceExhausted,
+	storage.CodeConflict:          codes.Aborted,
+	storage.CodeUnavailable:       codes.Unavailable,
+	storage.CodeCorrupted:         codes.DataLoss,
+	storage.CodeUnimplemented:     codes.Unimplemented,
+	storage.CodeOutOfRange:        codes.OutOfRange,
+	storage.CodeCanceled:          codes.Canceled,
+	storage.CodeDeadlineExceeded:  codes.DeadlineExceeded,
+}
+
+// storageCodes gRPC状态码对应的错误类别，状态详情中没有类别时使用
+var storageCodes = map[codes.Code]storage.Code{
+	codes.NotFound:          storage.CodeNotFound,
+	codes.InvalidArgument:   storage.CodeInvalidArgument,
+	codes.ResourceExhausted: storage.CodeResourceExhausted,
+	codes.Aborted:           storage.CodeConflict,
+	codes.Unavailable:       storage.CodeUnavailable,
+	codes.DataLoss:          storage.CodeCorrupted,
+	codes.Unimplemented:     storage.CodeUnimplemented,
+	codes.OutOfRange:        storage.CodeOutOfRange,
+	codes.Canceled:          storage.CodeCanceled,
+	codes.DeadlineExceeded:  storage.CodeDeadlineExceeded,
+}
+
+// Status 将错误转换为gRPC状态，已是gRPC状态的错误原样返回
+func Status(err error) error {
+	if err == nil {
+		return nil
+	}
+	if _, ok := status.FromError(err); ok {
+		return err
+	}
+
+	code := storage.ErrorCode(err)
+	grpcCode, ok := grpcCodes[code]
+	if !ok {
+		grpcCode = codes.Internal
+	}
+	return WithErrorInfo(status.New(grpcCode, err.Error()), strings.ToUpper(string(code)), nil)
+}
+
+// WithErrorInfo 在状态详情中附加错误类别，附加失败时返回不带详情的状态
+func WithErrorInfo(st *status.Status, reason string, metadata map[string]string) error {
+	detailed, err := st.WithDetails(&errdetails.ErrorInfo{Reason: reason, Domain: storage.ErrorDomain, Metadata: metadata})
+	if err != nil {
+		return st.Err()
+	}
+	return detailed.Err()
+}
+
+// FromStatus 将其他节点返回的gRPC状态转换为同一类别的错误，无法分类的状态原样返回
+func FromStatus(err error) error {
+	st, ok := status.FromError(err)
+	if !ok {
+		return err
+	}
+
+	code, ok := storageCodes[st.Code()]
+	for _, detail := range st.Details() {
+		if info, isInfo := detail.(*errdetails.ErrorInfo); isInfo && info.Domain == storage.ErrorDomain {
+			code, ok = storage.Code(strings.ToLower(info.Reason)), true
+		}
+	}
+	if !ok || code == storage.CodeInternal {
+		return err
+	}
+	if code == storage.CodeEvicted {
+		return storage.ErrEvicted
+	}
+
+	// 无法识别的类别（例如重定向）保留原始状态
+	converted := storage.NewError(code, st.Message())
+	if storage.ErrorCode(converted) != code {
+		return err
+	}
+	return converted
+}
@@ -0,0 +1,51 @@
+package rpcerror
+
+import (
+	"errors"
+	"fmt"
+	"testing"
+
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+
+	"kvcache/storage"
+)
+
+// TestStatusRoundTrip 测试错误经gRPC状态传递后保持原来的类别
+func TestStatusRoundTrip(t *testing.T) {
+	tests := []struct {
+		err  error
+		code codes.Code
+	}{
+		{fmt.Errorf("%w: prefix %q", storage.ErrQuotaExceeded, "a"), codes.ResourceExhausted},
+		{fmt.Errorf("%w: %s", storage.ErrReservedKey, storage.ReservedKeyPrefix), codes.InvalidArgument},
+		{storage.ErrEvicted, codes.NotFound},
+		{storage.NewError(storage.CodeUnavailable, "node is not the leader"), codes.Unavailable},
+	}
+	for _, tt := range tests {
+		st := Status(tt.err)
+		if status.Code(st) != tt.code {
+			t.Errorf("Status(%v): expected %s, got %s", tt.err, tt.code, status.Code(st))
+		}
+		converted := FromStatus(st)
+		if storage.ErrorCode(converted) != storage.ErrorCode(tt.err) || converted.Error() != tt.err.Error() {
+			t.Errorf("FromStatus(%v): expected %s %q, got %s %q", st, storage.ErrorCode(tt.err), tt.err, storage.ErrorCode(converted), converted)
+		}
+	}
+
+	// 已淘汰的值转换回storage.ErrEvicted
+	if !errors.Is(FromStatus(Status(storage.ErrEvicted)), storage.ErrEvicted) {
+		t.Errorf("Expected evicted error to round-trip")
+	}
+
+	// 无法分类的错误返回Internal，转换时保留原始状态
+	st := Status(errors.New("disk failure"))
+	if status.Code(st) != codes.Internal || FromStatus(st) != st {
+		t.Errorf("Expected unclassified error to stay an internal status, got %v", FromStatus(st))
+	}
+
+	// 没有详情的状态按状态码分类
+	if storage.ErrorCode(FromStatus(status.Error(codes.DeadlineExceeded, "timeout"))) != storage.CodeDeadlineExceeded {
+		t.Errorf("Expected DeadlineExceeded status to map to deadline_exceeded")
+	}
+}
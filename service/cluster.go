@@ -0,0 +1,221 @@
+package service
+
+import (
+	"context"
+	"time"
+
+	"kvcache/config"
+	"kvcache/storage"
+)
+
+const (
+	// CommandSet 设置键值对
+	CommandSet = "set"
+	// CommandDelete 删除键值对
+	CommandDelete = "delete"
+	// CommandMSet 批量设置键值对
+	CommandMSet = "mset"
+	// CommandMDelete 批量删除键值对
+	CommandMDelete = "mdelete"
+	// CommandUpdateConfig 更新全局配置
+	CommandUpdateConfig = "update_config"
+	// CommandCreateNamespace 创建命名空间
+	CommandCreateNamespace = "create_namespace"
+	// CommandDropNamespace 删除命名空间
+	CommandDropNamespace = "drop_namespace"
+)
+
+var (
+	// ErrNotReplicated 集群模式下只允许通过复制日志执行的写操作
+	ErrNotReplicated = storage.NewError(storage.CodeUnimplemented, "operation is not supported in cluster mode")
+	// ErrClusterDisabled 未启用集群模式
+	ErrClusterDisabled = storage.NewError(storage.CodeUnimplemented, "cluster mode is not enabled")
+)
+
+// Command 集群模式下写入复制日志的写操作，所有节点按日志顺序执行
+type Command struct {
+	Op        string                  `json:"op"`
+	Namespace string                  `json:"namespace,omitempty"`
+	Key       string                  `json:"key,omitempty"`
+	Value     []byte                  `json:"value,omitempty"`
+	TTL       int64                   `json:"ttl,omitempty"`        // 请求的存活时间（毫秒），0表示使用命名空间的默认TTL
+	ExpiresAt int64                   `json:"expires_at,omitempty"` // 领导者提交前确定的过期时间（Unix毫秒），0表示永不过期
+	KVs       map[string][]byte       `json:"kvs,omitempty"`
+	Keys      []string                `json:"keys,omitempty"`
+	Config    *config.Config          `json:"config,omitempty"`
+	NsConfig  *config.NamespaceConfig `json:"ns_config,omitempty"`
+}
+
+// ClusterMember 集群成员
+type ClusterMember struct {
+	ID       string `json:"id"`
+	RaftAddr string `json:"raft_addr"`
+	GRPCAddr string `json:"grpc_addr,omitempty"` // 转发写请求和读屏障使用的gRPC地址
+	Voter    bool   `json:"voter"`
+	Leader   bool   `json:"leader"`
+}
+
+// ClusterStatus 集群状态
+type ClusterStatus struct {
+	NodeID       string          `json:"node_id"`
+	State        string          `json:"state"` // Leader、Follower或Candidate
+	Leader       string          `json:"leader,omitempty"`
+	Term         uint64          `json:"term"`
+	CommitIndex  uint64          `json:"commit_index"`
+	AppliedIndex uint64          `json:"applied_index"`
+	Members      []ClusterMember `json:"members"`
+}
+
+// Cluster 集群节点，负责提交命令、线性一致读和成员变更
+type Cluster interface {
+	Apply(ctx context.Context, cmd *Command) error
+	ReadBarrier(ctx context.Context) error
+	Status() (*ClusterStatus, error)
+	AddMember(ctx context.Context, member ClusterMember) error
+	RemoveMember(ctx context.Context, id string) error
+}
+
+// clusterHolder 包装Cluster以便原子替换
+type clusterHolder struct {
+	Cluster
+}
+
+// SetCluster 启用集群模式，之后的写操作通过复制日志执行
+func (s *KVService) SetCluster(c Cluster) {
+	s.cluster.Store(&clusterHolder{c})
+}
+
+// replicate 集群模式下将写操作提交到复制日志并等待本节点执行完成，返回false表示单机模式
+func (s *KVService) replicate(ctx context.Context, cmd *Command) (bool, error) {
+	holder := s.cluster.Load()
+	if holder == nil {
+		return false, nil
+	}
+	if cmd.Namespace == "" {
+		cmd.Namespace = NamespaceFromContext(ctx)
+	}
+	return true, holder.Apply(ctx, cmd)
+}
+
+// linearize 集群模式下等待本节点执行完读取时刻已提交的命令
+func (s *KVService) linearize(ctx context.Context) error {
+	holder := s.cluster.Load()
+	if holder == nil || !s.config.Cluster.LinearizableReads {
+		return nil
+	}
+	return holder.ReadBarrier(ctx)
+}
+
+// PrepareCommand 由领导者在提交命令前调用，将写入的TTL转换为绝对过期时间
+// 各节点执行日志的时刻不同，重启后还会重放快照之后的日志，只有绝对过期时间能让所有节点在同一时刻过期
+func (s *KVService) PrepareCommand(cmd *Command) error {
+	if cmd.Op != CommandSet && cmd.Op != CommandMSet {
+		return nil
+	}
+
+	ttl := time.Duration(cmd.TTL) * time.Millisecond
+	if ttl <= 0 {
+		ns, err := s.namespace(WithNamespace(context.Background(), cmd.Namespace))
+		if err != nil {
+			return err
+		}
+		ttl = ns.defaultTTL
+	}
+	if ttl > 0 {
+		cmd.ExpiresAt = time.Now().Add(ttl).UnixMilli()
+	}
+	return nil
+}
+
+// remainingTTL 返回写命令在now时刻剩余的存活时间，已过期时返回true
+// 旧版本提交的日志没有绝对过期时间，按执行时刻的相对TTL处理
+func (cmd *Command) remainingTTL(now time.Time) (time.Duration, bool) {
+	if cmd.ExpiresAt == 0 {
+		return time.Duration(cmd.TTL) * time.Millisecond, false
+	}
+	ttl := time.UnixMilli(cmd.ExpiresAt).Sub(now)
+	return ttl, ttl <= 0
+}
+
+// ApplyCommand 在本节点执行已提交的命令，由复制状态机按日志顺序调用
+// 执行时已过期的写入等同于删除，重放的旧日志不会让已过期的键重新出现
+func (s *KVService) ApplyCommand(cmd *Command) error {
+	ctx := WithNamespace(context.Background(), cmd.Namespace)
+
+	switch cmd.Op {
+	case CommandSet:
+		ttl, expired := cmd.remainingTTL(time.Now())
+		if expired {
+			return s.delete(ctx, cmd.Key)
+		}
+		return s.set(ctx, cmd.Key, cmd.Value, ttl)
+	case CommandDelete:
+		return s.delete(ctx, cmd.Key)
+	case CommandMSet:
+		ttl, expired := cmd.remainingTTL(time.Now())
+		if expired {
+			keys := make([]string, 0, len(cmd.KVs))
+			for key := range cmd.KVs {
+				keys = append(keys, key)
+			}
+			return s.mdelete(ctx, keys)
+		}
+		return s.mset(ctx, cmd.KVs, ttl)
+	case CommandMDelete:
+		return s.mdelete(ctx, cmd.Keys)
+	case CommandUpdateConfig:
+		if cmd.Config == nil {
+			return storage.NewError(storage.CodeInvalidArgument, "empty config")
+		}
+		// 数据路径、复制角色、集群成员身份和分片地址属于本节点，不随集群复制
+		cfg := *cmd.Config
+		cfg.RocksDB = s.config.RocksDB
+		cfg.Value.DiskPath = s.config.Value.DiskPath
+		cfg.Replication = s.config.Replication
+		cfg.Cluster = s.config.Cluster
+		cfg.Sharding = s.config.Sharding
+		return s.updateConfig(&cfg)
+	case CommandCreateNamespace:
+		if cmd.NsConfig == nil {
+			return storage.NewError(storage.CodeInvalidArgument, "empty namespace")
+		}
+		return s.createNamespace(cmd.NsConfig)
+	case CommandDropNamespace:
+		return s.dropNamespace(cmd.Namespace)
+	default:
+		return storage.Errorf(storage.CodeInvalidArgument, "unknown command: %s", cmd.Op)
+	}
+}
+
+// ClusterStatus 返回集群状态
+func (s *KVService) ClusterStatus(ctx context.Context) (*ClusterStatus, error) {
+	holder := s.cluster.Load()
+	if holder == nil {
+		return nil, ErrClusterDisabled
+	}
+	return holder.Status()
+}
+
+// AddMember 向集群添加成员
+func (s *KVService) AddMember(ctx context.Context, member ClusterMember) error {
+	holder := s.cluster.Load()
+	if holder == nil {
+		return ErrClusterDisabled
+	}
+	if member.ID == "" || member.RaftAddr == "" {
+		return storage.NewError(storage.CodeInvalidArgument, "member id and raft_addr cannot be empty")
+	}
+	return holder.AddMember(ctx, member)
+}
+
+// RemoveMember 从集群移除成员
+func (s *KVService) RemoveMember(ctx context.Context, id string) error {
+	holder := s.cluster.Load()
+	if holder == nil {
+		return ErrClusterDisabled
+	}
+	if id == "" {
+		return storage.NewError(storage.CodeInvalidArgument, "member id cannot be empty")
+	}
+	return holder.RemoveMember(ctx, id)
+}
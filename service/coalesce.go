@@ -0,0 +1,212 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"sync"
+
+	"kvcache/storage"
+)
+
+// flight 一次进行中的存储读取，done关闭后结果可用
+type flight struct {
+	done  chan struct{}
+	value []byte
+	found bool
+	err   error
+}
+
+// flightGroup 合并对同一键并发的存储读取，Get和MGet共享进行中的读取
+type flightGroup struct {
+	mu      sync.Mutex
+	flights map[string]*flight
+}
+
+// newFlightGroup 创建读取合并组
+func newFlightGroup() *flightGroup {
+	return &flightGroup{flights: make(map[string]*flight)}
+}
+
+// join 为每个键加入进行中的读取，返回由本次调用读取的键和等待其他调用读取的键
+func (g *flightGroup) join(keys []string) (leading, waiting map[string]*flight) {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+
+	leading = make(map[string]*flight)
+	waiting = make(map[string]*flight)
+	for _, key := range keys {
+		if _, ok := leading[key]; ok {
+			continue
+		}
+		if f, ok := g.flights[key]; ok {
+			waiting[key] = f
+			continue
+		}
+		f := &flight{done: make(chan struct{})}
+		g.flights[key] = f
+		leading[key] = f
+	}
+	return leading, waiting
+}
+
+// finish 发布读取结果，读取期间键未被写入时在持有锁的情况下执行publish，例如写入缓存
+func (g *flightGroup) finish(key string, f *flight, publish func()) {
+	g.mu.Lock()
+	if g.flights[key] == f {
+		delete(g.flights, key)
+		if publish != nil {
+			publish()
+		}
+	}
+	g.mu.Unlock()
+	close(f.done)
+}
+
+// forget 键被写入后调用，之后的读取不再加入写入前开始的读取，其结果也不会写入缓存
+func (g *flightGroup) forget(keys ...string) {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+
+	for _, key := range keys {
+		delete(g.flights, key)
+	}
+}
+
+// forgetFunc 对满足条件的键调用forget
+func (g *flightGroup) forgetFunc(match func(key string) bool) {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+
+	for key := range g.flights {
+		if match(key) {
+			delete(g.flights, key)
+		}
+	}
+}
+
+// wait 等待读取完成
+func (f *flight) wait(ctx context.Context) error {
+	select {
+	case <-f.done:
+		return f.err
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
+
+// loadKey 从存储读取单个键，并发读取同一键时只有一个调用访问存储
+func (s *KVService) loadKey(ctx context.Context, ns *nsState, key string) ([]byte, bool, error) {
+	leading, waiting := ns.flights.join([]string{key})
+	if f, ok := waiting[key]; ok {
+		s.metrics.CoalescedLoads.WithLabelValues("get").Inc()
+		return s.awaitFlight(ctx, ns, key, f)
+	}
+
+	f := leading[key]
+	s.metrics.StorageLoads.WithLabelValues("get").Inc()
+	f.value, f.found, f.err = ns.storage.GetContext(ctx, []byte(key))
+	if f.err == nil && !f.found {
+		f.value, f.found, f.err = s.loadOrigin(ctx, ns, key)
+	} else if errors.Is(f.err, storage.ErrEvicted) {
+		// 已淘汰的值从源站重新加载，源站没有该键时仍返回已淘汰
+		if value, found, err := s.loadOrigin(ctx, ns, key); err != nil || found {
+			f.value, f.found, f.err = value, found, err
+		}
+	}
+	s.publish(ns, key, f)
+	return f.value, f.found, f.err
+}
+
+// loadKeys 从存储批量读取键，其他调用正在读取的键等待其结果，只读取剩余的键
+func (s *KVService) loadKeys(ctx context.Context, ns *nsState, keys []string) (map[string][]byte, error) {
+	leading, waiting := ns.flights.join(keys)
+	results := make(map[string][]byte, len(keys))
+
+	// 1. 读取由本次调用负责的键
+	if len(leading) > 0 {
+		byteKeys := make([][]byte, 0, len(leading))
+		for key := range leading {
+			byteKeys = append(byteKeys, []byte(key))
+		}
+		s.metrics.StorageLoads.WithLabelValues("mget").Add(float64(len(leading)))
+		values, err := ns.storage.MGetContext(ctx, byteKeys)
+
+		// 存储中没有的键和已淘汰的键从源站加载
+		var originErrs map[string]error
+		if err == nil && s.loader.Load() != nil {
+			var missing []string
+			for key := range leading {
+				if _, ok := values[key]; !ok {
+					missing = append(missing, key)
+				}
+			}
+			var loaded map[string][]byte
+			loaded, originErrs = s.loadOriginKeys(ctx, ns, missing)
+			for key, value := range loaded {
+				values[key] = value
+			}
+		}
+
+		for key, f := range leading {
+			f.value, f.found = values[key]
+			f.err = err
+			if f.err == nil {
+				f.err = originErrs[key]
+			}
+			s.publish(ns, key, f)
+			if f.found {
+				results[key] = f.value
+			}
+		}
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	// 2. 等待其他调用读取的键
+	if len(waiting) > 0 {
+		s.metrics.CoalescedLoads.WithLabelValues("mget").Add(float64(len(waiting)))
+	}
+	for key, f := range waiting {
+		value, found, err := s.awaitFlight(ctx, ns, key, f)
+		if err != nil {
+			return nil, err
+		}
+		if found {
+			results[key] = value
+		}
+	}
+	return results, nil
+}
+
+// awaitFlight 等待其他调用读取的键，负责读取的调用被取消或超时而本次调用仍有效时由本次调用重新读取
+func (s *KVService) awaitFlight(ctx context.Context, ns *nsState, key string, f *flight) ([]byte, bool, error) {
+	if err := f.wait(ctx); err != nil {
+		if ctx.Err() == nil && (errors.Is(err, context.Canceled) || errors.Is(err, context.DeadlineExceeded)) {
+			return s.loadKey(ctx, ns, key)
+		}
+		return nil, false, err
+	}
+	return f.value, f.found, nil
+}
+
+// publish 发布读取结果，值小于缓存阈值时按键剩余的存活时间写入缓存
+func (s *KVService) publish(ns *nsState, key string, f *flight) {
+	if f.err != nil || !f.found || !ns.config.Cache.Enabled || len(f.value) >= ns.config.Cache.SizeThreshold {
+		ns.flights.finish(key, f, nil)
+		return
+	}
+
+	info, found, err := ns.storage.GetMeta([]byte(key))
+	if err != nil || !found || info.TTL == 0 {
+		ns.flights.finish(key, f, nil)
+		return
+	}
+	ttl := info.TTL
+	if ttl < 0 {
+		ttl = 0
+	}
+	ns.flights.finish(key, f, func() {
+		ns.cache.Set(key, f.value, ttl)
+	})
+}
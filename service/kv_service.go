@@ -3,52 +3,130 @@ package service
 import (
 	"context"
 	"errors"
+	"fmt"
+	"strings"
 	"sync"
+	"sync/atomic"
 	"time"
 
+	"kvcache/cache"
 	"kvcache/config"
 	"kvcache/storage"
 )
 
+var (
+	// ErrEmptyKey 键为空
+	ErrEmptyKey = storage.NewError(storage.CodeInvalidArgument, "empty key")
+	// ErrKeyNotFound 键不存在
+	ErrKeyNotFound = storage.NewError(storage.CodeNotFound, "key not found")
+)
+
 // KVService 键值存储服务
 type KVService struct {
 	storage storage.Storage
 	config  *config.Config
 	metrics *Metrics
-	cache   sync.Map // 内存缓存，使用sync.Map保证线程安全
+	cache   *cache.Cache // 默认命名空间的内存缓存，按字节限制容量
+	flights *flightGroup // 默认命名空间进行中的存储读取
+
+	namespaces sync.Map // 命名空间名称 -> *nsState，默认命名空间不在其中
+
+	replicator atomic.Pointer[replicatorHolder] // 从节点的复制进程，主节点为nil
+	cluster    atomic.Pointer[clusterHolder]    // 集群模式下的Raft节点，单机模式为nil
+	router     atomic.Pointer[routerHolder]     // 服务端分片路由，未启用分片时为nil
+	migrator   atomic.Pointer[migratorHolder]   // 分片迁移，未启用分片时为nil
+	loader     atomic.Pointer[loaderHolder]     // 缓存未命中时加载的源站，未设置时为nil
 }
 
 // NewKVService 创建新的键值存储服务实例
 func NewKVService(storage storage.Storage, config *config.Config) *KVService {
 	metrics := NewMetrics()
-	return &KVService{
+	service := &KVService{
 		storage: storage,
 		config:  config,
 		metrics: metrics,
+		cache:   newDefaultCache(config),
+		flights: newFlightGroup(),
+	}
+	quotaMetrics.source.Store(service)
+	return service
+}
+
+// checkReserved 检查用户写入的键，保留给内部使用的键不能写入
+func checkReserved(keys ...string) error {
+	for _, key := range keys {
+		if storage.IsReservedKey([]byte(key)) {
+			return fmt.Errorf("%w: %s", storage.ErrReservedKey, key)
+		}
 	}
+	return nil
 }
 
 // Set 设置键值对
 func (s *KVService) Set(ctx context.Context, key string, value []byte, ttl time.Duration) error {
+	if err := checkReserved(key); err != nil {
+		s.metrics.SetErrors.WithLabelValues("reserved_key").Inc()
+		return err
+	}
+	shard, done, err := s.remoteWrite(ctx, key)
+	if err != nil {
+		return err
+	}
+	defer done()
+	if shard != nil {
+		return shard.Set(ctx, key, value, ttl)
+	}
+
+	if replicated, err := s.replicate(ctx, &Command{Op: CommandSet, Key: key, Value: value, TTL: ttl.Milliseconds()}); replicated {
+		return err
+	}
+	if err := s.writable(); err != nil {
+		return err
+	}
+	return s.set(ctx, key, value, ttl)
+}
+
+// set 在本节点设置键值对
+func (s *KVService) set(ctx context.Context, key string, value []byte, ttl time.Duration) error {
 	start := time.Now()
 	defer func() {
 		s.metrics.SetLatency.WithLabelValues("kv").Observe(time.Since(start).Seconds())
 	}()
 
+	ns, err := s.namespace(ctx)
+	if err != nil {
+		s.metrics.SetErrors.WithLabelValues("namespace_not_found").Inc()
+		return err
+	}
+	if ttl <= 0 {
+		ttl = ns.defaultTTL
+	}
+
 	if key == "" {
 		s.metrics.SetErrors.WithLabelValues("empty_key").Inc()
-		return errors.New("empty key")
+		return ErrEmptyKey
 	}
 
-	err := s.storage.Set([]byte(key), value)
+	// 启用写回时先标记键，写入成功后由后台写回源站
+	if err := s.markWriteBehind(ns, key); err != nil {
+		s.metrics.SetErrors.WithLabelValues(string(storage.ErrorCode(err))).Inc()
+		return err
+	}
+
+	err = ns.storage.SetWithTTL([]byte(key), value, ttl)
 	if err != nil {
-		s.metrics.SetErrors.WithLabelValues(err.Error()).Inc()
+		s.metrics.SetErrors.WithLabelValues(string(storage.ErrorCode(err))).Inc()
 		return err
 	}
 
-	// 检查是否需要写入缓存
-	if s.config.Cache.Enabled && len(value) < s.config.Cache.SizeThreshold {
-		s.cache.Store(key, value)
+	// 检查是否需要写入缓存，缓存条目与存储使用相同的TTL
+	ns.flights.forget(key)
+	if ns.config.Cache.Enabled {
+		if len(value) < ns.config.Cache.SizeThreshold {
+			ns.cache.Set(key, value, ttl)
+		} else {
+			ns.cache.Delete(key)
+		}
 	}
 
 	s.metrics.Sets.Inc()
@@ -58,65 +136,340 @@ func (s *KVService) Set(ctx context.Context, key string, value []byte, ttl time.
 
 // Get 获取值
 func (s *KVService) Get(ctx context.Context, key string) ([]byte, error) {
+	shard, err := s.remote(ctx, key)
+	if err != nil {
+		return nil, err
+	}
+	if shard != nil {
+		return shard.Get(ctx, key)
+	}
+
+	if err := s.linearize(ctx); err != nil {
+		return nil, err
+	}
+
 	start := time.Now()
 	defer func() {
 		s.metrics.GetLatency.WithLabelValues("kv").Observe(time.Since(start).Seconds())
 	}()
 
+	ns, err := s.namespace(ctx)
+	if err != nil {
+		s.metrics.GetErrors.WithLabelValues("namespace_not_found").Inc()
+		return nil, err
+	}
+
 	if key == "" {
 		s.metrics.GetErrors.WithLabelValues("empty_key").Inc()
-		return nil, errors.New("empty key")
+		return nil, ErrEmptyKey
 	}
 
 	// 优先从缓存中查询
-	if s.config.Cache.Enabled {
-		if cachedValue, ok := s.cache.Load(key); ok {
+	if ns.config.Cache.Enabled {
+		if cachedValue, ok := ns.cache.Get(key); ok {
 			s.metrics.Gets.Inc()
-			return cachedValue.([]byte), nil
+			return cachedValue, nil
 		}
 	}
 
-	value, found, err := s.storage.Get([]byte(key))
+	// 缓存未命中时从存储读取，并发读取同一键时合并为一次读取，值小于缓存阈值时写入缓存
+	value, found, err := s.loadKey(ctx, ns, key)
+	if errors.Is(err, storage.ErrEvicted) {
+		s.metrics.GetErrors.WithLabelValues("evicted").Inc()
+		return nil, err
+	}
 	if err != nil {
-		s.metrics.GetErrors.WithLabelValues(err.Error()).Inc()
+		s.metrics.GetErrors.WithLabelValues(string(storage.ErrorCode(err))).Inc()
+		s.metrics.observeCancelled("get", err)
 		return nil, err
 	}
 
 	if !found {
 		s.metrics.GetErrors.WithLabelValues("not_found").Inc()
-		return nil, errors.New("key not found")
-	}
-
-	// 如果值小于缓存阈值，并且缓存未命中，则将值写入缓存
-	if s.config.Cache.Enabled && len(value) < s.config.Cache.SizeThreshold {
-		s.cache.Store(key, value)
+		return nil, ErrKeyNotFound
 	}
 
 	s.metrics.Gets.Inc()
 	return value, nil
 }
 
+// Stat 获取键的元数据，不读取值本身
+func (s *KVService) Stat(ctx context.Context, key string) (*storage.KeyInfo, error) {
+	shard, err := s.remote(ctx, key)
+	if err != nil {
+		return nil, err
+	}
+	if shard != nil {
+		return shard.Stat(ctx, key)
+	}
+
+	if err := s.linearize(ctx); err != nil {
+		return nil, err
+	}
+
+	start := time.Now()
+	defer func() {
+		s.metrics.GetLatency.WithLabelValues("stat").Observe(time.Since(start).Seconds())
+	}()
+
+	ns, err := s.namespace(ctx)
+	if err != nil {
+		s.metrics.GetErrors.WithLabelValues("namespace_not_found").Inc()
+		return nil, err
+	}
+
+	if key == "" {
+		s.metrics.GetErrors.WithLabelValues("empty_key").Inc()
+		return nil, ErrEmptyKey
+	}
+
+	info, found, err := ns.storage.GetMeta([]byte(key))
+	if err != nil {
+		s.metrics.GetErrors.WithLabelValues(string(storage.ErrorCode(err))).Inc()
+		return nil, err
+	}
+
+	if !found {
+		s.metrics.GetErrors.WithLabelValues("not_found").Inc()
+		return nil, ErrKeyNotFound
+	}
+
+	s.metrics.Stats.Inc()
+	return info, nil
+}
+
+// Exists 判断键是否存在，不读取值本身
+func (s *KVService) Exists(ctx context.Context, key string) (bool, error) {
+	shard, err := s.remote(ctx, key)
+	if err != nil {
+		return false, err
+	}
+	if shard != nil {
+		return shard.Exists(ctx, key)
+	}
+
+	if err := s.linearize(ctx); err != nil {
+		return false, err
+	}
+
+	start := time.Now()
+	defer func() {
+		s.metrics.GetLatency.WithLabelValues("exists").Observe(time.Since(start).Seconds())
+	}()
+
+	ns, err := s.namespace(ctx)
+	if err != nil {
+		s.metrics.GetErrors.WithLabelValues("namespace_not_found").Inc()
+		return false, err
+	}
+
+	if key == "" {
+		s.metrics.GetErrors.WithLabelValues("empty_key").Inc()
+		return false, ErrEmptyKey
+	}
+
+	s.metrics.Exists.Inc()
+
+	// 1. 缓存命中说明键存在
+	if ns.config.Cache.Enabled {
+		if _, ok := ns.cache.Get(key); ok {
+			return true, nil
+		}
+	}
+
+	// 2. 查询存储元数据
+	exists, err := ns.storage.Exists([]byte(key))
+	if err != nil {
+		s.metrics.GetErrors.WithLabelValues(string(storage.ErrorCode(err))).Inc()
+		return false, err
+	}
+
+	return exists, nil
+}
+
+// MExists 批量判断键是否存在
+func (s *KVService) MExists(ctx context.Context, keys []string) (map[string]bool, error) {
+	local, batches, err := s.splitKeys(ctx, keys)
+	if err != nil {
+		return nil, err
+	}
+	if len(batches) > 0 {
+		return s.shardedMExists(ctx, local, batches)
+	}
+
+	if err := s.linearize(ctx); err != nil {
+		return nil, err
+	}
+
+	start := time.Now()
+	defer func() {
+		s.metrics.GetLatency.WithLabelValues("mexists").Observe(time.Since(start).Seconds())
+	}()
+
+	ns, err := s.namespace(ctx)
+	if err != nil {
+		s.metrics.GetErrors.WithLabelValues("namespace_not_found").Inc()
+		return nil, err
+	}
+
+	if len(keys) == 0 {
+		return make(map[string]bool), nil
+	}
+
+	s.metrics.Exists.Inc()
+
+	// 1. 检查缓存
+	results := make(map[string]bool, len(keys))
+	var missingKeys [][]byte
+	for _, key := range keys {
+		if ns.config.Cache.Enabled {
+			if _, ok := ns.cache.Get(key); ok {
+				results[key] = true
+				continue
+			}
+		}
+		missingKeys = append(missingKeys, []byte(key))
+	}
+
+	// 2. 查询存储元数据
+	if len(missingKeys) > 0 {
+		storageResults, err := ns.storage.MExists(missingKeys)
+		if err != nil {
+			s.metrics.GetErrors.WithLabelValues(string(storage.ErrorCode(err))).Inc()
+			return nil, err
+		}
+		for key, exists := range storageResults {
+			results[key] = exists
+		}
+	}
+
+	return results, nil
+}
+
+// CountPrefix 统计前缀下的键数量，exact为false时返回估算值
+func (s *KVService) CountPrefix(ctx context.Context, prefix string, exact bool) (int64, error) {
+	batches, err := s.allShards(ctx)
+	if err != nil {
+		return 0, err
+	}
+	if len(batches) > 0 {
+		return s.shardedCountPrefix(ctx, prefix, exact, batches)
+	}
+
+	if err := s.linearize(ctx); err != nil {
+		return 0, err
+	}
+
+	start := time.Now()
+	defer func() {
+		s.metrics.ScanLatency.WithLabelValues("count").Observe(time.Since(start).Seconds())
+	}()
+
+	ns, err := s.namespace(ctx)
+	if err != nil {
+		s.metrics.ScanErrors.WithLabelValues("namespace_not_found").Inc()
+		return 0, err
+	}
+
+	count, err := ns.storage.CountPrefixContext(ctx, []byte(prefix), exact)
+	if err != nil {
+		s.metrics.ScanErrors.WithLabelValues(string(storage.ErrorCode(err))).Inc()
+		s.metrics.observeCancelled("count", err)
+		return 0, err
+	}
+
+	s.metrics.Counts.Inc()
+	return count, nil
+}
+
+// SizeOf 统计前缀下键的数量和容量
+func (s *KVService) SizeOf(ctx context.Context, prefix string) (*storage.PrefixSize, error) {
+	batches, err := s.allShards(ctx)
+	if err != nil {
+		return nil, err
+	}
+	if len(batches) > 0 {
+		return s.shardedSizeOf(ctx, prefix, batches)
+	}
+
+	if err := s.linearize(ctx); err != nil {
+		return nil, err
+	}
+
+	start := time.Now()
+	defer func() {
+		s.metrics.ScanLatency.WithLabelValues("size").Observe(time.Since(start).Seconds())
+	}()
+
+	ns, err := s.namespace(ctx)
+	if err != nil {
+		s.metrics.ScanErrors.WithLabelValues("namespace_not_found").Inc()
+		return nil, err
+	}
+
+	size, err := ns.storage.SizeOfContext(ctx, []byte(prefix))
+	if err != nil {
+		s.metrics.ScanErrors.WithLabelValues(string(storage.ErrorCode(err))).Inc()
+		s.metrics.observeCancelled("size", err)
+		return nil, err
+	}
+
+	s.metrics.Sizes.Inc()
+	return size, nil
+}
+
 // Delete 删除键值对
 func (s *KVService) Delete(ctx context.Context, key string) error {
+	if err := checkReserved(key); err != nil {
+		s.metrics.DeleteErrors.WithLabelValues("reserved_key").Inc()
+		return err
+	}
+	shard, done, err := s.remoteWrite(ctx, key)
+	if err != nil {
+		return err
+	}
+	defer done()
+	if shard != nil {
+		return shard.Delete(ctx, key)
+	}
+
+	if replicated, err := s.replicate(ctx, &Command{Op: CommandDelete, Key: key}); replicated {
+		return err
+	}
+	if err := s.writable(); err != nil {
+		return err
+	}
+	return s.delete(ctx, key)
+}
+
+// delete 在本节点删除键值对
+func (s *KVService) delete(ctx context.Context, key string) error {
 	start := time.Now()
 	defer func() {
 		s.metrics.DeleteLatency.WithLabelValues("kv").Observe(time.Since(start).Seconds())
 	}()
 
+	ns, err := s.namespace(ctx)
+	if err != nil {
+		s.metrics.DeleteErrors.WithLabelValues("namespace_not_found").Inc()
+		return err
+	}
+
 	if key == "" {
 		s.metrics.DeleteErrors.WithLabelValues("empty_key").Inc()
-		return errors.New("empty key")
+		return ErrEmptyKey
 	}
 
-	err := s.storage.Delete([]byte(key))
+	err = ns.storage.Delete([]byte(key))
 	if err != nil {
-		s.metrics.DeleteErrors.WithLabelValues(err.Error()).Inc()
+		s.metrics.DeleteErrors.WithLabelValues(string(storage.ErrorCode(err))).Inc()
 		return err
 	}
 
 	// 从缓存中删除
-	if s.config.Cache.Enabled {
-		s.cache.Delete(key)
+	ns.flights.forget(key)
+	if ns.config.Cache.Enabled {
+		ns.cache.Delete(key)
 	}
 
 	s.metrics.Deletes.Inc()
@@ -124,20 +477,331 @@ func (s *KVService) Delete(ctx context.Context, key string) error {
 	return nil
 }
 
+// Append 向值末尾追加数据
+func (s *KVService) Append(ctx context.Context, key string, data []byte) error {
+	if err := checkReserved(key); err != nil {
+		s.metrics.SetErrors.WithLabelValues("reserved_key").Inc()
+		return err
+	}
+	shard, done, err := s.remoteWrite(ctx, key)
+	if err != nil {
+		return err
+	}
+	defer done()
+	if shard != nil {
+		return shard.Append(ctx, key, data)
+	}
+
+	if err := s.writable(); err != nil {
+		return err
+	}
+
+	start := time.Now()
+	defer func() {
+		s.metrics.SetLatency.WithLabelValues("append").Observe(time.Since(start).Seconds())
+	}()
+
+	ns, err := s.namespace(ctx)
+	if err != nil {
+		s.metrics.SetErrors.WithLabelValues("namespace_not_found").Inc()
+		return err
+	}
+
+	if key == "" {
+		s.metrics.SetErrors.WithLabelValues("empty_key").Inc()
+		return ErrEmptyKey
+	}
+
+	err = ns.storage.Append([]byte(key), data)
+	if err != nil {
+		s.metrics.SetErrors.WithLabelValues(string(storage.ErrorCode(err))).Inc()
+		return err
+	}
+
+	// 值已变化，使缓存失效
+	ns.flights.forget(key)
+	if ns.config.Cache.Enabled {
+		ns.cache.Delete(key)
+	}
+
+	s.metrics.Appends.Inc()
+	return nil
+}
+
+// WriteAt 在值的指定偏移量写入数据
+func (s *KVService) WriteAt(ctx context.Context, key string, offset int64, data []byte) error {
+	if err := checkReserved(key); err != nil {
+		s.metrics.SetErrors.WithLabelValues("reserved_key").Inc()
+		return err
+	}
+	shard, done, err := s.remoteWrite(ctx, key)
+	if err != nil {
+		return err
+	}
+	defer done()
+	if shard != nil {
+		return shard.WriteAt(ctx, key, offset, data)
+	}
+
+	if err := s.writable(); err != nil {
+		return err
+	}
+
+	start := time.Now()
+	defer func() {
+		s.metrics.SetLatency.WithLabelValues("write_at").Observe(time.Since(start).Seconds())
+	}()
+
+	ns, err := s.namespace(ctx)
+	if err != nil {
+		s.metrics.SetErrors.WithLabelValues("namespace_not_found").Inc()
+		return err
+	}
+
+	if key == "" {
+		s.metrics.SetErrors.WithLabelValues("empty_key").Inc()
+		return ErrEmptyKey
+	}
+
+	if offset < 0 {
+		s.metrics.SetErrors.WithLabelValues("invalid_offset").Inc()
+		return storage.NewError(storage.CodeInvalidArgument, "invalid offset")
+	}
+
+	err = ns.storage.WriteAt([]byte(key), offset, data)
+	if err != nil {
+		s.metrics.SetErrors.WithLabelValues(string(storage.ErrorCode(err))).Inc()
+		return err
+	}
+
+	// 值已变化，使缓存失效
+	ns.flights.forget(key)
+	if ns.config.Cache.Enabled {
+		ns.cache.Delete(key)
+	}
+
+	s.metrics.WriteAts.Inc()
+	return nil
+}
+
+// Rename 将键重命名为dst，overwrite为false时目标键已存在则返回错误
+func (s *KVService) Rename(ctx context.Context, src, dst string, overwrite bool) error {
+	if err := checkReserved(src, dst); err != nil {
+		s.metrics.SetErrors.WithLabelValues("reserved_key").Inc()
+		return err
+	}
+	shard, done, err := s.remotePair(ctx, src, dst)
+	if err != nil {
+		return err
+	}
+	defer done()
+	if shard != nil {
+		return shard.Rename(ctx, src, dst, overwrite)
+	}
+
+	if err := s.writable(); err != nil {
+		return err
+	}
+
+	start := time.Now()
+	defer func() {
+		s.metrics.SetLatency.WithLabelValues("rename").Observe(time.Since(start).Seconds())
+	}()
+
+	ns, err := s.namespace(ctx)
+	if err != nil {
+		s.metrics.SetErrors.WithLabelValues("namespace_not_found").Inc()
+		return err
+	}
+
+	if src == "" || dst == "" {
+		s.metrics.SetErrors.WithLabelValues("empty_key").Inc()
+		return ErrEmptyKey
+	}
+
+	err = ns.storage.Rename([]byte(src), []byte(dst), overwrite)
+	if err != nil {
+		s.metrics.SetErrors.WithLabelValues(string(storage.ErrorCode(err))).Inc()
+		return err
+	}
+
+	// 缓存随键一起移动
+	ns.flights.forget(src, dst)
+	if ns.config.Cache.Enabled {
+		ns.cache.Move(src, dst)
+	}
+
+	s.metrics.Renames.Inc()
+	return nil
+}
+
+// Copy 将键复制到dst，目标键已存在时覆盖
+func (s *KVService) Copy(ctx context.Context, src, dst string) error {
+	if err := checkReserved(dst); err != nil {
+		s.metrics.SetErrors.WithLabelValues("reserved_key").Inc()
+		return err
+	}
+	shard, done, err := s.remotePair(ctx, src, dst)
+	if err != nil {
+		return err
+	}
+	defer done()
+	if shard != nil {
+		return shard.Copy(ctx, src, dst)
+	}
+
+	if err := s.writable(); err != nil {
+		return err
+	}
+
+	start := time.Now()
+	defer func() {
+		s.metrics.SetLatency.WithLabelValues("copy").Observe(time.Since(start).Seconds())
+	}()
+
+	ns, err := s.namespace(ctx)
+	if err != nil {
+		s.metrics.SetErrors.WithLabelValues("namespace_not_found").Inc()
+		return err
+	}
+
+	if src == "" || dst == "" {
+		s.metrics.SetErrors.WithLabelValues("empty_key").Inc()
+		return ErrEmptyKey
+	}
+
+	err = ns.storage.Copy([]byte(src), []byte(dst))
+	if err != nil {
+		s.metrics.SetErrors.WithLabelValues(string(storage.ErrorCode(err))).Inc()
+		return err
+	}
+
+	// 目标键的值已变化，复用源键的缓存
+	ns.flights.forget(dst)
+	if ns.config.Cache.Enabled {
+		ns.cache.Copy(src, dst)
+	}
+
+	s.metrics.Copies.Inc()
+	return nil
+}
+
+// DeletePrefix 删除前缀下的所有键，返回后台清理任务ID
+func (s *KVService) DeletePrefix(ctx context.Context, prefix string) (string, error) {
+	if err := s.writable(); err != nil {
+		return "", err
+	}
+
+	start := time.Now()
+	defer func() {
+		s.metrics.DeleteLatency.WithLabelValues("prefix").Observe(time.Since(start).Seconds())
+	}()
+
+	ns, err := s.namespace(ctx)
+	if err != nil {
+		s.metrics.DeleteErrors.WithLabelValues("namespace_not_found").Inc()
+		return "", err
+	}
+
+	jobID, err := ns.storage.DeletePrefix([]byte(prefix))
+	if err != nil {
+		s.metrics.DeleteErrors.WithLabelValues(string(storage.ErrorCode(err))).Inc()
+		return "", err
+	}
+
+	// 从缓存中删除
+	s.evictCache(ns, func(key string) bool {
+		return strings.HasPrefix(key, prefix)
+	})
+
+	s.metrics.RangeDeletes.Inc()
+	return jobID, nil
+}
+
+// DeleteRange 删除[startKey, endKey)范围内的所有键，返回后台清理任务ID
+func (s *KVService) DeleteRange(ctx context.Context, startKey, endKey string) (string, error) {
+	if err := s.writable(); err != nil {
+		return "", err
+	}
+
+	start := time.Now()
+	defer func() {
+		s.metrics.DeleteLatency.WithLabelValues("range").Observe(time.Since(start).Seconds())
+	}()
+
+	ns, err := s.namespace(ctx)
+	if err != nil {
+		s.metrics.DeleteErrors.WithLabelValues("namespace_not_found").Inc()
+		return "", err
+	}
+
+	jobID, err := ns.storage.DeleteRange([]byte(startKey), []byte(endKey))
+	if err != nil {
+		s.metrics.DeleteErrors.WithLabelValues(string(storage.ErrorCode(err))).Inc()
+		return "", err
+	}
+
+	// 从缓存中删除
+	s.evictCache(ns, func(key string) bool {
+		return key >= startKey && key < endKey
+	})
+
+	s.metrics.RangeDeletes.Inc()
+	return jobID, nil
+}
+
+// DeleteJob 查询范围删除任务的进度
+func (s *KVService) DeleteJob(ctx context.Context, id string) (*storage.DeleteJob, error) {
+	job, found := s.storage.DeleteJob(id)
+	if !found {
+		return nil, storage.NewError(storage.CodeNotFound, "delete job not found")
+	}
+	return job, nil
+}
+
+// evictCache 删除缓存中满足条件的键
+func (s *KVService) evictCache(ns *nsState, match func(key string) bool) {
+	ns.flights.forgetFunc(match)
+	if !ns.config.Cache.Enabled {
+		return
+	}
+
+	ns.cache.DeleteFunc(match)
+}
+
 // Scan 扫描键值对
 func (s *KVService) Scan(ctx context.Context, prefix string, limit int) (map[string][]byte, error) {
+	batches, err := s.allShards(ctx)
+	if err != nil {
+		return nil, err
+	}
+	if len(batches) > 0 {
+		return s.shardedScan(ctx, prefix, limit, batches)
+	}
+
+	if err := s.linearize(ctx); err != nil {
+		return nil, err
+	}
+
 	start := time.Now()
 	defer func() {
 		s.metrics.ScanLatency.WithLabelValues("kv").Observe(time.Since(start).Seconds())
 	}()
 
+	ns, err := s.namespace(ctx)
+	if err != nil {
+		s.metrics.ScanErrors.WithLabelValues("namespace_not_found").Inc()
+		return nil, err
+	}
+
 	if limit <= 0 || limit > 1000 {
 		limit = 100
 	}
 
-	results, err := s.storage.ScanWithValues([]byte(prefix))
+	results, err := ns.storage.ScanWithValuesContext(ctx, []byte(prefix))
 	if err != nil {
-		s.metrics.ScanErrors.WithLabelValues(err.Error()).Inc()
+		s.metrics.ScanErrors.WithLabelValues(string(storage.ErrorCode(err))).Inc()
+		s.metrics.observeCancelled("scan", err)
 		return nil, err
 	}
 
@@ -147,27 +811,79 @@ func (s *KVService) Scan(ctx context.Context, prefix string, limit int) (map[str
 
 // MSet 批量设置键值对
 func (s *KVService) MSet(ctx context.Context, kvs map[string][]byte, ttl time.Duration) error {
+	keys := make([]string, 0, len(kvs))
+	for key := range kvs {
+		keys = append(keys, key)
+	}
+	if err := checkReserved(keys...); err != nil {
+		s.metrics.MSetErrors.WithLabelValues("reserved_key").Inc()
+		return err
+	}
+	local, batches, done, err := s.splitWrite(ctx, keys)
+	if err != nil {
+		return err
+	}
+	defer done()
+	if len(batches) > 0 {
+		return s.shardedMSet(ctx, kvs, ttl, local, batches)
+	}
+
+	if replicated, err := s.replicate(ctx, &Command{Op: CommandMSet, KVs: kvs, TTL: ttl.Milliseconds()}); replicated {
+		return err
+	}
+	if err := s.writable(); err != nil {
+		return err
+	}
+	return s.mset(ctx, kvs, ttl)
+}
+
+// mset 在本节点批量设置键值对
+func (s *KVService) mset(ctx context.Context, kvs map[string][]byte, ttl time.Duration) error {
 	start := time.Now()
 	defer func() {
 		s.metrics.MSetLatency.WithLabelValues("kv").Observe(time.Since(start).Seconds())
 	}()
 
+	ns, err := s.namespace(ctx)
+	if err != nil {
+		s.metrics.MSetErrors.WithLabelValues("namespace_not_found").Inc()
+		return err
+	}
+	if ttl <= 0 {
+		ttl = ns.defaultTTL
+	}
+
 	if len(kvs) == 0 {
 		s.metrics.MSetErrors.WithLabelValues("empty_kvs").Inc()
-		return errors.New("empty key-value pairs")
+		return storage.NewError(storage.CodeInvalidArgument, "empty key-value pairs")
 	}
 
-	err := s.storage.MSet(kvs)
+	// 启用写回时先标记键，写入成功后由后台写回源站
+	keys := make([]string, 0, len(kvs))
+	for key := range kvs {
+		keys = append(keys, key)
+	}
+	if err := s.markWriteBehind(ns, keys...); err != nil {
+		s.metrics.MSetErrors.WithLabelValues(string(storage.ErrorCode(err))).Inc()
+		return err
+	}
+
+	err = ns.storage.MSetWithTTL(kvs, ttl)
 	if err != nil {
-		s.metrics.MSetErrors.WithLabelValues(err.Error()).Inc()
+		s.metrics.MSetErrors.WithLabelValues(string(storage.ErrorCode(err))).Inc()
 		return err
 	}
 
 	// 批量写入缓存
-	if s.config.Cache.Enabled {
+	for key := range kvs {
+		ns.flights.forget(key)
+	}
+	if ns.config.Cache.Enabled {
 		for key, value := range kvs {
-			if len(value) < s.config.Cache.SizeThreshold {
-				s.cache.Store(key, value)
+			if len(value) < ns.config.Cache.SizeThreshold {
+				ns.cache.Set(key, value, ttl)
+			} else {
+				ns.cache.Delete(key)
 			}
 		}
 	}
@@ -179,24 +895,42 @@ func (s *KVService) MSet(ctx context.Context, kvs map[string][]byte, ttl time.Du
 
 // MGet 批量获取值
 func (s *KVService) MGet(ctx context.Context, keys []string) (map[string][]byte, error) {
+	local, batches, err := s.splitKeys(ctx, keys)
+	if err != nil {
+		return nil, err
+	}
+	if len(batches) > 0 {
+		return s.shardedMGet(ctx, local, batches)
+	}
+
+	if err := s.linearize(ctx); err != nil {
+		return nil, err
+	}
+
 	start := time.Now()
 	defer func() {
 		s.metrics.MGetLatency.WithLabelValues("kv").Observe(time.Since(start).Seconds())
 	}()
 
+	ns, err := s.namespace(ctx)
+	if err != nil {
+		s.metrics.MGetErrors.WithLabelValues("namespace_not_found").Inc()
+		return nil, err
+	}
+
 	if len(keys) == 0 {
 		s.metrics.MGetErrors.WithLabelValues("empty_keys").Inc()
-		return nil, errors.New("empty keys")
+		return nil, storage.NewError(storage.CodeInvalidArgument, "empty keys")
 	}
 
 	results := make(map[string][]byte)
 	missedKeys := make([]string, 0)
 
 	// 优先从缓存中查询
-	if s.config.Cache.Enabled {
+	if ns.config.Cache.Enabled {
 		for _, key := range keys {
-			if cachedValue, ok := s.cache.Load(key); ok {
-				results[key] = cachedValue.([]byte)
+			if cachedValue, ok := ns.cache.Get(key); ok {
+				results[key] = cachedValue
 			} else {
 				missedKeys = append(missedKeys, key)
 			}
@@ -205,27 +939,18 @@ func (s *KVService) MGet(ctx context.Context, keys []string) (map[string][]byte,
 		missedKeys = keys
 	}
 
-	// 从存储中查询缓存未命中的key
+	// 从存储中查询缓存未命中的key，其他Get或MGet正在读取的key等待其结果
 	if len(missedKeys) > 0 {
-		// 转换missedKeys为[][]byte
-		byteKeys := make([][]byte, len(missedKeys))
-		for i, key := range missedKeys {
-			byteKeys[i] = []byte(key)
-		}
-
-		storageResults, err := s.storage.MGet(byteKeys)
+		storageResults, err := s.loadKeys(ctx, ns, missedKeys)
 		if err != nil {
-			s.metrics.MGetErrors.WithLabelValues(err.Error()).Inc()
+			s.metrics.MGetErrors.WithLabelValues(string(storage.ErrorCode(err))).Inc()
+			s.metrics.observeCancelled("mget", err)
 			return nil, err
 		}
 
-		// 合并结果并写入缓存
+		// 合并结果，值小于缓存阈值的键已写入缓存
 		for key, value := range storageResults {
 			results[key] = value
-			// 如果值小于缓存阈值，将其写入缓存
-			if s.config.Cache.Enabled && len(value) < s.config.Cache.SizeThreshold {
-				s.cache.Store(key, value)
-			}
 		}
 	}
 
@@ -235,14 +960,44 @@ func (s *KVService) MGet(ctx context.Context, keys []string) (map[string][]byte,
 
 // MDelete 批量删除键值对
 func (s *KVService) MDelete(ctx context.Context, keys []string) error {
+	if err := checkReserved(keys...); err != nil {
+		s.metrics.MDeleteErrors.WithLabelValues("reserved_key").Inc()
+		return err
+	}
+	local, batches, done, err := s.splitWrite(ctx, keys)
+	if err != nil {
+		return err
+	}
+	defer done()
+	if len(batches) > 0 {
+		return s.shardedMDelete(ctx, local, batches)
+	}
+
+	if replicated, err := s.replicate(ctx, &Command{Op: CommandMDelete, Keys: keys}); replicated {
+		return err
+	}
+	if err := s.writable(); err != nil {
+		return err
+	}
+	return s.mdelete(ctx, keys)
+}
+
+// mdelete 在本节点批量删除键值对
+func (s *KVService) mdelete(ctx context.Context, keys []string) error {
 	start := time.Now()
 	defer func() {
 		s.metrics.MDeleteLatency.WithLabelValues("kv").Observe(time.Since(start).Seconds())
 	}()
 
+	ns, err := s.namespace(ctx)
+	if err != nil {
+		s.metrics.MDeleteErrors.WithLabelValues("namespace_not_found").Inc()
+		return err
+	}
+
 	if len(keys) == 0 {
 		s.metrics.MDeleteErrors.WithLabelValues("empty_keys").Inc()
-		return errors.New("empty keys")
+		return storage.NewError(storage.CodeInvalidArgument, "empty keys")
 	}
 
 	// 转换keys为[][]byte
@@ -251,16 +1006,17 @@ func (s *KVService) MDelete(ctx context.Context, keys []string) error {
 		byteKeys[i] = []byte(key)
 	}
 
-	err := s.storage.MDelete(byteKeys)
+	err = ns.storage.MDelete(byteKeys)
 	if err != nil {
-		s.metrics.MDeleteErrors.WithLabelValues(err.Error()).Inc()
+		s.metrics.MDeleteErrors.WithLabelValues(string(storage.ErrorCode(err))).Inc()
 		return err
 	}
 
 	// 批量从缓存中删除
-	if s.config.Cache.Enabled {
+	ns.flights.forget(keys...)
+	if ns.config.Cache.Enabled {
 		for _, key := range keys {
-			s.cache.Delete(key)
+			ns.cache.Delete(key)
 		}
 	}
 
@@ -281,6 +1037,14 @@ func (s *KVService) GetConfig(ctx context.Context) (*config.Config, error) {
 
 // UpdateConfig 更新配置
 func (s *KVService) UpdateConfig(ctx context.Context, newConfig *config.Config) error {
+	if replicated, err := s.replicate(ctx, &Command{Op: CommandUpdateConfig, Config: newConfig}); replicated {
+		return err
+	}
+	return s.updateConfig(newConfig)
+}
+
+// updateConfig 在本节点更新配置
+func (s *KVService) updateConfig(newConfig *config.Config) error {
 	start := time.Now()
 	defer func() {
 		s.metrics.SetLatency.WithLabelValues("config").Observe(time.Since(start).Seconds())
@@ -294,6 +1058,7 @@ func (s *KVService) UpdateConfig(ctx context.Context, newConfig *config.Config)
 
 	// 更新内存中的配置
 	s.config = newConfig
+	s.refreshNamespaces()
 
 	s.metrics.ConfigUpdates.Inc()
 	return nil
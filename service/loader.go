@@ -0,0 +1,108 @@
+package service
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	"kvcache/storage"
+)
+
+// originConcurrency MGet从源站并发加载的最大键数
+const originConcurrency = 16
+
+// Loader 存储中没有该键或值已被淘汰时从源站加载键
+type Loader interface {
+	// Load 返回键的值和存活时间，源站没有该键时found为false
+	// ttl为0时使用命名空间的默认存活时间，小于0时只返回值不保存
+	Load(ctx context.Context, namespace, key string) (value []byte, ttl time.Duration, found bool, err error)
+}
+
+// loaderHolder 包装Loader以便原子替换
+type loaderHolder struct {
+	Loader
+	writeBehind bool
+}
+
+// SetLoader 设置源站，存储中没有的键和已淘汰的值从源站加载并保存，writeBehind为true时标记Set和MSet写入的键待写回源站
+// 写回由origin.WriteBehind在后台执行
+func (s *KVService) SetLoader(l Loader, writeBehind bool) {
+	s.loader.Store(&loaderHolder{Loader: l, writeBehind: writeBehind})
+}
+
+// markWriteBehind 启用写回时标记键待写回源站，在写入存储前标记，写入失败时写回的是键当前的值
+func (s *KVService) markWriteBehind(ns *nsState, keys ...string) error {
+	holder := s.loader.Load()
+	if holder == nil || !holder.writeBehind {
+		return nil
+	}
+
+	byteKeys := make([][]byte, len(keys))
+	for i, key := range keys {
+		byteKeys[i] = []byte(key)
+	}
+	return ns.storage.MarkWriteBehind(byteKeys...)
+}
+
+// loadOrigin 从源站加载存储中没有的键或已淘汰的值，本节点可写时按存活时间保存，保留键不从源站加载
+func (s *KVService) loadOrigin(ctx context.Context, ns *nsState, key string) ([]byte, bool, error) {
+	holder := s.loader.Load()
+	if holder == nil || storage.IsReservedKey([]byte(key)) {
+		return nil, false, nil
+	}
+
+	value, ttl, found, err := holder.Load(ctx, NamespaceFromContext(ctx), key)
+	if err != nil {
+		s.metrics.OriginLoads.WithLabelValues("error").Inc()
+		return nil, false, err
+	}
+	if !found {
+		s.metrics.OriginLoads.WithLabelValues("not_found").Inc()
+		return nil, false, nil
+	}
+	s.metrics.OriginLoads.WithLabelValues("found").Inc()
+
+	// 从节点和集群模式下不在本地保存，避免与复制的数据不一致
+	if ttl >= 0 && s.writable() == nil {
+		if ttl == 0 {
+			ttl = ns.defaultTTL
+		}
+		// 加载期间键已被写入时保留新写入的值，已淘汰的值被替换
+		if _, err := ns.storage.SetIfAbsent([]byte(key), value, ttl); err != nil {
+			s.metrics.SetErrors.WithLabelValues(string(storage.ErrorCode(err))).Inc()
+		}
+	}
+	return value, true, nil
+}
+
+// loadOriginKeys 并发从源站加载多个键，返回找到的键和加载失败的键
+func (s *KVService) loadOriginKeys(ctx context.Context, ns *nsState, keys []string) (map[string][]byte, map[string]error) {
+	var (
+		mu      sync.Mutex
+		wg      sync.WaitGroup
+		sem     = make(chan struct{}, originConcurrency)
+		results = make(map[string][]byte, len(keys))
+		errs    = make(map[string]error)
+	)
+	for _, key := range keys {
+		wg.Add(1)
+		sem <- struct{}{}
+		go func(key string) {
+			defer func() {
+				<-sem
+				wg.Done()
+			}()
+			value, found, err := s.loadOrigin(ctx, ns, key)
+			mu.Lock()
+			defer mu.Unlock()
+			if err != nil {
+				errs[key] = err
+			}
+			if found {
+				results[key] = value
+			}
+		}(key)
+	}
+	wg.Wait()
+	return results, errs
+}
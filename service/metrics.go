@@ -4,10 +4,13 @@ import (
 	"sync"
 
 	"github.com/prometheus/client_golang/prometheus"
+
+	"kvcache/storage"
 )
 
 var (
 	metricsRegistered sync.Once
+	quotaMetrics      = newQuotaCollector()
 )
 
 // Metrics 监控指标
@@ -20,6 +23,15 @@ type Metrics struct {
 	MSets         prometheus.Counter
 	MGets         prometheus.Counter
 	MDeletes      prometheus.Counter
+	Appends       prometheus.Counter
+	WriteAts      prometheus.Counter
+	Renames       prometheus.Counter
+	Copies        prometheus.Counter
+	RangeDeletes  prometheus.Counter
+	Stats         prometheus.Counter
+	Exists        prometheus.Counter
+	Counts        prometheus.Counter
+	Sizes         prometheus.Counter
 	ConfigUpdates prometheus.Counter
 	HealthChecks  prometheus.Counter
 
@@ -32,6 +44,9 @@ type Metrics struct {
 	MGetErrors    *prometheus.CounterVec
 	MDeleteErrors *prometheus.CounterVec
 
+	// 客户端取消或超过截止时间而中止的操作
+	CancelledOps *prometheus.CounterVec
+
 	// 延迟指标
 	SetLatency         *prometheus.HistogramVec
 	GetLatency         *prometheus.HistogramVec
@@ -42,10 +57,16 @@ type Metrics struct {
 	MDeleteLatency     *prometheus.HistogramVec
 	HealthCheckLatency prometheus.Histogram
 
+	// 读取合并和源站加载
+	StorageLoads   *prometheus.CounterVec
+	CoalescedLoads *prometheus.CounterVec
+	OriginLoads    *prometheus.CounterVec
+
 	// 状态指标
 	Keys        prometheus.Gauge
 	DiskUsage   prometheus.Gauge
 	MemoryUsage prometheus.Gauge
+	Watchers    prometheus.Gauge
 }
 
 // NewMetrics 创建新的监控指标实例
@@ -94,6 +115,60 @@ func NewMetrics() *Metrics {
 			Name:      "mdeletes_total",
 			Help:      "Total number of multi-delete operations",
 		}),
+		Appends: prometheus.NewCounter(prometheus.CounterOpts{
+			Namespace: "cachefs",
+			Subsystem: "kv",
+			Name:      "appends_total",
+			Help:      "Total number of append operations",
+		}),
+		WriteAts: prometheus.NewCounter(prometheus.CounterOpts{
+			Namespace: "cachefs",
+			Subsystem: "kv",
+			Name:      "write_ats_total",
+			Help:      "Total number of partial write operations",
+		}),
+		Renames: prometheus.NewCounter(prometheus.CounterOpts{
+			Namespace: "cachefs",
+			Subsystem: "kv",
+			Name:      "renames_total",
+			Help:      "Total number of rename operations",
+		}),
+		Copies: prometheus.NewCounter(prometheus.CounterOpts{
+			Namespace: "cachefs",
+			Subsystem: "kv",
+			Name:      "copies_total",
+			Help:      "Total number of copy operations",
+		}),
+		RangeDeletes: prometheus.NewCounter(prometheus.CounterOpts{
+			Namespace: "cachefs",
+			Subsystem: "kv",
+			Name:      "range_deletes_total",
+			Help:      "Total number of prefix and range delete operations",
+		}),
+		Stats: prometheus.NewCounter(prometheus.CounterOpts{
+			Namespace: "cachefs",
+			Subsystem: "kv",
+			Name:      "stats_total",
+			Help:      "Total number of key metadata lookups",
+		}),
+		Exists: prometheus.NewCounter(prometheus.CounterOpts{
+			Namespace: "cachefs",
+			Subsystem: "kv",
+			Name:      "exists_total",
+			Help:      "Total number of key existence checks",
+		}),
+		Counts: prometheus.NewCounter(prometheus.CounterOpts{
+			Namespace: "cachefs",
+			Subsystem: "kv",
+			Name:      "counts_total",
+			Help:      "Total number of prefix count operations",
+		}),
+		Sizes: prometheus.NewCounter(prometheus.CounterOpts{
+			Namespace: "cachefs",
+			Subsystem: "kv",
+			Name:      "sizes_total",
+			Help:      "Total number of prefix size operations",
+		}),
 		ConfigUpdates: prometheus.NewCounter(prometheus.CounterOpts{
 			Namespace: "cachefs",
 			Subsystem: "config",
@@ -151,6 +226,14 @@ func NewMetrics() *Metrics {
 			Help:      "Total number of multi-delete operation errors",
 		}, []string{"error"}),
 
+		// 客户端取消或超过截止时间而中止的操作
+		CancelledOps: prometheus.NewCounterVec(prometheus.CounterOpts{
+			Namespace: "cachefs",
+			Subsystem: "kv",
+			Name:      "cancelled_operations_total",
+			Help:      "Total number of operations aborted because the request was canceled or its deadline was exceeded",
+		}, []string{"operation", "reason"}),
+
 		// 延迟指标
 		SetLatency: prometheus.NewHistogramVec(prometheus.HistogramOpts{
 			Namespace: "cachefs",
@@ -209,7 +292,33 @@ func NewMetrics() *Metrics {
 			Buckets:   prometheus.ExponentialBuckets(0.001, 2, 10),
 		}),
 
+		// 读取合并和源站加载
+		StorageLoads: prometheus.NewCounterVec(prometheus.CounterOpts{
+			Namespace: "cachefs",
+			Subsystem: "kv",
+			Name:      "storage_loads_total",
+			Help:      "Total number of keys read from storage after a cache miss",
+		}, []string{"type"}),
+		CoalescedLoads: prometheus.NewCounterVec(prometheus.CounterOpts{
+			Namespace: "cachefs",
+			Subsystem: "kv",
+			Name:      "coalesced_loads_total",
+			Help:      "Total number of cache misses served by an in-flight storage read of the same key",
+		}, []string{"type"}),
+		OriginLoads: prometheus.NewCounterVec(prometheus.CounterOpts{
+			Namespace: "cachefs",
+			Subsystem: "kv",
+			Name:      "origin_loads_total",
+			Help:      "Total number of keys loaded from the origin after a storage miss",
+		}, []string{"result"}),
+
 		// 状态指标
+		Watchers: prometheus.NewGauge(prometheus.GaugeOpts{
+			Namespace: "cachefs",
+			Subsystem: "kv",
+			Name:      "watchers_current",
+			Help:      "Current number of active watch streams",
+		}),
 		Keys: prometheus.NewGauge(prometheus.GaugeOpts{
 			Namespace: "cachefs",
 			Subsystem: "kv",
@@ -240,6 +349,15 @@ func NewMetrics() *Metrics {
 			metrics.MSets,
 			metrics.MGets,
 			metrics.MDeletes,
+			metrics.Appends,
+			metrics.WriteAts,
+			metrics.Renames,
+			metrics.Copies,
+			metrics.RangeDeletes,
+			metrics.Stats,
+			metrics.Exists,
+			metrics.Counts,
+			metrics.Sizes,
 			metrics.ConfigUpdates,
 			metrics.HealthChecks,
 			metrics.SetErrors,
@@ -249,6 +367,7 @@ func NewMetrics() *Metrics {
 			metrics.MSetErrors,
 			metrics.MGetErrors,
 			metrics.MDeleteErrors,
+			metrics.CancelledOps,
 			metrics.SetLatency,
 			metrics.GetLatency,
 			metrics.DeleteLatency,
@@ -257,11 +376,24 @@ func NewMetrics() *Metrics {
 			metrics.MGetLatency,
 			metrics.MDeleteLatency,
 			metrics.HealthCheckLatency,
+			metrics.StorageLoads,
+			metrics.CoalescedLoads,
+			metrics.OriginLoads,
 			metrics.Keys,
 			metrics.DiskUsage,
 			metrics.MemoryUsage,
+			metrics.Watchers,
+			quotaMetrics,
 		)
 	})
 
 	return metrics
 }
+
+// observeCancelled 错误为请求取消或超过截止时间时计入被中止的操作
+func (m *Metrics) observeCancelled(operation string, err error) {
+	switch code := storage.ErrorCode(err); code {
+	case storage.CodeCanceled, storage.CodeDeadlineExceeded:
+		m.CancelledOps.WithLabelValues(operation, string(code)).Inc()
+	}
+}
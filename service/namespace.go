@@ -0,0 +1,171 @@
+package service
+
+import (
+	"context"
+	"time"
+
+	"kvcache/cache"
+	"kvcache/config"
+	"kvcache/storage"
+)
+
+// namespaceKey 上下文中命名空间的键
+type namespaceKey struct{}
+
+// WithNamespace 返回携带命名空间的上下文，后续请求在该命名空间中执行
+func WithNamespace(ctx context.Context, name string) context.Context {
+	return context.WithValue(ctx, namespaceKey{}, name)
+}
+
+// NamespaceFromContext 获取上下文中的命名空间，未指定时返回默认命名空间
+func NamespaceFromContext(ctx context.Context) string {
+	if name, ok := ctx.Value(namespaceKey{}).(string); ok && name != "" {
+		return name
+	}
+	return config.DefaultNamespace
+}
+
+// nsState 命名空间的存储实例、生效配置、内存缓存和进行中的存储读取
+type nsState struct {
+	storage    storage.Storage
+	config     *config.Config
+	defaultTTL time.Duration
+	cache      *cache.Cache
+	flights    *flightGroup
+}
+
+// namespace 获取请求所属命名空间的状态
+func (s *KVService) namespace(ctx context.Context) (*nsState, error) {
+	name := NamespaceFromContext(ctx)
+	if name == config.DefaultNamespace {
+		return &nsState{storage: s.storage, config: s.config, cache: s.cache, flights: s.flights}, nil
+	}
+
+	if state, ok := s.namespaces.Load(name); ok {
+		return state.(*nsState), nil
+	}
+
+	state, err := s.loadNamespace(name, nil, newFlightGroup())
+	if err != nil {
+		return nil, err
+	}
+	actual, _ := s.namespaces.LoadOrStore(name, state)
+	return actual.(*nsState), nil
+}
+
+// newDefaultCache 创建默认命名空间的内存缓存
+func newDefaultCache(cfg *config.Config) *cache.Cache {
+	return cache.New(config.DefaultNamespace, cfg.Cache.MaxBytes)
+}
+
+// loadNamespace 从存储加载命名空间实例和配置，nsCache为nil时创建新的缓存，否则按新配置调整已有缓存
+func (s *KVService) loadNamespace(name string, nsCache *cache.Cache, flights *flightGroup) (*nsState, error) {
+	view, err := s.storage.Namespace(name)
+	if err != nil {
+		return nil, err
+	}
+	nsCfg, err := s.storage.GetNamespace(name)
+	if err != nil {
+		return nil, err
+	}
+
+	cfg := nsCfg.Apply(s.config)
+	if nsCache == nil {
+		nsCache = cache.New(name, cfg.Cache.MaxBytes)
+	} else {
+		resizeCache(nsCache, cfg)
+	}
+
+	return &nsState{
+		storage:    view,
+		config:     cfg,
+		defaultTTL: time.Duration(nsCfg.DefaultTTL) * time.Second,
+		cache:      nsCache,
+		flights:    flights,
+	}, nil
+}
+
+// resizeCache 按配置调整缓存容量，禁用缓存时清空已缓存的值
+func resizeCache(c *cache.Cache, cfg *config.Config) {
+	c.Resize(cfg.Cache.MaxBytes)
+	if !cfg.Cache.Enabled {
+		c.Clear()
+	}
+}
+
+// refreshNamespaces 全局配置变化后重新计算各命名空间生效的配置，保留已有缓存并调整容量
+func (s *KVService) refreshNamespaces() {
+	resizeCache(s.cache, s.config)
+	s.namespaces.Range(func(k, v interface{}) bool {
+		name := k.(string)
+		state, err := s.loadNamespace(name, v.(*nsState).cache, v.(*nsState).flights)
+		if err != nil {
+			v.(*nsState).cache.Close()
+			s.namespaces.Delete(name)
+			return true
+		}
+		s.namespaces.Store(name, state)
+		return true
+	})
+}
+
+// CreateNamespace 创建命名空间
+func (s *KVService) CreateNamespace(ctx context.Context, nsCfg *config.NamespaceConfig) error {
+	if replicated, err := s.replicate(ctx, &Command{Op: CommandCreateNamespace, Namespace: nsCfg.Name, NsConfig: nsCfg}); replicated {
+		return err
+	}
+	if err := s.writable(); err != nil {
+		return err
+	}
+	return s.createNamespace(nsCfg)
+}
+
+// createNamespace 在本节点创建命名空间
+func (s *KVService) createNamespace(nsCfg *config.NamespaceConfig) error {
+	start := time.Now()
+	defer func() {
+		s.metrics.SetLatency.WithLabelValues("namespace").Observe(time.Since(start).Seconds())
+	}()
+
+	if err := s.storage.CreateNamespace(nsCfg); err != nil {
+		s.metrics.SetErrors.WithLabelValues(string(storage.ErrorCode(err))).Inc()
+		return err
+	}
+
+	return nil
+}
+
+// DropNamespace 删除命名空间及其全部数据
+func (s *KVService) DropNamespace(ctx context.Context, name string) error {
+	if replicated, err := s.replicate(ctx, &Command{Op: CommandDropNamespace, Namespace: name}); replicated {
+		return err
+	}
+	if err := s.writable(); err != nil {
+		return err
+	}
+	return s.dropNamespace(name)
+}
+
+// dropNamespace 在本节点删除命名空间及其全部数据
+func (s *KVService) dropNamespace(name string) error {
+	start := time.Now()
+	defer func() {
+		s.metrics.DeleteLatency.WithLabelValues("namespace").Observe(time.Since(start).Seconds())
+	}()
+
+	if err := s.storage.DropNamespace(name); err != nil {
+		s.metrics.DeleteErrors.WithLabelValues(string(storage.ErrorCode(err))).Inc()
+		return err
+	}
+
+	// 丢弃命名空间的缓存
+	if state, ok := s.namespaces.LoadAndDelete(name); ok {
+		state.(*nsState).cache.Close()
+	}
+	return nil
+}
+
+// ListNamespaces 列出所有命名空间
+func (s *KVService) ListNamespaces(ctx context.Context) ([]*config.NamespaceConfig, error) {
+	return s.storage.ListNamespaces()
+}
@@ -0,0 +1,138 @@
+package service
+
+import (
+	"context"
+	"sync/atomic"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+
+	"kvcache/config"
+	"kvcache/storage"
+)
+
+// SetQuota 设置当前命名空间内的配额，前缀为空时限制整个命名空间
+func (s *KVService) SetQuota(ctx context.Context, qc *config.QuotaConfig) error {
+	if err := s.writable(); err != nil {
+		return err
+	}
+
+	start := time.Now()
+	defer func() {
+		s.metrics.SetLatency.WithLabelValues("quota").Observe(time.Since(start).Seconds())
+	}()
+
+	ns, err := s.namespace(ctx)
+	if err != nil {
+		s.metrics.SetErrors.WithLabelValues("namespace_not_found").Inc()
+		return err
+	}
+
+	if err := ns.storage.SetQuota(qc); err != nil {
+		s.metrics.SetErrors.WithLabelValues(string(storage.ErrorCode(err))).Inc()
+		return err
+	}
+
+	return nil
+}
+
+// DeleteQuota 删除当前命名空间内的配额
+func (s *KVService) DeleteQuota(ctx context.Context, prefix string) error {
+	if err := s.writable(); err != nil {
+		return err
+	}
+
+	start := time.Now()
+	defer func() {
+		s.metrics.DeleteLatency.WithLabelValues("quota").Observe(time.Since(start).Seconds())
+	}()
+
+	ns, err := s.namespace(ctx)
+	if err != nil {
+		s.metrics.DeleteErrors.WithLabelValues("namespace_not_found").Inc()
+		return err
+	}
+
+	if err := ns.storage.DeleteQuota(prefix); err != nil {
+		s.metrics.DeleteErrors.WithLabelValues(string(storage.ErrorCode(err))).Inc()
+		return err
+	}
+
+	return nil
+}
+
+// ListQuotas 列出当前命名空间的配额及用量
+func (s *KVService) ListQuotas(ctx context.Context) ([]*storage.QuotaStatus, error) {
+	ns, err := s.namespace(ctx)
+	if err != nil {
+		return nil, err
+	}
+	return ns.storage.ListQuotas()
+}
+
+// allQuotas 列出所有命名空间的配额及用量
+func (s *KVService) allQuotas() []*storage.QuotaStatus {
+	namespaces, err := s.storage.ListNamespaces()
+	if err != nil {
+		return nil
+	}
+
+	var statuses []*storage.QuotaStatus
+	for _, nsCfg := range namespaces {
+		view, err := s.storage.Namespace(nsCfg.Name)
+		if err != nil {
+			continue
+		}
+		quotas, err := view.ListQuotas()
+		if err != nil {
+			continue
+		}
+		statuses = append(statuses, quotas...)
+	}
+	return statuses
+}
+
+// quotaCollector 在抓取时读取配额用量和限制，数据来自最近创建的服务实例
+type quotaCollector struct {
+	usage  *prometheus.Desc
+	limit  *prometheus.Desc
+	source atomic.Pointer[KVService]
+}
+
+// newQuotaCollector 创建配额指标采集器
+func newQuotaCollector() *quotaCollector {
+	labels := []string{"namespace", "prefix", "resource"}
+	return &quotaCollector{
+		usage: prometheus.NewDesc("cachefs_kv_quota_usage", "Current usage of a quota", labels, nil),
+		limit: prometheus.NewDesc("cachefs_kv_quota_limit", "Limit of a quota, 0 means unlimited", labels, nil),
+	}
+}
+
+// Describe 实现prometheus.Collector
+func (c *quotaCollector) Describe(ch chan<- *prometheus.Desc) {
+	ch <- c.usage
+	ch <- c.limit
+}
+
+// Collect 实现prometheus.Collector
+func (c *quotaCollector) Collect(ch chan<- prometheus.Metric) {
+	s := c.source.Load()
+	if s == nil {
+		return
+	}
+
+	for _, status := range s.allQuotas() {
+		resources := []struct {
+			name         string
+			usage, limit int64
+		}{
+			{"keys", status.Usage.Keys, status.MaxKeys},
+			{"inline_bytes", status.Usage.InlineBytes, status.MaxInlineBytes},
+			{"disk_bytes", status.Usage.DiskBytes, status.MaxDiskBytes},
+		}
+		for _, r := range resources {
+			ch <- prometheus.MustNewConstMetric(c.usage, prometheus.GaugeValue, float64(r.usage), status.Namespace, status.Prefix, r.name)
+			ch <- prometheus.MustNewConstMetric(c.limit, prometheus.GaugeValue, float64(r.limit), status.Namespace, status.Prefix, r.name)
+		}
+	}
+}
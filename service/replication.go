@@ -0,0 +1,91 @@
+package service
+
+import (
+	"context"
+
+	"kvcache/storage"
+)
+
+const (
+	// RoleLeader 主节点，接受写入
+	RoleLeader = "leader"
+	// RoleFollower 从节点，只读并从主节点复制变更
+	RoleFollower = "follower"
+)
+
+// ReplicationStatus 复制状态
+type ReplicationStatus struct {
+	Role            string  `json:"role"`
+	Leader          string  `json:"leader,omitempty"`
+	Connected       bool    `json:"connected"`
+	AppliedRevision uint64  `json:"applied_revision"`
+	LeaderRevision  uint64  `json:"leader_revision,omitempty"`
+	LagSeconds      float64 `json:"lag_seconds"`
+	LastError       string  `json:"last_error,omitempty"`
+}
+
+// Replicator 从节点的复制进程
+type Replicator interface {
+	Status() ReplicationStatus
+	Promote() error
+}
+
+// replicatorHolder 包装Replicator以便原子替换
+type replicatorHolder struct {
+	Replicator
+}
+
+// SetReplicator 设置从节点的复制进程，用于查询状态和手动提升
+func (s *KVService) SetReplicator(r Replicator) {
+	s.replicator.Store(&replicatorHolder{r})
+}
+
+// writable 从节点拒绝客户端写入，集群模式下拒绝不经过复制日志的写入
+func (s *KVService) writable() error {
+	if s.storage.IsFollower() {
+		return storage.ErrReadOnly
+	}
+	if s.cluster.Load() != nil {
+		return ErrNotReplicated
+	}
+	return nil
+}
+
+// ReplicationStatus 返回当前节点的复制状态
+func (s *KVService) ReplicationStatus(ctx context.Context) ReplicationStatus {
+	if holder := s.replicator.Load(); holder != nil {
+		return holder.Status()
+	}
+	return ReplicationStatus{
+		Role:            RoleLeader,
+		Connected:       true,
+		AppliedRevision: s.storage.LatestRevision(),
+	}
+}
+
+// Promote 将从节点提升为主节点
+func (s *KVService) Promote(ctx context.Context) error {
+	holder := s.replicator.Load()
+	if holder == nil || !s.storage.IsFollower() {
+		return storage.NewError(storage.CodeConflict, "node is not a follower")
+	}
+	return holder.Promote()
+}
+
+// InvalidateChange 使复制到本节点的变更对应的缓存失效
+func (s *KVService) InvalidateChange(event storage.ChangeEvent) {
+	ns, err := s.namespace(WithNamespace(context.Background(), event.Namespace))
+	if err != nil {
+		return
+	}
+
+	if event.Type != storage.EventDeleteRange {
+		ns.flights.forget(string(event.Key))
+		ns.cache.Delete(string(event.Key))
+		return
+	}
+	start, end := string(event.Key), string(event.End)
+	s.evictCache(ns, func(key string) bool {
+		return key >= start && (event.End == nil || key < end)
+	})
+}
@@ -2,10 +2,17 @@ package service
 
 import (
 	"context"
+	"errors"
+	"io"
+	"net/http"
+	"net/http/httptest"
 	"os"
+	"sync/atomic"
 	"testing"
+	"time"
 
 	"kvcache/config"
+	"kvcache/origin"
 	"kvcache/storage"
 )
 
@@ -276,6 +283,61 @@ func TestKVServiceScan(t *testing.T) {
 	}
 }
 
+// TestKVServiceReservedKeys 测试保留键不能写入，扫描和计数不返回保留键和内部记录
+func TestKVServiceReservedKeys(t *testing.T) {
+	// 初始化配置
+	cfg := config.DefaultConfig()
+
+	// 删除现有的数据目录，确保测试环境干净
+	os.RemoveAll(cfg.RocksDB.Path)
+	os.RemoveAll(cfg.Value.DiskPath)
+
+	// 创建存储实例
+	store, err := storage.NewStorage(cfg)
+	if err != nil {
+		t.Fatalf("Failed to create storage: %v", err)
+	}
+	defer store.Stop()
+
+	// 创建KV服务实例
+	service := NewKVService(store, cfg)
+	ctx := context.Background()
+	reserved := storage.ReservedKeyPrefix + "internal"
+
+	// 1. 写入保留键被拒绝
+	writes := map[string]error{
+		"set":    service.Set(ctx, reserved, []byte("value"), 0),
+		"mset":   service.MSet(ctx, map[string][]byte{"user": []byte("value"), reserved: []byte("value")}, 0),
+		"append": service.Append(ctx, reserved, []byte("value")),
+		"rename": service.Rename(ctx, "user", reserved, true),
+		"copy":   service.Copy(ctx, "user", reserved),
+		"delete": service.Delete(ctx, reserved),
+	}
+	for op, err := range writes {
+		if !errors.Is(err, storage.ErrReservedKey) {
+			t.Errorf("%s: expected reserved key error, got %v", op, err)
+		}
+	}
+
+	// 2. 与配置键同名的键是普通的用户键，存储层写入的保留键不会被扫描返回
+	if err := service.Set(ctx, config.ConfigKey, []byte("user value"), 0); err != nil {
+		t.Fatalf("Failed to set value: %v", err)
+	}
+	if err := store.Set([]byte(reserved), []byte("internal")); err != nil {
+		t.Fatalf("Failed to set reserved key in storage: %v", err)
+	}
+	results, err := service.Scan(ctx, "", 100)
+	if err != nil {
+		t.Fatalf("Failed to scan: %v", err)
+	}
+	if len(results) != 1 || string(results[config.ConfigKey]) != "user value" {
+		t.Errorf("Expected only the user key in scan results, got %v", results)
+	}
+	if count, err := service.CountPrefix(ctx, "", true); err != nil || count != 1 {
+		t.Errorf("Expected count 1, got %d: %v", count, err)
+	}
+}
+
 // TestKVServiceConfig 测试KV服务的配置管理功能
 func TestKVServiceConfig(t *testing.T) {
 	// 初始化配置
@@ -322,6 +384,16 @@ func TestKVServiceConfig(t *testing.T) {
 	if updatedCfg == nil {
 		t.Fatalf("Expected updated config to be non-nil, got nil")
 	}
+
+	// 测试运行时调整缓存容量
+	newCfg = config.DefaultConfig()
+	newCfg.Cache.MaxBytes = 1 << 20
+	if err := service.UpdateConfig(context.Background(), newCfg); err != nil {
+		t.Fatalf("Failed to update cache size: %v", err)
+	}
+	if service.cache.MaxBytes() != 1<<20 {
+		t.Errorf("Expected cache resized to 1MB, got %d", service.cache.MaxBytes())
+	}
 }
 
 // TestKVServiceHealthCheck 测试KV服务的健康检查功能
@@ -471,3 +543,422 @@ func TestMetricsInitialization(t *testing.T) {
 		t.Fatalf("Expected MemoryUsage gauge to be non-nil, got nil")
 	}
 }
+
+// TestKVServiceAppend 测试KV服务的追加和局部写入功能
+func TestKVServiceAppend(t *testing.T) {
+	// 初始化配置
+	cfg := config.DefaultConfig()
+
+	// 删除现有的数据目录，确保测试环境干净
+	os.RemoveAll(cfg.RocksDB.Path)
+	os.RemoveAll(cfg.Value.DiskPath)
+
+	// 创建存储实例
+	store, err := storage.NewStorage(cfg)
+	if err != nil {
+		t.Fatalf("Failed to create storage: %v", err)
+	}
+	defer store.Stop()
+
+	// 创建KV服务实例
+	service := NewKVService(store, cfg)
+
+	testKey := "append-key"
+
+	// 先设置并读取一次，使值进入缓存
+	if err := service.Set(context.Background(), testKey, []byte("chunk-1;"), 0); err != nil {
+		t.Fatalf("Failed to set value: %v", err)
+	}
+	if _, err := service.Get(context.Background(), testKey); err != nil {
+		t.Fatalf("Failed to get value: %v", err)
+	}
+
+	// 追加后缓存应失效，读取到最新值
+	if err := service.Append(context.Background(), testKey, []byte("chunk-2;")); err != nil {
+		t.Fatalf("Failed to append value: %v", err)
+	}
+
+	value, err := service.Get(context.Background(), testKey)
+	if err != nil {
+		t.Fatalf("Failed to get value: %v", err)
+	}
+	if string(value) != "chunk-1;chunk-2;" {
+		t.Errorf("Expected value to be 'chunk-1;chunk-2;', got '%s'", string(value))
+	}
+
+	// 局部写入
+	if err := service.WriteAt(context.Background(), testKey, 6, []byte("#")); err != nil {
+		t.Fatalf("Failed to write at offset: %v", err)
+	}
+
+	value, err = service.Get(context.Background(), testKey)
+	if err != nil {
+		t.Fatalf("Failed to get value: %v", err)
+	}
+	if string(value) != "chunk-#;chunk-2;" {
+		t.Errorf("Expected value to be 'chunk-#;chunk-2;', got '%s'", string(value))
+	}
+
+	// 负偏移量应返回错误
+	if err := service.WriteAt(context.Background(), testKey, -1, []byte("x")); err == nil {
+		t.Errorf("Expected error for negative offset, got nil")
+	}
+}
+
+// TestKVServiceStat 测试KV服务的元数据查询功能
+func TestKVServiceStat(t *testing.T) {
+	// 初始化配置
+	cfg := config.DefaultConfig()
+
+	// 删除现有的数据目录，确保测试环境干净
+	os.RemoveAll(cfg.RocksDB.Path)
+	os.RemoveAll(cfg.Value.DiskPath)
+
+	// 创建存储实例
+	store, err := storage.NewStorage(cfg)
+	if err != nil {
+		t.Fatalf("Failed to create storage: %v", err)
+	}
+	defer store.Stop()
+
+	// 创建KV服务实例
+	service := NewKVService(store, cfg)
+
+	if err := service.Set(context.Background(), "stat-key", []byte("stat-value"), 0); err != nil {
+		t.Fatalf("Failed to set value: %v", err)
+	}
+
+	info, err := service.Stat(context.Background(), "stat-key")
+	if err != nil {
+		t.Fatalf("Failed to stat key: %v", err)
+	}
+	if info.Size != int64(len("stat-value")) {
+		t.Errorf("Expected size %d, got %d", len("stat-value"), info.Size)
+	}
+	if info.Version != 1 {
+		t.Errorf("Expected version 1, got %d", info.Version)
+	}
+
+	// 不存在的键应返回错误
+	if _, err := service.Stat(context.Background(), "stat-missing"); err == nil {
+		t.Errorf("Expected error for missing key, got nil")
+	}
+}
+
+func TestKVServiceExistsCount(t *testing.T) {
+	// 初始化配置
+	cfg := config.DefaultConfig()
+
+	// 删除现有的数据目录，确保测试环境干净
+	os.RemoveAll(cfg.RocksDB.Path)
+	os.RemoveAll(cfg.Value.DiskPath)
+
+	// 创建存储实例
+	store, err := storage.NewStorage(cfg)
+	if err != nil {
+		t.Fatalf("Failed to create storage: %v", err)
+	}
+	defer store.Stop()
+
+	// 创建KV服务实例
+	service := NewKVService(store, cfg)
+
+	kvs := map[string][]byte{
+		"exists:1": []byte("one"),
+		"exists:2": []byte("two"),
+	}
+	if err := service.MSet(context.Background(), kvs, 0); err != nil {
+		t.Fatalf("Failed to mset: %v", err)
+	}
+
+	exists, err := service.Exists(context.Background(), "exists:1")
+	if err != nil {
+		t.Fatalf("Failed to check key: %v", err)
+	}
+	if !exists {
+		t.Errorf("Expected key 'exists:1' to exist")
+	}
+
+	results, err := service.MExists(context.Background(), []string{"exists:2", "exists:3"})
+	if err != nil {
+		t.Fatalf("Failed to mexists: %v", err)
+	}
+	if !results["exists:2"] || results["exists:3"] {
+		t.Errorf("Unexpected mexists results: %v", results)
+	}
+
+	count, err := service.CountPrefix(context.Background(), "exists:", true)
+	if err != nil {
+		t.Fatalf("Failed to count prefix: %v", err)
+	}
+	if count != 2 {
+		t.Errorf("Expected count 2, got %d", count)
+	}
+
+	size, err := service.SizeOf(context.Background(), "exists:")
+	if err != nil {
+		t.Fatalf("Failed to get prefix size: %v", err)
+	}
+	if size.TotalBytes() != 6 {
+		t.Errorf("Expected 6 total bytes, got %d", size.TotalBytes())
+	}
+}
+
+func TestKVServiceRenameCopy(t *testing.T) {
+	// 初始化配置
+	cfg := config.DefaultConfig()
+
+	// 删除现有的数据目录，确保测试环境干净
+	os.RemoveAll(cfg.RocksDB.Path)
+	os.RemoveAll(cfg.Value.DiskPath)
+
+	// 创建存储实例
+	store, err := storage.NewStorage(cfg)
+	if err != nil {
+		t.Fatalf("Failed to create storage: %v", err)
+	}
+	defer store.Stop()
+
+	// 创建KV服务实例
+	service := NewKVService(store, cfg)
+
+	if err := service.Set(context.Background(), "rename-key", []byte("rename-value"), 0); err != nil {
+		t.Fatalf("Failed to set value: %v", err)
+	}
+	// 读取一次使值进入缓存
+	if _, err := service.Get(context.Background(), "rename-key"); err != nil {
+		t.Fatalf("Failed to get value: %v", err)
+	}
+
+	if err := service.Rename(context.Background(), "rename-key", "renamed-key", false); err != nil {
+		t.Fatalf("Failed to rename: %v", err)
+	}
+	if _, err := service.Get(context.Background(), "rename-key"); err == nil {
+		t.Errorf("Expected source key to be gone from cache and storage")
+	}
+
+	if err := service.Copy(context.Background(), "renamed-key", "copied-key"); err != nil {
+		t.Fatalf("Failed to copy: %v", err)
+	}
+	value, err := service.Get(context.Background(), "copied-key")
+	if err != nil {
+		t.Fatalf("Failed to get copied value: %v", err)
+	}
+	if string(value) != "rename-value" {
+		t.Errorf("Expected value 'rename-value', got '%s'", value)
+	}
+}
+
+func TestKVServiceDeleteRange(t *testing.T) {
+	// 初始化配置
+	cfg := config.DefaultConfig()
+
+	// 删除现有的数据目录，确保测试环境干净
+	os.RemoveAll(cfg.RocksDB.Path)
+	os.RemoveAll(cfg.Value.DiskPath)
+
+	// 创建存储实例
+	store, err := storage.NewStorage(cfg)
+	if err != nil {
+		t.Fatalf("Failed to create storage: %v", err)
+	}
+	defer store.Stop()
+
+	// 创建KV服务实例
+	service := NewKVService(store, cfg)
+
+	kvs := map[string][]byte{
+		"job:1": []byte("one"),
+		"job:2": []byte("two"),
+		"job:3": []byte("three"),
+	}
+	if err := service.MSet(context.Background(), kvs, 0); err != nil {
+		t.Fatalf("Failed to mset: %v", err)
+	}
+
+	jobID, err := service.DeleteRange(context.Background(), "job:1", "job:3")
+	if err != nil {
+		t.Fatalf("Failed to delete range: %v", err)
+	}
+	if _, err := service.DeleteJob(context.Background(), jobID); err != nil {
+		t.Errorf("Failed to get delete job: %v", err)
+	}
+
+	// 范围内的键从缓存和存储中删除，范围外的键保留
+	if _, err := service.Get(context.Background(), "job:2"); err == nil {
+		t.Errorf("Expected key 'job:2' to be deleted")
+	}
+	value, err := service.Get(context.Background(), "job:3")
+	if err != nil {
+		t.Fatalf("Failed to get value: %v", err)
+	}
+	if string(value) != "three" {
+		t.Errorf("Expected value 'three', got '%s'", value)
+	}
+}
+
+func TestKVServiceNamespaces(t *testing.T) {
+	// 初始化配置
+	cfg := config.DefaultConfig()
+
+	// 删除现有的数据目录，确保测试环境干净
+	os.RemoveAll(cfg.RocksDB.Path)
+	os.RemoveAll(cfg.Value.DiskPath)
+
+	// 创建存储实例
+	store, err := storage.NewStorage(cfg)
+	if err != nil {
+		t.Fatalf("Failed to create storage: %v", err)
+	}
+	defer store.Stop()
+
+	// 创建KV服务实例
+	service := NewKVService(store, cfg)
+
+	if err := service.CreateNamespace(context.Background(), &config.NamespaceConfig{Name: "svc-ns"}); err != nil {
+		t.Fatalf("Failed to create namespace: %v", err)
+	}
+	nsCtx := WithNamespace(context.Background(), "svc-ns")
+
+	if err := service.Set(nsCtx, "key", []byte("namespaced"), 0); err != nil {
+		t.Fatalf("Failed to set value: %v", err)
+	}
+	if _, err := service.Get(context.Background(), "key"); err == nil {
+		t.Errorf("Expected key to be invisible in the default namespace")
+	}
+	value, err := service.Get(nsCtx, "key")
+	if err != nil || string(value) != "namespaced" {
+		t.Errorf("Expected 'namespaced', got '%s' (err=%v)", value, err)
+	}
+
+	// 删除后缓存一并丢弃，再次访问返回错误
+	if err := service.DropNamespace(context.Background(), "svc-ns"); err != nil {
+		t.Fatalf("Failed to drop namespace: %v", err)
+	}
+	if _, err := service.Get(nsCtx, "key"); err == nil {
+		t.Errorf("Expected error when reading from a dropped namespace")
+	}
+}
+
+// TestFlightGroup 测试并发读取同一键时只有一个调用读取，键被写入后结果不再写入缓存
+func TestFlightGroup(t *testing.T) {
+	g := newFlightGroup()
+
+	// 1. 第一次读取负责a和b，第二次读取等待a并负责c
+	leading, _ := g.join([]string{"a", "b", "a"})
+	if len(leading) != 2 {
+		t.Fatalf("Expected to lead 2 keys, got %d", len(leading))
+	}
+	lead2, waiting := g.join([]string{"a", "c"})
+	if _, ok := waiting["a"]; !ok || len(lead2) != 1 {
+		t.Fatalf("Expected to wait for a and lead c, got leading=%d waiting=%d", len(lead2), len(waiting))
+	}
+
+	// 2. 读取完成后等待者得到相同的结果
+	published := false
+	leading["a"].value, leading["a"].found = []byte("1"), true
+	g.finish("a", leading["a"], func() { published = true })
+	if err := waiting["a"].wait(context.Background()); err != nil || string(waiting["a"].value) != "1" {
+		t.Errorf("Expected coalesced value 1, got %q: %v", waiting["a"].value, err)
+	}
+	if !published {
+		t.Errorf("Expected result of a published")
+	}
+
+	// 3. 读取期间键被写入，结果不再发布，之后的读取重新访问存储
+	g.forget("b")
+	g.finish("b", leading["b"], func() { t.Errorf("Expected stale result of b not published") })
+	if again, _ := g.join([]string{"b"}); len(again) != 1 {
+		t.Errorf("Expected a new read of b after forget")
+	}
+	g.forgetFunc(func(key string) bool { return key == "c" })
+	g.finish("c", lead2["c"], func() { t.Errorf("Expected stale result of c not published") })
+}
+
+// TestCommandRemainingTTL 测试复制日志中的写命令按领导者确定的绝对过期时间执行
+func TestCommandRemainingTTL(t *testing.T) {
+	now := time.Now()
+
+	// 1. 各节点在不同时刻执行得到同一个过期时间
+	cmd := &Command{Op: CommandSet, TTL: 60000, ExpiresAt: now.Add(time.Minute).UnixMilli()}
+	ttl, expired := cmd.remainingTTL(now.Add(20 * time.Second))
+	if expired || ttl > 40*time.Second || ttl < 39*time.Second {
+		t.Errorf("Expected about 40s remaining, got %v (expired=%v)", ttl, expired)
+	}
+
+	// 2. 重放时已过期的写入
+	if _, expired := cmd.remainingTTL(now.Add(2 * time.Minute)); !expired {
+		t.Errorf("Expected replayed command to be expired")
+	}
+
+	// 3. 没有绝对过期时间的旧日志按相对TTL执行
+	legacy := &Command{Op: CommandSet, TTL: 5000}
+	if ttl, expired := legacy.remainingTTL(now); expired || ttl != 5*time.Second {
+		t.Errorf("Expected legacy command to keep its relative ttl, got %v (expired=%v)", ttl, expired)
+	}
+}
+
+// TestKVServiceLoader 测试缓存和存储未命中时从源站加载并保存，启用写回时标记写入的键
+func TestKVServiceLoader(t *testing.T) {
+	// 初始化配置
+	cfg := config.DefaultConfig()
+
+	// 删除现有的数据目录，确保测试环境干净
+	os.RemoveAll(cfg.RocksDB.Path)
+	os.RemoveAll(cfg.Value.DiskPath)
+
+	// 创建存储实例
+	store, err := storage.NewStorage(cfg)
+	if err != nil {
+		t.Fatalf("Failed to create storage: %v", err)
+	}
+	defer store.Stop()
+
+	// 源站记录收到的请求数
+	var requests atomic.Int32
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		requests.Add(1)
+		if r.URL.Path == "/missing" {
+			http.NotFound(w, r)
+			return
+		}
+		io.WriteString(w, "origin"+r.URL.Path)
+	}))
+	defer ts.Close()
+
+	service := NewKVService(store, cfg)
+	service.SetLoader(origin.NewHTTPOrigin(&config.OriginConfig{URL: ts.URL, Timeout: 1000}), true)
+	ctx := context.Background()
+
+	// 1. 未命中时从源站加载，之后从本地读取
+	for i := 0; i < 2; i++ {
+		value, err := service.Get(ctx, "a")
+		if err != nil || string(value) != "origin/a" {
+			t.Fatalf("Expected origin/a, got %q: %v", value, err)
+		}
+	}
+	if requests.Load() != 1 {
+		t.Errorf("Expected 1 origin request, got %d", requests.Load())
+	}
+	if _, err := service.Get(ctx, "missing"); err == nil {
+		t.Errorf("Expected key not found when origin has no value")
+	}
+
+	// 2. MGet只从源站加载本地没有的键
+	results, err := service.MGet(ctx, []string{"a", "b", "missing"})
+	if err != nil {
+		t.Fatalf("Failed to mget: %v", err)
+	}
+	if len(results) != 2 || string(results["b"]) != "origin/b" {
+		t.Errorf("Expected a and b loaded, got %v", results)
+	}
+
+	// 3. 从源站加载的值不写回，Set写入的键等待写回
+	if err := service.Set(ctx, "c", []byte("local"), 0); err != nil {
+		t.Fatalf("Failed to set value: %v", err)
+	}
+	pending, err := store.PendingWriteBehind(10)
+	if err != nil || len(pending) != 1 || string(pending[0].Key) != "c" {
+		t.Errorf("Expected only c pending write-behind, got %v: %v", pending, err)
+	}
+}
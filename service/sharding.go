@@ -0,0 +1,559 @@
+package service
+
+import (
+	"context"
+	"fmt"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"kvcache/storage"
+)
+
+var (
+	// ErrCrossShard 操作涉及的键属于不同的节点
+	ErrCrossShard = storage.NewError(storage.CodeInvalidArgument, "keys belong to different shards")
+	// ErrShardingDisabled 未启用服务端分片
+	ErrShardingDisabled = storage.NewError(storage.CodeUnimplemented, "sharding is not enabled")
+)
+
+// maxForwards 请求最多被转发的次数，达到后只在本节点处理，防止分片表不一致时来回转发
+const maxForwards = 2
+
+// RedirectError 键不属于本节点且分片模式为redirect时返回，客户端应将请求发往Addr
+type RedirectError struct {
+	Addr string
+}
+
+func (e *RedirectError) Error() string {
+	return "MOVED " + e.Addr
+}
+
+// Shard 远程节点上的键值操作，由分片路由通过gRPC实现
+type Shard interface {
+	Set(ctx context.Context, key string, value []byte, ttl time.Duration) error
+	Get(ctx context.Context, key string) ([]byte, error)
+	Stat(ctx context.Context, key string) (*storage.KeyInfo, error)
+	Exists(ctx context.Context, key string) (bool, error)
+	Delete(ctx context.Context, key string) error
+	Append(ctx context.Context, key string, data []byte) error
+	WriteAt(ctx context.Context, key string, offset int64, data []byte) error
+	Rename(ctx context.Context, src, dst string, overwrite bool) error
+	Copy(ctx context.Context, src, dst string) error
+	MSet(ctx context.Context, kvs map[string][]byte, ttl time.Duration) error
+	MGet(ctx context.Context, keys []string) (map[string][]byte, error)
+	MDelete(ctx context.Context, keys []string) error
+	MExists(ctx context.Context, keys []string) (map[string]bool, error)
+	CountPrefix(ctx context.Context, prefix string, exact bool) (int64, error)
+	SizeOf(ctx context.Context, prefix string) (*storage.PrefixSize, error)
+	Scan(ctx context.Context, prefix string, limit int) (map[string][]byte, error)
+}
+
+// ShardingStatus 分片状态
+type ShardingStatus struct {
+	Self  string   `json:"self"`
+	Mode  string   `json:"mode"`
+	Nodes []string `json:"nodes"`
+	Next  []string `json:"next,omitempty"`  // 迁移中的目标分片表
+	Moved []string `json:"moved,omitempty"` // 已将键交接给目标分片表的节点
+	Owner string   `json:"owner,omitempty"` // 查询的键所属的节点
+}
+
+// 迁移阶段
+const (
+	MigrationIdle       = "idle"
+	MigrationCopying    = "copying"     // 复制迁出的键
+	MigrationCatchingUp = "catching_up" // 追赶复制期间的变更
+	MigrationHandoff    = "handoff"     // 暂停写入并交接所有权
+	MigrationCleanup    = "cleanup"     // 删除本节点已交接的键
+	MigrationCompleted  = "completed"
+	MigrationFailed     = "failed"
+	MigrationCancelled  = "cancelled"
+)
+
+// MigrationStatus 本节点的迁移进度
+type MigrationStatus struct {
+	State      string    `json:"state"`
+	Nodes      []string  `json:"nodes,omitempty"`    // 目标分片表
+	Rate       int64     `json:"rate"`               // 每秒传输的字节数上限，0表示不限速
+	TotalKeys  int64     `json:"total_keys"`         // 开始时需要迁出的键数量
+	MovedKeys  int64     `json:"moved_keys"`         // 已发送的键数量，包括追赶时重新发送的键
+	MovedBytes int64     `json:"moved_bytes"`        // 已发送的值字节数
+	Revision   uint64    `json:"revision,omitempty"` // 已追赶到的变更修订号
+	StartedAt  time.Time `json:"started_at"`
+	FinishedAt time.Time `json:"finished_at"`
+	LastError  string    `json:"last_error,omitempty"`
+}
+
+// Migrator 分片迁移，将本节点在新分片表中不再拥有的键迁移到新的所属节点
+type Migrator interface {
+	// Start 开始迁移到nodes，rate为每秒传输的字节数上限
+	Start(nodes []string, rate int64) error
+	Status() *MigrationStatus
+	// SetRate 调整正在进行的迁移的速率上限
+	SetRate(rate int64) error
+	// Cancel 取消尚未交接所有权的迁移
+	Cancel() error
+}
+
+// migratorHolder 包装Migrator以便原子替换
+type migratorHolder struct {
+	Migrator
+}
+
+// Router 分片路由，决定键所属的节点
+type Router interface {
+	// Owner 返回键所属节点的地址，以及该节点是否为本节点
+	Owner(key string) (string, bool)
+	// Migrated 判断键是否已从本节点迁出，其他节点转发来的这些键的请求会继续转发
+	Migrated(key string) bool
+	// Hold 开始一次本节点的写入，返回的函数在写入完成后调用，交接所有权前等待进行中的写入完成
+	Hold() func()
+	// Shard 返回远程节点的操作接口
+	Shard(addr string) (Shard, error)
+	// Remotes 返回除本节点外的所有节点
+	Remotes() []string
+	// Redirect 是否返回重定向错误而不是转发单键请求
+	Redirect() bool
+	Status() *ShardingStatus
+}
+
+// routerHolder 包装Router以便原子替换
+type routerHolder struct {
+	Router
+}
+
+// forwardedKey 请求已被其他节点转发次数的上下文键
+type forwardedKey struct{}
+
+// WithForwarded 标记请求已被其他节点转发hops次
+// 转发来的请求在本节点处理，只有已迁出本节点的键会再转发一次
+func WithForwarded(ctx context.Context, hops int) context.Context {
+	return context.WithValue(ctx, forwardedKey{}, hops)
+}
+
+// Forwards 返回请求已被转发的次数
+func Forwards(ctx context.Context) int {
+	hops, _ := ctx.Value(forwardedKey{}).(int)
+	return hops
+}
+
+// localContext 返回处理本节点部分时使用的上下文，只有已迁出本节点的键会再次转发
+func localContext(ctx context.Context) context.Context {
+	if Forwards(ctx) > 0 {
+		return ctx
+	}
+	return WithForwarded(ctx, 1)
+}
+
+// nop 不需要释放时返回的函数
+func nop() {}
+
+// SetRouter 启用服务端分片，之后不属于本节点的键转发给所属节点
+func (s *KVService) SetRouter(r Router) {
+	s.router.Store(&routerHolder{r})
+}
+
+// ShardingStatus 返回分片状态，key非空时包含键所属的节点
+func (s *KVService) ShardingStatus(ctx context.Context, key string) (*ShardingStatus, error) {
+	holder := s.router.Load()
+	if holder == nil {
+		return nil, ErrShardingDisabled
+	}
+	status := holder.Status()
+	if key != "" {
+		status.Owner, _ = holder.Owner(key)
+	}
+	return status, nil
+}
+
+// SetMigrator 设置分片迁移，用于管理接口
+func (s *KVService) SetMigrator(m Migrator) {
+	s.migrator.Store(&migratorHolder{m})
+}
+
+// StartMigration 开始将本节点的键迁移到nodes中的所属节点
+func (s *KVService) StartMigration(ctx context.Context, nodes []string, rate int64) error {
+	holder := s.migrator.Load()
+	if holder == nil {
+		return ErrShardingDisabled
+	}
+	return holder.Start(nodes, rate)
+}
+
+// MigrationStatus 返回本节点的迁移进度
+func (s *KVService) MigrationStatus(ctx context.Context) (*MigrationStatus, error) {
+	holder := s.migrator.Load()
+	if holder == nil {
+		return nil, ErrShardingDisabled
+	}
+	return holder.Status(), nil
+}
+
+// SetMigrationRate 调整迁移的速率上限
+func (s *KVService) SetMigrationRate(ctx context.Context, rate int64) error {
+	holder := s.migrator.Load()
+	if holder == nil {
+		return ErrShardingDisabled
+	}
+	return holder.SetRate(rate)
+}
+
+// CancelMigration 取消本节点的迁移
+func (s *KVService) CancelMigration(ctx context.Context) error {
+	holder := s.migrator.Load()
+	if holder == nil {
+		return ErrShardingDisabled
+	}
+	return holder.Cancel()
+}
+
+// remote 返回键所属的远程节点，键属于本节点、未启用分片或请求已被转发时返回nil
+func (s *KVService) remote(ctx context.Context, key string) (Shard, error) {
+	holder := s.router.Load()
+	hops := Forwards(ctx)
+	if holder == nil || hops >= maxForwards {
+		return nil, nil
+	}
+
+	addr, local := holder.Owner(key)
+	if local || (hops > 0 && !holder.Migrated(key)) {
+		return nil, nil
+	}
+	if hops == 0 && holder.Redirect() {
+		return nil, &RedirectError{Addr: addr}
+	}
+	return holder.Shard(addr)
+}
+
+// remoteWrite 与remote相同，键属于本节点时返回的done在写入完成后调用，写入期间不会交接所有权
+func (s *KVService) remoteWrite(ctx context.Context, key string) (Shard, func(), error) {
+	holder := s.router.Load()
+	if holder == nil {
+		return nil, nop, nil
+	}
+
+	// 先阻止交接再判断所属节点，保证本地写入发生在交接之前
+	done := holder.Hold()
+	shard, err := s.remote(ctx, key)
+	if shard != nil || err != nil {
+		done()
+		return shard, nop, err
+	}
+	return nil, done, nil
+}
+
+// remotePair 返回两个键共同所属的远程节点，两个键属于不同节点时返回ErrCrossShard
+// 键属于本节点时返回的done在写入完成后调用
+func (s *KVService) remotePair(ctx context.Context, src, dst string) (Shard, func(), error) {
+	holder := s.router.Load()
+	hops := Forwards(ctx)
+	if holder == nil || hops >= maxForwards {
+		return nil, nop, nil
+	}
+
+	done := holder.Hold()
+	if hops > 0 && !holder.Migrated(src) && !holder.Migrated(dst) {
+		return nil, done, nil
+	}
+	srcAddr, local := holder.Owner(src)
+	if dstAddr, _ := holder.Owner(dst); dstAddr != srcAddr {
+		done()
+		return nil, nop, ErrCrossShard
+	}
+	if local {
+		return nil, done, nil
+	}
+
+	done()
+	if hops == 0 && holder.Redirect() {
+		return nil, nop, &RedirectError{Addr: srcAddr}
+	}
+	shard, err := holder.Shard(srcAddr)
+	return shard, nop, err
+}
+
+// shardBatch 发往同一个远程节点的键
+type shardBatch struct {
+	addr  string
+	shard Shard
+	keys  []string
+}
+
+// splitKeys 按所属节点拆分键，返回属于本节点的键和发往远程节点的批次
+// 批量操作总是在本节点汇总结果，redirect模式同样会转发
+func (s *KVService) splitKeys(ctx context.Context, keys []string) ([]string, []shardBatch, error) {
+	holder := s.router.Load()
+	hops := Forwards(ctx)
+	if holder == nil || hops >= maxForwards {
+		return keys, nil, nil
+	}
+
+	var local []string
+	groups := make(map[string][]string)
+	for _, key := range keys {
+		addr, isLocal := holder.Owner(key)
+		if isLocal || (hops > 0 && !holder.Migrated(key)) {
+			local = append(local, key)
+			continue
+		}
+		groups[addr] = append(groups[addr], key)
+	}
+
+	batches := make([]shardBatch, 0, len(groups))
+	for addr, keys := range groups {
+		shard, err := holder.Shard(addr)
+		if err != nil {
+			return nil, nil, err
+		}
+		batches = append(batches, shardBatch{addr: addr, shard: shard, keys: keys})
+	}
+	return local, batches, nil
+}
+
+// splitWrite 与splitKeys相同，所有键都属于本节点时返回的done在写入完成后调用
+// 需要转发时不阻止交接，本节点的部分重新进入公开方法时再判断
+func (s *KVService) splitWrite(ctx context.Context, keys []string) ([]string, []shardBatch, func(), error) {
+	holder := s.router.Load()
+	if holder == nil {
+		return keys, nil, nop, nil
+	}
+
+	done := holder.Hold()
+	local, batches, err := s.splitKeys(ctx, keys)
+	if err != nil || len(batches) > 0 {
+		done()
+		return local, batches, nop, err
+	}
+	return local, batches, done, nil
+}
+
+// allShards 返回所有远程节点，用于前缀扫描和统计，转发来的请求只在本节点处理
+func (s *KVService) allShards(ctx context.Context) ([]shardBatch, error) {
+	holder := s.router.Load()
+	if holder == nil || Forwards(ctx) > 0 {
+		return nil, nil
+	}
+
+	var batches []shardBatch
+	for _, addr := range holder.Remotes() {
+		shard, err := holder.Shard(addr)
+		if err != nil {
+			return nil, err
+		}
+		batches = append(batches, shardBatch{addr: addr, shard: shard})
+	}
+	return batches, nil
+}
+
+// fanOut 并发地在远程节点执行remote，同时在本节点执行local，返回第一个错误
+// local的上下文标记为已转发，重新进入公开方法时只处理本节点的数据和已迁出的键
+func fanOut(ctx context.Context, batches []shardBatch, remote func(b shardBatch) error, local func(ctx context.Context) error) error {
+	var wg sync.WaitGroup
+	errs := make([]error, len(batches)+1)
+
+	for i, b := range batches {
+		wg.Add(1)
+		go func(i int, b shardBatch) {
+			defer wg.Done()
+			if err := remote(b); err != nil {
+				errs[i] = fmt.Errorf("shard %s: %w", b.addr, err)
+			}
+		}(i, b)
+	}
+	if local != nil {
+		errs[len(batches)] = local(localContext(ctx))
+	}
+	wg.Wait()
+
+	for _, err := range errs {
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// shardedMGet 在所有相关节点上批量获取并合并结果
+func (s *KVService) shardedMGet(ctx context.Context, local []string, batches []shardBatch) (map[string][]byte, error) {
+	var mu sync.Mutex
+	result := make(map[string][]byte)
+	merge := func(values map[string][]byte) {
+		mu.Lock()
+		defer mu.Unlock()
+		for key, value := range values {
+			result[key] = value
+		}
+	}
+
+	err := fanOut(ctx, batches, func(b shardBatch) error {
+		values, err := b.shard.MGet(ctx, b.keys)
+		if err != nil {
+			return err
+		}
+		merge(values)
+		return nil
+	}, localPart(local, func(ctx context.Context) error {
+		values, err := s.MGet(ctx, local)
+		if err != nil {
+			return err
+		}
+		merge(values)
+		return nil
+	}))
+	if err != nil {
+		return nil, err
+	}
+	return result, nil
+}
+
+// shardedMExists 在所有相关节点上批量判断键是否存在并合并结果
+func (s *KVService) shardedMExists(ctx context.Context, local []string, batches []shardBatch) (map[string]bool, error) {
+	var mu sync.Mutex
+	result := make(map[string]bool)
+	merge := func(values map[string]bool) {
+		mu.Lock()
+		defer mu.Unlock()
+		for key, exists := range values {
+			result[key] = exists
+		}
+	}
+
+	err := fanOut(ctx, batches, func(b shardBatch) error {
+		values, err := b.shard.MExists(ctx, b.keys)
+		if err != nil {
+			return err
+		}
+		merge(values)
+		return nil
+	}, localPart(local, func(ctx context.Context) error {
+		values, err := s.MExists(ctx, local)
+		if err != nil {
+			return err
+		}
+		merge(values)
+		return nil
+	}))
+	if err != nil {
+		return nil, err
+	}
+	return result, nil
+}
+
+// shardedMSet 按所属节点拆分批量写入，部分节点失败时其他节点的写入不会回滚
+func (s *KVService) shardedMSet(ctx context.Context, kvs map[string][]byte, ttl time.Duration, local []string, batches []shardBatch) error {
+	subset := func(keys []string) map[string][]byte {
+		part := make(map[string][]byte, len(keys))
+		for _, key := range keys {
+			part[key] = kvs[key]
+		}
+		return part
+	}
+
+	return fanOut(ctx, batches, func(b shardBatch) error {
+		return b.shard.MSet(ctx, subset(b.keys), ttl)
+	}, localPart(local, func(ctx context.Context) error {
+		return s.MSet(ctx, subset(local), ttl)
+	}))
+}
+
+// shardedMDelete 按所属节点拆分批量删除
+func (s *KVService) shardedMDelete(ctx context.Context, local []string, batches []shardBatch) error {
+	return fanOut(ctx, batches, func(b shardBatch) error {
+		return b.shard.MDelete(ctx, b.keys)
+	}, localPart(local, func(ctx context.Context) error {
+		return s.MDelete(ctx, local)
+	}))
+}
+
+// shardedScan 在所有节点上扫描前缀并合并结果
+func (s *KVService) shardedScan(ctx context.Context, prefix string, limit int, batches []shardBatch) (map[string][]byte, error) {
+	var mu sync.Mutex
+	result := make(map[string][]byte)
+	merge := func(values map[string][]byte) {
+		mu.Lock()
+		defer mu.Unlock()
+		for key, value := range values {
+			result[key] = value
+		}
+	}
+
+	err := fanOut(ctx, batches, func(b shardBatch) error {
+		values, err := b.shard.Scan(ctx, prefix, limit)
+		if err != nil {
+			return err
+		}
+		merge(values)
+		return nil
+	}, func(ctx context.Context) error {
+		values, err := s.Scan(ctx, prefix, limit)
+		if err != nil {
+			return err
+		}
+		merge(values)
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return result, nil
+}
+
+// shardedCountPrefix 汇总所有节点上前缀下的键数量
+func (s *KVService) shardedCountPrefix(ctx context.Context, prefix string, exact bool, batches []shardBatch) (int64, error) {
+	var total atomic.Int64
+	err := fanOut(ctx, batches, func(b shardBatch) error {
+		count, err := b.shard.CountPrefix(ctx, prefix, exact)
+		total.Add(count)
+		return err
+	}, func(ctx context.Context) error {
+		count, err := s.CountPrefix(ctx, prefix, exact)
+		total.Add(count)
+		return err
+	})
+	if err != nil {
+		return 0, err
+	}
+	return total.Load(), nil
+}
+
+// shardedSizeOf 汇总所有节点上前缀下的键数量和字节数
+func (s *KVService) shardedSizeOf(ctx context.Context, prefix string, batches []shardBatch) (*storage.PrefixSize, error) {
+	var mu sync.Mutex
+	total := &storage.PrefixSize{}
+	add := func(size *storage.PrefixSize) {
+		mu.Lock()
+		defer mu.Unlock()
+		total.Keys += size.Keys
+		total.InlineBytes += size.InlineBytes
+		total.DiskBytes += size.DiskBytes
+	}
+
+	err := fanOut(ctx, batches, func(b shardBatch) error {
+		size, err := b.shard.SizeOf(ctx, prefix)
+		if err != nil {
+			return err
+		}
+		add(size)
+		return nil
+	}, func(ctx context.Context) error {
+		size, err := s.SizeOf(ctx, prefix)
+		if err != nil {
+			return err
+		}
+		add(size)
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return total, nil
+}
+
+// localPart 本节点没有需要处理的键时返回nil
+func localPart(keys []string, fn func(ctx context.Context) error) func(ctx context.Context) error {
+	if len(keys) == 0 {
+		return nil
+	}
+	return fn
+}
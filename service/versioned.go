@@ -0,0 +1,119 @@
+package service
+
+import (
+	"context"
+	"time"
+
+	"kvcache/storage"
+)
+
+// SetVersioned 时间戳比本节点保存的新时写入键值对，返回是否已写入
+// 带版本的读写只作用于本节点，由法定数量复制的协调者选择副本
+func (s *KVService) SetVersioned(ctx context.Context, key string, value []byte, ttl time.Duration, stamp uint64) (bool, error) {
+	if err := checkReserved(key); err != nil {
+		s.metrics.SetErrors.WithLabelValues("reserved_key").Inc()
+		return false, err
+	}
+	if err := s.writable(); err != nil {
+		return false, err
+	}
+
+	start := time.Now()
+	defer func() {
+		s.metrics.SetLatency.WithLabelValues("versioned").Observe(time.Since(start).Seconds())
+	}()
+
+	ns, err := s.namespace(ctx)
+	if err != nil {
+		s.metrics.SetErrors.WithLabelValues("namespace_not_found").Inc()
+		return false, err
+	}
+	if key == "" {
+		s.metrics.SetErrors.WithLabelValues("empty_key").Inc()
+		return false, ErrEmptyKey
+	}
+	if ttl <= 0 {
+		ttl = ns.defaultTTL
+	}
+
+	applied, err := ns.storage.SetVersioned([]byte(key), value, ttl, stamp)
+	if err != nil {
+		s.metrics.SetErrors.WithLabelValues(string(storage.ErrorCode(err))).Inc()
+		return false, err
+	}
+	if applied {
+		// 带版本的写入不进入缓存，下次读取时从存储加载
+		ns.flights.forget(key)
+		if ns.config.Cache.Enabled {
+			ns.cache.Delete(key)
+		}
+		s.metrics.Sets.Inc()
+	}
+	return applied, nil
+}
+
+// DeleteVersioned 时间戳比本节点保存的新时删除键并记录墓碑，返回是否已删除
+func (s *KVService) DeleteVersioned(ctx context.Context, key string, stamp uint64) (bool, error) {
+	if err := checkReserved(key); err != nil {
+		s.metrics.DeleteErrors.WithLabelValues("reserved_key").Inc()
+		return false, err
+	}
+	if err := s.writable(); err != nil {
+		return false, err
+	}
+
+	start := time.Now()
+	defer func() {
+		s.metrics.DeleteLatency.WithLabelValues("versioned").Observe(time.Since(start).Seconds())
+	}()
+
+	ns, err := s.namespace(ctx)
+	if err != nil {
+		s.metrics.DeleteErrors.WithLabelValues("namespace_not_found").Inc()
+		return false, err
+	}
+	if key == "" {
+		s.metrics.DeleteErrors.WithLabelValues("empty_key").Inc()
+		return false, ErrEmptyKey
+	}
+
+	applied, err := ns.storage.DeleteVersioned([]byte(key), stamp)
+	if err != nil {
+		s.metrics.DeleteErrors.WithLabelValues(string(storage.ErrorCode(err))).Inc()
+		return false, err
+	}
+	if applied {
+		ns.flights.forget(key)
+		if ns.config.Cache.Enabled {
+			ns.cache.Delete(key)
+		}
+		s.metrics.Deletes.Inc()
+	}
+	return applied, nil
+}
+
+// GetVersioned 读取本节点保存的值和时间戳，不使用缓存
+func (s *KVService) GetVersioned(ctx context.Context, key string) (*storage.VersionedValue, error) {
+	start := time.Now()
+	defer func() {
+		s.metrics.GetLatency.WithLabelValues("versioned").Observe(time.Since(start).Seconds())
+	}()
+
+	ns, err := s.namespace(ctx)
+	if err != nil {
+		s.metrics.GetErrors.WithLabelValues("namespace_not_found").Inc()
+		return nil, err
+	}
+	if key == "" {
+		s.metrics.GetErrors.WithLabelValues("empty_key").Inc()
+		return nil, ErrEmptyKey
+	}
+
+	result, err := ns.storage.GetVersioned([]byte(key))
+	if err != nil {
+		s.metrics.GetErrors.WithLabelValues(string(storage.ErrorCode(err))).Inc()
+		return nil, err
+	}
+	s.metrics.Gets.Inc()
+	return result, nil
+}
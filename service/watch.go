@@ -0,0 +1,39 @@
+package service
+
+import (
+	"context"
+
+	"kvcache/storage"
+)
+
+// Watch 订阅当前命名空间内前缀下的变更，ctx结束时自动取消订阅
+func (s *KVService) Watch(ctx context.Context, prefix string, fromRevision uint64) (*storage.Watcher, error) {
+	ns, err := s.namespace(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	watcher, err := ns.storage.Watch([]byte(prefix), fromRevision)
+	if err != nil {
+		return nil, err
+	}
+
+	s.metrics.Watchers.Inc()
+	go func() {
+		<-ctx.Done()
+		watcher.Close()
+		s.metrics.Watchers.Dec()
+	}()
+
+	return watcher, nil
+}
+
+// DeleteCheckpoint 删除已下线的CDC输出的检查点，不再为其保留变更日志
+func (s *KVService) DeleteCheckpoint(ctx context.Context, name string) error {
+	if _, found, err := s.storage.Checkpoint(name); err != nil {
+		return err
+	} else if !found {
+		return storage.NewError(storage.CodeNotFound, "checkpoint not found")
+	}
+	return s.storage.DeleteCheckpoint(name)
+}
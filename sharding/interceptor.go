@@ -0,0 +1,41 @@
+package sharding
+
+import (
+	"context"
+	"strconv"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/metadata"
+
+	"kvcache/service"
+)
+
+// UnaryServerInterceptor 返回识别其他节点转发请求的拦截器，这些请求在本节点处理，只有已迁出本节点的键会再转发一次
+// 只信任来自分片表中节点的转发标记，其他调用方设置的标记会被移除，router为nil时不信任任何转发标记
+func UnaryServerInterceptor(router *Router) grpc.UnaryServerInterceptor {
+	return func(ctx context.Context, req interface{}, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (interface{}, error) {
+		md, ok := metadata.FromIncomingContext(ctx)
+		if !ok {
+			return handler(ctx, req)
+		}
+		values := md.Get(forwardedHeader)
+		if len(values) == 0 {
+			return handler(ctx, req)
+		}
+
+		// 1. 移除转发标记，后续处理只通过上下文判断请求是否被转发
+		md = md.Copy()
+		md.Delete(forwardedHeader)
+		ctx = metadata.NewIncomingContext(ctx, md)
+
+		// 2. 外部客户端设置的标记会使不属于本节点的键写入本地，只接受分片表中的节点
+		if router == nil || !router.fromMember(ctx) {
+			return handler(ctx, req)
+		}
+		hops, err := strconv.Atoi(values[0])
+		if err != nil || hops < 1 {
+			hops = 1
+		}
+		return handler(service.WithForwarded(ctx, hops), req)
+	}
+}
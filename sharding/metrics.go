@@ -0,0 +1,45 @@
+package sharding
+
+import "github.com/prometheus/client_golang/prometheus"
+
+// metrics 服务端分片监控指标
+type metrics struct {
+	forwarded     *prometheus.CounterVec
+	migratedKeys  prometheus.Counter
+	migratedBytes prometheus.Counter
+	importedKeys  prometheus.Counter
+}
+
+var shardingMetrics = newMetrics()
+
+// newMetrics 创建并注册分片监控指标
+func newMetrics() *metrics {
+	m := &metrics{
+		forwarded: prometheus.NewCounterVec(prometheus.CounterOpts{
+			Namespace: "cachefs",
+			Subsystem: "kv",
+			Name:      "sharding_forwarded_total",
+			Help:      "Total number of requests forwarded to the owning shard",
+		}, []string{"operation"}),
+		migratedKeys: prometheus.NewCounter(prometheus.CounterOpts{
+			Namespace: "cachefs",
+			Subsystem: "kv",
+			Name:      "sharding_migrated_keys_total",
+			Help:      "Total number of keys sent to their new owner during shard migration",
+		}),
+		migratedBytes: prometheus.NewCounter(prometheus.CounterOpts{
+			Namespace: "cachefs",
+			Subsystem: "kv",
+			Name:      "sharding_migrated_bytes_total",
+			Help:      "Total number of value bytes sent to their new owner during shard migration",
+		}),
+		importedKeys: prometheus.NewCounter(prometheus.CounterOpts{
+			Namespace: "cachefs",
+			Subsystem: "kv",
+			Name:      "sharding_imported_keys_total",
+			Help:      "Total number of keys imported from other nodes during shard migration",
+		}),
+	}
+	prometheus.MustRegister(m.forwarded, m.migratedKeys, m.migratedBytes, m.importedKeys)
+	return m
+}
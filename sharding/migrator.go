@@ -0,0 +1,849 @@
+package sharding
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"sort"
+	"sync"
+	"time"
+
+	"google.golang.org/grpc"
+
+	"kvcache/client"
+	"kvcache/config"
+	"kvcache/proto"
+	"kvcache/service"
+	"kvcache/storage"
+)
+
+const (
+	// migrationCheckpoint 迁移期间保存的变更日志检查点，阻止需要追赶的变更被裁剪
+	migrationCheckpoint = "shard-migration"
+	// batchKeys 每次导入的最大键数量
+	batchKeys = 100
+	// batchBytes 每次导入的值字节数达到该大小时提前发送
+	batchBytes = 4 << 20
+	// chunkSize 磁盘文件分块上传的大小
+	chunkSize = 1 << 20
+	// changeBatchSize 每次从变更日志读取的事件数
+	changeBatchSize = 500
+	// maxImportAttempts 导入一批键时补传磁盘文件的最大次数
+	maxImportAttempts = 3
+)
+
+// Migrator 分片迁移，作为源节点将迁出的键发送给新的所属节点，作为目标节点导入其他节点发送的键
+type Migrator struct {
+	proto.UnimplementedMigrationServer
+	store   storage.Storage
+	router  *Router
+	onApply func(storage.ChangeEvent)
+	limiter *throttle
+
+	mu     sync.Mutex
+	status service.MigrationStatus
+	cancel context.CancelFunc
+	done   chan struct{}
+}
+
+// NewMigrator 创建分片迁移，onApply在导入或清理键后调用，用于使缓存失效
+func NewMigrator(store storage.Storage, router *Router, onApply func(storage.ChangeEvent)) *Migrator {
+	return &Migrator{
+		store:   store,
+		router:  router,
+		onApply: onApply,
+		limiter: &throttle{},
+		status:  service.MigrationStatus{State: service.MigrationIdle},
+	}
+}
+
+// Register 注册gRPC服务
+func (m *Migrator) Register(srv *grpc.Server) {
+	proto.RegisterMigrationServer(srv, m)
+}
+
+// Start 开始将本节点在nodes中不再拥有的键迁移到新的所属节点，rate为每秒传输的字节数上限，0表示不限速
+func (m *Migrator) Start(nodes []string, rate int64) error {
+	if m.router.config.MapFile == "" {
+		return errors.New("migration requires a shard map file to record the handoff")
+	}
+	if len(nodes) == 0 {
+		return errors.New("target shard map has no nodes")
+	}
+	if rate < 0 {
+		return errors.New("rate cannot be negative")
+	}
+
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	if m.running() {
+		return errors.New("migration is already running")
+	}
+	next, err := m.router.prepare(nodes)
+	if err != nil {
+		return err
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	m.status = service.MigrationStatus{
+		State:     service.MigrationCopying,
+		Nodes:     next.Nodes(),
+		Rate:      rate,
+		StartedAt: time.Now(),
+	}
+	m.limiter.setRate(rate)
+	m.cancel = cancel
+	m.done = make(chan struct{})
+
+	job := &migration{
+		Migrator: m,
+		ctx:      ctx,
+		self:     m.router.config.Self,
+		next:     next,
+		keys:     make(map[string]map[string]string),
+	}
+	go job.run(m.done)
+	return nil
+}
+
+// running 判断迁移是否正在进行，调用方需持有mu
+func (m *Migrator) running() bool {
+	if m.done == nil {
+		return false
+	}
+	select {
+	case <-m.done:
+		return false
+	default:
+		return true
+	}
+}
+
+// Status 返回本节点最近一次迁移的进度
+func (m *Migrator) Status() *service.MigrationStatus {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	status := m.status
+	return &status
+}
+
+// SetRate 调整正在进行的迁移的速率上限
+func (m *Migrator) SetRate(rate int64) error {
+	if rate < 0 {
+		return errors.New("rate cannot be negative")
+	}
+
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	if !m.running() {
+		return errors.New("no migration is running")
+	}
+	m.status.Rate = rate
+	m.limiter.setRate(rate)
+	return nil
+}
+
+// Cancel 取消尚未开始交接的迁移，已发送给其他节点的键保留在那里
+func (m *Migrator) Cancel() error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	if !m.running() {
+		return errors.New("no migration is running")
+	}
+	switch m.status.State {
+	case service.MigrationCopying, service.MigrationCatchingUp:
+		m.cancel()
+		return nil
+	default:
+		return errors.New("keys are being handed off, migration can no longer be cancelled")
+	}
+}
+
+// Stop 停止正在进行的迁移并等待退出，尚未交接时下次需要重新开始
+func (m *Migrator) Stop() {
+	m.mu.Lock()
+	cancel, done := m.cancel, m.done
+	m.mu.Unlock()
+
+	if cancel != nil {
+		cancel()
+		<-done
+	}
+}
+
+// Import 导入源节点发送的键，引用的磁盘文件不存在时跳过这些键并返回文件名
+func (m *Migrator) Import(ctx context.Context, req *proto.ImportRequest) (*proto.ImportResponse, error) {
+	view, err := m.namespace(req.Namespace, req.Config)
+	if err != nil {
+		return &proto.ImportResponse{Success: false, Error: err.Error()}, nil
+	}
+
+	resp := &proto.ImportResponse{Success: true}
+	for _, e := range req.Entries {
+		event := &storage.ChangeEvent{Type: storage.EventDelete, Namespace: req.Namespace, Key: e.Key}
+		var entry *storage.ReplicaEntry
+		if !e.Deleted {
+			meta := &storage.KeyMeta{}
+			if err := json.Unmarshal(e.Meta, meta); err != nil {
+				return &proto.ImportResponse{Success: false, Error: fmt.Sprintf("invalid key meta for %s: %v", e.Key, err)}, nil
+			}
+			if meta.DiskFile != "" && !view.HasBlob(meta.DiskFile) {
+				resp.MissingBlobs = append(resp.MissingBlobs, meta.DiskFile)
+				continue
+			}
+			event.Type = storage.EventPut
+			event.Version = meta.Version
+			event.Size = meta.Size
+			entry = &storage.ReplicaEntry{Value: e.Value, Meta: meta}
+		}
+
+		if err := view.ApplyChange(event, entry); err != nil {
+			return &proto.ImportResponse{Success: false, Error: err.Error()}, nil
+		}
+		if m.onApply != nil {
+			m.onApply(*event)
+		}
+		shardingMetrics.importedKeys.Inc()
+	}
+	return resp, nil
+}
+
+// PushBlob 接收源节点上传的磁盘文件，导入时校验内容哈希
+func (m *Migrator) PushBlob(stream proto.Migration_PushBlobServer) error {
+	first, err := stream.Recv()
+	if err != nil {
+		return err
+	}
+	view, err := m.store.Namespace(first.Namespace)
+	if err != nil {
+		return stream.SendAndClose(&proto.PushBlobResponse{Success: false, Error: err.Error()})
+	}
+	if view.HasBlob(first.FileName) {
+		return stream.SendAndClose(&proto.PushBlobResponse{Success: true})
+	}
+
+	pr, pw := io.Pipe()
+	go func() {
+		if _, err := pw.Write(first.Data); err != nil {
+			return
+		}
+		for {
+			chunk, err := stream.Recv()
+			if err == io.EOF {
+				pw.Close()
+				return
+			}
+			if err != nil {
+				pw.CloseWithError(err)
+				return
+			}
+			if _, err := pw.Write(chunk.Data); err != nil {
+				return
+			}
+		}
+	}()
+
+	err = view.ImportBlob(first.FileName, pr)
+	pr.CloseWithError(err)
+	if err != nil {
+		return stream.SendAndClose(&proto.PushBlobResponse{Success: false, Error: err.Error()})
+	}
+	return stream.SendAndClose(&proto.PushBlobResponse{Success: true})
+}
+
+// Handoff 记录源节点已将键交接给目标分片表中的节点，之后这些键的请求直接发往新的所属节点
+func (m *Migrator) Handoff(ctx context.Context, req *proto.HandoffRequest) (*proto.HandoffResponse, error) {
+	if err := m.router.handoff(req.Source, req.Nodes); err != nil {
+		return &proto.HandoffResponse{Success: false, Error: err.Error()}, nil
+	}
+	return &proto.HandoffResponse{Success: true}, nil
+}
+
+// namespace 获取导入的命名空间，本地不存在时按源节点的配置创建
+func (m *Migrator) namespace(name string, ns *proto.Namespace) (storage.Storage, error) {
+	if view, err := m.store.Namespace(name); err == nil || ns == nil {
+		return view, err
+	}
+
+	nsCfg := &config.NamespaceConfig{Name: ns.Name, DefaultTTL: ns.DefaultTtl}
+	if ns.Cache != "" {
+		nsCfg.Cache = &config.CacheConfig{}
+		if err := json.Unmarshal([]byte(ns.Cache), nsCfg.Cache); err != nil {
+			return nil, err
+		}
+	}
+	if ns.Eviction != "" {
+		nsCfg.Eviction = &config.EvictionConfig{}
+		if err := json.Unmarshal([]byte(ns.Eviction), nsCfg.Eviction); err != nil {
+			return nil, err
+		}
+	}
+	if err := m.store.CreateNamespace(nsCfg); err != nil {
+		return nil, err
+	}
+	return m.store.Namespace(name)
+}
+
+// migration 一次迁移的执行过程
+type migration struct {
+	*Migrator
+	ctx    context.Context
+	self   string
+	next   *client.Ring
+	frozen bool // 交接期间不限速，尽快恢复写入
+
+	// keys 已发送的键，命名空间 -> 键 -> 新的所属节点，用于追赶范围删除和交接后清理
+	keys map[string]map[string]string
+}
+
+// pendingKey 已发送但需要重新导入的键
+type pendingKey struct {
+	key      string
+	diskFile string
+}
+
+// run 执行迁移并记录结果
+func (j *migration) run(done chan struct{}) {
+	defer close(done)
+	defer j.router.finish()
+
+	err := j.migrate()
+
+	j.mu.Lock()
+	defer j.mu.Unlock()
+	j.status.FinishedAt = time.Now()
+	switch {
+	case err == nil:
+		j.status.State = service.MigrationCompleted
+	case j.ctx.Err() != nil && (j.status.State == service.MigrationCopying || j.status.State == service.MigrationCatchingUp):
+		j.status.State = service.MigrationCancelled
+	default:
+		j.status.State = service.MigrationFailed
+		j.status.LastError = err.Error()
+	}
+}
+
+// migrate 复制迁出的键，追赶期间的变更，交接所有权后删除本节点的副本
+func (j *migration) migrate() error {
+	// 1. 保存检查点，追赶完成前之后的变更不会被裁剪
+	from := j.store.LatestRevision() + 1
+	if err := j.store.SaveCheckpoint(migrationCheckpoint, from-1); err != nil {
+		return err
+	}
+	defer j.store.DeleteCheckpoint(migrationCheckpoint)
+
+	// 2. 复制迁出的键
+	plan, err := j.plan()
+	if err != nil {
+		return err
+	}
+	for _, ns := range sortedNamespaces(plan) {
+		view, err := j.store.Namespace(ns)
+		if err != nil {
+			continue
+		}
+		for _, addr := range sortedAddrs(plan[ns]) {
+			if err := j.send(view, ns, addr, plan[ns][addr]); err != nil {
+				return err
+			}
+		}
+	}
+
+	// 3. 追赶复制期间的变更，直到剩余的变更可以在一批内处理
+	if err := j.enter(service.MigrationCatchingUp); err != nil {
+		return err
+	}
+	for {
+		n, err := j.catchUp(&from)
+		if err != nil {
+			return err
+		}
+		if n < changeBatchSize {
+			break
+		}
+	}
+
+	// 4. 暂停本节点的写入，追赶剩余的变更后交接所有权
+	if err := j.enter(service.MigrationHandoff); err != nil {
+		return err
+	}
+	if err := j.handoff(&from); err != nil {
+		return err
+	}
+
+	// 5. 通知其他节点，失败时请求仍会经本节点转发给新的所属节点
+	j.notify()
+
+	// 6. 删除本节点已交接的键
+	j.mu.Lock()
+	j.status.State = service.MigrationCleanup
+	j.mu.Unlock()
+	return j.cleanup()
+}
+
+// enter 进入下一个阶段，迁移已被取消时返回错误
+func (j *migration) enter(state string) error {
+	j.mu.Lock()
+	defer j.mu.Unlock()
+
+	if err := j.ctx.Err(); err != nil {
+		return err
+	}
+	j.status.State = state
+	return nil
+}
+
+// fail 记录最近一次错误，不中断迁移
+func (j *migration) fail(err error) {
+	j.mu.Lock()
+	defer j.mu.Unlock()
+	j.status.LastError = err.Error()
+}
+
+// plan 列出每个命名空间中迁出的键，按新的所属节点分组
+func (j *migration) plan() (map[string]map[string][]string, error) {
+	namespaces, err := j.store.ListNamespaces()
+	if err != nil {
+		return nil, err
+	}
+
+	plan := make(map[string]map[string][]string)
+	var total int64
+	for _, nsCfg := range namespaces {
+		view, err := j.store.Namespace(nsCfg.Name)
+		if err != nil {
+			return nil, err
+		}
+		keys, err := view.Scan(nil)
+		if err != nil {
+			return nil, err
+		}
+
+		groups := make(map[string][]string)
+		for _, key := range keys {
+			if addr, ok := j.router.leaving(j.next, string(key)); ok {
+				groups[addr] = append(groups[addr], string(key))
+				total++
+			}
+		}
+		if len(groups) > 0 {
+			plan[nsCfg.Name] = groups
+		}
+	}
+
+	j.mu.Lock()
+	j.status.TotalKeys = total
+	j.mu.Unlock()
+	return plan, nil
+}
+
+// send 分批发送键的当前状态，已删除的键发送删除标记
+func (j *migration) send(view storage.Storage, ns, addr string, keys []string) error {
+	conn, err := j.router.conn(addr)
+	if err != nil {
+		return err
+	}
+	c := proto.NewMigrationClient(conn)
+
+	nsMsg, err := j.namespaceMessage(ns)
+	if err != nil {
+		return err
+	}
+
+	for len(keys) > 0 {
+		// 1. 读取一批键，值的总大小达到上限时提前结束
+		var (
+			entries []*proto.MigrationEntry
+			pending []pendingKey
+			size    int64
+		)
+		for len(keys) > 0 && len(entries) < batchKeys && size < batchBytes {
+			entry, diskFile, n, err := exportEntry(view, keys[0])
+			if err != nil {
+				return err
+			}
+			entries = append(entries, entry)
+			pending = append(pending, pendingKey{key: keys[0], diskFile: diskFile})
+			size += n
+			j.track(ns, keys[0], addr)
+			keys = keys[1:]
+		}
+
+		// 2. 导入并补传缺少的磁盘文件
+		if err := j.importBatch(c, view, ns, nsMsg, entries, pending); err != nil {
+			return fmt.Errorf("failed to migrate keys to %s: %v", addr, err)
+		}
+
+		// 3. 更新进度并按速率限制等待
+		j.mu.Lock()
+		j.status.MovedKeys += int64(len(entries))
+		j.status.MovedBytes += size
+		j.mu.Unlock()
+		shardingMetrics.migratedKeys.Add(float64(len(entries)))
+		shardingMetrics.migratedBytes.Add(float64(size))
+
+		if !j.frozen {
+			if err := j.limiter.wait(j.ctx, size); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+// importBatch 导入一批键，目标节点缺少磁盘文件时上传文件后重新导入引用它们的键
+func (j *migration) importBatch(c proto.MigrationClient, view storage.Storage, ns string, nsMsg *proto.Namespace, entries []*proto.MigrationEntry, pending []pendingKey) error {
+	for attempt := 0; len(entries) > 0; attempt++ {
+		if attempt == maxImportAttempts {
+			return fmt.Errorf("disk files are still missing after %d attempts", attempt)
+		}
+
+		resp, err := c.Import(j.ctx, &proto.ImportRequest{Namespace: ns, Entries: entries, Config: nsMsg})
+		if err != nil {
+			return err
+		}
+		if !resp.Success {
+			return errors.New(resp.Error)
+		}
+		if len(resp.MissingBlobs) == 0 {
+			return nil
+		}
+
+		// 本地文件不存在说明键已被覆盖，重新读取时得到新的状态
+		missing := make(map[string]bool)
+		for _, fileName := range resp.MissingBlobs {
+			if missing[fileName] {
+				continue
+			}
+			missing[fileName] = true
+			if err := j.pushBlob(c, view, ns, fileName); err != nil && !os.IsNotExist(err) {
+				return err
+			}
+		}
+
+		var retry []pendingKey
+		entries = nil
+		for _, p := range pending {
+			if !missing[p.diskFile] {
+				continue
+			}
+			entry, diskFile, _, err := exportEntry(view, p.key)
+			if err != nil {
+				return err
+			}
+			entries = append(entries, entry)
+			retry = append(retry, pendingKey{key: p.key, diskFile: diskFile})
+		}
+		pending = retry
+	}
+	return nil
+}
+
+// pushBlob 分块上传磁盘文件，空文件也发送一个分块
+func (j *migration) pushBlob(c proto.MigrationClient, view storage.Storage, ns, fileName string) error {
+	r, err := view.OpenBlob(fileName)
+	if err != nil {
+		return err
+	}
+	defer r.Close()
+
+	stream, err := c.PushBlob(j.ctx)
+	if err != nil {
+		return err
+	}
+
+	buf := make([]byte, chunkSize)
+	first := true
+	for {
+		n, readErr := io.ReadFull(r, buf)
+		if n > 0 || first {
+			msg := &proto.BlobUpload{Data: buf[:n]}
+			if first {
+				msg.Namespace = ns
+				msg.FileName = fileName
+				first = false
+			}
+			// 接收方提前结束时从CloseAndRecv获取结果
+			if err := stream.Send(msg); err == io.EOF {
+				break
+			} else if err != nil {
+				return err
+			}
+		}
+		if readErr == io.EOF || readErr == io.ErrUnexpectedEOF {
+			break
+		}
+		if readErr != nil {
+			return readErr
+		}
+	}
+
+	resp, err := stream.CloseAndRecv()
+	if err != nil {
+		return err
+	}
+	if !resp.Success {
+		return errors.New(resp.Error)
+	}
+	return nil
+}
+
+// catchUp 从from开始读取一批变更，将涉及迁出键的最新状态发送给新的所属节点，返回读取的变更数
+func (j *migration) catchUp(from *uint64) (int, error) {
+	events, err := j.store.ReadChanges(*from, changeBatchSize)
+	if err != nil {
+		return 0, err
+	}
+	if len(events) == 0 {
+		return 0, nil
+	}
+
+	// 1. 按命名空间和新的所属节点汇总受影响的键，同一个键只发送一次
+	changed := make(map[string]map[string]map[string]bool)
+	add := func(ns, addr, key string) {
+		if changed[ns] == nil {
+			changed[ns] = make(map[string]map[string]bool)
+		}
+		if changed[ns][addr] == nil {
+			changed[ns][addr] = make(map[string]bool)
+		}
+		changed[ns][addr][key] = true
+	}
+	for _, event := range events {
+		if event.Type == storage.EventDeleteRange {
+			// 范围内已发送的键需要在新的所属节点删除
+			start, end := string(event.Key), string(event.End)
+			for key, addr := range j.keys[event.Namespace] {
+				if key >= start && (event.End == nil || key < end) {
+					add(event.Namespace, addr, key)
+				}
+			}
+			continue
+		}
+		if addr, ok := j.router.leaving(j.next, string(event.Key)); ok {
+			add(event.Namespace, addr, string(event.Key))
+		}
+	}
+
+	// 2. 发送键的当前状态
+	for ns, groups := range changed {
+		view, err := j.store.Namespace(ns)
+		if err != nil {
+			// 命名空间已被删除
+			continue
+		}
+		for addr, set := range groups {
+			keys := make([]string, 0, len(set))
+			for key := range set {
+				keys = append(keys, key)
+			}
+			sort.Strings(keys)
+			if err := j.send(view, ns, addr, keys); err != nil {
+				return 0, err
+			}
+		}
+	}
+
+	// 3. 保存检查点
+	last := events[len(events)-1].Revision
+	if err := j.store.SaveCheckpoint(migrationCheckpoint, last); err != nil {
+		return 0, err
+	}
+	*from = last + 1
+
+	j.mu.Lock()
+	j.status.Revision = last
+	j.mu.Unlock()
+	return len(events), nil
+}
+
+// handoff 阻止本节点的写入，追赶全部剩余的变更后交接所有权
+func (j *migration) handoff(from *uint64) error {
+	release := j.router.freeze()
+	defer release()
+
+	j.frozen = true
+	for {
+		n, err := j.catchUp(from)
+		if err != nil {
+			return err
+		}
+		if n == 0 {
+			break
+		}
+	}
+	return j.router.handoff(j.self, j.next.Nodes())
+}
+
+// notify 通知其他节点本节点已完成交接
+func (j *migration) notify() {
+	for _, addr := range j.router.Remotes() {
+		conn, err := j.router.conn(addr)
+		if err != nil {
+			j.fail(err)
+			continue
+		}
+		resp, err := proto.NewMigrationClient(conn).Handoff(j.ctx, &proto.HandoffRequest{Source: j.self, Nodes: j.next.Nodes()})
+		if err == nil && !resp.Success {
+			err = errors.New(resp.Error)
+		}
+		if err != nil {
+			j.fail(fmt.Errorf("failed to notify %s of handoff: %v", addr, err))
+		}
+	}
+}
+
+// cleanup 删除本节点已交接的键，这些键的请求已转发给新的所属节点
+func (j *migration) cleanup() error {
+	for ns, keys := range j.keys {
+		view, err := j.store.Namespace(ns)
+		if err != nil {
+			continue
+		}
+
+		batch := make([][]byte, 0, batchKeys)
+		flush := func() error {
+			if len(batch) == 0 {
+				return nil
+			}
+			if err := view.MDelete(batch); err != nil {
+				return err
+			}
+			if j.onApply != nil {
+				for _, key := range batch {
+					j.onApply(storage.ChangeEvent{Type: storage.EventDelete, Namespace: ns, Key: key})
+				}
+			}
+			batch = batch[:0]
+			return nil
+		}
+		for key := range keys {
+			batch = append(batch, []byte(key))
+			if len(batch) == batchKeys {
+				if err := flush(); err != nil {
+					return err
+				}
+			}
+		}
+		if err := flush(); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// track 记录已发送的键
+func (j *migration) track(ns, key, addr string) {
+	if j.keys[ns] == nil {
+		j.keys[ns] = make(map[string]string)
+	}
+	j.keys[ns][key] = addr
+}
+
+// namespaceMessage 返回命名空间的配置，目标节点不存在该命名空间时据此创建
+func (j *migration) namespaceMessage(name string) (*proto.Namespace, error) {
+	if name == config.DefaultNamespace {
+		return nil, nil
+	}
+	nsCfg, err := j.store.GetNamespace(name)
+	if err != nil {
+		return nil, err
+	}
+
+	ns := &proto.Namespace{Name: nsCfg.Name, DefaultTtl: nsCfg.DefaultTTL}
+	if nsCfg.Cache != nil {
+		data, _ := json.Marshal(nsCfg.Cache)
+		ns.Cache = string(data)
+	}
+	if nsCfg.Eviction != nil {
+		data, _ := json.Marshal(nsCfg.Eviction)
+		ns.Eviction = string(data)
+	}
+	return ns, nil
+}
+
+// exportEntry 读取键的当前状态，返回迁移消息、引用的磁盘文件和值的大小
+func exportEntry(view storage.Storage, key string) (*proto.MigrationEntry, string, int64, error) {
+	entry, err := view.ExportEntry([]byte(key))
+	if err != nil {
+		return nil, "", 0, err
+	}
+	if entry == nil {
+		return &proto.MigrationEntry{Key: []byte(key), Deleted: true}, "", 0, nil
+	}
+
+	meta, err := json.Marshal(entry.Meta)
+	if err != nil {
+		return nil, "", 0, err
+	}
+	return &proto.MigrationEntry{Key: []byte(key), Meta: meta, Value: entry.Value}, entry.Meta.DiskFile, entry.Meta.Size, nil
+}
+
+// sortedNamespaces 返回排序后的命名空间
+func sortedNamespaces(plan map[string]map[string][]string) []string {
+	names := make([]string, 0, len(plan))
+	for name := range plan {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// sortedAddrs 返回排序后的节点地址
+func sortedAddrs(groups map[string][]string) []string {
+	addrs := make([]string, 0, len(groups))
+	for addr := range groups {
+		addrs = append(addrs, addr)
+	}
+	sort.Strings(addrs)
+	return addrs
+}
+
+// throttle 按字节速率限制迁移速度，rate为0时不限速
+type throttle struct {
+	mu    sync.Mutex
+	rate  int64
+	start time.Time
+	bytes int64
+}
+
+// setRate 设置速率并重新开始计算
+func (t *throttle) setRate(rate int64) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	t.rate = rate
+	t.start = time.Now()
+	t.bytes = 0
+}
+
+// wait 记录已发送的字节数，超过速率时等待
+func (t *throttle) wait(ctx context.Context, n int64) error {
+	t.mu.Lock()
+	if t.rate <= 0 {
+		t.mu.Unlock()
+		return nil
+	}
+	t.bytes += n
+	delay := time.Duration(float64(t.bytes)/float64(t.rate)*float64(time.Second)) - time.Since(t.start)
+	t.mu.Unlock()
+
+	if delay <= 0 {
+		return nil
+	}
+	timer := time.NewTimer(delay)
+	defer timer.Stop()
+	select {
+	case <-timer.C:
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
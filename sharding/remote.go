@@ -0,0 +1,317 @@
+package sharding
+
+import (
+	"context"
+	"errors"
+	"strconv"
+	"time"
+
+	"google.golang.org/grpc/metadata"
+
+	"kvcache/proto"
+	"kvcache/rpcerror"
+	"kvcache/service"
+	"kvcache/storage"
+)
+
+// forwardedHeader 转发请求携带的gRPC元数据，值为请求已被转发的次数
+const forwardedHeader = "x-kv-forwarded"
+
+// remoteShard 通过gRPC访问远程节点，请求使用上下文中的命名空间
+type remoteShard struct {
+	addr   string
+	client proto.KeyValueServiceClient
+}
+
+// outgoing 为请求添加转发次数并返回命名空间
+func outgoing(ctx context.Context) (context.Context, string) {
+	hops := strconv.Itoa(service.Forwards(ctx) + 1)
+	return metadata.AppendToOutgoingContext(ctx, forwardedHeader, hops), service.NamespaceFromContext(ctx)
+}
+
+// Set 设置键值对
+func (r *remoteShard) Set(ctx context.Context, key string, value []byte, ttl time.Duration) error {
+	ctx, ns := outgoing(ctx)
+	shardingMetrics.forwarded.WithLabelValues("set").Inc()
+
+	resp, err := r.client.Set(ctx, &proto.SetRequest{Key: []byte(key), Value: value, Namespace: ns, Ttl: ttlSeconds(ttl)})
+	if err != nil {
+		return rpcerror.FromStatus(err)
+	}
+	if !resp.Success {
+		return errors.New(resp.Error)
+	}
+	return nil
+}
+
+// Get 获取值
+func (r *remoteShard) Get(ctx context.Context, key string) ([]byte, error) {
+	ctx, ns := outgoing(ctx)
+	shardingMetrics.forwarded.WithLabelValues("get").Inc()
+
+	resp, err := r.client.Get(ctx, &proto.GetRequest{Key: []byte(key), Namespace: ns})
+	if err != nil {
+		return nil, rpcerror.FromStatus(err)
+	}
+	if resp.Evicted {
+		return nil, storage.ErrEvicted
+	}
+	if resp.Error != "" {
+		return nil, errors.New(resp.Error)
+	}
+	if !resp.Found {
+		return nil, service.ErrKeyNotFound
+	}
+	return resp.Value, nil
+}
+
+// Stat 获取键的元数据
+func (r *remoteShard) Stat(ctx context.Context, key string) (*storage.KeyInfo, error) {
+	ctx, ns := outgoing(ctx)
+	shardingMetrics.forwarded.WithLabelValues("stat").Inc()
+
+	resp, err := r.client.GetMeta(ctx, &proto.GetMetaRequest{Key: []byte(key), Namespace: ns})
+	if err != nil {
+		return nil, rpcerror.FromStatus(err)
+	}
+	if resp.Error != "" {
+		return nil, errors.New(resp.Error)
+	}
+	if !resp.Found {
+		return nil, service.ErrKeyNotFound
+	}
+
+	meta := resp.Meta
+	info := &storage.KeyInfo{
+		KeyMeta: storage.KeyMeta{
+			Size:       meta.Size,
+			CreatedAt:  meta.CreateTime,
+			UpdatedAt:  meta.UpdateTime,
+			LastAccess: meta.LastAccess,
+			Version:    meta.Version,
+			Location:   meta.Location,
+			Codec:      meta.Codec,
+			Encryption: meta.Encryption,
+			Evicted:    meta.Evicted,
+			EvictedAt:  meta.EvictedAt,
+		},
+		TTL:      -1,
+		DiskPath: meta.DiskPath,
+	}
+	if meta.Ttl >= 0 {
+		info.TTL = time.Duration(meta.Ttl) * time.Second
+	}
+	return info, nil
+}
+
+// Exists 判断键是否存在
+func (r *remoteShard) Exists(ctx context.Context, key string) (bool, error) {
+	ctx, ns := outgoing(ctx)
+	shardingMetrics.forwarded.WithLabelValues("exists").Inc()
+
+	resp, err := r.client.Exists(ctx, &proto.ExistsRequest{Key: []byte(key), Namespace: ns})
+	if err != nil {
+		return false, rpcerror.FromStatus(err)
+	}
+	if resp.Error != "" {
+		return false, errors.New(resp.Error)
+	}
+	return resp.Exists, nil
+}
+
+// Delete 删除键
+func (r *remoteShard) Delete(ctx context.Context, key string) error {
+	ctx, ns := outgoing(ctx)
+	shardingMetrics.forwarded.WithLabelValues("delete").Inc()
+
+	resp, err := r.client.Delete(ctx, &proto.DeleteRequest{Key: []byte(key), Namespace: ns})
+	if err != nil {
+		return rpcerror.FromStatus(err)
+	}
+	if !resp.Success {
+		return errors.New(resp.Error)
+	}
+	return nil
+}
+
+// Append 向值末尾追加数据
+func (r *remoteShard) Append(ctx context.Context, key string, data []byte) error {
+	ctx, ns := outgoing(ctx)
+	shardingMetrics.forwarded.WithLabelValues("append").Inc()
+
+	resp, err := r.client.Append(ctx, &proto.AppendRequest{Key: []byte(key), Data: data, Namespace: ns})
+	if err != nil {
+		return rpcerror.FromStatus(err)
+	}
+	if !resp.Success {
+		return errors.New(resp.Error)
+	}
+	return nil
+}
+
+// WriteAt 在指定偏移处写入数据
+func (r *remoteShard) WriteAt(ctx context.Context, key string, offset int64, data []byte) error {
+	ctx, ns := outgoing(ctx)
+	shardingMetrics.forwarded.WithLabelValues("writeat").Inc()
+
+	resp, err := r.client.WriteAt(ctx, &proto.WriteAtRequest{Key: []byte(key), Offset: offset, Data: data, Namespace: ns})
+	if err != nil {
+		return rpcerror.FromStatus(err)
+	}
+	if !resp.Success {
+		return errors.New(resp.Error)
+	}
+	return nil
+}
+
+// Rename 重命名键
+func (r *remoteShard) Rename(ctx context.Context, src, dst string, overwrite bool) error {
+	ctx, ns := outgoing(ctx)
+	shardingMetrics.forwarded.WithLabelValues("rename").Inc()
+
+	resp, err := r.client.Rename(ctx, &proto.RenameRequest{Src: []byte(src), Dst: []byte(dst), Overwrite: overwrite, Namespace: ns})
+	if err != nil {
+		return rpcerror.FromStatus(err)
+	}
+	if !resp.Success {
+		return errors.New(resp.Error)
+	}
+	return nil
+}
+
+// Copy 复制键
+func (r *remoteShard) Copy(ctx context.Context, src, dst string) error {
+	ctx, ns := outgoing(ctx)
+	shardingMetrics.forwarded.WithLabelValues("copy").Inc()
+
+	resp, err := r.client.Copy(ctx, &proto.CopyRequest{Src: []byte(src), Dst: []byte(dst), Namespace: ns})
+	if err != nil {
+		return rpcerror.FromStatus(err)
+	}
+	if !resp.Success {
+		return errors.New(resp.Error)
+	}
+	return nil
+}
+
+// MSet 批量设置键值对
+func (r *remoteShard) MSet(ctx context.Context, kvs map[string][]byte, ttl time.Duration) error {
+	ctx, ns := outgoing(ctx)
+	shardingMetrics.forwarded.WithLabelValues("mset").Inc()
+
+	resp, err := r.client.MSet(ctx, &proto.MSetRequest{KeyValues: kvs, Namespace: ns, Ttl: ttlSeconds(ttl)})
+	if err != nil {
+		return rpcerror.FromStatus(err)
+	}
+	if !resp.Success {
+		return errors.New(resp.Error)
+	}
+	return nil
+}
+
+// MGet 批量获取值
+func (r *remoteShard) MGet(ctx context.Context, keys []string) (map[string][]byte, error) {
+	ctx, ns := outgoing(ctx)
+	shardingMetrics.forwarded.WithLabelValues("mget").Inc()
+
+	resp, err := r.client.MGet(ctx, &proto.MGetRequest{Keys: toBytes(keys), Namespace: ns})
+	if err != nil {
+		return nil, rpcerror.FromStatus(err)
+	}
+	if resp.Error != "" {
+		return nil, errors.New(resp.Error)
+	}
+	return resp.KeyValues, nil
+}
+
+// MDelete 批量删除键
+func (r *remoteShard) MDelete(ctx context.Context, keys []string) error {
+	ctx, ns := outgoing(ctx)
+	shardingMetrics.forwarded.WithLabelValues("mdelete").Inc()
+
+	resp, err := r.client.MDelete(ctx, &proto.MDeleteRequest{Keys: toBytes(keys), Namespace: ns})
+	if err != nil {
+		return rpcerror.FromStatus(err)
+	}
+	if !resp.Success {
+		return errors.New(resp.Error)
+	}
+	return nil
+}
+
+// MExists 批量判断键是否存在
+func (r *remoteShard) MExists(ctx context.Context, keys []string) (map[string]bool, error) {
+	ctx, ns := outgoing(ctx)
+	shardingMetrics.forwarded.WithLabelValues("mexists").Inc()
+
+	resp, err := r.client.MExists(ctx, &proto.MExistsRequest{Keys: toBytes(keys), Namespace: ns})
+	if err != nil {
+		return nil, rpcerror.FromStatus(err)
+	}
+	if resp.Error != "" {
+		return nil, errors.New(resp.Error)
+	}
+	return resp.Results, nil
+}
+
+// CountPrefix 统计前缀下的键数量
+func (r *remoteShard) CountPrefix(ctx context.Context, prefix string, exact bool) (int64, error) {
+	ctx, ns := outgoing(ctx)
+	shardingMetrics.forwarded.WithLabelValues("count").Inc()
+
+	resp, err := r.client.CountPrefix(ctx, &proto.CountPrefixRequest{Prefix: []byte(prefix), Exact: exact, Namespace: ns})
+	if err != nil {
+		return 0, rpcerror.FromStatus(err)
+	}
+	if resp.Error != "" {
+		return 0, errors.New(resp.Error)
+	}
+	return resp.Count, nil
+}
+
+// SizeOf 统计前缀下的键数量和字节数
+func (r *remoteShard) SizeOf(ctx context.Context, prefix string) (*storage.PrefixSize, error) {
+	ctx, ns := outgoing(ctx)
+	shardingMetrics.forwarded.WithLabelValues("sizeof").Inc()
+
+	resp, err := r.client.SizeOf(ctx, &proto.SizeOfRequest{Prefix: []byte(prefix), Namespace: ns})
+	if err != nil {
+		return nil, rpcerror.FromStatus(err)
+	}
+	if resp.Error != "" {
+		return nil, errors.New(resp.Error)
+	}
+	return &storage.PrefixSize{Keys: resp.Keys, InlineBytes: resp.InlineBytes, DiskBytes: resp.DiskBytes}, nil
+}
+
+// Scan 扫描前缀下的键值对
+func (r *remoteShard) Scan(ctx context.Context, prefix string, limit int) (map[string][]byte, error) {
+	ctx, ns := outgoing(ctx)
+	shardingMetrics.forwarded.WithLabelValues("scan").Inc()
+
+	resp, err := r.client.ScanKeyValues(ctx, &proto.ScanRequest{Prefix: []byte(prefix), Namespace: ns, Limit: int32(limit)})
+	if err != nil {
+		return nil, rpcerror.FromStatus(err)
+	}
+	if resp.Error != "" {
+		return nil, errors.New(resp.Error)
+	}
+	return resp.KeyValues, nil
+}
+
+// ttlSeconds 将过期时间转换为秒，不足一秒按一秒计算
+func ttlSeconds(ttl time.Duration) int64 {
+	if ttl <= 0 {
+		return 0
+	}
+	return int64((ttl + time.Second - 1) / time.Second)
+}
+
+// toBytes 将键转换为[]byte列表
+func toBytes(keys []string) [][]byte {
+	result := make([][]byte, len(keys))
+	for i, key := range keys {
+		result[i] = []byte(key)
+	}
+	return result
+}
@@ -0,0 +1,464 @@
+package sharding
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net"
+	"os"
+	"sort"
+	"sync"
+	"time"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/credentials/insecure"
+	"google.golang.org/grpc/peer"
+
+	"kvcache/client"
+	"kvcache/config"
+	"kvcache/proto"
+	"kvcache/service"
+)
+
+// shardMap 分片表文件的内容，迁移期间同时记录目标分片表和已完成交接的源节点
+type shardMap struct {
+	Nodes []string `json:"nodes"`
+	Next  []string `json:"next,omitempty"`
+	Moved []string `json:"moved,omitempty"`
+}
+
+// Router 基于一致性哈希的服务端分片路由，分片表来自静态配置或定期重新加载的文件
+// 迁移期间，已完成交接的源节点上的键归目标分片表中的节点所有
+type Router struct {
+	config *config.ShardingConfig
+
+	mu        sync.RWMutex
+	ring      *client.Ring
+	next      *client.Ring    // 迁移的目标分片表，没有迁移时为nil
+	moved     map[string]bool // 已完成交接的源节点
+	members   map[string]bool // 分片表中节点解析出的IP，用于识别节点之间的转发
+	migrating bool            // 本节点正在迁移，暂停重新加载分片表文件
+	conns     map[string]*grpc.ClientConn
+	modTime   time.Time // 已加载的分片表文件修改时间
+
+	// writes 本节点的写入持有读锁，交接所有权时持有写锁
+	writes sync.RWMutex
+
+	stop chan struct{}
+	wg   sync.WaitGroup
+}
+
+// NewRouter 创建分片路由并加载分片表
+func NewRouter(cfg *config.ShardingConfig) (*Router, error) {
+	if err := cfg.Validate(); err != nil {
+		return nil, err
+	}
+
+	r := &Router{
+		config: cfg,
+		ring:   client.NewRing(cfg.VirtualNodes),
+		conns:  make(map[string]*grpc.ClientConn),
+		stop:   make(chan struct{}),
+	}
+	if cfg.MapFile == "" {
+		if err := r.setMap(&shardMap{Nodes: cfg.Nodes}); err != nil {
+			return nil, err
+		}
+		return r, nil
+	}
+	if _, err := r.reload(); err != nil {
+		return nil, err
+	}
+	return r, nil
+}
+
+// Start 定期检查分片表文件，文件变化后重新加载
+func (r *Router) Start() {
+	if r.config.MapFile == "" || r.config.ReloadInterval <= 0 {
+		return
+	}
+
+	r.wg.Add(1)
+	go func() {
+		defer r.wg.Done()
+
+		ticker := time.NewTicker(time.Duration(r.config.ReloadInterval) * time.Second)
+		defer ticker.Stop()
+		for {
+			select {
+			case <-r.stop:
+				return
+			case <-ticker.C:
+				// 加载失败时继续使用当前的分片表
+				r.reload()
+			}
+		}
+	}()
+}
+
+// Stop 停止重新加载并关闭到其他节点的连接
+func (r *Router) Stop() {
+	close(r.stop)
+	r.wg.Wait()
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	for _, conn := range r.conns {
+		conn.Close()
+	}
+	r.conns = make(map[string]*grpc.ClientConn)
+}
+
+// reload 分片表文件的修改时间变化时重新加载，返回是否已更新
+func (r *Router) reload() (bool, error) {
+	info, err := os.Stat(r.config.MapFile)
+	if err != nil {
+		return false, err
+	}
+
+	r.mu.RLock()
+	unchanged := info.ModTime().Equal(r.modTime) || r.migrating
+	r.mu.RUnlock()
+	if unchanged {
+		return false, nil
+	}
+
+	data, err := os.ReadFile(r.config.MapFile)
+	if err != nil {
+		return false, err
+	}
+	var m shardMap
+	if err := json.Unmarshal(data, &m); err != nil {
+		return false, fmt.Errorf("invalid shard map %s: %v", r.config.MapFile, err)
+	}
+	if err := r.setMap(&m); err != nil {
+		return false, err
+	}
+
+	r.mu.Lock()
+	r.modTime = info.ModTime()
+	r.mu.Unlock()
+	return true, nil
+}
+
+// setMap 替换分片表并关闭已移除节点的连接
+func (r *Router) setMap(m *shardMap) error {
+	if len(m.Nodes) == 0 {
+		return errors.New("shard map has no nodes")
+	}
+
+	ring := client.NewRing(r.config.VirtualNodes)
+	ring.Add(m.Nodes...)
+	var next *client.Ring
+	if len(m.Next) > 0 {
+		next = client.NewRing(r.config.VirtualNodes)
+		next.Add(m.Next...)
+	}
+	moved := make(map[string]bool)
+	for _, addr := range m.Moved {
+		moved[addr] = true
+	}
+	members := resolveMembers(append(append([]string(nil), m.Nodes...), m.Next...))
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	r.ring, r.next, r.moved, r.members = ring, next, moved, members
+	for addr, conn := range r.conns {
+		if !contains(m.Nodes, addr) && !contains(m.Next, addr) {
+			conn.Close()
+			delete(r.conns, addr)
+		}
+	}
+	return nil
+}
+
+// resolveMembers 解析节点地址中的主机名，返回所有节点的IP，无法解析的节点被忽略
+func resolveMembers(addrs []string) map[string]bool {
+	members := make(map[string]bool)
+	for _, addr := range addrs {
+		host, _, err := net.SplitHostPort(addr)
+		if err != nil {
+			continue
+		}
+		if ip := net.ParseIP(host); ip != nil {
+			members[ip.String()] = true
+			continue
+		}
+		ips, err := net.LookupHost(host)
+		if err != nil {
+			continue
+		}
+		for _, ip := range ips {
+			if parsed := net.ParseIP(ip); parsed != nil {
+				members[parsed.String()] = true
+			}
+		}
+	}
+	return members
+}
+
+// fromMember 判断请求是否来自分片表中的节点
+func (r *Router) fromMember(ctx context.Context) bool {
+	p, ok := peer.FromContext(ctx)
+	if !ok || p.Addr == nil {
+		return false
+	}
+	host, _, err := net.SplitHostPort(p.Addr.String())
+	if err != nil {
+		return false
+	}
+	ip := net.ParseIP(host)
+	if ip == nil {
+		return false
+	}
+
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	return r.members[ip.String()]
+}
+
+// owner 返回键所属节点的地址，调用方需持有mu
+func (r *Router) owner(key string) string {
+	addr := r.ring.Get(key)
+	if r.next != nil && r.moved[addr] {
+		addr = r.next.Get(key)
+	}
+	return addr
+}
+
+// Owner 返回键所属节点的地址，以及该节点是否为本节点
+func (r *Router) Owner(key string) (string, bool) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	addr := r.owner(key)
+	return addr, addr == r.config.Self
+}
+
+// Migrated 判断键是否已从本节点交接给其他节点
+func (r *Router) Migrated(key string) bool {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	return r.next != nil && r.moved[r.config.Self] &&
+		r.ring.Get(key) == r.config.Self && r.next.Get(key) != r.config.Self
+}
+
+// leaving 判断本节点拥有的键在目标分片表中是否属于其他节点，返回新的所属节点
+func (r *Router) leaving(next *client.Ring, key string) (string, bool) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	if r.ring.Get(key) != r.config.Self {
+		return "", false
+	}
+	addr := next.Get(key)
+	return addr, addr != r.config.Self
+}
+
+// Hold 开始一次本节点的写入，交接所有权期间等待交接完成
+func (r *Router) Hold() func() {
+	r.writes.RLock()
+	return r.writes.RUnlock
+}
+
+// freeze 等待进行中的写入完成并阻止新的写入，返回的函数恢复写入
+func (r *Router) freeze() func() {
+	r.writes.Lock()
+	return r.writes.Unlock
+}
+
+// prepare 开始迁移到nodes，已有其他目标分片表的迁移时返回错误，返回目标分片表的哈希环
+func (r *Router) prepare(nodes []string) (*client.Ring, error) {
+	next := client.NewRing(r.config.VirtualNodes)
+	next.Add(nodes...)
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if r.migrating {
+		return nil, errors.New("migration is already running")
+	}
+	if r.next != nil && !equalNodes(r.next.Nodes(), next.Nodes()) {
+		return nil, fmt.Errorf("migration to %v is pending, update the shard map first", r.next.Nodes())
+	}
+	if r.next != nil && r.moved[r.config.Self] {
+		return nil, errors.New("keys have already been handed off")
+	}
+	r.next = next
+	r.migrating = true
+	return next, nil
+}
+
+// finish 结束本节点的迁移，之后恢复重新加载分片表文件
+func (r *Router) finish() {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	r.migrating = false
+	if len(r.moved) == 0 {
+		r.next = nil
+	}
+}
+
+// handoff 记录source已将键交接给目标分片表中的节点，并写入分片表文件使重启后保持不变
+func (r *Router) handoff(source string, nodes []string) error {
+	next := client.NewRing(r.config.VirtualNodes)
+	next.Add(nodes...)
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if r.next != nil && !equalNodes(r.next.Nodes(), next.Nodes()) {
+		return fmt.Errorf("migration to %v is pending", r.next.Nodes())
+	}
+	if r.next != nil && r.moved[source] {
+		return nil
+	}
+
+	m := shardMap{Nodes: r.ring.Nodes(), Next: next.Nodes(), Moved: []string{source}}
+	for addr := range r.moved {
+		m.Moved = append(m.Moved, addr)
+	}
+	sort.Strings(m.Moved)
+	if err := r.writeMap(&m); err != nil {
+		return err
+	}
+
+	r.next = next
+	r.moved[source] = true
+	return nil
+}
+
+// writeMap 替换分片表文件，调用方需持有mu，未使用分片表文件时只在内存中生效
+func (r *Router) writeMap(m *shardMap) error {
+	if r.config.MapFile == "" {
+		return nil
+	}
+
+	data, err := json.MarshalIndent(m, "", "  ")
+	if err != nil {
+		return err
+	}
+	tmp := r.config.MapFile + ".tmp"
+	if err := os.WriteFile(tmp, data, 0644); err != nil {
+		return err
+	}
+	if err := os.Rename(tmp, r.config.MapFile); err != nil {
+		return fmt.Errorf("failed to write shard map: %v", err)
+	}
+
+	// 记录修改时间，避免重新加载自己写入的文件
+	info, err := os.Stat(r.config.MapFile)
+	if err != nil {
+		return err
+	}
+	r.modTime = info.ModTime()
+	return nil
+}
+
+// Shard 返回远程节点的操作接口，首次使用时建立连接
+func (r *Router) Shard(addr string) (service.Shard, error) {
+	conn, err := r.conn(addr)
+	if err != nil {
+		return nil, err
+	}
+	return &remoteShard{addr: addr, client: proto.NewKeyValueServiceClient(conn)}, nil
+}
+
+// conn 返回到远程节点的连接，首次使用时建立
+func (r *Router) conn(addr string) (*grpc.ClientConn, error) {
+	r.mu.RLock()
+	conn, ok := r.conns[addr]
+	r.mu.RUnlock()
+	if ok {
+		return conn, nil
+	}
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if conn, ok = r.conns[addr]; !ok {
+		var err error
+		conn, err = grpc.NewClient(addr, grpc.WithTransportCredentials(insecure.NewCredentials()))
+		if err != nil {
+			return nil, fmt.Errorf("failed to connect to shard %s: %v", addr, err)
+		}
+		r.conns[addr] = conn
+	}
+	return conn, nil
+}
+
+// Remotes 返回除本节点外的所有节点
+func (r *Router) Remotes() []string {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	// 有节点完成交接后，目标分片表中的节点也持有数据
+	nodes := r.ring.Nodes()
+	if r.next != nil && len(r.moved) > 0 {
+		for _, addr := range r.next.Nodes() {
+			if !contains(nodes, addr) {
+				nodes = append(nodes, addr)
+			}
+		}
+	}
+
+	var remotes []string
+	for _, addr := range nodes {
+		if addr != r.config.Self {
+			remotes = append(remotes, addr)
+		}
+	}
+	return remotes
+}
+
+// Redirect 是否返回重定向错误而不是转发单键请求
+func (r *Router) Redirect() bool {
+	return r.config.Mode == config.ShardingModeRedirect
+}
+
+// Status 返回分片状态
+func (r *Router) Status() *service.ShardingStatus {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	status := &service.ShardingStatus{
+		Self:  r.config.Self,
+		Mode:  r.config.Mode,
+		Nodes: r.ring.Nodes(),
+	}
+	if r.next != nil {
+		status.Next = r.next.Nodes()
+		for addr := range r.moved {
+			status.Moved = append(status.Moved, addr)
+		}
+		sort.Strings(status.Moved)
+	}
+	return status
+}
+
+// contains 判断列表中是否包含指定地址
+func contains(list []string, addr string) bool {
+	for _, item := range list {
+		if item == addr {
+			return true
+		}
+	}
+	return false
+}
+
+// equalNodes 判断两个已排序的节点列表是否相同
+func equalNodes(a, b []string) bool {
+	if len(a) != len(b) {
+		return false
+	}
+	for i := range a {
+		if a[i] != b[i] {
+			return false
+		}
+	}
+	return true
+}
@@ -0,0 +1,463 @@
+package sharding
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/peer"
+
+	"kvcache/api"
+	"kvcache/config"
+	"kvcache/service"
+	"kvcache/storage"
+)
+
+// writeShardMap 写入分片表文件
+func writeShardMap(t *testing.T, path string, nodes ...string) {
+	data := `{"nodes": [`
+	for i, node := range nodes {
+		if i > 0 {
+			data += ","
+		}
+		data += fmt.Sprintf("%q", node)
+	}
+	data += `]}`
+	if err := os.WriteFile(path, []byte(data), 0644); err != nil {
+		t.Fatalf("Failed to write shard map: %v", err)
+	}
+}
+
+// TestRouterReload 测试分片表文件变化后重新加载
+func TestRouterReload(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "shards.json")
+	writeShardMap(t, path, "a:1", "b:1")
+
+	cfg := config.DefaultConfig().Sharding
+	cfg.Enabled = true
+	cfg.Self = "a:1"
+	cfg.MapFile = path
+
+	r, err := NewRouter(&cfg)
+	if err != nil {
+		t.Fatalf("Failed to create router: %v", err)
+	}
+	defer r.Stop()
+
+	if remotes := r.Remotes(); len(remotes) != 1 || remotes[0] != "b:1" {
+		t.Errorf("Expected remotes [b:1], got %v", remotes)
+	}
+	local := 0
+	for i := 0; i < 1000; i++ {
+		if _, isLocal := r.Owner(fmt.Sprintf("key-%d", i)); isLocal {
+			local++
+		}
+	}
+	if local == 0 || local == 1000 {
+		t.Errorf("Expected keys split between nodes, %d local", local)
+	}
+
+	// 未变化时不重新加载
+	if reloaded, err := r.reload(); err != nil || reloaded {
+		t.Errorf("Expected no reload, got %v: %v", reloaded, err)
+	}
+
+	// 修改后加入新节点
+	writeShardMap(t, path, "a:1", "b:1", "c:1")
+	future := time.Now().Add(time.Second)
+	os.Chtimes(path, future, future)
+	if reloaded, err := r.reload(); err != nil || !reloaded {
+		t.Fatalf("Expected reload, got %v: %v", reloaded, err)
+	}
+	if nodes := r.Status().Nodes; len(nodes) != 3 {
+		t.Errorf("Expected 3 nodes, got %v", nodes)
+	}
+
+	// 无效的分片表保留当前配置
+	os.WriteFile(path, []byte("{"), 0644)
+	future = future.Add(time.Second)
+	os.Chtimes(path, future, future)
+	if _, err := r.reload(); err == nil {
+		t.Errorf("Expected error for invalid shard map")
+	}
+	if nodes := r.Status().Nodes; len(nodes) != 3 {
+		t.Errorf("Expected 3 nodes after failed reload, got %v", nodes)
+	}
+}
+
+// TestRouterHandoff 测试源节点交接后键归目标分片表中的节点所有，并写入分片表文件
+func TestRouterHandoff(t *testing.T) {
+	dir := t.TempDir()
+	newRouter := func(self string) *Router {
+		path := filepath.Join(dir, self+".json")
+		if _, err := os.Stat(path); err != nil {
+			writeShardMap(t, path, "a:1", "b:1")
+		}
+		cfg := config.DefaultConfig().Sharding
+		cfg.Enabled = true
+		cfg.Self = self
+		cfg.MapFile = path
+		r, err := NewRouter(&cfg)
+		if err != nil {
+			t.Fatalf("Failed to create router: %v", err)
+		}
+		return r
+	}
+	r := newRouter("a:1")
+	defer r.Stop()
+
+	nodes := []string{"a:1", "b:1", "c:1"}
+	next, err := r.prepare(nodes)
+	if err != nil {
+		t.Fatalf("Failed to prepare: %v", err)
+	}
+	if _, err := r.prepare(nodes); err == nil {
+		t.Errorf("Expected error for concurrent migration")
+	}
+
+	// 找一个从本节点迁往c:1的键
+	var key string
+	for i := 0; ; i++ {
+		key = fmt.Sprintf("key-%d", i)
+		if addr, ok := r.leaving(next, key); ok && addr == "c:1" {
+			break
+		}
+	}
+	if addr, local := r.Owner(key); !local || r.Migrated(key) {
+		t.Fatalf("Expected %s owned by a:1 before handoff, got %s", key, addr)
+	}
+
+	// 交接后键归c:1所有
+	if err := r.handoff("a:1", nodes); err != nil {
+		t.Fatalf("Failed to handoff: %v", err)
+	}
+	r.finish()
+	if addr, _ := r.Owner(key); addr != "c:1" || !r.Migrated(key) {
+		t.Errorf("Expected %s owned by c:1 after handoff, got %s", key, addr)
+	}
+	if remotes := r.Remotes(); len(remotes) != 2 {
+		t.Errorf("Expected remotes [b:1 c:1], got %v", remotes)
+	}
+	if _, err := r.prepare([]string{"a:1", "d:1"}); err == nil {
+		t.Errorf("Expected error for different pending migration")
+	}
+
+	// 重启后从分片表文件恢复交接状态
+	restarted := newRouter("a:1")
+	defer restarted.Stop()
+	status := restarted.Status()
+	if len(status.Next) != 3 || len(status.Moved) != 1 || status.Moved[0] != "a:1" {
+		t.Errorf("Expected persisted handoff, got %+v", status)
+	}
+	if addr, _ := restarted.Owner(key); addr != "c:1" {
+		t.Errorf("Expected %s owned by c:1 after restart, got %s", key, addr)
+	}
+
+	// 写入最终的分片表后结束迁移
+	writeShardMap(t, restarted.config.MapFile, nodes...)
+	future := time.Now().Add(time.Second)
+	os.Chtimes(restarted.config.MapFile, future, future)
+	if _, err := restarted.reload(); err != nil {
+		t.Fatalf("Failed to reload: %v", err)
+	}
+	if status := restarted.Status(); status.Next != nil || restarted.Migrated(key) {
+		t.Errorf("Expected migration cleared, got %+v", status)
+	}
+}
+
+// testNode 进程内的分片节点
+type testNode struct {
+	addr     string
+	store    storage.Storage
+	service  *service.KVService
+	router   *Router
+	migrator *Migrator
+	server   *grpc.Server
+}
+
+// stop 停止节点
+func (n *testNode) stop() {
+	n.server.Stop()
+	n.migrator.Stop()
+	n.router.Stop()
+	n.store.Stop()
+}
+
+// startNodes 启动n个使用同一分片表的节点
+func startNodes(t *testing.T, n int, mode string) []*testNode {
+	return startNodesWithMap(t, n, n, mode)
+}
+
+// startNodesWithMap 启动n个节点，每个节点的分片表文件只包含前members个节点
+func startNodesWithMap(t *testing.T, n, members int, mode string) []*testNode {
+	var nodes []*testNode
+	var addrs []string
+	var listeners []net.Listener
+	for i := 0; i < n; i++ {
+		lis, err := net.Listen("tcp", "127.0.0.1:0")
+		if err != nil {
+			t.Fatalf("Failed to listen: %v", err)
+		}
+		listeners = append(listeners, lis)
+		addrs = append(addrs, lis.Addr().String())
+	}
+
+	for i, lis := range listeners {
+		dir := t.TempDir()
+		cfg := config.DefaultConfig()
+		cfg.RocksDB.Path = filepath.Join(dir, "data")
+		cfg.Value.DiskPath = filepath.Join(dir, "value_data")
+		cfg.Sharding.Enabled = true
+		cfg.Sharding.Self = addrs[i]
+		cfg.Sharding.MapFile = filepath.Join(dir, "shards.json")
+		cfg.Sharding.Mode = mode
+		writeShardMap(t, cfg.Sharding.MapFile, addrs[:members]...)
+
+		store, err := storage.NewStorage(cfg)
+		if err != nil {
+			t.Fatalf("Failed to create storage: %v", err)
+		}
+		kvService := service.NewKVService(store, cfg)
+		router, err := NewRouter(&cfg.Sharding)
+		if err != nil {
+			t.Fatalf("Failed to create router: %v", err)
+		}
+		kvService.SetRouter(router)
+		migrator := NewMigrator(store, router, kvService.InvalidateChange)
+		kvService.SetMigrator(migrator)
+
+		server := grpc.NewServer(grpc.UnaryInterceptor(UnaryServerInterceptor(router)))
+		api.NewGRPCServer(kvService).Register(server)
+		migrator.Register(server)
+		go server.Serve(lis)
+
+		nodes = append(nodes, &testNode{addr: addrs[i], store: store, service: kvService, router: router, migrator: migrator, server: server})
+	}
+	return nodes
+}
+
+// TestInterceptorForwarded 测试只信任分片表中节点的转发标记，其他调用方的标记被移除
+func TestInterceptorForwarded(t *testing.T) {
+	cfg := config.DefaultConfig().Sharding
+	cfg.Enabled = true
+	cfg.Self = "10.0.0.1:33000"
+	cfg.Nodes = []string{"10.0.0.1:33000", "10.0.0.2:33000"}
+	router, err := NewRouter(&cfg)
+	if err != nil {
+		t.Fatalf("Failed to create router: %v", err)
+	}
+	defer router.Stop()
+
+	call := func(router *Router, ip string) (int, []string) {
+		ctx := metadata.NewIncomingContext(context.Background(), metadata.Pairs(forwardedHeader, "1"))
+		ctx = peer.NewContext(ctx, &peer.Peer{Addr: &net.TCPAddr{IP: net.ParseIP(ip), Port: 40000}})
+		var hops int
+		var header []string
+		UnaryServerInterceptor(router)(ctx, nil, &grpc.UnaryServerInfo{}, func(ctx context.Context, req interface{}) (interface{}, error) {
+			hops = service.Forwards(ctx)
+			md, _ := metadata.FromIncomingContext(ctx)
+			header = md.Get(forwardedHeader)
+			return nil, nil
+		})
+		return hops, header
+	}
+
+	if hops, header := call(router, "10.0.0.2"); hops != 1 || len(header) != 0 {
+		t.Errorf("Expected member request forwarded once without header, got %d %v", hops, header)
+	}
+	if hops, header := call(router, "192.168.1.9"); hops != 0 || len(header) != 0 {
+		t.Errorf("Expected external request handled as not forwarded, got %d %v", hops, header)
+	}
+	if hops, _ := call(nil, "10.0.0.2"); hops != 0 {
+		t.Errorf("Expected forwarded header ignored without sharding, got %d", hops)
+	}
+}
+
+// TestShardedService 测试不属于本节点的键被转发，批量和扫描操作合并所有节点的结果
+func TestShardedService(t *testing.T) {
+	nodes := startNodes(t, 3, config.ShardingModeForward)
+	for _, n := range nodes {
+		defer n.stop()
+	}
+	ctx := context.Background()
+	entry := nodes[0]
+
+	// 1. 通过同一个节点写入，每个键只保存在所属节点
+	kvs := make(map[string][]byte)
+	var keys []string
+	for i := 0; i < 30; i++ {
+		key := fmt.Sprintf("user:%02d", i)
+		keys = append(keys, key)
+		kvs[key] = []byte(key)
+		if err := entry.service.Set(ctx, key, []byte(key), 0); err != nil {
+			t.Fatalf("Failed to set %s: %v", key, err)
+		}
+	}
+	for _, key := range keys {
+		owner, _ := entry.router.Owner(key)
+		for _, n := range nodes {
+			_, found, _ := n.store.Get([]byte(key))
+			if found != (n.addr == owner) {
+				t.Errorf("Key %s on %s: found=%v, owner=%s", key, n.addr, found, owner)
+			}
+		}
+	}
+
+	// 2. 任意节点都能读到
+	for _, n := range nodes {
+		if value, err := n.service.Get(ctx, "user:07"); err != nil || string(value) != "user:07" {
+			t.Errorf("Expected user:07 from %s, got %q: %v", n.addr, value, err)
+		}
+	}
+
+	// 3. 批量操作和扫描合并所有节点
+	values, err := nodes[1].service.MGet(ctx, keys)
+	if err != nil || len(values) != len(keys) {
+		t.Fatalf("Expected %d values, got %d: %v", len(keys), len(values), err)
+	}
+	scanned, err := nodes[2].service.Scan(ctx, "user:", 0)
+	if err != nil || len(scanned) != len(keys) {
+		t.Errorf("Expected %d scanned keys, got %d: %v", len(keys), len(scanned), err)
+	}
+	count, err := entry.service.CountPrefix(ctx, "user:", true)
+	if err != nil || count != int64(len(keys)) {
+		t.Errorf("Expected count %d, got %d: %v", len(keys), count, err)
+	}
+
+	if err := nodes[1].service.MDelete(ctx, keys[:10]); err != nil {
+		t.Fatalf("Failed to mdelete: %v", err)
+	}
+	exists, err := nodes[2].service.MExists(ctx, keys)
+	if err != nil {
+		t.Fatalf("Failed to mexists: %v", err)
+	}
+	for i, key := range keys {
+		if exists[key] != (i >= 10) {
+			t.Errorf("Unexpected existence of %s: %v", key, exists[key])
+		}
+	}
+}
+
+// TestShardedRedirect 测试redirect模式返回所属节点
+func TestShardedRedirect(t *testing.T) {
+	nodes := startNodes(t, 2, config.ShardingModeRedirect)
+	for _, n := range nodes {
+		defer n.stop()
+	}
+	ctx := context.Background()
+
+	// 找一个不属于第一个节点的键
+	var key, owner string
+	for i := 0; ; i++ {
+		key = fmt.Sprintf("key-%d", i)
+		var local bool
+		if owner, local = nodes[0].router.Owner(key); !local {
+			break
+		}
+	}
+
+	err := nodes[0].service.Set(ctx, key, []byte("v"), 0)
+	var redirect *service.RedirectError
+	if !errors.As(err, &redirect) || redirect.Addr != owner {
+		t.Fatalf("Expected redirect to %s, got %v", owner, err)
+	}
+
+	// 客户端按重定向写入所属节点后可以读取
+	if err := nodes[1].service.Set(ctx, key, []byte("v"), 0); err != nil {
+		t.Fatalf("Failed to set on owner: %v", err)
+	}
+	if value, err := nodes[1].service.Get(ctx, key); err != nil || string(value) != "v" {
+		t.Errorf("Expected v, got %q: %v", value, err)
+	}
+
+	// 批量操作仍由收到请求的节点转发
+	if values, err := nodes[0].service.MGet(ctx, []string{key}); err != nil || len(values) != 1 {
+		t.Errorf("Expected mget to fan out, got %v: %v", values, err)
+	}
+}
+
+// TestShardMigration 测试加入新节点后迁移键，迁移期间和完成后任意节点都能读到
+func TestShardMigration(t *testing.T) {
+	nodes := startNodesWithMap(t, 3, 2, config.ShardingModeForward)
+	for _, n := range nodes {
+		defer n.stop()
+	}
+	ctx := context.Background()
+	var addrs []string
+	for _, n := range nodes {
+		addrs = append(addrs, n.addr)
+	}
+
+	// 1. 新节点使用旧的分片表，写入的键都保存在前两个节点
+	var keys []string
+	for i := 0; i < 200; i++ {
+		key := fmt.Sprintf("user:%03d", i)
+		keys = append(keys, key)
+		value := []byte(key)
+		if i%50 == 0 {
+			value = make([]byte, 256*1024) // 大值保存在磁盘
+		}
+		if err := nodes[2].service.Set(ctx, key, value, 0); err != nil {
+			t.Fatalf("Failed to set %s: %v", key, err)
+		}
+	}
+
+	// 2. 两个源节点分别迁移
+	for _, n := range nodes[:2] {
+		if err := n.migrator.Start(addrs, 0); err != nil {
+			t.Fatalf("Failed to start migration on %s: %v", n.addr, err)
+		}
+	}
+	for _, n := range nodes[:2] {
+		deadline := time.Now().Add(30 * time.Second)
+		for {
+			status := n.migrator.Status()
+			if status.State == service.MigrationCompleted {
+				break
+			}
+			if status.State == service.MigrationFailed || time.Now().After(deadline) {
+				t.Fatalf("Migration on %s did not complete: %+v", n.addr, status)
+			}
+			time.Sleep(20 * time.Millisecond)
+		}
+	}
+
+	// 3. 迁出的键只保存在新节点，任意节点都能读到
+	moved := 0
+	for _, key := range keys {
+		owner, _ := nodes[0].router.Owner(key)
+		if owner == nodes[2].addr {
+			moved++
+		}
+		for _, n := range nodes {
+			_, found, _ := n.store.Get([]byte(key))
+			if found != (n.addr == owner) {
+				t.Errorf("Key %s on %s: found=%v, owner=%s", key, n.addr, found, owner)
+			}
+		}
+	}
+	if moved == 0 {
+		t.Fatalf("Expected keys moved to %s", nodes[2].addr)
+	}
+	for _, n := range nodes {
+		values, err := n.service.MGet(ctx, keys)
+		if err != nil || len(values) != len(keys) {
+			t.Errorf("Expected %d values from %s, got %d: %v", len(keys), n.addr, len(values), err)
+		}
+		if value, err := n.service.Get(ctx, "user:050"); err != nil || len(value) != 256*1024 {
+			t.Errorf("Expected large value from %s, got %d bytes: %v", n.addr, len(value), err)
+		}
+	}
+
+	// 4. 已完成交接后不能再次迁移
+	if err := nodes[0].migrator.Start(addrs, 0); err == nil {
+		t.Errorf("Expected error for repeated migration")
+	}
+}
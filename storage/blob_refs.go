@@ -0,0 +1,336 @@
+package storage
+
+import (
+	"encoding/binary"
+	"fmt"
+	"math"
+
+	"kvcache/config"
+
+	gorocksdb "github.com/linxGnu/grocksdb"
+)
+
+const (
+	// BlobRefsCF 磁盘文件引用计数列族
+	BlobRefsCF = "blob_refs"
+	// blobRefsReadyKey 引用计数已初始化的标记，存储在元数据列族
+	blobRefsReadyKey = "blob_refs.ready"
+)
+
+// batchDelta 一次批处理带来的磁盘文件引用计数和键元数据变化
+// 磁盘文件按内容寻址，相同内容的值以及复制出的键会共享同一个文件
+type batchDelta struct {
+	blobs    map[string]int64
+	metas    []metaChange
+	quotaSeq uint64 // 只计入序号不大于该值的配额
+	expire   bool   // 删除由过期引起
+	quiet    bool   // 不生成变更事件，用于范围删除的后台清理
+	// 从主节点复制的变更，不受配额拒绝，按主节点的修订号记录
+	replicated *ChangeEvent
+	// 提交成功后按顺序更新的创建时间索引，提交失败时索引保持不变
+	createTimes []createTimeChange
+}
+
+// metaChange 单个键的元数据变化，nil表示键不存在
+type metaChange struct {
+	key      []byte
+	old, new *KeyMeta
+}
+
+// createTimeChange 创建时间索引的一次修改，meta不为nil时删除键在meta中记录的创建时间，否则记录timestamp
+type createTimeChange struct {
+	key       []byte
+	meta      *KeyMeta
+	timestamp int64
+	drop      bool
+}
+
+// newBatchDelta 创建空的批处理变化
+func newBatchDelta() *batchDelta {
+	return &batchDelta{
+		blobs:    make(map[string]int64),
+		quotaSeq: math.MaxUint64,
+	}
+}
+
+// retain 增加文件引用
+func (d *batchDelta) retain(fileName string) {
+	if fileName != "" {
+		d.blobs[fileName]++
+	}
+}
+
+// release 减少文件引用
+func (d *batchDelta) release(fileName string) {
+	if fileName != "" {
+		d.blobs[fileName]--
+	}
+}
+
+// track 记录键元数据的变化，用于更新配额用量
+func (d *batchDelta) track(key []byte, old, new *KeyMeta) {
+	d.metas = append(d.metas, metaChange{key: key, old: old, new: new})
+}
+
+// indexCreateTime 提交成功后将键记录到创建时间索引
+func (d *batchDelta) indexCreateTime(key []byte, timestamp int64) {
+	d.createTimes = append(d.createTimes, createTimeChange{key: key, timestamp: timestamp})
+}
+
+// unindexCreateTime 提交成功后从创建时间索引中删除键，meta为删除前的元数据
+func (d *batchDelta) unindexCreateTime(key []byte, meta *KeyMeta) {
+	d.createTimes = append(d.createTimes, createTimeChange{key: key, meta: meta, drop: true})
+}
+
+// empty 判断是否没有需要额外处理的变化
+func (d *batchDelta) empty() bool {
+	return len(d.blobs) == 0 && len(d.metas) == 0
+}
+
+// commit 提交批处理，提交成功后更新创建时间索引
+func (s *RocksDBStorage) commit(wb *gorocksdb.WriteBatch, delta *batchDelta) error {
+	if err := s.commitBatch(wb, delta); err != nil {
+		return err
+	}
+	return s.updateCreateTime(delta)
+}
+
+// updateCreateTime 按顺序应用批处理记录的创建时间索引修改
+func (s *RocksDBStorage) updateCreateTime(delta *batchDelta) error {
+	for _, change := range delta.createTimes {
+		var err error
+		if change.drop {
+			err = s.dropCreateTime(change.key, change.meta)
+		} else {
+			err = s.recordCreateTime(change.key, change.timestamp)
+		}
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// commitBatch 将引用计数和配额用量的变化加入批处理并提交，提交成功后删除不再被引用的磁盘文件
+func (s *RocksDBStorage) commitBatch(wb *gorocksdb.WriteBatch, delta *batchDelta) error {
+	s.unindexEvicted(wb, delta)
+	if delta.empty() {
+		return s.db.Write(s.writeOpts, wb)
+	}
+
+	// 只有元数据变化且没有配额时无需串行化，设置配额需要写锁，保证统计与写入有确定的先后顺序
+	if len(delta.blobs) == 0 {
+		s.blobMu.RLock()
+		if !s.quotas.active() {
+			defer s.blobMu.RUnlock()
+			return s.write(wb, delta.events())
+		}
+		s.blobMu.RUnlock()
+	}
+
+	// 持有锁直到删除文件，避免并发的批处理基于过期的计数
+	s.blobMu.Lock()
+	defer s.blobMu.Unlock()
+
+	// 1. 计算配额用量，超出限制时拒绝写入
+	updates, err := s.quotas.prepare(delta)
+	if err != nil {
+		s.discardBlobs(delta)
+		return err
+	}
+	for _, update := range updates {
+		if err := s.putQuota(wb, update.QuotaConfig, update.usage); err != nil {
+			return err
+		}
+	}
+
+	// 2. 计算新的引用计数
+	var orphans []string
+	for fileName, change := range delta.blobs {
+		if change == 0 {
+			continue
+		}
+
+		count, err := s.blobRefCount(fileName)
+		if err != nil {
+			return err
+		}
+
+		// 文件可能在写入后、提交前被回收
+		if count == 0 && change > 0 {
+			if _, err := s.diskStore.Size(fileName); err != nil {
+				return fmt.Errorf("disk file %s was reclaimed concurrently: %v", fileName, err)
+			}
+		}
+
+		count += change
+		if count <= 0 {
+			wb.DeleteCF(s.blobRefsCF, []byte(fileName))
+			orphans = append(orphans, fileName)
+		} else {
+			wb.PutCF(s.blobRefsCF, []byte(fileName), encodeRefCount(count))
+		}
+	}
+
+	// 3. 提交批处理
+	if err := s.write(wb, delta.events()); err != nil {
+		return err
+	}
+	s.quotas.apply(updates)
+
+	// 4. 删除不再被引用的文件
+	for _, fileName := range orphans {
+		s.diskStore.Delete(fileName)
+	}
+
+	// 5. 超出限制且配置为淘汰的配额，在后台淘汰键，从节点等待主节点复制淘汰结果
+	if delta.replicated != nil {
+		return nil
+	}
+	for _, update := range updates {
+		if update.Action == config.QuotaActionEvict && update.exceeded(update.usage) {
+			s.startQuotaEviction(update.quota)
+		}
+	}
+
+	return nil
+}
+
+// discardBlobs 写入被拒绝时删除本次新写入且未被引用的磁盘文件，调用方需持有blobMu
+func (s *RocksDBStorage) discardBlobs(delta *batchDelta) {
+	for fileName, change := range delta.blobs {
+		if change <= 0 {
+			continue
+		}
+		if count, err := s.blobRefCount(fileName); err == nil && count == 0 {
+			s.diskStore.Delete(fileName)
+		}
+	}
+}
+
+// blobRefCount 读取文件的引用计数，不存在时返回0
+func (s *RocksDBStorage) blobRefCount(fileName string) (int64, error) {
+	value, err := s.db.GetCF(s.readOpts, s.blobRefsCF, []byte(fileName))
+	if err != nil {
+		return 0, err
+	}
+	defer value.Free()
+
+	if value.Size() != 8 {
+		return 0, nil
+	}
+	return int64(binary.BigEndian.Uint64(value.Data())), nil
+}
+
+// initBlobRefs 首次启动时根据已存储的磁盘指针重建引用计数
+func (s *RocksDBStorage) initBlobRefs() error {
+	// 1. 检查是否已初始化
+	ready, err := s.db.GetCF(s.readOpts, s.metadataCF, []byte(blobRefsReadyKey))
+	if err != nil {
+		return err
+	}
+	initialized := ready.Size() > 0
+	ready.Free()
+	if initialized {
+		return nil
+	}
+
+	// 2. 遍历所有值，统计磁盘文件的引用
+	counts, err := s.countBlobRefs()
+	if err != nil {
+		return fmt.Errorf("failed to rebuild blob refs: %v", err)
+	}
+
+	// 3. 写入引用计数和初始化标记
+	wb := gorocksdb.NewWriteBatch()
+	defer wb.Destroy()
+
+	for fileName, count := range counts {
+		wb.PutCF(s.blobRefsCF, []byte(fileName), encodeRefCount(count))
+	}
+	wb.PutCF(s.metadataCF, []byte(blobRefsReadyKey), []byte("1"))
+
+	return s.db.Write(s.writeOpts, wb)
+}
+
+// countBlobRefs 遍历所有值，统计磁盘文件的引用
+func (s *RocksDBStorage) countBlobRefs() (map[string]int64, error) {
+	iter := s.db.NewIteratorCF(s.readOpts, s.defaultCF)
+	defer iter.Close()
+
+	counts := make(map[string]int64)
+	for iter.SeekToFirst(); iter.Valid(); iter.Next() {
+		if env, err := decodeEnvelope(iter.Value().Data()); err == nil && env.Type == valueDisk {
+			counts[env.diskFile()]++
+		}
+	}
+	return counts, iter.Err()
+}
+
+// rebuildBlobRefs 按已存储的磁盘指针修正引用计数，删除不再被引用的文件，返回释放的引用数量
+// 用于恢复中断的范围删除，此时被删除的键仍持有引用
+func (s *RocksDBStorage) rebuildBlobRefs() (int64, error) {
+	s.blobMu.Lock()
+	defer s.blobMu.Unlock()
+
+	// 1. 统计实际的引用
+	counts, err := s.countBlobRefs()
+	if err != nil {
+		return 0, err
+	}
+
+	// 2. 与已记录的引用计数比较
+	wb := gorocksdb.NewWriteBatch()
+	defer wb.Destroy()
+
+	var released int64
+	var orphans []string
+	iter := s.db.NewIteratorCF(s.readOpts, s.blobRefsCF)
+	for iter.SeekToFirst(); iter.Valid(); iter.Next() {
+		if iter.Value().Size() != 8 {
+			continue
+		}
+		fileName := string(iter.Key().Data())
+		recorded := int64(binary.BigEndian.Uint64(iter.Value().Data()))
+		count := counts[fileName]
+		delete(counts, fileName)
+		if count == recorded {
+			continue
+		}
+
+		released += recorded - count
+		if count == 0 {
+			wb.DeleteCF(s.blobRefsCF, []byte(fileName))
+			orphans = append(orphans, fileName)
+		} else {
+			wb.PutCF(s.blobRefsCF, []byte(fileName), encodeRefCount(count))
+		}
+	}
+	err = iter.Err()
+	iter.Close()
+	if err != nil {
+		return 0, err
+	}
+
+	// 没有记录的引用补充计数
+	for fileName, count := range counts {
+		wb.PutCF(s.blobRefsCF, []byte(fileName), encodeRefCount(count))
+	}
+
+	// 3. 提交后删除不再被引用的文件
+	if err := s.db.Write(s.writeOpts, wb); err != nil {
+		return 0, err
+	}
+	for _, fileName := range orphans {
+		s.diskStore.Delete(fileName)
+	}
+
+	return released, nil
+}
+
+// encodeRefCount 编码引用计数
+func encodeRefCount(count int64) []byte {
+	buf := make([]byte, 8)
+	binary.BigEndian.PutUint64(buf, uint64(count))
+	return buf
+}
@@ -0,0 +1,509 @@
+package storage
+
+import (
+	"bytes"
+	"encoding/binary"
+	"encoding/json"
+	"fmt"
+	"sync"
+	"time"
+
+	gorocksdb "github.com/linxGnu/grocksdb"
+)
+
+const (
+	// ChangeLogCF 变更日志列族，键为8字节大端序的修订号
+	ChangeLogCF = "changelog"
+	// checkpointKeyPrefix 变更日志消费者检查点的键前缀，存储在元数据列族
+	checkpointKeyPrefix = "changelog.checkpoint."
+
+	// EventPut 写入或修改键
+	EventPut = "put"
+	// EventDelete 删除键
+	EventDelete = "delete"
+	// EventExpire 键过期后被删除
+	EventExpire = "expire"
+	// EventEvict 磁盘值被淘汰，键仍然保留
+	EventEvict = "evict"
+	// EventDeleteRange 删除[Key, End)范围内的所有键
+	EventDeleteRange = "delete_range"
+
+	// changeLogTrimInterval 变更日志超出保留数量多少条后裁剪一次
+	changeLogTrimInterval = 1000
+	// watcherBufferSize 订阅者的事件缓冲区大小，写满时订阅被中断
+	watcherBufferSize = 1024
+)
+
+var (
+	// ErrCompacted 请求的修订号已从变更日志中裁剪
+	ErrCompacted = NewError(CodeOutOfRange, "revision compacted")
+	// ErrWatchLagging 订阅者消费过慢，缓冲区已满，可从最后收到的修订号重新订阅
+	ErrWatchLagging = NewError(CodeUnavailable, "watcher is too slow to keep up with changes")
+)
+
+// ChangeEvent 一次键变更，修订号在所有命名空间内全局递增
+type ChangeEvent struct {
+	Revision  uint64 `json:"revision"`
+	Type      string `json:"type"`
+	Namespace string `json:"namespace"`
+	Key       []byte `json:"key"`
+	End       []byte `json:"end,omitempty"`     // 仅delete_range使用，nil表示不设上界
+	Version   uint64 `json:"version,omitempty"` // 写入后的版本号
+	Size      int64  `json:"size,omitempty"`    // 写入后的值大小
+	Time      int64  `json:"time"`              // 提交时间（Unix毫秒）
+}
+
+// matches 判断事件是否属于命名空间内的前缀
+func (e *ChangeEvent) matches(namespace string, prefix []byte) bool {
+	if e.Namespace != namespace {
+		return false
+	}
+	if e.Type != EventDeleteRange {
+		return bytes.HasPrefix(e.Key, prefix)
+	}
+
+	// 范围删除与前缀范围相交即匹配
+	end := prefixEnd(prefix)
+	return (end == nil || bytes.Compare(e.Key, end) < 0) &&
+		(e.End == nil || bytes.Compare(prefix, e.End) < 0)
+}
+
+// Watcher 前缀变更订阅，Events关闭后可通过Err获取中断原因
+type Watcher struct {
+	Events <-chan ChangeEvent
+
+	out       chan ChangeEvent
+	live      chan ChangeEvent
+	done      chan struct{}
+	once      sync.Once
+	namespace string
+	prefix    []byte
+	from      uint64
+	err       error
+	errMu     sync.Mutex
+}
+
+// Close 取消订阅
+func (w *Watcher) Close() {
+	w.once.Do(func() {
+		close(w.done)
+	})
+}
+
+// Err 返回订阅中断的原因，主动取消时返回nil
+func (w *Watcher) Err() error {
+	w.errMu.Lock()
+	defer w.errMu.Unlock()
+	return w.err
+}
+
+// fail 记录中断原因并关闭实时事件通道，调用方需持有changeLog.mu
+func (w *Watcher) fail(err error) {
+	w.errMu.Lock()
+	w.err = err
+	w.errMu.Unlock()
+	close(w.live)
+}
+
+// changeLog 所有命名空间共享的变更日志
+type changeLog struct {
+	cf        *gorocksdb.ColumnFamilyHandle
+	mu        sync.Mutex
+	rev       uint64 // 最新的修订号
+	compacted uint64 // 已裁剪的最大修订号
+	retention uint64
+	follower  bool // 从节点只接受带有主节点修订号的变更
+	watchers  map[*Watcher]struct{}
+	// 消费者已处理的修订号，裁剪不会越过最小的检查点
+	checkpoints map[string]uint64
+	stop        chan struct{}
+	once        sync.Once
+	wg          sync.WaitGroup
+}
+
+// newChangeLog 创建变更日志
+func newChangeLog() *changeLog {
+	return &changeLog{
+		watchers:    make(map[*Watcher]struct{}),
+		checkpoints: make(map[string]uint64),
+		stop:        make(chan struct{}),
+	}
+}
+
+// close 中断所有订阅并等待其退出
+func (l *changeLog) close() {
+	l.once.Do(func() {
+		close(l.stop)
+	})
+
+	l.mu.Lock()
+	for w := range l.watchers {
+		delete(l.watchers, w)
+		w.fail(ErrStopped)
+	}
+	l.mu.Unlock()
+
+	l.wg.Wait()
+}
+
+// publish 将已提交的事件分发给订阅者，调用方需持有mu
+func (l *changeLog) publish(events []ChangeEvent) {
+	for w := range l.watchers {
+		for i := range events {
+			if events[i].Revision < w.from || !events[i].matches(w.namespace, w.prefix) {
+				continue
+			}
+			select {
+			case w.live <- events[i]:
+				continue
+			default:
+			}
+			delete(l.watchers, w)
+			w.fail(ErrWatchLagging)
+			break
+		}
+	}
+}
+
+// trimTarget 返回可以裁剪到的修订号，调用方需持有mu
+func (l *changeLog) trimTarget() uint64 {
+	if l.rev <= l.retention {
+		return 0
+	}
+	target := l.rev - l.retention
+	for _, checkpoint := range l.checkpoints {
+		if checkpoint < target {
+			target = checkpoint
+		}
+	}
+	return target
+}
+
+// remove 移除订阅
+func (l *changeLog) remove(w *Watcher) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	delete(l.watchers, w)
+}
+
+// encodeRevision 编码修订号
+func encodeRevision(rev uint64) []byte {
+	buf := make([]byte, 8)
+	binary.BigEndian.PutUint64(buf, rev)
+	return buf
+}
+
+// initChangeLog 从变更日志中恢复最新和已裁剪的修订号
+func (s *RocksDBStorage) initChangeLog() error {
+	l := s.changes
+	l.retention = uint64(s.config.ChangeLog.Retention)
+	if l.retention == 0 {
+		l.retention = 1
+	}
+
+	iter := s.db.NewIteratorCF(s.readOpts, l.cf)
+	defer iter.Close()
+
+	iter.SeekToLast()
+	if iter.Valid() {
+		l.rev = binary.BigEndian.Uint64(iter.Key().Data())
+	}
+	iter.SeekToFirst()
+	if iter.Valid() {
+		l.compacted = binary.BigEndian.Uint64(iter.Key().Data()) - 1
+	} else {
+		l.compacted = l.rev
+	}
+
+	if err := iter.Err(); err != nil {
+		return fmt.Errorf("failed to load changelog: %v", err)
+	}
+
+	// 加载消费者检查点
+	prefix := []byte(checkpointKeyPrefix)
+	metaIter := s.db.NewIteratorCF(s.readOpts, s.metadataCF)
+	defer metaIter.Close()
+	for metaIter.Seek(prefix); metaIter.Valid(); metaIter.Next() {
+		key := metaIter.Key().Data()
+		if !bytes.HasPrefix(key, prefix) {
+			break
+		}
+		if metaIter.Value().Size() == 8 {
+			l.checkpoints[string(key[len(prefix):])] = binary.BigEndian.Uint64(metaIter.Value().Data())
+		}
+	}
+	return metaIter.Err()
+}
+
+// events 根据键元数据的变化生成变更事件
+func (d *batchDelta) events() []ChangeEvent {
+	if d.quiet {
+		return nil
+	}
+	if d.replicated != nil {
+		return []ChangeEvent{*d.replicated}
+	}
+
+	events := make([]ChangeEvent, 0, len(d.metas))
+	for _, m := range d.metas {
+		event := ChangeEvent{Key: m.key}
+		switch {
+		case m.new == nil && d.expire:
+			event.Type = EventExpire
+		case m.new == nil:
+			event.Type = EventDelete
+		case m.new.Evicted && (m.old == nil || !m.old.Evicted):
+			event.Type = EventEvict
+		default:
+			event.Type = EventPut
+		}
+		if m.new != nil {
+			event.Version = m.new.Version
+			event.Size = m.new.Size
+		}
+		events = append(events, event)
+	}
+	return events
+}
+
+// write 为事件分配修订号并与批处理一起提交，提交成功后按修订号顺序通知订阅者
+func (s *RocksDBStorage) write(wb *gorocksdb.WriteBatch, events []ChangeEvent) error {
+	if len(events) == 0 {
+		return s.db.Write(s.writeOpts, wb)
+	}
+
+	l := s.changes
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	// 从节点复制的变更沿用主节点的修订号
+	replicated := events[0].Revision != 0
+	if l.follower && !replicated {
+		return ErrReadOnly
+	}
+
+	// 1. 分配修订号并写入变更日志
+	now := time.Now().UnixMilli()
+	rev := l.rev
+	for i := range events {
+		if replicated {
+			if events[i].Revision <= rev {
+				return fmt.Errorf("revision %d is not after %d", events[i].Revision, rev)
+			}
+			rev = events[i].Revision
+		} else {
+			rev++
+			events[i].Revision = rev
+			events[i].Namespace = s.namespace.Name
+			events[i].Time = now
+		}
+
+		data, err := json.Marshal(&events[i])
+		if err != nil {
+			return err
+		}
+		wb.PutCF(l.cf, encodeRevision(rev), data)
+	}
+
+	// 2. 提交
+	if err := s.db.Write(s.writeOpts, wb); err != nil {
+		return err
+	}
+	l.rev = rev
+
+	// 3. 通知订阅者
+	l.publish(events)
+
+	// 4. 裁剪超出保留数量且所有消费者都已处理的日志，失败时下次重试
+	if compacted := l.trimTarget(); compacted >= l.compacted+changeLogTrimInterval {
+		trim := gorocksdb.NewWriteBatch()
+		defer trim.Destroy()
+		trim.DeleteRangeCF(l.cf, encodeRevision(l.compacted+1), encodeRevision(compacted+1))
+		if err := s.db.Write(s.writeOpts, trim); err == nil {
+			l.compacted = compacted
+		}
+	}
+
+	return nil
+}
+
+// Watch 订阅当前命名空间内前缀下的变更，fromRevision为0时只接收之后的变更
+func (s *RocksDBStorage) Watch(prefix []byte, fromRevision uint64) (*Watcher, error) {
+	l := s.changes
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	select {
+	case <-l.stop:
+		return nil, ErrStopped
+	default:
+	}
+
+	// 1. 检查修订号是否仍在保留范围内
+	if fromRevision > 0 && fromRevision <= l.compacted {
+		return nil, fmt.Errorf("%w: revision %d, oldest available is %d", ErrCompacted, fromRevision, l.compacted+1)
+	}
+
+	// 2. 先注册，保证回放与实时事件之间没有遗漏
+	out := make(chan ChangeEvent)
+	w := &Watcher{
+		Events:    out,
+		out:       out,
+		live:      make(chan ChangeEvent, watcherBufferSize),
+		done:      make(chan struct{}),
+		namespace: s.namespace.Name,
+		prefix:    append([]byte(nil), prefix...),
+		from:      l.rev + 1,
+	}
+	l.watchers[w] = struct{}{}
+
+	// 3. 后台回放历史事件，再转发实时事件
+	replayFrom := fromRevision
+	if fromRevision == 0 || fromRevision > l.rev {
+		replayFrom = 0
+		if fromRevision > w.from {
+			w.from = fromRevision
+		}
+	}
+	current := l.rev
+	l.wg.Add(1)
+	go func() {
+		defer l.wg.Done()
+		defer close(out)
+		defer l.remove(w)
+		if replayFrom > 0 && !s.replay(w, replayFrom, current) {
+			return
+		}
+		s.forward(w)
+	}()
+
+	return w, nil
+}
+
+// replay 回放[from, to]范围内的历史事件，订阅被取消时返回false
+func (s *RocksDBStorage) replay(w *Watcher, from, to uint64) bool {
+	readOpts := gorocksdb.NewDefaultReadOptions()
+	defer readOpts.Destroy()
+	readOpts.SetIterateUpperBound(encodeRevision(to + 1))
+
+	iter := s.db.NewIteratorCF(readOpts, s.changes.cf)
+	defer iter.Close()
+
+	for iter.Seek(encodeRevision(from)); iter.Valid(); iter.Next() {
+		var event ChangeEvent
+		if err := json.Unmarshal(iter.Value().Data(), &event); err != nil {
+			continue
+		}
+		if !event.matches(w.namespace, w.prefix) {
+			continue
+		}
+		if !w.send(event, s.changes.stop) {
+			return false
+		}
+	}
+	return true
+}
+
+// forward 转发实时事件，直到订阅被取消或中断
+func (s *RocksDBStorage) forward(w *Watcher) {
+	for {
+		select {
+		case event, ok := <-w.live:
+			if !ok {
+				return
+			}
+			if !w.send(event, s.changes.stop) {
+				return
+			}
+		case <-w.done:
+			return
+		}
+	}
+}
+
+// send 发送事件，订阅被取消或存储停止时返回false
+func (w *Watcher) send(event ChangeEvent, stop <-chan struct{}) bool {
+	select {
+	case w.out <- event:
+		return true
+	case <-w.done:
+		return false
+	case <-stop:
+		return false
+	}
+}
+
+// ReadChanges 从fromRevision开始按修订号顺序读取最多limit条变更，fromRevision为0时从最早保留的变更开始
+// 默认命名空间返回所有命名空间的变更，其他命名空间只返回自身的变更
+func (s *RocksDBStorage) ReadChanges(fromRevision uint64, limit int) ([]ChangeEvent, error) {
+	// 1. 在锁内检查修订号并创建迭代器，迭代器持有的隐式快照不受之后的裁剪影响
+	l := s.changes
+	l.mu.Lock()
+	if fromRevision > 0 && fromRevision <= l.compacted {
+		l.mu.Unlock()
+		return nil, fmt.Errorf("%w: revision %d, oldest available is %d", ErrCompacted, fromRevision, l.compacted+1)
+	}
+	if fromRevision == 0 {
+		fromRevision = l.compacted + 1
+	}
+	iter := s.db.NewIteratorCF(s.readOpts, l.cf)
+	l.mu.Unlock()
+	defer iter.Close()
+
+	// 2. 顺序读取
+	var events []ChangeEvent
+	for iter.Seek(encodeRevision(fromRevision)); iter.Valid() && len(events) < limit; iter.Next() {
+		var event ChangeEvent
+		if err := json.Unmarshal(iter.Value().Data(), &event); err != nil {
+			return nil, fmt.Errorf("failed to decode change %d: %v", binary.BigEndian.Uint64(iter.Key().Data()), err)
+		}
+		if s.isRoot() || event.Namespace == s.namespace.Name {
+			events = append(events, event)
+		}
+	}
+
+	return events, iter.Err()
+}
+
+// Checkpoint 读取消费者已处理的修订号
+func (s *RocksDBStorage) Checkpoint(name string) (uint64, bool, error) {
+	s.changes.mu.Lock()
+	defer s.changes.mu.Unlock()
+
+	revision, ok := s.changes.checkpoints[name]
+	return revision, ok, nil
+}
+
+// SaveCheckpoint 保存消费者已处理的修订号，之后的变更在消费者处理前不会被裁剪
+func (s *RocksDBStorage) SaveCheckpoint(name string, revision uint64) error {
+	if name == "" {
+		return NewError(CodeInvalidArgument, "checkpoint name cannot be empty")
+	}
+
+	l := s.changes
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	// 检查点落后于已裁剪的位置时，之间的变更已无法保留
+	if revision < l.compacted {
+		return fmt.Errorf("%w: checkpoint %d is before oldest available revision %d", ErrCompacted, revision, l.compacted+1)
+	}
+	if err := s.db.PutCF(s.writeOpts, s.metadataCF, []byte(checkpointKeyPrefix+name), encodeRevision(revision)); err != nil {
+		return err
+	}
+	l.checkpoints[name] = revision
+	return nil
+}
+
+// DeleteCheckpoint 删除消费者检查点，不再为其保留变更日志
+func (s *RocksDBStorage) DeleteCheckpoint(name string) error {
+	l := s.changes
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	if err := s.db.DeleteCF(s.writeOpts, s.metadataCF, []byte(checkpointKeyPrefix+name)); err != nil {
+		return err
+	}
+	delete(l.checkpoints, name)
+	return nil
+}
@@ -0,0 +1,48 @@
+package storage
+
+import (
+	"context"
+	"time"
+
+	gorocksdb "github.com/linxGnu/grocksdb"
+)
+
+// 取消与超时：带Context后缀的读取在ctx取消或超时后停止并返回ctx.Err()，
+// 遍历每cancelCheckInterval个键检查一次ctx，ctx的截止时间同时作为RocksDB迭代器的读取截止时间，
+// 磁盘存储的值按块读取，块之间检查ctx。不带Context后缀的方法不会被取消。
+
+// cancelCheckInterval 遍历时检查ctx的间隔键数
+const cancelCheckInterval = 256
+
+// iterReadOptions 创建遍历使用的读取选项，ctx带有截止时间时设置为RocksDB的读取截止时间，调用方负责释放
+func iterReadOptions(ctx context.Context) *gorocksdb.ReadOptions {
+	opts := gorocksdb.NewDefaultReadOptions()
+	if deadline, ok := ctx.Deadline(); ok {
+		// RocksDB的截止时间为Unix微秒，0表示不限制
+		opts.SetDeadline(uint64(max(deadline.UnixMicro(), 1)))
+	}
+	return opts
+}
+
+// checkContext 每遍历cancelCheckInterval个键检查一次ctx，n为已遍历的键数
+func checkContext(ctx context.Context, n int) error {
+	if n%cancelCheckInterval != 0 {
+		return nil
+	}
+	return ctx.Err()
+}
+
+// iterErr 返回迭代器的错误，达到读取截止时间或ctx已取消导致的错误返回对应的ctx错误
+func iterErr(ctx context.Context, iter *gorocksdb.Iterator) error {
+	err := iter.Err()
+	if err == nil {
+		return nil
+	}
+	if deadline, ok := ctx.Deadline(); ok && !time.Now().Before(deadline) {
+		return context.DeadlineExceeded
+	}
+	if ctxErr := ctx.Err(); ctxErr != nil {
+		return ctxErr
+	}
+	return err
+}
@@ -0,0 +1,202 @@
+package storage
+
+import (
+	"bytes"
+	"context"
+	"time"
+
+	gorocksdb "github.com/linxGnu/grocksdb"
+)
+
+// PrefixSize 前缀下键的容量统计
+type PrefixSize struct {
+	Keys        int64 // 键数量
+	InlineBytes int64 // 内联存储的字节数
+	DiskBytes   int64 // 磁盘存储的字节数
+}
+
+// TotalBytes 返回总字节数
+func (p *PrefixSize) TotalBytes() int64 {
+	return p.InlineBytes + p.DiskBytes
+}
+
+// Exists 判断键是否存在，只读取元数据，已过期或已淘汰的键视为不存在
+func (s *RocksDBStorage) Exists(key []byte) (bool, error) {
+	meta, err := s.loadMeta(key)
+	if err != nil {
+		return false, err
+	}
+
+	if meta == nil {
+		// 旧数据没有元数据，根据存储的值推导（磁盘存储的值只读取指针）
+		meta, err = s.deriveMeta(key)
+		if err != nil || meta == nil {
+			return false, err
+		}
+	}
+
+	return !meta.Evicted && !meta.Expired(time.Now()), nil
+}
+
+// MExists 批量判断键是否存在
+func (s *RocksDBStorage) MExists(keys [][]byte) (map[string]bool, error) {
+	results := make(map[string]bool, len(keys))
+
+	for _, key := range keys {
+		exists, err := s.Exists(key)
+		if err != nil {
+			return nil, err
+		}
+		results[string(key)] = exists
+	}
+
+	return results, nil
+}
+
+// CountPrefix 统计前缀下的键数量
+// exact为true时遍历元数据精确统计，否则根据RocksDB属性估算
+func (s *RocksDBStorage) CountPrefix(prefix []byte, exact bool) (int64, error) {
+	return s.CountPrefixContext(context.Background(), prefix, exact)
+}
+
+// CountPrefixContext 统计前缀下的键数量，精确统计时ctx取消或超时后停止遍历
+func (s *RocksDBStorage) CountPrefixContext(ctx context.Context, prefix []byte, exact bool) (int64, error) {
+	if !exact {
+		if count, ok := s.estimateCount(prefix); ok {
+			return count, nil
+		}
+	}
+
+	var count int64
+	err := s.iterateMeta(ctx, prefix, func(key []byte, meta *KeyMeta) {
+		count++
+	})
+	if err != nil {
+		return 0, err
+	}
+
+	return count, nil
+}
+
+// SizeOf 统计前缀下键的数量和容量，只读取元数据
+func (s *RocksDBStorage) SizeOf(prefix []byte) (*PrefixSize, error) {
+	return s.SizeOfContext(context.Background(), prefix)
+}
+
+// SizeOfContext 统计前缀下键的数量和容量，ctx取消或超时后停止遍历
+func (s *RocksDBStorage) SizeOfContext(ctx context.Context, prefix []byte) (*PrefixSize, error) {
+	size := &PrefixSize{}
+	err := s.iterateMeta(ctx, prefix, func(key []byte, meta *KeyMeta) {
+		size.Keys++
+		if meta.Location == LocationDisk {
+			size.DiskBytes += meta.Size
+		} else {
+			size.InlineBytes += meta.Size
+		}
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return size, nil
+}
+
+// iterateMeta 遍历前缀下未过期且未淘汰的键的元数据，ctx取消或超时后返回ctx.Err()
+// 值和元数据按相同的键顺序同时遍历，没有元数据的旧数据与Exists一样根据存储的值推导
+func (s *RocksDBStorage) iterateMeta(ctx context.Context, prefix []byte, fn func(key []byte, meta *KeyMeta)) error {
+	readOpts := iterReadOptions(ctx)
+	defer readOpts.Destroy()
+	if end := prefixEnd(prefix); end != nil {
+		readOpts.SetIterateUpperBound(end)
+	}
+
+	iter := s.db.NewIteratorCF(readOpts, s.defaultCF)
+	defer iter.Close()
+	metaIter := s.db.NewIteratorCF(readOpts, s.keyMetaCF)
+	defer metaIter.Close()
+	metaIter.Seek(prefix)
+
+	now := time.Now()
+	n := 0
+	for iter.Seek(prefix); iter.Valid(); iter.Next() {
+		if err := checkContext(ctx, n); err != nil {
+			return err
+		}
+		n++
+
+		key := iter.Key().Data()
+		if !bytes.HasPrefix(key, prefix) {
+			break
+		}
+		// 跳过保留键
+		if IsReservedKey(key) {
+			continue
+		}
+
+		for metaIter.Valid() && bytes.Compare(metaIter.Key().Data(), key) < 0 {
+			metaIter.Next()
+		}
+		var meta *KeyMeta
+		var err error
+		if metaIter.Valid() && bytes.Equal(metaIter.Key().Data(), key) {
+			meta, err = decodeKeyMeta(metaIter.Value().Data())
+		} else {
+			meta, err = s.deriveMeta(key)
+		}
+		if err != nil || meta == nil {
+			continue // 跳过损坏的元数据
+		}
+		if meta.Evicted || meta.Expired(now) {
+			continue
+		}
+		fn(key, meta)
+	}
+
+	if err := iterErr(ctx, metaIter); err != nil {
+		return err
+	}
+	return iterErr(ctx, iter)
+}
+
+// estimateCount 根据RocksDB属性估算前缀下的键数量，无法估算时返回false
+func (s *RocksDBStorage) estimateCount(prefix []byte) (int64, bool) {
+	// 1. 获取列族的估算键数量
+	total, ok := s.db.GetIntPropertyCF("rocksdb.estimate-num-keys", s.keyMetaCF)
+	if !ok {
+		return 0, false
+	}
+	if len(prefix) == 0 {
+		return int64(total), true
+	}
+
+	// 2. 按前缀范围占SST文件总大小的比例估算
+	end := prefixEnd(prefix)
+	if end == nil {
+		return 0, false
+	}
+	fileSize, ok := s.db.GetIntPropertyCF("rocksdb.total-sst-files-size", s.keyMetaCF)
+	if !ok || fileSize == 0 {
+		// 数据还在内存表中，无法估算
+		return 0, false
+	}
+	sizes, err := s.db.GetApproximateSizesCF(s.keyMetaCF, []gorocksdb.Range{{Start: prefix, Limit: end}})
+	if err != nil || len(sizes) == 0 {
+		return 0, false
+	}
+
+	ratio := float64(sizes[0]) / float64(fileSize)
+	return int64(ratio*float64(total) + 0.5), true
+}
+
+// prefixEnd 返回前缀范围的上界（不包含），前缀全为0xff或为空时返回nil
+func prefixEnd(prefix []byte) []byte {
+	end := make([]byte, len(prefix))
+	copy(end, prefix)
+	for i := len(end) - 1; i >= 0; i-- {
+		if end[i] < 0xff {
+			end[i]++
+			return end[:i+1]
+		}
+	}
+	return nil
+}
@@ -0,0 +1,513 @@
+package storage
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"strconv"
+	"sync"
+	"time"
+
+	gorocksdb "github.com/linxGnu/grocksdb"
+)
+
+const (
+	// DeleteJobRunning 清理进行中
+	DeleteJobRunning = "running"
+	// DeleteJobDone 清理完成
+	DeleteJobDone = "done"
+	// DeleteJobFailed 清理失败
+	DeleteJobFailed = "failed"
+
+	// deleteJobBatchSize 每清理多少个键提交一次磁盘文件引用并更新进度
+	deleteJobBatchSize = 1000
+	// maxFinishedDeleteJobs 保留的已结束任务数量
+	maxFinishedDeleteJobs = 100
+	// deleteJobKeyPrefix 未完成的清理任务在元数据列族中的键前缀，后接命名空间和任务ID，值为任务进度
+	// 记录与范围删除在同一批处理中写入，清理完成后删除，启动时仍存在的记录表示清理被中断
+	deleteJobKeyPrefix = "deletejob."
+)
+
+// DeleteJob 范围删除任务的进度
+type DeleteJob struct {
+	ID            string    `json:"id"`
+	Start         []byte    `json:"start"`
+	End           []byte    `json:"end,omitempty"` // 不包含，nil表示不设上界
+	State         string    `json:"state"`
+	KeysDeleted   int64     `json:"keys_deleted"`   // 已清理元数据和索引的键数量
+	BlobsReleased int64     `json:"blobs_released"` // 已释放的磁盘文件引用数量
+	StartedAt     time.Time `json:"started_at"`
+	FinishedAt    time.Time `json:"finished_at,omitempty"`
+	Error         string    `json:"error,omitempty"`
+}
+
+// deleteJobs 范围删除任务注册表
+type deleteJobs struct {
+	mu    sync.Mutex
+	seq   uint64
+	jobs  map[string]*DeleteJob
+	order []string
+	stop  chan struct{}
+	once  sync.Once
+	wg    sync.WaitGroup
+}
+
+// newDeleteJobs 创建任务注册表
+func newDeleteJobs() *deleteJobs {
+	return &deleteJobs{
+		jobs: make(map[string]*DeleteJob),
+		stop: make(chan struct{}),
+	}
+}
+
+// close 通知后台任务停止并等待退出
+func (d *deleteJobs) close() {
+	d.once.Do(func() {
+		close(d.stop)
+	})
+	d.wg.Wait()
+}
+
+// newJob 创建尚未注册的任务
+func (d *deleteJobs) newJob(start, end []byte) *DeleteJob {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+
+	d.seq++
+	return &DeleteJob{
+		ID:        fmt.Sprintf("del-%d-%d", time.Now().Unix(), d.seq),
+		Start:     start,
+		End:       end,
+		State:     DeleteJobRunning,
+		StartedAt: time.Now(),
+	}
+}
+
+// add 注册任务，超出数量时淘汰最早结束的任务
+func (d *deleteJobs) add(job *DeleteJob) {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+
+	d.jobs[job.ID] = job
+	d.order = append(d.order, job.ID)
+
+	for len(d.order) > maxFinishedDeleteJobs {
+		oldest := d.jobs[d.order[0]]
+		if oldest.State == DeleteJobRunning {
+			break
+		}
+		delete(d.jobs, oldest.ID)
+		d.order = d.order[1:]
+	}
+}
+
+// get 获取任务进度的副本
+func (d *deleteJobs) get(id string) (*DeleteJob, bool) {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+
+	job, ok := d.jobs[id]
+	if !ok {
+		return nil, false
+	}
+	snapshot := *job
+	return &snapshot, true
+}
+
+// update 在锁内修改任务进度
+func (d *deleteJobs) update(job *DeleteJob, fn func(job *DeleteJob)) {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	fn(job)
+}
+
+// encode 在锁内编码任务进度
+func (d *deleteJobs) encode(job *DeleteJob) ([]byte, error) {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	return json.Marshal(job)
+}
+
+// deleteJobKey 返回清理任务在元数据列族中的键
+func (s *RocksDBStorage) deleteJobKey(id string) []byte {
+	return []byte(deleteJobKeyPrefix + s.namespace.Name + "." + id)
+}
+
+// putDeleteJob 将清理任务的进度写入批处理
+func (s *RocksDBStorage) putDeleteJob(wb *gorocksdb.WriteBatch, job *DeleteJob) error {
+	data, err := s.deleteJobs.encode(job)
+	if err != nil {
+		return err
+	}
+	wb.PutCF(s.metadataCF, s.deleteJobKey(job.ID), data)
+	return nil
+}
+
+// DeletePrefix 删除前缀下的所有键，返回后台清理任务ID
+func (s *RocksDBStorage) DeletePrefix(prefix []byte) (string, error) {
+	if len(prefix) == 0 {
+		return "", NewError(CodeInvalidArgument, "prefix cannot be empty")
+	}
+	return s.deleteRange(prefix, prefixEnd(prefix), nil)
+}
+
+// DeleteRange 删除[start, end)范围内的所有键，返回后台清理任务ID
+func (s *RocksDBStorage) DeleteRange(start, end []byte) (string, error) {
+	if len(end) == 0 || bytes.Compare(start, end) >= 0 {
+		return "", NewError(CodeInvalidArgument, "invalid range: start must be less than end")
+	}
+	return s.deleteRange(start, end, nil)
+}
+
+// DeleteJob 查询范围删除任务的进度
+func (s *RocksDBStorage) DeleteJob(id string) (*DeleteJob, bool) {
+	return s.deleteJobs.get(id)
+}
+
+// deleteRange 使用RocksDB范围删除使键立即不可见，磁盘文件引用和创建时间索引在后台清理
+// replicated非nil时为从主节点复制的范围删除
+func (s *RocksDBStorage) deleteRange(start, end []byte, replicated *ChangeEvent) (string, error) {
+	// 1. 暂停全部写入，保证快照与范围删除之间没有新的写入
+	unlock := s.locks.lockAll()
+	snapshot := s.db.NewSnapshot()
+
+	wb := gorocksdb.NewWriteBatch()
+	defer wb.Destroy()
+
+	if end == nil {
+		// 前缀全为0xff时没有上界，逐个删除
+		if err := s.deleteFrom(wb, start); err != nil {
+			unlock()
+			s.db.ReleaseSnapshot(snapshot)
+			return "", err
+		}
+	} else {
+		wb.DeleteRangeCF(s.defaultCF, start, end)
+		wb.DeleteRangeCF(s.keyMetaCF, start, end)
+	}
+
+	// 清理任务的记录与范围删除一起提交，清理中断后可在启动时恢复
+	job := s.deleteJobs.newJob(start, end)
+	if err := s.putDeleteJob(wb, job); err != nil {
+		unlock()
+		s.db.ReleaseSnapshot(snapshot)
+		return "", err
+	}
+
+	// 持有blobMu使配额的注册与范围删除有确定的先后顺序
+	s.blobMu.Lock()
+	quotaSeq := s.quotas.current()
+	event := ChangeEvent{Type: EventDeleteRange, Key: start, End: end}
+	if replicated != nil {
+		event = *replicated
+	}
+	err := s.write(wb, []ChangeEvent{event})
+	s.blobMu.Unlock()
+	unlock()
+	if err != nil {
+		s.db.ReleaseSnapshot(snapshot)
+		return "", err
+	}
+
+	// 2. 后台根据快照清理磁盘文件引用和创建时间索引
+	s.deleteJobs.add(job)
+	s.deleteJobs.wg.Add(1)
+	go func() {
+		defer s.deleteJobs.wg.Done()
+		defer s.db.ReleaseSnapshot(snapshot)
+		s.cleanupRange(job, snapshot, quotaSeq)
+	}()
+
+	return job.ID, nil
+}
+
+// deleteFrom 将start之后的所有键的删除操作写入批处理
+func (s *RocksDBStorage) deleteFrom(wb *gorocksdb.WriteBatch, start []byte) error {
+	iter := s.db.NewIteratorCF(s.readOpts, s.defaultCF)
+	defer iter.Close()
+
+	for iter.Seek(start); iter.Valid(); iter.Next() {
+		key := iter.Key().Data()
+		wb.DeleteCF(s.defaultCF, key)
+		wb.DeleteCF(s.keyMetaCF, key)
+	}
+
+	return iter.Err()
+}
+
+// cleanupRange 遍历删除前的快照，释放磁盘文件引用、扣减配额用量并清理创建时间索引
+// quotaSeq之后注册的配额在统计用量时已不包含被删除的键
+// 每批释放与任务进度一起提交，清理完成后删除任务记录，中断或失败时保留记录，由下次启动时的resumeDeleteJobs完成清理
+func (s *RocksDBStorage) cleanupRange(job *DeleteJob, snapshot *gorocksdb.Snapshot, quotaSeq uint64) {
+	readOpts := gorocksdb.NewDefaultReadOptions()
+	defer readOpts.Destroy()
+	readOpts.SetSnapshot(snapshot)
+	if job.End != nil {
+		readOpts.SetIterateUpperBound(job.End)
+	}
+
+	iter := s.db.NewIteratorCF(readOpts, s.defaultCF)
+	defer iter.Close()
+
+	delta := newBatchDelta()
+	delta.quotaSeq = quotaSeq
+	delta.quiet = true
+	var keysDeleted, blobsReleased int64
+	flush := func() error {
+		s.deleteJobs.update(job, func(job *DeleteJob) {
+			job.KeysDeleted = keysDeleted
+			job.BlobsReleased = blobsReleased
+		})
+		if err := s.releaseBlobs(delta, job); err != nil {
+			return err
+		}
+		delta = newBatchDelta()
+		delta.quotaSeq = quotaSeq
+		delta.quiet = true
+		return nil
+	}
+
+	var err error
+	for iter.Seek(job.Start); iter.Valid(); iter.Next() {
+		key := make([]byte, iter.Key().Size())
+		copy(key, iter.Key().Data())
+
+		// 1. 释放删除前引用的磁盘文件，计数在flush时提交
+		if env, err := decodeEnvelope(iter.Value().Data()); err == nil && env.Type == valueDisk {
+			delta.release(env.diskFile())
+			blobsReleased++
+		}
+
+		// 2. 扣减配额用量并清理创建时间索引
+		var oldMeta *KeyMeta
+		if oldMeta, err = s.snapshotMeta(readOpts, key); err != nil {
+			break
+		}
+		delta.track(key, oldMeta, nil)
+		if err = s.cleanupCreateTime(key, oldMeta); err != nil {
+			break
+		}
+		keysDeleted++
+
+		if keysDeleted%deleteJobBatchSize == 0 {
+			if err = flush(); err != nil {
+				break
+			}
+		}
+
+		// 存储停止时中断清理
+		select {
+		case <-s.deleteJobs.stop:
+			err = ErrStopped
+		default:
+		}
+		if err != nil {
+			break
+		}
+	}
+	if err == nil {
+		err = iter.Err()
+	}
+	if err == nil {
+		err = flush()
+	}
+	if err == nil {
+		err = s.db.DeleteCF(s.writeOpts, s.metadataCF, s.deleteJobKey(job.ID))
+	}
+
+	s.deleteJobs.update(job, func(job *DeleteJob) {
+		job.FinishedAt = time.Now()
+		if err != nil {
+			job.State = DeleteJobFailed
+			job.Error = err.Error()
+		} else {
+			job.State = DeleteJobDone
+		}
+	})
+}
+
+// snapshotMeta 读取快照中键的元数据
+func (s *RocksDBStorage) snapshotMeta(snapshotOpts *gorocksdb.ReadOptions, key []byte) (*KeyMeta, error) {
+	value, err := s.db.GetCF(snapshotOpts, s.keyMetaCF, key)
+	if err != nil {
+		return nil, err
+	}
+	defer value.Free()
+
+	if value.Size() == 0 {
+		return nil, nil
+	}
+	return decodeKeyMeta(value.Data())
+}
+
+// cleanupCreateTime 清理已删除键的创建时间索引，跳过删除后被重新写入的键
+func (s *RocksDBStorage) cleanupCreateTime(key []byte, oldMeta *KeyMeta) error {
+	unlock := s.locks.lock(key)
+	defer unlock()
+
+	current, err := s.loadMeta(key)
+	if err != nil {
+		return err
+	}
+	if current != nil && (oldMeta == nil || current.CreatedAt == oldMeta.CreatedAt) {
+		// 键已被重新写入，且索引项无法与旧记录区分
+		return nil
+	}
+
+	return s.dropCreateTime(key, oldMeta)
+}
+
+// releaseBlobs 提交一批磁盘文件引用和配额用量的释放，同时记录任务进度
+func (s *RocksDBStorage) releaseBlobs(delta *batchDelta, job *DeleteJob) error {
+	wb := gorocksdb.NewWriteBatch()
+	defer wb.Destroy()
+
+	if err := s.putDeleteJob(wb, job); err != nil {
+		return err
+	}
+	return s.commit(wb, delta)
+}
+
+// resumeDeleteJobs 启动时完成上次中断的清理任务
+// 删除前的快照已不存在，无法再读取被删除的键，因此根据当前数据重新统计：
+// 重建磁盘文件引用计数并删除不再被引用的文件，清理范围内已不存在的键的创建时间索引，重新统计配额用量
+func (s *RocksDBStorage) resumeDeleteJobs() error {
+	// 1. 读取未完成的任务
+	prefix := []byte(deleteJobKeyPrefix + s.namespace.Name + ".")
+	iter := s.db.NewIteratorCF(s.readOpts, s.metadataCF)
+	var jobs []*DeleteJob
+	for iter.Seek(prefix); iter.Valid(); iter.Next() {
+		if !bytes.HasPrefix(iter.Key().Data(), prefix) {
+			break
+		}
+		job := &DeleteJob{}
+		if err := json.Unmarshal(iter.Value().Data(), job); err != nil {
+			iter.Close()
+			return fmt.Errorf("failed to decode delete job %s: %v", iter.Key().Data(), err)
+		}
+		jobs = append(jobs, job)
+	}
+	err := iter.Err()
+	iter.Close()
+	if err != nil || len(jobs) == 0 {
+		return err
+	}
+
+	// 2. 按当前数据重新统计，结果与任务数量无关，只需执行一次
+	released, err := s.rebuildBlobRefs()
+	if err != nil {
+		return fmt.Errorf("failed to resume delete jobs: %v", err)
+	}
+	keys, err := s.pruneCreateTime(jobs)
+	if err != nil {
+		return fmt.Errorf("failed to resume delete jobs: %v", err)
+	}
+
+	wb := gorocksdb.NewWriteBatch()
+	defer wb.Destroy()
+
+	if err := s.recountQuotas(wb); err != nil {
+		return fmt.Errorf("failed to resume delete jobs: %v", err)
+	}
+
+	// 3. 删除任务记录，任务以完成状态保留在注册表中供查询
+	now := time.Now()
+	for _, job := range jobs {
+		wb.DeleteCF(s.metadataCF, s.deleteJobKey(job.ID))
+		job.State = DeleteJobDone
+		job.FinishedAt = now
+		job.Error = ""
+	}
+	if err := s.db.Write(s.writeOpts, wb); err != nil {
+		return err
+	}
+
+	// 重新统计的结果无法区分各任务，计入最早的任务
+	jobs[0].KeysDeleted += keys
+	jobs[0].BlobsReleased += released
+	for _, job := range jobs {
+		s.deleteJobs.add(job)
+	}
+	return nil
+}
+
+// pruneCreateTime 从创建时间索引中删除任务范围内已不存在或已被重新写入的键，返回删除的索引项数量
+func (s *RocksDBStorage) pruneCreateTime(jobs []*DeleteJob) (int64, error) {
+	if s.createTimeCF == nil {
+		return 0, nil
+	}
+
+	s.indexMu.Lock()
+	defer s.indexMu.Unlock()
+
+	inRange := func(key []byte) bool {
+		for _, job := range jobs {
+			if bytes.Compare(key, job.Start) >= 0 && (job.End == nil || bytes.Compare(key, job.End) < 0) {
+				return true
+			}
+		}
+		return false
+	}
+
+	wb := gorocksdb.NewWriteBatch()
+	defer wb.Destroy()
+
+	iter := s.db.NewIteratorCF(s.readOpts, s.createTimeCF)
+	defer iter.Close()
+
+	var pruned int64
+	for iter.SeekToFirst(); iter.Valid(); iter.Next() {
+		timestamp, err := strconv.ParseInt(string(iter.Key().Data()), 10, 64)
+		if err != nil {
+			continue
+		}
+		var keys []string
+		if err := json.Unmarshal(iter.Value().Data(), &keys); err != nil {
+			continue
+		}
+
+		kept := keys[:0:0]
+		for _, key := range keys {
+			if inRange([]byte(key)) {
+				meta, err := s.loadMeta([]byte(key))
+				if err != nil {
+					return 0, err
+				}
+				if meta == nil || meta.CreatedAt != timestamp {
+					pruned++
+					continue
+				}
+			}
+			kept = append(kept, key)
+		}
+
+		timestampKey := append([]byte(nil), iter.Key().Data()...)
+		switch {
+		case len(kept) == len(keys):
+		case len(kept) == 0:
+			wb.DeleteCF(s.createTimeCF, timestampKey)
+		default:
+			data, err := json.Marshal(kept)
+			if err != nil {
+				return 0, err
+			}
+			wb.PutCF(s.createTimeCF, timestampKey, data)
+		}
+	}
+	if err := iter.Err(); err != nil {
+		return 0, err
+	}
+
+	return pruned, s.db.Write(s.writeOpts, wb)
+}
+
+// dropDeleteJobs 删除当前命名空间未完成的清理任务记录
+func (s *RocksDBStorage) dropDeleteJobs() error {
+	prefix := []byte(deleteJobKeyPrefix + s.namespace.Name + ".")
+
+	wb := gorocksdb.NewWriteBatch()
+	defer wb.Destroy()
+
+	wb.DeleteRangeCF(s.metadataCF, prefix, prefixEnd(prefix))
+	return s.db.Write(s.writeOpts, wb)
+}
@@ -1,11 +1,14 @@
 package storage
 
 import (
+	"context"
 	"crypto/sha256"
 	"encoding/hex"
 	"fmt"
+	"io"
 	"os"
 	"path/filepath"
+	"strings"
 )
 
 // DiskStore 磁盘存储实现
@@ -40,22 +43,56 @@ func (ds *DiskStore) Store(data []byte) (string, error) {
 	return fileName, nil
 }
 
+// loadChunkSize 加载磁盘文件时每次读取的字节数，每块之间检查ctx
+const loadChunkSize = 1 << 20
+
 // Load 从磁盘加载数据
 func (ds *DiskStore) Load(fileName string) ([]byte, error) {
-	filePath := filepath.Join(ds.basePath, fileName)
+	return ds.LoadContext(context.Background(), fileName)
+}
+
+// LoadContext 从磁盘加载数据，按块读取，ctx取消或超时后停止读取并返回ctx.Err()
+func (ds *DiskStore) LoadContext(ctx context.Context, fileName string) ([]byte, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
 
-	// 读取文件
-	data, err := os.ReadFile(filePath)
+	// 1. 打开文件并按大小分配缓冲区，文件名为内容哈希，读取期间内容不会改变
+	filePath, err := ds.Path(fileName)
+	if err != nil {
+		return nil, err
+	}
+	f, err := os.Open(filePath)
 	if err != nil {
 		return nil, fmt.Errorf("failed to read from disk: %v", err)
 	}
+	defer f.Close()
+
+	info, err := f.Stat()
+	if err != nil {
+		return nil, fmt.Errorf("failed to read from disk: %v", err)
+	}
+
+	// 2. 按块读取
+	data := make([]byte, info.Size())
+	for off := 0; off < len(data); off += loadChunkSize {
+		if err := ctx.Err(); err != nil {
+			return nil, err
+		}
+		if _, err := io.ReadFull(f, data[off:min(off+loadChunkSize, len(data))]); err != nil {
+			return nil, fmt.Errorf("failed to read from disk: %v", err)
+		}
+	}
 
 	return data, nil
 }
 
 // Delete 从磁盘删除数据
 func (ds *DiskStore) Delete(fileName string) error {
-	filePath := filepath.Join(ds.basePath, fileName)
+	filePath, err := ds.Path(fileName)
+	if err != nil {
+		return err
+	}
 
 	// 删除文件
 	if err := os.Remove(filePath); err != nil {
@@ -69,6 +106,147 @@ func (ds *DiskStore) Delete(fileName string) error {
 	return nil
 }
 
+// Size 返回磁盘文件的大小
+func (ds *DiskStore) Size(fileName string) (int64, error) {
+	filePath, err := ds.Path(fileName)
+	if err != nil {
+		return 0, err
+	}
+	info, err := os.Stat(filePath)
+	if err != nil {
+		return 0, fmt.Errorf("failed to stat disk file: %v", err)
+	}
+	return info.Size(), nil
+}
+
+// Has 判断磁盘文件是否存在
+func (ds *DiskStore) Has(fileName string) bool {
+	_, err := ds.Size(fileName)
+	return err == nil
+}
+
+// Path 返回磁盘文件的完整路径，文件名不是内容哈希时返回错误，防止路径穿越
+func (ds *DiskStore) Path(fileName string) (string, error) {
+	if !validBlobName(fileName) {
+		return "", Errorf(CodeInvalidArgument, "invalid disk file name: %s", fileName)
+	}
+	return filepath.Join(ds.basePath, fileName), nil
+}
+
+// validBlobName 判断磁盘文件名是否为内容哈希
+func validBlobName(fileName string) bool {
+	return len(fileName) == 64 && strings.Trim(fileName, "0123456789abcdef") == ""
+}
+
+// Append 以写时复制方式向已有文件追加数据，返回新文件名
+func (ds *DiskStore) Append(fileName string, data []byte) (string, error) {
+	return ds.rewrite(fileName, func(f *os.File) error {
+		if _, err := f.Seek(0, io.SeekEnd); err != nil {
+			return err
+		}
+		_, err := f.Write(data)
+		return err
+	})
+}
+
+// WriteAt 以写时复制方式在指定偏移量写入数据，返回新文件名
+func (ds *DiskStore) WriteAt(fileName string, offset int64, data []byte) (string, error) {
+	return ds.rewrite(fileName, func(f *os.File) error {
+		_, err := f.WriteAt(data, offset)
+		return err
+	})
+}
+
+// rewrite 复制原文件到临时文件，执行修改后按新内容的哈希重命名
+func (ds *DiskStore) rewrite(fileName string, modify func(f *os.File) error) (string, error) {
+	tmp, err := os.CreateTemp(ds.basePath, ".tmp-*")
+	if err != nil {
+		return "", fmt.Errorf("failed to create temp file: %v", err)
+	}
+	tmpPath := tmp.Name()
+	defer os.Remove(tmpPath)
+	defer tmp.Close()
+
+	// 1. 复制原文件内容
+	srcPath, err := ds.Path(fileName)
+	if err != nil {
+		return "", err
+	}
+	src, err := os.Open(srcPath)
+	if err != nil {
+		return "", fmt.Errorf("failed to read from disk: %v", err)
+	}
+	_, err = io.Copy(tmp, src)
+	src.Close()
+	if err != nil {
+		return "", fmt.Errorf("failed to copy disk file: %v", err)
+	}
+
+	// 2. 执行修改
+	if err := modify(tmp); err != nil {
+		return "", fmt.Errorf("failed to write to disk: %v", err)
+	}
+
+	// 3. 计算新内容的哈希作为文件名
+	if _, err := tmp.Seek(0, io.SeekStart); err != nil {
+		return "", err
+	}
+	hash := sha256.New()
+	if _, err := io.Copy(hash, tmp); err != nil {
+		return "", fmt.Errorf("failed to hash disk file: %v", err)
+	}
+	newName := hex.EncodeToString(hash.Sum(nil))
+
+	if err := tmp.Chmod(0644); err != nil {
+		return "", err
+	}
+	if err := tmp.Close(); err != nil {
+		return "", err
+	}
+	if err := os.Rename(tmpPath, filepath.Join(ds.basePath, newName)); err != nil {
+		return "", fmt.Errorf("failed to rename disk file: %v", err)
+	}
+
+	return newName, nil
+}
+
+// Import 写入从其他节点传输的文件，校验内容哈希与文件名一致
+func (ds *DiskStore) Import(fileName string, r io.Reader) error {
+	filePath, err := ds.Path(fileName)
+	if err != nil {
+		return err
+	}
+
+	tmp, err := os.CreateTemp(ds.basePath, ".tmp-*")
+	if err != nil {
+		return fmt.Errorf("failed to create temp file: %v", err)
+	}
+	tmpPath := tmp.Name()
+	defer os.Remove(tmpPath)
+	defer tmp.Close()
+
+	// 1. 写入临时文件并计算哈希
+	hash := sha256.New()
+	if _, err := io.Copy(io.MultiWriter(tmp, hash), r); err != nil {
+		return fmt.Errorf("failed to write to disk: %v", err)
+	}
+	if hex.EncodeToString(hash.Sum(nil)) != fileName {
+		return fmt.Errorf("disk file %s does not match its content", fileName)
+	}
+
+	// 2. 重命名为目标文件
+	if err := tmp.Chmod(0644); err != nil {
+		return err
+	}
+	if err := tmp.Close(); err != nil {
+		return err
+	}
+	if err := os.Rename(tmpPath, filePath); err != nil {
+		return fmt.Errorf("failed to rename disk file: %v", err)
+	}
+	return nil
+}
+
 // Close 关闭磁盘存储
 func (ds *DiskStore) Close() error {
 	// 目前不需要特殊处理
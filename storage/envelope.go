@@ -0,0 +1,299 @@
+package storage
+
+import (
+	"encoding/binary"
+	"fmt"
+	"hash/crc32"
+	"strings"
+
+	"kvcache/config"
+
+	gorocksdb "github.com/linxGnu/grocksdb"
+)
+
+// 值信封：值列族中的每个值都以固定长度的头部开始，值的含义只由头部决定，不再根据内容推断
+//
+//	0   1字节  信封格式版本
+//	1   1字节  值类型
+//	2   1字节  标志位，记录值的压缩和加密方式
+//	3   8字节  过期时间（Unix秒，大端），0表示永不过期
+//	11  8字节  版本号（大端），墓碑为删除的时间戳
+//	19  4字节  CRC32-C校验和，覆盖头部其余字段和载荷
+//	23  载荷   内联值的数据或磁盘存储的文件名，已淘汰的值和墓碑没有载荷
+const (
+	// envelopeVersion 当前的信封格式版本
+	envelopeVersion = 1
+	// envelopeHeaderSize 信封头部长度
+	envelopeHeaderSize = 23
+)
+
+// valueType 信封中的值类型
+type valueType byte
+
+const (
+	// valueInline 值内联存储在载荷中
+	valueInline valueType = 1
+	// valueDisk 值存储在磁盘文件中，载荷为文件名
+	valueDisk valueType = 2
+	// valueEvicted 磁盘存储的值已被淘汰
+	valueEvicted valueType = 3
+	// valueTombstone 带版本删除的墓碑
+	valueTombstone valueType = 4
+)
+
+const (
+	// flagCompressed 载荷已压缩
+	flagCompressed byte = 1 << 0
+	// flagEncrypted 载荷已加密
+	flagEncrypted byte = 1 << 1
+	// knownFlags 当前版本能识别的标志位
+	knownFlags = flagCompressed | flagEncrypted
+)
+
+// ErrCorruptValue 存储的值不是有效的信封或校验和不匹配
+var ErrCorruptValue = NewError(CodeCorrupted, "stored value is corrupt")
+
+// castagnoli CRC32-C校验表
+var castagnoli = crc32.MakeTable(crc32.Castagnoli)
+
+// envelope 解码后的值信封
+type envelope struct {
+	Type      valueType
+	Flags     byte
+	ExpiresAt int64
+	Version   uint64
+	Payload   []byte
+}
+
+// newEnvelope 根据键的元数据创建信封，meta为nil时不记录过期时间和版本号
+func newEnvelope(typ valueType, meta *KeyMeta, payload []byte) *envelope {
+	env := &envelope{Type: typ, Payload: payload}
+	if meta != nil {
+		env.ExpiresAt = meta.ExpiresAt
+		env.Version = meta.Version
+		if meta.Codec != "" && meta.Codec != CodecNone {
+			env.Flags |= flagCompressed
+		}
+		if meta.Encryption != "" && meta.Encryption != EncryptionNone {
+			env.Flags |= flagEncrypted
+		}
+	}
+	return env
+}
+
+// encode 编码信封
+func (e *envelope) encode() []byte {
+	data := make([]byte, envelopeHeaderSize+len(e.Payload))
+	data[0] = envelopeVersion
+	data[1] = byte(e.Type)
+	data[2] = e.Flags
+	binary.BigEndian.PutUint64(data[3:], uint64(e.ExpiresAt))
+	binary.BigEndian.PutUint64(data[11:], e.Version)
+	copy(data[envelopeHeaderSize:], e.Payload)
+	binary.BigEndian.PutUint32(data[19:], envelopeChecksum(data))
+	return data
+}
+
+// envelopeChecksum 计算除校验和字段以外的头部和载荷的校验和
+func envelopeChecksum(data []byte) uint32 {
+	sum := crc32.Update(0, castagnoli, data[:19])
+	return crc32.Update(sum, castagnoli, data[envelopeHeaderSize:])
+}
+
+// decodeEnvelope 解码并校验信封，载荷引用data
+func decodeEnvelope(data []byte) (*envelope, error) {
+	if len(data) < envelopeHeaderSize {
+		return nil, ErrCorruptValue
+	}
+	if data[0] != envelopeVersion {
+		return nil, Errorf(CodeCorrupted, "unsupported value envelope version %d", data[0])
+	}
+	if binary.BigEndian.Uint32(data[19:]) != envelopeChecksum(data) {
+		return nil, ErrCorruptValue
+	}
+
+	env := &envelope{
+		Type:      valueType(data[1]),
+		Flags:     data[2],
+		ExpiresAt: int64(binary.BigEndian.Uint64(data[3:])),
+		Version:   binary.BigEndian.Uint64(data[11:]),
+		Payload:   data[envelopeHeaderSize:],
+	}
+	if env.Type < valueInline || env.Type > valueTombstone || env.Flags&^knownFlags != 0 {
+		return nil, ErrCorruptValue
+	}
+	return env, nil
+}
+
+// diskFile 返回磁盘存储的值的文件名，其他类型返回空字符串
+func (e *envelope) diskFile() string {
+	if e == nil || e.Type != valueDisk {
+		return ""
+	}
+	return string(e.Payload)
+}
+
+// readEnvelope 读取键存储的信封，键不存在时返回nil，载荷为副本
+func (s *RocksDBStorage) readEnvelope(key []byte) (*envelope, error) {
+	value, err := s.db.GetCF(s.readOpts, s.defaultCF, key)
+	if err != nil {
+		return nil, err
+	}
+	defer value.Free()
+
+	if value.Size() == 0 {
+		return nil, nil
+	}
+	return decodeEnvelope(append([]byte(nil), value.Data()...))
+}
+
+// putEnvelope 将值的信封写入批处理，过期时间和版本号取自元数据
+func (s *RocksDBStorage) putEnvelope(wb *gorocksdb.WriteBatch, key []byte, typ valueType, meta *KeyMeta, payload []byte) {
+	wb.PutCF(s.defaultCF, key, newEnvelope(typ, meta, payload).encode())
+}
+
+// legacyEnvelope 将旧格式的值转换为信封，hasBlob判断磁盘文件是否存在
+// 有元数据时按元数据记录的位置和淘汰状态判断类型，只有没有元数据的旧数据才根据内容前缀推断，
+// 此时文件名为内容哈希的磁盘指针才被信任，文件已丢失的按已淘汰处理，其余带前缀的值是内容恰好带有前缀的用户值
+func legacyEnvelope(raw []byte, meta *KeyMeta, hasBlob func(fileName string) bool) *envelope {
+	switch {
+	case meta != nil && meta.Evicted:
+		return newEnvelope(valueEvicted, meta, nil)
+	case meta != nil && meta.Location == LocationDisk && meta.DiskFile != "":
+		return newEnvelope(valueDisk, meta, []byte(meta.DiskFile))
+	case meta != nil:
+		return newEnvelope(valueInline, meta, raw)
+	case string(raw) == EvictedValue:
+		return newEnvelope(valueEvicted, nil, nil)
+	case strings.HasPrefix(string(raw), DiskStorePrefix):
+		fileName := strings.TrimPrefix(string(raw), DiskStorePrefix)
+		switch {
+		case !validBlobName(fileName):
+			// 不是内容哈希的文件名不可能由磁盘存储生成，是内容恰好带有前缀的用户值
+			return newEnvelope(valueInline, nil, raw)
+		case hasBlob(fileName):
+			return newEnvelope(valueDisk, nil, []byte(fileName))
+		default:
+			// 磁盘文件已丢失，按已淘汰处理，读取时返回ErrEvicted并可从源站重新加载，不把内部指针当作值返回
+			return newEnvelope(valueEvicted, nil, nil)
+		}
+	default:
+		return newEnvelope(valueInline, nil, raw)
+	}
+}
+
+// migrateEnvelopes 将旧格式的值和墓碑转换为信封，值列族按键顺序转换并记录进度，中断后不会把已转换的值再次当作旧格式
+func (s *RocksDBStorage) migrateEnvelopes(m *migrationRun) error {
+	// 1. 逐个转换默认命名空间和各命名空间的值列族
+	for name, handle := range s.namespaces.handles {
+		if name != "default" && (!strings.HasPrefix(name, namespaceCFPrefix) || strings.Contains(name[len(namespaceCFPrefix):], ".")) {
+			continue
+		}
+		// 迁移先于磁盘存储初始化，按配置的目录判断磁盘文件是否存在
+		metaCF, diskStore := s.keyMetaCF, &DiskStore{basePath: s.config.Value.DiskPath}
+		if name != "default" {
+			metaCF = s.namespaces.handles[name+"."+KeyMetaCF]
+			diskStore = &DiskStore{basePath: config.NamespaceDiskPath(s.config, name[len(namespaceCFPrefix):])}
+		}
+		if err := s.migrateValueCF(m, name, handle, metaCF, diskStore); err != nil {
+			return fmt.Errorf("failed to migrate column family %s: %v", name, err)
+		}
+	}
+
+	// 2. 转换墓碑，旧格式的墓碑固定为8字节的时间戳
+	return s.migrateTombstones(m)
+}
+
+// migrateValueCF 转换一个值列族中的旧格式值，metaCF为对应的键元数据列族，可能为nil，diskStore为对应的磁盘存储
+func (s *RocksDBStorage) migrateValueCF(m *migrationRun, name string, valueCF, metaCF *gorocksdb.ColumnFamilyHandle, diskStore *DiskStore) error {
+	last, err := m.progress(name)
+	if err != nil {
+		return err
+	}
+
+	iter := s.db.NewIteratorCF(s.readOpts, valueCF)
+	defer iter.Close()
+
+	wb := gorocksdb.NewWriteBatch()
+	defer wb.Destroy()
+
+	// 1. 从上次中断的位置继续，合并操作数已由RocksDB应用到读取的值上
+	if last != nil {
+		iter.Seek(last)
+		if iter.Valid() && string(iter.Key().Data()) == string(last) {
+			iter.Next()
+		}
+	} else {
+		iter.SeekToFirst()
+	}
+
+	var lastKey []byte
+	for ; iter.Valid(); iter.Next() {
+		key := append([]byte(nil), iter.Key().Data()...)
+		var meta *KeyMeta
+		if metaCF != nil {
+			metaValue, err := s.db.GetCF(s.readOpts, metaCF, key)
+			if err != nil {
+				return err
+			}
+			if metaValue.Size() > 0 {
+				meta, err = decodeKeyMeta(metaValue.Data())
+			}
+			metaValue.Free()
+			if err != nil {
+				return err
+			}
+		}
+
+		// 2. 写入信封，批处理写满时提交并记录进度
+		lastKey = key
+		if m.put(wb, valueCF, key, legacyEnvelope(iter.Value().Data(), meta, diskStore.Has).encode()) {
+			if err := m.flush(wb, name, lastKey); err != nil {
+				return err
+			}
+		}
+	}
+	if err := iter.Err(); err != nil {
+		return err
+	}
+	return m.flush(wb, name, lastKey)
+}
+
+// migrateTombstones 将所有命名空间的旧格式墓碑转换为信封，已转换的墓碑长度不同，可以重复执行
+func (s *RocksDBStorage) migrateTombstones(m *migrationRun) error {
+	if s.metadataCF == nil {
+		return nil
+	}
+
+	prefix := []byte(tombstoneKeyPrefix)
+	iter := s.db.NewIteratorCF(s.readOpts, s.metadataCF)
+	defer iter.Close()
+
+	wb := gorocksdb.NewWriteBatch()
+	defer wb.Destroy()
+
+	for iter.Seek(prefix); iter.Valid(); iter.Next() {
+		key := iter.Key().Data()
+		if !strings.HasPrefix(string(key), tombstoneKeyPrefix) {
+			break
+		}
+		if iter.Value().Size() != 8 {
+			continue
+		}
+		stamp := binary.BigEndian.Uint64(iter.Value().Data())
+		if m.put(wb, s.metadataCF, append([]byte(nil), key...), encodeTombstone(stamp)) {
+			if err := m.flush(wb, "", nil); err != nil {
+				return err
+			}
+		}
+	}
+	if err := iter.Err(); err != nil {
+		return err
+	}
+	return m.flush(wb, "", nil)
+}
+
+// encodeTombstone 编码带版本删除的墓碑，版本号为删除的时间戳
+func encodeTombstone(stamp uint64) []byte {
+	return (&envelope{Type: valueTombstone, Version: stamp}).encode()
+}
@@ -0,0 +1,115 @@
+package storage
+
+import (
+	"context"
+	"errors"
+	"fmt"
+)
+
+// Code 错误类别，用于映射gRPC状态码和HTTP状态码，并作为监控指标的标签
+type Code string
+
+const (
+	// CodeNotFound 键或对象不存在
+	CodeNotFound Code = "not_found"
+	// CodeEvicted 值已被淘汰，键和元数据仍然存在
+	CodeEvicted Code = "evicted"
+	// CodeInvalidArgument 请求参数不合法
+	CodeInvalidArgument Code = "invalid_argument"
+	// CodeResourceExhausted 超出配额等资源限制
+	CodeResourceExhausted Code = "resource_exhausted"
+	// CodeConflict 与当前状态冲突，例如目标键已存在
+	CodeConflict Code = "conflict"
+	// CodeUnavailable 暂时无法处理，例如只读副本或存储已停止
+	CodeUnavailable Code = "unavailable"
+	// CodeCorrupted 存储的数据已损坏
+	CodeCorrupted Code = "corrupted"
+	// CodeUnimplemented 当前模式不支持该操作
+	CodeUnimplemented Code = "unimplemented"
+	// CodeOutOfRange 请求的修订号已从变更日志中裁剪
+	CodeOutOfRange Code = "out_of_range"
+	// CodeCanceled 请求已取消
+	CodeCanceled Code = "canceled"
+	// CodeDeadlineExceeded 请求超过截止时间
+	CodeDeadlineExceeded Code = "deadline_exceeded"
+	// CodeInternal 未分类的错误
+	CodeInternal Code = "internal"
+)
+
+// ErrorDomain gRPC错误详情中的错误域
+const ErrorDomain = "kvcache"
+
+// 各类别的错误，具体的错误通过errors.Is与所属类别匹配
+var (
+	ErrNotFound          = errors.New("not found")
+	ErrEvicted           = errors.New("value has been evicted")
+	ErrInvalidArgument   = errors.New("invalid argument")
+	ErrResourceExhausted = errors.New("resource exhausted")
+	ErrConflict          = errors.New("conflict")
+	ErrUnavailable       = errors.New("unavailable")
+	ErrCorrupted         = errors.New("corrupted")
+	ErrUnimplemented     = errors.New("unimplemented")
+	ErrOutOfRange        = errors.New("out of range")
+)
+
+// categories 错误类别及对应的错误，按匹配顺序排列
+var categories = []struct {
+	code Code
+	err  error
+}{
+	{CodeNotFound, ErrNotFound},
+	{CodeEvicted, ErrEvicted},
+	{CodeInvalidArgument, ErrInvalidArgument},
+	{CodeResourceExhausted, ErrResourceExhausted},
+	{CodeConflict, ErrConflict},
+	{CodeUnavailable, ErrUnavailable},
+	{CodeCorrupted, ErrCorrupted},
+	{CodeUnimplemented, ErrUnimplemented},
+	{CodeOutOfRange, ErrOutOfRange},
+	{CodeCanceled, context.Canceled},
+	{CodeDeadlineExceeded, context.DeadlineExceeded},
+}
+
+// ErrStopped 存储已停止
+var ErrStopped = NewError(CodeUnavailable, "storage stopped")
+
+// codedError 属于某个类别的错误，错误信息不包含类别
+type codedError struct {
+	code Code
+	msg  string
+}
+
+// NewError 创建属于code类别的错误
+func NewError(code Code, msg string) error {
+	return &codedError{code: code, msg: msg}
+}
+
+// Errorf 按格式创建属于code类别的错误
+func Errorf(code Code, format string, args ...any) error {
+	return NewError(code, fmt.Sprintf(format, args...))
+}
+
+// Error 返回错误信息
+func (e *codedError) Error() string {
+	return e.msg
+}
+
+// Is 与所属类别的错误匹配
+func (e *codedError) Is(target error) bool {
+	for _, c := range categories {
+		if c.code == e.code {
+			return target == c.err
+		}
+	}
+	return false
+}
+
+// ErrorCode 返回错误所属的类别，无法分类的错误返回CodeInternal
+func ErrorCode(err error) Code {
+	for _, c := range categories {
+		if errors.Is(err, c.err) {
+			return c.code
+		}
+	}
+	return CodeInternal
+}
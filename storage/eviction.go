@@ -1,34 +1,42 @@
 package storage
 
 import (
+	"bytes"
+	"encoding/binary"
 	"encoding/json"
 	"fmt"
 	"os"
 	"path/filepath"
-	"strings"
 	"sync"
 	"time"
+
+	gorocksdb "github.com/linxGnu/grocksdb"
 )
 
+// evictedKeyPrefix 已淘汰键在元数据列族中的索引前缀，按淘汰时间排序，用于清理超过保留时间的淘汰标记
+const evictedKeyPrefix = "evicted."
+
 // EvictionManager 淘汰管理器
 type EvictionManager struct {
-	storage    *RocksDBStorage
-	running    bool
-	stopCh     chan struct{}
-	mutex      sync.Mutex
-	checkInterval time.Duration
-	batchSize     int
-	diskThreshold float64
+	storage         *RocksDBStorage
+	running         bool
+	stopCh          chan struct{}
+	mutex           sync.Mutex
+	checkInterval   time.Duration
+	batchSize       int
+	diskThreshold   float64
+	markerRetention time.Duration
 }
 
 // NewEvictionManager 创建新的淘汰管理器实例
 func NewEvictionManager(storage *RocksDBStorage) (*EvictionManager, error) {
 	return &EvictionManager{
-		storage:       storage,
-		stopCh:        make(chan struct{}),
-		checkInterval: time.Duration(storage.config.Eviction.CheckInterval) * time.Second,
-		batchSize:     storage.config.Eviction.BatchSize,
-		diskThreshold: storage.config.Eviction.DiskUsageThreshold,
+		storage:         storage,
+		stopCh:          make(chan struct{}),
+		checkInterval:   time.Duration(storage.config.Eviction.CheckInterval) * time.Second,
+		batchSize:       storage.config.Eviction.BatchSize,
+		diskThreshold:   storage.config.Eviction.DiskUsageThreshold,
+		markerRetention: time.Duration(storage.config.Eviction.MarkerRetention) * time.Second,
 	}, nil
 }
 
@@ -74,6 +82,9 @@ func (em *EvictionManager) run() {
 				// 记录错误但继续运行
 				fmt.Printf("eviction check failed: %v\n", err)
 			}
+			if err := em.cleanupMarkers(); err != nil {
+				fmt.Printf("evicted marker cleanup failed: %v\n", err)
+			}
 		case <-em.stopCh:
 			return
 		}
@@ -165,7 +176,7 @@ func (em *EvictionManager) evict() error {
 				valueBytes := value.Data()
 
 				// 检查是否是磁盘存储的值
-				if strings.HasPrefix(string(valueBytes), DiskStorePrefix) {
+				if env, err := decodeEnvelope(valueBytes); err == nil && env.Type == valueDisk {
 					// 执行淘汰
 					if err := em.evictKey(key, valueBytes); err != nil {
 						value.Free()
@@ -182,19 +193,189 @@ func (em *EvictionManager) evict() error {
 	return iter.Err()
 }
 
+// cleanupMarkers 删除超过保留时间的淘汰标记，未配置保留时间时永久保留
+func (em *EvictionManager) cleanupMarkers() error {
+	if em.markerRetention <= 0 {
+		return nil
+	}
+
+	for {
+		select {
+		case <-em.stopCh:
+			return nil
+		default:
+		}
+
+		scanned, err := em.storage.cleanupEvicted(time.Now().Add(-em.markerRetention), em.batchSize)
+		if err != nil || scanned < em.batchSize {
+			return err
+		}
+	}
+}
+
 // evictKey 淘汰单个键
 func (em *EvictionManager) evictKey(key, value []byte) error {
-	// 1. 从磁盘删除文件
-	filePath := strings.TrimPrefix(string(value), DiskStorePrefix)
-	if err := em.storage.diskStore.Delete(filePath); err != nil {
+	return em.storage.evictValue(key, value)
+}
+
+// evictValue 淘汰磁盘存储的值，value为淘汰前读取到的值的信封
+func (s *RocksDBStorage) evictValue(key, value []byte) error {
+	unlock := s.locks.lock(key)
+	defer unlock()
+
+	// 加锁后重新检查，避免淘汰刚被重新写入的值
+	current, err := s.db.GetCF(s.readOpts, s.defaultCF, key)
+	if err != nil {
+		return err
+	}
+	unchanged := string(current.Data()) == string(value)
+	current.Free()
+	if !unchanged {
+		return nil
+	}
+
+	// 等待写回源站的值淘汰后无法写回，跳过
+	pending, err := s.db.GetCF(s.readOpts, s.metadataCF, writeBehindKey(s.namespace.Name, key))
+	if err != nil {
+		return err
+	}
+	marked := pending.Size() > 0
+	pending.Free()
+	if marked {
+		return nil
+	}
+
+	// 1. 释放磁盘文件引用，文件不再被其他键共享时会被删除
+	env, err := decodeEnvelope(value)
+	if err != nil {
+		return err
+	}
+	delta := newBatchDelta()
+	delta.release(env.diskFile())
+
+	// 2. 更新RocksDB中的值为已淘汰标记，并在元数据中记录淘汰状态
+	// 没有元数据的旧数据根据存储的值推导，同样记录淘汰时间，以便清理淘汰标记并通知订阅者和从节点
+	meta, err := s.loadMeta(key)
+	if err != nil {
+		return err
+	}
+	old := meta
+	if old == nil {
+		if old, err = s.deriveMeta(key); err != nil || old == nil {
+			return err
+		}
+	}
+
+	wb := gorocksdb.NewWriteBatch()
+	defer wb.Destroy()
+
+	evicted := *old
+	evicted.Evicted = true
+	evicted.EvictedAt = time.Now().Unix()
+	evicted.DiskFile = ""
+	s.putEnvelope(wb, key, valueEvicted, &evicted, nil)
+	if err := s.putMeta(wb, key, &evicted); err != nil {
 		return err
 	}
+	s.indexEvicted(wb, key, &evicted)
+	delta.track(key, old, &evicted)
+
+	// 3. 提交成功后从创建时间记录中删除
+	delta.unindexCreateTime(key, meta)
+	return s.commit(wb, delta)
+}
+
+// evictedIndexKey 返回已淘汰键的索引键：前缀、命名空间、8字节淘汰时间和键
+func evictedIndexKey(namespace string, evictedAt int64, key []byte) []byte {
+	indexKey := append([]byte(evictedKeyPrefix+namespace+"."), encodeRevision(uint64(evictedAt))...)
+	return append(indexKey, key...)
+}
+
+// indexEvicted 将已淘汰键加入淘汰索引，没有淘汰时间的旧数据不加入
+func (s *RocksDBStorage) indexEvicted(wb *gorocksdb.WriteBatch, key []byte, meta *KeyMeta) {
+	if meta.Evicted && meta.EvictedAt > 0 {
+		wb.PutCF(s.metadataCF, evictedIndexKey(s.namespace.Name, meta.EvictedAt, key), nil)
+	}
+}
+
+// unindexEvicted 键被重新写入、删除或再次淘汰时删除其过时的淘汰索引
+// 未配置保留时间时不会清理索引，随键的变化删除使索引只包含当前已淘汰的键
+func (s *RocksDBStorage) unindexEvicted(wb *gorocksdb.WriteBatch, delta *batchDelta) {
+	for _, m := range delta.metas {
+		if m.old == nil || !m.old.Evicted || m.old.EvictedAt == 0 {
+			continue
+		}
+		if m.new != nil && m.new.Evicted && m.new.EvictedAt == m.old.EvictedAt {
+			continue
+		}
+		wb.DeleteCF(s.metadataCF, evictedIndexKey(s.namespace.Name, m.old.EvictedAt, m.key))
+	}
+}
+
+// cleanupEvicted 按淘汰时间顺序删除在before之前淘汰的键，最多检查limit个索引，返回检查的索引数
+// 索引通常已随键的变化删除，残留的过时索引（例如范围删除的清理被中断）只删除索引
+func (s *RocksDBStorage) cleanupEvicted(before time.Time, limit int) (int, error) {
+	prefix := []byte(evictedKeyPrefix + s.namespace.Name + ".")
+
+	// 1. 收集淘汰时间早于before的索引
+	iter := s.db.NewIteratorCF(s.readOpts, s.metadataCF)
+	var indexKeys [][]byte
+	for iter.Seek(prefix); iter.Valid() && len(indexKeys) < limit; iter.Next() {
+		indexKey := iter.Key().Data()
+		if !bytes.HasPrefix(indexKey, prefix) || len(indexKey) < len(prefix)+8 {
+			break
+		}
+		if int64(binary.BigEndian.Uint64(indexKey[len(prefix):])) >= before.Unix() {
+			break
+		}
+		indexKeys = append(indexKeys, append([]byte(nil), indexKey...))
+	}
+	err := iter.Err()
+	iter.Close()
+	if err != nil {
+		return 0, err
+	}
+
+	// 2. 逐个删除仍处于该次淘汰状态的键
+	for _, indexKey := range indexKeys {
+		evictedAt := int64(binary.BigEndian.Uint64(indexKey[len(prefix):]))
+		if err := s.removeEvicted(indexKey[len(prefix)+8:], indexKey, evictedAt); err != nil {
+			return 0, err
+		}
+	}
+	return len(indexKeys), nil
+}
 
-	// 2. 更新RocksDB中的值为已淘汰标记
-	if err := em.storage.db.PutCF(em.storage.writeOpts, em.storage.defaultCF, key, []byte(EvictedValue)); err != nil {
+// removeEvicted 删除键的淘汰标记和索引，键的淘汰时间不是evictedAt时只删除索引
+func (s *RocksDBStorage) removeEvicted(key, indexKey []byte, evictedAt int64) error {
+	unlock := s.locks.lock(key)
+	defer unlock()
+
+	meta, err := s.loadMeta(key)
+	if err != nil {
 		return err
 	}
 
-	// 3. 从创建时间记录中删除
-	return em.storage.removeCreateTime(key)
+	wb := gorocksdb.NewWriteBatch()
+	defer wb.Destroy()
+
+	delta := newBatchDelta()
+	wb.DeleteCF(s.metadataCF, indexKey)
+	if meta != nil && meta.Evicted && meta.EvictedAt == evictedAt {
+		if err := s.deleteKey(wb, delta, key); err != nil {
+			return err
+		}
+	}
+	return s.commit(wb, delta)
+}
+
+// dropEvicted 删除当前命名空间的淘汰索引
+func (s *RocksDBStorage) dropEvicted() error {
+	prefix := []byte(evictedKeyPrefix + s.namespace.Name + ".")
+
+	wb := gorocksdb.NewWriteBatch()
+	defer wb.Destroy()
+
+	wb.DeleteRangeCF(s.metadataCF, prefix, prefixEnd(prefix))
+	return s.db.Write(s.writeOpts, wb)
 }
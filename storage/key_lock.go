@@ -0,0 +1,60 @@
+package storage
+
+import (
+	"hash/fnv"
+	"sort"
+	"sync"
+)
+
+// keyLockStripes 键锁分段数量
+const keyLockStripes = 256
+
+// keyLocks 分段键锁，用于串行化同一个键的读-改-写操作
+type keyLocks struct {
+	stripes [keyLockStripes]sync.Mutex
+}
+
+// stripe 计算键所在的分段
+func (l *keyLocks) stripe(key []byte) int {
+	h := fnv.New32a()
+	h.Write(key)
+	return int(h.Sum32() % keyLockStripes)
+}
+
+// lock 锁定一组键，返回解锁函数
+func (l *keyLocks) lock(keys ...[]byte) func() {
+	// 按分段序号排序并去重，避免死锁
+	seen := make(map[int]bool, len(keys))
+	stripes := make([]int, 0, len(keys))
+	for _, key := range keys {
+		idx := l.stripe(key)
+		if !seen[idx] {
+			seen[idx] = true
+			stripes = append(stripes, idx)
+		}
+	}
+	sort.Ints(stripes)
+
+	for _, idx := range stripes {
+		l.stripes[idx].Lock()
+	}
+
+	return func() {
+		for i := len(stripes) - 1; i >= 0; i-- {
+			l.stripes[stripes[i]].Unlock()
+		}
+	}
+}
+
+// lockAll 锁定所有分段，用于需要暂停全部写入的操作，返回解锁函数
+func (l *keyLocks) lockAll() func() {
+	for i := range l.stripes {
+		l.stripes[i].Lock()
+	}
+
+	return func() {
+		for i := len(l.stripes) - 1; i >= 0; i-- {
+			l.stripes[i].Unlock()
+		}
+	}
+}
@@ -0,0 +1,19 @@
+package storage
+
+import "bytes"
+
+// 键空间：用户键只保存在值、键元数据、创建时间索引和磁盘文件引用计数列族中，
+// 配置、墓碑、命名空间、配额、写回队列、淘汰标记和格式版本等内部记录只保存在元数据列族中，
+// 扫描只遍历用户列族，不会返回内部记录。
+// 以ReservedKeyPrefix开头的用户键保留给内部使用，服务层拒绝写入，扫描跳过这些键。
+
+// ReservedKeyPrefix 保留给内部使用的键前缀
+const ReservedKeyPrefix = "__kvcache__."
+
+// ErrReservedKey 键属于保留的内部键空间
+var ErrReservedKey = NewError(CodeInvalidArgument, "key is reserved for internal use")
+
+// IsReservedKey 判断键是否属于保留的内部键空间
+func IsReservedKey(key []byte) bool {
+	return bytes.HasPrefix(key, []byte(ReservedKeyPrefix))
+}
@@ -0,0 +1,112 @@
+package storage
+
+import (
+	"encoding/binary"
+	"math"
+)
+
+const (
+	// valueMergeOperatorName 合并操作符名称，写入RocksDB后不可修改
+	valueMergeOperatorName = "kvcache.value_merge"
+	// appendOffset 表示追加写入的偏移量标记
+	appendOffset = math.MaxUint64
+	// mergeOperandHeaderSize 合并操作数头部长度（8字节偏移量）
+	mergeOperandHeaderSize = 8
+	// envelopeOperandTag 信封格式合并操作数的首字节
+	// 旧格式操作数以偏移量的最高字节开头，旧版本写入的偏移量不超过磁盘阈值，最高字节只会是0x00或追加标记的0xff
+	envelopeOperandTag = 0x01
+	// envelopeOperandHeaderSize 信封格式合并操作数头部长度（1字节标记 + 8字节版本号 + 8字节偏移量）
+	envelopeOperandHeaderSize = 17
+)
+
+// valueMergeOperator 内联值的合并操作符，支持追加和按偏移量写入
+// 信封格式的操作数在FullMerge中修改信封的载荷和版本号并重新计算校验和，旧格式的操作数直接应用到迁移前的原始值上
+type valueMergeOperator struct{}
+
+// Name 返回合并操作符名称
+func (m *valueMergeOperator) Name() string {
+	return valueMergeOperatorName
+}
+
+// FullMerge 将操作数依次应用到已有值上
+func (m *valueMergeOperator) FullMerge(key, existingValue []byte, operands [][]byte) ([]byte, bool) {
+	if len(operands) > 0 && len(operands[0]) > 0 && operands[0][0] == envelopeOperandTag {
+		return mergeEnvelope(existingValue, operands)
+	}
+
+	value := make([]byte, len(existingValue))
+	copy(value, existingValue)
+
+	for _, operand := range operands {
+		offset, data, ok := decodeMergeOperand(operand)
+		if !ok {
+			return nil, false
+		}
+		value = applyWrite(value, offset, data)
+	}
+
+	return value, true
+}
+
+// mergeEnvelope 将信封格式的操作数应用到内联值的信封上，已有值不是未压缩的内联值时合并失败
+func mergeEnvelope(existingValue []byte, operands [][]byte) ([]byte, bool) {
+	env, err := decodeEnvelope(existingValue)
+	if err != nil || env.Type != valueInline || env.Flags != 0 {
+		return nil, false
+	}
+
+	payload := append([]byte(nil), env.Payload...)
+	for _, operand := range operands {
+		version, offset, data, ok := decodeEnvelopeOperand(operand)
+		if !ok {
+			return nil, false
+		}
+		payload = applyWrite(payload, offset, data)
+		env.Version = version
+	}
+
+	env.Payload = payload
+	return env.encode(), true
+}
+
+// encodeEnvelopeOperand 编码信封格式的合并操作数：1字节标记 + 8字节大端版本号 + 8字节大端偏移量 + 数据
+func encodeEnvelopeOperand(version, offset uint64, data []byte) []byte {
+	operand := make([]byte, envelopeOperandHeaderSize+len(data))
+	operand[0] = envelopeOperandTag
+	binary.BigEndian.PutUint64(operand[1:], version)
+	binary.BigEndian.PutUint64(operand[9:], offset)
+	copy(operand[envelopeOperandHeaderSize:], data)
+	return operand
+}
+
+// decodeEnvelopeOperand 解码信封格式的合并操作数
+func decodeEnvelopeOperand(operand []byte) (uint64, uint64, []byte, bool) {
+	if len(operand) < envelopeOperandHeaderSize || operand[0] != envelopeOperandTag {
+		return 0, 0, nil, false
+	}
+	return binary.BigEndian.Uint64(operand[1:]), binary.BigEndian.Uint64(operand[9:]), operand[envelopeOperandHeaderSize:], true
+}
+
+// decodeMergeOperand 解码合并操作数：8字节大端偏移量 + 数据
+func decodeMergeOperand(operand []byte) (uint64, []byte, bool) {
+	if len(operand) < mergeOperandHeaderSize {
+		return 0, nil, false
+	}
+	return binary.BigEndian.Uint64(operand), operand[mergeOperandHeaderSize:], true
+}
+
+// applyWrite 在指定偏移量写入数据，超出原长度的部分用0填充
+func applyWrite(value []byte, offset uint64, data []byte) []byte {
+	if offset == appendOffset {
+		return append(value, data...)
+	}
+
+	end := int(offset) + len(data)
+	if end > len(value) {
+		grown := make([]byte, end)
+		copy(grown, value)
+		value = grown
+	}
+	copy(value[offset:], data)
+	return value
+}
@@ -0,0 +1,102 @@
+package storage
+
+import (
+	"encoding/json"
+	"time"
+)
+
+const (
+	// LocationInline 值内联存储在RocksDB中
+	LocationInline = "inline"
+	// LocationDisk 值存储在磁盘文件中
+	LocationDisk = "disk"
+
+	// CodecNone 未压缩
+	CodecNone = "none"
+	// EncryptionNone 未加密
+	EncryptionNone = "none"
+
+	// accessTimeGranularity 最后访问时间的持久化粒度，避免每次读取都写入元数据
+	accessTimeGranularity = 60 // 秒
+)
+
+// KeyMeta 键的元数据
+type KeyMeta struct {
+	Size       int64  `json:"size"`
+	CreatedAt  int64  `json:"created_at"`      // 创建时间（Unix秒），与创建时间索引一致
+	UpdatedAt  int64  `json:"updated_at"`      // 最后修改时间（Unix秒）
+	LastAccess int64  `json:"last_access"`     // 最后访问时间（Unix秒）
+	Version    uint64 `json:"version"`         // 每次写入递增
+	Stamp      uint64 `json:"stamp,omitempty"` // 带版本写入的时间戳，普通写入为0
+	ExpiresAt  int64  `json:"expires_at"`      // 过期时间（Unix秒），0表示永不过期
+	Location   string `json:"location"`
+	DiskFile   string `json:"disk_file,omitempty"` // 磁盘存储的文件名（相对于Value.DiskPath）
+	Codec      string `json:"codec"`
+	Encryption string `json:"encryption"`
+	Evicted    bool   `json:"evicted"`
+	EvictedAt  int64  `json:"evicted_at,omitempty"` // 淘汰时间（Unix秒），未淘汰时为0
+}
+
+// KeyInfo 键的元数据查询结果
+type KeyInfo struct {
+	KeyMeta
+	TTL      time.Duration // 剩余存活时间，-1表示永不过期
+	DiskPath string        // 磁盘存储的完整路径，仅磁盘存储的值有效
+}
+
+// newKeyMeta 基于旧元数据创建新一次写入的元数据，保留创建时间并递增版本号
+func newKeyMeta(old *KeyMeta, now time.Time) *KeyMeta {
+	meta := &KeyMeta{
+		CreatedAt:  now.Unix(),
+		UpdatedAt:  now.Unix(),
+		LastAccess: now.Unix(),
+		Version:    1,
+		Codec:      CodecNone,
+		Encryption: EncryptionNone,
+	}
+	if old != nil {
+		meta.CreatedAt = old.CreatedAt
+		meta.Version = old.Version + 1
+	}
+	return meta
+}
+
+// RemainingTTL 返回剩余存活时间，永不过期时返回-1
+func (m *KeyMeta) RemainingTTL(now time.Time) time.Duration {
+	if m.ExpiresAt == 0 {
+		return -1
+	}
+	remaining := time.Unix(m.ExpiresAt, 0).Sub(now)
+	if remaining < 0 {
+		return 0
+	}
+	return remaining
+}
+
+// Expired 判断键是否已过期
+func (m *KeyMeta) Expired(now time.Time) bool {
+	return m.ExpiresAt > 0 && now.Unix() >= m.ExpiresAt
+}
+
+// encodeKeyMeta 序列化元数据
+func encodeKeyMeta(meta *KeyMeta) ([]byte, error) {
+	return json.Marshal(meta)
+}
+
+// decodeKeyMeta 反序列化元数据
+func decodeKeyMeta(data []byte) (*KeyMeta, error) {
+	meta := &KeyMeta{}
+	if err := json.Unmarshal(data, meta); err != nil {
+		return nil, err
+	}
+	return meta, nil
+}
+
+// expireAt 根据TTL计算过期时间，ttl<=0表示永不过期
+func expireAt(now time.Time, ttl time.Duration) int64 {
+	if ttl <= 0 {
+		return 0
+	}
+	// 向上取整到秒，保证至少存活ttl
+	return now.Add(ttl + time.Second - 1).Unix()
+}
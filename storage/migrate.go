@@ -0,0 +1,190 @@
+package storage
+
+import (
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"strconv"
+
+	"kvcache/config"
+
+	gorocksdb "github.com/linxGnu/grocksdb"
+)
+
+const (
+	// FormatVersion 当前版本写入的数据格式版本
+	FormatVersion = 1
+	// formatVersionKey 数据格式版本在元数据列族中的键，值为8字节大端版本号，不存在时为0
+	formatVersionKey = "format.version"
+	// migrationProgressPrefix 迁移进度在元数据列族中的键前缀，后接版本号和范围，值为该范围内最后一个已转换的键
+	migrationProgressPrefix = "format.progress."
+	// migrationBatchSize 迁移时每个批处理写入的条目数
+	migrationBatchSize = 1000
+)
+
+// ErrFormatTooNew 数据由更新的版本写入，当前版本无法读取
+var ErrFormatTooNew = errors.New("data format is newer than supported")
+
+// migration 数据格式迁移步骤，将数据从version-1升级到version
+// 步骤需要可重复执行：批处理通过migrationRun提交并记录进度，中断后从进度继续
+type migration struct {
+	version     int
+	description string
+	run         func(s *RocksDBStorage, m *migrationRun) error
+}
+
+// migrations 按版本排序的迁移步骤，新增步骤追加在末尾并同时增加FormatVersion
+var migrations = []migration{
+	{version: 1, description: "wrap stored values and tombstones in the value envelope", run: (*RocksDBStorage).migrateEnvelopes},
+}
+
+// MigrationStep 一个迁移步骤的执行结果
+type MigrationStep struct {
+	Version     int    `json:"version"`
+	Description string `json:"description"`
+	Entries     int    `json:"entries"` // 已转换的条目数，试运行时为需要转换的条目数
+}
+
+// MigrationReport 数据格式迁移的结果
+type MigrationReport struct {
+	From   int             `json:"from"`
+	To     int             `json:"to"`
+	DryRun bool            `json:"dry_run"`
+	Steps  []MigrationStep `json:"steps"`
+}
+
+// migrationRun 一个迁移步骤的执行状态，试运行时只统计条目数，不写入数据
+type migrationRun struct {
+	storage *RocksDBStorage
+	version int
+	dryRun  bool
+	entries int
+}
+
+// Migrate 打开数据目录并将数据升级到当前格式
+// dryRun为true时以只读方式打开，只统计各步骤需要转换的条目数，之后的步骤按尚未转换的数据统计
+func Migrate(cfg *config.Config, dryRun bool) (*MigrationReport, error) {
+	s, err := NewRocksDBStorage(cfg)
+	if err != nil {
+		return nil, err
+	}
+	defer s.Stop()
+
+	if err := s.initRocksDB(dryRun); err != nil {
+		return nil, err
+	}
+	return s.migrate(dryRun)
+}
+
+// formatVersion 读取数据格式版本
+func (s *RocksDBStorage) formatVersion() (int, error) {
+	// 只读打开的旧数据库可能没有元数据列族
+	if s.metadataCF == nil {
+		return 0, nil
+	}
+
+	value, err := s.db.GetCF(s.readOpts, s.metadataCF, []byte(formatVersionKey))
+	if err != nil {
+		return 0, err
+	}
+	defer value.Free()
+
+	switch value.Size() {
+	case 0:
+		return 0, nil
+	case 8:
+		return int(binary.BigEndian.Uint64(value.Data())), nil
+	default:
+		return 0, fmt.Errorf("invalid data format version %q", value.Data())
+	}
+}
+
+// migrate 依次执行版本高于当前数据格式的迁移步骤，每个步骤完成后记录新的格式版本
+func (s *RocksDBStorage) migrate(dryRun bool) (*MigrationReport, error) {
+	// 1. 数据格式比当前版本新时拒绝打开
+	version, err := s.formatVersion()
+	if err != nil {
+		return nil, err
+	}
+	if version > FormatVersion {
+		return nil, fmt.Errorf("%w: data format version %d, supported version %d", ErrFormatTooNew, version, FormatVersion)
+	}
+
+	report := &MigrationReport{From: version, To: version, DryRun: dryRun}
+	for _, step := range migrations {
+		if step.version <= version {
+			continue
+		}
+
+		// 2. 执行迁移步骤
+		run := &migrationRun{storage: s, version: step.version, dryRun: dryRun}
+		if err := step.run(s, run); err != nil {
+			return report, fmt.Errorf("migration to format version %d failed: %v", step.version, err)
+		}
+		report.Steps = append(report.Steps, MigrationStep{Version: step.version, Description: step.description, Entries: run.entries})
+		report.To = step.version
+		if dryRun {
+			continue
+		}
+
+		// 3. 记录新的格式版本并删除该步骤的进度
+		wb := gorocksdb.NewWriteBatch()
+		wb.PutCF(s.metadataCF, []byte(formatVersionKey), encodeRevision(uint64(step.version)))
+		progress := run.progressKey("")
+		wb.DeleteRangeCF(s.metadataCF, progress, prefixEnd(progress))
+		err := s.db.Write(s.writeOpts, wb)
+		wb.Destroy()
+		if err != nil {
+			return report, err
+		}
+	}
+
+	return report, nil
+}
+
+// progressKey 返回范围的迁移进度在元数据列族中的键
+func (m *migrationRun) progressKey(scope string) []byte {
+	return []byte(migrationProgressPrefix + strconv.Itoa(m.version) + "." + scope)
+}
+
+// progress 返回范围内上次中断前最后一个已转换的键，没有进度时返回nil
+func (m *migrationRun) progress(scope string) ([]byte, error) {
+	s := m.storage
+	if s.metadataCF == nil {
+		return nil, nil
+	}
+
+	value, err := s.db.GetCF(s.readOpts, s.metadataCF, m.progressKey(scope))
+	if err != nil {
+		return nil, err
+	}
+	defer value.Free()
+
+	if value.Size() == 0 {
+		return nil, nil
+	}
+	return append([]byte(nil), value.Data()...), nil
+}
+
+// put 将一个条目的转换结果写入批处理，返回批处理是否已满
+func (m *migrationRun) put(wb *gorocksdb.WriteBatch, cf *gorocksdb.ColumnFamilyHandle, key, value []byte) bool {
+	m.entries++
+	if !m.dryRun {
+		wb.PutCF(cf, key, value)
+	}
+	return m.entries%migrationBatchSize == 0
+}
+
+// flush 提交批处理并记录范围的进度，last为nil时不记录进度，试运行时丢弃批处理
+func (m *migrationRun) flush(wb *gorocksdb.WriteBatch, scope string, last []byte) error {
+	defer wb.Clear()
+	if m.dryRun {
+		return nil
+	}
+
+	s := m.storage
+	if last != nil {
+		wb.PutCF(s.metadataCF, m.progressKey(scope), last)
+	}
+	return s.db.Write(s.writeOpts, wb)
+}
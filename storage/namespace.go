@@ -0,0 +1,323 @@
+package storage
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"sort"
+	"sync"
+
+	"kvcache/config"
+
+	gorocksdb "github.com/linxGnu/grocksdb"
+)
+
+const (
+	// namespaceCFPrefix 命名空间列族名称前缀
+	namespaceCFPrefix = "ns."
+	// namespaceKeyPrefix 命名空间注册信息在元数据列族中的键前缀
+	namespaceKeyPrefix = "namespace."
+)
+
+// namespaceCFNames 返回命名空间使用的列族：值、键元数据、创建时间索引、磁盘文件引用计数
+func namespaceCFNames(name string) []string {
+	base := namespaceCFPrefix + name
+	return []string{base, base + "." + KeyMetaCF, base + "." + CreateTimeCF, base + "." + BlobRefsCF}
+}
+
+// namespaceRegistry 命名空间注册表，由默认命名空间和所有命名空间实例共享
+type namespaceRegistry struct {
+	mu      sync.RWMutex
+	root    *RocksDBStorage
+	views   map[string]*RocksDBStorage
+	handles map[string]*gorocksdb.ColumnFamilyHandle // 所有已打开的列族
+	dropped []*gorocksdb.ColumnFamilyHandle          // 已删除的列族，停止时统一释放
+}
+
+// newNamespaceRegistry 创建命名空间注册表
+func newNamespaceRegistry(root *RocksDBStorage) *namespaceRegistry {
+	return &namespaceRegistry{
+		root:    root,
+		views:   make(map[string]*RocksDBStorage),
+		handles: make(map[string]*gorocksdb.ColumnFamilyHandle),
+	}
+}
+
+// list 返回所有命名空间实例（不含默认命名空间）
+func (r *namespaceRegistry) list() []*RocksDBStorage {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	views := make([]*RocksDBStorage, 0, len(r.views))
+	for _, view := range r.views {
+		views = append(views, view)
+	}
+	return views
+}
+
+// refresh 全局配置变化后重新计算各命名空间生效的配置并重启淘汰管理器
+func (r *namespaceRegistry) refresh(cfg *config.Config) error {
+	for _, view := range r.list() {
+		view.StopEvictionManager()
+		view.config = view.namespace.Apply(cfg)
+		if view.config.Eviction.Enabled {
+			if err := view.StartEvictionManager(); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+// destroyHandles 释放所有列族句柄
+func (r *namespaceRegistry) destroyHandles() {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	for name, handle := range r.handles {
+		handle.Destroy()
+		delete(r.handles, name)
+	}
+	for _, handle := range r.dropped {
+		handle.Destroy()
+	}
+	r.dropped = nil
+}
+
+// isRoot 判断是否为默认命名空间
+func (s *RocksDBStorage) isRoot() bool {
+	return s.namespaces.root == s
+}
+
+// Namespace 返回指定命名空间的存储实例，空名称表示默认命名空间
+func (s *RocksDBStorage) Namespace(name string) (Storage, error) {
+	if name == "" || name == config.DefaultNamespace {
+		return s.namespaces.root, nil
+	}
+
+	s.namespaces.mu.RLock()
+	defer s.namespaces.mu.RUnlock()
+
+	view, ok := s.namespaces.views[name]
+	if !ok {
+		return nil, Errorf(CodeNotFound, "namespace %s not found", name)
+	}
+	return view, nil
+}
+
+// GetNamespace 获取命名空间配置
+func (s *RocksDBStorage) GetNamespace(name string) (*config.NamespaceConfig, error) {
+	if name == "" || name == config.DefaultNamespace {
+		return &config.NamespaceConfig{Name: config.DefaultNamespace}, nil
+	}
+
+	s.namespaces.mu.RLock()
+	defer s.namespaces.mu.RUnlock()
+
+	view, ok := s.namespaces.views[name]
+	if !ok {
+		return nil, Errorf(CodeNotFound, "namespace %s not found", name)
+	}
+	nsCfg := *view.namespace
+	return &nsCfg, nil
+}
+
+// ListNamespaces 列出所有命名空间，默认命名空间排在第一位
+func (s *RocksDBStorage) ListNamespaces() ([]*config.NamespaceConfig, error) {
+	s.namespaces.mu.RLock()
+	defer s.namespaces.mu.RUnlock()
+
+	names := make([]string, 0, len(s.namespaces.views))
+	for name := range s.namespaces.views {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	namespaces := []*config.NamespaceConfig{{Name: config.DefaultNamespace}}
+	for _, name := range names {
+		nsCfg := *s.namespaces.views[name].namespace
+		namespaces = append(namespaces, &nsCfg)
+	}
+	return namespaces, nil
+}
+
+// CreateNamespace 创建命名空间，分配独立的列族和磁盘存储目录
+func (s *RocksDBStorage) CreateNamespace(nsCfg *config.NamespaceConfig) error {
+	if !config.ValidNamespaceName(nsCfg.Name) || nsCfg.Name == config.DefaultNamespace {
+		return Errorf(CodeInvalidArgument, "invalid namespace name: %s", nsCfg.Name)
+	}
+	if nsCfg.DefaultTTL < 0 {
+		return NewError(CodeInvalidArgument, "default ttl cannot be negative")
+	}
+
+	root := s.namespaces.root
+	s.namespaces.mu.Lock()
+	defer s.namespaces.mu.Unlock()
+
+	if _, ok := s.namespaces.views[nsCfg.Name]; ok {
+		return Errorf(CodeConflict, "namespace %s already exists", nsCfg.Name)
+	}
+
+	// 1. 写入注册信息，列族创建失败时启动阶段会补齐
+	data, err := json.Marshal(nsCfg)
+	if err != nil {
+		return err
+	}
+	if err := root.db.PutCF(root.writeOpts, root.metadataCF, []byte(namespaceKeyPrefix+nsCfg.Name), data); err != nil {
+		return err
+	}
+
+	// 2. 打开命名空间
+	created := *nsCfg
+	return root.openNamespace(&created)
+}
+
+// DropNamespace 删除命名空间及其全部数据
+func (s *RocksDBStorage) DropNamespace(name string) error {
+	if name == "" || name == config.DefaultNamespace {
+		return NewError(CodeInvalidArgument, "cannot drop the default namespace")
+	}
+
+	root := s.namespaces.root
+	s.namespaces.mu.Lock()
+	defer s.namespaces.mu.Unlock()
+
+	view, ok := s.namespaces.views[name]
+	if !ok {
+		return Errorf(CodeNotFound, "namespace %s not found", name)
+	}
+
+	// 1. 停止淘汰，移除注册信息、配额、墓碑、写回标记、淘汰索引和清理任务记录
+	// 任一步骤失败时命名空间仍保留在注册表中，重新删除即可完成剩余的步骤
+	view.StopEvictionManager()
+	if err := view.dropQuotas(); err != nil {
+		return err
+	}
+	if err := view.dropTombstones(); err != nil {
+		return err
+	}
+	if err := view.dropWriteBehind(); err != nil {
+		return err
+	}
+	if err := view.dropEvicted(); err != nil {
+		return err
+	}
+	if err := view.dropDeleteJobs(); err != nil {
+		return err
+	}
+	if err := root.db.DeleteCF(root.writeOpts, root.metadataCF, []byte(namespaceKeyPrefix+name)); err != nil {
+		return err
+	}
+
+	// 2. 删除列族，句柄可能仍被进行中的请求使用，停止时再释放
+	for _, cfName := range namespaceCFNames(name) {
+		handle, ok := s.namespaces.handles[cfName]
+		if !ok {
+			continue
+		}
+		if err := root.db.DropColumnFamily(handle); err != nil {
+			return fmt.Errorf("failed to drop column family %s: %v", cfName, err)
+		}
+		delete(s.namespaces.handles, cfName)
+		s.namespaces.dropped = append(s.namespaces.dropped, handle)
+	}
+
+	// 3. 元数据和列族删除成功后从注册表中移除，再删除磁盘存储目录
+	delete(s.namespaces.views, name)
+	return os.RemoveAll(view.diskStore.basePath)
+}
+
+// loadNamespaces 启动时加载元数据列族中注册的命名空间
+func (s *RocksDBStorage) loadNamespaces() error {
+	iter := s.db.NewIteratorCF(s.readOpts, s.metadataCF)
+	defer iter.Close()
+
+	var namespaces []*config.NamespaceConfig
+	prefix := []byte(namespaceKeyPrefix)
+	for iter.Seek(prefix); iter.Valid(); iter.Next() {
+		key := iter.Key().Data()
+		if len(key) < len(prefix) || string(key[:len(prefix)]) != namespaceKeyPrefix {
+			break
+		}
+
+		nsCfg := &config.NamespaceConfig{}
+		if err := json.Unmarshal(iter.Value().Data(), nsCfg); err != nil {
+			return fmt.Errorf("failed to decode namespace %s: %v", key, err)
+		}
+		namespaces = append(namespaces, nsCfg)
+	}
+	if err := iter.Err(); err != nil {
+		return err
+	}
+
+	s.namespaces.mu.Lock()
+	defer s.namespaces.mu.Unlock()
+
+	for _, nsCfg := range namespaces {
+		if err := s.openNamespace(nsCfg); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// openNamespace 创建命名空间实例，缺失的列族会被创建，调用方需持有注册表写锁
+func (s *RocksDBStorage) openNamespace(nsCfg *config.NamespaceConfig) error {
+	// 1. 打开或创建列族
+	cfNames := namespaceCFNames(nsCfg.Name)
+	handles := make([]*gorocksdb.ColumnFamilyHandle, len(cfNames))
+	for i, cfName := range cfNames {
+		handle, ok := s.namespaces.handles[cfName]
+		if !ok {
+			var err error
+			handle, err = s.db.CreateColumnFamily(s.cfOpts, cfName)
+			if err != nil {
+				return fmt.Errorf("failed to create column family %s: %v", cfName, err)
+			}
+			s.namespaces.handles[cfName] = handle
+		}
+		handles[i] = handle
+	}
+
+	// 2. 创建独立的磁盘存储目录
+	nsConfig := nsCfg.Apply(s.config)
+	diskStore, err := NewDiskStore(nsConfig.Value.DiskPath)
+	if err != nil {
+		return err
+	}
+
+	// 3. 共享数据库和锁，使用命名空间自己的列族、磁盘存储和配置
+	view := &RocksDBStorage{
+		db:           s.db,
+		opts:         s.opts,
+		cfOpts:       s.cfOpts,
+		readOpts:     s.readOpts,
+		writeOpts:    s.writeOpts,
+		defaultCF:    handles[0],
+		keyMetaCF:    handles[1],
+		createTimeCF: handles[2],
+		blobRefsCF:   handles[3],
+		metadataCF:   s.metadataCF,
+		config:       nsConfig,
+		diskStore:    diskStore,
+		locks:        s.locks,
+		deleteJobs:   s.deleteJobs,
+		changes:      s.changes,
+		quotas:       newQuotaSet(),
+		namespace:    nsCfg,
+		namespaces:   s.namespaces,
+	}
+	if err := view.loadQuotas(); err != nil {
+		return err
+	}
+	if err := view.resumeDeleteJobs(); err != nil {
+		return err
+	}
+	s.namespaces.views[nsCfg.Name] = view
+
+	// 4. 启动命名空间的淘汰管理器
+	if nsConfig.Eviction.Enabled {
+		return view.StartEvictionManager()
+	}
+	return nil
+}
@@ -0,0 +1,462 @@
+package storage
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"sort"
+	"strings"
+	"sync"
+
+	"kvcache/config"
+
+	gorocksdb "github.com/linxGnu/grocksdb"
+)
+
+// quotaKeyPrefix 配额及其用量在元数据列族中的键前缀，完整的键为 quota.<命名空间>.<前缀>
+const quotaKeyPrefix = "quota."
+
+// ErrQuotaExceeded 写入超出配额
+var ErrQuotaExceeded = NewError(CodeResourceExhausted, "quota exceeded")
+
+// QuotaUsage 配额范围内的用量
+type QuotaUsage struct {
+	Keys        int64 `json:"keys"`
+	InlineBytes int64 `json:"inline_bytes"`
+	DiskBytes   int64 `json:"disk_bytes"`
+}
+
+// add 累加用量
+func (u QuotaUsage) add(o QuotaUsage) QuotaUsage {
+	return QuotaUsage{Keys: u.Keys + o.Keys, InlineBytes: u.InlineBytes + o.InlineBytes, DiskBytes: u.DiskBytes + o.DiskBytes}
+}
+
+// sub 扣减用量
+func (u QuotaUsage) sub(o QuotaUsage) QuotaUsage {
+	return QuotaUsage{Keys: u.Keys - o.Keys, InlineBytes: u.InlineBytes - o.InlineBytes, DiskBytes: u.DiskBytes - o.DiskBytes}
+}
+
+// metaUsage 计算单个键占用的配额，已淘汰的值只计入键数量
+func metaUsage(meta *KeyMeta) QuotaUsage {
+	if meta == nil {
+		return QuotaUsage{}
+	}
+
+	usage := QuotaUsage{Keys: 1}
+	if meta.Evicted {
+		return usage
+	}
+	if meta.Location == LocationDisk {
+		usage.DiskBytes = meta.Size
+	} else {
+		usage.InlineBytes = meta.Size
+	}
+	return usage
+}
+
+// QuotaStatus 配额及其当前用量
+type QuotaStatus struct {
+	Namespace string `json:"namespace"`
+	config.QuotaConfig
+	Usage QuotaUsage `json:"usage"`
+}
+
+// quota 已注册的配额
+type quota struct {
+	config.QuotaConfig
+	usage    QuotaUsage
+	seq      uint64 // 注册序号，重新设置配额时递增
+	evicting bool   // 是否有后台淘汰正在进行
+}
+
+// exceeded 判断用量是否超出任一限制
+func (q *quota) exceeded(usage QuotaUsage) bool {
+	return (q.MaxKeys > 0 && usage.Keys > q.MaxKeys) ||
+		(q.MaxInlineBytes > 0 && usage.InlineBytes > q.MaxInlineBytes) ||
+		(q.MaxDiskBytes > 0 && usage.DiskBytes > q.MaxDiskBytes)
+}
+
+// violation 返回写入后超出且有所增长的限制，未超出时返回空字符串
+func (q *quota) violation(old, new QuotaUsage) string {
+	switch {
+	case q.MaxKeys > 0 && new.Keys > q.MaxKeys && new.Keys > old.Keys:
+		return fmt.Sprintf("keys %d exceeds limit %d", new.Keys, q.MaxKeys)
+	case q.MaxInlineBytes > 0 && new.InlineBytes > q.MaxInlineBytes && new.InlineBytes > old.InlineBytes:
+		return fmt.Sprintf("inline bytes %d exceeds limit %d", new.InlineBytes, q.MaxInlineBytes)
+	case q.MaxDiskBytes > 0 && new.DiskBytes > q.MaxDiskBytes && new.DiskBytes > old.DiskBytes:
+		return fmt.Sprintf("disk bytes %d exceeds limit %d", new.DiskBytes, q.MaxDiskBytes)
+	}
+	return ""
+}
+
+// quotaUpdate 一次批处理提交后配额的新用量
+type quotaUpdate struct {
+	*quota
+	usage QuotaUsage
+}
+
+// quotaSet 一个命名空间的配额，用量的修改由blobMu串行化
+type quotaSet struct {
+	mu     sync.Mutex
+	seq    uint64
+	quotas map[string]*quota // 前缀 -> 配额
+	stop   chan struct{}
+	once   sync.Once
+	wg     sync.WaitGroup
+}
+
+// newQuotaSet 创建配额集合
+func newQuotaSet() *quotaSet {
+	return &quotaSet{
+		quotas: make(map[string]*quota),
+		stop:   make(chan struct{}),
+	}
+}
+
+// close 通知后台淘汰停止并等待退出
+func (qs *quotaSet) close() {
+	qs.once.Do(func() {
+		close(qs.stop)
+	})
+	qs.wg.Wait()
+}
+
+// current 返回最新的注册序号
+func (qs *quotaSet) current() uint64 {
+	qs.mu.Lock()
+	defer qs.mu.Unlock()
+	return qs.seq
+}
+
+// active 判断是否注册了配额
+func (qs *quotaSet) active() bool {
+	qs.mu.Lock()
+	defer qs.mu.Unlock()
+	return len(qs.quotas) > 0
+}
+
+// set 注册或替换配额
+func (qs *quotaSet) set(qc config.QuotaConfig, usage QuotaUsage) *quota {
+	qs.mu.Lock()
+	defer qs.mu.Unlock()
+
+	qs.seq++
+	q := &quota{QuotaConfig: qc, usage: usage, seq: qs.seq}
+	qs.quotas[qc.Prefix] = q
+	return q
+}
+
+// remove 移除配额
+func (qs *quotaSet) remove(prefix string) bool {
+	qs.mu.Lock()
+	defer qs.mu.Unlock()
+
+	if _, ok := qs.quotas[prefix]; !ok {
+		return false
+	}
+	delete(qs.quotas, prefix)
+	return true
+}
+
+// usage 返回配额的当前用量，配额已被移除或替换时返回false
+func (qs *quotaSet) usage(q *quota) (QuotaUsage, bool) {
+	qs.mu.Lock()
+	defer qs.mu.Unlock()
+	return q.usage, qs.quotas[q.Prefix] == q
+}
+
+// list 按前缀顺序返回配额及用量
+func (qs *quotaSet) list(namespace string) []*QuotaStatus {
+	qs.mu.Lock()
+	defer qs.mu.Unlock()
+
+	statuses := make([]*QuotaStatus, 0, len(qs.quotas))
+	for _, q := range qs.quotas {
+		statuses = append(statuses, &QuotaStatus{Namespace: namespace, QuotaConfig: q.QuotaConfig, Usage: q.usage})
+	}
+	sort.Slice(statuses, func(i, j int) bool {
+		return statuses[i].Prefix < statuses[j].Prefix
+	})
+	return statuses
+}
+
+// prepare 计算批处理对各配额用量的影响，拒绝超出限制的写入，调用方需持有blobMu
+func (qs *quotaSet) prepare(delta *batchDelta) ([]quotaUpdate, error) {
+	if len(delta.metas) == 0 {
+		return nil, nil
+	}
+
+	qs.mu.Lock()
+	defer qs.mu.Unlock()
+
+	var updates []quotaUpdate
+	for _, q := range qs.quotas {
+		// 配额在范围删除之后注册时，统计结果已不包含被删除的键
+		if q.seq > delta.quotaSeq {
+			continue
+		}
+
+		var change QuotaUsage
+		prefix := []byte(q.Prefix)
+		for _, m := range delta.metas {
+			if bytes.HasPrefix(m.key, prefix) {
+				change = change.add(metaUsage(m.new)).sub(metaUsage(m.old))
+			}
+		}
+		if change == (QuotaUsage{}) {
+			continue
+		}
+
+		usage := q.usage.add(change)
+		if q.Action == config.QuotaActionReject && delta.replicated == nil {
+			if violation := q.violation(q.usage, usage); violation != "" {
+				return nil, fmt.Errorf("%w: prefix %q %s", ErrQuotaExceeded, q.Prefix, violation)
+			}
+		}
+		updates = append(updates, quotaUpdate{quota: q, usage: usage})
+	}
+
+	return updates, nil
+}
+
+// apply 批处理提交成功后更新内存中的用量
+func (qs *quotaSet) apply(updates []quotaUpdate) {
+	if len(updates) == 0 {
+		return
+	}
+
+	qs.mu.Lock()
+	defer qs.mu.Unlock()
+
+	for _, update := range updates {
+		update.quota.usage = update.usage
+	}
+}
+
+// quotaKey 返回配额在元数据列族中的键
+func (s *RocksDBStorage) quotaKey(prefix string) []byte {
+	return []byte(quotaKeyPrefix + s.namespace.Name + "." + prefix)
+}
+
+// putQuota 将配额及用量写入批处理
+func (s *RocksDBStorage) putQuota(wb *gorocksdb.WriteBatch, qc config.QuotaConfig, usage QuotaUsage) error {
+	data, err := json.Marshal(&QuotaStatus{Namespace: s.namespace.Name, QuotaConfig: qc, Usage: usage})
+	if err != nil {
+		return err
+	}
+	wb.PutCF(s.metadataCF, s.quotaKey(qc.Prefix), data)
+	return nil
+}
+
+// SetQuota 设置命名空间或键前缀的配额，并重新统计范围内的用量
+func (s *RocksDBStorage) SetQuota(qc *config.QuotaConfig) error {
+	if err := qc.Validate(); err != nil {
+		return NewError(CodeInvalidArgument, err.Error())
+	}
+
+	// 持有blobMu期间没有其他批处理修改用量
+	s.blobMu.Lock()
+	defer s.blobMu.Unlock()
+
+	// 1. 统计当前用量
+	usage, err := s.scanUsage([]byte(qc.Prefix))
+	if err != nil {
+		return err
+	}
+
+	// 2. 持久化配额
+	wb := gorocksdb.NewWriteBatch()
+	defer wb.Destroy()
+
+	if err := s.putQuota(wb, *qc, usage); err != nil {
+		return err
+	}
+	if err := s.db.Write(s.writeOpts, wb); err != nil {
+		return err
+	}
+
+	// 3. 注册配额，已超出时按配置淘汰
+	q := s.quotas.set(*qc, usage)
+	if q.Action == config.QuotaActionEvict && q.exceeded(usage) {
+		s.startQuotaEviction(q)
+	}
+
+	return nil
+}
+
+// DeleteQuota 删除配额
+func (s *RocksDBStorage) DeleteQuota(prefix string) error {
+	s.blobMu.Lock()
+	defer s.blobMu.Unlock()
+
+	if !s.quotas.remove(prefix) {
+		return Errorf(CodeNotFound, "quota for prefix %q not found", prefix)
+	}
+	return s.db.DeleteCF(s.writeOpts, s.metadataCF, s.quotaKey(prefix))
+}
+
+// ListQuotas 列出当前命名空间的配额及用量
+func (s *RocksDBStorage) ListQuotas() ([]*QuotaStatus, error) {
+	return s.quotas.list(s.namespace.Name), nil
+}
+
+// scanUsage 遍历键元数据统计前缀下的用量，没有元数据的旧数据不计入
+func (s *RocksDBStorage) scanUsage(prefix []byte) (QuotaUsage, error) {
+	readOpts := gorocksdb.NewDefaultReadOptions()
+	defer readOpts.Destroy()
+	if end := prefixEnd(prefix); end != nil {
+		readOpts.SetIterateUpperBound(end)
+	}
+
+	iter := s.db.NewIteratorCF(readOpts, s.keyMetaCF)
+	defer iter.Close()
+
+	var usage QuotaUsage
+	for iter.Seek(prefix); iter.Valid(); iter.Next() {
+		meta, err := decodeKeyMeta(iter.Value().Data())
+		if err != nil {
+			continue
+		}
+		usage = usage.add(metaUsage(meta))
+	}
+
+	return usage, iter.Err()
+}
+
+// loadQuotas 启动时加载当前命名空间的配额
+func (s *RocksDBStorage) loadQuotas() error {
+	prefix := []byte(quotaKeyPrefix + s.namespace.Name + ".")
+	iter := s.db.NewIteratorCF(s.readOpts, s.metadataCF)
+	defer iter.Close()
+
+	for iter.Seek(prefix); iter.Valid(); iter.Next() {
+		if !bytes.HasPrefix(iter.Key().Data(), prefix) {
+			break
+		}
+
+		status := &QuotaStatus{}
+		if err := json.Unmarshal(iter.Value().Data(), status); err != nil {
+			return fmt.Errorf("failed to decode quota %s: %v", iter.Key().Data(), err)
+		}
+		s.quotas.set(status.QuotaConfig, status.Usage)
+	}
+
+	return iter.Err()
+}
+
+// recountQuotas 重新统计当前命名空间全部配额的用量，并将结果写入批处理
+func (s *RocksDBStorage) recountQuotas(wb *gorocksdb.WriteBatch) error {
+	s.blobMu.Lock()
+	defer s.blobMu.Unlock()
+
+	for _, status := range s.quotas.list(s.namespace.Name) {
+		usage, err := s.scanUsage([]byte(status.Prefix))
+		if err != nil {
+			return err
+		}
+		if usage == status.Usage {
+			continue
+		}
+		s.quotas.set(status.QuotaConfig, usage)
+		if err := s.putQuota(wb, status.QuotaConfig, usage); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// dropQuotas 删除当前命名空间的全部配额
+func (s *RocksDBStorage) dropQuotas() error {
+	s.quotas.close()
+
+	wb := gorocksdb.NewWriteBatch()
+	defer wb.Destroy()
+
+	for _, status := range s.quotas.list(s.namespace.Name) {
+		wb.DeleteCF(s.metadataCF, s.quotaKey(status.Prefix))
+	}
+	return s.db.Write(s.writeOpts, wb)
+}
+
+// startQuotaEviction 在后台淘汰超出配额的键，同一配额同时只有一个淘汰任务
+func (s *RocksDBStorage) startQuotaEviction(q *quota) {
+	s.quotas.mu.Lock()
+	defer s.quotas.mu.Unlock()
+
+	select {
+	case <-s.quotas.stop:
+		return
+	default:
+	}
+	if q.evicting {
+		return
+	}
+	q.evicting = true
+
+	s.quotas.wg.Add(1)
+	go func() {
+		defer s.quotas.wg.Done()
+		if err := s.evictQuota(q); err != nil {
+			fmt.Printf("quota eviction failed: %v\n", err)
+		}
+
+		s.quotas.mu.Lock()
+		q.evicting = false
+		s.quotas.mu.Unlock()
+	}()
+}
+
+// evictQuota 按创建时间顺序回收配额范围内的键，直到用量回到限制以内
+func (s *RocksDBStorage) evictQuota(q *quota) error {
+	iter := s.db.NewIteratorCF(s.readOpts, s.createTimeCF)
+	defer iter.Close()
+
+	for iter.SeekToFirst(); iter.Valid(); iter.Next() {
+		var keys []string
+		if err := json.Unmarshal(iter.Value().Data(), &keys); err != nil {
+			continue
+		}
+
+		for _, keyStr := range keys {
+			select {
+			case <-s.quotas.stop:
+				return nil
+			default:
+			}
+
+			usage, ok := s.quotas.usage(q)
+			if !ok || !q.exceeded(usage) {
+				return nil
+			}
+			if !strings.HasPrefix(keyStr, q.Prefix) {
+				continue
+			}
+
+			if err := s.reclaimKey([]byte(keyStr), q, usage); err != nil {
+				return err
+			}
+		}
+	}
+
+	return iter.Err()
+}
+
+// reclaimKey 回收单个键：键数量或内联字节超限时删除键，仅磁盘字节超限时淘汰磁盘存储的值
+func (s *RocksDBStorage) reclaimKey(key []byte, q *quota, usage QuotaUsage) error {
+	if (q.MaxKeys > 0 && usage.Keys > q.MaxKeys) || (q.MaxInlineBytes > 0 && usage.InlineBytes > q.MaxInlineBytes) {
+		return s.Delete(key)
+	}
+
+	value, err := s.db.GetCF(s.readOpts, s.defaultCF, key)
+	if err != nil {
+		return err
+	}
+	raw := make([]byte, value.Size())
+	copy(raw, value.Data())
+	value.Free()
+
+	if env, err := decodeEnvelope(raw); err != nil || env.Type != valueDisk {
+		return nil
+	}
+	return s.evictValue(key, raw)
+}
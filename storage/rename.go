@@ -0,0 +1,188 @@
+package storage
+
+import (
+	"time"
+
+	gorocksdb "github.com/linxGnu/grocksdb"
+)
+
+// Rename 原子地将键重命名为dst，overwrite为false时目标键已存在则返回错误
+// 磁盘存储的值只移动指针，不复制文件内容
+func (s *RocksDBStorage) Rename(src, dst []byte, overwrite bool) error {
+	if string(src) == string(dst) {
+		return NewError(CodeInvalidArgument, "source and destination keys are the same")
+	}
+
+	unlock := s.locks.lock(src, dst)
+	defer unlock()
+
+	// 1. 读取源键
+	env, meta, err := s.loadForMove(src)
+	if err != nil {
+		return err
+	}
+
+	wb := gorocksdb.NewWriteBatch()
+	defer wb.Destroy()
+	delta := newBatchDelta()
+
+	// 2. 处理已存在的目标键
+	if err := s.replaceTarget(wb, delta, dst, overwrite); err != nil {
+		return err
+	}
+
+	// 3. 移动值和元数据，元数据原样保留，旧数据没有创建时间时以重命名时间为准
+	dstMeta := *meta
+	srcMeta := meta
+	if dstMeta.CreatedAt == 0 {
+		// 旧数据没有存储元数据，不计入配额用量
+		srcMeta = nil
+		dstMeta.CreatedAt = time.Now().Unix()
+		dstMeta.UpdatedAt = dstMeta.CreatedAt
+		dstMeta.Version = 1
+	}
+	s.putEnvelope(wb, dst, env.Type, &dstMeta, env.Payload)
+	if err := s.putMeta(wb, dst, &dstMeta); err != nil {
+		return err
+	}
+	s.indexEvicted(wb, dst, &dstMeta)
+	wb.DeleteCF(s.defaultCF, src)
+	wb.DeleteCF(s.keyMetaCF, src)
+	delta.track(src, srcMeta, nil)
+	delta.track(dst, nil, &dstMeta)
+
+	if err := s.commit(wb, delta); err != nil {
+		return err
+	}
+
+	// 4. 更新创建时间索引，已淘汰的键不在索引中
+	if err := s.dropCreateTime(src, meta); err != nil {
+		return err
+	}
+	if meta.Evicted {
+		return nil
+	}
+	return s.recordCreateTime(dst, dstMeta.CreatedAt)
+}
+
+// Copy 原子地将键复制到dst，目标键已存在时覆盖
+// 磁盘存储的值共享同一个文件，只增加引用计数
+func (s *RocksDBStorage) Copy(src, dst []byte) error {
+	if string(src) == string(dst) {
+		return NewError(CodeInvalidArgument, "source and destination keys are the same")
+	}
+
+	unlock := s.locks.lock(src, dst)
+	defer unlock()
+
+	// 1. 读取源键
+	env, srcMeta, err := s.loadForMove(src)
+	if err != nil {
+		return err
+	}
+	if srcMeta.Evicted {
+		return ErrEvicted
+	}
+
+	// 2. 目标键的元数据：保留大小、位置和过期时间，创建时间和版本号重新计算
+	dstMeta, err := s.loadMeta(dst)
+	if err != nil {
+		return err
+	}
+	now := time.Now()
+	if dstMeta != nil && dstMeta.Expired(now) {
+		dstMeta = nil
+	}
+	meta := newKeyMeta(dstMeta, now)
+	meta.CreatedAt = now.Unix()
+	meta.Size = srcMeta.Size
+	meta.ExpiresAt = srcMeta.ExpiresAt
+	meta.Location = srcMeta.Location
+	meta.DiskFile = srcMeta.DiskFile
+	meta.Codec = srcMeta.Codec
+	meta.Encryption = srcMeta.Encryption
+
+	wb := gorocksdb.NewWriteBatch()
+	defer wb.Destroy()
+	delta := newBatchDelta()
+
+	// 3. 删除旧的目标键
+	if err := s.replaceTarget(wb, delta, dst, true); err != nil {
+		return err
+	}
+
+	// 4. 写入值和元数据
+	s.putEnvelope(wb, dst, env.Type, meta, env.Payload)
+	if err := s.putMeta(wb, dst, meta); err != nil {
+		return err
+	}
+	delta.retain(meta.DiskFile)
+	delta.track(dst, nil, meta)
+
+	if err := s.commit(wb, delta); err != nil {
+		return err
+	}
+
+	// 5. 记录目标键的创建时间
+	return s.recordCreateTime(dst, meta.CreatedAt)
+}
+
+// loadForMove 读取待移动或复制的键的信封和元数据，调用方需持有键锁
+func (s *RocksDBStorage) loadForMove(key []byte) (*envelope, *KeyMeta, error) {
+	meta, err := s.loadMeta(key)
+	if err != nil {
+		return nil, nil, err
+	}
+	if meta != nil && meta.Expired(time.Now()) {
+		return nil, nil, NewError(CodeNotFound, "source key not found")
+	}
+	if meta == nil {
+		// 旧数据没有元数据，根据存储的值推导
+		meta, err = s.deriveMeta(key)
+		if err != nil {
+			return nil, nil, err
+		}
+		if meta == nil {
+			return nil, nil, NewError(CodeNotFound, "source key not found")
+		}
+	}
+
+	// 内联值或磁盘指针原样移动，目标键的信封按新的元数据重新编码
+	env, err := s.readEnvelope(key)
+	if err != nil {
+		return nil, nil, err
+	}
+	if env == nil {
+		return nil, nil, NewError(CodeNotFound, "source key not found")
+	}
+
+	return env, meta, nil
+}
+
+// replaceTarget 删除已存在的目标键，overwrite为false时返回错误，调用方需持有键锁
+func (s *RocksDBStorage) replaceTarget(wb *gorocksdb.WriteBatch, delta *batchDelta, key []byte, overwrite bool) error {
+	meta, err := s.loadMeta(key)
+	if err != nil {
+		return err
+	}
+
+	exists := meta != nil && !meta.Expired(time.Now())
+	if meta == nil {
+		// 旧数据没有元数据，检查是否存储了值
+		value, err := s.db.GetCF(s.readOpts, s.defaultCF, key)
+		if err != nil {
+			return err
+		}
+		exists = value.Size() > 0
+		value.Free()
+	}
+
+	if exists && !overwrite {
+		return NewError(CodeConflict, "destination key already exists")
+	}
+	if meta == nil && !exists {
+		return nil
+	}
+
+	return s.deleteKey(wb, delta, key)
+}
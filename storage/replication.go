@@ -0,0 +1,244 @@
+package storage
+
+import (
+	"fmt"
+	"io"
+	"os"
+
+	gorocksdb "github.com/linxGnu/grocksdb"
+)
+
+// ErrReadOnly 从节点只接受主节点复制的变更
+var ErrReadOnly = NewError(CodeUnavailable, "storage is a read-only replica")
+
+// ReplicaEntry 复制到从节点的键的完整状态，磁盘值只传递文件名，文件内容单独传输
+type ReplicaEntry struct {
+	Value []byte   // 内联存储的值，磁盘值和已淘汰的值为nil
+	Meta  *KeyMeta // DiskFile非空时值存储在磁盘
+}
+
+// IsFollower 判断当前是否为从节点
+func (s *RocksDBStorage) IsFollower() bool {
+	s.changes.mu.Lock()
+	defer s.changes.mu.Unlock()
+	return s.changes.follower
+}
+
+// Promote 将从节点提升为主节点，之后接受本地写入并恢复后台淘汰
+func (s *RocksDBStorage) Promote() error {
+	s.changes.mu.Lock()
+	s.changes.follower = false
+	s.changes.mu.Unlock()
+
+	if s.config.Eviction.Enabled {
+		if err := s.StartEvictionManager(); err != nil {
+			return err
+		}
+	}
+	for _, view := range s.namespaces.list() {
+		if view.config.Eviction.Enabled {
+			if err := view.StartEvictionManager(); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+// LatestRevision 返回最新已提交的修订号
+func (s *RocksDBStorage) LatestRevision() uint64 {
+	s.changes.mu.Lock()
+	defer s.changes.mu.Unlock()
+	return s.changes.rev
+}
+
+// CreateCheckpoint 在dir创建RocksDB检查点，返回检查点包含的最新修订号，dir必须不存在
+func (s *RocksDBStorage) CreateCheckpoint(dir string) (uint64, error) {
+	checkpoint, err := s.db.NewCheckpoint()
+	if err != nil {
+		return 0, err
+	}
+	defer checkpoint.Destroy()
+
+	// 持有变更日志锁期间没有新的修订号提交，检查点与修订号一致
+	s.changes.mu.Lock()
+	defer s.changes.mu.Unlock()
+
+	if err := checkpoint.CreateCheckpoint(dir, 0); err != nil {
+		return 0, fmt.Errorf("failed to create checkpoint: %v", err)
+	}
+	return s.changes.rev, nil
+}
+
+// ExportEntry 读取键当前存储的值和元数据，键不存在时返回nil
+func (s *RocksDBStorage) ExportEntry(key []byte) (*ReplicaEntry, error) {
+	unlock := s.locks.lock(key)
+	defer unlock()
+
+	meta, err := s.loadMeta(key)
+	if err != nil {
+		return nil, err
+	}
+	if meta == nil {
+		// 旧数据没有元数据，根据存储的值推导
+		if meta, err = s.deriveMeta(key); err != nil || meta == nil {
+			return nil, err
+		}
+	}
+
+	entry := &ReplicaEntry{Meta: meta}
+	if meta.Location == LocationInline {
+		env, err := s.readEnvelope(key)
+		if err != nil {
+			return nil, err
+		}
+		if env != nil {
+			entry.Value = env.Payload
+		}
+	}
+	return entry, nil
+}
+
+// ApplyChange 按主节点的修订号应用一次变更，event必须属于当前命名空间，修订号为0时分配本地修订号
+// 写入类事件的entry为主节点读取时键的状态，键已被删除时为nil，此时只记录事件，之后的删除事件会使状态一致
+func (s *RocksDBStorage) ApplyChange(event *ChangeEvent, entry *ReplicaEntry) error {
+	if event.Namespace != s.namespace.Name {
+		return fmt.Errorf("change %d belongs to namespace %s", event.Revision, event.Namespace)
+	}
+
+	switch event.Type {
+	case EventDeleteRange:
+		_, err := s.deleteRange(event.Key, event.End, event)
+		return err
+	case EventDelete, EventExpire:
+		return s.applyDelete(event)
+	default:
+		return s.applyEntry(event, entry)
+	}
+}
+
+// SkipChange 不应用变更，只在变更日志中记录主节点的修订号，用于跳过已在主节点删除的命名空间中的变更
+// 跳过的变更同样推进最新修订号，复制延迟能回到0，重新连接时从该修订号之后继续
+func (s *RocksDBStorage) SkipChange(event *ChangeEvent) error {
+	wb := gorocksdb.NewWriteBatch()
+	defer wb.Destroy()
+
+	return s.write(wb, []ChangeEvent{*event})
+}
+
+// applyDelete 应用删除或过期事件
+func (s *RocksDBStorage) applyDelete(event *ChangeEvent) error {
+	unlock := s.locks.lock(event.Key)
+	defer unlock()
+
+	wb := gorocksdb.NewWriteBatch()
+	defer wb.Destroy()
+
+	delta := newBatchDelta()
+	delta.replicated = event
+	if err := s.deleteKey(wb, delta, event.Key); err != nil {
+		return err
+	}
+	return s.commit(wb, delta)
+}
+
+// applyEntry 用主节点的值和元数据覆盖键，磁盘文件需已通过ImportBlob导入
+func (s *RocksDBStorage) applyEntry(event *ChangeEvent, entry *ReplicaEntry) error {
+	key := event.Key
+	unlock := s.locks.lock(key)
+	defer unlock()
+
+	wb := gorocksdb.NewWriteBatch()
+	defer wb.Destroy()
+
+	delta := newBatchDelta()
+	delta.replicated = event
+	if entry == nil {
+		return s.commit(wb, delta)
+	}
+
+	// 1. 释放旧值引用的磁盘文件
+	oldMeta, err := s.loadMeta(key)
+	if err != nil {
+		return err
+	}
+	oldFile, err := s.diskFileOf(key, oldMeta)
+	if err != nil {
+		return err
+	}
+	delta.release(oldFile)
+
+	// 2. 写入值和元数据
+	meta := entry.Meta
+	switch {
+	case meta.DiskFile != "":
+		s.putEnvelope(wb, key, valueDisk, meta, []byte(meta.DiskFile))
+		delta.retain(meta.DiskFile)
+	case meta.Evicted:
+		s.putEnvelope(wb, key, valueEvicted, meta, nil)
+		s.indexEvicted(wb, key, meta)
+	default:
+		s.putEnvelope(wb, key, valueInline, meta, entry.Value)
+	}
+	if err := s.putMeta(wb, key, meta); err != nil {
+		return err
+	}
+	delta.track(key, oldMeta, meta)
+
+	// 3. 提交成功后同步创建时间索引
+	if oldMeta != nil && oldMeta.CreatedAt != meta.CreatedAt {
+		delta.unindexCreateTime(key, oldMeta)
+	}
+	if oldMeta == nil || oldMeta.CreatedAt != meta.CreatedAt {
+		delta.indexCreateTime(key, meta.CreatedAt)
+	}
+
+	return s.commit(wb, delta)
+}
+
+// HasBlob 判断磁盘文件是否存在
+func (s *RocksDBStorage) HasBlob(fileName string) bool {
+	return s.diskStore.Has(fileName)
+}
+
+// OpenBlob 打开磁盘文件用于传输
+func (s *RocksDBStorage) OpenBlob(fileName string) (io.ReadCloser, error) {
+	filePath, err := s.diskStore.Path(fileName)
+	if err != nil {
+		return nil, err
+	}
+	return os.Open(filePath)
+}
+
+// ImportBlob 从其他节点导入磁盘文件，文件名必须与内容的哈希一致
+func (s *RocksDBStorage) ImportBlob(fileName string, r io.Reader) error {
+	return s.diskStore.Import(fileName, r)
+}
+
+// ListBlobs 列出当前命名空间中被引用的磁盘文件
+func (s *RocksDBStorage) ListBlobs() ([]string, error) {
+	iter := s.db.NewIteratorCF(s.readOpts, s.blobRefsCF)
+	defer iter.Close()
+
+	var names []string
+	for iter.SeekToFirst(); iter.Valid(); iter.Next() {
+		names = append(names, string(iter.Key().Data()))
+	}
+	return names, iter.Err()
+}
+
+// MissingBlobs 列出当前命名空间中被引用但本地不存在的磁盘文件
+func (s *RocksDBStorage) MissingBlobs() ([]string, error) {
+	names, err := s.ListBlobs()
+	if err != nil {
+		return nil, err
+	}
+
+	var missing []string
+	for _, fileName := range names {
+		if !s.HasBlob(fileName) {
+			missing = append(missing, fileName)
+		}
+	}
+	return missing, nil
+}
@@ -1,24 +1,28 @@
 package storage
 
 import (
+	"context"
 	"encoding/json"
 	"fmt"
 	"kvcache/config"
 	"strings"
+	"sync"
 	"time"
 
 	gorocksdb "github.com/linxGnu/grocksdb"
 )
 
 const (
-	// DiskStorePrefix 磁盘存储路径前缀
+	// DiskStorePrefix 旧格式中磁盘存储路径的前缀，只用于迁移到信封格式
 	DiskStorePrefix = "__rocksdb_disk_store__://"
-	// EvictedValue 已淘汰值标记
+	// EvictedValue 旧格式中的已淘汰值标记，只用于迁移到信封格式
 	EvictedValue = "__evicted__"
 	// CreateTimeCF 创建时间列族
 	CreateTimeCF = "create_time"
 	// MetadataCF 元数据列族
 	MetadataCF = "metadata"
+	// KeyMetaCF 键元数据列族
+	KeyMetaCF = "key_meta"
 )
 
 // RocksDBStorage RocksDB存储实现
@@ -31,58 +35,132 @@ type RocksDBStorage struct {
 	defaultCF    *gorocksdb.ColumnFamilyHandle
 	createTimeCF *gorocksdb.ColumnFamilyHandle
 	metadataCF   *gorocksdb.ColumnFamilyHandle
+	keyMetaCF    *gorocksdb.ColumnFamilyHandle
+	blobRefsCF   *gorocksdb.ColumnFamilyHandle
 	config       *config.Config
 	diskStore    *DiskStore
 	eviction     *EvictionManager
+	locks        *keyLocks
+	deleteJobs   *deleteJobs
+	changes      *changeLog
+	tombstones   *tombstonePurger
+	quotas       *quotaSet               // 当前命名空间的配额
+	namespace    *config.NamespaceConfig // 当前实例所属的命名空间
+	namespaces   *namespaceRegistry      // 所有命名空间共享的注册表
+	indexMu      sync.Mutex              // 保护创建时间索引的读-改-写
+	blobMu       sync.RWMutex            // 保护磁盘文件引用计数和配额用量的读-改-写
 }
 
 // NewRocksDBStorage 创建新的RocksDB存储实例
 func NewRocksDBStorage(cfg *config.Config) (*RocksDBStorage, error) {
 	storage := &RocksDBStorage{
-		config: cfg,
+		config:     cfg,
+		locks:      &keyLocks{},
+		deleteJobs: newDeleteJobs(),
+		changes:    newChangeLog(),
+		tombstones: newTombstonePurger(),
+		quotas:     newQuotaSet(),
+		namespace:  &config.NamespaceConfig{Name: config.DefaultNamespace},
 	}
+	storage.namespaces = newNamespaceRegistry(storage)
 
 	return storage, nil
 }
 
 // Start 启动存储
 func (s *RocksDBStorage) Start() error {
+	// 命名空间实例在创建时已完成初始化
+	if !s.isRoot() {
+		return nil
+	}
+
 	// 1. 初始化RocksDB
-	if err := s.initRocksDB(); err != nil {
+	if err := s.initRocksDB(false); err != nil {
+		return err
+	}
+
+	// 2. 将数据升级到当前格式，数据格式比当前版本新时拒绝启动
+	if _, err := s.migrate(false); err != nil {
 		return err
 	}
 
-	// 2. 初始化磁盘存储
+	// 3. 初始化磁盘存储
 	diskStore, err := NewDiskStore(s.config.Value.DiskPath)
 	if err != nil {
 		return err
 	}
 	s.diskStore = diskStore
 
-	// 3. 加载配置
+	// 4. 初始化磁盘文件引用计数
+	if err := s.initBlobRefs(); err != nil {
+		return err
+	}
+
+	// 5. 加载配置
 	if err := s.loadConfig(); err != nil {
 		return err
 	}
 
-	// 4. 存储配置到RocksDB
+	// 6. 存储配置到RocksDB
 	if err := s.storeConfig(); err != nil {
 		return err
 	}
 
-	// 5. 检查是否启用淘汰机制
+	// 7. 恢复变更日志的修订号，配置了主节点时作为从节点启动
+	if err := s.initChangeLog(); err != nil {
+		return err
+	}
+	s.changes.follower = s.config.Replication.LeaderAddr != ""
+
+	// 8. 检查是否启用淘汰机制
 	if s.config.Eviction.Enabled {
 		if err := s.StartEvictionManager(); err != nil {
 			return err
 		}
 	}
 
+	// 9. 加载配额
+	if err := s.loadQuotas(); err != nil {
+		return err
+	}
+
+	// 10. 完成上次中断的范围删除清理
+	if err := s.resumeDeleteJobs(); err != nil {
+		return err
+	}
+
+	// 11. 加载已创建的命名空间
+	if err := s.loadNamespaces(); err != nil {
+		return err
+	}
+
+	// 12. 在后台清理超过保留时间的墓碑
+	s.startTombstonePurge()
 	return nil
 }
 
-// Stop 停止存储
+// Stop 停止存储，命名空间实例的生命周期由默认命名空间管理
 func (s *RocksDBStorage) Stop() error {
+	if !s.isRoot() {
+		return nil
+	}
+
 	// 停止淘汰管理器
 	s.StopEvictionManager()
+	for _, view := range s.namespaces.list() {
+		view.StopEvictionManager()
+	}
+
+	// 等待后台清理任务、配额淘汰和墓碑清理退出
+	s.quotas.close()
+	for _, view := range s.namespaces.list() {
+		view.quotas.close()
+	}
+	s.deleteJobs.close()
+	s.tombstones.close()
+
+	// 中断所有订阅
+	s.changes.close()
 
 	// 关闭磁盘存储
 	if s.diskStore != nil {
@@ -90,15 +168,7 @@ func (s *RocksDBStorage) Stop() error {
 	}
 
 	// 关闭RocksDB
-	if s.defaultCF != nil {
-		s.defaultCF.Destroy()
-	}
-	if s.createTimeCF != nil {
-		s.createTimeCF.Destroy()
-	}
-	if s.metadataCF != nil {
-		s.metadataCF.Destroy()
-	}
+	s.namespaces.destroyHandles()
 	if s.db != nil {
 		s.db.Close()
 	}
@@ -118,42 +188,133 @@ func (s *RocksDBStorage) Stop() error {
 	return nil
 }
 
-// initRocksDB 初始化RocksDB
-func (s *RocksDBStorage) initRocksDB() error {
+// initRocksDB 初始化RocksDB，readOnly为true时以只读方式打开已存在的数据库和列族
+func (s *RocksDBStorage) initRocksDB(readOnly bool) error {
 	// 1. 创建选项
 	s.opts = gorocksdb.NewDefaultOptions()
-	s.opts.SetCreateIfMissing(true)
+	s.opts.SetCreateIfMissing(!readOnly)
+	s.opts.SetCreateIfMissingColumnFamilies(!readOnly)
 
-	// 初始化选项
+	// 初始化选项，注册合并操作符用于内联值的追加和局部更新
 	s.cfOpts = gorocksdb.NewDefaultOptions()
+	s.cfOpts.SetMergeOperator(&valueMergeOperator{})
 
 	s.readOpts = gorocksdb.NewDefaultReadOptions()
 	s.writeOpts = gorocksdb.NewDefaultWriteOptions()
 
-	// 2. 准备要使用的列族
-	cfNames := []string{"default"}
+	// 2. 准备要使用的列族，已存在的命名空间列族也需要打开，只读时只打开已存在的列族
+	cfNames := []string{"default", CreateTimeCF, MetadataCF, KeyMetaCF, BlobRefsCF, ChangeLogCF}
+	existing, err := gorocksdb.ListColumnFamilies(s.opts, s.config.RocksDB.Path)
+	if readOnly {
+		if err != nil {
+			return fmt.Errorf("failed to open rocksdb: %v", err)
+		}
+		cfNames = existing
+	} else if err == nil {
+		for _, name := range existing {
+			if strings.HasPrefix(name, namespaceCFPrefix) {
+				cfNames = append(cfNames, name)
+			}
+		}
+	}
 	cfOpts := make([]*gorocksdb.Options, len(cfNames))
 	for i := range cfOpts {
 		cfOpts[i] = s.cfOpts
 	}
 
-	// 3. 打开数据库，只使用default列族
-	db, cfHandles, err := gorocksdb.OpenDbColumnFamilies(s.opts, s.config.RocksDB.Path, cfNames, cfOpts)
+	// 3. 打开数据库，缺失的列族会自动创建
+	var db *gorocksdb.DB
+	var cfHandles []*gorocksdb.ColumnFamilyHandle
+	if readOnly {
+		db, cfHandles, err = gorocksdb.OpenDbForReadOnlyColumnFamilies(s.opts, s.config.RocksDB.Path, cfNames, cfOpts, false)
+	} else {
+		db, cfHandles, err = gorocksdb.OpenDbColumnFamilies(s.opts, s.config.RocksDB.Path, cfNames, cfOpts)
+	}
 	if err != nil {
 		return fmt.Errorf("failed to open rocksdb: %v", err)
 	}
 
-	// 4. 赋值
+	// 4. 赋值，只读打开的旧数据库可能缺少部分列族
 	s.db = db
-	s.defaultCF = cfHandles[0]
-	// 暂时不使用其他列族，后续需要时再创建
+	for i, name := range cfNames {
+		s.namespaces.handles[name] = cfHandles[i]
+	}
+	s.defaultCF = s.namespaces.handles["default"]
+	s.createTimeCF = s.namespaces.handles[CreateTimeCF]
+	s.metadataCF = s.namespaces.handles[MetadataCF]
+	s.keyMetaCF = s.namespaces.handles[KeyMetaCF]
+	s.blobRefsCF = s.namespaces.handles[BlobRefsCF]
+	s.changes.cf = s.namespaces.handles[ChangeLogCF]
 
 	return nil
 }
 
 // Set 设置键值对
 func (s *RocksDBStorage) Set(key, value []byte) error {
-	// 1. 检查是否需要存储到磁盘
+	return s.SetWithTTL(key, value, 0)
+}
+
+// SetWithTTL 设置键值对并指定存活时间，ttl<=0表示永不过期
+func (s *RocksDBStorage) SetWithTTL(key, value []byte, ttl time.Duration) error {
+	unlock := s.locks.lock(key)
+	defer unlock()
+
+	wb := gorocksdb.NewWriteBatch()
+	defer wb.Destroy()
+
+	delta := newBatchDelta()
+	if err := s.putValue(wb, delta, key, value, ttl, 0); err != nil {
+		return err
+	}
+
+	return s.commit(wb, delta)
+}
+
+// SetIfAbsent 键不存在、已过期或值已被淘汰时写入，返回是否已写入
+func (s *RocksDBStorage) SetIfAbsent(key, value []byte, ttl time.Duration) (bool, error) {
+	unlock := s.locks.lock(key)
+	defer unlock()
+
+	meta, err := s.loadMeta(key)
+	if err != nil {
+		return false, err
+	}
+	if meta != nil && !meta.Expired(time.Now()) && !meta.Evicted {
+		return false, nil
+	}
+
+	wb := gorocksdb.NewWriteBatch()
+	defer wb.Destroy()
+
+	delta := newBatchDelta()
+	if err := s.putValue(wb, delta, key, value, ttl, 0); err != nil {
+		return false, err
+	}
+	return true, s.commit(wb, delta)
+}
+
+// putValue 将值和元数据写入批处理，stamp为带版本写入的时间戳，调用方需持有键锁
+func (s *RocksDBStorage) putValue(wb *gorocksdb.WriteBatch, delta *batchDelta, key, value []byte, ttl time.Duration, stamp uint64) error {
+	// 1. 读取旧的元数据，保留创建时间和版本号
+	oldMeta, err := s.loadMeta(key)
+	if err != nil {
+		return err
+	}
+
+	// 释放旧值引用的磁盘文件
+	oldFile, err := s.diskFileOf(key, oldMeta)
+	if err != nil {
+		return err
+	}
+	delta.release(oldFile)
+
+	now := time.Now()
+	meta := newKeyMeta(oldMeta, now)
+	meta.Size = int64(len(value))
+	meta.ExpiresAt = expireAt(now, ttl)
+	meta.Stamp = stamp
+
+	// 2. 检查是否需要存储到磁盘
 	if len(value) > s.config.Value.DiskThreshold {
 		// 存储到磁盘
 		filePath, err := s.diskStore.Store(value)
@@ -162,104 +323,456 @@ func (s *RocksDBStorage) Set(key, value []byte) error {
 		}
 
 		// 在RocksDB中存储路径
-		diskValue := []byte(DiskStorePrefix + filePath)
-		if err := s.db.PutCF(s.writeOpts, s.defaultCF, key, diskValue); err != nil {
-			return err
-		}
+		meta.Location = LocationDisk
+		meta.DiskFile = filePath
+		s.putEnvelope(wb, key, valueDisk, meta, []byte(filePath))
+		delta.retain(filePath)
 	} else {
 		// 直接存储到RocksDB
-		if err := s.db.PutCF(s.writeOpts, s.defaultCF, key, value); err != nil {
-			return err
-		}
+		meta.Location = LocationInline
+		s.putEnvelope(wb, key, valueInline, meta, value)
 	}
 
-	// 2. 记录创建时间
-	if err := s.recordCreateTime(key); err != nil {
+	// 3. 写入元数据
+	if err := s.putMeta(wb, key, meta); err != nil {
 		return err
 	}
+	delta.track(key, oldMeta, meta)
+
+	// 4. 新建的键和重新写入的已淘汰键记录创建时间
+	if oldMeta == nil || oldMeta.Evicted {
+		return s.recordCreateTime(key, meta.CreatedAt)
+	}
 
 	return nil
 }
 
 // Get 获取值
 func (s *RocksDBStorage) Get(key []byte) ([]byte, bool, error) {
-	// 1. 从RocksDB获取
-	value, err := s.db.GetCF(s.readOpts, s.defaultCF, key)
+	return s.GetContext(context.Background(), key)
+}
+
+// GetContext 获取值，ctx取消或超时后停止从磁盘读取值
+func (s *RocksDBStorage) GetContext(ctx context.Context, key []byte) ([]byte, bool, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, false, err
+	}
+
+	// 1. 检查元数据，过期的键视为不存在
+	meta, err := s.loadMeta(key)
 	if err != nil {
 		return nil, false, err
 	}
-	defer value.Free()
 
-	if value.Size() == 0 {
+	now := time.Now()
+	if meta != nil && meta.Expired(now) {
+		s.expireKey(key)
 		return nil, false, nil
 	}
 
-	valueBytes := value.Data()
+	// 2. 从RocksDB获取
+	env, err := s.readEnvelope(key)
+	if err != nil {
+		return nil, false, err
+	}
+	if env == nil {
+		return nil, false, nil
+	}
 
-	// 2. 检查值类型
-	if string(valueBytes) == EvictedValue {
-		return nil, true, fmt.Errorf("value has been evicted")
+	// 3. 检查值类型
+	if env.Type == valueEvicted {
+		return nil, true, ErrEvicted
+	}
+	if env.Flags != 0 {
+		return nil, true, Errorf(CodeCorrupted, "unsupported value flags %#x", env.Flags)
 	}
 
-	if strings.HasPrefix(string(valueBytes), DiskStorePrefix) {
+	// 4. 更新最后访问时间
+	if meta != nil {
+		s.touch(key, meta, now)
+	}
+
+	if env.Type == valueDisk {
 		// 从磁盘获取
-		filePath := strings.TrimPrefix(string(valueBytes), DiskStorePrefix)
-		diskValue, err := s.diskStore.Load(filePath)
+		diskValue, err := s.diskStore.LoadContext(ctx, env.diskFile())
 		if err != nil {
 			return nil, true, err
 		}
 		return diskValue, true, nil
 	}
 
-	// 复制valueBytes的内容，因为value.Free()会释放内部缓冲区
-	copyValue := make([]byte, len(valueBytes))
-	copy(copyValue, valueBytes)
-
-	// 返回复制后的值
-	return copyValue, true, nil
+	// readEnvelope返回的载荷已是副本
+	return env.Payload, true, nil
 }
 
 // Delete 删除键值对
 func (s *RocksDBStorage) Delete(key []byte) error {
-	// 1. 先获取值，检查是否存储在磁盘
-	value, err := s.db.GetCF(s.readOpts, s.defaultCF, key)
+	unlock := s.locks.lock(key)
+	defer unlock()
+
+	wb := gorocksdb.NewWriteBatch()
+	defer wb.Destroy()
+
+	delta := newBatchDelta()
+	if err := s.deleteKey(wb, delta, key); err != nil {
+		return err
+	}
+
+	return s.commit(wb, delta)
+}
+
+// deleteKey 将删除操作写入批处理，释放磁盘文件引用，提交成功后清理创建时间索引，调用方需持有键锁
+func (s *RocksDBStorage) deleteKey(wb *gorocksdb.WriteBatch, delta *batchDelta, key []byte) error {
+	// 1. 释放磁盘文件引用
+	meta, err := s.loadMeta(key)
+	if err != nil {
+		return err
+	}
+
+	fileName, err := s.diskFileOf(key, meta)
 	if err != nil {
 		return err
 	}
+	delta.release(fileName)
+
+	// 2. 从RocksDB删除值和元数据
+	wb.DeleteCF(s.defaultCF, key)
+	wb.DeleteCF(s.keyMetaCF, key)
+	delta.track(key, meta, nil)
+
+	// 3. 提交成功后从创建时间记录中删除，提交失败时索引仍与数据一致
+	delta.unindexCreateTime(key, meta)
+	return nil
+}
+
+// expireKey 删除已过期的键
+func (s *RocksDBStorage) expireKey(key []byte) {
+	// 从节点等待主节点复制过期事件
+	if s.IsFollower() {
+		return
+	}
+
+	unlock := s.locks.lock(key)
+	defer unlock()
+
+	// 加锁后重新检查，避免删除刚被重新写入的键
+	meta, err := s.loadMeta(key)
+	if err != nil || meta == nil || !meta.Expired(time.Now()) {
+		return
+	}
+
+	wb := gorocksdb.NewWriteBatch()
+	defer wb.Destroy()
+
+	delta := newBatchDelta()
+	delta.expire = true
+	if err := s.deleteKey(wb, delta, key); err != nil {
+		return
+	}
+	s.commit(wb, delta)
+}
+
+// touch 按粒度更新键的最后访问时间
+func (s *RocksDBStorage) touch(key []byte, meta *KeyMeta, now time.Time) {
+	if now.Unix()-meta.LastAccess < accessTimeGranularity {
+		return
+	}
+
+	unlock := s.locks.lock(key)
+	defer unlock()
+
+	// 加锁后重新读取，避免覆盖并发写入的元数据
+	current, err := s.loadMeta(key)
+	if err != nil || current == nil {
+		return
+	}
+	current.LastAccess = now.Unix()
+
+	metaBytes, err := encodeKeyMeta(current)
+	if err != nil {
+		return
+	}
+	s.db.PutCF(s.writeOpts, s.keyMetaCF, key, metaBytes)
+}
+
+// GetMeta 获取键的元数据，不读取值本身
+func (s *RocksDBStorage) GetMeta(key []byte) (*KeyInfo, bool, error) {
+	meta, err := s.loadMeta(key)
+	if err != nil {
+		return nil, false, err
+	}
+
+	now := time.Now()
+	if meta == nil {
+		// 旧数据没有元数据，根据存储的值推导
+		meta, err = s.deriveMeta(key)
+		if err != nil || meta == nil {
+			return nil, false, err
+		}
+	} else if meta.Expired(now) {
+		return nil, false, nil
+	}
+
+	info := &KeyInfo{
+		KeyMeta: *meta,
+		TTL:     meta.RemainingTTL(now),
+	}
+	if meta.Location == LocationDisk && meta.DiskFile != "" {
+		info.DiskPath, _ = s.diskStore.Path(meta.DiskFile)
+	}
+
+	return info, true, nil
+}
+
+// deriveMeta 根据存储的值的信封推导元数据
+func (s *RocksDBStorage) deriveMeta(key []byte) (*KeyMeta, error) {
+	env, err := s.readEnvelope(key)
+	if err != nil || env == nil {
+		return nil, err
+	}
+
+	meta := &KeyMeta{
+		Location:   LocationInline,
+		Size:       int64(len(env.Payload)),
+		Version:    env.Version,
+		ExpiresAt:  env.ExpiresAt,
+		Codec:      CodecNone,
+		Encryption: EncryptionNone,
+	}
+
+	switch env.Type {
+	case valueEvicted:
+		meta.Location = LocationDisk
+		meta.Size = 0
+		meta.Evicted = true
+	case valueDisk:
+		meta.Location = LocationDisk
+		meta.DiskFile = env.diskFile()
+		size, err := s.diskStore.Size(meta.DiskFile)
+		if err != nil {
+			return nil, err
+		}
+		meta.Size = size
+	}
+
+	return meta, nil
+}
+
+// loadMeta 读取键的元数据，不存在时返回nil
+func (s *RocksDBStorage) loadMeta(key []byte) (*KeyMeta, error) {
+	value, err := s.db.GetCF(s.readOpts, s.keyMetaCF, key)
+	if err != nil {
+		return nil, err
+	}
 	defer value.Free()
 
-	if value.Size() > 0 {
-		valueBytes := value.Data()
-		if strings.HasPrefix(string(valueBytes), DiskStorePrefix) {
-			// 删除磁盘文件
-			filePath := strings.TrimPrefix(string(valueBytes), DiskStorePrefix)
-			s.diskStore.Delete(filePath)
+	if value.Size() == 0 {
+		return nil, nil
+	}
+
+	return decodeKeyMeta(value.Data())
+}
+
+// diskFileOf 返回键引用的磁盘文件名，值不在磁盘时返回空字符串
+func (s *RocksDBStorage) diskFileOf(key []byte, meta *KeyMeta) (string, error) {
+	if meta != nil {
+		return meta.DiskFile, nil
+	}
+
+	// 旧数据没有元数据，从存储的值的信封中解析
+	env, err := s.readEnvelope(key)
+	if err != nil {
+		return "", err
+	}
+	return env.diskFile(), nil
+}
+
+// putMeta 将元数据写入批处理
+func (s *RocksDBStorage) putMeta(wb *gorocksdb.WriteBatch, key []byte, meta *KeyMeta) error {
+	metaBytes, err := encodeKeyMeta(meta)
+	if err != nil {
+		return err
+	}
+	wb.PutCF(s.keyMetaCF, key, metaBytes)
+	return nil
+}
+
+// Append 向值末尾追加数据，键不存在时创建
+func (s *RocksDBStorage) Append(key, data []byte) error {
+	return s.writeAt(key, appendOffset, data)
+}
+
+// WriteAt 在值的指定偏移量写入数据，超出原长度的部分用0填充
+func (s *RocksDBStorage) WriteAt(key []byte, offset int64, data []byte) error {
+	if offset < 0 {
+		return Errorf(CodeInvalidArgument, "invalid offset: %d", offset)
+	}
+	return s.writeAt(key, uint64(offset), data)
+}
+
+// writeAt 执行追加或局部写入
+func (s *RocksDBStorage) writeAt(key []byte, offset uint64, data []byte) error {
+	unlock := s.locks.lock(key)
+	defer unlock()
+
+	wb := gorocksdb.NewWriteBatch()
+	defer wb.Destroy()
+
+	// 1. 读取当前的元数据，已过期的键按不存在处理
+	now := time.Now()
+	oldMeta, err := s.loadMeta(key)
+	if err != nil {
+		return err
+	}
+	if oldMeta != nil && oldMeta.Expired(now) {
+		expired := newBatchDelta()
+		expired.expire = true
+		if err := s.deleteKey(wb, expired, key); err != nil {
+			return err
+		}
+		if err := s.commit(wb, expired); err != nil {
+			return err
 		}
+		wb.Clear()
+		oldMeta = nil
 	}
 
-	// 2. 从RocksDB删除
-	if err := s.db.DeleteCF(s.writeOpts, s.defaultCF, key); err != nil {
+	meta := newKeyMeta(oldMeta, now)
+	if oldMeta != nil {
+		meta.ExpiresAt = oldMeta.ExpiresAt
+	}
+
+	// 2. 未压缩的内联值写入后不超过阈值时使用合并操作符，避免读取和重写整个值
+	if oldMeta != nil && oldMeta.Location == LocationInline && !oldMeta.Evicted && newEnvelope(valueInline, oldMeta, nil).Flags == 0 {
+		if size := writtenSize(uint64(oldMeta.Size), offset, data); size <= uint64(s.config.Value.DiskThreshold) {
+			meta.Location = LocationInline
+			meta.Size = int64(size)
+			wb.MergeCF(s.defaultCF, key, encodeEnvelopeOperand(meta.Version, offset, data))
+			if err := s.putMeta(wb, key, meta); err != nil {
+				return err
+			}
+			delta := newBatchDelta()
+			delta.track(key, oldMeta, meta)
+			return s.commit(wb, delta)
+		}
+	}
+
+	// 3. 读取当前存储的值
+	env, err := s.readEnvelope(key)
+	if err != nil {
 		return err
 	}
+	if env != nil && env.Type == valueEvicted {
+		return ErrEvicted
+	}
+	if env != nil && env.Flags != 0 {
+		return Errorf(CodeCorrupted, "unsupported value flags %#x", env.Flags)
+	}
 
-	// 3. 从创建时间记录中删除
-	if err := s.removeCreateTime(key); err != nil {
+	// 4. 磁盘存储的值：写时复制生成新文件
+	if oldPath := env.diskFile(); oldPath != "" {
+		oldSize, err := s.diskStore.Size(oldPath)
+		if err != nil {
+			return err
+		}
+
+		var newPath string
+		if offset == appendOffset {
+			newPath, err = s.diskStore.Append(oldPath, data)
+		} else {
+			newPath, err = s.diskStore.WriteAt(oldPath, int64(offset), data)
+		}
+		if err != nil {
+			return err
+		}
+
+		meta.Size = int64(writtenSize(uint64(oldSize), offset, data))
+		meta.Location = LocationDisk
+		meta.DiskFile = newPath
+		s.putEnvelope(wb, key, valueDisk, meta, []byte(newPath))
+		if err := s.putMeta(wb, key, meta); err != nil {
+			return err
+		}
+
+		// 旧文件可能被其他键共享，通过引用计数回收
+		delta := newBatchDelta()
+		delta.release(oldPath)
+		delta.retain(newPath)
+		delta.track(key, oldMeta, meta)
+		return s.commit(wb, delta)
+	}
+
+	// 5. 新建的键、没有元数据的旧数据和超过阈值的内联值：计算写入后的值，超过阈值时迁移到磁盘
+	var current []byte
+	if env != nil {
+		current = env.Payload
+	}
+	updated := applyWrite(current, offset, data)
+	meta.Size = int64(len(updated))
+
+	delta := newBatchDelta()
+	if len(updated) > s.config.Value.DiskThreshold {
+		filePath, err := s.diskStore.Store(updated)
+		if err != nil {
+			return err
+		}
+		meta.Location = LocationDisk
+		meta.DiskFile = filePath
+		s.putEnvelope(wb, key, valueDisk, meta, []byte(filePath))
+		delta.retain(filePath)
+	} else {
+		meta.Location = LocationInline
+		s.putEnvelope(wb, key, valueInline, meta, updated)
+	}
+
+	if err := s.putMeta(wb, key, meta); err != nil {
+		return err
+	}
+	delta.track(key, oldMeta, meta)
+	if err := s.commit(wb, delta); err != nil {
 		return err
 	}
 
+	// 6. 新建的键需要记录创建时间
+	if env == nil {
+		return s.recordCreateTime(key, meta.CreatedAt)
+	}
+
 	return nil
 }
 
+// writtenSize 计算写入后的值大小
+func writtenSize(size, offset uint64, data []byte) uint64 {
+	if offset == appendOffset {
+		return size + uint64(len(data))
+	}
+	return max(size, offset+uint64(len(data)))
+}
+
 // Scan 扫描键前缀
 func (s *RocksDBStorage) Scan(prefix []byte) ([][]byte, error) {
-	iter := s.db.NewIteratorCF(s.readOpts, s.defaultCF)
+	return s.ScanContext(context.Background(), prefix)
+}
+
+// ScanContext 扫描键前缀，ctx取消或超时后停止遍历
+func (s *RocksDBStorage) ScanContext(ctx context.Context, prefix []byte) ([][]byte, error) {
+	readOpts := iterReadOptions(ctx)
+	defer readOpts.Destroy()
+	iter := s.db.NewIteratorCF(readOpts, s.defaultCF)
 	defer iter.Close()
 
 	var keys [][]byte
 	prefixStr := string(prefix)
+	now := time.Now()
 
 	// 从第一个键开始遍历
+	n := 0
 	for iter.SeekToFirst(); iter.Valid(); iter.Next() {
+		if err := checkContext(ctx, n); err != nil {
+			return nil, err
+		}
+		n++
+
 		key := iter.Key().Data()
 		// 复制键，因为 iter.Key().Data() 会在 iter.Next() 后失效
 		keyCopy := make([]byte, len(key))
@@ -268,29 +781,48 @@ func (s *RocksDBStorage) Scan(prefix []byte) ([][]byte, error) {
 
 		// 检查键是否以前缀开头
 		if strings.HasPrefix(keyStr, prefixStr) {
-			// 跳过配置键
-			if keyStr != config.ConfigKey {
+			// 跳过保留键和已过期的键
+			if !IsReservedKey(keyCopy) && !s.expired(keyCopy, now) {
 				keys = append(keys, keyCopy)
 			}
 		}
 	}
 
-	if err := iter.Err(); err != nil {
+	if err := iterErr(ctx, iter); err != nil {
 		return nil, err
 	}
 
 	return keys, nil
 }
 
+// expired 判断键是否已过期
+func (s *RocksDBStorage) expired(key []byte, now time.Time) bool {
+	meta, err := s.loadMeta(key)
+	return err == nil && meta != nil && meta.Expired(now)
+}
+
 // ScanWithValues 扫描键前缀并返回值
 func (s *RocksDBStorage) ScanWithValues(prefix []byte) (map[string][]byte, error) {
-	iter := s.db.NewIteratorCF(s.readOpts, s.defaultCF)
+	return s.ScanWithValuesContext(context.Background(), prefix)
+}
+
+// ScanWithValuesContext 扫描键前缀并返回值，ctx取消或超时后停止遍历
+func (s *RocksDBStorage) ScanWithValuesContext(ctx context.Context, prefix []byte) (map[string][]byte, error) {
+	readOpts := iterReadOptions(ctx)
+	defer readOpts.Destroy()
+	iter := s.db.NewIteratorCF(readOpts, s.defaultCF)
 	defer iter.Close()
 
 	keyValues := make(map[string][]byte)
 	prefixStr := string(prefix)
 
+	n := 0
 	for iter.Seek(prefix); iter.Valid(); iter.Next() {
+		if err := checkContext(ctx, n); err != nil {
+			return nil, err
+		}
+		n++
+
 		key := iter.Key().Data()
 		keyStr := string(key)
 
@@ -298,22 +830,25 @@ func (s *RocksDBStorage) ScanWithValues(prefix []byte) (map[string][]byte, error
 			break
 		}
 
-		// 跳过配置键
-		if keyStr == config.ConfigKey {
+		// 跳过保留键
+		if IsReservedKey(key) {
 			continue
 		}
 
-		// 获取值
-		value, found, err := s.Get(key)
+		// 获取值，ctx取消时停止，其他错误跳过该键
+		value, found, err := s.GetContext(ctx, key)
 		if err != nil {
-			continue // 跳过错误的键
+			if ctxErr := ctx.Err(); ctxErr != nil {
+				return nil, ctxErr
+			}
+			continue
 		}
 		if found {
 			keyValues[keyStr] = value
 		}
 	}
 
-	if err := iter.Err(); err != nil {
+	if err := iterErr(ctx, iter); err != nil {
 		return nil, err
 	}
 
@@ -322,44 +857,49 @@ func (s *RocksDBStorage) ScanWithValues(prefix []byte) (map[string][]byte, error
 
 // MSet 批量设置键值对
 func (s *RocksDBStorage) MSet(keyValues map[string][]byte) error {
+	return s.MSetWithTTL(keyValues, 0)
+}
+
+// MSetWithTTL 批量设置键值对并指定存活时间，ttl<=0表示永不过期
+func (s *RocksDBStorage) MSetWithTTL(keyValues map[string][]byte, ttl time.Duration) error {
+	lockKeys := make([][]byte, 0, len(keyValues))
+	for k := range keyValues {
+		lockKeys = append(lockKeys, []byte(k))
+	}
+	unlock := s.locks.lock(lockKeys...)
+	defer unlock()
+
 	wb := gorocksdb.NewWriteBatch()
 	defer wb.Destroy()
 
+	delta := newBatchDelta()
 	for k, v := range keyValues {
-		key := []byte(k)
-
-		// 检查是否需要存储到磁盘
-		if len(v) > s.config.Value.DiskThreshold {
-			// 存储到磁盘
-			filePath, err := s.diskStore.Store(v)
-			if err != nil {
-				return err
-			}
-
-			// 在RocksDB中存储路径
-			diskValue := []byte(DiskStorePrefix + filePath)
-			wb.PutCF(s.defaultCF, key, diskValue)
-		} else {
-			// 直接存储到RocksDB
-			wb.PutCF(s.defaultCF, key, v)
-		}
-
-		// 记录创建时间
-		if err := s.recordCreateTime(key); err != nil {
+		if err := s.putValue(wb, delta, []byte(k), v, ttl, 0); err != nil {
 			return err
 		}
 	}
 
-	return s.db.Write(s.writeOpts, wb)
+	return s.commit(wb, delta)
 }
 
 // MGet 批量获取值
 func (s *RocksDBStorage) MGet(keys [][]byte) (map[string][]byte, error) {
+	return s.MGetContext(context.Background(), keys)
+}
+
+// MGetContext 批量获取值，ctx取消或超时后停止读取并返回ctx.Err()
+func (s *RocksDBStorage) MGetContext(ctx context.Context, keys [][]byte) (map[string][]byte, error) {
 	keyValues := make(map[string][]byte)
 
 	for _, key := range keys {
-		value, found, err := s.Get(key)
-		if err == nil && found {
+		value, found, err := s.GetContext(ctx, key)
+		if err != nil {
+			if ctxErr := ctx.Err(); ctxErr != nil {
+				return nil, ctxErr
+			}
+			continue
+		}
+		if found {
 			keyValues[string(key)] = value
 		}
 	}
@@ -369,40 +909,29 @@ func (s *RocksDBStorage) MGet(keys [][]byte) (map[string][]byte, error) {
 
 // MDelete 批量删除键值对
 func (s *RocksDBStorage) MDelete(keys [][]byte) error {
+	unlock := s.locks.lock(keys...)
+	defer unlock()
+
 	wb := gorocksdb.NewWriteBatch()
 	defer wb.Destroy()
 
+	delta := newBatchDelta()
 	for _, key := range keys {
-		// 先获取值，检查是否存储在磁盘
-		value, err := s.db.GetCF(s.readOpts, s.defaultCF, key)
-		if err != nil {
-			continue
-		}
-
-		if value.Size() > 0 {
-			valueBytes := value.Data()
-			if strings.HasPrefix(string(valueBytes), DiskStorePrefix) {
-				// 删除磁盘文件
-				filePath := strings.TrimPrefix(string(valueBytes), DiskStorePrefix)
-				s.diskStore.Delete(filePath)
-			}
-		}
-		value.Free()
-
-		// 从RocksDB删除
-		wb.DeleteCF(s.defaultCF, key)
-
-		// 从创建时间记录中删除
-		if err := s.removeCreateTime(key); err != nil {
+		if err := s.deleteKey(wb, delta, key); err != nil {
 			continue
 		}
 	}
 
-	return s.db.Write(s.writeOpts, wb)
+	return s.commit(wb, delta)
 }
 
 // GetConfig 获取配置
 func (s *RocksDBStorage) GetConfig() (*config.Config, error) {
+	// 命名空间实例返回生效的配置
+	if !s.isRoot() {
+		return s.config, nil
+	}
+
 	// 如果metadataCF为nil，返回默认配置
 	if s.metadataCF == nil {
 		return config.DefaultConfig(), nil
@@ -430,6 +959,11 @@ func (s *RocksDBStorage) GetConfig() (*config.Config, error) {
 
 // UpdateConfig 更新配置
 func (s *RocksDBStorage) UpdateConfig(cfg *config.Config) error {
+	// 命名空间实例的全局配置由默认命名空间统一更新
+	if !s.isRoot() {
+		return s.namespaces.root.UpdateConfig(cfg)
+	}
+
 	// 序列化配置
 	configBytes, err := cfg.ToJSON()
 	if err != nil {
@@ -456,18 +990,20 @@ func (s *RocksDBStorage) UpdateConfig(cfg *config.Config) error {
 		s.StopEvictionManager()
 	}
 
-	return nil
+	// 重新计算各命名空间生效的配置
+	return s.namespaces.refresh(cfg)
 }
 
 // recordCreateTime 记录创建时间
-func (s *RocksDBStorage) recordCreateTime(key []byte) error {
+func (s *RocksDBStorage) recordCreateTime(key []byte, timestamp int64) error {
 	// 如果createTimeCF为nil，跳过记录创建时间
 	if s.createTimeCF == nil {
 		return nil
 	}
 
-	// 获取当前时间戳（秒）
-	timestamp := time.Now().Unix()
+	s.indexMu.Lock()
+	defer s.indexMu.Unlock()
+
 	timestampKey := []byte(fmt.Sprintf("%d", timestamp))
 
 	// 读取当前时间戳的key列表
@@ -497,6 +1033,69 @@ func (s *RocksDBStorage) recordCreateTime(key []byte) error {
 	return s.db.PutCF(s.writeOpts, s.createTimeCF, timestampKey, keysBytes)
 }
 
+// dropCreateTime 从创建时间记录中删除，元数据中有创建时间时直接定位到对应记录
+func (s *RocksDBStorage) dropCreateTime(key []byte, meta *KeyMeta) error {
+	if meta != nil && meta.CreatedAt > 0 {
+		return s.removeCreateTimeAt(key, meta.CreatedAt)
+	}
+	return s.removeCreateTime(key)
+}
+
+// removeCreateTimeAt 从指定时间戳的创建时间记录中删除
+func (s *RocksDBStorage) removeCreateTimeAt(key []byte, timestamp int64) error {
+	if s.createTimeCF == nil {
+		return nil
+	}
+
+	s.indexMu.Lock()
+	defer s.indexMu.Unlock()
+
+	timestampKey := []byte(fmt.Sprintf("%d", timestamp))
+
+	value, err := s.db.GetCF(s.readOpts, s.createTimeCF, timestampKey)
+	if err != nil {
+		return err
+	}
+
+	var keys []string
+	if value.Size() > 0 {
+		if err := json.Unmarshal(value.Data(), &keys); err != nil {
+			value.Free()
+			return err
+		}
+	}
+	value.Free()
+
+	return s.writeCreateTimeKeys(timestampKey, keys, string(key))
+}
+
+// writeCreateTimeKeys 从key列表中删除指定的key并写回
+func (s *RocksDBStorage) writeCreateTimeKeys(timestampKey []byte, keys []string, keyStr string) error {
+	newKeys := make([]string, 0, len(keys))
+	for _, k := range keys {
+		if k != keyStr {
+			newKeys = append(newKeys, k)
+		}
+	}
+
+	// key不在列表中，无需更新
+	if len(newKeys) == len(keys) {
+		return nil
+	}
+
+	if len(newKeys) == 0 {
+		// 如果没有key了，删除整个记录
+		return s.db.DeleteCF(s.writeOpts, s.createTimeCF, timestampKey)
+	}
+
+	newKeysBytes, err := json.Marshal(newKeys)
+	if err != nil {
+		return err
+	}
+
+	return s.db.PutCF(s.writeOpts, s.createTimeCF, timestampKey, newKeysBytes)
+}
+
 // removeCreateTime 从创建时间记录中删除
 func (s *RocksDBStorage) removeCreateTime(key []byte) error {
 	// 如果createTimeCF为nil，跳过删除创建时间记录
@@ -504,6 +1103,9 @@ func (s *RocksDBStorage) removeCreateTime(key []byte) error {
 		return nil
 	}
 
+	s.indexMu.Lock()
+	defer s.indexMu.Unlock()
+
 	// 遍历所有时间戳
 	iter := s.db.NewIteratorCF(s.readOpts, s.createTimeCF)
 	defer iter.Close()
@@ -511,49 +1113,31 @@ func (s *RocksDBStorage) removeCreateTime(key []byte) error {
 	keyStr := string(key)
 
 	for iter.SeekToFirst(); iter.Valid(); iter.Next() {
-		timestampKey := iter.Key().Data()
-		value := iter.Value().Data()
-
 		var keys []string
-		if err := json.Unmarshal(value, &keys); err != nil {
+		if err := json.Unmarshal(iter.Value().Data(), &keys); err != nil {
 			continue
 		}
 
-		// 查找并删除key
-		newKeys := make([]string, 0)
+		// 查找key所在的记录
 		for _, k := range keys {
-			if k != keyStr {
-				newKeys = append(newKeys, k)
-			}
-		}
-
-		// 如果key被删除了，更新记录
-		if len(newKeys) != len(keys) {
-			if len(newKeys) == 0 {
-				// 如果没有key了，删除整个记录
-				if err := s.db.DeleteCF(s.writeOpts, s.createTimeCF, timestampKey); err != nil {
-					return err
-				}
-			} else {
-				// 更新记录
-				newKeysBytes, err := json.Marshal(newKeys)
-				if err != nil {
-					return err
-				}
-
-				if err := s.db.PutCF(s.writeOpts, s.createTimeCF, timestampKey, newKeysBytes); err != nil {
-					return err
-				}
+			if k == keyStr {
+				timestampKey := make([]byte, len(iter.Key().Data()))
+				copy(timestampKey, iter.Key().Data())
+				return s.writeCreateTimeKeys(timestampKey, keys, keyStr)
 			}
-			break
 		}
 	}
 
-	return nil
+	return iter.Err()
 }
 
 // StartEvictionManager 启动淘汰管理器
 func (s *RocksDBStorage) StartEvictionManager() error {
+	// 从节点的淘汰结果由主节点复制
+	if s.IsFollower() {
+		return nil
+	}
+
 	eviction, err := NewEvictionManager(s)
 	if err != nil {
 		return err
@@ -571,12 +1155,29 @@ func (s *RocksDBStorage) StopEvictionManager() error {
 	return nil
 }
 
-// loadConfig 加载配置
+// loadConfig 加载配置，RocksDB中没有已保存的配置时保留启动时传入的配置
 func (s *RocksDBStorage) loadConfig() error {
-	cfg, err := s.GetConfig()
+	value, err := s.db.GetCF(s.readOpts, s.metadataCF, []byte(config.ConfigKey))
 	if err != nil {
 		return err
 	}
+	defer value.Free()
+
+	if value.Size() == 0 {
+		return nil
+	}
+
+	cfg, err := config.FromJSON(value.Data())
+	if err != nil {
+		return err
+	}
+
+	// 数据路径、复制角色、集群成员身份和分片地址属于本节点，以启动配置为准，从节点的持久化配置来自主节点的检查点
+	cfg.RocksDB.Path = s.config.RocksDB.Path
+	cfg.Value.DiskPath = s.config.Value.DiskPath
+	cfg.Replication = s.config.Replication
+	cfg.Cluster = s.config.Cluster
+	cfg.Sharding = s.config.Sharding
 
 	s.config = cfg
 	return nil
@@ -1,6 +1,10 @@
 package storage
 
 import (
+	"context"
+	"io"
+	"time"
+
 	"kvcache/config"
 )
 
@@ -8,20 +12,94 @@ import (
 type Storage interface {
 	// 基本操作
 	Set(key, value []byte) error
+	SetWithTTL(key, value []byte, ttl time.Duration) error
+	SetIfAbsent(key, value []byte, ttl time.Duration) (bool, error)
 	Get(key []byte) ([]byte, bool, error)
 	Delete(key []byte) error
 	Scan(prefix []byte) ([][]byte, error)
 	ScanWithValues(prefix []byte) (map[string][]byte, error)
 
+	// 可取消的读取，ctx取消或超时后返回ctx.Err()
+	GetContext(ctx context.Context, key []byte) ([]byte, bool, error)
+	ScanContext(ctx context.Context, prefix []byte) ([][]byte, error)
+	ScanWithValuesContext(ctx context.Context, prefix []byte) (map[string][]byte, error)
+	MGetContext(ctx context.Context, keys [][]byte) (map[string][]byte, error)
+	CountPrefixContext(ctx context.Context, prefix []byte, exact bool) (int64, error)
+	SizeOfContext(ctx context.Context, prefix []byte) (*PrefixSize, error)
+
+	// 带版本的读写，较旧时间戳的写入被忽略
+	SetVersioned(key, value []byte, ttl time.Duration, stamp uint64) (bool, error)
+	DeleteVersioned(key []byte, stamp uint64) (bool, error)
+	GetVersioned(key []byte) (*VersionedValue, error)
+
+	// 写回源站的持久化队列，PendingWriteBehind和AckWriteBehind跨所有命名空间
+	MarkWriteBehind(keys ...[]byte) error
+	PendingWriteBehind(limit int) ([]WriteBehindEntry, error)
+	AckWriteBehind(entry WriteBehindEntry) error
+
+	// 增量写入
+	Append(key, data []byte) error
+	WriteAt(key []byte, offset int64, data []byte) error
+
+	// 重命名与复制
+	Rename(src, dst []byte, overwrite bool) error
+	Copy(src, dst []byte) error
+
+	// 元数据查询
+	GetMeta(key []byte) (*KeyInfo, bool, error)
+	Exists(key []byte) (bool, error)
+	MExists(keys [][]byte) (map[string]bool, error)
+	CountPrefix(prefix []byte, exact bool) (int64, error)
+	SizeOf(prefix []byte) (*PrefixSize, error)
+
 	// 批量操作
 	MSet(keyValues map[string][]byte) error
+	MSetWithTTL(keyValues map[string][]byte, ttl time.Duration) error
 	MGet(keys [][]byte) (map[string][]byte, error)
 	MDelete(keys [][]byte) error
 
+	// 范围删除
+	DeletePrefix(prefix []byte) (string, error)
+	DeleteRange(start, end []byte) (string, error)
+	DeleteJob(id string) (*DeleteJob, bool)
+
+	// 变更订阅与变更日志消费
+	Watch(prefix []byte, fromRevision uint64) (*Watcher, error)
+	ReadChanges(fromRevision uint64, limit int) ([]ChangeEvent, error)
+	Checkpoint(name string) (uint64, bool, error)
+	SaveCheckpoint(name string, revision uint64) error
+	DeleteCheckpoint(name string) error
+
+	// 主从复制
+	IsFollower() bool
+	Promote() error
+	LatestRevision() uint64
+	CreateCheckpoint(dir string) (uint64, error)
+	ExportEntry(key []byte) (*ReplicaEntry, error)
+	ApplyChange(event *ChangeEvent, entry *ReplicaEntry) error
+	SkipChange(event *ChangeEvent) error
+	HasBlob(fileName string) bool
+	OpenBlob(fileName string) (io.ReadCloser, error)
+	ImportBlob(fileName string, r io.Reader) error
+	ListBlobs() ([]string, error)
+	MissingBlobs() ([]string, error)
+
 	// 配置操作
 	GetConfig() (*config.Config, error)
 	UpdateConfig(cfg *config.Config) error
 
+	// 配额
+	SetQuota(qc *config.QuotaConfig) error
+	DeleteQuota(prefix string) error
+	ListQuotas() ([]*QuotaStatus, error)
+
+	// 命名空间
+	Namespace(name string) (Storage, error)
+	GetNamespace(name string) (*config.NamespaceConfig, error)
+	ListNamespaces() ([]*config.NamespaceConfig, error)
+	CreateNamespace(nsCfg *config.NamespaceConfig) error
+	DropNamespace(name string) error
+
 	// 管理操作
 	Start() error
 	Stop() error
@@ -39,8 +117,9 @@ func NewStorage(cfg *config.Config) (Storage, error) {
 		return nil, err
 	}
 
-	// 2. 启动存储
+	// 2. 启动存储，失败时释放已打开的数据库
 	if err := storage.Start(); err != nil {
+		storage.Stop()
 		return nil, err
 	}
 
@@ -1,8 +1,19 @@
 package storage
 
 import (
+	"bytes"
+	"context"
+	"encoding/binary"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
 	"os"
+	"path/filepath"
+	"slices"
+	"strings"
 	"testing"
+	"time"
 
 	"kvcache/config"
 )
@@ -429,6 +440,24 @@ func TestDiskStore(t *testing.T) {
 		t.Errorf("Expected data to be '%s', got '%s'", string(testData), string(loadedData))
 	}
 
+	// 测试按块加载跨越多个块的数据
+	largeData := bytes.Repeat([]byte("0123456789"), loadChunkSize/4)
+	largeFile, err := diskStore.Store(largeData)
+	if err != nil {
+		t.Fatalf("Failed to store large data: %v", err)
+	}
+	loadedData, err = diskStore.LoadContext(context.Background(), largeFile)
+	if err != nil || !bytes.Equal(loadedData, largeData) {
+		t.Fatalf("Expected large data to round-trip, got %d bytes, err %v", len(loadedData), err)
+	}
+
+	// 测试ctx已取消时停止加载
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	if _, err := diskStore.LoadContext(ctx, largeFile); !errors.Is(err, context.Canceled) || ErrorCode(err) != CodeCanceled {
+		t.Errorf("Expected canceled load to fail with context.Canceled, got %v", err)
+	}
+
 	// 测试删除数据
 	err = diskStore.Delete(fileName)
 	if err != nil {
@@ -436,14 +465,1549 @@ func TestDiskStore(t *testing.T) {
 	}
 
 	// 测试删除不存在的文件（应该成功）
-	err = diskStore.Delete("non-existent-file")
+	err = diskStore.Delete(strings.Repeat("0", 64))
 	if err != nil {
 		t.Fatalf("Expected success for deleting non-existent file, but got error: %v", err)
 	}
 
+	// 测试拒绝不是内容哈希的文件名
+	for _, name := range []string{"non-existent-file", "../" + fileName} {
+		if _, err := diskStore.Load(name); ErrorCode(err) != CodeInvalidArgument {
+			t.Errorf("Expected invalid file name %q to be rejected by Load, got %v", name, err)
+		}
+		if err := diskStore.Delete(name); ErrorCode(err) != CodeInvalidArgument {
+			t.Errorf("Expected invalid file name %q to be rejected by Delete, got %v", name, err)
+		}
+	}
+
 	// 测试加载已删除的数据（应该失败）
 	_, err = diskStore.Load(fileName)
 	if err == nil {
 		t.Fatalf("Expected error for loading deleted data, but got nil")
 	}
 }
+
+// TestStorageAppendWriteAt 测试内联值的追加和局部写入功能
+func TestStorageAppendWriteAt(t *testing.T) {
+	// 初始化配置
+	cfg := config.DefaultConfig()
+
+	// 删除现有的数据目录，确保测试环境干净
+	os.RemoveAll(cfg.RocksDB.Path)
+	os.RemoveAll(cfg.Value.DiskPath)
+
+	// 创建存储实例
+	store, err := NewStorage(cfg)
+	if err != nil {
+		t.Fatalf("Failed to create storage: %v", err)
+	}
+	defer store.Stop()
+
+	testKey := []byte("append-key")
+
+	// 追加到不存在的键会创建该键
+	if err := store.Append(testKey, []byte("hello")); err != nil {
+		t.Fatalf("Failed to append value: %v", err)
+	}
+	if err := store.Append(testKey, []byte(" world")); err != nil {
+		t.Fatalf("Failed to append value: %v", err)
+	}
+
+	// 覆盖中间部分
+	if err := store.WriteAt(testKey, 6, []byte("WORLD")); err != nil {
+		t.Fatalf("Failed to write at offset: %v", err)
+	}
+
+	value, found, err := store.Get(testKey)
+	if err != nil {
+		t.Fatalf("Failed to get value: %v", err)
+	}
+	if !found {
+		t.Fatalf("Expected key to be found, but it wasn't")
+	}
+	if string(value) != "hello WORLD" {
+		t.Errorf("Expected value to be 'hello WORLD', got '%s'", string(value))
+	}
+
+	// 超出末尾写入时用0填充
+	if err := store.WriteAt(testKey, 13, []byte("!")); err != nil {
+		t.Fatalf("Failed to write at offset: %v", err)
+	}
+
+	value, _, err = store.Get(testKey)
+	if err != nil {
+		t.Fatalf("Failed to get value: %v", err)
+	}
+	if string(value) != "hello WORLD\x00\x00!" {
+		t.Errorf("Expected zero-filled value, got %q", string(value))
+	}
+}
+
+// TestStorageAppendCrossDiskThreshold 测试追加后超过阈值的值自动迁移到磁盘
+func TestStorageAppendCrossDiskThreshold(t *testing.T) {
+	// 初始化配置
+	cfg := config.DefaultConfig()
+
+	// 删除现有的数据目录，确保测试环境干净
+	os.RemoveAll(cfg.RocksDB.Path)
+	os.RemoveAll(cfg.Value.DiskPath)
+
+	// 创建存储实例
+	store, err := NewStorage(cfg)
+	if err != nil {
+		t.Fatalf("Failed to create storage: %v", err)
+	}
+	defer store.Stop()
+
+	// 设置较小的磁盘阈值
+	cfg.Value.DiskThreshold = 16
+	if err := store.UpdateConfig(cfg); err != nil {
+		t.Fatalf("Failed to update config: %v", err)
+	}
+
+	testKey := []byte("append-disk-key")
+
+	// 未超过阈值，内联存储
+	if err := store.Set(testKey, []byte("0123456789")); err != nil {
+		t.Fatalf("Failed to set value: %v", err)
+	}
+
+	// 超过阈值，迁移到磁盘
+	if err := store.Append(testKey, []byte("abcdefghij")); err != nil {
+		t.Fatalf("Failed to append value: %v", err)
+	}
+
+	// 已在磁盘上，写时复制追加
+	if err := store.Append(testKey, []byte("XYZ")); err != nil {
+		t.Fatalf("Failed to append value: %v", err)
+	}
+	if err := store.WriteAt(testKey, 0, []byte("##")); err != nil {
+		t.Fatalf("Failed to write at offset: %v", err)
+	}
+
+	value, found, err := store.Get(testKey)
+	if err != nil {
+		t.Fatalf("Failed to get value: %v", err)
+	}
+	if !found {
+		t.Fatalf("Expected key to be found, but it wasn't")
+	}
+	if string(value) != "##23456789abcdefghijXYZ" {
+		t.Errorf("Expected value to be '##23456789abcdefghijXYZ', got '%s'", string(value))
+	}
+
+	// 磁盘目录中只保留最新的文件
+	entries, err := os.ReadDir(cfg.Value.DiskPath)
+	if err != nil {
+		t.Fatalf("Failed to read disk path: %v", err)
+	}
+	if len(entries) != 1 {
+		t.Errorf("Expected 1 file in disk store, got %d", len(entries))
+	}
+}
+
+// TestDiskStoreAppend 测试磁盘存储的写时复制追加功能
+func TestDiskStoreAppend(t *testing.T) {
+	// 创建临时目录用于测试
+	tempDir, err := os.MkdirTemp("", "diskstore-append-test")
+	if err != nil {
+		t.Fatalf("Failed to create temp directory: %v", err)
+	}
+	defer os.RemoveAll(tempDir)
+
+	diskStore, err := NewDiskStore(tempDir)
+	if err != nil {
+		t.Fatalf("Failed to create disk store: %v", err)
+	}
+	defer diskStore.Close()
+
+	fileName, err := diskStore.Store([]byte("log chunk 1;"))
+	if err != nil {
+		t.Fatalf("Failed to store data: %v", err)
+	}
+
+	newName, err := diskStore.Append(fileName, []byte("log chunk 2;"))
+	if err != nil {
+		t.Fatalf("Failed to append data: %v", err)
+	}
+
+	if newName == fileName {
+		t.Errorf("Expected a new file name after append")
+	}
+
+	// 新文件名与直接存储相同内容时一致
+	expectedName, err := diskStore.Store([]byte("log chunk 1;log chunk 2;"))
+	if err != nil {
+		t.Fatalf("Failed to store data: %v", err)
+	}
+	if newName != expectedName {
+		t.Errorf("Expected file name '%s', got '%s'", expectedName, newName)
+	}
+
+	// 原文件保持不变
+	original, err := diskStore.Load(fileName)
+	if err != nil {
+		t.Fatalf("Failed to load original data: %v", err)
+	}
+	if string(original) != "log chunk 1;" {
+		t.Errorf("Expected original data to be unchanged, got '%s'", string(original))
+	}
+}
+
+// TestStorageGetMeta 测试键元数据查询功能
+func TestStorageGetMeta(t *testing.T) {
+	// 初始化配置，设置较小的磁盘阈值以便测试
+	cfg := config.DefaultConfig()
+	cfg.Value.DiskThreshold = 16
+
+	// 删除现有的数据目录，确保测试环境干净
+	os.RemoveAll(cfg.RocksDB.Path)
+	os.RemoveAll(cfg.Value.DiskPath)
+
+	// 创建存储实例
+	store, err := NewStorage(cfg)
+	if err != nil {
+		t.Fatalf("Failed to create storage: %v", err)
+	}
+	defer store.Stop()
+
+	// 内联存储的值
+	if err := store.Set([]byte("meta-inline"), []byte("small")); err != nil {
+		t.Fatalf("Failed to set value: %v", err)
+	}
+	if err := store.Set([]byte("meta-inline"), []byte("small-2")); err != nil {
+		t.Fatalf("Failed to set value: %v", err)
+	}
+
+	info, found, err := store.GetMeta([]byte("meta-inline"))
+	if err != nil {
+		t.Fatalf("Failed to get meta: %v", err)
+	}
+	if !found {
+		t.Fatalf("Expected meta to be found, but it wasn't")
+	}
+	if info.Size != 7 {
+		t.Errorf("Expected size 7, got %d", info.Size)
+	}
+	if info.Location != LocationInline {
+		t.Errorf("Expected location '%s', got '%s'", LocationInline, info.Location)
+	}
+	if info.Version != 2 {
+		t.Errorf("Expected version 2, got %d", info.Version)
+	}
+	if info.CreatedAt == 0 {
+		t.Errorf("Expected non-zero create time")
+	}
+	if info.TTL != -1 {
+		t.Errorf("Expected TTL -1 for key without expiry, got %v", info.TTL)
+	}
+	if info.Codec != CodecNone || info.Encryption != EncryptionNone {
+		t.Errorf("Expected codec and encryption to be '%s', got '%s' and '%s'", CodecNone, info.Codec, info.Encryption)
+	}
+
+	// 磁盘存储的值
+	largeValue := []byte("this value is larger than the disk threshold")
+	if err := store.SetWithTTL([]byte("meta-disk"), largeValue, time.Hour); err != nil {
+		t.Fatalf("Failed to set value: %v", err)
+	}
+
+	info, found, err = store.GetMeta([]byte("meta-disk"))
+	if err != nil {
+		t.Fatalf("Failed to get meta: %v", err)
+	}
+	if !found {
+		t.Fatalf("Expected meta to be found, but it wasn't")
+	}
+	if info.Location != LocationDisk {
+		t.Errorf("Expected location '%s', got '%s'", LocationDisk, info.Location)
+	}
+	if info.Size != int64(len(largeValue)) {
+		t.Errorf("Expected size %d, got %d", len(largeValue), info.Size)
+	}
+	if _, err := os.Stat(info.DiskPath); err != nil {
+		t.Errorf("Expected disk path '%s' to exist: %v", info.DiskPath, err)
+	}
+	if info.TTL <= 0 || info.TTL > time.Hour+time.Second {
+		t.Errorf("Expected TTL close to 1h, got %v", info.TTL)
+	}
+
+	// 不存在的键
+	_, found, err = store.GetMeta([]byte("meta-missing"))
+	if err != nil {
+		t.Fatalf("Failed to get meta: %v", err)
+	}
+	if found {
+		t.Errorf("Expected meta to be not found")
+	}
+}
+
+// TestStorageTTL 测试键过期功能
+func TestStorageTTL(t *testing.T) {
+	// 初始化配置
+	cfg := config.DefaultConfig()
+
+	// 删除现有的数据目录，确保测试环境干净
+	os.RemoveAll(cfg.RocksDB.Path)
+	os.RemoveAll(cfg.Value.DiskPath)
+
+	// 创建存储实例
+	store, err := NewStorage(cfg)
+	if err != nil {
+		t.Fatalf("Failed to create storage: %v", err)
+	}
+	defer store.Stop()
+
+	if err := store.SetWithTTL([]byte("ttl-key"), []byte("ttl-value"), time.Second); err != nil {
+		t.Fatalf("Failed to set value: %v", err)
+	}
+
+	if _, found, _ := store.Get([]byte("ttl-key")); !found {
+		t.Fatalf("Expected key to be found before expiry")
+	}
+
+	time.Sleep(2100 * time.Millisecond)
+
+	value, found, err := store.Get([]byte("ttl-key"))
+	if err != nil {
+		t.Fatalf("Failed to get value: %v", err)
+	}
+	if found {
+		t.Errorf("Expected key to be expired, got '%s'", string(value))
+	}
+
+	keys, err := store.Scan([]byte("ttl-"))
+	if err != nil {
+		t.Fatalf("Failed to scan: %v", err)
+	}
+	if len(keys) != 0 {
+		t.Errorf("Expected expired key to be excluded from scan, got %d keys", len(keys))
+	}
+}
+
+func TestStorageExistsCountSize(t *testing.T) {
+	// 初始化配置，设置较小的磁盘阈值以便测试
+	cfg := config.DefaultConfig()
+	cfg.Value.DiskThreshold = 16
+
+	// 删除现有的数据目录，确保测试环境干净
+	os.RemoveAll(cfg.RocksDB.Path)
+	os.RemoveAll(cfg.Value.DiskPath)
+
+	// 创建存储实例
+	store, err := NewStorage(cfg)
+	if err != nil {
+		t.Fatalf("Failed to create storage: %v", err)
+	}
+	defer store.Stop()
+
+	largeValue := []byte("this value is larger than the disk threshold")
+	kvs := map[string][]byte{
+		"count:a": []byte("aa"),
+		"count:b": []byte("bbbb"),
+		"count:c": largeValue,
+		"other:a": []byte("x"),
+	}
+	if err := store.MSet(kvs); err != nil {
+		t.Fatalf("Failed to mset: %v", err)
+	}
+
+	// Exists
+	exists, err := store.Exists([]byte("count:c"))
+	if err != nil {
+		t.Fatalf("Failed to check key: %v", err)
+	}
+	if !exists {
+		t.Errorf("Expected key 'count:c' to exist")
+	}
+
+	results, err := store.MExists([][]byte{[]byte("count:a"), []byte("count:missing")})
+	if err != nil {
+		t.Fatalf("Failed to mexists: %v", err)
+	}
+	if !results["count:a"] || results["count:missing"] {
+		t.Errorf("Unexpected mexists results: %v", results)
+	}
+
+	// 精确计数
+	count, err := store.CountPrefix([]byte("count:"), true)
+	if err != nil {
+		t.Fatalf("Failed to count prefix: %v", err)
+	}
+	if count != 3 {
+		t.Errorf("Expected count 3, got %d", count)
+	}
+
+	// 估算计数，数据在内存表中时回退为精确计数
+	count, err = store.CountPrefix([]byte("count:"), false)
+	if err != nil {
+		t.Fatalf("Failed to estimate prefix count: %v", err)
+	}
+	if count < 0 {
+		t.Errorf("Expected non-negative estimate, got %d", count)
+	}
+
+	// 容量统计
+	size, err := store.SizeOf([]byte("count:"))
+	if err != nil {
+		t.Fatalf("Failed to get prefix size: %v", err)
+	}
+	if size.Keys != 3 {
+		t.Errorf("Expected 3 keys, got %d", size.Keys)
+	}
+	if size.InlineBytes != 6 {
+		t.Errorf("Expected 6 inline bytes, got %d", size.InlineBytes)
+	}
+	if size.DiskBytes != int64(len(largeValue)) {
+		t.Errorf("Expected %d disk bytes, got %d", len(largeValue), size.DiskBytes)
+	}
+
+	// 删除后不再存在也不再计数
+	if err := store.Delete([]byte("count:a")); err != nil {
+		t.Fatalf("Failed to delete: %v", err)
+	}
+	exists, err = store.Exists([]byte("count:a"))
+	if err != nil {
+		t.Fatalf("Failed to check key: %v", err)
+	}
+	if exists {
+		t.Errorf("Expected key 'count:a' to be deleted")
+	}
+	count, err = store.CountPrefix([]byte("count:"), true)
+	if err != nil {
+		t.Fatalf("Failed to count prefix: %v", err)
+	}
+	if count != 2 {
+		t.Errorf("Expected count 2 after delete, got %d", count)
+	}
+
+	// 没有元数据的旧数据与Exists一样计入计数和容量
+	root := store.(*RocksDBStorage)
+	if err := root.db.PutCF(root.writeOpts, root.defaultCF, []byte("count:legacy"), newEnvelope(valueInline, nil, []byte("legacy")).encode()); err != nil {
+		t.Fatalf("Failed to write legacy value: %v", err)
+	}
+	if exists, err := store.Exists([]byte("count:legacy")); err != nil || !exists {
+		t.Errorf("Expected legacy key to exist: %v", err)
+	}
+	if count, err := store.CountPrefix([]byte("count:"), true); err != nil || count != 3 {
+		t.Errorf("Expected count 3 with legacy key, got %d: %v", count, err)
+	}
+	size, err = store.SizeOf([]byte("count:"))
+	if err != nil || size.Keys != 3 || size.InlineBytes != 10 || size.DiskBytes != int64(len(largeValue)) {
+		t.Errorf("Expected 3 keys, 10 inline and %d disk bytes with legacy key, got %+v: %v", len(largeValue), size, err)
+	}
+}
+
+func TestPrefixEnd(t *testing.T) {
+	tests := []struct {
+		prefix []byte
+		want   []byte
+	}{
+		{[]byte("abc"), []byte("abd")},
+		{[]byte{'a', 0xff}, []byte("b")},
+		{[]byte{0xff, 0xff}, nil},
+		{nil, nil},
+	}
+
+	for _, tt := range tests {
+		got := prefixEnd(tt.prefix)
+		if string(got) != string(tt.want) || (got == nil) != (tt.want == nil) {
+			t.Errorf("prefixEnd(%q) = %q, want %q", tt.prefix, got, tt.want)
+		}
+	}
+}
+
+func TestStorageRenameCopy(t *testing.T) {
+	// 初始化配置，设置较小的磁盘阈值以便测试
+	cfg := config.DefaultConfig()
+	cfg.Value.DiskThreshold = 16
+
+	// 删除现有的数据目录，确保测试环境干净
+	os.RemoveAll(cfg.RocksDB.Path)
+	os.RemoveAll(cfg.Value.DiskPath)
+
+	// 创建存储实例
+	store, err := NewStorage(cfg)
+	if err != nil {
+		t.Fatalf("Failed to create storage: %v", err)
+	}
+	defer store.Stop()
+
+	largeValue := []byte("this value is larger than the disk threshold")
+	if err := store.Set([]byte("rename-src"), largeValue); err != nil {
+		t.Fatalf("Failed to set value: %v", err)
+	}
+	srcInfo, _, err := store.GetMeta([]byte("rename-src"))
+	if err != nil {
+		t.Fatalf("Failed to get meta: %v", err)
+	}
+
+	// 重命名后源键不存在，目标键复用同一个磁盘文件
+	if err := store.Rename([]byte("rename-src"), []byte("rename-dst"), false); err != nil {
+		t.Fatalf("Failed to rename: %v", err)
+	}
+	if _, found, _ := store.Get([]byte("rename-src")); found {
+		t.Errorf("Expected source key to be removed after rename")
+	}
+	value, found, err := store.Get([]byte("rename-dst"))
+	if err != nil || !found || string(value) != string(largeValue) {
+		t.Fatalf("Expected renamed value '%s', got '%s' (found=%v, err=%v)", largeValue, value, found, err)
+	}
+	dstInfo, _, err := store.GetMeta([]byte("rename-dst"))
+	if err != nil {
+		t.Fatalf("Failed to get meta: %v", err)
+	}
+	if dstInfo.DiskPath != srcInfo.DiskPath {
+		t.Errorf("Expected disk path '%s' to be reused, got '%s'", srcInfo.DiskPath, dstInfo.DiskPath)
+	}
+	if dstInfo.CreatedAt != srcInfo.CreatedAt {
+		t.Errorf("Expected create time to be preserved, got %d and %d", srcInfo.CreatedAt, dstInfo.CreatedAt)
+	}
+
+	// 目标键已存在且不允许覆盖
+	if err := store.Set([]byte("rename-other"), []byte("other")); err != nil {
+		t.Fatalf("Failed to set value: %v", err)
+	}
+	if err := store.Rename([]byte("rename-other"), []byte("rename-dst"), false); err == nil {
+		t.Errorf("Expected error when destination exists")
+	}
+
+	// 复制共享磁盘文件，删除其中一个键后文件仍然保留
+	if err := store.Copy([]byte("rename-dst"), []byte("rename-copy")); err != nil {
+		t.Fatalf("Failed to copy: %v", err)
+	}
+	if err := store.Delete([]byte("rename-dst")); err != nil {
+		t.Fatalf("Failed to delete: %v", err)
+	}
+	if _, err := os.Stat(dstInfo.DiskPath); err != nil {
+		t.Fatalf("Expected shared disk file to remain: %v", err)
+	}
+	value, found, err = store.Get([]byte("rename-copy"))
+	if err != nil || !found || string(value) != string(largeValue) {
+		t.Fatalf("Expected copied value '%s', got '%s' (found=%v, err=%v)", largeValue, value, found, err)
+	}
+
+	// 最后一个引用删除后文件被回收
+	if err := store.Delete([]byte("rename-copy")); err != nil {
+		t.Fatalf("Failed to delete: %v", err)
+	}
+	if _, err := os.Stat(dstInfo.DiskPath); !os.IsNotExist(err) {
+		t.Errorf("Expected disk file to be removed, got %v", err)
+	}
+}
+
+func TestStorageSharedBlobOverwrite(t *testing.T) {
+	// 初始化配置，设置较小的磁盘阈值以便测试
+	cfg := config.DefaultConfig()
+	cfg.Value.DiskThreshold = 16
+
+	// 删除现有的数据目录，确保测试环境干净
+	os.RemoveAll(cfg.RocksDB.Path)
+	os.RemoveAll(cfg.Value.DiskPath)
+
+	// 创建存储实例
+	store, err := NewStorage(cfg)
+	if err != nil {
+		t.Fatalf("Failed to create storage: %v", err)
+	}
+	defer store.Stop()
+
+	// 相同内容的值共享同一个磁盘文件
+	largeValue := []byte("this value is larger than the disk threshold")
+	if err := store.MSet(map[string][]byte{"shared-1": largeValue, "shared-2": largeValue}); err != nil {
+		t.Fatalf("Failed to mset: %v", err)
+	}
+	info, _, err := store.GetMeta([]byte("shared-1"))
+	if err != nil {
+		t.Fatalf("Failed to get meta: %v", err)
+	}
+
+	// 覆盖其中一个键不会删除另一个键仍在使用的文件
+	if err := store.Set([]byte("shared-1"), []byte("small")); err != nil {
+		t.Fatalf("Failed to set value: %v", err)
+	}
+	value, found, err := store.Get([]byte("shared-2"))
+	if err != nil || !found || string(value) != string(largeValue) {
+		t.Fatalf("Expected shared value '%s', got '%s' (found=%v, err=%v)", largeValue, value, found, err)
+	}
+
+	// 覆盖最后一个引用后文件被回收
+	if err := store.Set([]byte("shared-2"), []byte("small")); err != nil {
+		t.Fatalf("Failed to set value: %v", err)
+	}
+	if _, err := os.Stat(info.DiskPath); !os.IsNotExist(err) {
+		t.Errorf("Expected disk file to be removed, got %v", err)
+	}
+}
+
+func TestStorageDeletePrefix(t *testing.T) {
+	// 初始化配置，设置较小的磁盘阈值以便测试
+	cfg := config.DefaultConfig()
+	cfg.Value.DiskThreshold = 16
+
+	// 删除现有的数据目录，确保测试环境干净
+	os.RemoveAll(cfg.RocksDB.Path)
+	os.RemoveAll(cfg.Value.DiskPath)
+
+	// 创建存储实例
+	store, err := NewStorage(cfg)
+	if err != nil {
+		t.Fatalf("Failed to create storage: %v", err)
+	}
+	defer store.Stop()
+
+	largeValue := []byte("this value is larger than the disk threshold")
+	kvs := map[string][]byte{
+		"tenant1:a": []byte("a"),
+		"tenant1:b": largeValue,
+		"tenant2:a": []byte("keep"),
+	}
+	if err := store.MSet(kvs); err != nil {
+		t.Fatalf("Failed to mset: %v", err)
+	}
+	info, _, err := store.GetMeta([]byte("tenant1:b"))
+	if err != nil {
+		t.Fatalf("Failed to get meta: %v", err)
+	}
+
+	jobID, err := store.DeletePrefix([]byte("tenant1:"))
+	if err != nil {
+		t.Fatalf("Failed to delete prefix: %v", err)
+	}
+
+	// 键立即不可见
+	if _, found, _ := store.Get([]byte("tenant1:a")); found {
+		t.Errorf("Expected key 'tenant1:a' to be deleted")
+	}
+	count, err := store.CountPrefix([]byte("tenant1:"), true)
+	if err != nil {
+		t.Fatalf("Failed to count prefix: %v", err)
+	}
+	if count != 0 {
+		t.Errorf("Expected count 0 after delete, got %d", count)
+	}
+
+	// 等待后台清理完成
+	var job *DeleteJob
+	for i := 0; i < 100; i++ {
+		job, _ = store.DeleteJob(jobID)
+		if job != nil && job.State != DeleteJobRunning {
+			break
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+	if job == nil || job.State != DeleteJobDone {
+		t.Fatalf("Expected delete job to be done, got %+v", job)
+	}
+	if job.KeysDeleted != 2 || job.BlobsReleased != 1 {
+		t.Errorf("Expected 2 keys and 1 blob released, got %d and %d", job.KeysDeleted, job.BlobsReleased)
+	}
+	if _, err := os.Stat(info.DiskPath); !os.IsNotExist(err) {
+		t.Errorf("Expected disk file to be removed, got %v", err)
+	}
+
+	// 其他前缀不受影响
+	value, found, err := store.Get([]byte("tenant2:a"))
+	if err != nil || !found || string(value) != "keep" {
+		t.Errorf("Expected 'tenant2:a' to remain, got '%s' (found=%v, err=%v)", value, found, err)
+	}
+
+	// 非法的范围
+	if _, err := store.DeleteRange([]byte("b"), []byte("a")); err == nil {
+		t.Errorf("Expected error for invalid range")
+	}
+	if _, err := store.DeletePrefix(nil); err == nil {
+		t.Errorf("Expected error for empty prefix")
+	}
+}
+
+func TestStorageNamespaces(t *testing.T) {
+	// 初始化配置，设置较小的磁盘阈值以便测试
+	cfg := config.DefaultConfig()
+	cfg.Value.DiskThreshold = 16
+
+	// 删除现有的数据目录，确保测试环境干净
+	os.RemoveAll(cfg.RocksDB.Path)
+	os.RemoveAll(cfg.Value.DiskPath)
+
+	// 创建存储实例
+	store, err := NewStorage(cfg)
+	if err != nil {
+		t.Fatalf("Failed to create storage: %v", err)
+	}
+
+	if err := store.CreateNamespace(&config.NamespaceConfig{Name: "tenant-a", DefaultTTL: 60}); err != nil {
+		t.Fatalf("Failed to create namespace: %v", err)
+	}
+	if err := store.CreateNamespace(&config.NamespaceConfig{Name: "tenant-a"}); err == nil {
+		t.Errorf("Expected error when creating an existing namespace")
+	}
+	if err := store.CreateNamespace(&config.NamespaceConfig{Name: "bad/name"}); err == nil {
+		t.Errorf("Expected error for invalid namespace name")
+	}
+
+	// 同名键在不同命名空间中相互隔离
+	tenant, err := store.Namespace("tenant-a")
+	if err != nil {
+		t.Fatalf("Failed to open namespace: %v", err)
+	}
+	largeValue := []byte("this value is larger than the disk threshold")
+	if err := store.Set([]byte("shared"), []byte("root")); err != nil {
+		t.Fatalf("Failed to set value: %v", err)
+	}
+	if err := tenant.Set([]byte("shared"), largeValue); err != nil {
+		t.Fatalf("Failed to set value: %v", err)
+	}
+	value, found, err := store.Get([]byte("shared"))
+	if err != nil || !found || string(value) != "root" {
+		t.Errorf("Expected 'root' in default namespace, got '%s' (found=%v, err=%v)", value, found, err)
+	}
+	value, found, err = tenant.Get([]byte("shared"))
+	if err != nil || !found || string(value) != string(largeValue) {
+		t.Errorf("Expected '%s' in namespace, got '%s' (found=%v, err=%v)", largeValue, value, found, err)
+	}
+	keys, err := tenant.Scan([]byte(""))
+	if err != nil || len(keys) != 1 {
+		t.Errorf("Expected 1 key in namespace, got %d (err=%v)", len(keys), err)
+	}
+
+	// 重启后命名空间和数据仍然存在
+	store.Stop()
+	store, err = NewStorage(cfg)
+	if err != nil {
+		t.Fatalf("Failed to reopen storage: %v", err)
+	}
+	defer store.Stop()
+
+	namespaces, err := store.ListNamespaces()
+	if err != nil || len(namespaces) != 2 || namespaces[1].Name != "tenant-a" || namespaces[1].DefaultTTL != 60 {
+		t.Fatalf("Expected default and tenant-a namespaces, got %v (err=%v)", namespaces, err)
+	}
+	tenant, err = store.Namespace("tenant-a")
+	if err != nil {
+		t.Fatalf("Failed to open namespace: %v", err)
+	}
+	value, found, err = tenant.Get([]byte("shared"))
+	if err != nil || !found || string(value) != string(largeValue) {
+		t.Errorf("Expected namespace value after restart, got '%s' (found=%v, err=%v)", value, found, err)
+	}
+
+	// 删除命名空间会移除其数据和磁盘目录
+	if err := store.DropNamespace(config.DefaultNamespace); err == nil {
+		t.Errorf("Expected error when dropping the default namespace")
+	}
+	if err := store.DropNamespace("tenant-a"); err != nil {
+		t.Fatalf("Failed to drop namespace: %v", err)
+	}
+	if _, err := store.Namespace("tenant-a"); err == nil {
+		t.Errorf("Expected error when opening a dropped namespace")
+	}
+	if _, err := os.Stat(config.NamespaceDiskPath(cfg, "tenant-a")); !os.IsNotExist(err) {
+		t.Errorf("Expected namespace disk directory to be removed, got %v", err)
+	}
+	if value, found, _ := store.Get([]byte("shared")); !found || string(value) != "root" {
+		t.Errorf("Expected default namespace to be unaffected, got '%s'", value)
+	}
+}
+
+func TestStorageQuota(t *testing.T) {
+	// 初始化配置，设置较小的磁盘阈值以便测试
+	cfg := config.DefaultConfig()
+	cfg.Value.DiskThreshold = 16
+
+	// 删除现有的数据目录，确保测试环境干净
+	os.RemoveAll(cfg.RocksDB.Path)
+	os.RemoveAll(cfg.Value.DiskPath)
+
+	// 创建存储实例
+	store, err := NewStorage(cfg)
+	if err != nil {
+		t.Fatalf("Failed to create storage: %v", err)
+	}
+
+	// 已有数据计入新建配额的用量
+	if err := store.Set([]byte("job:1"), []byte("small")); err != nil {
+		t.Fatalf("Failed to set value: %v", err)
+	}
+	if err := store.SetQuota(&config.QuotaConfig{Prefix: "job:", MaxKeys: 2, MaxDiskBytes: 64}); err != nil {
+		t.Fatalf("Failed to set quota: %v", err)
+	}
+
+	largeValue := []byte("this value is larger than the disk threshold")
+	if err := store.Set([]byte("job:2"), largeValue); err != nil {
+		t.Fatalf("Failed to set value within quota: %v", err)
+	}
+
+	// 超出键数量限制的写入被拒绝，其他前缀不受影响
+	if err := store.Set([]byte("job:3"), []byte("small")); !errors.Is(err, ErrQuotaExceeded) {
+		t.Errorf("Expected quota exceeded error, got %v", err)
+	}
+	if _, found, _ := store.Get([]byte("job:3")); found {
+		t.Errorf("Expected rejected key to be absent")
+	}
+	if err := store.Set([]byte("other"), []byte("small")); err != nil {
+		t.Errorf("Expected write outside quota prefix to succeed, got %v", err)
+	}
+
+	// 覆盖写入不增加键数量，但超出磁盘字节限制时被拒绝
+	if err := store.Set([]byte("job:1"), []byte("tiny")); err != nil {
+		t.Errorf("Expected overwrite within quota to succeed, got %v", err)
+	}
+	if err := store.Set([]byte("job:1"), append(largeValue, largeValue...)); !errors.Is(err, ErrQuotaExceeded) {
+		t.Errorf("Expected disk quota exceeded error, got %v", err)
+	}
+
+	quotas, err := store.ListQuotas()
+	if err != nil || len(quotas) != 1 {
+		t.Fatalf("Expected 1 quota, got %d (err=%v)", len(quotas), err)
+	}
+	want := QuotaUsage{Keys: 2, InlineBytes: 4, DiskBytes: int64(len(largeValue))}
+	if quotas[0].Usage != want {
+		t.Errorf("Expected usage %+v, got %+v", want, quotas[0].Usage)
+	}
+
+	// 删除后释放用量，重启后用量保持
+	if err := store.Delete([]byte("job:2")); err != nil {
+		t.Fatalf("Failed to delete: %v", err)
+	}
+	store.Stop()
+	store, err = NewStorage(cfg)
+	if err != nil {
+		t.Fatalf("Failed to reopen storage: %v", err)
+	}
+	defer store.Stop()
+
+	quotas, _ = store.ListQuotas()
+	want = QuotaUsage{Keys: 1, InlineBytes: 4}
+	if len(quotas) != 1 || quotas[0].Usage != want {
+		t.Fatalf("Expected usage %+v after restart, got %v", want, quotas)
+	}
+
+	// 配置为淘汰时接受写入，并在后台删除最早创建的键
+	if err := store.SetQuota(&config.QuotaConfig{Prefix: "job:", MaxKeys: 2, Action: config.QuotaActionEvict}); err != nil {
+		t.Fatalf("Failed to update quota: %v", err)
+	}
+	for _, key := range []string{"job:4", "job:5"} {
+		if err := store.Set([]byte(key), []byte("small")); err != nil {
+			t.Fatalf("Expected write to be accepted by evict quota, got %v", err)
+		}
+	}
+	deadline := time.Now().Add(5 * time.Second)
+	for {
+		quotas, _ = store.ListQuotas()
+		if quotas[0].Usage.Keys <= 2 {
+			break
+		}
+		if time.Now().After(deadline) {
+			t.Fatalf("Expected eviction to bring keys within quota, got %+v", quotas[0].Usage)
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+
+	if err := store.DeleteQuota("job:"); err != nil {
+		t.Fatalf("Failed to delete quota: %v", err)
+	}
+	if err := store.DeleteQuota("job:"); err == nil {
+		t.Errorf("Expected error when deleting a missing quota")
+	}
+}
+
+// TestStorageWatch 测试变更订阅、历史回放和日志裁剪
+func TestStorageWatch(t *testing.T) {
+	// 初始化配置，使用较小的保留数量以便测试裁剪
+	cfg := config.DefaultConfig()
+	cfg.ChangeLog.Retention = 10
+
+	// 删除现有的数据目录，确保测试环境干净
+	os.RemoveAll(cfg.RocksDB.Path)
+	os.RemoveAll(cfg.Value.DiskPath)
+
+	// 创建存储实例
+	store, err := NewStorage(cfg)
+	if err != nil {
+		t.Fatalf("Failed to create storage: %v", err)
+	}
+	defer store.Stop()
+
+	next := func(w *Watcher) ChangeEvent {
+		select {
+		case event, ok := <-w.Events:
+			if !ok {
+				t.Fatalf("Watcher closed unexpectedly: %v", w.Err())
+			}
+			return event
+		case <-time.After(5 * time.Second):
+			t.Fatalf("Timed out waiting for event")
+		}
+		return ChangeEvent{}
+	}
+
+	// 实时事件按提交顺序推送，只包含匹配前缀的键
+	watcher, err := store.Watch([]byte("user:"), 0)
+	if err != nil {
+		t.Fatalf("Failed to watch: %v", err)
+	}
+	store.Set([]byte("user:1"), []byte("a"))
+	store.Set([]byte("other"), []byte("b"))
+	store.Set([]byte("user:1"), []byte("bb"))
+	store.Delete([]byte("user:1"))
+	store.SetWithTTL([]byte("user:2"), []byte("c"), time.Second)
+
+	first := next(watcher)
+	if first.Type != EventPut || string(first.Key) != "user:1" || first.Version != 1 {
+		t.Errorf("Unexpected first event: %+v", first)
+	}
+	expected := []string{EventPut, EventDelete, EventPut}
+	for _, eventType := range expected {
+		event := next(watcher)
+		if event.Type != eventType || event.Revision <= first.Revision {
+			t.Errorf("Expected %s event after revision %d, got %+v", eventType, first.Revision, event)
+		}
+	}
+
+	// 过期删除生成expire事件
+	time.Sleep(2100 * time.Millisecond)
+	store.Get([]byte("user:2"))
+	if event := next(watcher); event.Type != EventExpire || string(event.Key) != "user:2" {
+		t.Errorf("Expected expire event, got %+v", event)
+	}
+	watcher.Close()
+
+	// 从历史修订号回放
+	replay, err := store.Watch([]byte("user:"), first.Revision)
+	if err != nil {
+		t.Fatalf("Failed to watch from revision: %v", err)
+	}
+	if event := next(replay); event.Revision != first.Revision || event.Type != EventPut {
+		t.Errorf("Expected replay to start at revision %d, got %+v", first.Revision, event)
+	}
+	replay.Close()
+
+	// 范围删除与前缀相交时推送
+	watcher, err = store.Watch([]byte("user:"), 0)
+	if err != nil {
+		t.Fatalf("Failed to watch: %v", err)
+	}
+	if _, err := store.DeletePrefix([]byte("u")); err != nil {
+		t.Fatalf("Failed to delete prefix: %v", err)
+	}
+	if event := next(watcher); event.Type != EventDeleteRange || string(event.Key) != "u" || string(event.End) != "v" {
+		t.Errorf("Expected delete_range event, got %+v", event)
+	}
+	watcher.Close()
+
+	// 超出保留数量后旧的修订号被裁剪
+	for i := 0; i < 1100; i++ {
+		if err := store.Set([]byte("fill"), []byte("x")); err != nil {
+			t.Fatalf("Failed to set value: %v", err)
+		}
+	}
+	if _, err := store.Watch([]byte("user:"), first.Revision); !errors.Is(err, ErrCompacted) {
+		t.Errorf("Expected compacted error, got %v", err)
+	}
+}
+
+// TestStorageChangeCheckpoint 测试消费者检查点阻止变更日志裁剪
+func TestStorageChangeCheckpoint(t *testing.T) {
+	// 初始化配置，使用较小的保留数量以便测试裁剪
+	cfg := config.DefaultConfig()
+	cfg.ChangeLog.Retention = 10
+
+	// 删除现有的数据目录，确保测试环境干净
+	os.RemoveAll(cfg.RocksDB.Path)
+	os.RemoveAll(cfg.Value.DiskPath)
+
+	// 创建存储实例
+	store, err := NewStorage(cfg)
+	if err != nil {
+		t.Fatalf("Failed to create storage: %v", err)
+	}
+	defer store.Stop()
+
+	store.Set([]byte("cdc"), []byte("value"))
+	events, err := store.ReadChanges(0, 10)
+	if err != nil || len(events) != 1 {
+		t.Fatalf("Expected one change, got %v (err=%v)", events, err)
+	}
+	first := events[0].Revision
+	if err := store.SaveCheckpoint("sink", first-1); err != nil {
+		t.Fatalf("Failed to save checkpoint: %v", err)
+	}
+
+	// 检查点之后的变更不会被裁剪
+	for i := 0; i < 1100; i++ {
+		store.Set([]byte("fill"), []byte("x"))
+	}
+	events, err = store.ReadChanges(first, 1)
+	if err != nil || len(events) != 1 || events[0].Revision != first {
+		t.Fatalf("Expected change %d to be retained, got %v (err=%v)", first, events, err)
+	}
+
+	// 删除检查点后恢复按保留数量裁剪
+	if err := store.DeleteCheckpoint("sink"); err != nil {
+		t.Fatalf("Failed to delete checkpoint: %v", err)
+	}
+	store.Set([]byte("fill"), []byte("x"))
+	if _, err := store.ReadChanges(first, 1); !errors.Is(err, ErrCompacted) {
+		t.Errorf("Expected compacted error, got %v", err)
+	}
+}
+
+func TestStorageReplication(t *testing.T) {
+	// 初始化主节点和从节点的配置，使用不同的数据目录
+	cfg := config.DefaultConfig()
+	cfg.Value.DiskThreshold = 1024
+	followerCfg := config.DefaultConfig()
+	followerCfg.Value.DiskThreshold = 1024
+	followerCfg.RocksDB.Path = cfg.RocksDB.Path + "_follower"
+	followerCfg.Value.DiskPath = cfg.Value.DiskPath + "_follower"
+	followerCfg.Replication.LeaderAddr = "leader"
+
+	// 删除现有的数据目录，确保测试环境干净
+	for _, c := range []*config.Config{cfg, followerCfg} {
+		os.RemoveAll(c.RocksDB.Path)
+		os.RemoveAll(c.Value.DiskPath)
+	}
+
+	// 创建存储实例
+	leader, err := NewStorage(cfg)
+	if err != nil {
+		t.Fatalf("Failed to create leader storage: %v", err)
+	}
+	defer leader.Stop()
+	follower, err := NewStorage(followerCfg)
+	if err != nil {
+		t.Fatalf("Failed to create follower storage: %v", err)
+	}
+	defer follower.Stop()
+
+	// 从节点拒绝本地写入
+	if !follower.IsFollower() {
+		t.Fatal("Expected storage to start as a follower")
+	}
+	if err := follower.Set([]byte("local"), []byte("value")); !errors.Is(err, ErrReadOnly) {
+		t.Fatalf("Expected read-only error, got %v", err)
+	}
+
+	// replicate 将主节点的变更按修订号应用到从节点
+	var applied uint64
+	replicate := func() {
+		events, err := leader.ReadChanges(applied+1, 100)
+		if err != nil {
+			t.Fatalf("Failed to read changes: %v", err)
+		}
+		for i := range events {
+			event := &events[i]
+			var entry *ReplicaEntry
+			if event.Type == EventPut {
+				if entry, err = leader.ExportEntry(event.Key); err != nil {
+					t.Fatalf("Failed to export %s: %v", event.Key, err)
+				}
+				if entry != nil && entry.Meta.DiskFile != "" && !follower.HasBlob(entry.Meta.DiskFile) {
+					r, err := leader.OpenBlob(entry.Meta.DiskFile)
+					if err != nil {
+						t.Fatalf("Failed to open blob: %v", err)
+					}
+					err = follower.ImportBlob(entry.Meta.DiskFile, r)
+					r.Close()
+					if err != nil {
+						t.Fatalf("Failed to import blob: %v", err)
+					}
+				}
+			}
+			if err := follower.ApplyChange(event, entry); err != nil {
+				t.Fatalf("Failed to apply revision %d: %v", event.Revision, err)
+			}
+			applied = event.Revision
+		}
+	}
+
+	// 复制内联值和磁盘值
+	large := bytes.Repeat([]byte("x"), 4096)
+	leader.Set([]byte("inline"), []byte("value"))
+	leader.Set([]byte("disk"), large)
+	leader.Set([]byte("gone"), []byte("value"))
+	leader.Delete([]byte("gone"))
+	replicate()
+
+	if value, found, err := follower.Get([]byte("inline")); err != nil || !found || string(value) != "value" {
+		t.Errorf("Expected replicated inline value, got %q (found=%v, err=%v)", value, found, err)
+	}
+	if value, found, err := follower.Get([]byte("disk")); err != nil || !found || !bytes.Equal(value, large) {
+		t.Errorf("Expected replicated disk value (found=%v, err=%v)", found, err)
+	}
+	if _, found, _ := follower.Get([]byte("gone")); found {
+		t.Error("Expected deleted key to be absent on follower")
+	}
+	if follower.LatestRevision() != leader.LatestRevision() {
+		t.Errorf("Expected follower revision %d, got %d", leader.LatestRevision(), follower.LatestRevision())
+	}
+
+	// 重复应用已应用的修订号会被拒绝
+	events, _ := leader.ReadChanges(applied, 1)
+	if err := follower.ApplyChange(&events[0], nil); err == nil {
+		t.Error("Expected error when applying an old revision")
+	}
+
+	// 提升后接受本地写入，修订号延续主节点
+	if err := follower.Promote(); err != nil {
+		t.Fatalf("Failed to promote: %v", err)
+	}
+	if err := follower.Set([]byte("local"), []byte("value")); err != nil {
+		t.Fatalf("Failed to write after promotion: %v", err)
+	}
+	if follower.LatestRevision() != applied+1 {
+		t.Errorf("Expected revision %d after promotion, got %d", applied+1, follower.LatestRevision())
+	}
+}
+
+// TestStorageVersioned 测试带版本的写入忽略较旧的时间戳，删除后保留墓碑
+func TestStorageVersioned(t *testing.T) {
+	cfg := config.DefaultConfig()
+	cfg.Value.DiskThreshold = 16
+
+	os.RemoveAll(cfg.RocksDB.Path)
+	os.RemoveAll(cfg.Value.DiskPath)
+
+	store, err := NewStorage(cfg)
+	if err != nil {
+		t.Fatalf("Failed to create storage: %v", err)
+	}
+	defer store.Stop()
+
+	key := []byte("versioned")
+	largeValue := []byte("this value is larger than the disk threshold")
+
+	// 1. 较新的时间戳覆盖，较旧的被忽略
+	if applied, err := store.SetVersioned(key, []byte("v1"), 0, 10); err != nil || !applied {
+		t.Fatalf("Expected first write applied, got %v: %v", applied, err)
+	}
+	if applied, err := store.SetVersioned(key, largeValue, 0, 20); err != nil || !applied {
+		t.Fatalf("Expected newer write applied, got %v: %v", applied, err)
+	}
+	if applied, err := store.SetVersioned(key, []byte("stale"), 0, 15); err != nil || applied {
+		t.Fatalf("Expected older write ignored, got %v: %v", applied, err)
+	}
+	result, err := store.GetVersioned(key)
+	if err != nil || !result.Found || result.Stamp != 20 || !bytes.Equal(result.Value, largeValue) {
+		t.Fatalf("Unexpected versioned value %+v: %v", result, err)
+	}
+
+	// 2. 删除后保留墓碑，较旧的写入不会使键复活
+	if applied, err := store.DeleteVersioned(key, 30); err != nil || !applied {
+		t.Fatalf("Expected delete applied, got %v: %v", applied, err)
+	}
+	if applied, _ := store.SetVersioned(key, []byte("stale"), 0, 25); applied {
+		t.Errorf("Expected write older than tombstone ignored")
+	}
+	result, err = store.GetVersioned(key)
+	if err != nil || result.Found || result.Stamp != 30 {
+		t.Errorf("Expected tombstone at 30, got %+v: %v", result, err)
+	}
+	if _, found, _ := store.Get(key); found {
+		t.Errorf("Expected key deleted")
+	}
+
+	// 3. 更新的写入移除墓碑
+	if applied, _ := store.SetVersioned(key, []byte("v3"), time.Hour, 40); !applied {
+		t.Fatalf("Expected write newer than tombstone applied")
+	}
+	result, err = store.GetVersioned(key)
+	if err != nil || !result.Found || string(result.Value) != "v3" || result.TTL <= 0 {
+		t.Errorf("Unexpected value after tombstone %+v: %v", result, err)
+	}
+}
+
+// TestStorageEvicted 测试已淘汰的值返回ErrEvicted、记录淘汰时间、超过保留期后清理标记以及SetIfAbsent替换已淘汰的值和淘汰索引
+// 没有元数据的旧数据淘汰后同样记录淘汰时间并被清理
+func TestStorageEvicted(t *testing.T) {
+	cfg := config.DefaultConfig()
+	cfg.Value.DiskThreshold = 16
+
+	os.RemoveAll(cfg.RocksDB.Path)
+	os.RemoveAll(cfg.Value.DiskPath)
+
+	store, err := NewRocksDBStorage(cfg)
+	if err != nil {
+		t.Fatalf("Failed to create storage: %v", err)
+	}
+	if err := store.Start(); err != nil {
+		t.Fatalf("Failed to start storage: %v", err)
+	}
+	defer store.Stop()
+
+	largeValue := []byte("this value is larger than the disk threshold")
+	evict := func(key []byte) {
+		if err := store.Set(key, largeValue); err != nil {
+			t.Fatalf("Failed to set %s: %v", key, err)
+		}
+		pointer, err := store.db.GetCF(store.readOpts, store.defaultCF, key)
+		if err != nil {
+			t.Fatalf("Failed to read disk pointer: %v", err)
+		}
+		value := append([]byte(nil), pointer.Data()...)
+		pointer.Free()
+		if err := store.evictValue(key, value); err != nil {
+			t.Fatalf("Failed to evict %s: %v", key, err)
+		}
+	}
+
+	// 1. 已淘汰的值返回ErrEvicted，元数据记录淘汰时间
+	evict([]byte("evicted1"))
+	if _, found, err := store.Get([]byte("evicted1")); !found || !errors.Is(err, ErrEvicted) {
+		t.Errorf("Expected ErrEvicted, got found=%v: %v", found, err)
+	}
+	info, found, err := store.GetMeta([]byte("evicted1"))
+	if err != nil || !found || !info.Evicted || info.EvictedAt == 0 {
+		t.Errorf("Expected evicted meta with timestamp, got %+v: %v", info, err)
+	}
+
+	// 2. SetIfAbsent替换已淘汰的值
+	evict([]byte("evicted2"))
+	if ok, err := store.SetIfAbsent([]byte("evicted2"), []byte("reloaded"), 0); err != nil || !ok {
+		t.Fatalf("Expected evicted value replaced, got %v: %v", ok, err)
+	}
+	if value, _, err := store.Get([]byte("evicted2")); err != nil || string(value) != "reloaded" {
+		t.Errorf("Expected reloaded value, got %q: %v", value, err)
+	}
+
+	// 3. 没有元数据的旧数据淘汰后记录淘汰时间
+	if err := store.Set([]byte("legacy"), largeValue); err != nil {
+		t.Fatalf("Failed to set legacy: %v", err)
+	}
+	if err := store.db.DeleteCF(store.writeOpts, store.keyMetaCF, []byte("legacy")); err != nil {
+		t.Fatalf("Failed to delete legacy meta: %v", err)
+	}
+	evict([]byte("legacy"))
+	info, found, err = store.GetMeta([]byte("legacy"))
+	if err != nil || !found || !info.Evicted || info.EvictedAt == 0 {
+		t.Errorf("Expected legacy evicted meta with timestamp, got %+v: %v", info, err)
+	}
+
+	// 4. 保留期内不清理，超过保留期后删除标记，已被重新写入的键的索引在写入时已删除
+	if n, err := store.cleanupEvicted(time.Now().Add(-time.Hour), 100); err != nil || n != 0 {
+		t.Errorf("Expected no markers before retention, got %d: %v", n, err)
+	}
+	if n, err := store.cleanupEvicted(time.Now().Add(time.Second), 100); err != nil || n != 2 {
+		t.Errorf("Expected 2 markers scanned, got %d: %v", n, err)
+	}
+	for _, key := range []string{"evicted1", "legacy"} {
+		if _, found, err := store.Get([]byte(key)); found || err != nil {
+			t.Errorf("Expected evicted marker of %s removed, got found=%v: %v", key, found, err)
+		}
+	}
+	if value, _, err := store.Get([]byte("evicted2")); err != nil || string(value) != "reloaded" {
+		t.Errorf("Expected reloaded value kept, got %q: %v", value, err)
+	}
+}
+
+// TestEnvelope 测试值信封的编码、校验和以及旧格式值的转换
+func TestEnvelope(t *testing.T) {
+	meta := &KeyMeta{Version: 3, ExpiresAt: 1700000000, Location: LocationInline, Codec: CodecNone, Encryption: EncryptionNone}
+
+	// 1. 编码后解码得到相同的信封
+	data := newEnvelope(valueInline, meta, []byte("hello")).encode()
+	env, err := decodeEnvelope(data)
+	if err != nil {
+		t.Fatalf("Failed to decode envelope: %v", err)
+	}
+	if env.Type != valueInline || env.Version != 3 || env.ExpiresAt != 1700000000 || string(env.Payload) != "hello" {
+		t.Errorf("Unexpected envelope %+v", env)
+	}
+
+	// 2. 内容被修改、长度不足或版本未知时拒绝
+	tampered := append([]byte(nil), data...)
+	tampered[len(tampered)-1] ^= 1
+	if _, err := decodeEnvelope(tampered); !errors.Is(err, ErrCorruptValue) {
+		t.Errorf("Expected corrupt value for tampered payload, got %v", err)
+	}
+	if _, err := decodeEnvelope([]byte("hello")); !errors.Is(err, ErrCorruptValue) {
+		t.Errorf("Expected corrupt value for short value, got %v", err)
+	}
+	future := append([]byte(nil), data...)
+	future[0] = envelopeVersion + 1
+	if _, err := decodeEnvelope(future); err == nil {
+		t.Errorf("Expected error for newer envelope version")
+	}
+
+	// 3. 有元数据的旧值按元数据判断类型，内容与旧标记相同的用户值仍是内联值
+	// 没有元数据时只信任文件名为内容哈希且文件存在的磁盘指针，文件已丢失的按已淘汰处理
+	blob := strings.Repeat("ab", 32)
+	hasBlob := func(fileName string) bool { return fileName == blob }
+	tests := []struct {
+		name    string
+		raw     string
+		meta    *KeyMeta
+		typ     valueType
+		payload string
+	}{
+		{"inline", "hello", meta, valueInline, "hello"},
+		{"spoofed disk pointer", DiskStorePrefix + "../secret", meta, valueInline, DiskStorePrefix + "../secret"},
+		{"spoofed evicted", EvictedValue, meta, valueInline, EvictedValue},
+		{"disk", DiskStorePrefix + "ab/cdef", &KeyMeta{Location: LocationDisk, DiskFile: "ab/cdef"}, valueDisk, "ab/cdef"},
+		{"evicted", EvictedValue, &KeyMeta{Location: LocationDisk, Evicted: true}, valueEvicted, ""},
+		{"no meta disk", DiskStorePrefix + blob, nil, valueDisk, blob},
+		{"no meta spoofed disk pointer", DiskStorePrefix + "../../etc/passwd", nil, valueInline, DiskStorePrefix + "../../etc/passwd"},
+		{"no meta missing disk file", DiskStorePrefix + strings.Repeat("cd", 32), nil, valueEvicted, ""},
+		{"no meta evicted", EvictedValue, nil, valueEvicted, ""},
+	}
+	for _, tt := range tests {
+		env := legacyEnvelope([]byte(tt.raw), tt.meta, hasBlob)
+		if env.Type != tt.typ || string(env.Payload) != tt.payload {
+			t.Errorf("%s: expected type %d payload %q, got %d %q", tt.name, tt.typ, tt.payload, env.Type, env.Payload)
+		}
+	}
+}
+
+// TestValueMergeOperator 测试合并操作符更新信封的载荷、版本号和校验和，并兼容旧格式的操作数
+func TestValueMergeOperator(t *testing.T) {
+	op := &valueMergeOperator{}
+	meta := &KeyMeta{Version: 1, ExpiresAt: 1700000000}
+
+	// 1. 信封格式的操作数：追加和局部写入后重新计算校验和
+	existing := newEnvelope(valueInline, meta, []byte("hello")).encode()
+	merged, ok := op.FullMerge([]byte("k"), existing, [][]byte{
+		encodeEnvelopeOperand(2, appendOffset, []byte(" world")),
+		encodeEnvelopeOperand(3, 6, []byte("WORLD")),
+	})
+	if !ok {
+		t.Fatalf("Failed to merge envelope operands")
+	}
+	env, err := decodeEnvelope(merged)
+	if err != nil {
+		t.Fatalf("Failed to decode merged envelope: %v", err)
+	}
+	if env.Type != valueInline || env.Version != 3 || env.ExpiresAt != 1700000000 || string(env.Payload) != "hello WORLD" {
+		t.Errorf("Unexpected merged envelope %+v", env)
+	}
+
+	// 2. 已有值不是内联值时合并失败
+	evicted := newEnvelope(valueEvicted, meta, nil).encode()
+	if _, ok := op.FullMerge([]byte("k"), evicted, [][]byte{encodeEnvelopeOperand(2, appendOffset, []byte("x"))}); ok {
+		t.Errorf("Expected merge onto an evicted value to fail")
+	}
+
+	// 3. 旧格式的操作数直接应用到原始值上
+	legacy := make([]byte, mergeOperandHeaderSize, mergeOperandHeaderSize+1)
+	binary.BigEndian.PutUint64(legacy, appendOffset)
+	merged, ok = op.FullMerge([]byte("k"), []byte("ab"), [][]byte{append(legacy, 'c')})
+	if !ok || string(merged) != "abc" {
+		t.Errorf("Expected legacy operand to append, got %q, %v", merged, ok)
+	}
+}
+
+// migrationFixture testdata/format下的数据目录描述，按条目写入旧格式的RocksDB，values目录为磁盘存储的文件
+type migrationFixture struct {
+	Description    string   `json:"description"`
+	ColumnFamilies []string `json:"column_families"` // 旧版本已有的列族，为空时保留当前版本的全部列族
+	Entries        []struct {
+		CF       string `json:"cf"`
+		Key      string `json:"key"`
+		Value    string `json:"value"`
+		ValueHex string `json:"value_hex"`
+		MergeHex string `json:"merge_hex"` // 未合并的追加或局部更新
+	} `json:"entries"`
+	Migrated []int `json:"migrated"` // 各迁移步骤转换的条目数
+	Expect   []struct {
+		Namespace string `json:"namespace"`
+		Key       string `json:"key"`
+		Value     string `json:"value"`
+		Evicted   bool   `json:"evicted"`
+		Tombstone uint64 `json:"tombstone"`
+		Stored    string `json:"stored"` // 迁移后信封中的值类型：inline、disk或evicted
+	} `json:"expect"`
+}
+
+// writeMigrationFixture 按描述写入旧格式的数据目录
+func writeMigrationFixture(t *testing.T, cfg *config.Config, dir string, fixture *migrationFixture) {
+	os.RemoveAll(cfg.RocksDB.Path)
+	os.RemoveAll(cfg.Value.DiskPath)
+
+	store, err := NewRocksDBStorage(cfg)
+	if err != nil {
+		t.Fatalf("Failed to create storage: %v", err)
+	}
+	defer store.Stop()
+	if err := store.initRocksDB(false); err != nil {
+		t.Fatalf("Failed to open rocksdb: %v", err)
+	}
+
+	for _, e := range fixture.Entries {
+		handle, ok := store.namespaces.handles[e.CF]
+		if !ok {
+			handle, err = store.db.CreateColumnFamily(store.cfOpts, e.CF)
+			if err != nil {
+				t.Fatalf("Failed to create column family %s: %v", e.CF, err)
+			}
+			store.namespaces.handles[e.CF] = handle
+		}
+
+		if e.MergeHex != "" {
+			operand, _ := hex.DecodeString(e.MergeHex)
+			err = store.db.MergeCF(store.writeOpts, handle, []byte(e.Key), operand)
+		} else {
+			value := []byte(e.Value)
+			if e.ValueHex != "" {
+				value, _ = hex.DecodeString(e.ValueHex)
+			}
+			err = store.db.PutCF(store.writeOpts, handle, []byte(e.Key), value)
+		}
+		if err != nil {
+			t.Fatalf("Failed to write %s/%s: %v", e.CF, e.Key, err)
+		}
+	}
+
+	// 删除旧版本没有的列族
+	if len(fixture.ColumnFamilies) > 0 {
+		for name, handle := range store.namespaces.handles {
+			if !slices.Contains(fixture.ColumnFamilies, name) {
+				if err := store.db.DropColumnFamily(handle); err != nil {
+					t.Fatalf("Failed to drop column family %s: %v", name, err)
+				}
+			}
+		}
+	}
+
+	if err := os.CopyFS(cfg.Value.DiskPath, os.DirFS(filepath.Join(dir, "values"))); err != nil {
+		t.Fatalf("Failed to copy disk values: %v", err)
+	}
+}
+
+// TestStorageMigrations 测试各版本的数据目录试运行迁移不修改数据，启动时升级到当前格式后数据可以正确读取
+func TestStorageMigrations(t *testing.T) {
+	fixtures, err := filepath.Glob("testdata/format/*/fixture.json")
+	if err != nil || len(fixtures) == 0 {
+		t.Fatalf("Failed to find migration fixtures: %v", err)
+	}
+
+	for _, path := range fixtures {
+		dir := filepath.Dir(path)
+		t.Run(filepath.Base(dir), func(t *testing.T) {
+			data, err := os.ReadFile(path)
+			if err != nil {
+				t.Fatalf("Failed to read fixture: %v", err)
+			}
+			fixture := &migrationFixture{}
+			if err := json.Unmarshal(data, fixture); err != nil {
+				t.Fatalf("Failed to decode fixture: %v", err)
+			}
+
+			cfg := config.DefaultConfig()
+			writeMigrationFixture(t, cfg, dir, fixture)
+
+			// 1. 试运行报告需要转换的条目数，重复试运行结果相同
+			for i := 0; i < 2; i++ {
+				report, err := Migrate(cfg, true)
+				if err != nil {
+					t.Fatalf("Failed to dry-run migration: %v", err)
+				}
+				if report.To != FormatVersion || len(report.Steps) != len(fixture.Migrated) {
+					t.Fatalf("Expected migration to version %d in %d steps, got %+v", FormatVersion, len(fixture.Migrated), report)
+				}
+				for j, step := range report.Steps {
+					if step.Entries != fixture.Migrated[j] {
+						t.Errorf("Expected step %d to migrate %d entries, got %d", step.Version, fixture.Migrated[j], step.Entries)
+					}
+				}
+			}
+
+			// 2. 启动时完成迁移
+			store, err := NewStorage(cfg)
+			if err != nil {
+				t.Fatalf("Failed to create storage: %v", err)
+			}
+			root := store.(*RocksDBStorage)
+			if version, err := root.formatVersion(); err != nil || version != FormatVersion {
+				t.Errorf("Expected format version %d, got %d: %v", FormatVersion, version, err)
+			}
+
+			// 3. 迁移后的数据按原有语义读取
+			for _, e := range fixture.Expect {
+				ns, err := store.Namespace(e.Namespace)
+				if err != nil {
+					t.Fatalf("Failed to open namespace %s: %v", e.Namespace, err)
+				}
+				switch {
+				case e.Tombstone != 0:
+					if result, err := ns.GetVersioned([]byte(e.Key)); err != nil || result.Found || result.Stamp != e.Tombstone {
+						t.Errorf("%s/%s: expected tombstone at %d, got %+v: %v", e.Namespace, e.Key, e.Tombstone, result, err)
+					}
+				case e.Evicted:
+					if _, _, err := ns.Get([]byte(e.Key)); !errors.Is(err, ErrEvicted) {
+						t.Errorf("%s/%s: expected evicted, got %v", e.Namespace, e.Key, err)
+					}
+				default:
+					if value, _, err := ns.Get([]byte(e.Key)); err != nil || string(value) != e.Value {
+						t.Errorf("%s/%s: expected %q, got %q: %v", e.Namespace, e.Key, e.Value, value, err)
+					}
+				}
+
+				// 旧格式的前缀只有指向已存在的内容哈希文件时才转换为磁盘指针
+				if e.Stored != "" {
+					stored := map[string]valueType{"inline": valueInline, "disk": valueDisk, "evicted": valueEvicted}[e.Stored]
+					if env, err := ns.(*RocksDBStorage).readEnvelope([]byte(e.Key)); err != nil || env == nil || env.Type != stored {
+						t.Errorf("%s/%s: expected %s envelope, got %+v: %v", e.Namespace, e.Key, e.Stored, env, err)
+					}
+				}
+			}
+
+			// 4. 已是当前格式时没有需要执行的迁移
+			store.Stop()
+			report, err := Migrate(cfg, false)
+			if err != nil || report.From != FormatVersion || len(report.Steps) != 0 {
+				t.Errorf("Expected no migrations on current format, got %+v: %v", report, err)
+			}
+
+			// 5. 数据格式比当前版本新时拒绝打开
+			root, err = NewRocksDBStorage(cfg)
+			if err != nil {
+				t.Fatalf("Failed to create storage: %v", err)
+			}
+			if err := root.initRocksDB(false); err != nil {
+				t.Fatalf("Failed to open rocksdb: %v", err)
+			}
+			err = root.db.PutCF(root.writeOpts, root.metadataCF, []byte(formatVersionKey), encodeRevision(FormatVersion+1))
+			root.Stop()
+			if err != nil {
+				t.Fatalf("Failed to write format version: %v", err)
+			}
+			if _, err := NewStorage(cfg); !errors.Is(err, ErrFormatTooNew) {
+				t.Errorf("Expected newer format to be refused, got %v", err)
+			}
+			if _, err := Migrate(cfg, true); !errors.Is(err, ErrFormatTooNew) {
+				t.Errorf("Expected dry-run on newer format to be refused, got %v", err)
+			}
+		})
+	}
+}
+
+// TestErrorCode 测试错误类别的匹配，包装后的错误仍属于原来的类别
+func TestErrorCode(t *testing.T) {
+	tests := []struct {
+		err  error
+		code Code
+	}{
+		{ErrQuotaExceeded, CodeResourceExhausted},
+		{fmt.Errorf("%w: prefix %q", ErrQuotaExceeded, "a"), CodeResourceExhausted},
+		{ErrReadOnly, CodeUnavailable},
+		{ErrCorruptValue, CodeCorrupted},
+		{ErrEvicted, CodeEvicted},
+		{fmt.Errorf("%w: %s", ErrReservedKey, ReservedKeyPrefix), CodeInvalidArgument},
+		{Errorf(CodeConflict, "key %s already exists", "a"), CodeConflict},
+		{context.Canceled, CodeCanceled},
+		{fmt.Errorf("scan: %w", context.DeadlineExceeded), CodeDeadlineExceeded},
+		{NewError(CodeDeadlineExceeded, "remote deadline"), CodeDeadlineExceeded},
+		{fmt.Errorf("%w: requested 3, oldest available 7", ErrCompacted), CodeOutOfRange},
+		{ErrWatchLagging, CodeUnavailable},
+		{errors.New("disk failure"), CodeInternal},
+	}
+	for _, tt := range tests {
+		if code := ErrorCode(tt.err); code != tt.code {
+			t.Errorf("ErrorCode(%v): expected %s, got %s", tt.err, tt.code, code)
+		}
+	}
+
+	// 同一类别的不同错误互不匹配，但都与类别匹配
+	if errors.Is(ErrReadOnly, ErrStopped) || !errors.Is(ErrReadOnly, ErrUnavailable) || !errors.Is(ErrStopped, ErrUnavailable) {
+		t.Errorf("Expected errors to match only their category")
+	}
+}
@@ -0,0 +1,290 @@
+package storage
+
+import (
+	"bytes"
+	"fmt"
+	"sync"
+	"time"
+
+	gorocksdb "github.com/linxGnu/grocksdb"
+)
+
+const (
+	// tombstoneKeyPrefix 带版本删除的墓碑在元数据列族中的键前缀，值为墓碑类型的信封，版本号为删除时的时间戳
+	tombstoneKeyPrefix = "tombstone."
+	// tombstonePurgeInterval 清理超过保留时间的墓碑的间隔
+	tombstonePurgeInterval = time.Minute
+	// tombstonePurgeBatch 每批检查的墓碑数量
+	tombstonePurgeBatch = 1000
+)
+
+// tombstonePurger 在后台清理所有命名空间超过保留时间的墓碑
+type tombstonePurger struct {
+	stop chan struct{}
+	once sync.Once
+	wg   sync.WaitGroup
+}
+
+// newTombstonePurger 创建墓碑清理器
+func newTombstonePurger() *tombstonePurger {
+	return &tombstonePurger{stop: make(chan struct{})}
+}
+
+// close 通知清理停止并等待退出
+func (p *tombstonePurger) close() {
+	p.once.Do(func() {
+		close(p.stop)
+	})
+	p.wg.Wait()
+}
+
+// VersionedValue 带版本读取的结果，键已被带版本删除时Found为false，Stamp为删除的时间戳
+type VersionedValue struct {
+	Value []byte
+	Stamp uint64
+	Found bool
+	TTL   time.Duration // 剩余存活时间，-1表示永不过期
+}
+
+// tombstoneKey 返回键的墓碑在元数据列族中的键
+func (s *RocksDBStorage) tombstoneKey(key []byte) []byte {
+	return append([]byte(tombstoneKeyPrefix+s.namespace.Name+"."), key...)
+}
+
+// currentStamp 返回键当前值或墓碑的时间戳，调用方需持有键锁
+func (s *RocksDBStorage) currentStamp(key []byte) (*KeyMeta, uint64, error) {
+	meta, err := s.loadMeta(key)
+	if err != nil {
+		return nil, 0, err
+	}
+	if meta != nil && !meta.Expired(time.Now()) {
+		return meta, meta.Stamp, nil
+	}
+
+	value, err := s.db.GetCF(s.readOpts, s.metadataCF, s.tombstoneKey(key))
+	if err != nil {
+		return nil, 0, err
+	}
+	defer value.Free()
+	if value.Size() == 0 {
+		return meta, 0, nil
+	}
+	tombstone, err := decodeEnvelope(value.Data())
+	if err != nil {
+		return nil, 0, err
+	}
+	if tombstone.Type != valueTombstone {
+		return nil, 0, ErrCorruptValue
+	}
+	return meta, tombstone.Version, nil
+}
+
+// SetVersioned 时间戳比键当前的时间戳新时写入值，返回是否已写入
+func (s *RocksDBStorage) SetVersioned(key, value []byte, ttl time.Duration, stamp uint64) (bool, error) {
+	unlock := s.locks.lock(key)
+	defer unlock()
+
+	// 1. 忽略不比当前值或墓碑新的写入
+	_, current, err := s.currentStamp(key)
+	if err != nil {
+		return false, err
+	}
+	if stamp <= current {
+		return false, nil
+	}
+
+	// 2. 写入值并移除墓碑
+	wb := gorocksdb.NewWriteBatch()
+	defer wb.Destroy()
+
+	delta := newBatchDelta()
+	if err := s.putValue(wb, delta, key, value, ttl, stamp); err != nil {
+		return false, err
+	}
+	wb.DeleteCF(s.metadataCF, s.tombstoneKey(key))
+
+	if err := s.commit(wb, delta); err != nil {
+		return false, err
+	}
+	return true, nil
+}
+
+// DeleteVersioned 时间戳比键当前的时间戳新时删除键并记录墓碑，返回是否已删除
+// 墓碑在键被更新的时间戳重新写入或超过保留时间前一直保留，使较旧的写入不会使键复活
+func (s *RocksDBStorage) DeleteVersioned(key []byte, stamp uint64) (bool, error) {
+	unlock := s.locks.lock(key)
+	defer unlock()
+
+	_, current, err := s.currentStamp(key)
+	if err != nil {
+		return false, err
+	}
+	if stamp <= current {
+		return false, nil
+	}
+
+	wb := gorocksdb.NewWriteBatch()
+	defer wb.Destroy()
+
+	delta := newBatchDelta()
+	if err := s.deleteKey(wb, delta, key); err != nil {
+		return false, err
+	}
+	wb.PutCF(s.metadataCF, s.tombstoneKey(key), encodeTombstone(stamp))
+
+	if err := s.commit(wb, delta); err != nil {
+		return false, err
+	}
+	return true, nil
+}
+
+// GetVersioned 读取键的值和时间戳，过期的键视为不存在
+func (s *RocksDBStorage) GetVersioned(key []byte) (*VersionedValue, error) {
+	unlock := s.locks.lock(key)
+	defer unlock()
+
+	meta, stamp, err := s.currentStamp(key)
+	if err != nil {
+		return nil, err
+	}
+	now := time.Now()
+	result := &VersionedValue{Stamp: stamp, TTL: -1}
+	if meta == nil || meta.Expired(now) {
+		return result, nil
+	}
+	result.TTL = meta.RemainingTTL(now)
+
+	// 持有键锁期间值和元数据一致，直接读取存储的值
+	env, err := s.readEnvelope(key)
+	if err != nil {
+		return nil, err
+	}
+
+	switch {
+	case env == nil:
+		return result, nil
+	case env.Type == valueEvicted:
+		return nil, ErrEvicted
+	case env.Type == valueDisk:
+		diskValue, err := s.diskStore.Load(env.diskFile())
+		if err != nil {
+			return nil, err
+		}
+		result.Value = diskValue
+	default:
+		result.Value = env.Payload
+	}
+	result.Found = true
+	return result, nil
+}
+
+// startTombstonePurge 未配置保留时间时墓碑永久保留
+// 时间戳的高48位为毫秒，删除早于保留时间的墓碑后，时间戳更旧的写入可以重新写入键，保留时间需长于暂存写入和读修复的时间
+func (s *RocksDBStorage) startTombstonePurge() {
+	retention := time.Duration(s.config.Versioned.TombstoneRetention) * time.Second
+	if retention <= 0 {
+		return
+	}
+
+	s.tombstones.wg.Add(1)
+	go func() {
+		defer s.tombstones.wg.Done()
+
+		ticker := time.NewTicker(tombstonePurgeInterval)
+		defer ticker.Stop()
+
+		for {
+			select {
+			case <-ticker.C:
+				before := uint64(time.Now().Add(-retention).UnixMilli()) << 16
+				for _, view := range append([]*RocksDBStorage{s}, s.namespaces.list()...) {
+					if err := view.purgeTombstones(before, s.tombstones.stop); err != nil {
+						fmt.Printf("tombstone purge of namespace %s failed: %v\n", view.namespace.Name, err)
+					}
+				}
+			case <-s.tombstones.stop:
+				return
+			}
+		}
+	}()
+}
+
+// purgeTombstones 分批删除当前命名空间时间戳早于before的墓碑
+func (s *RocksDBStorage) purgeTombstones(before uint64, stop <-chan struct{}) error {
+	prefix := []byte(tombstoneKeyPrefix + s.namespace.Name + ".")
+	start := prefix
+	for start != nil {
+		select {
+		case <-stop:
+			return nil
+		default:
+		}
+
+		keys, next, err := s.expiredTombstones(prefix, start, before)
+		if err != nil {
+			return err
+		}
+		for _, key := range keys {
+			if err := s.purgeTombstone(key, before); err != nil {
+				return err
+			}
+		}
+		start = next
+	}
+	return nil
+}
+
+// expiredTombstones 从start开始检查一批墓碑，返回其中早于before的键和下一批的起始位置，检查完毕时起始位置为nil
+func (s *RocksDBStorage) expiredTombstones(prefix, start []byte, before uint64) ([][]byte, []byte, error) {
+	iter := s.db.NewIteratorCF(s.readOpts, s.metadataCF)
+	defer iter.Close()
+
+	var keys [][]byte
+	scanned := 0
+	for iter.Seek(start); iter.Valid(); iter.Next() {
+		tombstoneKey := iter.Key().Data()
+		if !bytes.HasPrefix(tombstoneKey, prefix) {
+			return keys, nil, iter.Err()
+		}
+		if scanned == tombstonePurgeBatch {
+			return keys, append([]byte(nil), tombstoneKey...), iter.Err()
+		}
+		scanned++
+
+		if tombstone, err := decodeEnvelope(iter.Value().Data()); err == nil && tombstone.Type == valueTombstone && tombstone.Version < before {
+			keys = append(keys, append([]byte(nil), tombstoneKey[len(prefix):]...))
+		}
+	}
+	return keys, nil, iter.Err()
+}
+
+// purgeTombstone 加锁后确认墓碑仍早于before再删除，避免删除并发写入的新墓碑
+func (s *RocksDBStorage) purgeTombstone(key []byte, before uint64) error {
+	unlock := s.locks.lock(key)
+	defer unlock()
+
+	value, err := s.db.GetCF(s.readOpts, s.metadataCF, s.tombstoneKey(key))
+	if err != nil {
+		return err
+	}
+	defer value.Free()
+	if value.Size() == 0 {
+		return nil
+	}
+	tombstone, err := decodeEnvelope(value.Data())
+	if err != nil || tombstone.Type != valueTombstone || tombstone.Version >= before {
+		return nil
+	}
+	return s.db.DeleteCF(s.writeOpts, s.metadataCF, s.tombstoneKey(key))
+}
+
+// dropTombstones 删除当前命名空间的全部墓碑
+func (s *RocksDBStorage) dropTombstones() error {
+	prefix := []byte(tombstoneKeyPrefix + s.namespace.Name + ".")
+
+	wb := gorocksdb.NewWriteBatch()
+	defer wb.Destroy()
+
+	wb.DeleteRangeCF(s.metadataCF, prefix, prefixEnd(prefix))
+	return s.db.Write(s.writeOpts, wb)
+}
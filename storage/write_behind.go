@@ -0,0 +1,112 @@
+package storage
+
+import (
+	"bytes"
+	"encoding/binary"
+	"sync/atomic"
+	"time"
+
+	gorocksdb "github.com/linxGnu/grocksdb"
+)
+
+// writeBehindKeyPrefix 待写回源站的键在元数据列族中的键前缀，值为标记序号
+const writeBehindKeyPrefix = "writebehind."
+
+// writeBehindSeq 最近一次分配的标记序号
+var writeBehindSeq atomic.Uint64
+
+// WriteBehindEntry 待写回源站的键，只记录键，写回时读取键的最新值
+type WriteBehindEntry struct {
+	Namespace string
+	Key       []byte
+	Seq       uint64 // 标记序号，键被再次标记后变化
+}
+
+// nextWriteBehindSeq 分配递增的标记序号，重启后从当前时间继续
+func nextWriteBehindSeq() uint64 {
+	for {
+		last := writeBehindSeq.Load()
+		next := max(uint64(time.Now().UnixNano()), last+1)
+		if writeBehindSeq.CompareAndSwap(last, next) {
+			return next
+		}
+	}
+}
+
+// writeBehindKey 返回键的写回标记在元数据列族中的键
+func writeBehindKey(namespace string, key []byte) []byte {
+	return append([]byte(writeBehindKeyPrefix+namespace+"."), key...)
+}
+
+// MarkWriteBehind 标记键待写回源站，重复标记的键只保留最新的序号
+func (s *RocksDBStorage) MarkWriteBehind(keys ...[]byte) error {
+	metaKeys := make([][]byte, len(keys))
+	for i, key := range keys {
+		metaKeys[i] = writeBehindKey(s.namespace.Name, key)
+	}
+	unlock := s.locks.lock(metaKeys...)
+	defer unlock()
+
+	wb := gorocksdb.NewWriteBatch()
+	defer wb.Destroy()
+
+	seq := encodeRevision(nextWriteBehindSeq())
+	for _, metaKey := range metaKeys {
+		wb.PutCF(s.metadataCF, metaKey, seq)
+	}
+	return s.db.Write(s.writeOpts, wb)
+}
+
+// PendingWriteBehind 返回所有命名空间中最多limit个待写回的键
+func (s *RocksDBStorage) PendingWriteBehind(limit int) ([]WriteBehindEntry, error) {
+	iter := s.db.NewIteratorCF(s.readOpts, s.metadataCF)
+	defer iter.Close()
+
+	var entries []WriteBehindEntry
+	prefix := []byte(writeBehindKeyPrefix)
+	for iter.Seek(prefix); iter.Valid() && len(entries) < limit; iter.Next() {
+		key := iter.Key().Data()
+		if !bytes.HasPrefix(key, prefix) {
+			break
+		}
+		rest := key[len(prefix):]
+		dot := bytes.IndexByte(rest, '.')
+		if dot < 0 || iter.Value().Size() != 8 {
+			continue
+		}
+		entries = append(entries, WriteBehindEntry{
+			Namespace: string(rest[:dot]),
+			Key:       append([]byte(nil), rest[dot+1:]...),
+			Seq:       binary.BigEndian.Uint64(iter.Value().Data()),
+		})
+	}
+	return entries, iter.Err()
+}
+
+// AckWriteBehind 写回成功后移除标记，写回期间键被再次标记时保留标记
+func (s *RocksDBStorage) AckWriteBehind(entry WriteBehindEntry) error {
+	metaKey := writeBehindKey(entry.Namespace, entry.Key)
+	unlock := s.locks.lock(metaKey)
+	defer unlock()
+
+	value, err := s.db.GetCF(s.readOpts, s.metadataCF, metaKey)
+	if err != nil {
+		return err
+	}
+	defer value.Free()
+	if value.Size() != 8 || binary.BigEndian.Uint64(value.Data()) != entry.Seq {
+		return nil
+	}
+	return s.db.DeleteCF(s.writeOpts, s.metadataCF, metaKey)
+}
+
+// dropWriteBehind 删除当前命名空间的全部写回标记
+func (s *RocksDBStorage) dropWriteBehind() error {
+	prefix := writeBehindKey(s.namespace.Name, nil)
+
+	wb := gorocksdb.NewWriteBatch()
+	defer wb.Destroy()
+
+	wb.DeleteRangeCF(s.metadataCF, prefix, prefixEnd(prefix))
+	return s.db.Write(s.writeOpts, wb)
+}
@@ -3,6 +3,11 @@ package api_test
 import (
 	"context"
 	"testing"
+	"time"
+
+	"google.golang.org/genproto/googleapis/rpc/errdetails"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
 
 	"kvcache/proto"
 )
@@ -388,14 +393,15 @@ func TestGRPCGetNonExistentKey(t *testing.T) {
 	}
 
 	// 发送请求
-	resp, err := grpcClient.Get(context.Background(), req)
-	if err != nil {
-		t.Fatalf("Failed to get non-existent key: %v", err)
-	}
+	_, err := grpcClient.Get(context.Background(), req)
 
-	// 检查响应
-	if resp.Found {
-		t.Errorf("Expected found false for non-existent key, got %v", resp.Found)
+	// 检查响应，不存在的键返回NotFound状态，错误详情中带有类别
+	st := status.Convert(err)
+	if st.Code() != codes.NotFound {
+		t.Fatalf("Expected NotFound for non-existent key, got %v", err)
+	}
+	if len(st.Details()) != 1 || st.Details()[0].(*errdetails.ErrorInfo).Reason != "NOT_FOUND" {
+		t.Errorf("Expected NOT_FOUND error info, got %v", st.Details())
 	}
 }
 
@@ -418,3 +424,173 @@ func TestGRPCDeleteNonExistentKey(t *testing.T) {
 		t.Errorf("Expected success true for deleting non-existent key, got %v", resp.Success)
 	}
 }
+
+// 测试追加和局部写入接口
+func TestGRPCAppend(t *testing.T) {
+
+	// 追加到不存在的键
+	appendResp, err := grpcClient.Append(context.Background(), &proto.AppendRequest{
+		Key:  []byte("grpc-append-key"),
+		Data: []byte("part-1;"),
+	})
+	if err != nil {
+		t.Fatalf("Failed to append: %v", err)
+	}
+	if !appendResp.Success {
+		t.Errorf("Expected success true, got %v (%s)", appendResp.Success, appendResp.Error)
+	}
+
+	_, err = grpcClient.Append(context.Background(), &proto.AppendRequest{
+		Key:  []byte("grpc-append-key"),
+		Data: []byte("part-2;"),
+	})
+	if err != nil {
+		t.Fatalf("Failed to append: %v", err)
+	}
+
+	// 局部写入
+	writeResp, err := grpcClient.WriteAt(context.Background(), &proto.WriteAtRequest{
+		Key:    []byte("grpc-append-key"),
+		Offset: 0,
+		Data:   []byte("PART"),
+	})
+	if err != nil {
+		t.Fatalf("Failed to write at offset: %v", err)
+	}
+	if !writeResp.Success {
+		t.Errorf("Expected success true, got %v (%s)", writeResp.Success, writeResp.Error)
+	}
+
+	// 检查最终的值
+	getResp, err := grpcClient.Get(context.Background(), &proto.GetRequest{Key: []byte("grpc-append-key")})
+	if err != nil {
+		t.Fatalf("Failed to get: %v", err)
+	}
+	if string(getResp.Value) != "PART-1;part-2;" {
+		t.Errorf("Expected value 'PART-1;part-2;', got '%s'", string(getResp.Value))
+	}
+}
+
+// 测试元数据查询接口
+func TestGRPCGetMeta(t *testing.T) {
+
+	_, err := grpcClient.Set(context.Background(), &proto.SetRequest{
+		Key:   []byte("grpc-meta-key"),
+		Value: []byte("grpc-meta-value"),
+	})
+	if err != nil {
+		t.Fatalf("Failed to set: %v", err)
+	}
+
+	resp, err := grpcClient.GetMeta(context.Background(), &proto.GetMetaRequest{
+		Key: []byte("grpc-meta-key"),
+	})
+	if err != nil {
+		t.Fatalf("Failed to get meta: %v", err)
+	}
+
+	if !resp.Found {
+		t.Fatalf("Expected found true, got %v (%s)", resp.Found, resp.Error)
+	}
+	if resp.Meta.Size != int64(len("grpc-meta-value")) {
+		t.Errorf("Expected size %d, got %d", len("grpc-meta-value"), resp.Meta.Size)
+	}
+	if resp.Meta.Location != "inline" {
+		t.Errorf("Expected location 'inline', got '%s'", resp.Meta.Location)
+	}
+	if resp.Meta.Ttl != -1 {
+		t.Errorf("Expected ttl -1, got %d", resp.Meta.Ttl)
+	}
+}
+
+func TestGRPCExistsCount(t *testing.T) {
+
+	_, err := grpcClient.MSet(context.Background(), &proto.MSetRequest{
+		KeyValues: map[string][]byte{
+			"grpc-count:1": []byte("one"),
+			"grpc-count:2": []byte("two"),
+		},
+	})
+	if err != nil {
+		t.Fatalf("Failed to mset: %v", err)
+	}
+
+	existsResp, err := grpcClient.Exists(context.Background(), &proto.ExistsRequest{
+		Key: []byte("grpc-count:1"),
+	})
+	if err != nil {
+		t.Fatalf("Failed to check key: %v", err)
+	}
+	if !existsResp.Exists {
+		t.Errorf("Expected exists true, got false (%s)", existsResp.Error)
+	}
+
+	countResp, err := grpcClient.CountPrefix(context.Background(), &proto.CountPrefixRequest{
+		Prefix: []byte("grpc-count:"),
+		Exact:  true,
+	})
+	if err != nil {
+		t.Fatalf("Failed to count prefix: %v", err)
+	}
+	if countResp.Count != 2 {
+		t.Errorf("Expected count 2, got %d (%s)", countResp.Count, countResp.Error)
+	}
+
+	sizeResp, err := grpcClient.SizeOf(context.Background(), &proto.SizeOfRequest{
+		Prefix: []byte("grpc-count:"),
+	})
+	if err != nil {
+		t.Fatalf("Failed to get prefix size: %v", err)
+	}
+	if sizeResp.TotalBytes != 6 {
+		t.Errorf("Expected 6 total bytes, got %d (%s)", sizeResp.TotalBytes, sizeResp.Error)
+	}
+}
+
+// 测试变更订阅接口
+func TestGRPCWatch(t *testing.T) {
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	// 订阅后写入，事件按提交顺序推送
+	stream, err := grpcClient.Watch(ctx, &proto.WatchRequest{Prefix: "grpc-watch:"})
+	if err != nil {
+		t.Fatalf("Failed to watch: %v", err)
+	}
+	// 收到响应头表示订阅已在服务端注册
+	if _, err := stream.Header(); err != nil {
+		t.Fatalf("Failed to receive header: %v", err)
+	}
+
+	grpcClient.Set(ctx, &proto.SetRequest{Key: []byte("grpc-watch:1"), Value: []byte("v1")})
+	grpcClient.Set(ctx, &proto.SetRequest{Key: []byte("grpc-other"), Value: []byte("v2")})
+	grpcClient.Delete(ctx, &proto.DeleteRequest{Key: []byte("grpc-watch:1")})
+
+	put, err := stream.Recv()
+	if err != nil {
+		t.Fatalf("Failed to receive event: %v", err)
+	}
+	if put.Type != "put" || string(put.Key) != "grpc-watch:1" {
+		t.Errorf("Expected put event, got %+v", put)
+	}
+	del, err := stream.Recv()
+	if err != nil {
+		t.Fatalf("Failed to receive event: %v", err)
+	}
+	if del.Type != "delete" || del.Revision <= put.Revision {
+		t.Errorf("Expected delete event after revision %d, got %+v", put.Revision, del)
+	}
+
+	// 从历史修订号重新订阅
+	replay, err := grpcClient.Watch(ctx, &proto.WatchRequest{Prefix: "grpc-watch:", FromRevision: put.Revision})
+	if err != nil {
+		t.Fatalf("Failed to watch from revision: %v", err)
+	}
+	event, err := replay.Recv()
+	if err != nil {
+		t.Fatalf("Failed to receive replayed event: %v", err)
+	}
+	if event.Revision != put.Revision {
+		t.Errorf("Expected replay to start at revision %d, got %d", put.Revision, event.Revision)
+	}
+}
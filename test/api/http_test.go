@@ -1,13 +1,17 @@
 package api_test
 
 import (
+	"bufio"
 	"bytes"
+	"context"
 	"encoding/json"
 	"net"
 	"net/http"
 	"net/http/httptest"
 	"os"
+	"strings"
 	"testing"
+	"time"
 
 	"github.com/gin-gonic/gin"
 	"google.golang.org/grpc"
@@ -64,12 +68,42 @@ func TestMain(m *testing.M) {
 	testRouter.POST("/api/v1/set", httpServer.Set)
 	testRouter.GET("/api/v1/get/:key", httpServer.Get)
 	testRouter.DELETE("/api/v1/delete/:key", httpServer.Delete)
+	testRouter.HEAD("/api/v1/keys/:key", httpServer.Stat)
+	testRouter.GET("/api/v1/exists/:key", httpServer.Exists)
+	testRouter.POST("/api/v1/mexists", httpServer.MExists)
+	testRouter.GET("/api/v1/count", httpServer.CountPrefix)
+	testRouter.GET("/api/v1/size", httpServer.SizeOf)
 	testRouter.GET("/api/v1/scan", httpServer.Scan)
+	testRouter.POST("/api/v1/append", httpServer.Append)
+	testRouter.POST("/api/v1/writeat", httpServer.WriteAt)
+	testRouter.POST("/api/v1/rename", httpServer.Rename)
+	testRouter.POST("/api/v1/copy", httpServer.Copy)
 	testRouter.POST("/api/v1/mset", httpServer.MSet)
 	testRouter.POST("/api/v1/mget", httpServer.MGet)
 	testRouter.POST("/api/v1/mdelete", httpServer.MDelete)
+	testRouter.GET("/api/v1/watch", httpServer.Watch)
+	testRouter.POST("/api/v1/admin/delete-prefix", httpServer.DeletePrefix)
+	testRouter.POST("/api/v1/admin/delete-range", httpServer.DeleteRange)
+	testRouter.GET("/api/v1/admin/delete-jobs/:id", httpServer.GetDeleteJob)
+	testRouter.GET("/api/v1/admin/quotas", httpServer.ListQuotas)
+	testRouter.POST("/api/v1/admin/quotas", httpServer.SetQuota)
+	testRouter.DELETE("/api/v1/admin/quotas", httpServer.DeleteQuota)
+	testRouter.DELETE("/api/v1/admin/cdc/checkpoints/:name", httpServer.DeleteCheckpoint)
+	testRouter.GET("/api/v1/admin/replication", httpServer.ReplicationStatus)
+	testRouter.POST("/api/v1/admin/replication/promote", httpServer.Promote)
+	testRouter.GET("/api/v1/admin/cluster", httpServer.ClusterStatus)
+	testRouter.POST("/api/v1/admin/cluster/members", httpServer.AddMember)
+	testRouter.DELETE("/api/v1/admin/cluster/members/:id", httpServer.RemoveMember)
+	testRouter.GET("/api/v1/admin/shards", httpServer.ShardingStatus)
+	testRouter.GET("/api/v1/admin/shards/migration", httpServer.MigrationStatus)
+	testRouter.POST("/api/v1/admin/shards/migration", httpServer.StartMigration)
+	testRouter.PUT("/api/v1/admin/shards/migration/rate", httpServer.SetMigrationRate)
+	testRouter.DELETE("/api/v1/admin/shards/migration", httpServer.CancelMigration)
 	testRouter.GET("/api/v1/config", httpServer.GetConfig)
 	testRouter.POST("/api/v1/config", httpServer.UpdateConfig)
+	testRouter.GET("/api/v1/namespaces", httpServer.ListNamespaces)
+	testRouter.POST("/api/v1/namespaces", httpServer.CreateNamespace)
+	testRouter.DELETE("/api/v1/namespaces/:name", httpServer.DropNamespace)
 	testRouter.GET("/metrics", gin.WrapH(http.DefaultServeMux))
 
 	// 创建gRPC服务器
@@ -715,3 +749,675 @@ func TestErrorHandling(t *testing.T) {
 		t.Errorf("Expected status code %d, got %d", http.StatusBadRequest, w.Code)
 	}
 }
+
+// 测试追加和局部写入接口
+func TestAppend(t *testing.T) {
+	// 依次发送追加和局部写入请求
+	requests := []struct {
+		path string
+		body map[string]interface{}
+	}{
+		{"/api/v1/set", map[string]interface{}{"key": "http-append-key", "value": "abc"}},
+		{"/api/v1/append", map[string]interface{}{"key": "http-append-key", "value": "def"}},
+		{"/api/v1/writeat", map[string]interface{}{"key": "http-append-key", "offset": 1, "value": "B"}},
+	}
+
+	for _, r := range requests {
+		data, err := json.Marshal(r.body)
+		if err != nil {
+			t.Fatalf("Failed to marshal test data: %v", err)
+		}
+
+		req, err := http.NewRequest("POST", r.path, bytes.NewBuffer(data))
+		if err != nil {
+			t.Fatalf("Failed to create request: %v", err)
+		}
+		req.Header.Set("Content-Type", "application/json")
+
+		w := httptest.NewRecorder()
+		testRouter.ServeHTTP(w, req)
+
+		if w.Code != http.StatusOK {
+			t.Fatalf("Expected status code %d for %s, got %d", http.StatusOK, r.path, w.Code)
+		}
+	}
+
+	// 检查最终的值
+	getReq, err := http.NewRequest("GET", "/api/v1/get/http-append-key", nil)
+	if err != nil {
+		t.Fatalf("Failed to create get request: %v", err)
+	}
+
+	getW := httptest.NewRecorder()
+	testRouter.ServeHTTP(getW, getReq)
+
+	var response map[string]interface{}
+	if err := json.Unmarshal(getW.Body.Bytes(), &response); err != nil {
+		t.Fatalf("Failed to unmarshal response: %v", err)
+	}
+
+	if response["value"] != "aBcdef" {
+		t.Errorf("Expected value 'aBcdef', got '%v'", response["value"])
+	}
+}
+
+// 测试元数据查询接口
+func TestStat(t *testing.T) {
+	// 先设置一个键值对
+	data, err := json.Marshal(map[string]interface{}{
+		"key":   "http-stat-key",
+		"value": "http-stat-value",
+		"ttl":   3600,
+	})
+	if err != nil {
+		t.Fatalf("Failed to marshal test data: %v", err)
+	}
+
+	setReq, err := http.NewRequest("POST", "/api/v1/set", bytes.NewBuffer(data))
+	if err != nil {
+		t.Fatalf("Failed to create set request: %v", err)
+	}
+	setReq.Header.Set("Content-Type", "application/json")
+	testRouter.ServeHTTP(httptest.NewRecorder(), setReq)
+
+	// 创建元数据查询请求
+	req, err := http.NewRequest("HEAD", "/api/v1/keys/http-stat-key", nil)
+	if err != nil {
+		t.Fatalf("Failed to create request: %v", err)
+	}
+
+	w := httptest.NewRecorder()
+	testRouter.ServeHTTP(w, req)
+
+	if w.Code != http.StatusOK {
+		t.Fatalf("Expected status code %d, got %d", http.StatusOK, w.Code)
+	}
+
+	if w.Header().Get("X-KV-Size") != "15" {
+		t.Errorf("Expected X-KV-Size '15', got '%s'", w.Header().Get("X-KV-Size"))
+	}
+	if w.Header().Get("X-KV-Location") != "inline" {
+		t.Errorf("Expected X-KV-Location 'inline', got '%s'", w.Header().Get("X-KV-Location"))
+	}
+	if w.Header().Get("X-KV-TTL") == "-1" {
+		t.Errorf("Expected X-KV-TTL to reflect the ttl, got '-1'")
+	}
+	if w.Body.Len() != 0 {
+		t.Errorf("Expected empty body for HEAD request, got %d bytes", w.Body.Len())
+	}
+
+	// 不存在的键
+	missingReq, err := http.NewRequest("HEAD", "/api/v1/keys/http-stat-missing", nil)
+	if err != nil {
+		t.Fatalf("Failed to create request: %v", err)
+	}
+
+	missingW := httptest.NewRecorder()
+	testRouter.ServeHTTP(missingW, missingReq)
+
+	if missingW.Code != http.StatusNotFound {
+		t.Errorf("Expected status code %d, got %d", http.StatusNotFound, missingW.Code)
+	}
+	if code := missingW.Header().Get("X-KV-Error-Code"); code != "not_found" {
+		t.Errorf("Expected error code not_found, got %q", code)
+	}
+}
+
+func TestRename(t *testing.T) {
+	// 先设置一个键值对
+	data, err := json.Marshal(map[string]interface{}{
+		"key":   "http-rename-src",
+		"value": "http-rename-value",
+	})
+	if err != nil {
+		t.Fatalf("Failed to marshal test data: %v", err)
+	}
+
+	setReq, err := http.NewRequest("POST", "/api/v1/set", bytes.NewBuffer(data))
+	if err != nil {
+		t.Fatalf("Failed to create set request: %v", err)
+	}
+	setReq.Header.Set("Content-Type", "application/json")
+	testRouter.ServeHTTP(httptest.NewRecorder(), setReq)
+
+	// 创建重命名请求
+	renameData, err := json.Marshal(map[string]interface{}{
+		"src":       "http-rename-src",
+		"dst":       "http-rename-dst",
+		"overwrite": true,
+	})
+	if err != nil {
+		t.Fatalf("Failed to marshal test data: %v", err)
+	}
+
+	req, err := http.NewRequest("POST", "/api/v1/rename", bytes.NewBuffer(renameData))
+	if err != nil {
+		t.Fatalf("Failed to create request: %v", err)
+	}
+	req.Header.Set("Content-Type", "application/json")
+
+	w := httptest.NewRecorder()
+	testRouter.ServeHTTP(w, req)
+
+	if w.Code != http.StatusOK {
+		t.Fatalf("Expected status code %d, got %d: %s", http.StatusOK, w.Code, w.Body.String())
+	}
+
+	// 读取重命名后的键
+	getReq, err := http.NewRequest("GET", "/api/v1/get/http-rename-dst", nil)
+	if err != nil {
+		t.Fatalf("Failed to create get request: %v", err)
+	}
+
+	getW := httptest.NewRecorder()
+	testRouter.ServeHTTP(getW, getReq)
+
+	if getW.Code != http.StatusOK {
+		t.Errorf("Expected status code %d, got %d", http.StatusOK, getW.Code)
+	}
+}
+
+func TestDeletePrefix(t *testing.T) {
+	// 先设置一个键值对
+	data, err := json.Marshal(map[string]interface{}{
+		"key":   "http-purge:1",
+		"value": "value",
+	})
+	if err != nil {
+		t.Fatalf("Failed to marshal test data: %v", err)
+	}
+
+	setReq, err := http.NewRequest("POST", "/api/v1/set", bytes.NewBuffer(data))
+	if err != nil {
+		t.Fatalf("Failed to create set request: %v", err)
+	}
+	setReq.Header.Set("Content-Type", "application/json")
+	testRouter.ServeHTTP(httptest.NewRecorder(), setReq)
+
+	// 未确认的请求被拒绝
+	unconfirmed, err := json.Marshal(map[string]interface{}{
+		"prefix": "http-purge:",
+	})
+	if err != nil {
+		t.Fatalf("Failed to marshal test data: %v", err)
+	}
+
+	req, err := http.NewRequest("POST", "/api/v1/admin/delete-prefix", bytes.NewBuffer(unconfirmed))
+	if err != nil {
+		t.Fatalf("Failed to create request: %v", err)
+	}
+	req.Header.Set("Content-Type", "application/json")
+
+	w := httptest.NewRecorder()
+	testRouter.ServeHTTP(w, req)
+
+	if w.Code != http.StatusBadRequest {
+		t.Errorf("Expected status code %d, got %d", http.StatusBadRequest, w.Code)
+	}
+
+	// 确认后执行删除
+	confirmed, err := json.Marshal(map[string]interface{}{
+		"prefix":  "http-purge:",
+		"confirm": true,
+	})
+	if err != nil {
+		t.Fatalf("Failed to marshal test data: %v", err)
+	}
+
+	req, err = http.NewRequest("POST", "/api/v1/admin/delete-prefix", bytes.NewBuffer(confirmed))
+	if err != nil {
+		t.Fatalf("Failed to create request: %v", err)
+	}
+	req.Header.Set("Content-Type", "application/json")
+
+	w = httptest.NewRecorder()
+	testRouter.ServeHTTP(w, req)
+
+	if w.Code != http.StatusAccepted {
+		t.Fatalf("Expected status code %d, got %d: %s", http.StatusAccepted, w.Code, w.Body.String())
+	}
+
+	var resp map[string]interface{}
+	if err := json.Unmarshal(w.Body.Bytes(), &resp); err != nil {
+		t.Fatalf("Failed to unmarshal response: %v", err)
+	}
+	jobID, _ := resp["job_id"].(string)
+	if jobID == "" {
+		t.Fatalf("Expected job_id in response, got %v", resp)
+	}
+
+	// 查询任务进度
+	jobReq, err := http.NewRequest("GET", "/api/v1/admin/delete-jobs/"+jobID, nil)
+	if err != nil {
+		t.Fatalf("Failed to create request: %v", err)
+	}
+
+	jobW := httptest.NewRecorder()
+	testRouter.ServeHTTP(jobW, jobReq)
+
+	if jobW.Code != http.StatusOK {
+		t.Errorf("Expected status code %d, got %d", http.StatusOK, jobW.Code)
+	}
+}
+
+func TestExistsCount(t *testing.T) {
+	// 先设置两个键值对
+	data, err := json.Marshal(map[string]interface{}{
+		"kvs": map[string]string{
+			"http-count:1": "one",
+			"http-count:2": "two",
+		},
+	})
+	if err != nil {
+		t.Fatalf("Failed to marshal test data: %v", err)
+	}
+
+	setReq, err := http.NewRequest("POST", "/api/v1/mset", bytes.NewBuffer(data))
+	if err != nil {
+		t.Fatalf("Failed to create mset request: %v", err)
+	}
+	setReq.Header.Set("Content-Type", "application/json")
+	testRouter.ServeHTTP(httptest.NewRecorder(), setReq)
+
+	// 判断键是否存在
+	existsReq, err := http.NewRequest("GET", "/api/v1/exists/http-count:1", nil)
+	if err != nil {
+		t.Fatalf("Failed to create request: %v", err)
+	}
+
+	existsW := httptest.NewRecorder()
+	testRouter.ServeHTTP(existsW, existsReq)
+
+	if existsW.Code != http.StatusOK {
+		t.Fatalf("Expected status code %d, got %d", http.StatusOK, existsW.Code)
+	}
+
+	var existsResp map[string]interface{}
+	if err := json.Unmarshal(existsW.Body.Bytes(), &existsResp); err != nil {
+		t.Fatalf("Failed to unmarshal response: %v", err)
+	}
+	if existsResp["exists"] != true {
+		t.Errorf("Expected exists true, got %v", existsResp["exists"])
+	}
+
+	// 统计前缀下的键数量
+	countReq, err := http.NewRequest("GET", "/api/v1/count?prefix=http-count:&mode=exact", nil)
+	if err != nil {
+		t.Fatalf("Failed to create request: %v", err)
+	}
+
+	countW := httptest.NewRecorder()
+	testRouter.ServeHTTP(countW, countReq)
+
+	if countW.Code != http.StatusOK {
+		t.Fatalf("Expected status code %d, got %d", http.StatusOK, countW.Code)
+	}
+
+	var countResp map[string]interface{}
+	if err := json.Unmarshal(countW.Body.Bytes(), &countResp); err != nil {
+		t.Fatalf("Failed to unmarshal response: %v", err)
+	}
+	if countResp["count"] != float64(2) {
+		t.Errorf("Expected count 2, got %v", countResp["count"])
+	}
+
+	// 不支持的统计模式
+	badReq, err := http.NewRequest("GET", "/api/v1/count?prefix=http-count:&mode=fuzzy", nil)
+	if err != nil {
+		t.Fatalf("Failed to create request: %v", err)
+	}
+
+	badW := httptest.NewRecorder()
+	testRouter.ServeHTTP(badW, badReq)
+
+	if badW.Code != http.StatusBadRequest {
+		t.Errorf("Expected status code %d, got %d", http.StatusBadRequest, badW.Code)
+	}
+}
+
+func TestNamespaces(t *testing.T) {
+	// 创建命名空间
+	nsData, err := json.Marshal(map[string]interface{}{
+		"name":        "http-ns",
+		"default_ttl": 0,
+	})
+	if err != nil {
+		t.Fatalf("Failed to marshal test data: %v", err)
+	}
+
+	req, err := http.NewRequest("POST", "/api/v1/namespaces", bytes.NewBuffer(nsData))
+	if err != nil {
+		t.Fatalf("Failed to create request: %v", err)
+	}
+	req.Header.Set("Content-Type", "application/json")
+
+	w := httptest.NewRecorder()
+	testRouter.ServeHTTP(w, req)
+
+	if w.Code != http.StatusCreated {
+		t.Fatalf("Expected status code %d, got %d: %s", http.StatusCreated, w.Code, w.Body.String())
+	}
+
+	// 在命名空间中设置键值对
+	data, err := json.Marshal(map[string]interface{}{
+		"key":   "http-ns-key",
+		"value": "http-ns-value",
+	})
+	if err != nil {
+		t.Fatalf("Failed to marshal test data: %v", err)
+	}
+
+	setReq, err := http.NewRequest("POST", "/api/v1/set?namespace=http-ns", bytes.NewBuffer(data))
+	if err != nil {
+		t.Fatalf("Failed to create set request: %v", err)
+	}
+	setReq.Header.Set("Content-Type", "application/json")
+	setW := httptest.NewRecorder()
+	testRouter.ServeHTTP(setW, setReq)
+
+	if setW.Code != http.StatusOK {
+		t.Fatalf("Expected status code %d, got %d: %s", http.StatusOK, setW.Code, setW.Body.String())
+	}
+
+	// 通过请求头读取命名空间中的键，默认命名空间中不可见
+	getReq, err := http.NewRequest("GET", "/api/v1/get/http-ns-key", nil)
+	if err != nil {
+		t.Fatalf("Failed to create get request: %v", err)
+	}
+	getReq.Header.Set("X-KV-Namespace", "http-ns")
+	getW := httptest.NewRecorder()
+	testRouter.ServeHTTP(getW, getReq)
+
+	if getW.Code != http.StatusOK {
+		t.Errorf("Expected status code %d, got %d", http.StatusOK, getW.Code)
+	}
+
+	defaultReq, err := http.NewRequest("GET", "/api/v1/get/http-ns-key", nil)
+	if err != nil {
+		t.Fatalf("Failed to create get request: %v", err)
+	}
+	defaultW := httptest.NewRecorder()
+	testRouter.ServeHTTP(defaultW, defaultReq)
+
+	if defaultW.Code == http.StatusOK {
+		t.Errorf("Expected key to be invisible in the default namespace")
+	}
+
+	// 删除命名空间
+	dropReq, err := http.NewRequest("DELETE", "/api/v1/namespaces/http-ns", nil)
+	if err != nil {
+		t.Fatalf("Failed to create drop request: %v", err)
+	}
+	dropW := httptest.NewRecorder()
+	testRouter.ServeHTTP(dropW, dropReq)
+
+	if dropW.Code != http.StatusOK {
+		t.Errorf("Expected status code %d, got %d: %s", http.StatusOK, dropW.Code, dropW.Body.String())
+	}
+}
+
+func TestQuota(t *testing.T) {
+	// 创建配额
+	quotaData, err := json.Marshal(map[string]interface{}{
+		"prefix":   "http-quota:",
+		"max_keys": 1,
+	})
+	if err != nil {
+		t.Fatalf("Failed to marshal test data: %v", err)
+	}
+
+	req, err := http.NewRequest("POST", "/api/v1/admin/quotas", bytes.NewBuffer(quotaData))
+	if err != nil {
+		t.Fatalf("Failed to create request: %v", err)
+	}
+	req.Header.Set("Content-Type", "application/json")
+
+	w := httptest.NewRecorder()
+	testRouter.ServeHTTP(w, req)
+
+	if w.Code != http.StatusOK {
+		t.Fatalf("Expected status code %d, got %d: %s", http.StatusOK, w.Code, w.Body.String())
+	}
+
+	// 第二个键超出配额
+	writes := []struct {
+		key      string
+		expected int
+	}{
+		{"http-quota:1", http.StatusOK},
+		{"http-quota:2", http.StatusInsufficientStorage},
+	}
+	for _, write := range writes {
+		data, err := json.Marshal(map[string]interface{}{
+			"key":   write.key,
+			"value": "value",
+		})
+		if err != nil {
+			t.Fatalf("Failed to marshal test data: %v", err)
+		}
+
+		setReq, err := http.NewRequest("POST", "/api/v1/set", bytes.NewBuffer(data))
+		if err != nil {
+			t.Fatalf("Failed to create set request: %v", err)
+		}
+		setReq.Header.Set("Content-Type", "application/json")
+		setW := httptest.NewRecorder()
+		testRouter.ServeHTTP(setW, setReq)
+
+		if setW.Code != write.expected {
+			t.Errorf("Expected status code %d, got %d: %s", write.expected, setW.Code, setW.Body.String())
+		}
+	}
+
+	// 查询用量
+	listReq, err := http.NewRequest("GET", "/api/v1/admin/quotas", nil)
+	if err != nil {
+		t.Fatalf("Failed to create list request: %v", err)
+	}
+	listW := httptest.NewRecorder()
+	testRouter.ServeHTTP(listW, listReq)
+
+	var listResp struct {
+		Quotas []struct {
+			Prefix string `json:"prefix"`
+			Usage  struct {
+				Keys int64 `json:"keys"`
+			} `json:"usage"`
+		} `json:"quotas"`
+	}
+	if err := json.Unmarshal(listW.Body.Bytes(), &listResp); err != nil {
+		t.Fatalf("Failed to unmarshal response: %v", err)
+	}
+	found := false
+	for _, q := range listResp.Quotas {
+		if q.Prefix == "http-quota:" {
+			found = true
+			if q.Usage.Keys != 1 {
+				t.Errorf("Expected usage of 1 key, got %d", q.Usage.Keys)
+			}
+		}
+	}
+	if !found {
+		t.Errorf("Expected quota in list response: %s", listW.Body.String())
+	}
+
+	// 删除配额
+	delReq, err := http.NewRequest("DELETE", "/api/v1/admin/quotas?prefix=http-quota:", nil)
+	if err != nil {
+		t.Fatalf("Failed to create delete request: %v", err)
+	}
+	delW := httptest.NewRecorder()
+	testRouter.ServeHTTP(delW, delReq)
+
+	if delW.Code != http.StatusOK {
+		t.Errorf("Expected status code %d, got %d: %s", http.StatusOK, delW.Code, delW.Body.String())
+	}
+}
+
+// 测试变更订阅接口
+func TestWatch(t *testing.T) {
+	server := httptest.NewServer(testRouter)
+	defer server.Close()
+
+	// 非法的修订号
+	resp, err := http.Get(server.URL + "/api/v1/watch?from_revision=abc")
+	if err != nil {
+		t.Fatalf("Failed to send request: %v", err)
+	}
+	resp.Body.Close()
+	if resp.StatusCode != http.StatusBadRequest {
+		t.Errorf("Expected status code %d, got %d", http.StatusBadRequest, resp.StatusCode)
+	}
+
+	// 订阅后写入，通过SSE接收事件
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+	req, _ := http.NewRequestWithContext(ctx, http.MethodGet, server.URL+"/api/v1/watch?prefix=sse:", nil)
+	resp, err = http.DefaultClient.Do(req)
+	if err != nil {
+		t.Fatalf("Failed to watch: %v", err)
+	}
+	defer resp.Body.Close()
+	if ct := resp.Header.Get("Content-Type"); !strings.HasPrefix(ct, "text/event-stream") {
+		t.Fatalf("Expected event stream, got %q", ct)
+	}
+
+	if err := store.Set([]byte("sse:1"), []byte("value")); err != nil {
+		t.Fatalf("Failed to set value: %v", err)
+	}
+
+	var id, eventType, data string
+	reader := bufio.NewReader(resp.Body)
+	for data == "" {
+		line, err := reader.ReadString('\n')
+		if err != nil {
+			t.Fatalf("Failed to read event: %v", err)
+		}
+		line = strings.TrimSpace(line)
+		switch {
+		case strings.HasPrefix(line, "id: "):
+			id = strings.TrimPrefix(line, "id: ")
+		case strings.HasPrefix(line, "event: "):
+			eventType = strings.TrimPrefix(line, "event: ")
+		case strings.HasPrefix(line, "data: "):
+			data = strings.TrimPrefix(line, "data: ")
+		}
+	}
+
+	var event map[string]interface{}
+	if err := json.Unmarshal([]byte(data), &event); err != nil {
+		t.Fatalf("Failed to parse event: %v", err)
+	}
+	if eventType != "put" || event["key"] != "sse:1" || id == "" {
+		t.Errorf("Unexpected event: id=%s type=%s data=%s", id, eventType, data)
+	}
+}
+
+func TestReplicationStatus(t *testing.T) {
+	// 独立实例作为主节点运行
+	req, err := http.NewRequest("GET", "/api/v1/admin/replication", nil)
+	if err != nil {
+		t.Fatalf("Failed to create request: %v", err)
+	}
+
+	w := httptest.NewRecorder()
+	testRouter.ServeHTTP(w, req)
+
+	if w.Code != http.StatusOK {
+		t.Fatalf("Expected status code %d, got %d: %s", http.StatusOK, w.Code, w.Body.String())
+	}
+
+	var status service.ReplicationStatus
+	if err := json.Unmarshal(w.Body.Bytes(), &status); err != nil {
+		t.Fatalf("Failed to unmarshal response: %v", err)
+	}
+	if status.Role != service.RoleLeader {
+		t.Errorf("Expected role %s, got %s", service.RoleLeader, status.Role)
+	}
+
+	// 主节点不能被提升
+	promoteReq, err := http.NewRequest("POST", "/api/v1/admin/replication/promote", nil)
+	if err != nil {
+		t.Fatalf("Failed to create promote request: %v", err)
+	}
+
+	promoteW := httptest.NewRecorder()
+	testRouter.ServeHTTP(promoteW, promoteReq)
+
+	if promoteW.Code != http.StatusConflict {
+		t.Errorf("Expected status code %d, got %d: %s", http.StatusConflict, promoteW.Code, promoteW.Body.String())
+	}
+}
+
+func TestClusterStatusDisabled(t *testing.T) {
+	// 未启用集群模式时集群管理接口返回404
+	req, err := http.NewRequest("GET", "/api/v1/admin/cluster", nil)
+	if err != nil {
+		t.Fatalf("Failed to create request: %v", err)
+	}
+
+	w := httptest.NewRecorder()
+	testRouter.ServeHTTP(w, req)
+
+	if w.Code != http.StatusNotFound {
+		t.Errorf("Expected status code %d, got %d: %s", http.StatusNotFound, w.Code, w.Body.String())
+	}
+
+	body := []byte(`{"id":"node2","raft_addr":"127.0.0.1:7001"}`)
+	addReq, err := http.NewRequest("POST", "/api/v1/admin/cluster/members", bytes.NewBuffer(body))
+	if err != nil {
+		t.Fatalf("Failed to create add request: %v", err)
+	}
+	addReq.Header.Set("Content-Type", "application/json")
+
+	addW := httptest.NewRecorder()
+	testRouter.ServeHTTP(addW, addReq)
+
+	if addW.Code != http.StatusNotFound {
+		t.Errorf("Expected status code %d, got %d: %s", http.StatusNotFound, addW.Code, addW.Body.String())
+	}
+}
+
+func TestShardingStatusDisabled(t *testing.T) {
+	// 未启用分片时返回404
+	req, err := http.NewRequest("GET", "/api/v1/admin/shards?key=a", nil)
+	if err != nil {
+		t.Fatalf("Failed to create request: %v", err)
+	}
+
+	w := httptest.NewRecorder()
+	testRouter.ServeHTTP(w, req)
+
+	if w.Code != http.StatusNotFound {
+		t.Errorf("Expected status code %d, got %d: %s", http.StatusNotFound, w.Code, w.Body.String())
+	}
+}
+
+func TestMigrationDisabled(t *testing.T) {
+	// 未启用分片时查询和开始迁移都返回404
+	req, err := http.NewRequest("GET", "/api/v1/admin/shards/migration", nil)
+	if err != nil {
+		t.Fatalf("Failed to create request: %v", err)
+	}
+
+	w := httptest.NewRecorder()
+	testRouter.ServeHTTP(w, req)
+
+	if w.Code != http.StatusNotFound {
+		t.Errorf("Expected status code %d, got %d: %s", http.StatusNotFound, w.Code, w.Body.String())
+	}
+
+	body := bytes.NewBufferString(`{"nodes": ["localhost:33000", "localhost:33002"]}`)
+	startReq, err := http.NewRequest("POST", "/api/v1/admin/shards/migration", body)
+	if err != nil {
+		t.Fatalf("Failed to create request: %v", err)
+	}
+	startReq.Header.Set("Content-Type", "application/json")
+
+	startW := httptest.NewRecorder()
+	testRouter.ServeHTTP(startW, startReq)
+
+	if startW.Code != http.StatusNotFound {
+		t.Errorf("Expected status code %d, got %d: %s", http.StatusNotFound, startW.Code, startW.Body.String())
+	}
+}